@@ -2,6 +2,9 @@ package main
 
 import (
 	"dbm/internal/assets"
+	"dbm/internal/audit"
+	"dbm/internal/auth"
+	"dbm/internal/config"
 	"dbm/internal/connection"
 	"dbm/internal/server"
 	"errors"
@@ -61,17 +64,41 @@ func main() {
 	}
 
 	// 创建连接管理器
-	connManager, err := connection.NewManager(cfg.DataDir, encryptionKey)
+	connManager, err := connection.NewManager(cfg.DataDir, encryptionKey, cfg.File.Pool)
 	if err != nil {
 		log.Fatalf("创建连接管理器失败: %v", err)
 	}
 	defer connManager.Close()
 
+	// 加载用户，首次运行时创建管理员
+	users, err := initUsers(cfg)
+	if err != nil {
+		log.Fatalf("初始化用户失败: %v", err)
+	}
+
+	// 加载连接权限的角色与授权
+	var policy *auth.Policy
+	if users != nil {
+		if policy, err = auth.NewPolicy(cfg.DataDir); err != nil {
+			log.Fatalf("加载权限配置失败: %v", err)
+		}
+	}
+
+	// 打开审计日志
+	var auditLog *audit.Log
+	if cfg.File.Audit.Enabled {
+		if auditLog, err = audit.NewLog(cfg.File.Audit.Dir, cfg.File.Audit); err != nil {
+			log.Fatalf("初始化审计日志失败: %v", err)
+		}
+		defer auditLog.Close()
+	}
+
 	// 获取前端文件系统
 	staticFS := assets.FS()
 
 	// 创建并启动服务器
-	srv := server.NewServer(connManager, http.FS(staticFS))
+	srv := server.NewServer(connManager, users, policy, auditLog, http.FS(staticFS), cfg.File)
+	defer srv.Close()
 
 	addr := fmt.Sprintf("%s:%d", *host, *port)
 	log.Printf("DBM v%s 启动中...", version)
@@ -87,6 +114,7 @@ func main() {
 // Config 配置
 type Config struct {
 	DataDir string
+	File    *config.Config // 配置文件内容
 }
 
 // initConfig 初始化配置
@@ -101,6 +129,24 @@ func initConfig(configPath, dataPath string) (*Config, error) {
 		cfg.DataDir = filepath.Join(homeDir, ".dbm")
 	}
 
+	// 配置文件
+	if configPath == "" {
+		configPath = getConfigPath()
+	}
+	fileCfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置文件 %s 失败: %w", configPath, err)
+	}
+	cfg.File = fileCfg
+
+	// 导出文件默认放在数据目录下
+	if fileCfg.Export.Dir == "" {
+		fileCfg.Export.Dir = filepath.Join(cfg.DataDir, "exports")
+	}
+	if fileCfg.Audit.Dir == "" {
+		fileCfg.Audit.Dir = filepath.Join(cfg.DataDir, "audit")
+	}
+
 	return cfg, nil
 }
 
@@ -110,6 +156,38 @@ func getConfigPath() string {
 	return filepath.Join(homeDir, ".dbm", "config.yaml")
 }
 
+// initUsers 加载用户存储，未启用认证时返回 nil
+// 没有任何用户时创建管理员：用户名与密码取自 DBM_ADMIN_USER、DBM_ADMIN_PASSWORD，
+// 未设置密码时生成随机密码并输出到日志。
+func initUsers(cfg *Config) (*auth.Store, error) {
+	if !cfg.File.Auth.Enabled {
+		log.Println("警告: 已关闭登录认证，任何能访问监听地址的人都可以使用全部功能")
+		return nil, nil
+	}
+
+	users, err := auth.NewStore(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	username := os.Getenv("DBM_ADMIN_USER")
+	password, created, err := users.Bootstrap(username, os.Getenv("DBM_ADMIN_PASSWORD"))
+	if err != nil {
+		return nil, fmt.Errorf("创建管理员失败: %w", err)
+	}
+	if created {
+		if username == "" {
+			username = "admin"
+		}
+		if os.Getenv("DBM_ADMIN_PASSWORD") == "" {
+			log.Printf("已创建管理员 %s，初始密码: %s（请登录后修改）", username, password)
+		} else {
+			log.Printf("已创建管理员 %s", username)
+		}
+	}
+	return users, nil
+}
+
 // getOrGenerateEncryptionKey 获取或生成加密密钥
 func getOrGenerateEncryptionKey(cfg *Config) (string, error) {
 	keyFile := filepath.Join(cfg.DataDir, ".key")
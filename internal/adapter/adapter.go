@@ -1,6 +1,8 @@
 package adapter
 
 import (
+	"context"
+	"dbm/internal/export"
 	"dbm/internal/model"
 	"io"
 )
@@ -27,14 +29,19 @@ type DatabaseAdapter interface {
 	Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error)
 	Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error)
 
+	// 支持取消的 SQL 执行：ctx 取消时在服务端终止正在运行的语句
+	ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error)
+	QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error)
+
 	// 数据编辑
 	Insert(db any, database, table string, data map[string]interface{}) error
-	Update(db any, database, table string, data map[string]interface{}, where string) error
-	Delete(db any, database, table, where string) error
+	// Update 与 Delete 按行标识定位一行数据，行标识匹配多行时拒绝执行，返回影响行数
+	Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error)
+	Delete(db any, database, table string, key model.RowKey) (int64, error)
 
-	// 导出
-	ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error
-	ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error
+	// 导出：逐行读取结果集并写出，不在内存中保留整个结果集
+	ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error
+	ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error
 
 	// 建表语句
 	GetCreateTableSQL(db any, database, table string) (string, error)
@@ -71,6 +78,45 @@ type SchemaAwareDatabase interface {
 	GetRoutineDefinitionWithSchema(db any, database, schema, routineName, routineType string) (string, error)
 }
 
+// ChangeApplier 支持原子应用数据变更集的数据库接口
+type ChangeApplier interface {
+	// ApplyChanges 在单个事务中应用变更集，任一行失败时整体回滚并返回逐行结果
+	// preview 为 true 时只生成语句，不执行。
+	ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error)
+}
+
+// CellAccessor 支持按行标识读写单个单元格完整值的数据库接口
+type CellAccessor interface {
+	// ReadCell 读取单元格的完整值并交给 fn，值为 NULL 时以 nil 调用；data 只在 fn 调用期间有效
+	ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error
+	// WriteCell 使用二进制数据替换单元格的值，返回影响行数
+	WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error)
+}
+
+// BulkInserter 支持批量写入与建表的数据库接口，用于数据导入
+type BulkInserter interface {
+	// InsertRows 使用数据库最快的写入方式（多行 INSERT、COPY、原生批量）写入多行，返回写入行数
+	// rows 中每行的值与 columns 一一对应。出错时返回已写入的行数：
+	// SQL 数据库整批回滚为 0，MongoDB 为出错前已写入的文档数。
+	InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error)
+	// CreateTable 按列定义建表
+	CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error
+}
+
+// RowCounter 支持统计表行数的数据库接口，用于迁移后校验行数
+type RowCounter interface {
+	// CountRows 返回表中的精确行数
+	CountRows(ctx context.Context, db any, database, table string) (int64, error)
+}
+
+// RowStreamer 支持以行迭代器逐行读取查询结果的数据库接口，用于各种格式的导出
+type RowStreamer interface {
+	// StreamQuery 执行查询并将结果以行迭代器交给 fn，fn 返回后关闭结果集
+	StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error
+	// StreamTable 读取表中的数据，limit > 0 时只读取前 limit 行
+	StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error
+}
+
 // AdapterFactory 适配器工厂接口
 type AdapterFactory interface {
 	CreateAdapter(dbType model.DatabaseType) (DatabaseAdapter, error)
@@ -0,0 +1,240 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// redactedLiteral 脱敏后替换字面量的占位符
+const redactedLiteral = "?"
+
+// auditEditors 生成审计描述语句使用的构造器，与各适配器编辑数据时一致
+var auditEditors = map[model.DatabaseType]*rowEditor{
+	model.DatabaseMySQL:      backtickEditor,
+	model.DatabaseClickHouse: clickhouseEditor,
+	model.DatabasePostgreSQL: pgEditor,
+	model.DatabaseKingBase:   pgEditor,
+	model.DatabaseSQLite:     sqliteEditor,
+	model.DatabaseMSSQL:      mssqlEditor,
+	model.DatabaseOracle:     oracleEditor,
+	model.DatabaseDM:         dmEditor,
+}
+
+// DescribeRowEdit 生成按行编辑数据对应的语句（参数内联为字面量），用于审计记录
+// MongoDB 生成等价的命令文档；语句无法生成（如缺少行标识）时返回空字符串。
+func DescribeRowEdit(dbType model.DatabaseType, op model.ChangeOp, table string, data map[string]interface{}, key model.RowKey) string {
+	if dbType == model.DatabaseMongoDB {
+		return describeMongoEdit(op, table, data, key)
+	}
+
+	e, ok := auditEditors[dbType]
+	if !ok {
+		e = &rowEditor{quote: quoteDouble, placeholder: placeholderQuestion}
+	}
+	e = e.display()
+	name := e.ident(table)
+	if dbType == model.DatabasePostgreSQL || dbType == model.DatabaseKingBase {
+		name = pgTable(table)
+	}
+
+	var (
+		stmt rowStatement
+		err  error
+	)
+	switch op {
+	case model.ChangeInsert:
+		stmt = e.Insert(name, data)
+	case model.ChangeUpdate:
+		stmt, err = e.Update(name, data, key)
+	case model.ChangeDelete:
+		stmt, err = e.Delete(name, key)
+	}
+	if err != nil {
+		return ""
+	}
+	return stmt.Query
+}
+
+// describeMongoEdit 生成 insert、update、delete 命令文档
+func describeMongoEdit(op model.ChangeOp, collection string, data map[string]interface{}, key model.RowKey) string {
+	var command bson.D
+	switch op {
+	case model.ChangeInsert:
+		command = bson.D{{Key: "insert", Value: collection}, {Key: "documents", Value: bson.A{sortedDoc(data)}}}
+	case model.ChangeUpdate:
+		command = bson.D{{Key: "update", Value: collection}, {Key: "updates", Value: bson.A{bson.D{
+			{Key: "q", Value: sortedDoc(key)},
+			{Key: "u", Value: bson.D{{Key: "$set", Value: sortedDoc(data)}}},
+		}}}}
+	case model.ChangeDelete:
+		command = bson.D{{Key: "delete", Value: collection}, {Key: "deletes", Value: bson.A{bson.D{
+			{Key: "q", Value: sortedDoc(key)},
+			{Key: "limit", Value: 1},
+		}}}}
+	}
+
+	out, err := bson.MarshalExtJSON(command, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// sortedDoc 按键排序转换为文档，保证描述稳定
+func sortedDoc(m map[string]interface{}) bson.D {
+	doc := make(bson.D, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		doc = append(doc, bson.E{Key: k, Value: m[k]})
+	}
+	return doc
+}
+
+// RedactLiterals 将语句中的字符串与数字字面量替换为 ?，保留关键字、标识符与注释
+// MongoDB 命令替换文档中的所有值（集合名称除外），无法解析为 JSON 的文本原样返回。
+func RedactLiterals(dbType model.DatabaseType, sql string) string {
+	if dbType == model.DatabaseMongoDB {
+		return redactMongo(sql)
+	}
+
+	dialect := splitDialects[dbType]
+	// MySQL 默认将双引号视为字符串
+	doubleQuoteString := dbType == model.DatabaseMySQL
+
+	var b strings.Builder
+	b.Grow(len(sql))
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		rest := sql[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "--") || (dialect.hashComment && c == '#'):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			b.WriteString(rest[:end])
+			i += end
+		case strings.HasPrefix(rest, "/*") && !strings.HasPrefix(rest, "/*!"):
+			end := len(rest)
+			if n := strings.Index(rest[2:], "*/"); n >= 0 {
+				end = n + 4
+			}
+			b.WriteString(rest[:end])
+			i += end
+		case c == '\'' || (c == '"' && doubleQuoteString):
+			i += quotedLength(rest, c, dialect.backslashEscape)
+			b.WriteString(redactedLiteral)
+		case c == '"' || (c == '`' && dialect.backtick):
+			n := quotedLength(rest, c, false)
+			b.WriteString(rest[:n])
+			i += n
+		case c == '[' && dialect.bracket:
+			n := quotedLength(rest, ']', false)
+			b.WriteString(rest[:n])
+			i += n
+		case c == '$' && dialect.dollarQuote && dollarTagRegex.MatchString(rest):
+			tag := dollarTagRegex.FindString(rest)
+			if end := strings.Index(rest[len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(sql)
+			}
+			b.WriteString(redactedLiteral)
+		case c >= '0' && c <= '9':
+			i += numberLength(rest)
+			b.WriteString(redactedLiteral)
+		case isWordChar(c):
+			j := i + 1
+			for j < len(sql) && isWordChar(sql[j]) {
+				j++
+			}
+			// N'...'、X'...'、E'...' 等带前缀的字符串整体替换
+			if j < len(sql) && sql[j] == '\'' && j-i == 1 && strings.ContainsRune("NnXxBbEe", rune(c)) {
+				// PostgreSQL E'...' 中反斜杠为转义符
+				i = j + quotedLength(sql[j:], '\'', dialect.backslashQuoted(sql, j))
+				b.WriteString(redactedLiteral)
+				continue
+			}
+			b.WriteString(sql[i:j])
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// numberLength 返回以数字开头的数值字面量长度（整数、小数、科学计数法与 0x 十六进制）
+func numberLength(rest string) int {
+	if len(rest) > 2 && rest[0] == '0' && (rest[1] == 'x' || rest[1] == 'X') {
+		i := 2
+		for i < len(rest) && strings.IndexByte("0123456789abcdefABCDEF", rest[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+
+	i := 0
+	for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+		i++
+	}
+	if i < len(rest) && (rest[i] == 'e' || rest[i] == 'E') {
+		j := i + 1
+		if j < len(rest) && (rest[j] == '+' || rest[j] == '-') {
+			j++
+		}
+		if j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+			for j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	return i
+}
+
+// redactMongo 替换 MongoDB 命令文档中的值，命令名对应的集合名称保留
+func redactMongo(query string) string {
+	var command bson.D
+	if err := bson.UnmarshalExtJSON([]byte(query), false, &command); err != nil || len(command) == 0 {
+		return query
+	}
+
+	redacted := make(bson.D, len(command))
+	redacted[0] = command[0]
+	if _, ok := command[0].Value.(string); !ok {
+		redacted[0].Value = redactValue(command[0].Value)
+	}
+	for i, e := range command[1:] {
+		redacted[i+1] = bson.E{Key: e.Key, Value: redactValue(e.Value)}
+	}
+
+	out, err := bson.MarshalExtJSON(redacted, false, false)
+	if err != nil {
+		return query
+	}
+	return string(out)
+}
+
+// redactValue 递归替换文档与数组中的标量值
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case bson.D:
+		doc := make(bson.D, len(v))
+		for i, e := range v {
+			doc[i] = bson.E{Key: e.Key, Value: redactValue(e.Value)}
+		}
+		return doc
+	case bson.A:
+		arr := make(bson.A, len(v))
+		for i, item := range v {
+			arr[i] = redactValue(item)
+		}
+		return arr
+	default:
+		return redactedLiteral
+	}
+}
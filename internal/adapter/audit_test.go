@@ -0,0 +1,70 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"testing"
+)
+
+// TestRedactLiterals 测试审计语句的字面量脱敏
+func TestRedactLiterals(t *testing.T) {
+	tests := []struct {
+		dbType model.DatabaseType
+		sql    string
+		want   string
+	}{
+		{model.DatabasePostgreSQL, "SELECT * FROM t1 WHERE name = 'bob' AND age > 30", "SELECT * FROM t1 WHERE name = ? AND age > ?"},
+		{model.DatabasePostgreSQL, `UPDATE "users" SET salary = 1.5e3 WHERE id = 7 -- 'note'`, `UPDATE "users" SET salary = ? WHERE id = ? -- 'note'`},
+		{model.DatabasePostgreSQL, "SELECT $$secret$$, E'a\\'b'", "SELECT ?, ?"},
+		{model.DatabaseMySQL, `INSERT INTO t (a, b) VALUES ("x", 'it\'s'), (0x1F, -2)`, "INSERT INTO t (a, b) VALUES (?, ?), (?, -?)"},
+		{model.DatabaseMySQL, "SELECT `a'b` FROM t WHERE c = 'd'", "SELECT `a'b` FROM t WHERE c = ?"},
+		{model.DatabaseMSSQL, "SELECT [it's] FROM t WHERE n = N'张三'", "SELECT [it's] FROM t WHERE n = ?"},
+	}
+
+	for _, tt := range tests {
+		if got := RedactLiterals(tt.dbType, tt.sql); got != tt.want {
+			t.Errorf("RedactLiterals(%s, %q) = %q, want %q", tt.dbType, tt.sql, got, tt.want)
+		}
+	}
+}
+
+// TestRedactMongo 测试 MongoDB 命令的脱敏
+func TestRedactMongo(t *testing.T) {
+	got := RedactLiterals(model.DatabaseMongoDB, `{"find": "users", "filter": {"name": "bob", "age": {"$gt": 30}}, "limit": 10}`)
+	want := `{"find":"users","filter":{"name":"?","age":{"$gt":"?"}},"limit":"?"}`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// 集合名称不是 JSON，原样返回
+	if got := RedactLiterals(model.DatabaseMongoDB, "users"); got != "users" {
+		t.Errorf("got %s, want users", got)
+	}
+}
+
+// TestDescribeRowEdit 测试按行编辑的审计描述
+func TestDescribeRowEdit(t *testing.T) {
+	data := map[string]interface{}{"name": "bob", "age": float64(3)}
+	key := model.RowKey{"id": float64(1)}
+
+	tests := []struct {
+		dbType model.DatabaseType
+		op     model.ChangeOp
+		want   string
+	}{
+		{model.DatabaseMySQL, model.ChangeInsert, "INSERT INTO `users` (`age`, `name`) VALUES (3, 'bob')"},
+		{model.DatabasePostgreSQL, model.ChangeUpdate, `UPDATE "users" SET "age" = 3, "name" = 'bob' WHERE "id" = 1`},
+		{model.DatabaseClickHouse, model.ChangeDelete, "ALTER TABLE `users` DELETE WHERE `id` = 1"},
+		{model.DatabaseMongoDB, model.ChangeDelete, `{"delete":"users","deletes":[{"q":{"id":1.0},"limit":1}]}`},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeRowEdit(tt.dbType, tt.op, "users", data, key); got != tt.want {
+			t.Errorf("DescribeRowEdit(%s, %s) = %q, want %q", tt.dbType, tt.op, got, tt.want)
+		}
+	}
+
+	// 缺少行标识时无法生成语句
+	if got := DescribeRowEdit(model.DatabaseMySQL, model.ChangeDelete, "users", nil, nil); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+}
@@ -0,0 +1,123 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"fmt"
+	"strings"
+)
+
+// 各数据库单条语句允许的最多绑定参数数
+const (
+	mysqlMaxParams  = 65535
+	sqliteMaxParams = 32766
+	dmMaxParams     = 10000
+)
+
+// insertValues 使用多行 INSERT 在一个事务中批量写入
+// 每条语句的行数受 maxParams 限制，返回写入行数。
+func insertValues(ctx context.Context, db any, e *rowEditor, table string, columns []string, rows [][]any, maxParams int) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	perStmt := max(1, maxParams/max(1, len(columns)))
+
+	cols := make([]string, len(columns))
+	for i, col := range columns {
+		cols[i] = e.ident(col)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(cols, ", "))
+
+	var affected int64
+	err := inTx(ctx, db, func(tx *sql.Tx) error {
+		for start := 0; start < len(rows); start += perStmt {
+			end := min(start+perStmt, len(rows))
+
+			var stmt rowStatement
+			values := make([]string, 0, end-start)
+			for _, row := range rows[start:end] {
+				placeholders := make([]string, len(row))
+				for i, val := range row {
+					placeholders[i] = e.bind(&stmt, val)
+				}
+				values = append(values, "("+strings.Join(placeholders, ", ")+")")
+			}
+
+			result, err := tx.ExecContext(ctx, prefix+strings.Join(values, ", "), stmt.Args...)
+			if err != nil {
+				return err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			affected += n
+		}
+		return nil
+	})
+	return affected, err
+}
+
+// insertPrepared 在事务中复用预编译语句逐行写入
+// 用于 COPY（PostgreSQL、KingBase）、原生批量（ClickHouse）与 bulk copy（SQL Server）：
+// 驱动在 Exec 时缓冲行数据，最后一次无参数 Exec（flush 为 true 时）或提交时发送。
+func insertPrepared(ctx context.Context, db any, query string, rows [][]any, flush bool) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	err := inTx(ctx, db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			if _, err := stmt.ExecContext(ctx, row...); err != nil {
+				return err
+			}
+		}
+		if flush {
+			if _, err := stmt.ExecContext(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(rows)), nil
+}
+
+// inTx 在事务中执行 fn，出错时回滚
+func inTx(ctx context.Context, db any, fn func(tx *sql.Tx) error) error {
+	tx, err := db.(*sql.DB).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// createTable 按列定义建表，columnType 为各数据库的列类型构造函数
+func createTable(ctx context.Context, db any, quote func(string) string, table string, columns []model.ColumnDef, columnType func(col *model.ColumnDef) string, suffix string) error {
+	defs := make([]string, len(columns))
+	for i := range columns {
+		defs[i] = quote(columns[i].Name) + " " + columnType(&columns[i])
+	}
+	query := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)%s", table, strings.Join(defs, ",\n  "), suffix)
+	_, err := db.(*sql.DB).ExecContext(ctx, query)
+	return err
+}
+
+// countRows 使用 COUNT(*) 统计表的行数
+func countRows(ctx context.Context, db any, table string) (int64, error) {
+	var n int64
+	err := db.(*sql.DB).QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n)
+	return n, err
+}
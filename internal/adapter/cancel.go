@@ -0,0 +1,109 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// killTimeout 发送服务端终止语句的超时时间
+const killTimeout = 5 * time.Second
+
+// sqlQueryer *sql.DB、*sql.Conn、*sql.Tx 的公共执行接口
+type sqlQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sessionKiller 描述如何在服务端终止某个会话正在执行的语句
+type sessionKiller struct {
+	// sessionIDQuery 查询当前会话 ID 的语句
+	sessionIDQuery string
+	// killStatement 根据会话 ID 生成终止语句（通过另一条连接执行）
+	killStatement func(sessionID string) string
+}
+
+var (
+	// mysqlKiller MySQL 使用 KILL QUERY 终止当前语句但保留连接
+	mysqlKiller = &sessionKiller{
+		sessionIDQuery: "SELECT CONNECTION_ID()",
+		killStatement: func(id string) string {
+			return "KILL QUERY " + id
+		},
+	}
+
+	// pgKiller PostgreSQL / KingBase 使用 pg_cancel_backend 取消后端当前语句
+	pgKiller = &sessionKiller{
+		sessionIDQuery: "SELECT pg_backend_pid()",
+		killStatement: func(id string) string {
+			return "SELECT pg_cancel_backend(" + id + ")"
+		},
+	}
+
+	// dmKiller 达梦使用 SP_CANCEL_SESSION_OPERATION 取消会话当前操作
+	dmKiller = &sessionKiller{
+		sessionIDQuery: "SELECT SESSID()",
+		killStatement: func(id string) string {
+			return "CALL SP_CANCEL_SESSION_OPERATION(" + id + ")"
+		},
+	}
+)
+
+// runWithKill 在独立会话上执行 fn
+// 当 ctx 被取消时，通过连接池中的另一条连接在服务端终止该会话正在执行的语句，
+// 避免客户端放弃等待后查询仍在数据库中继续运行。
+// db 为 *Session 时在会话连接上执行；ctx 不可取消或 killer 为空时直接在连接池上执行。
+func runWithKill(ctx context.Context, db any, killer *sessionKiller, fn func(q sqlQueryer) error) error {
+	if s, ok := db.(*Session); ok {
+		return s.run(ctx, killer, fn)
+	}
+
+	dbSQL := db.(*sql.DB)
+	if killer == nil || ctx.Done() == nil {
+		return fn(dbSQL)
+	}
+
+	conn, err := dbSQL.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var sessionID string
+	if err := conn.QueryRowContext(ctx, killer.sessionIDQuery).Scan(&sessionID); err != nil {
+		return err
+	}
+
+	stop := watchKill(ctx, dbSQL, killer, sessionID)
+	err = fn(conn)
+	// 等待监视协程退出后再归还连接，防止终止语句误伤后续复用该连接的查询
+	stop()
+
+	return err
+}
+
+// watchKill 监视 ctx，取消时通过 dbSQL 终止指定服务端会话的语句
+// 返回的 stop 会等待监视协程退出。
+func watchKill(ctx context.Context, dbSQL *sql.DB, killer *sessionKiller, sessionID string) (stop func()) {
+	done := make(chan struct{})
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		select {
+		case <-ctx.Done():
+			killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+			defer cancel()
+			if _, err := dbSQL.ExecContext(killCtx, killer.killStatement(sessionID)); err != nil {
+				log.Printf("终止会话 %s 的查询失败: %v", sessionID, err)
+			}
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-watcherDone
+	}
+}
@@ -0,0 +1,84 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+)
+
+// ErrRowNotFound 行标识没有匹配的行
+var ErrRowNotFound = errors.New("未找到行标识对应的数据")
+
+// Select 生成按行标识读取单列的语句
+func (e *rowEditor) Select(table, column string, key model.RowKey) (rowStatement, error) {
+	var stmt rowStatement
+	where, err := e.where(&stmt, key)
+	if err != nil {
+		return stmt, err
+	}
+
+	stmt.Query = fmt.Sprintf("SELECT %s FROM %s WHERE %s", e.ident(column), table, where)
+	return stmt, nil
+}
+
+// readCell 按行标识读取单元格的完整值并交给 fn
+// 先确认行标识只匹配一行，再以 sql.RawBytes 读取，值不复制到新的缓冲区；
+// 值为 NULL 时以 nil 调用 fn，data 只在 fn 调用期间有效。
+func readCell(ctx context.Context, db any, e *rowEditor, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	stmt, err := e.Select(table, column, key)
+	if err != nil {
+		return err
+	}
+	count, err := e.Count(table, key)
+	if err != nil {
+		return err
+	}
+
+	dbSQL := db.(*sql.DB)
+	n, err := checkUnique(ctx, dbSQL, count)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRowNotFound
+	}
+
+	rows, err := dbSQL.QueryContext(ctx, stmt.Query, stmt.Args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return ErrRowNotFound
+	}
+	var value sql.Null[sql.RawBytes]
+	if err := rows.Scan(&value); err != nil {
+		return err
+	}
+	if !value.Valid {
+		return fn(nil)
+	}
+	if value.V == nil {
+		return fn([]byte{})
+	}
+	return fn(value.V)
+}
+
+// writeCell 按行标识替换单元格的值，返回影响行数
+func writeCell(ctx context.Context, db any, e *rowEditor, table, column string, key model.RowKey, data []byte) (int64, error) {
+	stmt, err := e.Update(table, map[string]interface{}{column: data}, key)
+	if err != nil {
+		return 0, err
+	}
+	count, err := e.Count(table, key)
+	if err != nil {
+		return 0, err
+	}
+	return execKeyedTx(ctx, db, count, stmt)
+}
@@ -0,0 +1,96 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestTruncateValue 测试超长值按字符边界截断
+func TestTruncateValue(t *testing.T) {
+	preview, size, ok := truncateValue("数据库管理", 7)
+	if !ok || preview != "数据" || size != 15 {
+		t.Errorf("truncateValue(string) = %q, %d, %v", preview, size, ok)
+	}
+
+	preview, size, ok = truncateValue([]byte{0xFF, 0xFE, 0xFD}, 2)
+	if !ok || !bytes.Equal(preview.([]byte), []byte{0xFF, 0xFE}) || size != 3 {
+		t.Errorf("truncateValue(binary) = %v, %d, %v", preview, size, ok)
+	}
+
+	if _, _, ok := truncateValue(int64(1), 1); ok {
+		t.Error("non-string values should not be truncated")
+	}
+}
+
+// TestSQLite_Cell 测试查询结果截断与单元格完整值读写
+func TestSQLite_Cell(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "cell.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	a := NewSQLiteAdapter()
+	if _, err := a.Execute(db, "CREATE TABLE files (id INTEGER PRIMARY KEY, body TEXT, data BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	body := strings.Repeat("x", 100)
+	if _, err := db.Exec("INSERT INTO files VALUES (1, ?, NULL)", body); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := a.Query(db, "SELECT id, body FROM files", &model.QueryOptions{MaxValueSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Rows[0][1]; got != body[:10] {
+		t.Errorf("preview = %v, want %q", got, body[:10])
+	}
+	if want := (model.TruncatedCell{Row: 0, Column: 1, Size: 100}); len(result.TruncatedCells) != 1 || result.TruncatedCells[0] != want {
+		t.Errorf("TruncatedCells = %+v, want [%+v]", result.TruncatedCells, want)
+	}
+
+	key := model.RowKey{"id": 1}
+	full, err := readCellBytes(ctx, a, db, "files", "body", key)
+	if err != nil || string(full) != body {
+		t.Errorf("ReadCell(body) = %d bytes, %v, want %d bytes", len(full), err, len(body))
+	}
+
+	if data, err := readCellBytes(ctx, a, db, "files", "data", key); err != nil || data != nil {
+		t.Errorf("ReadCell(NULL) = %v, %v, want nil, nil", data, err)
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00")
+	affected, err := a.WriteCell(ctx, db, "", "files", "data", key, png)
+	if err != nil || affected != 1 {
+		t.Fatalf("WriteCell() = %d, %v, want 1, nil", affected, err)
+	}
+	if data, err := readCellBytes(ctx, a, db, "files", "data", key); err != nil || !bytes.Equal(data, png) {
+		t.Errorf("ReadCell(data) = %v, %v, want %v", data, err, png)
+	}
+
+	if _, err := readCellBytes(ctx, a, db, "files", "data", model.RowKey{"id": 2}); !errors.Is(err, ErrRowNotFound) {
+		t.Errorf("ReadCell(missing) = %v, want ErrRowNotFound", err)
+	}
+}
+
+// readCellBytes 读取单元格并复制值，NULL 返回 nil
+func readCellBytes(ctx context.Context, a CellAccessor, db any, table, column string, key model.RowKey) ([]byte, error) {
+	var value []byte
+	err := a.ReadCell(ctx, db, "", table, column, key, func(data []byte) error {
+		if data != nil {
+			value = bytes.Clone(data)
+		}
+		return nil
+	})
+	return value, err
+}
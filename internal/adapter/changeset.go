@@ -0,0 +1,163 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// changeStatement 变更集中单行对应的语句
+type changeStatement struct {
+	stmt rowStatement
+	// count 更新与删除时校验行标识唯一性的统计语句
+	count *rowStatement
+}
+
+// display 返回参数内联为字面量的构造器，用于生成预览语句
+func (e *rowEditor) display() *rowEditor {
+	d := *e
+	d.inline = true
+	return &d
+}
+
+// changeStatements 按删除、更新、插入的顺序生成变更集语句与逐行结果
+// 语句生成失败的行记录错误，此时返回的 ok 为 false。
+func (e *rowEditor) changeStatements(table string, cs *model.ChangeSet) (stmts []changeStatement, rows []model.RowChangeResult, ok bool) {
+	ok = true
+	view := e.display()
+
+	add := func(op model.ChangeOp, index int, build func(e *rowEditor) (rowStatement, error), key model.RowKey) {
+		row := model.RowChangeResult{Op: op, Index: index, Status: model.StatementPending}
+		stmt, err := build(e)
+		if err == nil {
+			var shown rowStatement
+			shown, err = build(view)
+			row.Statement = shown.Query
+		}
+		var count *rowStatement
+		if err == nil && key != nil {
+			var c rowStatement
+			c, err = e.Count(table, key)
+			count = &c
+		}
+		if err != nil {
+			row.Status = model.StatementError
+			row.Error = err.Error()
+			ok = false
+		}
+		stmts = append(stmts, changeStatement{stmt: stmt, count: count})
+		rows = append(rows, row)
+	}
+
+	for i, key := range cs.Deletes {
+		add(model.ChangeDelete, i, func(e *rowEditor) (rowStatement, error) {
+			return e.Delete(table, key)
+		}, key)
+	}
+	for i, change := range cs.Updates {
+		add(model.ChangeUpdate, i, func(e *rowEditor) (rowStatement, error) {
+			return e.Update(table, change.Data, change.Key)
+		}, change.Key)
+	}
+	for i, data := range cs.Inserts {
+		add(model.ChangeInsert, i, func(e *rowEditor) (rowStatement, error) {
+			if len(data) == 0 {
+				return rowStatement{}, fmt.Errorf("插入的行没有列值")
+			}
+			return e.Insert(table, data), nil
+		}, nil)
+	}
+	return stmts, rows, ok
+}
+
+// applyChanges 在单个事务中应用变更集
+// 任一行失败时回滚事务，该行记录错误，之后的行标记为未执行。
+func applyChanges(ctx context.Context, db any, e *rowEditor, table string, cs *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	start := time.Now()
+	stmts, rows, ok := e.changeStatements(table, cs)
+	result := &model.ChangeSetResult{Rows: rows}
+	if preview || !ok {
+		result.TimeCost = time.Since(start)
+		return result, nil
+	}
+
+	tx, err := db.(*sql.DB).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, s := range stmts {
+		row := &result.Rows[i]
+		affected, err := s.exec(ctx, tx)
+		if err != nil {
+			_ = tx.Rollback()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			row.Status = model.StatementError
+			row.Error = err.Error()
+			for j := i + 1; j < len(result.Rows); j++ {
+				result.Rows[j].Status = model.StatementSkipped
+			}
+			result.RowsAffected = 0
+			result.TimeCost = time.Since(start)
+			return result, nil
+		}
+		row.Status = model.StatementSuccess
+		row.RowsAffected = affected
+		result.RowsAffected += affected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result.Committed = true
+	result.TimeCost = time.Since(start)
+	return result, nil
+}
+
+// exec 执行单行语句，更新与删除时先校验行标识唯一
+func (s changeStatement) exec(ctx context.Context, tx *sql.Tx) (int64, error) {
+	if s.count != nil {
+		return execKeyed(ctx, tx, *s.count, s.stmt)
+	}
+	res, err := tx.ExecContext(ctx, s.stmt.Query, s.stmt.Args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// sqlLiteral 将参数值格式化为 SQL 字面量，仅用于预览展示
+func sqlLiteral(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05") + "'"
+	case fmt.Stringer:
+		return sqlLiteral(v.String())
+	default:
+		return sqlLiteral(fmt.Sprint(v))
+	}
+}
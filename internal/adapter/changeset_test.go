@@ -0,0 +1,109 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestSQLLiteral 测试预览语句的字面量格式化
+func TestSQLLiteral(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{nil, "NULL"},
+		{"O'Brien", "'O''Brien'"},
+		{float64(42), "42"},
+		{1.5, "1.5"},
+		{true, "TRUE"},
+		{[]byte{0xAB, 0x01}, "X'AB01'"},
+	}
+
+	for _, tt := range tests {
+		if got := sqlLiteral(tt.value); got != tt.want {
+			t.Errorf("sqlLiteral(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestSQLite_ApplyChanges 测试变更集预览、提交与出错回滚
+func TestSQLite_ApplyChanges(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "changes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	a := NewSQLiteAdapter()
+	if _, err := a.Execute(db, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Execute(db, "INSERT INTO users VALUES (1, 'a'), (2, 'b')"); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := &model.ChangeSet{
+		Inserts: []map[string]interface{}{{"id": 3, "name": "c"}},
+		Updates: []model.RowChange{{Key: model.RowKey{"id": 1}, Data: map[string]interface{}{"name": "a'"}}},
+		Deletes: []model.RowKey{{"id": 2}},
+	}
+
+	preview, err := a.ApplyChanges(ctx, db, "", "users", changes, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStatements := []string{
+		"DELETE FROM `users` WHERE `id` = 2",
+		"UPDATE `users` SET `name` = 'a''' WHERE `id` = 1",
+		"INSERT INTO `users` (`id`, `name`) VALUES (3, 'c')",
+	}
+	for i, want := range wantStatements {
+		if got := preview.Rows[i].Statement; got != want {
+			t.Errorf("preview[%d] = %q, want %q", i, got, want)
+		}
+		if preview.Rows[i].Status != model.StatementPending {
+			t.Errorf("preview[%d] status = %s, want pending", i, preview.Rows[i].Status)
+		}
+	}
+	if preview.Committed {
+		t.Error("preview should not commit")
+	}
+
+	result, err := a.ApplyChanges(ctx, db, "", "users", changes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Committed || result.RowsAffected != 3 {
+		t.Fatalf("ApplyChanges() committed = %v, rowsAffected = %d, want true, 3", result.Committed, result.RowsAffected)
+	}
+
+	// 第二行插入主键冲突，整个变更集回滚
+	failing := &model.ChangeSet{
+		Inserts: []map[string]interface{}{{"id": 4, "name": "d"}, {"id": 1, "name": "dup"}, {"id": 5, "name": "e"}},
+		Deletes: []model.RowKey{{"id": 3}},
+	}
+	result, err = a.ApplyChanges(ctx, db, "", "users", failing, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Committed {
+		t.Error("failing change set should not commit")
+	}
+	wantStatus := []model.StatementStatus{model.StatementSuccess, model.StatementSuccess, model.StatementError, model.StatementSkipped}
+	for i, want := range wantStatus {
+		if got := result.Rows[i].Status; got != want {
+			t.Errorf("row %d status = %s, want %s (%s)", i, got, want, result.Rows[i].Error)
+		}
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE id IN (3, 4)").Scan(&count); err != nil || count != 1 {
+		t.Errorf("rollback failed: rows with id 3, 4 = %d, %v, want 1", count, err)
+	}
+}
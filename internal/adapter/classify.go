@@ -0,0 +1,617 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// StatementClass 语句类别，按影响范围从小到大排序
+type StatementClass int
+
+const (
+	// StatementQuery 只读查询（SELECT、SHOW、EXPLAIN、事务控制等）
+	StatementQuery StatementClass = iota
+	// StatementDML 修改数据（INSERT、UPDATE、DELETE 等）
+	StatementDML
+	// StatementDDL 修改结构或无法判断的语句
+	StatementDDL
+)
+
+// String 返回类别名称
+func (c StatementClass) String() string {
+	switch c {
+	case StatementQuery:
+		return "query"
+	case StatementDML:
+		return "dml"
+	default:
+		return "ddl"
+	}
+}
+
+// 语句开头关键字的类别，未列出的关键字视为 DDL
+var (
+	queryKeywords = map[string]bool{
+		"SELECT": true, "WITH": true, "SHOW": true, "DESC": true, "DESCRIBE": true, "EXPLAIN": true,
+		"VALUES": true, "TABLE": true, "DECLARE": true, "FETCH": true, "PRINT": true,
+		"BEGIN": true, "START": true, "COMMIT": true, "ROLLBACK": true, "SAVEPOINT": true, "RELEASE": true, "END": true,
+		"PRAGMA": true,
+	}
+	dmlKeywords = map[string]bool{
+		"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "REPLACE": true, "UPSERT": true,
+		"LOAD": true, "COPY": true, "LOCK": true, "USE": true,
+	}
+	// procedureKeywords 调用存储过程或执行动态 SQL，按 classifyProcedure 判断
+	procedureKeywords = map[string]bool{"CALL": true, "EXEC": true, "EXECUTE": true}
+	// beginTransaction BEGIN 之后表示开始事务的关键字，其他 BEGIN 开始匿名块
+	beginTransaction = map[string]bool{
+		"": true, ";": true, "TRANSACTION": true, "TRAN": true, "WORK": true, "DISTRIBUTED": true,
+		"ISOLATION": true, "READ": true, "NOT": true, "DEFERRABLE": true, "DEFERRED": true, "IMMEDIATE": true, "EXCLUSIVE": true,
+	}
+)
+
+// plsqlDialects 以 BEGIN、DECLARE 开始 PL/SQL 匿名块的数据库
+var plsqlDialects = map[model.DatabaseType]bool{
+	model.DatabaseOracle: true, model.DatabaseDM: true,
+}
+
+// queryPragmas 以表名或索引名为参数、只读取结构信息的 SQLite PRAGMA
+var queryPragmas = map[string]bool{
+	"TABLE_INFO": true, "TABLE_XINFO": true, "TABLE_LIST": true, "INDEX_LIST": true, "INDEX_INFO": true,
+	"INDEX_XINFO": true, "FOREIGN_KEY_LIST": true, "FOREIGN_KEY_CHECK": true, "INTEGRITY_CHECK": true,
+	"QUICK_CHECK": true,
+}
+
+// queryProcedures 只读取信息的 SQL Server 系统存储过程，可不加限定名或以 sys. 限定
+var queryProcedures = map[string]bool{
+	"SP_HELP": true, "SP_HELPTEXT": true, "SP_HELPINDEX": true, "SP_HELPCONSTRAINT": true, "SP_HELPDB": true,
+	"SP_COLUMNS": true, "SP_TABLES": true, "SP_PKEYS": true, "SP_FKEYS": true, "SP_STORED_PROCEDURES": true,
+	"SP_DATABASES": true, "SP_SPACEUSED": true, "SP_WHO": true, "SP_WHO2": true, "SP_LOCK": true,
+}
+
+// 语句任意位置出现即提升类别的关键字（用于识别 CTE 中的 DML、批次中的多条语句、PL/SQL 块等）
+var (
+	anywhereDDL = map[string]bool{
+		"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "GRANT": true, "REVOKE": true,
+	}
+	anywhereDML = map[string]bool{
+		"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true, "REPLACE": true,
+	}
+	// mssqlAnywhereDDL SQL Server 批次中语句不必以分号分隔
+	mssqlAnywhereDDL = map[string]bool{
+		"DBCC": true, "BACKUP": true, "RESTORE": true, "KILL": true, "SHUTDOWN": true, "RECONFIGURE": true, "DENY": true,
+	}
+)
+
+// pureFunctionDialects 函数不能修改数据的数据库，函数调用只按 sideEffectFunctions 判断
+// SQLite 与 ClickHouse 不能通过 SQL 创建函数修改数据，SQL Server 的函数中不允许修改数据。
+var pureFunctionDialects = map[model.DatabaseType]bool{
+	model.DatabaseSQLite: true, model.DatabaseClickHouse: true, model.DatabaseMSSQL: true,
+}
+
+// sideEffectFunctions 有副作用的内置函数：管理服务器、切换会话设置、读写服务器文件、执行动态 SQL 或访问外部数据源
+var sideEffectFunctions = map[string]bool{
+	// PostgreSQL、人大金仓
+	"PG_TERMINATE_BACKEND": true, "PG_CANCEL_BACKEND": true, "PG_RELOAD_CONF": true, "PG_ROTATE_LOGFILE": true,
+	"PG_PROMOTE": true, "PG_SWITCH_WAL": true, "PG_SWITCH_XLOG": true, "PG_CREATE_RESTORE_POINT": true,
+	"PG_START_BACKUP": true, "PG_STOP_BACKUP": true, "PG_BACKUP_START": true, "PG_BACKUP_STOP": true,
+	"PG_WAL_REPLAY_PAUSE": true, "PG_WAL_REPLAY_RESUME": true,
+	"PG_CREATE_PHYSICAL_REPLICATION_SLOT": true, "PG_CREATE_LOGICAL_REPLICATION_SLOT": true, "PG_DROP_REPLICATION_SLOT": true,
+	"PG_STAT_RESET": true, "PG_STAT_RESET_SHARED": true,
+	"PG_READ_FILE": true, "PG_READ_BINARY_FILE": true, "PG_LS_DIR": true, "PG_STAT_FILE": true,
+	"PG_FILE_WRITE": true, "PG_FILE_RENAME": true, "PG_FILE_UNLINK": true,
+	"SET_CONFIG": true, "LO_IMPORT": true, "LO_EXPORT": true,
+	"DBLINK": true, "DBLINK_EXEC": true, "DBLINK_CONNECT": true, "DBLINK_CONNECT_U": true, "DBLINK_SEND_QUERY": true,
+	"QUERY_TO_XML": true, "QUERY_TO_XMLSCHEMA": true, "QUERY_TO_XML_AND_XMLSCHEMA": true,
+	// MySQL
+	"LOAD_FILE": true,
+	// SQLite
+	"LOAD_EXTENSION": true, "READFILE": true, "WRITEFILE": true,
+	// SQL Server
+	"OPENQUERY": true, "OPENROWSET": true, "OPENDATASOURCE": true,
+	// ClickHouse 表函数
+	"FILE": true, "URL": true, "S3": true, "HDFS": true, "REMOTE": true, "REMOTESECURE": true, "CLUSTER": true,
+	"MYSQL": true, "POSTGRESQL": true, "JDBC": true, "ODBC": true, "EXECUTABLE": true, "AZUREBLOBSTORAGE": true,
+}
+
+// queryFunctions 可出现在括号前的关键字、类型名与无副作用的常用内置函数
+var queryFunctions = map[string]bool{
+	// 关键字
+	"ALL": true, "AND": true, "ANY": true, "ARRAY": true, "AS": true, "BETWEEN": true, "BY": true, "CASE": true,
+	"CUBE": true, "CURSOR": true, "DISTINCT": true, "ELSE": true, "ELSEIF": true, "ELSIF": true, "EXCEPT": true,
+	"EXISTS": true, "FILTER": true, "FOR": true, "FROM": true, "GROUP": true, "GROUPING": true, "HAVING": true,
+	"IF": true, "IN": true, "INTERSECT": true, "IS": true, "JOIN": true, "KEEP": true, "LATERAL": true, "LIKE": true,
+	"LIMIT": true, "MATCH_RECOGNIZE": true, "MATERIALIZED": true, "MINUS": true, "MULTISET": true, "NOT": true,
+	"OF": true, "OFFSET": true, "ON": true, "ONLY": true, "OR": true, "OVER": true, "OVERLAPS": true, "PARTITION": true,
+	"PATTERN": true, "PIVOT": true, "PRIOR": true, "RETURN": true, "RETURNING": true, "ROLLUP": true, "ROW": true,
+	"SAMPLE": true, "SELECT": true, "SETS": true, "SOME": true, "TABLE": true, "TABLESAMPLE": true, "BERNOULLI": true,
+	"SYSTEM": true, "REPEATABLE": true, "THEN": true, "TOP": true, "UNION": true, "UNPIVOT": true, "USING": true,
+	"VALUES": true, "WHEN": true, "WHERE": true, "WHILE": true, "WITH": true, "WITHIN": true, "COLUMNS": true,
+	"INDEX": true, "KEY": true, "MATCH": true, "AGAINST": true,
+	// 类型
+	"BIGINT": true, "BINARY": true, "BIT": true, "CHAR": true, "CHARACTER": true, "DEC": true, "DECIMAL": true,
+	"DATETIME2": true, "DATETIMEOFFSET": true, "DOUBLE": true, "FLOAT": true, "INT": true, "INTEGER": true,
+	"INTERVAL": true, "NCHAR": true, "NUMBER": true, "NUMERIC": true, "NVARCHAR": true, "NVARCHAR2": true,
+	"PRECISION": true, "RAW": true, "REAL": true, "SMALLINT": true, "TIME": true, "TIMESTAMP": true, "TINYINT": true,
+	"VARBINARY": true, "VARBIT": true, "VARCHAR": true, "VARCHAR2": true, "VARYING": true, "BPCHAR": true,
+	// 聚合与窗口函数
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true, "STDDEV": true, "STDDEV_POP": true,
+	"STDDEV_SAMP": true, "VARIANCE": true, "VAR_POP": true, "VAR_SAMP": true, "MEDIAN": true, "MODE": true,
+	"CORR": true, "COVAR_POP": true, "COVAR_SAMP": true, "PERCENTILE_CONT": true, "PERCENTILE_DISC": true,
+	"GROUP_CONCAT": true, "STRING_AGG": true, "ARRAY_AGG": true, "LISTAGG": true, "WM_CONCAT": true,
+	"JSON_AGG": true, "JSONB_AGG": true, "JSON_OBJECT_AGG": true, "JSONB_OBJECT_AGG": true, "JSON_ARRAYAGG": true,
+	"JSON_OBJECTAGG": true, "XMLAGG": true, "BOOL_AND": true, "BOOL_OR": true, "EVERY": true, "BIT_AND": true,
+	"BIT_OR": true, "BIT_XOR": true, "ROW_NUMBER": true, "RANK": true, "DENSE_RANK": true, "PERCENT_RANK": true,
+	"CUME_DIST": true, "NTILE": true, "LAG": true, "LEAD": true, "FIRST_VALUE": true, "LAST_VALUE": true,
+	"NTH_VALUE": true, "RATIO_TO_REPORT": true,
+	// 条件与类型转换
+	"COALESCE": true, "NULLIF": true, "IFNULL": true, "NVL": true, "NVL2": true, "ISNULL": true, "IIF": true,
+	"DECODE": true, "GREATEST": true, "LEAST": true, "LNNVL": true, "NANVL": true, "CAST": true, "CONVERT": true,
+	"TRY_CAST": true, "TO_CHAR": true, "TO_DATE": true, "TO_NUMBER": true, "TO_TIMESTAMP": true, "TO_TIMESTAMP_TZ": true,
+	"TO_CLOB": true, "TO_NCHAR": true, "TO_JSON": true, "TO_JSONB": true, "TO_HEX": true, "HEXTORAW": true,
+	"RAWTOHEX": true, "ROWIDTOCHAR": true, "CHARTOROWID": true,
+	// 字符串
+	"CONCAT": true, "CONCAT_WS": true, "LENGTH": true, "LEN": true, "CHAR_LENGTH": true, "CHARACTER_LENGTH": true,
+	"OCTET_LENGTH": true, "BIT_LENGTH": true, "DATALENGTH": true, "LOWER": true, "UPPER": true, "LCASE": true,
+	"UCASE": true, "INITCAP": true, "TRIM": true, "LTRIM": true, "RTRIM": true, "BTRIM": true, "LPAD": true,
+	"RPAD": true, "SUBSTR": true, "SUBSTRING": true, "SUBSTRING_INDEX": true, "MID": true, "LEFT": true,
+	"RIGHT": true, "REPLACE": true, "INSERT": true, "TRANSLATE": true, "REVERSE": true, "REPEAT": true,
+	"SPACE": true, "POSITION": true, "STRPOS": true, "INSTR": true, "LOCATE": true, "SPLIT_PART": true,
+	"OVERLAY": true, "FORMAT": true, "ASCII": true, "CHR": true, "FIELD": true, "FIND_IN_SET": true, "ELT": true,
+	"HEX": true, "UNHEX": true, "MD5": true, "SHA1": true, "SHA2": true, "ENCODE": true, "SOUNDEX": true,
+	"QUOTE_IDENT": true, "QUOTE_LITERAL": true, "QUOTE_NULLABLE": true, "STRING_TO_ARRAY": true,
+	"ARRAY_TO_STRING": true, "REGEXP_REPLACE": true, "REGEXP_SUBSTR": true, "REGEXP_INSTR": true,
+	"REGEXP_LIKE": true, "REGEXP_COUNT": true, "REGEXP_MATCH": true, "REGEXP_MATCHES": true,
+	"REGEXP_SPLIT_TO_ARRAY": true, "REGEXP_SPLIT_TO_TABLE": true,
+	// 数值
+	"ABS": true, "CEIL": true, "CEILING": true, "FLOOR": true, "ROUND": true, "TRUNC": true, "TRUNCATE": true,
+	"MOD": true, "DIV": true, "POWER": true, "POW": true, "SQRT": true, "CBRT": true, "EXP": true, "LN": true,
+	"LOG": true, "LOG10": true, "LOG2": true, "SIGN": true, "PI": true, "RAND": true, "RANDOM": true, "SIN": true,
+	"COS": true, "TAN": true, "COT": true, "ASIN": true, "ACOS": true, "ATAN": true, "ATAN2": true,
+	"DEGREES": true, "RADIANS": true, "WIDTH_BUCKET": true, "GCD": true, "LCM": true, "CONV": true, "BIN": true,
+	"OCT": true, "CRC32": true, "BITAND": true,
+	// 日期与时间
+	"NOW": true, "CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true, "LOCALTIME": true,
+	"LOCALTIMESTAMP": true, "SYSDATE": true, "SYSTIMESTAMP": true, "CURDATE": true, "CURTIME": true,
+	"UTC_DATE": true, "UTC_TIME": true, "UTC_TIMESTAMP": true, "CLOCK_TIMESTAMP": true,
+	"STATEMENT_TIMESTAMP": true, "TRANSACTION_TIMESTAMP": true, "DATE": true, "DATETIME": true, "YEAR": true,
+	"MONTH": true, "DAY": true, "HOUR": true, "MINUTE": true, "SECOND": true, "MICROSECOND": true, "QUARTER": true,
+	"WEEK": true, "WEEKDAY": true, "DAYOFWEEK": true, "DAYOFMONTH": true, "DAYOFYEAR": true, "DAYNAME": true,
+	"MONTHNAME": true, "LAST_DAY": true, "EXTRACT": true, "DATE_PART": true, "DATE_TRUNC": true,
+	"DATE_FORMAT": true, "DATE_ADD": true, "DATE_SUB": true, "ADDDATE": true, "SUBDATE": true, "ADDTIME": true,
+	"SUBTIME": true, "DATEDIFF": true, "TIMEDIFF": true, "TIMESTAMPDIFF": true, "TIMESTAMPADD": true,
+	"STR_TO_DATE": true, "FROM_UNIXTIME": true, "UNIX_TIMESTAMP": true, "TO_DAYS": true, "FROM_DAYS": true,
+	"CONVERT_TZ": true, "AGE": true, "MAKE_DATE": true, "MAKE_TIMESTAMP": true, "MAKE_INTERVAL": true,
+	"ADD_MONTHS": true, "MONTHS_BETWEEN": true, "NEXT_DAY": true, "NUMTODSINTERVAL": true, "NUMTOYMINTERVAL": true,
+	"TIMEZONE": true, "STRFTIME": true, "JULIANDAY": true,
+	// JSON 与数组
+	"JSON_EXTRACT": true, "JSON_UNQUOTE": true, "JSON_OBJECT": true, "JSON_ARRAY": true, "JSON_CONTAINS": true,
+	"JSON_CONTAINS_PATH": true, "JSON_KEYS": true, "JSON_LENGTH": true, "JSON_TYPE": true, "JSON_VALID": true,
+	"JSON_VALUE": true, "JSON_QUERY": true, "JSON_TABLE": true, "JSON_SEARCH": true, "JSON_SET": true,
+	"JSON_INSERT": true, "JSON_REPLACE": true, "JSON_REMOVE": true, "JSON_MERGE_PATCH": true, "JSON_QUOTE": true,
+	"JSON_BUILD_OBJECT": true, "JSON_BUILD_ARRAY": true, "JSONB_BUILD_OBJECT": true, "JSONB_BUILD_ARRAY": true,
+	"JSON_EACH": true, "JSONB_EACH": true, "JSON_EACH_TEXT": true, "JSONB_EACH_TEXT": true,
+	"JSON_ARRAY_ELEMENTS": true, "JSONB_ARRAY_ELEMENTS": true, "JSON_ARRAY_ELEMENTS_TEXT": true,
+	"JSONB_ARRAY_ELEMENTS_TEXT": true, "JSON_ARRAY_LENGTH": true, "JSONB_ARRAY_LENGTH": true,
+	"JSON_OBJECT_KEYS": true, "JSONB_OBJECT_KEYS": true, "JSON_TYPEOF": true, "JSONB_TYPEOF": true,
+	"JSON_EXTRACT_PATH": true, "JSON_EXTRACT_PATH_TEXT": true, "JSONB_EXTRACT_PATH": true,
+	"JSONB_EXTRACT_PATH_TEXT": true, "JSONB_SET": true, "JSONB_PRETTY": true, "JSONB_PATH_QUERY": true,
+	"JSONB_PATH_EXISTS": true, "ROW_TO_JSON": true, "ARRAY_TO_JSON": true, "ARRAY_LENGTH": true,
+	"ARRAY_UPPER": true, "ARRAY_LOWER": true, "ARRAY_APPEND": true, "ARRAY_PREPEND": true, "ARRAY_CAT": true,
+	"ARRAY_POSITION": true, "ARRAY_REMOVE": true, "CARDINALITY": true, "UNNEST": true, "GENERATE_SERIES": true,
+	"XMLELEMENT": true, "XMLFOREST": true, "XMLTABLE": true, "EXTRACTVALUE": true,
+	// 会话与系统信息
+	"VERSION": true, "DATABASE": true, "SCHEMA": true, "USER": true, "CURRENT_USER": true, "SESSION_USER": true,
+	"CURRENT_SCHEMA": true, "CURRENT_SCHEMAS": true, "CURRENT_DATABASE": true, "CONNECTION_ID": true,
+	"LAST_INSERT_ID": true, "FOUND_ROWS": true, "ROW_COUNT": true, "USERENV": true, "SYS_CONTEXT": true,
+	"SYS_GUID": true, "UUID": true, "GEN_RANDOM_UUID": true, "CURRVAL": true, "LASTVAL": true, "SLEEP": true,
+	"PG_SLEEP": true, "PG_BACKEND_PID": true, "PG_TYPEOF": true, "FORMAT_TYPE": true, "PG_SIZE_PRETTY": true,
+	"PG_RELATION_SIZE": true, "PG_TABLE_SIZE": true, "PG_INDEXES_SIZE": true, "PG_TOTAL_RELATION_SIZE": true,
+	"PG_DATABASE_SIZE": true, "PG_GET_VIEWDEF": true, "PG_GET_INDEXDEF": true, "PG_GET_CONSTRAINTDEF": true,
+	"PG_GET_FUNCTIONDEF": true, "PG_GET_TRIGGERDEF": true, "PG_GET_EXPR": true, "PG_GET_SERIAL_SEQUENCE": true,
+	"OBJ_DESCRIPTION": true, "COL_DESCRIPTION": true, "HAS_TABLE_PRIVILEGE": true, "HAS_SCHEMA_PRIVILEGE": true,
+	"HAS_DATABASE_PRIVILEGE": true, "INET_ATON": true, "INET_NTOA": true,
+}
+
+// sqlToken 分类用的词法单元：关键字或标识符（已转大写）、标点
+type sqlToken struct {
+	text string
+	word bool
+}
+
+// ClassifySQL 判断 SQL（可包含多条语句）的类别，取各语句中最高的类别
+// 无法识别的语句视为 DDL；SELECT ... INTO、动态 SQL（EXECUTE IMMEDIATE、EXEC('...')）、匿名块（DO、BEGIN ... END）、
+// 调用存储过程、切换角色（SET ROLE）与有副作用的内置函数（pg_terminate_backend 等）同样视为 DDL；查询中调用用户函数视为 DML。
+func ClassifySQL(dbType model.DatabaseType, sql string) StatementClass {
+	if dbType == model.DatabaseMongoDB {
+		return classifyMongo(sql)
+	}
+
+	class := StatementQuery
+	for _, stmt := range SplitStatements(dbType, sql) {
+		if c := classifyTokens(dbType, tokenizeSQL(dbType, stmt.SQL)); c > class {
+			class = c
+		}
+		if class == StatementDDL {
+			break
+		}
+	}
+	return class
+}
+
+// classifyTokens 判断单条语句（SQL Server 为一个批次）的类别
+func classifyTokens(dbType model.DatabaseType, tokens []sqlToken) StatementClass {
+	if isPlainExplain(tokens) {
+		return StatementQuery
+	}
+
+	class := StatementQuery
+	raise := func(c StatementClass) {
+		if c > class {
+			class = c
+		}
+	}
+
+	wrote := false // 已出现修改数据的关键字，其后的 INTO 属于 INSERT/MERGE
+	start := true  // 当前位置为语句开头（批次开头或分号之后）
+	for i, tok := range tokens {
+		if !tok.word {
+			start = tok.text == ";"
+			continue
+		}
+
+		prev, next := sqlToken{}, sqlToken{}
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		if start {
+			start = false
+			raise(classifyLeading(dbType, tokens[i:]))
+			if dmlKeywords[tok.text] || procedureKeywords[tok.text] {
+				wrote = true
+			}
+			continue
+		}
+
+		// 函数调用（REPLACE(...)、schema.func(...)）按函数判断，其中的关键字不是语句
+		if next.text == "(" && !procedureKeywords[tok.text] {
+			raise(classifyCall(dbType, tokens, i))
+			continue
+		}
+		// 限定名（schema.update）与 SHOW CREATE TABLE 中的关键字不是语句
+		if prev.text == "." || prev.text == "SHOW" {
+			// Oracle、达梦的 seq.NEXTVAL 递增序列
+			if prev.text == "." && tok.text == "NEXTVAL" {
+				raise(StatementDML)
+			}
+			continue
+		}
+
+		switch {
+		case anywhereDDL[tok.text] || (dbType == model.DatabaseMSSQL && mssqlAnywhereDDL[tok.text]):
+			raise(StatementDDL)
+		case procedureKeywords[tok.text]:
+			raise(classifyProcedure(tokens, i+1))
+			wrote = true
+		case anywhereDML[tok.text]:
+			raise(StatementDML)
+			wrote = true
+		case tok.text == "INTO" && !wrote:
+			// SELECT ... INTO 创建表或写入文件
+			raise(StatementDDL)
+		}
+	}
+	return class
+}
+
+// isPlainExplain 不带 ANALYZE 的 EXPLAIN 只生成执行计划，不执行语句
+func isPlainExplain(tokens []sqlToken) bool {
+	if len(tokens) == 0 || tokens[0].text != "EXPLAIN" {
+		return false
+	}
+	for _, tok := range tokens[1:] {
+		if tok.text == "ANALYZE" || tok.text == ";" {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyLeading 按语句开头的关键字判断类别
+func classifyLeading(dbType model.DatabaseType, tokens []sqlToken) StatementClass {
+	first := tokens[0].text
+	next := ""
+	if len(tokens) > 1 {
+		next = tokens[1].text
+	}
+
+	switch {
+	case first == "SET":
+		// MySQL 修改全局变量、密码与默认角色
+		if next == "GLOBAL" || next == "PERSIST" || next == "PERSIST_ONLY" || next == "PASSWORD" || next == "DEFAULT" ||
+			strings.HasPrefix(next, "@@GLOBAL") || strings.HasPrefix(next, "@@PERSIST") {
+			return StatementDDL
+		}
+		// 切换角色或会话用户后以其他身份执行（SET [SESSION | LOCAL] ROLE、SET SESSION AUTHORIZATION）
+		if next == "SESSION" || next == "LOCAL" {
+			if len(tokens) > 2 {
+				next = tokens[2].text
+			}
+		}
+		if next == "ROLE" || next == "AUTHORIZATION" {
+			return StatementDDL
+		}
+		// 给变量赋值（SET @x = 1）只影响当前批次或会话中的变量
+		if strings.HasPrefix(next, "@") && !strings.HasPrefix(next, "@@") {
+			return StatementQuery
+		}
+		// 其他设置（search_path、NAMES 等）修改连接池中共享的连接
+		return StatementDML
+	case first == "PRAGMA":
+		// SQLite PRAGMA name = value、PRAGMA name(value) 修改数据库设置，读取表结构的 PRAGMA 除外
+		name := ""
+		for _, tok := range tokens[1:] {
+			if tok.text == ";" {
+				break
+			}
+			if tok.word {
+				name = tok.text
+			}
+			if tok.text == "=" || (tok.text == "(" && !queryPragmas[name]) {
+				return StatementDDL
+			}
+		}
+		return StatementQuery
+	case first == "BEGIN":
+		// 匿名块可以调用任意存储过程或执行动态 SQL
+		if plsqlDialects[dbType] || !beginTransaction[next] {
+			return StatementDDL
+		}
+		return StatementQuery
+	case first == "DECLARE" && plsqlDialects[dbType]:
+		return StatementDDL
+	case procedureKeywords[first]:
+		return classifyProcedure(tokens, 1)
+	case queryKeywords[first]:
+		return StatementQuery
+	case dmlKeywords[first]:
+		return StatementDML
+	default:
+		return StatementDDL
+	}
+}
+
+// classifyProcedure 判断 CALL、EXEC 之后 tokens[i] 处的调用
+// 存储过程可以执行任意语句，除 queryProcedures 中的系统存储过程外视为 DDL；
+// 执行动态 SQL（EXEC('...')、EXECUTE IMMEDIATE）与切换执行身份（EXECUTE AS）同样视为 DDL。
+func classifyProcedure(tokens []sqlToken, i int) StatementClass {
+	var name []string
+	for ; i < len(tokens) && tokens[i].word; i += 2 {
+		name = append(name, tokens[i].text)
+		if i+1 >= len(tokens) || tokens[i+1].text != "." {
+			break
+		}
+	}
+	switch {
+	case len(name) == 1 && queryProcedures[name[0]]:
+		return StatementQuery
+	case len(name) == 2 && name[0] == "SYS" && queryProcedures[name[1]]:
+		return StatementQuery
+	default:
+		return StatementDDL
+	}
+}
+
+// classifyCall 判断 tokens[i] 处函数调用的类别
+// 有副作用的内置函数视为 DDL；允许通过 SQL 创建可修改数据的函数的数据库中，
+// 内置函数与括号前的关键字以外的调用（用户函数、schema.func）视为 DML。
+func classifyCall(dbType model.DatabaseType, tokens []sqlToken, i int) StatementClass {
+	name := tokens[i].text
+	if sideEffectFunctions[name] {
+		return StatementDDL
+	}
+	if pureFunctionDialects[dbType] {
+		return StatementQuery
+	}
+
+	prev := ""
+	if i > 0 {
+		prev = tokens[i-1].text
+	}
+	switch {
+	case prev == ".":
+		return StatementDML
+	case prev == "AS" || prev == ":":
+		// 别名的列清单（AS t(a, b)）与类型（CAST(x AS VARCHAR(10))、x::NUMERIC(10, 2)）
+		return StatementQuery
+	case queryFunctions[name] || strings.HasPrefix(name, "ST_") || isCTEName(tokens, i):
+		return StatementQuery
+	default:
+		return StatementDML
+	}
+}
+
+// isCTEName tokens[i] 是否为带列清单的公用表表达式名称：name(a, b) AS [NOT] [MATERIALIZED] (...)
+func isCTEName(tokens []sqlToken, i int) bool {
+	depth := 0
+	for j := i + 1; j < len(tokens); j++ {
+		switch tokens[j].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth > 0 {
+			continue
+		}
+		if j+2 >= len(tokens) || tokens[j+1].text != "AS" {
+			return false
+		}
+		after := tokens[j+2].text
+		return after == "(" || after == "MATERIALIZED" || after == "NOT"
+	}
+	return false
+}
+
+// tokenizeSQL 提取语句中引号与注释以外的关键字、标识符和标点
+// MySQL 条件注释（/*! ... */）中的内容会被执行，按语句内容处理。
+func tokenizeSQL(dbType model.DatabaseType, sql string) []sqlToken {
+	dialect := splitDialects[dbType]
+	var tokens []sqlToken
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		rest := sql[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "--") || (dialect.hashComment && c == '#'):
+			if end := strings.IndexByte(rest, '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sql)
+			}
+		case strings.HasPrefix(rest, "/*!"):
+			i += 3
+			for i < len(sql) && sql[i] >= '0' && sql[i] <= '9' {
+				i++
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if end := strings.Index(rest[2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sql)
+			}
+		case c == '\'':
+			i += quotedLength(rest, c, dialect.backslashQuoted(sql, i))
+		case c == '"' || (c == '`' && dialect.backtick):
+			i += quotedLength(rest, c, dialect.backslashEscape && c != '`')
+			// 带引号的标识符作为普通标识符保留，避免与关键字混淆
+			if c != '\'' {
+				tokens = append(tokens, sqlToken{text: "\"", word: true})
+			}
+		case c == '[' && dialect.bracket:
+			i += quotedLength(rest, ']', false)
+			tokens = append(tokens, sqlToken{text: "\"", word: true})
+		case c == '$' && dialect.dollarQuote && dollarTagRegex.MatchString(rest):
+			tag := dollarTagRegex.FindString(rest)
+			if end := strings.Index(rest[len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag)
+			} else {
+				i = len(sql)
+			}
+		case isWordChar(c):
+			j := i + 1
+			for j < len(sql) && isWordChar(sql[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: strings.ToUpper(sql[i:j]), word: true})
+			i = j
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		default:
+			tokens = append(tokens, sqlToken{text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// quotedLength 返回以 rest[0] 开头的引号内容长度（含结束引号），未闭合时返回剩余长度
+func quotedLength(rest string, closing byte, backslashEscape bool) int {
+	for i := 1; i < len(rest); i++ {
+		switch {
+		case backslashEscape && rest[i] == '\\':
+			i++
+		case rest[i] == closing:
+			if i+1 < len(rest) && rest[i+1] == closing {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(rest)
+}
+
+// isWordChar 关键字与标识符字符，包含变量前缀（@x、#temp）与非 ASCII 字符
+func isWordChar(c byte) bool {
+	return isIdentChar(c) || c == '$' || c == '@' || c == '#' || c >= 0x80
+}
+
+// mongoQueryCommands 只读的 MongoDB 命令
+var mongoQueryCommands = map[string]bool{
+	"find": true, "aggregate": true, "count": true, "distinct": true, "explain": true, "ping": true,
+	"buildInfo": true, "hostInfo": true, "serverStatus": true, "connectionStatus": true, "getMore": true,
+	"listCollections": true, "listDatabases": true, "listIndexes": true, "listCommands": true,
+	"collStats": true, "dbStats": true,
+}
+
+// mongoDMLCommands 修改文档的 MongoDB 命令
+var mongoDMLCommands = map[string]bool{
+	"insert": true, "update": true, "delete": true, "findAndModify": true, "bulkWrite": true,
+}
+
+// classifyMongo 按 MongoDB 命令的第一个键判断类别
+// 与 QueryContext 相同，无法解析为 JSON 的文本视为集合名称（find）；带 $out、$merge 的聚合写入集合，视为 DDL。
+func classifyMongo(query string) StatementClass {
+	var command bson.D
+	if err := bson.UnmarshalExtJSON([]byte(query), false, &command); err != nil {
+		return StatementQuery
+	}
+	if len(command) == 0 {
+		return StatementDDL
+	}
+
+	name := command[0].Key
+	switch {
+	case name == "aggregate" || name == "explain":
+		if containsKey(command, "$out") || containsKey(command, "$merge") {
+			return StatementDDL
+		}
+		if name == "explain" {
+			if inner, ok := command[0].Value.(bson.D); ok && len(inner) > 0 {
+				return classifyMongoCommand(inner[0].Key)
+			}
+		}
+		return StatementQuery
+	default:
+		return classifyMongoCommand(name)
+	}
+}
+
+// classifyMongoCommand 按命令名判断类别
+func classifyMongoCommand(name string) StatementClass {
+	switch {
+	case mongoQueryCommands[name] || strings.HasSuffix(name, "Stats"):
+		return StatementQuery
+	case mongoDMLCommands[name]:
+		return StatementDML
+	default:
+		return StatementDDL
+	}
+}
+
+// containsKey 递归查找文档或数组中是否包含指定键
+func containsKey(value any, key string) bool {
+	switch v := value.(type) {
+	case bson.D:
+		for _, e := range v {
+			if e.Key == key || containsKey(e.Value, key) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, item := range v {
+			if containsKey(item, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"testing"
+)
+
+// TestClassifySQL 测试语句分类
+func TestClassifySQL(t *testing.T) {
+	tests := []struct {
+		dbType model.DatabaseType
+		sql    string
+		want   StatementClass
+	}{
+		{model.DatabasePostgreSQL, "SELECT * FROM t WHERE name = 'drop table x'", StatementQuery},
+		{model.DatabasePostgreSQL, "-- DELETE FROM t\nSELECT 1 /* UPDATE t */", StatementQuery},
+		{model.DatabasePostgreSQL, `SELECT "update", t.delete, replace(a, 'x', 'y') FROM t`, StatementQuery},
+		{model.DatabasePostgreSQL, "WITH x AS (SELECT 1) SELECT * FROM x", StatementQuery},
+		{model.DatabasePostgreSQL, `SELECT E'it\'s; DROP TABLE t', name FROM t WHERE note = 'x\'`, StatementQuery},
+		{model.DatabasePostgreSQL, "EXPLAIN UPDATE t SET a = 1", StatementQuery},
+		{model.DatabasePostgreSQL, "BEGIN; SELECT 1; COMMIT", StatementQuery},
+		{model.DatabaseMySQL, "BEGIN WORK", StatementQuery},
+		{model.DatabaseSQLite, "BEGIN IMMEDIATE TRANSACTION", StatementQuery},
+		{model.DatabaseMSSQL, "BEGIN TRAN; SELECT 1; COMMIT", StatementQuery},
+		{model.DatabaseMSSQL, "EXEC sp_help 't'", StatementQuery},
+		{model.DatabaseMSSQL, "EXEC sys.sp_columns @table_name = 't'", StatementQuery},
+		{model.DatabaseMySQL, "SHOW CREATE TABLE t", StatementQuery},
+		{model.DatabaseMySQL, "SET @n = 1", StatementQuery},
+		{model.DatabaseSQLite, "PRAGMA table_info(t)", StatementQuery},
+		{model.DatabaseSQLite, "PRAGMA main.index_list(t)", StatementQuery},
+		{model.DatabaseSQLite, "PRAGMA journal_mode", StatementQuery},
+		{model.DatabaseMSSQL, "DECLARE @x INT; SET @x = 1; SELECT [drop] FROM t WHERE id = @x", StatementQuery},
+		{model.DatabasePostgreSQL, "SELECT count(*), coalesce(max(a), 0), now() FROM t WHERE id IN (1, 2) AND EXISTS (SELECT 1)", StatementQuery},
+		{model.DatabasePostgreSQL, "SELECT CAST(a AS numeric(10, 2)), b::varchar(20) FROM (VALUES (1, 2)) AS v(a, b)", StatementQuery},
+		{model.DatabasePostgreSQL, "WITH x(a) AS (SELECT 1) SELECT row_number() OVER (PARTITION BY a) FROM x", StatementQuery},
+		{model.DatabaseMySQL, "SELECT date_format(now(), '%Y'), json_extract(doc, '$.a') FROM t USE INDEX (idx)", StatementQuery},
+		{model.DatabaseMSSQL, "SELECT dbo.fn_total(id) FROM t", StatementQuery},
+		{model.DatabaseClickHouse, "SELECT toStartOfDay(ts), uniqExact(id) FROM t", StatementQuery},
+
+		{model.DatabasePostgreSQL, "INSERT INTO t VALUES (1)", StatementDML},
+		{model.DatabasePostgreSQL, "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", StatementDML},
+		{model.DatabasePostgreSQL, "EXPLAIN ANALYZE DELETE FROM t", StatementDML},
+		{model.DatabasePostgreSQL, "SELECT * FROM t FOR UPDATE", StatementDML},
+		{model.DatabaseMySQL, "REPLACE INTO t VALUES (1)", StatementDML},
+		{model.DatabaseOracle, "MERGE INTO t USING s ON (t.id = s.id) WHEN MATCHED THEN UPDATE SET t.a = s.a", StatementDML},
+		{model.DatabaseMSSQL, "SELECT 1\nUPDATE t SET a = 1", StatementDML},
+		// 修改连接池中共享连接的会话设置
+		{model.DatabasePostgreSQL, "SET search_path TO app", StatementDML},
+		{model.DatabaseMySQL, "SET NAMES utf8mb4", StatementDML},
+		{model.DatabaseMySQL, "USE other_db", StatementDML},
+		{model.DatabasePostgreSQL, "SELECT setval('seq', 1)", StatementDML},
+		{model.DatabasePostgreSQL, "SELECT lo_unlink(42)", StatementDML},
+		{model.DatabasePostgreSQL, "SELECT archive_orders(id) FROM orders", StatementDML},
+		{model.DatabasePostgreSQL, "SELECT * FROM app.purge_old_rows()", StatementDML},
+		{model.DatabaseMySQL, "SELECT get_lock('x', 10)", StatementDML},
+		{model.DatabaseOracle, "SELECT seq.NEXTVAL FROM dual", StatementDML},
+
+		{model.DatabasePostgreSQL, "DROP TABLE t", StatementDDL},
+		{model.DatabasePostgreSQL, "SELECT 1; DROP TABLE t", StatementDDL},
+		{model.DatabasePostgreSQL, "SELECT * INTO backup FROM t", StatementDDL},
+		{model.DatabasePostgreSQL, "DO $$ BEGIN EXECUTE 'DROP TABLE t'; END $$", StatementDDL},
+		{model.DatabaseMySQL, "/*!40000 DROP TABLE t */", StatementDDL},
+		{model.DatabaseMySQL, "SET GLOBAL max_connections = 10", StatementDDL},
+		{model.DatabaseMySQL, "SELECT * FROM t INTO OUTFILE '/tmp/t.csv'", StatementDDL},
+		{model.DatabaseSQLite, "PRAGMA journal_mode = WAL", StatementDDL},
+		{model.DatabaseSQLite, "PRAGMA writable_schema(1)", StatementDDL},
+		{model.DatabaseSQLite, "PRAGMA journal_mode(OFF)", StatementDDL},
+		{model.DatabaseSQLite, "VACUUM", StatementDDL},
+		{model.DatabaseOracle, "BEGIN\n  EXECUTE IMMEDIATE 'DROP TABLE t';\nEND;", StatementDDL},
+		{model.DatabaseMSSQL, "SELECT 1\nDROP TABLE t", StatementDDL},
+		{model.DatabaseMSSQL, "EXEC('DROP TABLE t')", StatementDDL},
+		{model.DatabaseMSSQL, "SELECT 1 DBCC CHECKDB", StatementDDL},
+		{model.DatabaseClickHouse, "OPTIMIZE TABLE t FINAL", StatementDDL},
+		{model.DatabasePostgreSQL, "VACUUMX something unknown", StatementDDL},
+		{model.DatabasePostgreSQL, "SELECT pg_terminate_backend(pid) FROM pg_stat_activity", StatementDDL},
+		{model.DatabasePostgreSQL, "SELECT dblink_exec('host=x', 'DROP TABLE t')", StatementDDL},
+		{model.DatabasePostgreSQL, "SELECT pg_catalog.set_config('role', 'admin', false)", StatementDDL},
+		{model.DatabasePostgreSQL, "SET ROLE admin", StatementDDL},
+		{model.DatabasePostgreSQL, "SET LOCAL ROLE admin", StatementDDL},
+		{model.DatabasePostgreSQL, "SET SESSION AUTHORIZATION admin", StatementDDL},
+		{model.DatabaseMySQL, "SET @@GLOBAL.max_connections = 10", StatementDDL},
+		{model.DatabaseMSSQL, "EXECUTE AS LOGIN = 'sa'", StatementDDL},
+		{model.DatabaseMSSQL, "SELECT * FROM OPENROWSET(BULK 'C:\\data.txt', SINGLE_CLOB) AS f", StatementDDL},
+		// 匿名块与存储过程可以执行任意语句
+		{model.DatabaseOracle, "BEGIN purge_everything; END;", StatementDDL},
+		{model.DatabaseOracle, "BEGIN DBMS_SQL.EXECUTE(c); END;", StatementDDL},
+		{model.DatabaseDM, "BEGIN DBMS_UTILITY.EXEC_DDL_STATEMENT('DROP TABLE t'); END;", StatementDDL},
+		{model.DatabaseOracle, "DECLARE n NUMBER; BEGIN SELECT 1 INTO n FROM dual; END;", StatementDDL},
+		{model.DatabaseMSSQL, "BEGIN TRY EXEC purge END TRY BEGIN CATCH END CATCH", StatementDDL},
+		{model.DatabaseMySQL, "CALL refresh_stats()", StatementDDL},
+		{model.DatabaseOracle, "CALL app.refresh_stats()", StatementDDL},
+		{model.DatabaseMSSQL, "EXEC purge_everything", StatementDDL},
+		{model.DatabaseMSSQL, "EXEC dbo.sp_help 't'", StatementDDL},
+		{model.DatabaseMSSQL, "SELECT 1\nEXEC purge_everything", StatementDDL},
+		// E'...' 中的 \' 不结束字符串，引号后的语句不能被当作字符串内容
+		{model.DatabasePostgreSQL, `SELECT E'\'' ; DROP TABLE t; --'`, StatementDDL},
+		{model.DatabaseKingBase, `SELECT e'\'' ; DROP TABLE t; --'`, StatementDDL},
+		{model.DatabasePostgreSQL, `SELECT U&'\' ; DROP TABLE t; --'`, StatementDDL},
+		{model.DatabasePostgreSQL, `SELECT '\' ; DROP TABLE t; --'`, StatementDDL},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifySQL(tt.dbType, tt.sql); got != tt.want {
+			t.Errorf("ClassifySQL(%s, %q) = %s, want %s", tt.dbType, tt.sql, got, tt.want)
+		}
+	}
+}
+
+// TestClassifyMongo 测试 MongoDB 命令分类
+func TestClassifyMongo(t *testing.T) {
+	tests := []struct {
+		query string
+		want  StatementClass
+	}{
+		{"users", StatementQuery},
+		{`{"find": "users", "filter": {"age": {"$gt": 18}}}`, StatementQuery},
+		{`{"aggregate": "users", "pipeline": [{"$match": {}}], "cursor": {}}`, StatementQuery},
+		{`{"listCollections": 1}`, StatementQuery},
+		{`{"explain": {"find": "users"}}`, StatementQuery},
+		{`{"insert": "users", "documents": [{"a": 1}]}`, StatementDML},
+		{`{"explain": {"delete": "users", "deletes": []}}`, StatementDML},
+		{`{"aggregate": "users", "pipeline": [{"$match": {}}, {"$out": "copy"}], "cursor": {}}`, StatementDDL},
+		{`{"drop": "users"}`, StatementDDL},
+		{`{"createIndexes": "users", "indexes": []}`, StatementDDL},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifySQL(model.DatabaseMongoDB, tt.query); got != tt.want {
+			t.Errorf("ClassifySQL(mongodb, %q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
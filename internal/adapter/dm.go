@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"dbm/internal/export"
 	"dbm/internal/model"
@@ -27,6 +28,17 @@ func NewDMAdapter() *DMAdapter {
 // Connect 连接达梦数据库
 func (a *DMAdapter) Connect(config *model.ConnectionConfig) (any, error) {
 	dsn := a.buildDSN(config)
+	if config.TLS.Enabled() {
+		params, err := a.tlsParams(config)
+		if err != nil {
+			return nil, err
+		}
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + strings.Join(params, "&")
+	}
 	db, err := sql.Open("dm", dsn)
 	if err != nil {
 		return nil, err
@@ -85,6 +97,23 @@ func (a *DMAdapter) buildDSN(config *model.ConnectionConfig) string {
 	return dsn
 }
 
+// tlsParams 构建达梦 SSL 连接参数
+// 达梦驱动只接受客户端证书与私钥的文件路径，且不校验服务端证书，仅支持 require 模式。
+func (a *DMAdapter) tlsParams(config *model.ConnectionConfig) ([]string, error) {
+	if config.TLS.Mode != model.TLSRequire {
+		return nil, fmt.Errorf("dm driver cannot verify server certificates, use tls mode require")
+	}
+	if config.TLS.ClientCert == "" || config.TLS.ClientKey == "" {
+		return nil, fmt.Errorf("dm ssl requires a client certificate and key")
+	}
+
+	certPath, keyPath, err := tlsFiles(config)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"sslCertPath=" + certPath, "sslKeyPath=" + keyPath}, nil
+}
+
 // Close 关闭数据库连接
 func (a *DMAdapter) Close(db any) error {
 	return db.(*sql.DB).Close()
@@ -175,7 +204,10 @@ func (a *DMAdapter) GetTableSchema(db any, database, table string) (*model.Table
 			NULLABLE,
 			DATA_DEFAULT,
 			'' as COLUMN_KEY,
-			'' as EXTRA
+			'' as EXTRA,
+			DATA_LENGTH,
+			DATA_PRECISION,
+			DATA_SCALE
 		FROM ALL_TAB_COLUMNS
 		WHERE OWNER = :1 AND TABLE_NAME = :2
 		ORDER BY COLUMN_ID
@@ -193,13 +225,14 @@ func (a *DMAdapter) GetTableSchema(db any, database, table string) (*model.Table
 	for colsRows.Next() {
 		var col model.ColumnInfo
 		var colType, nullable, def, key, extra sql.NullString
+		var length, precision, scale sql.NullInt64
 
-		if err := colsRows.Scan(&col.Name, &colType, &nullable, &def, &key, &extra); err != nil {
+		if err := colsRows.Scan(&col.Name, &colType, &nullable, &def, &key, &extra, &length, &precision, &scale); err != nil {
 			return nil, err
 		}
 
 		// 构建类型字符串
-		col.Type = a.buildTypeString(colType.String, sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{})
+		col.Type = a.buildTypeString(colType.String, length, precision, scale)
 		col.Nullable = nullable.String == "Y"
 		col.DefaultValue = def.String
 		col.Key = key.String
@@ -274,6 +307,12 @@ func (a *DMAdapter) GetTableSchema(db any, database, table string) (*model.Table
 		tableSchema.Indexes = append(tableSchema.Indexes, *idx)
 	}
 
+	// 获取外键信息
+	tableSchema.Constraints, err = queryForeignKeys(dbSQL, oracleForeignKeysQuery, strings.ToUpper(database), strings.ToUpper(table))
+	if err != nil {
+		return nil, err
+	}
+
 	return tableSchema, nil
 }
 
@@ -282,19 +321,13 @@ func (a *DMAdapter) buildTypeString(dataType string, length, precision, scale sq
 	dt := strings.ToUpper(dataType)
 
 	switch dt {
-	case "VARCHAR", "VARCHAR2", "CHAR":
+	case "VARCHAR", "VARCHAR2", "CHAR", "BINARY", "VARBINARY":
 		if length.Valid && length.Int64 > 0 {
 			return fmt.Sprintf("%s(%d)", dt, length.Int64)
 		}
-	case "NUMBER":
-		if precision.Valid {
-			if scale.Valid && scale.Int64 > 0 {
-				return fmt.Sprintf("NUMBER(%d,%d)", precision.Int64, scale.Int64)
-			}
-			return fmt.Sprintf("NUMBER(%d)", precision.Int64)
-		}
-	case "DECIMAL", "NUMERIC":
-		if precision.Valid {
+	case "NUMBER", "DECIMAL", "NUMERIC":
+		// 未指定精度时不带参数
+		if precision.Valid && precision.Int64 > 0 {
 			if scale.Valid && scale.Int64 > 0 {
 				return fmt.Sprintf("%s(%d,%d)", dt, precision.Int64, scale.Int64)
 			}
@@ -438,10 +471,19 @@ func (a *DMAdapter) GetIndexes(db any, database, table string) ([]model.IndexInf
 
 // Execute 执行非查询 SQL
 func (a *DMAdapter) Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.ExecuteContext(context.Background(), db, query, args...)
+}
+
+// ExecuteContext 执行非查询 SQL，ctx 取消时通过 SP_CANCEL_SESSION_OPERATION 终止服务端语句
+func (a *DMAdapter) ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
 	start := time.Now()
 
-	result, err := dbSQL.Exec(query, args...)
+	var result sql.Result
+	err := runWithKill(ctx, db, dmKiller, func(q sqlQueryer) error {
+		var err error
+		result, err = q.ExecContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -457,7 +499,22 @@ func (a *DMAdapter) Execute(db any, query string, args ...interface{}) (*model.E
 
 // Query 执行查询
 func (a *DMAdapter) Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.QueryContext(context.Background(), db, query, opts)
+}
+
+// QueryContext 执行查询，ctx 取消时通过 SP_CANCEL_SESSION_OPERATION 终止服务端语句
+func (a *DMAdapter) QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	var result *model.QueryResult
+	err := runWithKill(ctx, db, dmKiller, func(q sqlQueryer) error {
+		var err error
+		result, err = a.query(ctx, q, query, opts)
+		return err
+	})
+	return result, err
+}
+
+// query 在指定会话上执行查询
+func (a *DMAdapter) query(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
 	start := time.Now()
 
 	trimQuery := strings.TrimSpace(strings.ToUpper(query))
@@ -467,7 +524,7 @@ func (a *DMAdapter) Query(db any, query string, opts *model.QueryOptions) (*mode
 		strings.HasPrefix(trimQuery, "WITH")
 
 	if !isQuery {
-		result, err := dbSQL.Exec(query)
+		result, err := q.ExecContext(ctx, query)
 		if err != nil {
 			return nil, err
 		}
@@ -479,210 +536,102 @@ func (a *DMAdapter) Query(db any, query string, opts *model.QueryOptions) (*mode
 		}, nil
 	}
 
-	rows, err := dbSQL.Query(query)
+	rows, err := dmPager.query(ctx, q, query, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
+	result, err := scanResult(rows, opts, nil)
 	if err != nil {
 		return nil, err
 	}
+	// 统计总数前释放当前会话上的结果集
+	rows.Close()
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			if val == nil {
-				row[col] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
-		rowData = append(rowData, row)
+	result.Message = "查询成功"
+	if err := dmPager.fillTotal(ctx, q, query, opts, result); err != nil {
+		return nil, err
 	}
+	result.TimeCost = time.Since(start)
 
-	return &model.QueryResult{
-		Columns:  columns,
-		Rows:     rowData,
-		Total:    int64(len(rowData)),
-		Message:  "查询成功",
-		TimeCost: time.Since(start),
-	}, nil
+	return result, nil
 }
 
 // Insert 插入数据
 func (a *DMAdapter) Insert(db any, database, table string, data map[string]interface{}) error {
 	dbSQL := db.(*sql.DB)
-	cols := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-
-	for col, val := range data {
-		cols = append(cols, fmt.Sprintf(`"%s"`, strings.ToUpper(col)))
-		placeholders = append(placeholders, "?")
-		values = append(values, val)
-	}
-
-	insertSql := fmt.Sprintf(`INSERT INTO "%s"."%s" (%s) VALUES (%s)`,
-		strings.ToUpper(database),
-		strings.ToUpper(table),
-		strings.Join(cols, ", "),
-		strings.Join(placeholders, ", "))
-
-	_, err := dbSQL.Exec(insertSql, values...)
+	stmt := dmEditor.Insert(qualify(quoteDouble, database, table), data)
+	_, err := dbSQL.Exec(stmt.Query, stmt.Args...)
 	return err
 }
 
-// Update 更新数据
-func (a *DMAdapter) Update(db any, database, table string, data map[string]interface{}, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("更新操作必须指定 WHERE 条件")
-	}
-
-	sets := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-
-	for col, val := range data {
-		sets = append(sets, fmt.Sprintf(`"%s" = ?`, strings.ToUpper(col)))
-		values = append(values, val)
-	}
-
-	updateSql := fmt.Sprintf(`UPDATE "%s"."%s" SET %s WHERE %s`,
-		strings.ToUpper(database),
-		strings.ToUpper(table),
-		strings.Join(sets, ", "),
-		where)
-
-	_, err := dbSQL.Exec(updateSql, values...)
-	return err
+// InsertRows 批量写入（多行 INSERT）
+func (a *DMAdapter) InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error) {
+	return insertValues(ctx, db, dmBulkEditor, qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table)), columns, rows, dmMaxParams)
 }
 
-// Delete 删除数据
-func (a *DMAdapter) Delete(db any, database, table, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("删除操作必须指定 WHERE 条件")
-	}
+// CreateTable 按列定义建表
+func (a *DMAdapter) CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error {
+	return createTable(ctx, db, func(name string) string { return quoteDouble(strings.ToUpper(name)) }, qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table)), columns, a.buildColumnType, "")
+}
 
-	deleteSql := fmt.Sprintf(`DELETE FROM "%s"."%s" WHERE %s`,
-		strings.ToUpper(database),
-		strings.ToUpper(table),
-		where)
+// CountRows 统计表的行数
+func (a *DMAdapter) CountRows(ctx context.Context, db any, database, table string) (int64, error) {
+	return countRows(ctx, db, qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table)))
+}
 
-	_, err := dbSQL.Exec(deleteSql)
-	return err
+// Update 按行标识更新数据
+func (a *DMAdapter) Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
+	return updateRow(db, dmEditor, qualify(quoteDouble, database, table), data, key)
 }
 
-// ExportToCSV 导出为 CSV
-func (a *DMAdapter) ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
-	dbSQL := db.(*sql.DB)
-	exporter := export.NewCSVExporter(opts)
+// Delete 按行标识删除数据
+func (a *DMAdapter) Delete(db any, database, table string, key model.RowKey) (int64, error) {
+	return deleteRow(db, dmEditor, qualify(quoteDouble, database, table), key)
+}
 
-	rows, err := dbSQL.Query(query)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
+// ApplyChanges 在单个事务中应用数据变更集
+func (a *DMAdapter) ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	return applyChanges(ctx, db, dmEditor, qualify(quoteDouble, database, table), changes, preview)
+}
 
-	colNames, err := rows.Columns()
-	if err != nil {
-		return err
-	}
+// ReadCell 按行标识读取单元格完整值（BLOB、CLOB）
+func (a *DMAdapter) ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	return readCell(ctx, db, dmEditor, qualify(quoteDouble, database, table), column, key, fn)
+}
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(colNames))
-		valuePtrs := make([]interface{}, len(colNames))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// WriteCell 按行标识替换单元格的值
+func (a *DMAdapter) WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error) {
+	return writeCell(ctx, db, dmEditor, qualify(quoteDouble, database, table), column, key, data)
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
-		}
+// StreamQuery 执行查询并以行迭代器逐行读取结果
+func (a *DMAdapter) StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error {
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-		row := make(map[string]interface{})
-		for i, colName := range colNames {
-			val := values[i]
-			if val == nil {
-				row[colName] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[colName] = string(b)
-			} else {
-				row[colName] = val
-			}
-		}
-		rowData = append(rowData, row)
+// StreamTable 以行迭代器逐行读取表数据，limit > 0 时只读取前 limit 行
+func (a *DMAdapter) StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error {
+	query := "SELECT * FROM " + qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table))
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-	return exporter.Export(writer, colNames, rowData)
+// ExportToCSV 导出为 CSV，逐行读取并写出
+func (a *DMAdapter) ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
+	return a.StreamQuery(ctx, db, database, query, exportCSV(writer, opts))
 }
 
-// ExportToSQL 导出为 SQL
-func (a *DMAdapter) ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
-	dbSQL := db.(*sql.DB)
+// ExportToSQL 导出为 SQL，表数据逐行读取并写出
+func (a *DMAdapter) ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
 	exporter := export.NewSQLExporter(opts, model.DatabaseDM)
 
 	// 如果提供了自定义查询，则按查询导出
 	if opts.Query != "" {
-		rows, err := dbSQL.Query(opts.Query)
-		if err != nil {
-			return err
-		}
-		defer rows.Close()
-
-		colNames, err := rows.Columns()
-		if err != nil {
-			return err
-		}
-
-		var rowData []map[string]interface{}
-		for rows.Next() {
-			values := make([]interface{}, len(colNames))
-			valuePtrs := make([]interface{}, len(colNames))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return err
-			}
-
-			row := make(map[string]interface{})
-			for i, colName := range colNames {
-				val := values[i]
-				if val == nil {
-					row[colName] = nil
-				} else if b, ok := val.([]byte); ok {
-					row[colName] = string(b)
-				} else {
-					row[colName] = val
-				}
-			}
-			rowData = append(rowData, row)
-		}
-
-		tableName := opts.TableName
-		if tableName == "" {
-			tableName = "query_result"
-		}
-		return exporter.ExportData(writer, "", tableName, colNames, rowData)
+		return a.StreamQuery(ctx, db, database, opts.Query, exportInserts(writer, exporter, "", queryTableName(opts)))
 	}
 
 	for _, table := range tables {
@@ -699,56 +648,14 @@ func (a *DMAdapter) ExportToSQL(db any, writer io.Writer, database string, table
 
 		// 导出数据
 		if !opts.StructureOnly {
-			querySQL := fmt.Sprintf(`SELECT * FROM "%s"."%s"`, strings.ToUpper(database), strings.ToUpper(table))
-			if opts.MaxRows > 0 {
-				querySQL = fmt.Sprintf("%s LIMIT %d", querySQL, opts.MaxRows)
-			}
-			rows, err := dbSQL.Query(querySQL)
-			if err != nil {
-				return err
-			}
-
-			colNames, err := rows.Columns()
-			if err != nil {
-				rows.Close()
-				return err
-			}
-
-			var rowData []map[string]interface{}
-			for rows.Next() {
-				values := make([]interface{}, len(colNames))
-				valuePtrs := make([]interface{}, len(colNames))
-				for i := range values {
-					valuePtrs[i] = &values[i]
-				}
-
-				if err := rows.Scan(valuePtrs...); err != nil {
-					rows.Close()
-					return err
-				}
-
-				row := make(map[string]interface{})
-				for i, colName := range colNames {
-					val := values[i]
-					if val == nil {
-						row[colName] = nil
-					} else if b, ok := val.([]byte); ok {
-						row[colName] = string(b)
-					} else {
-						row[colName] = val
-					}
-				}
-				rowData = append(rowData, row)
-			}
-			rows.Close()
-
-			if err := exporter.ExportData(writer, "", table, colNames, rowData); err != nil {
+			if err := a.StreamTable(ctx, db, database, table, opts.MaxRows, exportInserts(writer, exporter, "", table)); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	// 外键引用的表可能排在后面，在所有表之后添加
+	return exporter.ExportForeignKeys(writer)
 }
 
 // GetCreateTableSQL 获取建表语句
@@ -0,0 +1,146 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/export"
+	"dbm/internal/model"
+	"io"
+)
+
+// sqlRows 将 *sql.Rows 包装为导出行迭代器，每次只在内存中保留一行
+type sqlRows struct {
+	rows    *sql.Rows
+	columns []string
+	types   []*sql.ColumnType
+	values  []any
+	ptrs    []any
+	row     []any
+	convert valueConverter
+	err     error
+}
+
+// newSQLRows 创建导出行迭代器，convert 为空时只将 []byte 转换为字符串
+func newSQLRows(rows *sql.Rows, convert valueConverter) (*sqlRows, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &sqlRows{
+		rows:    rows,
+		columns: make([]string, len(types)),
+		types:   types,
+		values:  make([]any, len(types)),
+		ptrs:    make([]any, len(types)),
+		row:     make([]any, len(types)),
+		convert: convert,
+	}
+	for i, ct := range types {
+		r.columns[i] = ct.Name()
+		r.ptrs[i] = &r.values[i]
+	}
+	return r, nil
+}
+
+func (r *sqlRows) Columns() []string { return r.columns }
+
+// ColumnTypes 返回各列的数据库类型名称
+func (r *sqlRows) ColumnTypes() []string {
+	types := make([]string, len(r.types))
+	for i, ct := range r.types {
+		types[i] = ct.DatabaseTypeName()
+	}
+	return types
+}
+
+func (r *sqlRows) Next() bool {
+	if r.err != nil || !r.rows.Next() {
+		return false
+	}
+	if err := r.rows.Scan(r.ptrs...); err != nil {
+		r.err = err
+		return false
+	}
+
+	for i, val := range r.values {
+		if r.convert != nil {
+			val = r.convert(r.types[i], val)
+		}
+		if b, ok := val.([]byte); ok {
+			val = string(b)
+		}
+		r.row[i] = val
+	}
+	return true
+}
+
+func (r *sqlRows) Values() []any { return r.row }
+
+func (r *sqlRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+// streamQuery 执行查询并以行迭代器交给 fn，fn 返回后关闭结果集
+// ctx 携带行计数器时统计读取的行数。
+func streamQuery(ctx context.Context, db any, query string, convert valueConverter, fn func(rows export.RowIterator) error) error {
+	rows, err := db.(*sql.DB).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	it, err := newSQLRows(rows, convert)
+	if err != nil {
+		return err
+	}
+	if err := fn(export.CountRows(ctx, it)); err != nil {
+		return err
+	}
+	return it.Err()
+}
+
+// exportCSV 返回将行迭代器写出为 CSV 的回调
+func exportCSV(writer io.Writer, opts *model.CSVOptions) func(rows export.RowIterator) error {
+	return func(rows export.RowIterator) error {
+		return export.NewCSVExporter(opts).Export(writer, rows)
+	}
+}
+
+// exportInserts 返回将行迭代器写出为 table 的 INSERT 语句的回调
+func exportInserts(writer io.Writer, exporter *export.SQLExporter, database, table string) func(rows export.RowIterator) error {
+	return func(rows export.RowIterator) error {
+		return exporter.ExportData(writer, database, table, rows)
+	}
+}
+
+// queryTableName 按查询导出时 INSERT 语句使用的表名，默认为 query_result
+func queryTableName(opts *model.SQLOptions) string {
+	if opts.TableName == "" {
+		return "query_result"
+	}
+	return opts.TableName
+}
+
+// queryForeignKeys 查询表的外键
+// 查询的每行依次为约束名、列名、引用的表与引用的列，多列外键按列的顺序返回多行。
+func queryForeignKeys(db *sql.DB, query string, args ...any) ([]model.ConstraintInfo, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var constraints []model.ConstraintInfo
+	for rows.Next() {
+		c := model.ConstraintInfo{Type: "FOREIGN KEY"}
+		if err := rows.Scan(&c.Name, &c.ColumnName, &c.ReferenceTable, &c.ReferenceColumn); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, rows.Err()
+}
@@ -0,0 +1,114 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"dbm/internal/export"
+	"dbm/internal/model"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestSQLite_ExportStreaming 测试按查询与按表导出时逐行写出并遵守 MaxRows
+func TestSQLite_ExportStreaming(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "export.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := NewSQLiteAdapter()
+	for _, stmt := range []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, data BLOB)",
+		"INSERT INTO items VALUES (1, 'a', X'6869'), (2, NULL, NULL), (3, 'c', NULL)",
+	} {
+		if _, err := a.Execute(db, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	var csvOut bytes.Buffer
+	opts := &model.CSVOptions{IncludeHeader: true, Encoding: "GBK", MaxRows: 2}
+	if err := a.ExportToCSV(ctx, db, &csvOut, "", "SELECT * FROM items ORDER BY id", opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,name,data\n1,a,hi\n2,NULL,NULL\n"; csvOut.String() != want {
+		t.Errorf("csv = %q, want %q", csvOut.String(), want)
+	}
+
+	var sqlOut bytes.Buffer
+	if err := a.ExportToSQL(ctx, db, &sqlOut, "", []string{"items"}, &model.SQLOptions{BatchInsert: true, BatchSize: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(sqlOut.String(), "INSERT INTO"); got != 2 {
+		t.Errorf("got %d INSERT statements, want 2:\n%s", got, sqlOut.String())
+	}
+
+	// 按表读取时限制行数，并提供列类型
+	var rows [][]any
+	var types []string
+	err = a.StreamTable(ctx, db, "", "items", 2, func(it export.RowIterator) error {
+		types = it.(export.ColumnTyper).ColumnTypes()
+		for it.Next() {
+			rows = append(rows, append([]any(nil), it.Values()...))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][2] != "hi" {
+		t.Errorf("rows = %v, want 2 rows", rows)
+	}
+	if strings.Join(types, ",") != "INTEGER,TEXT,BLOB" {
+		t.Errorf("types = %v", types)
+	}
+}
+
+// TestSQLite_ExportToSQLTarget 测试导出为其他数据库的 SQL：按目标方言生成建表语句，外键在数据之后添加
+func TestSQLite_ExportToSQLTarget(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "export.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := NewSQLiteAdapter()
+	for _, stmt := range []string{
+		"CREATE TABLE orgs (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT 'none')",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, org_id INTEGER REFERENCES orgs, avatar BLOB)",
+		"INSERT INTO orgs VALUES (1, 'a')",
+		"INSERT INTO users VALUES (1, 1, X'6869')",
+	} {
+		if _, err := a.Execute(db, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	opts := &model.SQLOptions{IncludeCreateTable: true, TargetType: model.DatabasePostgreSQL}
+	if err := a.ExportToSQL(context.Background(), db, &out, "", []string{"users", "orgs"}, opts); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		`"id" BIGINT GENERATED BY DEFAULT AS IDENTITY,`,
+		`"name" TEXT DEFAULT 'none' NOT NULL`,
+		`INSERT INTO "users" ("id", "org_id", "avatar") VALUES (1, 1, '\x6869');`,
+		`ALTER TABLE "users" ADD CONSTRAINT "fk_users_0" FOREIGN KEY ("org_id") REFERENCES "orgs" ("id");`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	// 引用的表在后面创建，外键在所有表之后添加
+	if strings.Index(got, "ADD CONSTRAINT") < strings.Index(got, `CREATE TABLE "orgs"`) {
+		t.Errorf("foreign key added before referenced table:\n%s", got)
+	}
+}
@@ -23,7 +23,7 @@ func (f *Factory) CreateAdapter(dbType model.DatabaseType) (DatabaseAdapter, err
 	case model.DatabaseSQLite:
 		return NewSQLiteAdapter(), nil
 	case model.DatabaseMSSQL:
-		return nil, fmt.Errorf("SQL Server 适配器尚未实现")
+		return NewMSSQLAdapter(), nil
 	case model.DatabaseOracle:
 		return NewOracleAdapter(), nil
 	case model.DatabaseClickHouse:
@@ -45,6 +45,7 @@ func (f *Factory) SupportedTypes() []model.DatabaseType {
 		model.DatabaseMySQL,
 		model.DatabasePostgreSQL,
 		model.DatabaseSQLite,
+		model.DatabaseMSSQL,
 		model.DatabaseClickHouse,
 		model.DatabaseKingBase,
 		model.DatabaseDM,
@@ -65,6 +65,8 @@ Valid values for sslmode are:
   - verify-full - Always SSL (verify that the certification presented by
     the server was signed by a trusted CA and the server host name
     matches the one in the certificate)
+  - gokb-<key> - Always SSL using the tls.Config registered with
+    RegisterTLSConfig under <key>
 
 See http://www.kingbase.org/docs/current/static/libkci-connect.html#LIBPQ-CONNSTRING
 for more information about connection string parameters.
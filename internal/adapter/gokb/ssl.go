@@ -8,8 +8,44 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
+// Registry for custom tls.Configs
+var (
+	tlsConfs   = make(map[string]*tls.Config)
+	tlsConfsMu sync.RWMutex
+)
+
+// RegisterTLSConfig registers a custom tls.Config. They are used by using
+// sslmode=gokb-<key> in the connection string. The certificate settings
+// (sslcert, sslkey, sslrootcert) are ignored for custom configs.
+//
+// Set the config to nil to remove a configuration.
+func RegisterTLSConfig(key string, config *tls.Config) error {
+	key = strings.TrimPrefix(key, "gokb-")
+
+	tlsConfsMu.Lock()
+	defer tlsConfsMu.Unlock()
+	if config == nil {
+		delete(tlsConfs, key)
+	} else {
+		tlsConfs[key] = config
+	}
+	return nil
+}
+
+// getTLSConfigClone returns a copy of the registered config, or nil.
+func getTLSConfigClone(key string) *tls.Config {
+	tlsConfsMu.RLock()
+	defer tlsConfsMu.RUnlock()
+	if config, ok := tlsConfs[key]; ok {
+		return config.Clone()
+	}
+	return nil
+}
+
 // ssl generates a function to upgrade a net.Conn based on the "sslmode" and
 // related settings. The function is nil when no upgrade should take place.
 func ssl(o values) (func(net.Conn) (net.Conn, error), error) {
@@ -47,6 +83,15 @@ func ssl(o values) (func(net.Conn) (net.Conn, error), error) {
 	case "disable":
 		return nil, nil
 	default:
+		if strings.HasPrefix(mode, "gokb-") {
+			custom := getTLSConfigClone(mode[len("gokb-"):])
+			if custom == nil {
+				return nil, fmterrorf("unknown custom sslmode %q", mode)
+			}
+			return func(conn net.Conn) (net.Conn, error) {
+				return tls.Client(conn, custom), nil
+			}, nil
+		}
 		return nil, fmterrorf(`unsupported sslmode %q; only "require" (default), "verify-full", "verify-ca", and "disable" supported`, mode)
 	}
 
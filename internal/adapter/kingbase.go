@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"dbm/internal/export"
 	"dbm/internal/model"
@@ -9,7 +10,7 @@ import (
 	"strings"
 	"time"
 
-	_ "kingbase.com/gokb"
+	"kingbase.com/gokb"
 )
 
 // KingBaseAdapter KingBase（人大金仓）数据库适配器
@@ -26,6 +27,11 @@ func NewKingBaseAdapter() *KingBaseAdapter {
 
 // Connect 连接 KingBase 数据库
 func (a *KingBaseAdapter) Connect(config *model.ConnectionConfig) (any, error) {
+	// 先注册 TLS 配置，DSN 中按指纹引用
+	if _, _, err := newTLSConfig(config); err != nil {
+		return nil, err
+	}
+
 	dsn := a.buildDSN(config)
 	db, err := sql.Open("kingbase", dsn)
 	if err != nil {
@@ -62,6 +68,11 @@ func (a *KingBaseAdapter) buildDSN(config *model.ConnectionConfig) string {
 		params = append(params, fmt.Sprintf("%s=%s", k, v))
 	}
 
+	// TLS 配置优先于参数中的 sslmode，使用以配置指纹注册到 gokb 的 tls.Config
+	if config.TLS.Enabled() {
+		params = append(params, "sslmode=gokb-"+tlsKey(config))
+	}
+
 	return strings.Join(params, " ")
 }
 
@@ -184,8 +195,11 @@ func (a *KingBaseAdapter) GetTableSchemaWithSchema(db any, database, schema, tab
 			is_nullable,
 			column_default,
 			'' as column_key,
-			'' as extra,
-			'' as column_comment
+			CASE WHEN is_identity = 'YES' THEN 'identity' ELSE '' END as extra,
+			COALESCE(col_description(format('%I.%I', table_schema, table_name)::regclass, ordinal_position), '') as column_comment,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale
 		FROM information_schema.columns
 		WHERE table_schema = $1 AND table_name = $2
 		ORDER BY ordinal_position
@@ -200,10 +214,11 @@ func (a *KingBaseAdapter) GetTableSchemaWithSchema(db any, database, schema, tab
 	for colsRows.Next() {
 		var col model.ColumnInfo
 		var colType, nullable, def, key, extra, comment sql.NullString
-		if err := colsRows.Scan(&col.Name, &colType, &nullable, &def, &key, &extra, &comment); err != nil {
+		var length, precision, scale sql.NullInt64
+		if err := colsRows.Scan(&col.Name, &colType, &nullable, &def, &key, &extra, &comment, &length, &precision, &scale); err != nil {
 			return nil, err
 		}
-		col.Type = colType.String
+		col.Type = pgTypeString(colType.String, length, precision, scale)
 		col.Nullable = nullable.String == "YES"
 		col.DefaultValue = def.String
 		col.Key = key.String
@@ -256,6 +271,12 @@ func (a *KingBaseAdapter) GetTableSchemaWithSchema(db any, database, schema, tab
 		tableSchema.Indexes = append(tableSchema.Indexes, *idx)
 	}
 
+	// 获取外键信息
+	tableSchema.Constraints, err = queryForeignKeys(dbSQL, pgForeignKeysQuery, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
 	return tableSchema, nil
 }
 
@@ -358,10 +379,19 @@ func (a *KingBaseAdapter) GetIndexes(db any, database, table string) ([]model.In
 
 // Execute 执行非查询 SQL
 func (a *KingBaseAdapter) Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.ExecuteContext(context.Background(), db, query, args...)
+}
+
+// ExecuteContext 执行非查询 SQL，ctx 取消时通过 pg_cancel_backend 终止服务端语句
+func (a *KingBaseAdapter) ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
 	start := time.Now()
 
-	result, err := dbSQL.Exec(query, args...)
+	var result sql.Result
+	err := runWithKill(ctx, db, pgKiller, func(q sqlQueryer) error {
+		var err error
+		result, err = q.ExecContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -377,7 +407,22 @@ func (a *KingBaseAdapter) Execute(db any, query string, args ...interface{}) (*m
 
 // Query 执行查询
 func (a *KingBaseAdapter) Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.QueryContext(context.Background(), db, query, opts)
+}
+
+// QueryContext 执行查询，ctx 取消时通过 pg_cancel_backend 终止服务端语句
+func (a *KingBaseAdapter) QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	var result *model.QueryResult
+	err := runWithKill(ctx, db, pgKiller, func(q sqlQueryer) error {
+		var err error
+		result, err = a.query(ctx, q, query, opts)
+		return err
+	})
+	return result, err
+}
+
+// query 在指定会话上执行查询
+func (a *KingBaseAdapter) query(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
 	start := time.Now()
 
 	trimQuery := strings.TrimSpace(strings.ToUpper(query))
@@ -388,7 +433,7 @@ func (a *KingBaseAdapter) Query(db any, query string, opts *model.QueryOptions)
 		strings.HasPrefix(trimQuery, "WITH")
 
 	if !isQuery {
-		result, err := dbSQL.Exec(query)
+		result, err := q.ExecContext(ctx, query)
 		if err != nil {
 			return nil, err
 		}
@@ -400,209 +445,106 @@ func (a *KingBaseAdapter) Query(db any, query string, opts *model.QueryOptions)
 		}, nil
 	}
 
-	rows, err := dbSQL.Query(query)
+	rows, err := pgPager.query(ctx, q, query, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
+	result, err := scanResult(rows, opts, nil)
 	if err != nil {
 		return nil, err
 	}
+	// 统计总数前释放当前会话上的结果集
+	rows.Close()
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			if val == nil {
-				row[col] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
-		rowData = append(rowData, row)
+	result.Message = "查询成功"
+	if err := pgPager.fillTotal(ctx, q, query, opts, result); err != nil {
+		return nil, err
 	}
+	result.TimeCost = time.Since(start)
 
-	return &model.QueryResult{
-		Columns:  columns,
-		Rows:     rowData,
-		Total:    int64(len(rowData)),
-		Message:  "查询成功",
-		TimeCost: time.Since(start),
-	}, nil
+	return result, nil
 }
 
 // Insert 插入数据
 func (a *KingBaseAdapter) Insert(db any, database, table string, data map[string]interface{}) error {
 	dbSQL := db.(*sql.DB)
-	cols := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-	argNum := 1
-
-	for col, val := range data {
-		cols = append(cols, fmt.Sprintf(`"%s"`, col))
-		placeholders = append(placeholders, fmt.Sprintf("$%d", argNum))
-		argNum++
-		values = append(values, val)
-	}
-
-	insertSql := fmt.Sprintf(`INSERT INTO "%s" (%s) VALUES (%s)`,
-		table,
-		strings.Join(cols, ", "),
-		strings.Join(placeholders, ", "))
-
-	_, err := dbSQL.Exec(insertSql, values...)
+	stmt := pgEditor.Insert(pgTable(table), data)
+	_, err := dbSQL.Exec(stmt.Query, stmt.Args...)
 	return err
 }
 
-// Update 更新数据
-func (a *KingBaseAdapter) Update(db any, database, table string, data map[string]interface{}, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("更新操作必须指定 WHERE 条件")
-	}
-
-	sets := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-	argNum := 1
-
-	for col, val := range data {
-		sets = append(sets, fmt.Sprintf(`"%s" = $%d`, col, argNum))
-		argNum++
-		values = append(values, val)
+// InsertRows 批量写入（COPY FROM STDIN）
+func (a *KingBaseAdapter) InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error) {
+	schema, name := splitPGTable(table)
+	if schema == "" {
+		return insertPrepared(ctx, db, gokb.CopyIn(name, columns...), rows, true)
 	}
+	return insertPrepared(ctx, db, gokb.CopyInSchema(schema, name, columns...), rows, true)
+}
 
-	updateSql := fmt.Sprintf(`UPDATE "%s" SET %s WHERE %s`,
-		table,
-		strings.Join(sets, ", "),
-		where)
-
-	_, err := dbSQL.Exec(updateSql, values...)
-	return err
+// CreateTable 按列定义建表
+func (a *KingBaseAdapter) CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error {
+	return createTable(ctx, db, quoteDouble, pgTable(table), columns, a.buildColumnType, "")
 }
 
-// Delete 删除数据
-func (a *KingBaseAdapter) Delete(db any, database, table, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("删除操作必须指定 WHERE 条件")
-	}
+// CountRows 统计表的行数
+func (a *KingBaseAdapter) CountRows(ctx context.Context, db any, database, table string) (int64, error) {
+	return countRows(ctx, db, pgTable(table))
+}
 
-	deleteSql := fmt.Sprintf(`DELETE FROM "%s" WHERE %s`, table, where)
-	_, err := dbSQL.Exec(deleteSql)
-	return err
+// Update 按行标识更新数据
+func (a *KingBaseAdapter) Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
+	return updateRow(db, pgEditor, pgTable(table), data, key)
 }
 
-// ExportToCSV 导出为 CSV
-func (a *KingBaseAdapter) ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
-	dbSQL := db.(*sql.DB)
-	exporter := export.NewCSVExporter(opts)
+// Delete 按行标识删除数据
+func (a *KingBaseAdapter) Delete(db any, database, table string, key model.RowKey) (int64, error) {
+	return deleteRow(db, pgEditor, pgTable(table), key)
+}
 
-	rows, err := dbSQL.Query(query)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
+// ApplyChanges 在单个事务中应用数据变更集
+func (a *KingBaseAdapter) ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	return applyChanges(ctx, db, pgEditor, pgTable(table), changes, preview)
+}
 
-	colNames, err := rows.Columns()
-	if err != nil {
-		return err
-	}
+// ReadCell 按行标识读取单元格完整值（bytea、TEXT）
+func (a *KingBaseAdapter) ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	return readCell(ctx, db, pgEditor, pgTable(table), column, key, fn)
+}
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(colNames))
-		valuePtrs := make([]interface{}, len(colNames))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// WriteCell 按行标识替换单元格的值
+func (a *KingBaseAdapter) WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error) {
+	return writeCell(ctx, db, pgEditor, pgTable(table), column, key, data)
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
-		}
+// StreamQuery 执行查询并以行迭代器逐行读取结果
+func (a *KingBaseAdapter) StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error {
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-		row := make(map[string]interface{})
-		for i, colName := range colNames {
-			val := values[i]
-			if val == nil {
-				row[colName] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[colName] = string(b)
-			} else {
-				row[colName] = val
-			}
-		}
-		rowData = append(rowData, row)
+// StreamTable 以行迭代器逐行读取表数据，limit > 0 时只读取前 limit 行
+func (a *KingBaseAdapter) StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error {
+	query := "SELECT * FROM " + pgTable(table)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-	return exporter.Export(writer, colNames, rowData)
+// ExportToCSV 导出为 CSV，逐行读取并写出
+func (a *KingBaseAdapter) ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
+	return a.StreamQuery(ctx, db, database, query, exportCSV(writer, opts))
 }
 
-// ExportToSQL 导出为 SQL
-func (a *KingBaseAdapter) ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
-	dbSQL := db.(*sql.DB)
+// ExportToSQL 导出为 SQL，表数据逐行读取并写出
+func (a *KingBaseAdapter) ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
 	exporter := export.NewSQLExporter(opts, model.DatabaseKingBase)
 
 	// 如果提供了自定义查询，则按查询导出
 	if opts.Query != "" {
-		rows, err := dbSQL.Query(opts.Query)
-		if err != nil {
-			return err
-		}
-		defer rows.Close()
-
-		colNames, err := rows.Columns()
-		if err != nil {
-			return err
-		}
-
-		var rowData []map[string]interface{}
-		for rows.Next() {
-			values := make([]interface{}, len(colNames))
-			valuePtrs := make([]interface{}, len(colNames))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return err
-			}
-
-			row := make(map[string]interface{})
-			for i, colName := range colNames {
-				val := values[i]
-				if val == nil {
-					row[colName] = nil
-				} else if b, ok := val.([]byte); ok {
-					row[colName] = string(b)
-				} else {
-					row[colName] = val
-				}
-			}
-			rowData = append(rowData, row)
-		}
-
-		// 使用自定义表名，如果没有指定则使用 "query_result" 作为默认值
-		tableName := opts.TableName
-		if tableName == "" {
-			tableName = "query_result"
-		}
-		return exporter.ExportData(writer, "", tableName, colNames, rowData)
+		return a.StreamQuery(ctx, db, database, opts.Query, exportInserts(writer, exporter, "", queryTableName(opts)))
 	}
 
 	for _, table := range tables {
@@ -619,56 +561,14 @@ func (a *KingBaseAdapter) ExportToSQL(db any, writer io.Writer, database string,
 
 		// 导出数据
 		if !opts.StructureOnly {
-			querySQL := fmt.Sprintf(`SELECT * FROM "%s"`, table)
-			if opts.MaxRows > 0 {
-				querySQL = fmt.Sprintf("%s LIMIT %d", querySQL, opts.MaxRows)
-			}
-			rows, err := dbSQL.Query(querySQL)
-			if err != nil {
-				return err
-			}
-
-			colNames, err := rows.Columns()
-			if err != nil {
-				rows.Close()
-				return err
-			}
-
-			var rowData []map[string]interface{}
-			for rows.Next() {
-				values := make([]interface{}, len(colNames))
-				valuePtrs := make([]interface{}, len(colNames))
-				for i := range values {
-					valuePtrs[i] = &values[i]
-				}
-
-				if err := rows.Scan(valuePtrs...); err != nil {
-					rows.Close()
-					return err
-				}
-
-				row := make(map[string]interface{})
-				for i, colName := range colNames {
-					val := values[i]
-					if val == nil {
-						row[colName] = nil
-					} else if b, ok := val.([]byte); ok {
-						row[colName] = string(b)
-					} else {
-						row[colName] = val
-					}
-				}
-				rowData = append(rowData, row)
-			}
-			rows.Close()
-
-			if err := exporter.ExportData(writer, "", table, colNames, rowData); err != nil {
+			if err := a.StreamTable(ctx, db, database, table, opts.MaxRows, exportInserts(writer, exporter, "", table)); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	// 外键引用的表可能排在后面，在所有表之后添加
+	return exporter.ExportForeignKeys(writer)
 }
 
 // GetCreateTableSQL 获取建表语句
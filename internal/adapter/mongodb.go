@@ -2,12 +2,16 @@ package adapter
 
 import (
 	"context"
+	"dbm/internal/export"
 	"dbm/internal/model"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -37,11 +41,24 @@ func (a *MongoDBAdapter) Connect(config *model.ConnectionConfig) (any, error) {
 	} else {
 		return nil, fmt.Errorf("host or uri is required")
 	}
+	// 直连指定节点，不通过副本集发现其他成员（经 SSH 隧道连接时成员地址不可达）
+	if config.Host != "" && config.Params["directConnection"] == "true" {
+		uri += "/?directConnection=true"
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	opts := options.Client().ApplyURI(uri)
+	tlsConfig, _, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	client, err := mongo.Connect(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -167,11 +184,88 @@ func (a *MongoDBAdapter) GetIndexes(db any, database, table string) ([]model.Ind
 	return indexInfos, nil
 }
 
+// runWithKillOp 执行 fn，ctx 取消时通过 killOp 在服务端终止对应操作
+// fn 需要把传入的 comment 写入命令的 comment 字段，以便通过 $currentOp 定位操作；
+// ctx 不可取消时 comment 为空。
+func (a *MongoDBAdapter) runWithKillOp(ctx context.Context, client *mongo.Client, fn func(comment string) error) error {
+	if ctx.Done() == nil {
+		return fn("")
+	}
+
+	comment := "dbm-" + uuid.New().String()
+
+	stop := make(chan struct{})
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		select {
+		case <-ctx.Done():
+			if err := a.killOpsByComment(client, comment); err != nil {
+				log.Printf("终止 MongoDB 操作 %s 失败: %v", comment, err)
+			}
+		case <-stop:
+		}
+	}()
+
+	err := fn(comment)
+
+	close(stop)
+	<-watcherDone
+
+	return err
+}
+
+// withComment 在命令末尾追加 comment 字段，保持命令名为第一个键；comment 为空时原样返回
+func withComment(command bson.D, comment string) bson.D {
+	if comment == "" {
+		return command
+	}
+	return append(command, bson.E{Key: "comment", Value: comment})
+}
+
+// killOpsByComment 查找带有指定 comment 的运行中操作并逐个 killOp
+func (a *MongoDBAdapter) killOpsByComment(client *mongo.Client, comment string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+	defer cancel()
+
+	admin := client.Database("admin")
+	pipeline := mongo.Pipeline{
+		{{Key: "$currentOp", Value: bson.D{}}},
+		{{Key: "$match", Value: bson.D{{Key: "command.comment", Value: comment}}}},
+		{{Key: "$project", Value: bson.D{{Key: "opid", Value: 1}}}},
+	}
+	cursor, err := admin.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var op struct {
+			OpID any `bson:"opid"`
+		}
+		if err := cursor.Decode(&op); err != nil {
+			return err
+		}
+		killCmd := bson.D{{Key: "killOp", Value: 1}, {Key: "op", Value: op.OpID}}
+		if err := admin.RunCommand(ctx, killCmd).Err(); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 // Execute 执行命令
 func (a *MongoDBAdapter) Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
+	return a.ExecuteContext(context.Background(), db, query, args...)
+}
+
+// ExecuteContext 执行命令，ctx 取消时通过 killOp 终止服务端操作
+func (a *MongoDBAdapter) ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
 	// 简单实现：将 query 解析为 BSON 并作为 RunCommand 执行
 	client := db.(*mongo.Client)
-	var command bson.M
+	// 使用 bson.D 保持键的顺序，命令名必须是第一个键
+	var command bson.D
 	if err := bson.UnmarshalExtJSON([]byte(query), false, &command); err != nil {
 		return nil, fmt.Errorf("invalid MongoDB command JSON: %w", err)
 	}
@@ -186,7 +280,9 @@ func (a *MongoDBAdapter) Execute(db any, query string, args ...interface{}) (*mo
 	}
 
 	var result bson.M
-	err := client.Database(dbName).RunCommand(context.Background(), command).Decode(&result)
+	err := a.runWithKillOp(ctx, client, func(comment string) error {
+		return client.Database(dbName).RunCommand(ctx, withComment(command, comment)).Decode(&result)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +295,11 @@ func (a *MongoDBAdapter) Execute(db any, query string, args ...interface{}) (*mo
 
 // Query 执行查询
 func (a *MongoDBAdapter) Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	return a.QueryContext(context.Background(), db, query, opts)
+}
+
+// QueryContext 执行查询，ctx 取消时通过 killOp 终止服务端操作
+func (a *MongoDBAdapter) QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
 	client := db.(*mongo.Client)
 	var command bson.D
 	if err := bson.UnmarshalExtJSON([]byte(query), false, &command); err != nil {
@@ -232,7 +333,9 @@ func (a *MongoDBAdapter) Query(db any, query string, opts *model.QueryOptions) (
 
 	start := time.Now()
 	var result bson.M
-	err := client.Database(opts.Database).RunCommand(context.Background(), command).Decode(&result)
+	err := a.runWithKillOp(ctx, client, func(comment string) error {
+		return client.Database(opts.Database).RunCommand(ctx, withComment(command, comment)).Decode(&result)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +367,7 @@ func (a *MongoDBAdapter) Query(db any, query string, opts *model.QueryOptions) (
 		// 如果没有 cursor，返回原始结果作为单行
 		return &model.QueryResult{
 			Columns:  []string{"result"},
-			Rows:     []map[string]any{result},
+			Rows:     [][]any{{result}},
 			Total:    1,
 			TimeCost: time.Since(start),
 		}, nil
@@ -338,11 +441,35 @@ func (a *MongoDBAdapter) Query(db any, query string, opts *model.QueryOptions) (
 		columns = finalCols
 	}
 
+	// 按列顺序排列，文档缺少的字段为 nil；过大的字符串与 BinData 截断为预览
+	maxSize := maxValueSize(opts)
+	var truncated []model.TruncatedCell
+	rowData := make([][]any, len(rows))
+	for i, row := range rows {
+		values := make([]any, len(columns))
+		for j, col := range columns {
+			val := row[col]
+			if bin, ok := val.(bson.Binary); ok {
+				if preview, size, cut := truncateValue(bin.Data, maxSize); cut {
+					bin.Data = preview.([]byte)
+					val = bin
+					truncated = append(truncated, model.TruncatedCell{Row: i, Column: j, Size: size})
+				}
+			} else if preview, size, cut := truncateValue(val, maxSize); cut {
+				val = preview
+				truncated = append(truncated, model.TruncatedCell{Row: i, Column: j, Size: size})
+			}
+			values[j] = val
+		}
+		rowData[i] = values
+	}
+
 	return &model.QueryResult{
-		Columns:  columns,
-		Rows:     rows,
-		Total:    int64(len(rows)),
-		TimeCost: time.Since(start),
+		Columns:        columns,
+		Rows:           rowData,
+		TruncatedCells: truncated,
+		Total:          int64(len(rows)),
+		TimeCost:       time.Since(start),
 	}, nil
 }
 
@@ -353,72 +480,539 @@ func (a *MongoDBAdapter) Insert(db any, database, table string, data map[string]
 	return err
 }
 
-// Update 更新数据
-func (a *MongoDBAdapter) Update(db any, database, table string, data map[string]interface{}, where string) error {
+// Update 按行标识更新文档
+func (a *MongoDBAdapter) Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
 	client := db.(*mongo.Client)
-	var filter bson.M
-	if err := bson.UnmarshalExtJSON([]byte(where), true, &filter); err != nil {
-		return fmt.Errorf("invalid MongoDB filter JSON: %w", err)
+	ctx := context.Background()
+	coll := client.Database(database).Collection(table)
+
+	filter, _, err := a.keyFilter(ctx, coll, key)
+	if err != nil {
+		return 0, err
 	}
 
-	_, err := client.Database(database).Collection(table).UpdateMany(context.Background(), filter, bson.M{"$set": data})
-	return err
+	// _id 不可修改，结果中的 _id 为序列化后的字符串，写回会被视为修改
+	set := bson.M{}
+	for k, v := range data {
+		if k != "_id" {
+			set[k] = v
+		}
+	}
+	if len(set) == 0 {
+		return 0, fmt.Errorf("没有需要更新的字段")
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
 }
 
-// Delete 删除数据
-func (a *MongoDBAdapter) Delete(db any, database, table, where string) error {
+// Delete 按行标识删除文档
+func (a *MongoDBAdapter) Delete(db any, database, table string, key model.RowKey) (int64, error) {
 	client := db.(*mongo.Client)
-	var filter bson.M
-	if err := bson.UnmarshalExtJSON([]byte(where), true, &filter); err != nil {
-		return fmt.Errorf("invalid MongoDB filter JSON: %w", err)
+	ctx := context.Background()
+	coll := client.Database(database).Collection(table)
+
+	filter, _, err := a.keyFilter(ctx, coll, key)
+	if err != nil {
+		return 0, err
 	}
 
-	_, err := client.Database(database).Collection(table).DeleteMany(context.Background(), filter)
-	return err
+	result, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
 }
 
-// ExportToCSV 导出 CSV
-func (a *MongoDBAdapter) ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
-	qOpts := &model.QueryOptions{
-		Database: database,
+// InsertRows 使用 insertMany 批量写入文档，值为 nil 的字段不写入
+func (a *MongoDBAdapter) InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
 	}
-	result, err := a.Query(db, query, qOpts)
+	client := db.(*mongo.Client)
+	coll := client.Database(database).Collection(table)
+
+	docs := make([]any, len(rows))
+	for i, row := range rows {
+		doc := make(bson.D, 0, len(columns))
+		for j, col := range columns {
+			if row[j] != nil {
+				doc = append(doc, bson.E{Key: col, Value: row[j]})
+			}
+		}
+		docs[i] = doc
+	}
+
+	// 有序写入，出错时返回出错前已写入的文档数
+	result, err := coll.InsertMany(ctx, docs)
+	if err != nil {
+		var bwe mongo.BulkWriteException
+		if errors.As(err, &bwe) && len(bwe.WriteErrors) > 0 {
+			return int64(bwe.WriteErrors[0].Index), err
+		}
+		return 0, err
+	}
+	return int64(len(result.InsertedIDs)), nil
+}
+
+// CreateTable 创建集合，MongoDB 无固定结构，忽略列定义
+func (a *MongoDBAdapter) CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error {
+	client := db.(*mongo.Client)
+	return client.Database(database).CreateCollection(ctx, table)
+}
+
+// CountRows 统计集合的文档数
+func (a *MongoDBAdapter) CountRows(ctx context.Context, db any, database, table string) (int64, error) {
+	client := db.(*mongo.Client)
+	return client.Database(database).Collection(table).CountDocuments(ctx, bson.D{})
+}
+
+// ReadCell 按行标识读取字段完整值
+// BinData 为原始字节，字符串为 UTF-8 文本，其他类型为扩展 JSON；文档大小受 MongoDB 16MB 上限约束。
+func (a *MongoDBAdapter) ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	client := db.(*mongo.Client)
+	coll := client.Database(database).Collection(table)
+
+	filter, n, err := a.keyFilter(ctx, coll, key)
 	if err != nil {
 		return err
 	}
+	if n == 0 {
+		return ErrRowNotFound
+	}
 
-	csvWriter := csv.NewWriter(writer)
-	if opts != nil && opts.Separator != "" {
-		csvWriter.Comma = rune(opts.Separator[0])
+	var doc bson.Raw
+	err = coll.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{column: 1})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrRowNotFound
+	}
+	if err != nil {
+		return err
 	}
 
-	// 写入表头
-	if err := csvWriter.Write(result.Columns); err != nil {
+	// 文档缺少该字段时 Type 为零值，与 null 一样视为 NULL
+	value := doc.Lookup(column)
+	switch value.Type {
+	case 0, bson.TypeNull:
+		return fn(nil)
+	case bson.TypeBinary:
+		_, data := value.Binary()
+		if data == nil {
+			data = []byte{}
+		}
+		return fn(data)
+	case bson.TypeString:
+		return fn([]byte(value.StringValue()))
+	}
+	var v any
+	if err := value.Unmarshal(&v); err != nil {
 		return err
 	}
+	return fn([]byte(extJSON(bson.M{column: v})))
+}
 
-	// 写入数据
-	for _, row := range result.Rows {
-		record := make([]string, len(result.Columns))
-		for i, col := range result.Columns {
-			val := row[col]
-			if val == nil {
-				record[i] = ""
-				continue
+// WriteCell 按行标识将字段替换为 BinData（通用二进制子类型）
+func (a *MongoDBAdapter) WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error) {
+	if column == "_id" {
+		return 0, fmt.Errorf("_id 字段不可修改")
+	}
+	client := db.(*mongo.Client)
+	coll := client.Database(database).Collection(table)
+
+	filter, _, err := a.keyFilter(ctx, coll, key)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{column: bson.Binary{Subtype: bson.TypeBinaryGeneric, Data: data}}})
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+// keyFilter 将行标识转换为查询条件，并确认最多匹配一个文档，返回匹配数量
+func (a *MongoDBAdapter) keyFilter(ctx context.Context, coll *mongo.Collection, key model.RowKey) (bson.M, int64, error) {
+	if len(key) == 0 {
+		return nil, 0, ErrRowKeyRequired
+	}
+
+	filter := bson.M{}
+	for k, v := range key {
+		filter[k] = v
+	}
+	// 查询结果中的 ObjectID 以十六进制字符串返回，同时匹配 ObjectID 与原字符串
+	if id, ok := key["_id"].(string); ok {
+		if oid, err := bson.ObjectIDFromHex(id); err == nil {
+			filter["_id"] = bson.M{"$in": bson.A{oid, id}}
+		}
+	}
+
+	n, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(2))
+	if err != nil {
+		return nil, 0, err
+	}
+	if n > 1 {
+		return nil, n, fmt.Errorf("%w（匹配 %d 个文档）", ErrRowKeyNotUnique, n)
+	}
+	return filter, n, nil
+}
+
+// ApplyChanges 使用 bulkWrite 在事务中应用数据变更集
+// MongoDB 事务要求副本集或分片集群，单机部署会返回错误。
+func (a *MongoDBAdapter) ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	start := time.Now()
+	client := db.(*mongo.Client)
+	coll := client.Database(database).Collection(table)
+	result := &model.ChangeSetResult{}
+
+	var (
+		models   []mongo.WriteModel
+		expected []int64
+		ok       = true
+	)
+	add := func(op model.ChangeOp, index int, build func() (mongo.WriteModel, string, int64, error)) {
+		row := model.RowChangeResult{Op: op, Index: index, Status: model.StatementPending}
+		m, statement, n, err := build()
+		row.Statement = statement
+		if err != nil {
+			row.Status = model.StatementError
+			row.Error = err.Error()
+			ok = false
+		}
+		models = append(models, m)
+		expected = append(expected, n)
+		result.Rows = append(result.Rows, row)
+	}
+
+	for i, key := range changes.Deletes {
+		add(model.ChangeDelete, i, func() (mongo.WriteModel, string, int64, error) {
+			filter, n, err := a.keyFilter(ctx, coll, key)
+			if err != nil {
+				return nil, "", 0, err
+			}
+			statement := fmt.Sprintf("db.%s.deleteOne(%s)", table, extJSON(filter))
+			return mongo.NewDeleteOneModel().SetFilter(filter), statement, n, nil
+		})
+	}
+	for i, change := range changes.Updates {
+		add(model.ChangeUpdate, i, func() (mongo.WriteModel, string, int64, error) {
+			filter, n, err := a.keyFilter(ctx, coll, change.Key)
+			if err != nil {
+				return nil, "", 0, err
 			}
-			record[i] = fmt.Sprintf("%v", val)
+			set := bson.M{}
+			for k, v := range change.Data {
+				if k != "_id" {
+					set[k] = v
+				}
+			}
+			if len(set) == 0 {
+				return nil, "", 0, fmt.Errorf("没有需要更新的字段")
+			}
+			update := bson.M{"$set": set}
+			statement := fmt.Sprintf("db.%s.updateOne(%s, %s)", table, extJSON(filter), extJSON(update))
+			return mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update), statement, n, nil
+		})
+	}
+	for i, doc := range changes.Inserts {
+		add(model.ChangeInsert, i, func() (mongo.WriteModel, string, int64, error) {
+			if len(doc) == 0 {
+				return nil, "", 0, fmt.Errorf("插入的文档没有字段")
+			}
+			statement := fmt.Sprintf("db.%s.insertOne(%s)", table, extJSON(doc))
+			return mongo.NewInsertOneModel().SetDocument(doc), statement, 1, nil
+		})
+	}
+
+	if preview || !ok || len(models) == 0 {
+		result.Committed = ok && !preview
+		result.TimeCost = time.Since(start)
+		return result, nil
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc context.Context) (any, error) {
+		return coll.BulkWrite(sc, models, options.BulkWrite().SetOrdered(true))
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		if err := csvWriter.Write(record); err != nil {
+		var bwe mongo.BulkWriteException
+		if !errors.As(err, &bwe) || len(bwe.WriteErrors) == 0 {
+			return nil, err
+		}
+
+		// 有序写入在第一个错误处停止，事务回滚
+		failed := bwe.WriteErrors[0]
+		for i := range result.Rows {
+			switch {
+			case i < failed.Index:
+				result.Rows[i].Status = model.StatementSuccess
+				result.Rows[i].RowsAffected = expected[i]
+			case i == failed.Index:
+				result.Rows[i].Status = model.StatementError
+				result.Rows[i].Error = failed.Message
+			default:
+				result.Rows[i].Status = model.StatementSkipped
+			}
+		}
+		result.TimeCost = time.Since(start)
+		return result, nil
+	}
+
+	for i := range result.Rows {
+		result.Rows[i].Status = model.StatementSuccess
+		result.Rows[i].RowsAffected = expected[i]
+		result.RowsAffected += expected[i]
+	}
+	result.Committed = true
+	result.TimeCost = time.Since(start)
+	return result, nil
+}
+
+// extJSON 将文档格式化为扩展 JSON，用于展示
+func extJSON(doc any) string {
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return fmt.Sprint(doc)
+	}
+	return string(data)
+}
+
+// mongoSampleDocs 导出时用于确定列的文档数，之后的文档中新出现的字段不导出
+const mongoSampleDocs = 1000
+
+// StreamQuery 执行查询并以行迭代器逐行读取结果
+// find 与 aggregate 命令通过游标逐批读取，列由前 mongoSampleDocs 个文档的字段确定；
+// 其他命令只有一个结果文档，按查询结果读取。
+func (a *MongoDBAdapter) StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error {
+	client := db.(*mongo.Client)
+
+	cursor, err := a.openCursor(ctx, client, database, query)
+	if err != nil {
+		return err
+	}
+	if cursor == nil {
+		result, err := a.QueryContext(ctx, db, query, &model.QueryOptions{Database: database, MaxValueSize: math.MaxInt})
+		if err != nil {
 			return err
 		}
+		return fn(export.CountRows(ctx, export.NewSliceRows(result.Columns, result.Rows)))
 	}
+	return streamCursor(ctx, cursor, fn)
+}
+
+// StreamTable 以行迭代器逐行读取集合中的文档，limit > 0 时只读取前 limit 个
+func (a *MongoDBAdapter) StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error {
+	client := db.(*mongo.Client)
+
+	findOpts := options.Find()
+	if limit > 0 {
+		findOpts.SetLimit(int64(limit))
+	}
+	cursor, err := client.Database(database).Collection(table).Find(ctx, bson.D{}, findOpts)
+	if err != nil {
+		return err
+	}
+	return streamCursor(ctx, cursor, fn)
+}
 
-	csvWriter.Flush()
-	return csvWriter.Error()
+// streamCursor 以行迭代器读取游标并在 fn 返回后关闭游标
+func streamCursor(ctx context.Context, cursor *mongo.Cursor, fn func(rows export.RowIterator) error) error {
+	defer cursor.Close(ctx)
+
+	rows, err := newMongoRows(ctx, cursor, mongoSampleDocs)
+	if err != nil {
+		return err
+	}
+	if err := fn(export.CountRows(ctx, rows)); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// ExportToCSV 导出 CSV
+func (a *MongoDBAdapter) ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
+	return a.StreamQuery(ctx, db, database, query, exportCSV(writer, opts))
+}
+
+// openCursor 为 find 与 aggregate 命令打开游标，其他命令返回 nil
+// 与 QueryContext 相同，无法解析为 JSON 的查询视为集合名称。
+func (a *MongoDBAdapter) openCursor(ctx context.Context, client *mongo.Client, database, query string) (*mongo.Cursor, error) {
+	var command bson.D
+	if err := bson.UnmarshalExtJSON([]byte(query), false, &command); err != nil {
+		command = bson.D{{Key: "find", Value: query}}
+	}
+	if len(command) == 0 {
+		return nil, nil
+	}
+
+	name, ok := command[0].Value.(string)
+	if !ok {
+		return nil, nil
+	}
+	coll := client.Database(database).Collection(name)
+
+	switch command[0].Key {
+	case "find":
+		filter := any(bson.D{})
+		findOpts := options.Find()
+		for _, e := range command[1:] {
+			switch e.Key {
+			case "filter":
+				filter = e.Value
+			case "projection":
+				findOpts.SetProjection(e.Value)
+			case "sort":
+				findOpts.SetSort(e.Value)
+			case "skip":
+				findOpts.SetSkip(bsonInt64(e.Value))
+			case "limit":
+				findOpts.SetLimit(bsonInt64(e.Value))
+			case "batchSize":
+				findOpts.SetBatchSize(int32(bsonInt64(e.Value)))
+			}
+		}
+		return coll.Find(ctx, filter, findOpts)
+	case "aggregate":
+		var pipeline any = bson.A{}
+		for _, e := range command[1:] {
+			if e.Key == "pipeline" {
+				pipeline = e.Value
+			}
+		}
+		return coll.Aggregate(ctx, pipeline)
+	}
+	return nil, nil
+}
+
+// bsonInt64 将扩展 JSON 中的数字转换为 int64
+func bsonInt64(v any) int64 {
+	switch n := v.(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// mongoRows 基于游标的导出行迭代器
+// 先读取 sample 个文档确定列，之后逐个读取文档，文档缺少的字段为 nil。
+type mongoRows struct {
+	ctx      context.Context
+	cursor   *mongo.Cursor
+	columns  []string
+	index    map[string]int
+	buffered []bson.D
+	row      []any
+	err      error
+}
+
+// newMongoRows 读取样本文档并创建行迭代器
+func newMongoRows(ctx context.Context, cursor *mongo.Cursor, sample int) (*mongoRows, error) {
+	r := &mongoRows{ctx: ctx, cursor: cursor, index: make(map[string]int)}
+
+	var columns []string
+	hasID := false
+	for len(r.buffered) < sample && cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		r.buffered = append(r.buffered, doc)
+		for _, e := range doc {
+			if _, ok := r.index[e.Key]; ok {
+				continue
+			}
+			r.index[e.Key] = 0
+			if e.Key == "_id" {
+				hasID = true
+				continue
+			}
+			columns = append(columns, e.Key)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	// _id 置于首列
+	if hasID {
+		columns = append([]string{"_id"}, columns...)
+	}
+	for i, col := range columns {
+		r.index[col] = i
+	}
+	r.columns = columns
+	r.row = make([]any, len(columns))
+	return r, nil
+}
+
+func (r *mongoRows) Columns() []string { return r.columns }
+
+func (r *mongoRows) Next() bool {
+	var doc bson.D
+	if len(r.buffered) > 0 {
+		doc, r.buffered = r.buffered[0], r.buffered[1:]
+	} else {
+		if r.err != nil || !r.cursor.Next(r.ctx) {
+			return false
+		}
+		if err := r.cursor.Decode(&doc); err != nil {
+			r.err = err
+			return false
+		}
+	}
+
+	clear(r.row)
+	for _, e := range doc {
+		if i, ok := r.index[e.Key]; ok {
+			r.row[i] = exportBSONValue(e.Value)
+		}
+	}
+	return true
+}
+
+func (r *mongoRows) Values() []any { return r.row }
+
+func (r *mongoRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.cursor.Err()
+}
+
+// exportBSONValue 将 BSON 值转换为导出使用的值，嵌套文档、数组与二进制数据格式化为扩展 JSON
+func exportBSONValue(v any) any {
+	switch val := v.(type) {
+	case bson.ObjectID:
+		return val.Hex()
+	case bson.DateTime:
+		return val.Time()
+	case bson.Decimal128:
+		return val.String()
+	case bson.D, bson.M, bson.A, bson.Binary:
+		return extJSON(val)
+	default:
+		return v
+	}
 }
 
 // ExportToSQL 导出 SQL
-func (a *MongoDBAdapter) ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
+func (a *MongoDBAdapter) ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
 	return fmt.Errorf("not applicable for MongoDB")
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 func TestMongoDBAdapter_Connect(t *testing.T) {
@@ -32,3 +33,24 @@ func TestMongoDBAdapter_Metadata(t *testing.T) {
 	// These would require a mock or a live connection.
 	// For now, let's just test that the functions exist and have correct signatures.
 }
+
+// TestWithComment 追加 comment 后命令名仍是第一个键
+func TestWithComment(t *testing.T) {
+	var command bson.D
+	err := bson.UnmarshalExtJSON([]byte(`{"createIndexes": "users", "indexes": [], "writeConcern": {"w": 1}, "maxTimeMS": 100}`), false, &command)
+	assert.NoError(t, err)
+
+	got := withComment(command, "dbm-1")
+	assert.Equal(t, []string{"createIndexes", "indexes", "writeConcern", "maxTimeMS", "comment"}, docKeys(got))
+	assert.Equal(t, "dbm-1", got[len(got)-1].Value)
+	assert.Equal(t, command, withComment(command, ""))
+}
+
+// docKeys 返回文档中按顺序排列的键
+func docKeys(doc bson.D) []string {
+	var names []string
+	for _, e := range doc {
+		names = append(names, e.Key)
+	}
+	return names
+}
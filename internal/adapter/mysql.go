@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"dbm/internal/export"
 	"dbm/internal/model"
@@ -9,7 +10,7 @@ import (
 	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 // MySQLAdapter MySQL 数据库适配器
@@ -26,12 +27,26 @@ func NewMySQLAdapter() *MySQLAdapter {
 
 // Connect 连接 MySQL 数据库
 func (a *MySQLAdapter) Connect(config *model.ConnectionConfig) (any, error) {
-	dsn := a.buildDSN(config)
-	db, err := sql.Open("mysql", dsn)
+	cfg, err := mysql.ParseDSN(a.buildDSN(config))
 	if err != nil {
 		return nil, err
 	}
 
+	// TLS 配置优先于参数中的 tls 选项
+	tlsConfig, _, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		cfg.TLS = tlsConfig
+	}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+
 	if err := db.Ping(); err != nil {
 		return nil, err
 	}
@@ -230,6 +245,17 @@ func (a *MySQLAdapter) GetTableSchema(db any, database, table string) (*model.Ta
 		schema.Indexes = append(schema.Indexes, *idx)
 	}
 
+	// 获取外键信息
+	schema.Constraints, err = queryForeignKeys(dbSQL, `
+		SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION
+	`, database, table)
+	if err != nil {
+		return nil, err
+	}
+
 	return schema, nil
 }
 
@@ -388,10 +414,19 @@ func (a *MySQLAdapter) GetIndexes(db any, database, table string) ([]model.Index
 
 // Execute 执行非查询 SQL
 func (a *MySQLAdapter) Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.ExecuteContext(context.Background(), db, query, args...)
+}
+
+// ExecuteContext 执行非查询 SQL，ctx 取消时通过 KILL QUERY 终止服务端语句
+func (a *MySQLAdapter) ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
 	start := time.Now()
 
-	result, err := dbSQL.Exec(query, args...)
+	var result sql.Result
+	err := runWithKill(ctx, db, mysqlKiller, func(q sqlQueryer) error {
+		var err error
+		result, err = q.ExecContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -407,7 +442,22 @@ func (a *MySQLAdapter) Execute(db any, query string, args ...interface{}) (*mode
 
 // Query 执行查询
 func (a *MySQLAdapter) Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.QueryContext(context.Background(), db, query, opts)
+}
+
+// QueryContext 执行查询，ctx 取消时通过 KILL QUERY 终止服务端语句
+func (a *MySQLAdapter) QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	var result *model.QueryResult
+	err := runWithKill(ctx, db, mysqlKiller, func(q sqlQueryer) error {
+		var err error
+		result, err = a.query(ctx, q, query, opts)
+		return err
+	})
+	return result, err
+}
+
+// query 在指定会话上执行查询
+func (a *MySQLAdapter) query(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
 	start := time.Now()
 
 	trimQuery := strings.TrimSpace(strings.ToUpper(query))
@@ -419,7 +469,7 @@ func (a *MySQLAdapter) Query(db any, query string, opts *model.QueryOptions) (*m
 		strings.HasPrefix(trimQuery, "PRAGMA")
 
 	if !isQuery {
-		result, err := dbSQL.Exec(query)
+		result, err := q.ExecContext(ctx, query)
 		if err != nil {
 			return nil, err
 		}
@@ -431,226 +481,110 @@ func (a *MySQLAdapter) Query(db any, query string, opts *model.QueryOptions) (*m
 		}, nil
 	}
 
-	rows, err := dbSQL.Query(query)
+	rows, err := mysqlPager.query(ctx, q, query, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
+	result, err := scanResult(rows, opts, nil)
 	if err != nil {
 		return nil, err
 	}
+	// 统计总数前释放当前会话上的结果集
+	rows.Close()
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		// 创建扫描目标
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		// 转换为 map
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			// 处理 NULL 值
-			if val == nil {
-				row[col] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
-		rowData = append(rowData, row)
+	result.Message = "查询成功"
+	if err := mysqlPager.fillTotal(ctx, q, query, opts, result); err != nil {
+		return nil, err
 	}
+	result.TimeCost = time.Since(start)
 
-	return &model.QueryResult{
-		Columns:  columns,
-		Rows:     rowData,
-		Total:    int64(len(rowData)),
-		Message:  "查询成功",
-		TimeCost: time.Since(start),
-	}, nil
+	return result, nil
 }
 
 // Insert 插入数据
 func (a *MySQLAdapter) Insert(db any, database, table string, data map[string]interface{}) error {
 	dbSQL := db.(*sql.DB)
-	columns := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-
-	for col, val := range data {
-		columns = append(columns, fmt.Sprintf("`%s`", col))
-		placeholders = append(placeholders, "?")
-		values = append(values, val)
-	}
-
-	query := fmt.Sprintf("INSERT INTO `%s`.`%s` (%s) VALUES (%s)",
-		database, table,
-		strings.Join(columns, ", "),
-		strings.Join(placeholders, ", "))
-
-	_, err := dbSQL.Exec(query, values...)
+	stmt := backtickEditor.Insert(qualify(quoteBacktick, database, table), data)
+	_, err := dbSQL.Exec(stmt.Query, stmt.Args...)
 	return err
 }
 
-// Update 更新数据
-func (a *MySQLAdapter) Update(db any, database, table string, data map[string]interface{}, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("更新操作必须指定 WHERE 条件")
-	}
-
-	sets := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-
-	for col, val := range data {
-		sets = append(sets, fmt.Sprintf("`%s` = ?", col))
-		values = append(values, val)
-	}
-
-	query := fmt.Sprintf("UPDATE `%s`.`%s` SET %s WHERE %s",
-		database, table,
-		strings.Join(sets, ", "),
-		where)
-
-	_, err := dbSQL.Exec(query, values...)
-	return err
+// InsertRows 批量写入（多行 INSERT）
+func (a *MySQLAdapter) InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error) {
+	return insertValues(ctx, db, backtickEditor, qualify(quoteBacktick, database, table), columns, rows, mysqlMaxParams)
 }
 
-// Delete 删除数据
-func (a *MySQLAdapter) Delete(db any, database, table, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("删除操作必须指定 WHERE 条件")
-	}
+// CreateTable 按列定义建表
+func (a *MySQLAdapter) CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error {
+	return createTable(ctx, db, quoteBacktick, qualify(quoteBacktick, database, table), columns, a.buildColumnType, "")
+}
 
-	query := fmt.Sprintf("DELETE FROM `%s`.`%s` WHERE %s", database, table, where)
-	_, err := dbSQL.Exec(query)
-	return err
+// CountRows 统计表的行数
+func (a *MySQLAdapter) CountRows(ctx context.Context, db any, database, table string) (int64, error) {
+	return countRows(ctx, db, qualify(quoteBacktick, database, table))
 }
 
-// ExportToCSV 导出为 CSV
-func (a *MySQLAdapter) ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
-	dbSQL := db.(*sql.DB)
-	exporter := export.NewCSVExporter(opts)
+// Update 按行标识更新数据
+func (a *MySQLAdapter) Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
+	return updateRow(db, backtickEditor, qualify(quoteBacktick, database, table), data, key)
+}
 
-	rows, err := dbSQL.Query(query)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
+// Delete 按行标识删除数据
+func (a *MySQLAdapter) Delete(db any, database, table string, key model.RowKey) (int64, error) {
+	return deleteRow(db, backtickEditor, qualify(quoteBacktick, database, table), key)
+}
 
-	columns, err := rows.ColumnTypes()
-	if err != nil {
-		return err
-	}
+// ApplyChanges 在单个事务中应用数据变更集
+func (a *MySQLAdapter) ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	return applyChanges(ctx, db, backtickEditor, qualify(quoteBacktick, database, table), changes, preview)
+}
 
-	colNames := make([]string, len(columns))
-	for i, col := range columns {
-		colNames[i] = col.Name()
-	}
+// ReadCell 按行标识读取单元格完整值（BLOB、TEXT）
+func (a *MySQLAdapter) ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	return readCell(ctx, db, backtickEditor, qualify(quoteBacktick, database, table), column, key, fn)
+}
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// WriteCell 按行标识替换单元格的值
+func (a *MySQLAdapter) WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error) {
+	return writeCell(ctx, db, backtickEditor, qualify(quoteBacktick, database, table), column, key, data)
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
-		}
+// StreamQuery 执行查询并以行迭代器逐行读取结果
+func (a *MySQLAdapter) StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error {
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			if val == nil {
-				row[col.Name()] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col.Name()] = string(b)
-			} else {
-				row[col.Name()] = val
-			}
-		}
-		rowData = append(rowData, row)
+// StreamTable 以行迭代器逐行读取表数据，limit > 0 时只读取前 limit 行
+func (a *MySQLAdapter) StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error {
+	query := "SELECT * FROM " + qualify(quoteBacktick, database, table)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-	return exporter.Export(writer, colNames, rowData)
+// ExportToCSV 导出为 CSV，逐行读取并写出
+func (a *MySQLAdapter) ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
+	return a.StreamQuery(ctx, db, database, query, exportCSV(writer, opts))
 }
 
-// ExportToSQL 导出为 SQL
-func (a *MySQLAdapter) ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
-	dbSQL := db.(*sql.DB)
+// ExportToSQL 导出为 SQL，表数据逐行读取并写出
+func (a *MySQLAdapter) ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
 	exporter := export.NewSQLExporter(opts, model.DatabaseMySQL)
 
 	// 如果提供了自定义查询，则按查询导出
 	if opts.Query != "" {
-		rows, err := dbSQL.Query(opts.Query)
-		if err != nil {
-			return err
-		}
-		defer rows.Close()
-
-		columns, err := rows.ColumnTypes()
-		if err != nil {
-			return err
-		}
-
-		colNames := make([]string, len(columns))
-		for i, col := range columns {
-			colNames[i] = col.Name()
-		}
-
-		var rowData []map[string]interface{}
-		for rows.Next() {
-			values := make([]interface{}, len(columns))
-			valuePtrs := make([]interface{}, len(columns))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return err
-			}
-
-			row := make(map[string]interface{})
-			for i, col := range columns {
-				val := values[i]
-				if val == nil {
-					row[col.Name()] = nil
-				} else if b, ok := val.([]byte); ok {
-					row[col.Name()] = string(b)
-				} else {
-					row[col.Name()] = val
-				}
-			}
-			rowData = append(rowData, row)
-		}
-
-		// 使用自定义表名，如果没有指定则使用 "query_result" 作为默认值
-		tableName := opts.TableName
-		if tableName == "" {
-			tableName = "query_result"
-		}
-		return exporter.ExportData(writer, "", tableName, colNames, rowData)
+		return a.StreamQuery(ctx, db, database, opts.Query, exportInserts(writer, exporter, "", queryTableName(opts)))
 	}
 
 	for _, table := range tables {
 		// 导出表结构
 		if opts.IncludeCreateTable || opts.StructureOnly {
 			var createSQL string
-			// 尝试获取原生建表语句
-			if rawSQL, err := a.GetCreateTableSQL(db, database, table); err == nil {
+			// 同类数据库之间尝试获取原生建表语句，跨数据库导出时按目标方言重建
+			if rawSQL, err := a.GetCreateTableSQL(db, database, table); err == nil && exporter.SameDialect() {
 				createSQL = rawSQL + ";\n\n"
 				if opts.IncludeDropTable {
 					if database != "" {
@@ -663,7 +597,7 @@ func (a *MySQLAdapter) ExportToSQL(db any, writer io.Writer, database string, ta
 					return err
 				}
 			} else {
-				// 降级：使用通用 Exporter 重建（如果获取原生失败）
+				// 降级：使用通用 Exporter 重建（跨数据库导出或获取原生失败）
 				schema, err := a.GetTableSchema(db, database, table)
 				if err != nil {
 					return err
@@ -676,61 +610,14 @@ func (a *MySQLAdapter) ExportToSQL(db any, writer io.Writer, database string, ta
 
 		// 导出数据
 		if !opts.StructureOnly {
-			query := fmt.Sprintf("SELECT * FROM `%s`", table)
-			if opts.MaxRows > 0 {
-				query = fmt.Sprintf("%s LIMIT %d", query, opts.MaxRows)
-			}
-			rows, err := dbSQL.Query(query)
-			if err != nil {
-				return err
-			}
-
-			columns, err := rows.ColumnTypes()
-			if err != nil {
-				rows.Close()
-				return err
-			}
-
-			colNames := make([]string, len(columns))
-			for i, col := range columns {
-				colNames[i] = col.Name()
-			}
-
-			var rowData []map[string]interface{}
-			for rows.Next() {
-				values := make([]interface{}, len(columns))
-				valuePtrs := make([]interface{}, len(columns))
-				for i := range values {
-					valuePtrs[i] = &values[i]
-				}
-
-				if err := rows.Scan(valuePtrs...); err != nil {
-					rows.Close()
-					return err
-				}
-
-				row := make(map[string]interface{})
-				for i, col := range columns {
-					val := values[i]
-					if val == nil {
-						row[col.Name()] = nil
-					} else if b, ok := val.([]byte); ok {
-						row[col.Name()] = string(b)
-					} else {
-						row[col.Name()] = val
-					}
-				}
-				rowData = append(rowData, row)
-			}
-			rows.Close()
-
-			if err := exporter.ExportData(writer, "", table, colNames, rowData); err != nil {
+			if err := a.StreamTable(ctx, db, database, table, opts.MaxRows, exportInserts(writer, exporter, "", table)); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	// 外键引用的表可能排在后面，在所有表之后添加
+	return exporter.ExportForeignKeys(writer)
 }
 
 // GetCreateTableSQL 获取建表语句
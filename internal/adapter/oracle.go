@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"dbm/internal/export"
 	"dbm/internal/model"
@@ -29,11 +30,22 @@ func NewOracleAdapter() *OracleAdapter {
 // Connect 连接 Oracle 数据库
 func (a *OracleAdapter) Connect(config *model.ConnectionConfig) (any, error) {
 	dsn := a.buildDSN(config)
-	db, err := sql.Open("oracle", dsn)
+
+	// 启用 TLS 时以 TCPS 连接，tls.Config 优先于参数中的 WALLET
+	tlsConfig, _, err := newTLSConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
+	var db *sql.DB
+	if tlsConfig != nil {
+		connector := go_ora.NewConnector(dsn).(*go_ora.OracleConnector)
+		connector.WithTLSConfig(tlsConfig)
+		db = sql.OpenDB(connector)
+	} else if db, err = sql.Open("oracle", dsn); err != nil {
+		return nil, err
+	}
+
 	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, err
@@ -65,6 +77,10 @@ func (a *OracleAdapter) buildDSN(config *model.ConnectionConfig) string {
 		}
 	}
 
+	if config.TLS.Enabled() {
+		options["SSL"] = "true"
+	}
+
 	// BuildUrl(server, port, service, user, password, options)
 	return go_ora.BuildUrl(config.Host, config.Port, service, config.Username, config.Password, options)
 }
@@ -225,9 +241,31 @@ func (a *OracleAdapter) GetTableSchema(db any, database, table string) (*model.T
 		tableSchema.Indexes = append(tableSchema.Indexes, *idx)
 	}
 
+	// 获取外键信息
+	tableSchema.Constraints, err = queryForeignKeys(dbSQL, oracleForeignKeysQuery, strings.ToUpper(database), strings.ToUpper(table))
+	if err != nil {
+		return nil, err
+	}
+
 	return tableSchema, nil
 }
 
+// oracleForeignKeysQuery 查询 Oracle 与 DM 表的外键，引用的列按位置与外键列对应
+const oracleForeignKeysQuery = `
+	SELECT
+		c.CONSTRAINT_NAME,
+		cc.COLUMN_NAME,
+		rc.TABLE_NAME,
+		rcc.COLUMN_NAME
+	FROM ALL_CONSTRAINTS c
+	JOIN ALL_CONS_COLUMNS cc ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+	JOIN ALL_CONSTRAINTS rc ON rc.OWNER = c.R_OWNER AND rc.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME
+	JOIN ALL_CONS_COLUMNS rcc ON rcc.OWNER = rc.OWNER AND rcc.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+		AND rcc.POSITION = cc.POSITION
+	WHERE c.CONSTRAINT_TYPE = 'R' AND c.OWNER = :1 AND c.TABLE_NAME = :2
+	ORDER BY c.CONSTRAINT_NAME, cc.POSITION
+`
+
 func (a *OracleAdapter) buildTypeString(dataType string, length, precision, scale sql.NullInt64) string {
 	dt := strings.ToUpper(dataType)
 	switch dt {
@@ -288,11 +326,20 @@ func (a *OracleAdapter) GetIndexes(db any, database, table string) ([]model.Inde
 
 // Execute 执行非查询 SQL
 func (a *OracleAdapter) Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.ExecuteContext(context.Background(), db, query, args...)
+}
+
+// ExecuteContext 执行非查询 SQL，ctx 取消时由驱动发送 break 包中断服务端执行
+func (a *OracleAdapter) ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
 	start := time.Now()
 
 	query = a.rewriteQuery(query)
-	result, err := dbSQL.Exec(query, args...)
+	var result sql.Result
+	err := runWithKill(ctx, db, nil, func(q sqlQueryer) error {
+		var err error
+		result, err = q.ExecContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -308,173 +355,127 @@ func (a *OracleAdapter) Execute(db any, query string, args ...interface{}) (*mod
 
 // Query 执行查询
 func (a *OracleAdapter) Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.QueryContext(context.Background(), db, query, opts)
+}
+
+// QueryContext 执行查询，ctx 取消时由驱动发送 break 包中断服务端执行
+func (a *OracleAdapter) QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	var result *model.QueryResult
+	err := runWithKill(ctx, db, nil, func(q sqlQueryer) error {
+		var err error
+		result, err = a.query(ctx, q, query, opts)
+		return err
+	})
+	return result, err
+}
+
+// query 在指定会话上执行查询
+func (a *OracleAdapter) query(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
 	start := time.Now()
 
 	query = a.rewriteQuery(query)
-	rows, err := dbSQL.Query(query)
+	rows, err := oraclePager.query(ctx, q, query, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
+	// 剔除 ROWNUM 分页引入的辅助列
+	result, err := scanResult(rows, opts, nil, rownumColumn)
 	if err != nil {
 		return nil, err
 	}
+	// 统计总数前释放当前会话上的结果集
+	rows.Close()
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			if val == nil {
-				row[col] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
-		rowData = append(rowData, row)
+	result.Message = "查询成功"
+	if err := oraclePager.fillTotal(ctx, q, query, opts, result); err != nil {
+		return nil, err
 	}
+	result.TimeCost = time.Since(start)
 
-	return &model.QueryResult{
-		Columns:  columns,
-		Rows:     rowData,
-		Total:    int64(len(rowData)),
-		Message:  "查询成功",
-		TimeCost: time.Since(start),
-	}, nil
+	return result, nil
 }
 
 // Insert 插入数据
 func (a *OracleAdapter) Insert(db any, database, table string, data map[string]interface{}) error {
 	dbSQL := db.(*sql.DB)
-	cols := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-	argNum := 1
-
-	for col, val := range data {
-		cols = append(cols, fmt.Sprintf(`"%s"`, strings.ToUpper(col)))
-		placeholders = append(placeholders, fmt.Sprintf(":%d", argNum))
-		argNum++
-		values = append(values, val)
-	}
-
-	query := fmt.Sprintf(`INSERT INTO "%s"."%s" (%s) VALUES (%s)`,
-		strings.ToUpper(database),
-		strings.ToUpper(table),
-		strings.Join(cols, ", "),
-		strings.Join(placeholders, ", "))
-
-	_, err := dbSQL.Exec(query, values...)
+	stmt := oracleEditor.Insert(qualify(quoteDouble, database, table), data)
+	_, err := dbSQL.Exec(stmt.Query, stmt.Args...)
 	return err
 }
 
-// Update 更新数据
-func (a *OracleAdapter) Update(db any, database, table string, data map[string]interface{}, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("更新操作必须指定 WHERE 条件")
-	}
-
-	sets := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-	argNum := 1
-
-	for col, val := range data {
-		sets = append(sets, fmt.Sprintf(`"%s" = :%d`, strings.ToUpper(col), argNum))
-		argNum++
-		values = append(values, val)
-	}
-
-	query := fmt.Sprintf(`UPDATE "%s"."%s" SET %s WHERE %s`,
-		strings.ToUpper(database),
-		strings.ToUpper(table),
-		strings.Join(sets, ", "),
-		where)
-
-	_, err := dbSQL.Exec(query, values...)
-	return err
+// InsertRows 批量写入（预编译语句）
+func (a *OracleAdapter) InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error) {
+	// Oracle 不支持多行 VALUES，在事务中复用预编译语句
+	cols := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		cols[i] = oracleBulkEditor.ident(col)
+		placeholders[i] = fmt.Sprintf(":%d", i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table)),
+		strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+	return insertPrepared(ctx, db, query, rows, false)
 }
 
-// Delete 删除数据
-func (a *OracleAdapter) Delete(db any, database, table, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("删除操作必须指定 WHERE 条件")
-	}
+// CreateTable 按列定义建表
+func (a *OracleAdapter) CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error {
+	return createTable(ctx, db, func(name string) string { return quoteDouble(strings.ToUpper(name)) }, qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table)), columns, a.buildColumnType, "")
+}
 
-	query := fmt.Sprintf(`DELETE FROM "%s"."%s" WHERE %s`,
-		strings.ToUpper(database),
-		strings.ToUpper(table),
-		where)
+// CountRows 统计表的行数
+func (a *OracleAdapter) CountRows(ctx context.Context, db any, database, table string) (int64, error) {
+	return countRows(ctx, db, qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table)))
+}
 
-	_, err := dbSQL.Exec(query)
-	return err
+// Update 按行标识更新数据
+func (a *OracleAdapter) Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
+	return updateRow(db, oracleEditor, qualify(quoteDouble, database, table), data, key)
 }
 
-// ExportToCSV 导出为 CSV
-func (a *OracleAdapter) ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
-	dbSQL := db.(*sql.DB)
-	exporter := export.NewCSVExporter(opts)
+// Delete 按行标识删除数据
+func (a *OracleAdapter) Delete(db any, database, table string, key model.RowKey) (int64, error) {
+	return deleteRow(db, oracleEditor, qualify(quoteDouble, database, table), key)
+}
 
-	query = a.rewriteQuery(query)
-	rows, err := dbSQL.Query(query)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
+// ApplyChanges 在单个事务中应用数据变更集
+func (a *OracleAdapter) ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	return applyChanges(ctx, db, oracleEditor, qualify(quoteDouble, database, table), changes, preview)
+}
 
-	colNames, err := rows.Columns()
-	if err != nil {
-		return err
-	}
+// ReadCell 按行标识读取单元格完整值（BLOB、CLOB）
+func (a *OracleAdapter) ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	return readCell(ctx, db, oracleEditor, qualify(quoteDouble, database, table), column, key, fn)
+}
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(colNames))
-		valuePtrs := make([]interface{}, len(colNames))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// WriteCell 按行标识替换单元格的值
+func (a *OracleAdapter) WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error) {
+	return writeCell(ctx, db, oracleEditor, qualify(quoteDouble, database, table), column, key, data)
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
-		}
+// StreamQuery 执行查询并以行迭代器逐行读取结果
+func (a *OracleAdapter) StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error {
+	return streamQuery(ctx, db, a.rewriteQuery(query), nil, fn)
+}
 
-		row := make(map[string]interface{})
-		for i, colName := range colNames {
-			val := values[i]
-			if val == nil {
-				row[colName] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[colName] = string(b)
-			} else {
-				row[colName] = val
-			}
-		}
-		rowData = append(rowData, row)
+// StreamTable 以行迭代器逐行读取表数据，limit > 0 时只读取前 limit 行
+func (a *OracleAdapter) StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error {
+	query := "SELECT * FROM " + qualify(quoteDouble, strings.ToUpper(database), strings.ToUpper(table))
+	if limit > 0 {
+		query += fmt.Sprintf(" WHERE ROWNUM <= %d", limit)
 	}
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-	return exporter.Export(writer, colNames, rowData)
+// ExportToCSV 导出为 CSV，逐行读取并写出
+func (a *OracleAdapter) ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
+	return a.StreamQuery(ctx, db, database, query, exportCSV(writer, opts))
 }
 
-// ExportToSQL 导出为 SQL
-func (a *OracleAdapter) ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
-	dbSQL := db.(*sql.DB)
+// ExportToSQL 导出为 SQL，表数据逐行读取并写出
+func (a *OracleAdapter) ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
 	exporter := export.NewSQLExporter(opts, model.DatabaseOracle)
 
 	for _, table := range tables {
@@ -491,53 +492,14 @@ func (a *OracleAdapter) ExportToSQL(db any, writer io.Writer, database string, t
 
 		// 导出数据
 		if !opts.StructureOnly {
-			query := fmt.Sprintf(`SELECT * FROM "%s"."%s"`, strings.ToUpper(database), strings.ToUpper(table))
-			rows, err := dbSQL.Query(query)
-			if err != nil {
-				return err
-			}
-
-			colNames, err := rows.Columns()
-			if err != nil {
-				rows.Close()
-				return err
-			}
-
-			var rowData []map[string]interface{}
-			for rows.Next() {
-				values := make([]interface{}, len(colNames))
-				valuePtrs := make([]interface{}, len(colNames))
-				for i := range values {
-					valuePtrs[i] = &values[i]
-				}
-
-				if err := rows.Scan(valuePtrs...); err != nil {
-					rows.Close()
-					return err
-				}
-
-				row := make(map[string]interface{})
-				for i, colName := range colNames {
-					val := values[i]
-					if val == nil {
-						row[colName] = nil
-					} else if b, ok := val.([]byte); ok {
-						row[colName] = string(b)
-					} else {
-						row[colName] = val
-					}
-				}
-				rowData = append(rowData, row)
-			}
-			rows.Close()
-
-			if err := exporter.ExportData(writer, database, table, colNames, rowData); err != nil {
+			if err := a.StreamTable(ctx, db, database, table, opts.MaxRows, exportInserts(writer, exporter, database, table)); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	// 外键引用的表可能排在后面，在所有表之后添加
+	return exporter.ExportForeignKeys(writer)
 }
 
 // GetCreateTableSQL 获取建表语句
@@ -558,6 +520,24 @@ func (a *OracleAdapter) AlterTable(db any, request *model.AlterTableRequest) err
 	return fmt.Errorf("Oracle AlterTable 尚未实现")
 }
 
+// buildColumnType 构建列类型定义
+func (a *OracleAdapter) buildColumnType(col *model.ColumnDef) string {
+	colType := strings.ToUpper(col.Type)
+	if col.Length > 0 {
+		colType = fmt.Sprintf("%s(%d)", colType, col.Length)
+	} else if col.Precision > 0 {
+		if col.Scale > 0 {
+			colType = fmt.Sprintf("%s(%d,%d)", colType, col.Precision, col.Scale)
+		} else {
+			colType = fmt.Sprintf("%s(%d)", colType, col.Precision)
+		}
+	}
+	if !col.Nullable {
+		colType += " NOT NULL"
+	}
+	return colType
+}
+
 // RenameTable 重命名表
 func (a *OracleAdapter) RenameTable(db any, database, oldName, newName string) error {
 	dbSQL := db.(*sql.DB)
@@ -0,0 +1,467 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultMaxQueryRows 单次查询返回的默认最大行数
+	DefaultMaxQueryRows = 10000
+	// DefaultCountLimit 分页统计总数时的默认上限
+	DefaultCountLimit = 100000
+	// DefaultMaxValueSize 查询结果中单元格值的默认最大字节数，超出部分截断为预览
+	DefaultMaxValueSize = 8 * 1024
+)
+
+// pageDialect 分页语法方言
+type pageDialect int
+
+const (
+	// pageLimitOffset LIMIT n OFFSET m（MySQL、PostgreSQL、SQLite、ClickHouse、KingBase）
+	pageLimitOffset pageDialect = iota
+	// pageFetchNext OFFSET m ROWS FETCH NEXT n ROWS ONLY（达梦）
+	pageFetchNext
+	// pageRownum ROWNUM 嵌套（Oracle，兼容 12c 以前版本）
+	pageRownum
+	// pageOffsetFetch ORDER BY ... OFFSET m ROWS FETCH NEXT n ROWS ONLY（SQL Server，必须带 ORDER BY）
+	pageOffsetFetch
+)
+
+// rownumColumn Oracle ROWNUM 分页引入的辅助列名，返回结果时需要剔除
+const rownumColumn = "DBM_RN"
+
+// ErrDuplicateColumns 排序或分页需要把查询包装为子查询，而查询结果中有重名列
+var ErrDuplicateColumns = errors.New("查询结果包含重名列，排序或分页前请为重名列指定不同的别名")
+
+var (
+	pageableQueryRegex = regexp.MustCompile(`(?is)^\s*(SELECT|WITH)\b`)
+	// selectOnlyRegex SQL Server 的 CTE 不能放入子查询，只包装 SELECT
+	selectOnlyRegex = regexp.MustCompile(`(?is)^\s*SELECT\b`)
+	// topOffsetRegex SQL Server 中允许子查询带 ORDER BY 的子句
+	topOffsetRegex = regexp.MustCompile(`(?i)\b(TOP|OFFSET)\b`)
+	// duplicateColumnRegex 子查询中列名重复的错误：MySQL 1060、Oracle ORA-00918、SQL Server 8156、
+	// PostgreSQL 按重名列排序、ClickHouse DUPLICATE_COLUMN
+	duplicateColumnRegex = regexp.MustCompile(`(?i)duplicate column name|ORA-00918|specified multiple times|column reference .* is ambiguous|DUPLICATE_COLUMN`)
+)
+
+// limitKeywords 出现在最外层时，不能再直接追加分页子句的关键字
+var limitKeywords = map[string]bool{"LIMIT": true, "OFFSET": true, "FETCH": true, "TOP": true, "ROWNUM": true}
+
+// pager 分页查询构造器
+type pager struct {
+	dialect pageDialect
+	quote   func(ident string) string
+	lex     splitDialect // 识别引号与注释的词法规则
+}
+
+var (
+	mysqlPager      = &pager{dialect: pageLimitOffset, quote: quoteBacktick, lex: splitDialects[model.DatabaseMySQL]}
+	clickhousePager = &pager{dialect: pageLimitOffset, quote: quoteBacktick, lex: splitDialects[model.DatabaseClickHouse]}
+	pgPager         = &pager{dialect: pageLimitOffset, quote: quoteDouble, lex: splitDialects[model.DatabasePostgreSQL]}
+	sqlitePager     = &pager{dialect: pageLimitOffset, quote: quoteDouble, lex: splitDialects[model.DatabaseSQLite]}
+	dmPager         = &pager{dialect: pageFetchNext, quote: quoteDouble, lex: splitDialects[model.DatabaseDM]}
+	oraclePager     = &pager{dialect: pageRownum, quote: quoteDouble, lex: splitDialects[model.DatabaseOracle]}
+	mssqlPager      = &pager{dialect: pageOffsetFetch, quote: quoteBracket, lex: splitDialects[model.DatabaseMSSQL]}
+)
+
+// quoteBacktick 使用反引号引用标识符
+func quoteBacktick(ident string) string {
+	return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+}
+
+// quoteDouble 使用双引号引用标识符
+func quoteDouble(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+// quoteBracket 使用方括号引用标识符（SQL Server）
+func quoteBracket(ident string) string {
+	return "[" + strings.ReplaceAll(ident, "]", "]]") + "]"
+}
+
+// isPageableQuery 判断查询是否可以被包装分页（仅 SELECT / WITH）
+func isPageableQuery(query string) bool {
+	return pageableQueryRegex.MatchString(query)
+}
+
+// pageable 判断当前方言下查询是否可以被包装分页，query 需已去掉前后注释
+func (p *pager) pageable(query string) bool {
+	if p.dialect == pageOffsetFetch {
+		return selectOnlyRegex.MatchString(query)
+	}
+	return isPageableQuery(query)
+}
+
+// splitOrderBy 拆分查询末尾位于最外层的 ORDER BY 子句
+// 忽略括号、字符串和方括号标识符中的内容，没有 ORDER BY 时 order 为空。
+func splitOrderBy(query string) (body, order string) {
+	depth := 0
+	pos := -1
+	upper := strings.ToUpper(query)
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '\'', '"', '[':
+			closing := query[i]
+			if closing == '[' {
+				closing = ']'
+			}
+			for i++; i < len(query) && query[i] != closing; i++ {
+			}
+		case 'O', 'o':
+			if depth == 0 && strings.HasPrefix(upper[i:], "ORDER") && (i == 0 || !isIdentChar(query[i-1])) {
+				rest := strings.TrimLeft(upper[i+5:], " \t\r\n")
+				if strings.HasPrefix(rest, "BY") {
+					pos = i
+				}
+			}
+		}
+	}
+	if pos < 0 {
+		return query, ""
+	}
+	return strings.TrimSpace(query[:pos]), strings.TrimSpace(query[pos:])
+}
+
+// isIdentChar 判断字符是否可以出现在标识符中
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// maxRows 获取有效的最大行数
+func maxRows(opts *model.QueryOptions) int {
+	if opts == nil || opts.MaxRows <= 0 {
+		return DefaultMaxQueryRows
+	}
+	return opts.MaxRows
+}
+
+// maxValueSize 获取有效的单元格值最大字节数
+func maxValueSize(opts *model.QueryOptions) int {
+	if opts == nil || opts.MaxValueSize <= 0 {
+		return DefaultMaxValueSize
+	}
+	return opts.MaxValueSize
+}
+
+// countLimit 获取有效的 COUNT 统计上限
+func countLimit(opts *model.QueryOptions) int {
+	if opts == nil || opts.CountLimit <= 0 {
+		return DefaultCountLimit
+	}
+	return opts.CountLimit
+}
+
+// isPaged 判断是否请求了分页
+func isPaged(opts *model.QueryOptions) bool {
+	return opts != nil && opts.PageSize > 0
+}
+
+// queryShape 分页需要的查询结构
+type queryShape struct {
+	body    string // 去掉首尾空白、注释与结束符的语句
+	limited bool   // 最外层已有 LIMIT、OFFSET、FETCH、TOP 或 ROWNUM
+	lockAt  int    // 最外层锁定子句（FOR UPDATE、FOR SHARE、LOCK IN SHARE MODE）在 body 中的位置，没有时为 -1
+}
+
+// shape 按方言的引号与注释规则扫描查询
+// 末尾的注释会把追加的分页子句变成注释内容，开头的注释使查询无法识别为 SELECT，因此都需要去掉；
+// MySQL 条件注释（/*! ... */）会被执行，保留在语句中。
+func (p *pager) shape(query string) queryShape {
+	start, end := -1, 0
+	depth := 0
+	lockAt := -1
+	limited := false
+	prevWord, prevPos := "", 0
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		rest := query[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "--") || (p.lex.hashComment && c == '#'):
+			if n := strings.IndexByte(rest, '\n'); n >= 0 {
+				i += n + 1
+			} else {
+				i = len(query)
+			}
+			continue
+		case strings.HasPrefix(rest, "/*") && !strings.HasPrefix(rest, "/*!"):
+			if n := strings.Index(rest[2:], "*/"); n >= 0 {
+				i += n + 4
+			} else {
+				i = len(query)
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';':
+			i++
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+		word := ""
+		switch {
+		case strings.HasPrefix(rest, "/*!"):
+			if n := strings.Index(rest[3:], "*/"); n >= 0 {
+				i += n + 5
+			} else {
+				i = len(query)
+			}
+		case c == '\'':
+			i += quotedLength(rest, c, p.lex.backslashQuoted(query, i))
+		case c == '"' || (c == '`' && p.lex.backtick):
+			i += quotedLength(rest, c, p.lex.backslashEscape && c != '`')
+		case c == '[' && p.lex.bracket:
+			i += quotedLength(rest, ']', false)
+		case c == '$' && p.lex.dollarQuote && dollarTagRegex.MatchString(rest):
+			tag := dollarTagRegex.FindString(rest)
+			if n := strings.Index(rest[len(tag):], tag); n >= 0 {
+				i += len(tag) + n + len(tag)
+			} else {
+				i = len(query)
+			}
+		case isIdentChar(c):
+			j := i + 1
+			for j < len(query) && isIdentChar(query[j]) {
+				j++
+			}
+			word = strings.ToUpper(query[i:j])
+			if depth == 0 {
+				if limitKeywords[word] {
+					limited = true
+				}
+				lock := (prevWord == "FOR" && (word == "UPDATE" || word == "SHARE" || word == "NO" || word == "KEY")) ||
+					(prevWord == "LOCK" && word == "IN")
+				if lock && lockAt < 0 {
+					lockAt = prevPos
+				}
+			}
+			prevPos = i
+			i = j
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		default:
+			i++
+		}
+		if depth == 0 {
+			prevWord = word
+		}
+		end = i
+	}
+
+	if start < 0 {
+		return queryShape{lockAt: -1}
+	}
+	shape := queryShape{body: query[start:end], limited: limited, lockAt: -1}
+	if lockAt >= 0 {
+		shape.lockAt = lockAt - start
+	}
+	return shape
+}
+
+// pageBounds 计算偏移量和页大小，页大小不超过最大行数
+func pageBounds(opts *model.QueryOptions) (offset, limit int) {
+	limit = opts.PageSize
+	if max := maxRows(opts); limit > max {
+		limit = max
+	}
+	page := opts.Page
+	if page < 1 {
+		page = 1
+	}
+	return (page - 1) * limit, limit
+}
+
+// orderBy 生成排序子句
+func (p *pager) orderBy(opts *model.QueryOptions) string {
+	if opts == nil || opts.SortBy == "" {
+		return ""
+	}
+	direction := "ASC"
+	if opts.SortDesc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s", p.quote(opts.SortBy), direction)
+}
+
+// pageQuery 根据分页选项包装用户查询
+func (p *pager) pageQuery(query string, opts *model.QueryOptions) string {
+	stmt, _ := p.build(query, opts)
+	return stmt
+}
+
+// build 生成分页语句，wrapped 表示用户查询被放入了子查询
+// 未请求分页时按最大行数 + 1 截断，用于判断结果是否被截断。
+// 未指定排序时尽量不包装子查询，以保留用户的 ORDER BY 与重名列：没有行数限制时直接追加分页子句
+// （位于最外层的 FOR UPDATE 等锁定子句之前），已有行数限制或无法追加时由扫描阶段按最大行数截断。
+func (p *pager) build(query string, opts *model.QueryOptions) (stmt string, wrapped bool) {
+	shape := p.shape(query)
+	query = shape.body
+	if !p.pageable(query) {
+		return query, false
+	}
+	if p.dialect == pageOffsetFetch {
+		return p.pageOffsetFetch(query, opts)
+	}
+
+	var offset, limit int
+	if isPaged(opts) {
+		offset, limit = pageBounds(opts)
+	} else {
+		limit = maxRows(opts) + 1
+	}
+
+	order := p.orderBy(opts)
+	if order == "" && !isPaged(opts) && (shape.limited || p.dialect == pageRownum) {
+		return query, false
+	}
+
+	switch p.dialect {
+	case pageRownum:
+		inner := query
+		if order != "" {
+			inner = fmt.Sprintf("SELECT * FROM (%s)%s", query, order)
+		}
+		return fmt.Sprintf("SELECT * FROM (SELECT dbm_page.*, ROWNUM %s FROM (%s) dbm_page WHERE ROWNUM <= %d) WHERE %s > %d",
+			rownumColumn, inner, offset+limit, rownumColumn, offset), true
+	case pageFetchNext:
+		clause := fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+		if order != "" || shape.limited {
+			return fmt.Sprintf("SELECT * FROM (%s) dbm_page%s %s", query, order, clause), true
+		}
+		return appendClause(query, clause, shape.lockAt), false
+	default:
+		clause := fmt.Sprintf("LIMIT %d", limit)
+		if offset > 0 {
+			clause = fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+		}
+		if order != "" || shape.limited {
+			return fmt.Sprintf("SELECT * FROM (%s) dbm_page%s %s", query, order, clause), true
+		}
+		return appendClause(query, clause, shape.lockAt), false
+	}
+}
+
+// appendClause 在语句末尾追加分页子句，有锁定子句时插入到锁定子句之前
+func appendClause(query, clause string, lockAt int) string {
+	if lockAt < 0 {
+		return query + " " + clause
+	}
+	return strings.TrimRight(query[:lockAt], " \t\r\n") + " " + clause + " " + query[lockAt:]
+}
+
+// query 执行分页语句；包装为子查询后因列名重复而失败时返回 ErrDuplicateColumns
+func (p *pager) query(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions) (*sql.Rows, error) {
+	stmt, wrapped := p.build(query, opts)
+	rows, err := q.QueryContext(ctx, stmt)
+	if err != nil && wrapped {
+		return nil, duplicateColumnError(err)
+	}
+	return rows, err
+}
+
+// duplicateColumnError 将子查询中列名重复的错误转换为 ErrDuplicateColumns，其他错误原样返回
+func duplicateColumnError(err error) error {
+	if duplicateColumnRegex.MatchString(err.Error()) {
+		return fmt.Errorf("%w (%v)", ErrDuplicateColumns, err)
+	}
+	return err
+}
+
+// pageOffsetFetch 生成 SQL Server 分页语句
+// OFFSET/FETCH 必须跟在 ORDER BY 之后；子查询中不允许单独的 ORDER BY，
+// 因此包装前需要去掉用户查询末尾的 ORDER BY（带 TOP/OFFSET 时除外）。
+func (p *pager) pageOffsetFetch(query string, opts *model.QueryOptions) (string, bool) {
+	body, userOrder := splitOrderBy(query)
+	inner := query
+	if userOrder != "" && !topOffsetRegex.MatchString(query) {
+		inner = body
+	}
+	order := p.orderBy(opts)
+
+	if !isPaged(opts) {
+		// 未指定排序时不包装，由扫描阶段按最大行数截断
+		if order == "" {
+			return query, false
+		}
+		return fmt.Sprintf("SELECT TOP (%d) * FROM (%s) dbm_page%s", maxRows(opts)+1, inner, order), true
+	}
+
+	offset, limit := pageBounds(opts)
+	fetch := fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+	switch {
+	case order != "":
+		return fmt.Sprintf("SELECT * FROM (%s) dbm_page%s %s", inner, order, fetch), true
+	case userOrder != "" && inner == body:
+		// 保留用户的排序，直接追加分页子句
+		return fmt.Sprintf("%s %s", query, fetch), false
+	default:
+		return fmt.Sprintf("SELECT * FROM (%s) dbm_page ORDER BY (SELECT NULL) %s", query, fetch), true
+	}
+}
+
+// countQuery 生成有界 COUNT 语句，最多统计 bound 行以避免全表计数
+func (p *pager) countQuery(query string, bound int) string {
+	query = p.shape(query).body
+	switch p.dialect {
+	case pageOffsetFetch:
+		if body, order := splitOrderBy(query); order != "" && !topOffsetRegex.MatchString(query) {
+			query = body
+		}
+		return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT TOP (%d) 1 AS dbm_one FROM (%s) dbm_q) dbm_count", bound, query)
+	case pageRownum:
+		return fmt.Sprintf("SELECT COUNT(*) FROM (%s) WHERE ROWNUM <= %d", query, bound)
+	case pageFetchNext:
+		return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 AS dbm_one FROM (%s) dbm_q OFFSET 0 ROWS FETCH NEXT %d ROWS ONLY) dbm_count", query, bound)
+	default:
+		return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 AS dbm_one FROM (%s) dbm_q LIMIT %d) dbm_count", query, bound)
+	}
+}
+
+// fillTotal 计算并填充结果总行数
+// 分页查询通过有界 COUNT 计算总数，超过统计上限时标记为 TotalCapped；
+// 非分页查询以实际返回行数为准，超过最大行数的部分被截断。
+func (p *pager) fillTotal(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions, result *model.QueryResult) error {
+	if max := maxRows(opts); len(result.Rows) > max {
+		result.Rows = result.Rows[:max]
+		result.Truncated = true
+	}
+
+	if !isPaged(opts) || !p.pageable(p.shape(query).body) {
+		result.Total = int64(len(result.Rows))
+		return nil
+	}
+
+	bound := countLimit(opts)
+	var total int64
+	if err := q.QueryRowContext(ctx, p.countQuery(query, bound+1)).Scan(&total); err != nil {
+		return duplicateColumnError(err)
+	}
+	if total > int64(bound) {
+		total = int64(bound)
+		result.TotalCapped = true
+	}
+
+	result.Total = total
+	_, result.PageSize = pageBounds(opts)
+	result.Page = opts.Page
+	if result.Page < 1 {
+		result.Page = 1
+	}
+	return nil
+}
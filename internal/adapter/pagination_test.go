@@ -0,0 +1,221 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"errors"
+	"testing"
+)
+
+// TestPageQuery 测试分页语句生成
+func TestPageQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		pager *pager
+		query string
+		opts  *model.QueryOptions
+		want  string
+	}{
+		{
+			name:  "未分页时按最大行数截断",
+			pager: mysqlPager,
+			query: "SELECT * FROM users;",
+			opts:  &model.QueryOptions{MaxRows: 100},
+			want:  "SELECT * FROM users LIMIT 101",
+		},
+		{
+			name:  "MySQL 分页",
+			pager: mysqlPager,
+			query: "SELECT * FROM users",
+			opts:  &model.QueryOptions{Page: 3, PageSize: 20},
+			want:  "SELECT * FROM users LIMIT 20 OFFSET 40",
+		},
+		{
+			name:  "MySQL 分页排序",
+			pager: mysqlPager,
+			query: "SELECT * FROM users",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 20, SortBy: "name", SortDesc: true},
+			want:  "SELECT * FROM (SELECT * FROM users) dbm_page ORDER BY `name` DESC LIMIT 20",
+		},
+		{
+			name:  "已有 LIMIT 时包装子查询",
+			pager: pgPager,
+			query: "SELECT * FROM users LIMIT 5",
+			opts:  &model.QueryOptions{Page: 2, PageSize: 2},
+			want:  "SELECT * FROM (SELECT * FROM users LIMIT 5) dbm_page LIMIT 2 OFFSET 2",
+		},
+		{
+			name:  "页大小不超过最大行数",
+			pager: pgPager,
+			query: "SELECT * FROM users",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 500, MaxRows: 100},
+			want:  "SELECT * FROM users LIMIT 100",
+		},
+		{
+			name:  "达梦 FETCH 分页",
+			pager: dmPager,
+			query: "SELECT * FROM users",
+			opts:  &model.QueryOptions{Page: 2, PageSize: 10, SortBy: "id"},
+			want:  `SELECT * FROM (SELECT * FROM users) dbm_page ORDER BY "id" ASC OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY`,
+		},
+		{
+			name:  "Oracle ROWNUM 分页",
+			pager: oraclePager,
+			query: "SELECT * FROM users",
+			opts:  &model.QueryOptions{Page: 2, PageSize: 10},
+			want:  `SELECT * FROM (SELECT dbm_page.*, ROWNUM DBM_RN FROM (SELECT * FROM users) dbm_page WHERE ROWNUM <= 20) WHERE DBM_RN > 10`,
+		},
+		{
+			name:  "SQL Server 保留用户排序",
+			pager: mssqlPager,
+			query: "SELECT * FROM users ORDER BY name",
+			opts:  &model.QueryOptions{Page: 2, PageSize: 10},
+			want:  "SELECT * FROM users ORDER BY name OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:  "SQL Server 指定排序时去掉子查询中的 ORDER BY",
+			pager: mssqlPager,
+			query: "SELECT * FROM users ORDER BY name",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10, SortBy: "id", SortDesc: true},
+			want:  "SELECT * FROM (SELECT * FROM users) dbm_page ORDER BY [id] DESC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:  "SQL Server 无排序分页",
+			pager: mssqlPager,
+			query: "SELECT * FROM users WHERE name IN (SELECT name FROM t ORDER BY 1 OFFSET 0 ROWS)",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "SELECT * FROM (SELECT * FROM users WHERE name IN (SELECT name FROM t ORDER BY 1 OFFSET 0 ROWS)) dbm_page ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:  "SQL Server 不包装 CTE",
+			pager: mssqlPager,
+			query: "WITH t AS (SELECT 1 AS a) SELECT * FROM t",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "WITH t AS (SELECT 1 AS a) SELECT * FROM t",
+		},
+		{
+			name:  "去掉末尾的行注释",
+			pager: mysqlPager,
+			query: "SELECT * FROM users -- 全部用户\n",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "SELECT * FROM users LIMIT 10",
+		},
+		{
+			name:  "MySQL 井号注释与结束符",
+			pager: mysqlPager,
+			query: "SELECT * FROM users; # 全部用户",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "SELECT * FROM users LIMIT 10",
+		},
+		{
+			name:  "开头的注释不影响识别",
+			pager: pgPager,
+			query: "/* 报表 */\n-- 用户\nSELECT '--' AS a FROM users",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "SELECT '--' AS a FROM users LIMIT 10",
+		},
+		{
+			name:  "分页子句放在 FOR UPDATE 之前",
+			pager: mysqlPager,
+			query: "SELECT * FROM users WHERE id IN (SELECT id FROM t LIMIT 1) FOR UPDATE",
+			opts:  &model.QueryOptions{Page: 2, PageSize: 10},
+			want:  "SELECT * FROM users WHERE id IN (SELECT id FROM t LIMIT 1) LIMIT 10 OFFSET 10 FOR UPDATE",
+		},
+		{
+			name:  "未分页且已有 LIMIT 时不包装",
+			pager: mysqlPager,
+			query: "SELECT a.id, b.id FROM a JOIN b ON a.id = b.id LIMIT 5",
+			opts:  &model.QueryOptions{MaxRows: 100},
+			want:  "SELECT a.id, b.id FROM a JOIN b ON a.id = b.id LIMIT 5",
+		},
+		{
+			name:  "字符串中的关键字不算行数限制",
+			pager: dmPager,
+			query: "SELECT 'LIMIT' AS a FROM dual",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "SELECT 'LIMIT' AS a FROM dual OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:  "Oracle 未分页且未排序时不包装",
+			pager: oraclePager,
+			query: "SELECT a.id, b.id FROM a, b;",
+			opts:  &model.QueryOptions{MaxRows: 100},
+			want:  "SELECT a.id, b.id FROM a, b",
+		},
+		{
+			name:  "非查询语句不包装",
+			pager: mysqlPager,
+			query: "SHOW TABLES",
+			opts:  &model.QueryOptions{Page: 1, PageSize: 10},
+			want:  "SHOW TABLES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.pager.pageQuery(tt.query, tt.opts)
+			if got != tt.want {
+				t.Errorf("pageQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCountQuery 测试有界 COUNT 语句生成
+func TestCountQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		pager *pager
+		want  string
+	}{
+		{
+			name:  "LIMIT 方言",
+			pager: pgPager,
+			want:  "SELECT COUNT(*) FROM (SELECT 1 AS dbm_one FROM (SELECT * FROM users) dbm_q LIMIT 1001) dbm_count",
+		},
+		{
+			name:  "达梦",
+			pager: dmPager,
+			want:  "SELECT COUNT(*) FROM (SELECT 1 AS dbm_one FROM (SELECT * FROM users) dbm_q OFFSET 0 ROWS FETCH NEXT 1001 ROWS ONLY) dbm_count",
+		},
+		{
+			name:  "SQL Server",
+			pager: mssqlPager,
+			want:  "SELECT COUNT(*) FROM (SELECT TOP (1001) 1 AS dbm_one FROM (SELECT * FROM users) dbm_q) dbm_count",
+		},
+		{
+			name:  "Oracle",
+			pager: oraclePager,
+			want:  "SELECT COUNT(*) FROM (SELECT * FROM users) WHERE ROWNUM <= 1001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.pager.countQuery("SELECT * FROM users;", 1001)
+			if got != tt.want {
+				t.Errorf("countQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDuplicateColumnError 测试子查询列名重复错误的识别
+func TestDuplicateColumnError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("Error 1060 (42S21): Duplicate column name 'id'"), true},
+		{errors.New("ORA-00918: column ambiguously defined"), true},
+		{errors.New("mssql: The column 'id' was specified multiple times for 'dbm_page'."), true},
+		{errors.New(`pq: column reference "id" is ambiguous`), true},
+		{errors.New("table users doesn't exist"), false},
+	}
+
+	for _, tt := range tests {
+		got := errors.Is(duplicateColumnError(tt.err), ErrDuplicateColumns)
+		if got != tt.want {
+			t.Errorf("duplicateColumnError(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
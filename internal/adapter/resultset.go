@@ -0,0 +1,151 @@
+package adapter
+
+import (
+	"database/sql"
+	"dbm/internal/model"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+)
+
+// binaryTypeRegex 二进制列类型（BLOB、BINARY、VARBINARY、BYTEA、RAW、IMAGE 等）
+var binaryTypeRegex = regexp.MustCompile(`(?i)^(TINY|MEDIUM|LONG)?BLOB$|^(VAR)?BINARY$|^BYTEA$|^(LONG )?RAW$|^IMAGE$|^LONGVARBINARY$|^BFILE$`)
+
+// valueConverter 转换驱动返回的单个值，[]byte 在转换之后统一处理
+type valueConverter func(ct *sql.ColumnType, val any) any
+
+// scanResult 读取查询结果集，最多读取最大行数 +1 行用于判断是否截断
+// skip 中的列（如分页引入的辅助列）不出现在结果中。
+// 二进制列的值保留为 []byte，JSON 序列化时为 base64，并在列信息中标记 Binary；
+// 其他列的 []byte 转换为字符串。超过 MaxValueSize 的值截断为预览并记录在 TruncatedCells 中。
+func scanResult(rows *sql.Rows, opts *model.QueryOptions, convert valueConverter, skip ...string) (*model.QueryResult, error) {
+	limit := maxRows(opts)
+	maxSize := maxValueSize(opts)
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 需要保留的列下标
+	var keep []int
+	for i, ct := range columnTypes {
+		if !slices.Contains(skip, ct.Name()) {
+			keep = append(keep, i)
+		}
+	}
+
+	result := &model.QueryResult{
+		Columns:     make([]string, len(keep)),
+		ColumnTypes: make([]model.ColumnMeta, len(keep)),
+		Rows:        make([][]interface{}, 0),
+	}
+	for j, i := range keep {
+		result.Columns[j] = columnTypes[i].Name()
+		result.ColumnTypes[j] = columnMeta(columnTypes[i])
+	}
+
+	values := make([]interface{}, len(columnTypes))
+	valuePtrs := make([]interface{}, len(columnTypes))
+	for rows.Next() {
+		// 多读取一行用于判断是否截断
+		if len(result.Rows) > limit {
+			break
+		}
+
+		for i := range values {
+			values[i] = nil
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+
+		row := make([]interface{}, len(keep))
+		for j, i := range keep {
+			val := values[i]
+			if convert != nil && val != nil {
+				val = convert(columnTypes[i], val)
+			}
+			// 驱动可能复用缓冲区，复制一份
+			if b, ok := val.([]byte); ok {
+				if !result.ColumnTypes[j].Binary && !utf8.Valid(b) {
+					result.ColumnTypes[j].Binary = true
+				}
+				val = append([]byte(nil), b...)
+			}
+			if preview, size, ok := truncateValue(val, maxSize); ok {
+				val = preview
+				// 多读取的一行不返回，无需记录
+				if len(result.Rows) < limit {
+					result.TruncatedCells = append(result.TruncatedCells, model.TruncatedCell{Row: len(result.Rows), Column: j, Size: size})
+				}
+			}
+			row[j] = val
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 非二进制列的 []byte 转为字符串
+	for _, row := range result.Rows {
+		for j, val := range row {
+			if b, ok := val.([]byte); ok && !result.ColumnTypes[j].Binary {
+				row[j] = string(b)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// columnMeta 从驱动的列类型提取列信息，驱动未提供的属性留空
+func columnMeta(ct *sql.ColumnType) model.ColumnMeta {
+	meta := model.ColumnMeta{
+		Name:   ct.Name(),
+		DBType: ct.DatabaseTypeName(),
+	}
+	if t := ct.ScanType(); t != nil {
+		meta.ScanType = t.String()
+	}
+	if nullable, ok := ct.Nullable(); ok {
+		meta.Nullable = &nullable
+	}
+	if length, ok := ct.Length(); ok {
+		meta.Length = &length
+	}
+	if precision, scale, ok := ct.DecimalSize(); ok {
+		meta.Precision = &precision
+		meta.Scale = &scale
+	}
+	meta.Binary = binaryTypeRegex.MatchString(meta.DBType)
+	return meta
+}
+
+// truncateValue 将超过 maxSize 字节的字符串或二进制值截断为预览，返回预览、完整字节数与是否截断
+// 字符串与 UTF-8 文本按字符边界截断。
+func truncateValue(val any, maxSize int) (any, int, bool) {
+	switch v := val.(type) {
+	case []byte:
+		if len(v) > maxSize {
+			end := maxSize
+			if utf8.Valid(v) {
+				for end > 0 && !utf8.RuneStart(v[end]) {
+					end--
+				}
+			}
+			return v[:end], len(v), true
+		}
+	case string:
+		if len(v) > maxSize {
+			end := maxSize
+			for end > 0 && !utf8.RuneStart(v[end]) {
+				end--
+			}
+			return v[:end], len(v), true
+		}
+	}
+	return val, 0, false
+}
@@ -0,0 +1,63 @@
+package adapter
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestSQLite_QueryResultColumns 测试列信息、重名列与二进制值
+func TestSQLite_QueryResultColumns(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "result.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := NewSQLiteAdapter()
+	for _, stmt := range []string{
+		"CREATE TABLE a (id INTEGER, price DECIMAL(10,2), data BLOB)",
+		"CREATE TABLE b (id INTEGER, note TEXT)",
+		"INSERT INTO a VALUES (1, 9.5, X'00FF10')",
+		"INSERT INTO b VALUES (2, 'x')",
+	} {
+		if _, err := a.Execute(db, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := a.Query(db, "SELECT a.id, b.id, a.price, a.data, b.note FROM a, b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(result.Columns, ","); got != "id,id,price,data,note" {
+		t.Errorf("Columns = %s", got)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != int64(1) || result.Rows[0][1] != int64(2) {
+		t.Fatalf("duplicate columns lost: %v", result.Rows)
+	}
+
+	if len(result.ColumnTypes) != 5 {
+		t.Fatalf("ColumnTypes = %d, want 5", len(result.ColumnTypes))
+	}
+	if got := result.ColumnTypes[2].DBType; got != "DECIMAL(10,2)" {
+		t.Errorf("price DBType = %q, want DECIMAL(10,2)", got)
+	}
+	if !result.ColumnTypes[3].Binary || result.ColumnTypes[4].Binary {
+		t.Errorf("Binary flags = %v, %v, want true, false", result.ColumnTypes[3].Binary, result.ColumnTypes[4].Binary)
+	}
+
+	// 二进制值序列化为 base64，文本值保持字符串
+	data, err := json.Marshal(result.Rows[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[1,2,9.5,"AP8Q","x"]`; string(data) != want {
+		t.Errorf("row JSON = %s, want %s", data, want)
+	}
+}
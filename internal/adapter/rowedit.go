@@ -0,0 +1,295 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrRowKeyRequired 未指定行标识
+	ErrRowKeyRequired = errors.New("必须指定行标识（主键或唯一键列）")
+	// ErrRowKeyNotUnique 行标识匹配多行
+	ErrRowKeyNotUnique = errors.New("行标识匹配多行数据，已拒绝执行")
+)
+
+// placeholderStyle 绑定参数占位符风格
+type placeholderStyle int
+
+const (
+	// placeholderQuestion ?（MySQL、SQLite、ClickHouse、达梦）
+	placeholderQuestion placeholderStyle = iota
+	// placeholderDollar $n（PostgreSQL、KingBase）
+	placeholderDollar
+	// placeholderColon :n（Oracle）
+	placeholderColon
+	// placeholderAtP @pn（SQL Server）
+	placeholderAtP
+)
+
+// rowStatement 参数化语句
+type rowStatement struct {
+	Query string
+	Args  []any
+}
+
+// rowEditor 按行标识编辑数据的语句构造器
+type rowEditor struct {
+	quote       func(ident string) string
+	placeholder placeholderStyle
+	// upperIdent 列名转为大写，与 CreateTable 建表时的列名一致（Oracle、达梦的批量写入）
+	upperIdent bool
+	// pseudoKeys 可作为行标识的伪列（小写名）→ 比较表达式，%s 为占位符
+	pseudoKeys map[string]string
+	// mutation 使用 ALTER TABLE ... UPDATE / DELETE 修改数据（ClickHouse）
+	mutation bool
+	// inline 参数以字面量内联到语句中，仅用于预览展示
+	inline bool
+}
+
+var (
+	backtickEditor = &rowEditor{quote: quoteBacktick, placeholder: placeholderQuestion}
+	sqliteEditor   = &rowEditor{quote: quoteBacktick, placeholder: placeholderQuestion,
+		pseudoKeys: map[string]string{"rowid": "rowid = %s"}}
+	pgEditor = &rowEditor{quote: quoteDouble, placeholder: placeholderDollar,
+		pseudoKeys: map[string]string{"ctid": "ctid = CAST(%s AS tid)"}}
+	// Oracle、达梦按行编辑时表名与列名按目录中的名称原样引用，不转换大小写
+	oracleEditor = &rowEditor{quote: quoteDouble, placeholder: placeholderColon,
+		pseudoKeys: map[string]string{"rowid": "ROWID = CHARTOROWID(%s)"}}
+	dmEditor = &rowEditor{quote: quoteDouble, placeholder: placeholderQuestion,
+		pseudoKeys: map[string]string{"rowid": "ROWID = %s"}}
+	oracleBulkEditor = &rowEditor{quote: quoteDouble, placeholder: placeholderColon, upperIdent: true}
+	dmBulkEditor     = &rowEditor{quote: quoteDouble, placeholder: placeholderQuestion, upperIdent: true}
+	mssqlEditor      = &rowEditor{quote: quoteBracket, placeholder: placeholderAtP}
+	clickhouseEditor = &rowEditor{quote: quoteBacktick, placeholder: placeholderQuestion, mutation: true}
+)
+
+// ident 引用列名
+func (e *rowEditor) ident(name string) string {
+	if e.upperIdent {
+		name = strings.ToUpper(name)
+	}
+	return e.quote(name)
+}
+
+// bind 追加参数并返回对应占位符
+func (e *rowEditor) bind(stmt *rowStatement, value any) string {
+	stmt.Args = append(stmt.Args, value)
+	if e.inline {
+		return sqlLiteral(value)
+	}
+	switch e.placeholder {
+	case placeholderDollar:
+		return fmt.Sprintf("$%d", len(stmt.Args))
+	case placeholderColon:
+		return fmt.Sprintf(":%d", len(stmt.Args))
+	case placeholderAtP:
+		return fmt.Sprintf("@p%d", len(stmt.Args))
+	}
+	return "?"
+}
+
+// where 生成行标识条件，列按名称排序保证语句稳定
+func (e *rowEditor) where(stmt *rowStatement, key model.RowKey) (string, error) {
+	if len(key) == 0 {
+		return "", ErrRowKeyRequired
+	}
+
+	conds := make([]string, 0, len(key))
+	for _, col := range sortedKeys(key) {
+		value := key[col]
+		if expr, ok := e.pseudoKeys[strings.ToLower(col)]; ok {
+			conds = append(conds, fmt.Sprintf(expr, e.bind(stmt, value)))
+			continue
+		}
+		if value == nil {
+			conds = append(conds, e.ident(col)+" IS NULL")
+			continue
+		}
+		conds = append(conds, e.ident(col)+" = "+e.bind(stmt, value))
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+// Insert 生成 INSERT 语句
+func (e *rowEditor) Insert(table string, data map[string]interface{}) rowStatement {
+	var stmt rowStatement
+	cols := make([]string, 0, len(data))
+	placeholders := make([]string, 0, len(data))
+	for _, col := range sortedKeys(data) {
+		cols = append(cols, e.ident(col))
+		placeholders = append(placeholders, e.bind(&stmt, data[col]))
+	}
+
+	stmt.Query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+	return stmt
+}
+
+// Update 生成按行标识更新的语句，伪列不会出现在 SET 中
+func (e *rowEditor) Update(table string, data map[string]interface{}, key model.RowKey) (rowStatement, error) {
+	var stmt rowStatement
+	sets := make([]string, 0, len(data))
+	for _, col := range sortedKeys(data) {
+		if _, ok := e.pseudoKeys[strings.ToLower(col)]; ok {
+			continue
+		}
+		sets = append(sets, e.ident(col)+" = "+e.bind(&stmt, data[col]))
+	}
+	if len(sets) == 0 {
+		return stmt, fmt.Errorf("没有需要更新的列")
+	}
+
+	where, err := e.where(&stmt, key)
+	if err != nil {
+		return stmt, err
+	}
+
+	if e.mutation {
+		stmt.Query = fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", table, strings.Join(sets, ", "), where)
+	} else {
+		stmt.Query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), where)
+	}
+	return stmt, nil
+}
+
+// Delete 生成按行标识删除的语句
+func (e *rowEditor) Delete(table string, key model.RowKey) (rowStatement, error) {
+	var stmt rowStatement
+	where, err := e.where(&stmt, key)
+	if err != nil {
+		return stmt, err
+	}
+
+	if e.mutation {
+		stmt.Query = fmt.Sprintf("ALTER TABLE %s DELETE WHERE %s", table, where)
+	} else {
+		stmt.Query = fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+	}
+	return stmt, nil
+}
+
+// Count 生成统计行标识匹配行数的语句
+func (e *rowEditor) Count(table string, key model.RowKey) (rowStatement, error) {
+	var stmt rowStatement
+	where, err := e.where(&stmt, key)
+	if err != nil {
+		return stmt, err
+	}
+
+	stmt.Query = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, where)
+	return stmt, nil
+}
+
+// checkUnique 确认行标识最多匹配一行，返回匹配行数
+func checkUnique(ctx context.Context, q sqlQueryer, count rowStatement) (int64, error) {
+	var n int64
+	if err := q.QueryRowContext(ctx, count.Query, count.Args...).Scan(&n); err != nil {
+		return 0, err
+	}
+	if n > 1 {
+		return n, fmt.Errorf("%w（匹配 %d 行）", ErrRowKeyNotUnique, n)
+	}
+	return n, nil
+}
+
+// execKeyed 在 q 上执行按行标识定位的语句，匹配多行时拒绝执行，返回影响行数
+func execKeyed(ctx context.Context, q sqlQueryer, count, stmt rowStatement) (int64, error) {
+	if _, err := checkUnique(ctx, q, count); err != nil {
+		return 0, err
+	}
+
+	result, err := q.ExecContext(ctx, stmt.Query, stmt.Args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected > 1 {
+		return 0, fmt.Errorf("%w（影响 %d 行）", ErrRowKeyNotUnique, affected)
+	}
+	return affected, nil
+}
+
+// execKeyedTx 在事务中执行按行标识定位的语句
+// 统计与修改在同一事务中进行，影响多行时回滚。
+func execKeyedTx(ctx context.Context, db any, count, stmt rowStatement) (int64, error) {
+	dbSQL := db.(*sql.DB)
+
+	tx, err := dbSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := execKeyed(ctx, tx, count, stmt)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// sortedKeys 返回按名称排序的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// qualify 引用表名，指定数据库时加上数据库前缀
+func qualify(quote func(string) string, database, table string) string {
+	if database == "" {
+		return quote(table)
+	}
+	return quote(database) + "." + quote(table)
+}
+
+// splitPGTable 拆分 PostgreSQL、人大金仓的表名：schema.table 形式时返回 schema 与表名，否则 schema 为空
+// 表名中的第一个点号视为 schema 分隔符。
+func splitPGTable(table string) (schema, name string) {
+	if schema, name, ok := strings.Cut(table, "."); ok && schema != "" && name != "" {
+		return schema, name
+	}
+	return "", table
+}
+
+// pgTable 引用 PostgreSQL、人大金仓的表名，schema 与表名分别引用
+func pgTable(table string) string {
+	schema, name := splitPGTable(table)
+	return qualify(quoteDouble, schema, name)
+}
+
+// updateRow 按行标识更新一行数据
+func updateRow(db any, e *rowEditor, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
+	stmt, err := e.Update(table, data, key)
+	if err != nil {
+		return 0, err
+	}
+	count, err := e.Count(table, key)
+	if err != nil {
+		return 0, err
+	}
+	return execKeyedTx(context.Background(), db, count, stmt)
+}
+
+// deleteRow 按行标识删除一行数据
+func deleteRow(db any, e *rowEditor, table string, key model.RowKey) (int64, error) {
+	stmt, err := e.Delete(table, key)
+	if err != nil {
+		return 0, err
+	}
+	count, err := e.Count(table, key)
+	if err != nil {
+		return 0, err
+	}
+	return execKeyedTx(context.Background(), db, count, stmt)
+}
@@ -0,0 +1,174 @@
+package adapter
+
+import (
+	"database/sql"
+	"dbm/internal/model"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestRowEditor_Statements 测试各方言的参数化语句生成
+func TestRowEditor_Statements(t *testing.T) {
+	data := map[string]interface{}{"name": "O'Brien", "age": 30}
+	key := model.RowKey{"id": 7, "tenant": nil}
+
+	tests := []struct {
+		name       string
+		editor     *rowEditor
+		table      string
+		wantUpdate string
+		wantDelete string
+	}{
+		{
+			name:       "mysql",
+			editor:     backtickEditor,
+			table:      qualify(quoteBacktick, "app", "users"),
+			wantUpdate: "UPDATE `app`.`users` SET `age` = ?, `name` = ? WHERE `id` = ? AND `tenant` IS NULL",
+			wantDelete: "DELETE FROM `app`.`users` WHERE `id` = ? AND `tenant` IS NULL",
+		},
+		{
+			name:       "postgresql",
+			editor:     pgEditor,
+			table:      quoteDouble("users"),
+			wantUpdate: `UPDATE "users" SET "age" = $1, "name" = $2 WHERE "id" = $3 AND "tenant" IS NULL`,
+			wantDelete: `DELETE FROM "users" WHERE "id" = $1 AND "tenant" IS NULL`,
+		},
+		{
+			name:       "postgresql schema",
+			editor:     pgEditor,
+			table:      pgTable("app.users"),
+			wantUpdate: `UPDATE "app"."users" SET "age" = $1, "name" = $2 WHERE "id" = $3 AND "tenant" IS NULL`,
+			wantDelete: `DELETE FROM "app"."users" WHERE "id" = $1 AND "tenant" IS NULL`,
+		},
+		{
+			name:       "oracle",
+			editor:     oracleEditor,
+			table:      qualify(quoteDouble, "APP", "USERS"),
+			wantUpdate: `UPDATE "APP"."USERS" SET "age" = :1, "name" = :2 WHERE "id" = :3 AND "tenant" IS NULL`,
+			wantDelete: `DELETE FROM "APP"."USERS" WHERE "id" = :1 AND "tenant" IS NULL`,
+		},
+		{
+			name:       "mssql",
+			editor:     mssqlEditor,
+			table:      "[app].[dbo].[users]",
+			wantUpdate: "UPDATE [app].[dbo].[users] SET [age] = @p1, [name] = @p2 WHERE [id] = @p3 AND [tenant] IS NULL",
+			wantDelete: "DELETE FROM [app].[dbo].[users] WHERE [id] = @p1 AND [tenant] IS NULL",
+		},
+		{
+			name:       "clickhouse",
+			editor:     clickhouseEditor,
+			table:      qualify(quoteBacktick, "app", "users"),
+			wantUpdate: "ALTER TABLE `app`.`users` UPDATE `age` = ?, `name` = ? WHERE `id` = ? AND `tenant` IS NULL",
+			wantDelete: "ALTER TABLE `app`.`users` DELETE WHERE `id` = ? AND `tenant` IS NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := tt.editor.Update(tt.table, data, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if update.Query != tt.wantUpdate {
+				t.Errorf("Update() = %q, want %q", update.Query, tt.wantUpdate)
+			}
+			if want := []any{30, "O'Brien", 7}; !reflect.DeepEqual(update.Args, want) {
+				t.Errorf("Update() args = %v, want %v", update.Args, want)
+			}
+
+			del, err := tt.editor.Delete(tt.table, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if del.Query != tt.wantDelete {
+				t.Errorf("Delete() = %q, want %q", del.Query, tt.wantDelete)
+			}
+		})
+	}
+}
+
+// TestRowEditor_PseudoKey 测试伪列行标识
+func TestRowEditor_PseudoKey(t *testing.T) {
+	stmt, err := pgEditor.Update(`"t"`, map[string]interface{}{"ctid": "(0,1)", "v": 1}, model.RowKey{"ctid": "(0,1)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `UPDATE "t" SET "v" = $1 WHERE ctid = CAST($2 AS tid)`; stmt.Query != want {
+		t.Errorf("Update() = %q, want %q", stmt.Query, want)
+	}
+
+	stmt, err = oracleEditor.Delete(`"T"`, model.RowKey{"ROWID": "AAAR3sAAEAAAACXAAA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `DELETE FROM "T" WHERE ROWID = CHARTOROWID(:1)`; stmt.Query != want {
+		t.Errorf("Delete() = %q, want %q", stmt.Query, want)
+	}
+
+	if _, err := backtickEditor.Delete("`t`", nil); err != ErrRowKeyRequired {
+		t.Errorf("Delete() without key = %v, want ErrRowKeyRequired", err)
+	}
+}
+
+// TestRowEditor_Ident 测试按行编辑原样引用目录中的名称，批量写入与建表一致转为大写
+func TestRowEditor_Ident(t *testing.T) {
+	if got := oracleEditor.ident("MixedCase"); got != `"MixedCase"` {
+		t.Errorf("oracleEditor.ident() = %s, want \"MixedCase\"", got)
+	}
+	if got := oracleBulkEditor.ident("name"); got != `"NAME"` {
+		t.Errorf("oracleBulkEditor.ident() = %s, want \"NAME\"", got)
+	}
+	if got := pgTable("users"); got != `"users"` {
+		t.Errorf("pgTable() = %s, want \"users\"", got)
+	}
+}
+
+// TestSQLite_UpdateDeleteByKey 测试按行标识编辑与多行匹配拒绝
+func TestSQLite_UpdateDeleteByKey(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "edit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := NewSQLiteAdapter()
+	if _, err := a.Execute(db, "CREATE TABLE items (name TEXT, qty INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Execute(db, "INSERT INTO items VALUES ('a''b', 1), ('dup', 1), ('dup', 1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	affected, err := a.Update(db, "", "items", map[string]interface{}{"qty": 5}, model.RowKey{"name": "a'b"})
+	if err != nil || affected != 1 {
+		t.Fatalf("Update() = %d, %v, want 1, nil", affected, err)
+	}
+
+	// 重复行匹配多行，必须拒绝且不修改数据
+	if _, err := a.Update(db, "", "items", map[string]interface{}{"qty": 9}, model.RowKey{"name": "dup"}); !errors.Is(err, ErrRowKeyNotUnique) {
+		t.Errorf("Update() on duplicates = %v, want ErrRowKeyNotUnique", err)
+	}
+	var qty int
+	if err := db.QueryRow("SELECT MAX(qty) FROM items WHERE name = 'dup'").Scan(&qty); err != nil || qty != 1 {
+		t.Errorf("duplicates modified: qty = %d, %v", qty, err)
+	}
+
+	// 无主键时使用 rowid 定位
+	var rowid int64
+	if err := db.QueryRow("SELECT rowid FROM items WHERE name = 'dup' LIMIT 1").Scan(&rowid); err != nil {
+		t.Fatal(err)
+	}
+	affected, err = a.Delete(db, "", "items", model.RowKey{"rowid": rowid})
+	if err != nil || affected != 1 {
+		t.Fatalf("Delete() by rowid = %d, %v, want 1, nil", affected, err)
+	}
+
+	affected, err = a.Delete(db, "", "items", model.RowKey{"name": "missing"})
+	if err != nil || affected != 0 {
+		t.Errorf("Delete() missing row = %d, %v, want 0, nil", affected, err)
+	}
+}
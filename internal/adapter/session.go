@@ -0,0 +1,182 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrSessionUnsupported 数据库类型不支持会话
+	ErrSessionUnsupported = errors.New("该数据库类型不支持会话")
+	// ErrNoTransaction 会话中没有进行中的事务
+	ErrNoTransaction = errors.New("当前会话没有进行中的事务")
+	// ErrTransactionActive 会话中已有进行中的事务
+	ErrTransactionActive = errors.New("当前会话已有进行中的事务")
+)
+
+// Session 固定在单条数据库连接上的 SQL 会话
+// 会话内的语句都在同一连接上执行，事务与会话变量（SET、USE 等）在会话生命周期内保持。
+// 可作为 db 参数传给适配器的 QueryContext / ExecuteContext。
+type Session struct {
+	mu   sync.Mutex
+	db   *sql.DB
+	conn *sql.Conn
+	tx   *sql.Tx
+
+	// killID 服务端会话 ID，首次需要终止语句时查询并缓存
+	killID string
+}
+
+// NewSession 从连接池中取出一条连接创建会话
+func NewSession(ctx context.Context, db any) (*Session, error) {
+	dbSQL, ok := db.(*sql.DB)
+	if !ok {
+		return nil, ErrSessionUnsupported
+	}
+
+	conn, err := dbSQL.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{db: dbSQL, conn: conn}, nil
+}
+
+// queryer 返回当前执行语句的句柄：有事务时使用事务，否则使用连接
+func (s *Session) queryer() sqlQueryer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current()
+}
+
+// current 同 queryer，调用方需持有 s.mu
+func (s *Session) current() sqlQueryer {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.conn
+}
+
+// InTransaction 判断会话是否有进行中的事务
+func (s *Session) InTransaction() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tx != nil
+}
+
+// Begin 开启事务
+func (s *Session) Begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx != nil {
+		return ErrTransactionActive
+	}
+
+	// 事务生命周期与会话一致，不能绑定到单次请求的 ctx 上
+	tx, err := s.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	s.tx = tx
+	return nil
+}
+
+// Commit 提交事务
+func (s *Session) Commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		return ErrNoTransaction
+	}
+	err := s.tx.Commit()
+	s.tx = nil
+	return err
+}
+
+// Rollback 回滚事务
+func (s *Session) Rollback() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		return ErrNoTransaction
+	}
+	err := s.tx.Rollback()
+	s.tx = nil
+	return err
+}
+
+// Close 回滚未提交的事务并丢弃连接
+// 会话可能修改过连接状态（USE、SET 等），因此不归还连接池而是直接关闭。
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	if s.tx != nil {
+		if err := s.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			firstErr = err
+		}
+		s.tx = nil
+	}
+
+	_ = s.conn.Raw(func(any) error { return driver.ErrBadConn })
+	if err := s.conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// run 在会话上执行 fn，ctx 取消时通过连接池中的另一条连接终止语句
+func (s *Session) run(ctx context.Context, killer *sessionKiller, fn func(q sqlQueryer) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := s.current()
+	if killer == nil || ctx.Done() == nil {
+		return fn(q)
+	}
+
+	if s.killID == "" {
+		if err := q.QueryRowContext(ctx, killer.sessionIDQuery).Scan(&s.killID); err != nil {
+			return err
+		}
+	}
+
+	stop := watchKill(ctx, s.db, killer, s.killID)
+	defer stop()
+
+	return fn(q)
+}
+
+// sqlHandles 返回执行语句的句柄与用于终止语句的连接池
+// db 为会话时在会话连接上执行，否则直接使用连接池。
+func sqlHandles(db any) (sqlQueryer, *sql.DB) {
+	if s, ok := db.(*Session); ok {
+		return s.queryer(), s.db
+	}
+	dbSQL := db.(*sql.DB)
+	return dbSQL, dbSQL
+}
+
+// TransactionControl 识别事务控制语句，返回 BEGIN、COMMIT、ROLLBACK 之一，其他语句返回空字符串
+// 会话中的事务控制语句统一通过 database/sql 的事务接口执行，以兼容自动提交的驱动。
+func TransactionControl(query string) string {
+	stmt := strings.ToUpper(strings.Join(strings.Fields(strings.TrimRight(strings.TrimSpace(query), ";")), " "))
+
+	switch stmt {
+	case "BEGIN", "BEGIN WORK", "BEGIN TRAN", "BEGIN TRANSACTION", "START TRANSACTION":
+		return "BEGIN"
+	case "COMMIT", "COMMIT WORK", "COMMIT TRAN", "COMMIT TRANSACTION", "END", "END TRANSACTION":
+		return "COMMIT"
+	case "ROLLBACK", "ROLLBACK WORK", "ROLLBACK TRAN", "ROLLBACK TRANSACTION", "ABORT":
+		return "ROLLBACK"
+	}
+	return ""
+}
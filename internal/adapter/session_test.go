@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestTransactionControl 测试事务控制语句识别
+func TestTransactionControl(t *testing.T) {
+	tests := map[string]string{
+		"BEGIN":                     "BEGIN",
+		"begin transaction;":        "BEGIN",
+		"  START   TRANSACTION ; ":  "BEGIN",
+		"COMMIT":                    "COMMIT",
+		"commit work;":              "COMMIT",
+		"END":                       "COMMIT",
+		"ROLLBACK TRAN":             "ROLLBACK",
+		"ROLLBACK TO SAVEPOINT sp1": "",
+		"BEGIN NULL; END;":          "",
+		"SELECT 1":                  "",
+	}
+
+	for query, want := range tests {
+		if got := TransactionControl(query); got != want {
+			t.Errorf("TransactionControl(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+// TestSession_PinnedConnection 测试会话固定连接与事务回滚
+func TestSession_PinnedConnection(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "session.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	a := NewSQLiteAdapter()
+	if _, err := a.Execute(db, "CREATE TABLE users (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSession(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// 临时表仅对创建它的连接可见
+	if _, err := a.ExecuteContext(ctx, s, "CREATE TEMP TABLE scratch (v INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.QueryContext(ctx, s, "SELECT * FROM scratch", nil); err != nil {
+		t.Errorf("temp table should be visible in session: %v", err)
+	}
+	if _, err := a.QueryContext(ctx, db, "SELECT * FROM scratch", nil); err == nil {
+		t.Error("temp table should not be visible outside session")
+	}
+
+	if err := s.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Begin(); err != ErrTransactionActive {
+		t.Errorf("Begin() twice = %v, want ErrTransactionActive", err)
+	}
+	if _, err := a.ExecuteContext(ctx, s, "INSERT INTO users (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Commit(); err != ErrNoTransaction {
+		t.Errorf("Commit() without transaction = %v, want ErrNoTransaction", err)
+	}
+
+	result, err := a.QueryContext(ctx, s, "SELECT COUNT(*) AS n FROM users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := result.Rows[0][0]; n != int64(0) {
+		t.Errorf("rows after rollback = %v, want 0", n)
+	}
+}
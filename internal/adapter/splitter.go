@@ -0,0 +1,376 @@
+package adapter
+
+import (
+	"bufio"
+	"dbm/internal/model"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Statement 脚本中的单条语句
+type Statement struct {
+	SQL  string `json:"sql"`  // 语句文本（不含结束符与前导注释）
+	Line int    `json:"line"` // 语句起始行号（从 1 开始）
+}
+
+// splitDialect 语句拆分的方言差异
+type splitDialect struct {
+	backslashEscape  bool // 字符串中反斜杠转义（MySQL、ClickHouse）
+	escapeString     bool // E'...' 字符串中反斜杠转义（PostgreSQL、KingBase）
+	hashComment      bool // # 单行注释（MySQL）
+	backtick         bool // 反引号标识符
+	bracket          bool // [方括号] 标识符（SQL Server、SQLite）
+	dollarQuote      bool // $tag$ 字符串（PostgreSQL、KingBase）
+	delimiterCommand bool // DELIMITER 命令修改结束符（MySQL）
+	slashTerminator  bool // 单独一行的 / 结束 PL/SQL 块（Oracle、达梦）
+	batchSeparator   bool // 单独一行的 GO 分隔批次，分号不拆分（SQL Server）
+	triggerBlocks    bool // CREATE TRIGGER ... BEGIN ... END; 作为整体（SQLite）
+}
+
+// splitDialects 各数据库的拆分规则，未列出的类型只按引号、注释和分号拆分
+var splitDialects = map[model.DatabaseType]splitDialect{
+	model.DatabaseMySQL:      {backslashEscape: true, hashComment: true, backtick: true, delimiterCommand: true},
+	model.DatabaseClickHouse: {backslashEscape: true, backtick: true},
+	model.DatabasePostgreSQL: {dollarQuote: true, escapeString: true},
+	model.DatabaseKingBase:   {dollarQuote: true, escapeString: true},
+	model.DatabaseSQLite:     {backtick: true, bracket: true, triggerBlocks: true},
+	model.DatabaseMSSQL:      {bracket: true, batchSeparator: true},
+	model.DatabaseOracle:     {slashTerminator: true},
+	model.DatabaseDM:         {slashTerminator: true},
+}
+
+var (
+	delimiterCommandRegex = regexp.MustCompile(`(?i)^\s*DELIMITER\s+(\S+)\s*$`)
+	batchSeparatorRegex   = regexp.MustCompile(`(?i)^\s*GO(\s+\d+)?\s*$`)
+	dollarTagRegex        = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+	// plsqlBlockRegex PL/SQL 块开头，块内的分号不结束语句
+	plsqlBlockRegex = regexp.MustCompile(`(?i)^(CREATE\s+(OR\s+REPLACE\s+)?((NON)?EDITIONABLE\s+)?(PROCEDURE|FUNCTION|PACKAGE|TRIGGER|TYPE)\b|DECLARE\b|BEGIN\b)`)
+	// sqliteTriggerRegex SQLite 触发器开头
+	sqliteTriggerRegex = regexp.MustCompile(`(?i)^CREATE\s+(TEMP\s+|TEMPORARY\s+)?TRIGGER\b`)
+)
+
+// scanState 拆分器所处的词法状态
+type scanState int
+
+const (
+	stateNormal scanState = iota
+	stateSingleQuote
+	stateEscapeQuote // E'...' 字符串
+	stateDoubleQuote
+	stateBacktick
+	stateBracket
+	stateBlockComment
+	stateDollarQuote
+)
+
+// StatementScanner 从脚本中逐条读取 SQL 语句
+// 按行读取输入，能处理任意大小的脚本；引号、注释与方言特有的块结构内的分号不会拆分语句。
+type StatementScanner struct {
+	reader  *bufio.Reader
+	dialect splitDialect
+
+	state     scanState
+	dollarTag string
+	delimiter string
+
+	buf          strings.Builder
+	started      bool // 当前语句已出现注释以外的内容
+	contentStart int  // 当前语句正文在 buf 中的起始位置
+	stmtLine     int
+	blockChecked bool // 是否已判断当前语句是否为块结构
+	inBlock      bool // 当前语句为块结构，分号不结束语句
+
+	line    int
+	offset  int64
+	pending []Statement
+	current Statement
+	err     error
+	eof     bool
+}
+
+// NewStatementScanner 创建语句扫描器
+func NewStatementScanner(r io.Reader, dbType model.DatabaseType) *StatementScanner {
+	s := &StatementScanner{
+		reader:    bufio.NewReaderSize(r, 64*1024),
+		dialect:   splitDialects[dbType],
+		delimiter: ";",
+	}
+	if s.dialect.batchSeparator {
+		s.delimiter = ""
+	}
+	return s
+}
+
+// SplitStatements 按数据库方言将脚本拆分为单条语句
+func SplitStatements(dbType model.DatabaseType, script string) []Statement {
+	scanner := NewStatementScanner(strings.NewReader(script), dbType)
+	var statements []Statement
+	for scanner.Scan() {
+		statements = append(statements, scanner.Statement())
+	}
+	return statements
+}
+
+// Scan 读取下一条语句，没有更多语句或出错时返回 false
+func (s *StatementScanner) Scan() bool {
+	for len(s.pending) == 0 {
+		if s.eof || s.err != nil {
+			return false
+		}
+
+		line, err := s.reader.ReadString('\n')
+		if len(line) > 0 {
+			s.line++
+			s.offset += int64(len(line))
+			s.scanLine(line)
+		}
+		if err == io.EOF {
+			s.eof = true
+			s.emit()
+		} else if err != nil {
+			s.err = err
+		}
+	}
+
+	s.current = s.pending[0]
+	s.pending = s.pending[1:]
+	return true
+}
+
+// Statement 返回最近一次 Scan 读取的语句
+func (s *StatementScanner) Statement() Statement {
+	return s.current
+}
+
+// Err 返回读取输入时的错误
+func (s *StatementScanner) Err() error {
+	return s.err
+}
+
+// Offset 返回已读取的字节数
+func (s *StatementScanner) Offset() int64 {
+	return s.offset
+}
+
+// scanLine 处理一行输入
+func (s *StatementScanner) scanLine(line string) {
+	if s.state == stateNormal && s.scanCommand(line) {
+		return
+	}
+
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch s.state {
+		case stateNormal:
+			i = s.scanNormal(line, i)
+		case stateSingleQuote, stateEscapeQuote, stateDoubleQuote, stateBacktick, stateBracket:
+			i = s.scanQuoted(line, i)
+		case stateBlockComment:
+			if end := strings.Index(line[i:], "*/"); end >= 0 {
+				s.buf.WriteString(line[i : i+end+2])
+				s.state = stateNormal
+				i += end + 2
+			} else {
+				s.buf.WriteString(line[i:])
+				i = len(line)
+			}
+		case stateDollarQuote:
+			closing := "$" + s.dollarTag + "$"
+			if end := strings.Index(line[i:], closing); end >= 0 {
+				s.buf.WriteString(line[i : i+end+len(closing)])
+				s.state = stateNormal
+				i += end + len(closing)
+			} else {
+				s.buf.WriteString(line[i:])
+				i = len(line)
+			}
+		default:
+			s.buf.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// scanCommand 处理行级命令（DELIMITER、/、GO），返回该行是否为命令
+func (s *StatementScanner) scanCommand(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	if s.dialect.delimiterCommand {
+		if m := delimiterCommandRegex.FindStringSubmatch(trimmed); m != nil {
+			s.emit()
+			s.delimiter = m[1]
+			return true
+		}
+	}
+	if s.dialect.slashTerminator && trimmed == "/" {
+		s.emit()
+		return true
+	}
+	if s.dialect.batchSeparator && batchSeparatorRegex.MatchString(trimmed) {
+		s.emit()
+		return true
+	}
+	return false
+}
+
+// scanNormal 处理引号与注释之外的内容，返回下一个位置
+func (s *StatementScanner) scanNormal(line string, i int) int {
+	rest := line[i:]
+	c := line[i]
+
+	// 结束符优先匹配（自定义结束符可能与注释开头相同，如 //）
+	if s.delimiter != "" && strings.HasPrefix(rest, s.delimiter) && !s.continuesBlock() {
+		s.emit()
+		return i + len(s.delimiter)
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "--") || (s.dialect.hashComment && c == '#'):
+		// 单行注释
+		s.buf.WriteString(strings.TrimRight(rest, "\r\n"))
+		s.buf.WriteByte('\n')
+		return len(line)
+	case strings.HasPrefix(rest, "/*") && !strings.HasPrefix(rest, "/*!") && !strings.HasPrefix(rest, "/*+"):
+		// 块注释（MySQL 条件注释与优化器提示属于语句内容）
+		s.state = stateBlockComment
+		s.buf.WriteString("/*")
+		return i + 2
+	}
+
+	if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+		s.buf.WriteByte(c)
+		return i + 1
+	}
+
+	s.markStarted()
+
+	switch {
+	case c == '\'' && s.dialect.escapeString && isEscapePrefix(line, i):
+		s.state = stateEscapeQuote
+	case c == '\'':
+		s.state = stateSingleQuote
+	case c == '"':
+		s.state = stateDoubleQuote
+	case c == '`' && s.dialect.backtick:
+		s.state = stateBacktick
+	case c == '[' && s.dialect.bracket:
+		s.state = stateBracket
+	case c == '$' && s.dialect.dollarQuote && (i == 0 || !isIdentChar(line[i-1])):
+		if m := dollarTagRegex.FindStringSubmatch(rest); m != nil {
+			s.state = stateDollarQuote
+			s.dollarTag = m[1]
+			s.buf.WriteString(m[0])
+			return i + len(m[0])
+		}
+	}
+
+	s.buf.WriteByte(c)
+	return i + 1
+}
+
+// scanQuoted 处理字符串与带引号的标识符，返回下一个位置
+func (s *StatementScanner) scanQuoted(line string, i int) int {
+	c := line[i]
+
+	var closing byte
+	switch s.state {
+	case stateSingleQuote, stateEscapeQuote:
+		closing = '\''
+	case stateDoubleQuote:
+		closing = '"'
+	case stateBacktick:
+		closing = '`'
+	case stateBracket:
+		closing = ']'
+	}
+
+	// 反斜杠转义仅适用于字符串
+	escaped := s.state == stateEscapeQuote || (s.dialect.backslashEscape && s.state != stateBracket && s.state != stateBacktick)
+	if c == '\\' && escaped && i+1 < len(line) {
+		s.buf.WriteString(line[i : i+2])
+		return i + 2
+	}
+
+	s.buf.WriteByte(c)
+	if c != closing {
+		return i + 1
+	}
+
+	// 连续两个结束引号表示转义
+	if i+1 < len(line) && line[i+1] == closing {
+		s.buf.WriteByte(closing)
+		return i + 2
+	}
+	s.state = stateNormal
+	return i + 1
+}
+
+// isEscapePrefix 判断 sql[i] 处的单引号是否开始 E'...' 字符串（前面是单独的 E 或 e）
+// U&'...' 等其他字符串中反斜杠不转义引号，按普通字符串处理。
+func isEscapePrefix(sql string, i int) bool {
+	return i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i == 1 || !isWordChar(sql[i-2]))
+}
+
+// backslashQuoted 判断 sql[i] 处开始的单引号字符串中反斜杠是否为转义符
+func (d splitDialect) backslashQuoted(sql string, i int) bool {
+	return d.backslashEscape || (d.escapeString && isEscapePrefix(sql, i))
+}
+
+// markStarted 记录语句正文的起始位置与行号
+func (s *StatementScanner) markStarted() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.contentStart = s.buf.Len()
+	s.stmtLine = s.line
+}
+
+// continuesBlock 判断当前结束符是否位于块结构内部（不结束语句）
+func (s *StatementScanner) continuesBlock() bool {
+	if !s.started {
+		return false
+	}
+
+	if !s.blockChecked {
+		s.blockChecked = true
+		head := s.buf.String()[s.contentStart:]
+		switch {
+		case s.dialect.slashTerminator:
+			s.inBlock = plsqlBlockRegex.MatchString(head)
+		case s.dialect.triggerBlocks:
+			s.inBlock = sqliteTriggerRegex.MatchString(head)
+		}
+	}
+	if !s.inBlock {
+		return false
+	}
+
+	// SQLite 触发器在 END 之后的分号结束
+	if s.dialect.triggerBlocks {
+		body := strings.TrimRight(s.buf.String(), " \t\r\n")
+		if len(body) >= 3 && strings.EqualFold(body[len(body)-3:], "END") &&
+			(len(body) == 3 || !isIdentChar(body[len(body)-4])) {
+			return false
+		}
+	}
+	return true
+}
+
+// emit 结束当前语句并放入待读取队列
+func (s *StatementScanner) emit() {
+	if s.started {
+		text := strings.TrimSpace(s.buf.String()[s.contentStart:])
+		if text != "" {
+			s.pending = append(s.pending, Statement{SQL: text, Line: s.stmtLine})
+		}
+	}
+
+	s.buf.Reset()
+	s.state = stateNormal
+	s.started = false
+	s.contentStart = 0
+	s.blockChecked = false
+	s.inBlock = false
+}
@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"reflect"
+	"testing"
+)
+
+// TestSplitStatements 测试按方言拆分语句
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbType model.DatabaseType
+		script string
+		want   []string
+	}{
+		{
+			name:   "分号拆分并去除前导注释",
+			dbType: model.DatabasePostgreSQL,
+			script: "-- 建表\nCREATE TABLE t (id INT);\n/* 插入 */ INSERT INTO t VALUES (1);\n\n;SELECT 1",
+			want:   []string{"CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1)", "SELECT 1"},
+		},
+		{
+			name:   "引号与注释中的分号",
+			dbType: model.DatabasePostgreSQL,
+			script: "SELECT 'a;''b', \"c;d\" -- e;f\nFROM t; SELECT 2",
+			want:   []string{"SELECT 'a;''b', \"c;d\" -- e;f\nFROM t", "SELECT 2"},
+		},
+		{
+			name:   "PostgreSQL $$ 函数体",
+			dbType: model.DatabasePostgreSQL,
+			script: "CREATE FUNCTION f() RETURNS int AS $body$\nBEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql;\nSELECT f();",
+			want:   []string{"CREATE FUNCTION f() RETURNS int AS $body$\nBEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql", "SELECT f()"},
+		},
+		{
+			name:   "PostgreSQL E'' 字符串中的反斜杠转义",
+			dbType: model.DatabasePostgreSQL,
+			script: "SELECT E'it\\'s;', U&'\\';SELECT 'a\\';",
+			want:   []string{"SELECT E'it\\'s;', U&'\\'", "SELECT 'a\\'"},
+		},
+		{
+			name:   "MySQL DELIMITER 与反斜杠转义",
+			dbType: model.DatabaseMySQL,
+			script: "SELECT 'it\\'s;';\nDELIMITER $$\nCREATE PROCEDURE p() BEGIN SELECT 1; END$$\nDELIMITER ;\n# 注释\nSELECT `a;b` FROM t;",
+			want:   []string{"SELECT 'it\\'s;'", "CREATE PROCEDURE p() BEGIN SELECT 1; END", "SELECT `a;b` FROM t"},
+		},
+		{
+			name:   "MySQL 条件注释属于语句",
+			dbType: model.DatabaseMySQL,
+			script: "/*!40101 SET NAMES utf8mb4 */;\nSELECT 1;",
+			want:   []string{"/*!40101 SET NAMES utf8mb4 */", "SELECT 1"},
+		},
+		{
+			name:   "Oracle PL/SQL 块以 / 结束",
+			dbType: model.DatabaseOracle,
+			script: "CREATE TABLE t (id NUMBER);\nCREATE OR REPLACE PROCEDURE p AS\nBEGIN\n  NULL;\nEND;\n/\nBEGIN\n  p;\nEND;\n/\nSELECT 1 FROM dual;",
+			want:   []string{"CREATE TABLE t (id NUMBER)", "CREATE OR REPLACE PROCEDURE p AS\nBEGIN\n  NULL;\nEND;", "BEGIN\n  p;\nEND;", "SELECT 1 FROM dual"},
+		},
+		{
+			name:   "SQL Server 按 GO 分批",
+			dbType: model.DatabaseMSSQL,
+			script: "DECLARE @x INT; SET @x = 1; SELECT @x;\nGO\nCREATE PROCEDURE p AS BEGIN SELECT [a;b] FROM t; END\ngo\n",
+			want:   []string{"DECLARE @x INT; SET @x = 1; SELECT @x;", "CREATE PROCEDURE p AS BEGIN SELECT [a;b] FROM t; END"},
+		},
+		{
+			name:   "SQLite 触发器",
+			dbType: model.DatabaseSQLite,
+			script: "CREATE TRIGGER tr AFTER INSERT ON t BEGIN\n  UPDATE t SET n = 1;\n  DELETE FROM u;\nEND;\nSELECT 1;",
+			want:   []string{"CREATE TRIGGER tr AFTER INSERT ON t BEGIN\n  UPDATE t SET n = 1;\n  DELETE FROM u;\nEND", "SELECT 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, stmt := range SplitStatements(tt.dbType, tt.script) {
+				got = append(got, stmt.SQL)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSplitStatements_Line 测试语句起始行号
+func TestSplitStatements_Line(t *testing.T) {
+	stmts := SplitStatements(model.DatabaseMySQL, "-- header\n\nSELECT 1;\nSELECT\n2;")
+	if len(stmts) != 2 || stmts[0].Line != 3 || stmts[1].Line != 4 {
+		t.Errorf("unexpected statements: %+v", stmts)
+	}
+}
@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"dbm/internal/export"
 	"dbm/internal/model"
@@ -117,15 +118,14 @@ func (a *SQLiteAdapter) GetTableSchema(db any, database, table string) (*model.T
 		var cid, pkColumn int
 		var notNull int
 		var defaultValue sql.NullString
-		var extra sql.NullString
 
-		if err := colsRows.Scan(&cid, &col.Name, &col.Type, &notNull, &defaultValue, &pkColumn, &extra); err != nil {
+		// PRAGMA table_info 返回 cid, name, type, notnull, dflt_value, pk
+		if err := colsRows.Scan(&cid, &col.Name, &col.Type, &notNull, &defaultValue, &pkColumn); err != nil {
 			return nil, err
 		}
 
 		col.Nullable = notNull == 0
 		col.DefaultValue = defaultValue.String
-		col.Extra = extra.String
 
 		// 判断是否是主键
 		if pkColumn > 0 {
@@ -177,6 +177,20 @@ func (a *SQLiteAdapter) GetTableSchema(db any, database, table string) (*model.T
 		})
 	}
 
+	// 获取外键信息，SQLite 的外键没有名称，未指定引用列时引用的是主键
+	schema.Constraints, err = queryForeignKeys(dbSQL, `
+		SELECT
+			'fk_' || ? || '_' || f.id,
+			f."from",
+			f."table",
+			COALESCE(f."to", (SELECT p.name FROM pragma_table_info(f."table") p WHERE p.pk = f.seq + 1))
+		FROM pragma_foreign_key_list(?) f
+		ORDER BY f.id, f.seq
+	`, table, table)
+	if err != nil {
+		return nil, err
+	}
+
 	return schema, nil
 }
 
@@ -252,10 +266,19 @@ func (a *SQLiteAdapter) GetIndexes(db any, database, table string) ([]model.Inde
 
 // Execute 执行非查询 SQL
 func (a *SQLiteAdapter) Execute(db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.ExecuteContext(context.Background(), db, query, args...)
+}
+
+// ExecuteContext 执行非查询 SQL，ctx 取消时由驱动调用 sqlite3_interrupt 中断执行
+func (a *SQLiteAdapter) ExecuteContext(ctx context.Context, db any, query string, args ...interface{}) (*model.ExecuteResult, error) {
 	start := time.Now()
 
-	result, err := dbSQL.Exec(query, args...)
+	var result sql.Result
+	err := runWithKill(ctx, db, nil, func(q sqlQueryer) error {
+		var err error
+		result, err = q.ExecContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +294,22 @@ func (a *SQLiteAdapter) Execute(db any, query string, args ...interface{}) (*mod
 
 // Query 执行查询
 func (a *SQLiteAdapter) Query(db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
-	dbSQL := db.(*sql.DB)
+	return a.QueryContext(context.Background(), db, query, opts)
+}
+
+// QueryContext 执行查询，ctx 取消时由驱动调用 sqlite3_interrupt 中断执行
+func (a *SQLiteAdapter) QueryContext(ctx context.Context, db any, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	var result *model.QueryResult
+	err := runWithKill(ctx, db, nil, func(q sqlQueryer) error {
+		var err error
+		result, err = a.query(ctx, q, query, opts)
+		return err
+	})
+	return result, err
+}
+
+// query 在指定会话上执行查询
+func (a *SQLiteAdapter) query(ctx context.Context, q sqlQueryer, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
 	start := time.Now()
 
 	trimQuery := strings.TrimSpace(strings.ToUpper(query))
@@ -281,7 +319,7 @@ func (a *SQLiteAdapter) Query(db any, query string, opts *model.QueryOptions) (*
 		strings.HasPrefix(trimQuery, "WITH")
 
 	if !isQuery {
-		result, err := dbSQL.Exec(query)
+		result, err := q.ExecContext(ctx, query)
 		if err != nil {
 			return nil, err
 		}
@@ -293,215 +331,102 @@ func (a *SQLiteAdapter) Query(db any, query string, opts *model.QueryOptions) (*
 		}, nil
 	}
 
-	rows, err := dbSQL.Query(query)
+	rows, err := sqlitePager.query(ctx, q, query, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
+	result, err := scanResult(rows, opts, nil)
 	if err != nil {
 		return nil, err
 	}
+	// 统计总数前释放当前会话上的结果集
+	rows.Close()
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			if val == nil {
-				row[col] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
-		rowData = append(rowData, row)
+	result.Message = "查询成功"
+	if err := sqlitePager.fillTotal(ctx, q, query, opts, result); err != nil {
+		return nil, err
 	}
+	result.TimeCost = time.Since(start)
 
-	return &model.QueryResult{
-		Columns:  columns,
-		Rows:     rowData,
-		Total:    int64(len(rowData)),
-		Message:  "查询成功",
-		TimeCost: time.Since(start),
-	}, nil
+	return result, nil
 }
 
 // Insert 插入数据
 func (a *SQLiteAdapter) Insert(db any, database, table string, data map[string]interface{}) error {
 	dbSQL := db.(*sql.DB)
-	columns := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-
-	for col, val := range data {
-		columns = append(columns, fmt.Sprintf("`%s`", col))
-		placeholders = append(placeholders, "?")
-		values = append(values, val)
-	}
-
-	insertSql := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)",
-		table,
-		strings.Join(columns, ", "),
-		strings.Join(placeholders, ", "))
-
-	_, err := dbSQL.Exec(insertSql, values...)
+	stmt := sqliteEditor.Insert(quoteBacktick(table), data)
+	_, err := dbSQL.Exec(stmt.Query, stmt.Args...)
 	return err
 }
 
-// Update 更新数据
-func (a *SQLiteAdapter) Update(db any, database, table string, data map[string]interface{}, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("更新操作必须指定 WHERE 条件")
-	}
-
-	sets := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-
-	for col, val := range data {
-		sets = append(sets, fmt.Sprintf("`%s` = ?", col))
-		values = append(values, val)
-	}
-
-	updateSql := fmt.Sprintf("UPDATE `%s` SET %s WHERE %s",
-		table,
-		strings.Join(sets, ", "),
-		where)
-
-	_, err := dbSQL.Exec(updateSql, values...)
-	return err
+// InsertRows 批量写入（多行 INSERT）
+func (a *SQLiteAdapter) InsertRows(ctx context.Context, db any, database, table string, columns []string, rows [][]any) (int64, error) {
+	return insertValues(ctx, db, sqliteEditor, quoteBacktick(table), columns, rows, sqliteMaxParams)
 }
 
-// Delete 删除数据
-func (a *SQLiteAdapter) Delete(db any, database, table, where string) error {
-	dbSQL := db.(*sql.DB)
-	if where == "" {
-		return fmt.Errorf("删除操作必须指定 WHERE 条件")
-	}
+// CreateTable 按列定义建表
+func (a *SQLiteAdapter) CreateTable(ctx context.Context, db any, database, table string, columns []model.ColumnDef) error {
+	return createTable(ctx, db, quoteBacktick, quoteBacktick(table), columns, a.buildColumnType, "")
+}
 
-	deleteSql := fmt.Sprintf("DELETE FROM `%s` WHERE %s", table, where)
-	_, err := dbSQL.Exec(deleteSql)
-	return err
+// CountRows 统计表的行数
+func (a *SQLiteAdapter) CountRows(ctx context.Context, db any, database, table string) (int64, error) {
+	return countRows(ctx, db, quoteBacktick(table))
 }
 
-// ExportToCSV 导出为 CSV
-func (a *SQLiteAdapter) ExportToCSV(db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
-	dbSQL := db.(*sql.DB)
-	exporter := export.NewCSVExporter(opts)
+// Update 按行标识更新数据
+func (a *SQLiteAdapter) Update(db any, database, table string, data map[string]interface{}, key model.RowKey) (int64, error) {
+	return updateRow(db, sqliteEditor, quoteBacktick(table), data, key)
+}
 
-	rows, err := dbSQL.Query(query)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
+// Delete 按行标识删除数据
+func (a *SQLiteAdapter) Delete(db any, database, table string, key model.RowKey) (int64, error) {
+	return deleteRow(db, sqliteEditor, quoteBacktick(table), key)
+}
 
-	columns, err := rows.ColumnTypes()
-	if err != nil {
-		return err
-	}
+// ApplyChanges 在单个事务中应用数据变更集
+func (a *SQLiteAdapter) ApplyChanges(ctx context.Context, db any, database, table string, changes *model.ChangeSet, preview bool) (*model.ChangeSetResult, error) {
+	return applyChanges(ctx, db, sqliteEditor, quoteBacktick(table), changes, preview)
+}
 
-	colNames := make([]string, len(columns))
-	for i, col := range columns {
-		colNames[i] = col.Name()
-	}
+// ReadCell 按行标识读取单元格完整值（BLOB、TEXT）
+func (a *SQLiteAdapter) ReadCell(ctx context.Context, db any, database, table, column string, key model.RowKey, fn func(data []byte) error) error {
+	return readCell(ctx, db, sqliteEditor, quoteBacktick(table), column, key, fn)
+}
 
-	var rowData []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// WriteCell 按行标识替换单元格的值
+func (a *SQLiteAdapter) WriteCell(ctx context.Context, db any, database, table, column string, key model.RowKey, data []byte) (int64, error) {
+	return writeCell(ctx, db, sqliteEditor, quoteBacktick(table), column, key, data)
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
-		}
+// StreamQuery 执行查询并以行迭代器逐行读取结果
+func (a *SQLiteAdapter) StreamQuery(ctx context.Context, db any, database, query string, fn func(rows export.RowIterator) error) error {
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			if val == nil {
-				row[col.Name()] = nil
-			} else if b, ok := val.([]byte); ok {
-				row[col.Name()] = string(b)
-			} else {
-				row[col.Name()] = val
-			}
-		}
-		rowData = append(rowData, row)
+// StreamTable 以行迭代器逐行读取表数据，limit > 0 时只读取前 limit 行
+func (a *SQLiteAdapter) StreamTable(ctx context.Context, db any, database, table string, limit int, fn func(rows export.RowIterator) error) error {
+	query := "SELECT * FROM " + quoteBacktick(table)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
+	return streamQuery(ctx, db, query, nil, fn)
+}
 
-	return exporter.Export(writer, colNames, rowData)
+// ExportToCSV 导出为 CSV，逐行读取并写出
+func (a *SQLiteAdapter) ExportToCSV(ctx context.Context, db any, writer io.Writer, database, query string, opts *model.CSVOptions) error {
+	return a.StreamQuery(ctx, db, database, query, exportCSV(writer, opts))
 }
 
-// ExportToSQL 导出为 SQL
-func (a *SQLiteAdapter) ExportToSQL(db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
-	dbSQL := db.(*sql.DB)
+// ExportToSQL 导出为 SQL，表数据逐行读取并写出
+func (a *SQLiteAdapter) ExportToSQL(ctx context.Context, db any, writer io.Writer, database string, tables []string, opts *model.SQLOptions) error {
 	exporter := export.NewSQLExporter(opts, model.DatabaseSQLite)
 
 	// 如果提供了自定义查询，则按查询导出
 	if opts.Query != "" {
-		rows, err := dbSQL.Query(opts.Query)
-		if err != nil {
-			return err
-		}
-		defer rows.Close()
-
-		columns, err := rows.ColumnTypes()
-		if err != nil {
-			return err
-		}
-
-		colNames := make([]string, len(columns))
-		for i, col := range columns {
-			colNames[i] = col.Name()
-		}
-
-		var rowData []map[string]interface{}
-		for rows.Next() {
-			values := make([]interface{}, len(columns))
-			valuePtrs := make([]interface{}, len(columns))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return err
-			}
-
-			row := make(map[string]interface{})
-			for i, col := range columns {
-				val := values[i]
-				if val == nil {
-					row[col.Name()] = nil
-				} else if b, ok := val.([]byte); ok {
-					row[col.Name()] = string(b)
-				} else {
-					row[col.Name()] = val
-				}
-			}
-			rowData = append(rowData, row)
-		}
-
-		// 使用自定义表名，如果没有指定则使用 "query_result" 作为默认值
-		tableName := opts.TableName
-		if tableName == "" {
-			tableName = "query_result"
-		}
-		return exporter.ExportData(writer, "", tableName, colNames, rowData)
+		return a.StreamQuery(ctx, db, database, opts.Query, exportInserts(writer, exporter, "", queryTableName(opts)))
 	}
 
 	for _, table := range tables {
@@ -518,61 +443,14 @@ func (a *SQLiteAdapter) ExportToSQL(db any, writer io.Writer, database string, t
 
 		// 导出数据
 		if !opts.StructureOnly {
-			querySQL := fmt.Sprintf("SELECT * FROM `%s`", table)
-			if opts.MaxRows > 0 {
-				querySQL = fmt.Sprintf("%s LIMIT %d", querySQL, opts.MaxRows)
-			}
-			rows, err := dbSQL.Query(querySQL)
-			if err != nil {
-				return err
-			}
-
-			columns, err := rows.ColumnTypes()
-			if err != nil {
-				rows.Close()
-				return err
-			}
-
-			colNames := make([]string, len(columns))
-			for i, col := range columns {
-				colNames[i] = col.Name()
-			}
-
-			var rowData []map[string]interface{}
-			for rows.Next() {
-				values := make([]interface{}, len(columns))
-				valuePtrs := make([]interface{}, len(columns))
-				for i := range values {
-					valuePtrs[i] = &values[i]
-				}
-
-				if err := rows.Scan(valuePtrs...); err != nil {
-					rows.Close()
-					return err
-				}
-
-				row := make(map[string]interface{})
-				for i, col := range columns {
-					val := values[i]
-					if val == nil {
-						row[col.Name()] = nil
-					} else if b, ok := val.([]byte); ok {
-						row[col.Name()] = string(b)
-					} else {
-						row[col.Name()] = val
-					}
-				}
-				rowData = append(rowData, row)
-			}
-			rows.Close()
-
-			if err := exporter.ExportData(writer, "", table, colNames, rowData); err != nil {
+			if err := a.StreamTable(ctx, db, database, table, opts.MaxRows, exportInserts(writer, exporter, "", table)); err != nil {
 				return err
 			}
 		}
 	}
 
-	return nil
+	// 外键引用的表可能排在后面，在所有表之后添加
+	return exporter.ExportForeignKeys(writer)
 }
 
 // GetCreateTableSQL 获取建表语句
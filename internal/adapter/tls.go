@@ -0,0 +1,211 @@
+package adapter
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"dbm/internal/model"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lib/pq"
+	"kingbase.com/gokb"
+)
+
+// tlsEntry 按 TLS 配置缓存的 tls.Config
+type tlsEntry struct {
+	config *tls.Config
+	info   *model.TLSInfo // 最近一次握手的协商结果
+	mu     sync.Mutex
+}
+
+var (
+	// tlsConfigs 已构建的 tls.Config，key: TLS 配置指纹
+	// 同一配置的连接复用同一个 tls.Config，lib/pq 与 gokb 以指纹注册自定义 sslmode。
+	tlsConfigs sync.Map
+	// tlsBuildMu 构建与注册 tls.Config 时持有
+	tlsBuildMu sync.Mutex
+)
+
+// tlsKey 计算连接 TLS 配置的指纹
+// 校验使用的主机名参与计算，同一证书用于不同主机时互不影响。
+func tlsKey(config *model.ConnectionConfig) string {
+	h := sha256.New()
+	for _, part := range []string{string(config.TLS.Mode), config.TLS.CACert, config.TLS.ClientCert, config.TLS.ClientKey, tlsServerName(config)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return "dbm" + hex.EncodeToString(h.Sum(nil)[:12])
+}
+
+// tlsServerName 校验证书使用的主机名
+func tlsServerName(config *model.ConnectionConfig) string {
+	if config.TLS.ServerName != "" {
+		return config.TLS.ServerName
+	}
+	return config.Host
+}
+
+// newTLSConfig 按连接的 TLS 配置构建 tls.Config 并返回配置指纹，未启用 TLS 时返回 nil
+// 证书校验在 VerifyConnection 中按模式完成，不依赖驱动设置的 ServerName：
+// 经 SSH 隧道连接时驱动看到的地址是本地端口，仍按原主机名校验。
+func newTLSConfig(config *model.ConnectionConfig) (*tls.Config, string, error) {
+	if !config.TLS.Enabled() {
+		return nil, "", nil
+	}
+
+	key := tlsKey(config)
+	if entry, ok := tlsConfigs.Load(key); ok {
+		return entry.(*tlsEntry).config.Clone(), key, nil
+	}
+
+	tlsBuildMu.Lock()
+	defer tlsBuildMu.Unlock()
+	if entry, ok := tlsConfigs.Load(key); ok {
+		return entry.(*tlsEntry).config.Clone(), key, nil
+	}
+
+	entry, err := buildTLSConfig(config)
+	if err != nil {
+		return nil, "", err
+	}
+	// lib/pq 与 gokb 只能通过注册的名称使用自定义 tls.Config
+	if err := pq.RegisterTLSConfig(key, entry.config); err != nil {
+		return nil, "", err
+	}
+	if err := gokb.RegisterTLSConfig(key, entry.config); err != nil {
+		return nil, "", err
+	}
+	tlsConfigs.Store(key, entry)
+
+	// 部分驱动会改写 ServerName，返回副本
+	return entry.config.Clone(), key, nil
+}
+
+// buildTLSConfig 解析证书并构建 tls.Config
+func buildTLSConfig(config *model.ConnectionConfig) (*tlsEntry, error) {
+	cfg := config.TLS
+	switch cfg.Mode {
+	case model.TLSRequire, model.TLSVerifyCA, model.TLSVerifyFull:
+	default:
+		return nil, fmt.Errorf("unsupported tls mode %q, use disable, require, verify-ca or verify-full", cfg.Mode)
+	}
+
+	var roots *x509.CertPool // 为空时使用系统根证书
+	if cfg.CACert != "" {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, errors.New("tls: no valid certificate in CA PEM")
+		}
+	}
+
+	serverName := tlsServerName(config)
+	entry := &tlsEntry{}
+	entry.config = &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+		// 由 VerifyConnection 按模式校验，驱动改写 ServerName 不影响校验结果
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			verify := cfg.Mode != model.TLSRequire
+			if verify {
+				if err := verifyPeer(state, roots, cfg.Mode, serverName); err != nil {
+					return err
+				}
+			}
+			entry.mu.Lock()
+			entry.info = &model.TLSInfo{
+				Version:     tls.VersionName(state.Version),
+				CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+				ServerName:  serverName,
+				Verified:    verify,
+			}
+			entry.mu.Unlock()
+			return nil
+		},
+		// 兼容仍发起重协商的旧版本服务端
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCert), []byte(cfg.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("tls: load client certificate: %w", err)
+		}
+		entry.config.Certificates = []tls.Certificate{cert}
+	}
+
+	return entry, nil
+}
+
+// verifyPeer 校验服务端证书链，verify-full 同时校验主机名
+func verifyPeer(state tls.ConnectionState, roots *x509.CertPool, mode model.TLSMode, serverName string) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("tls: server presented no certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if mode == model.TLSVerifyFull {
+		opts.DNSName = serverName
+	}
+
+	if _, err := state.PeerCertificates[0].Verify(opts); err != nil {
+		return fmt.Errorf("tls: verify server certificate: %w", err)
+	}
+	return nil
+}
+
+// NegotiatedTLS 返回连接最近一次 TLS 握手的协商结果，未启用 TLS 或尚未握手时返回 nil
+func NegotiatedTLS(config *model.ConnectionConfig) *model.TLSInfo {
+	if !config.TLS.Enabled() {
+		return nil
+	}
+	entry, ok := tlsConfigs.Load(tlsKey(config))
+	if !ok {
+		return nil
+	}
+
+	e := entry.(*tlsEntry)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.info == nil {
+		return nil
+	}
+	info := *e.info
+	return &info
+}
+
+// tlsFiles 将客户端证书与私钥写入文件，供只接受文件路径的驱动使用
+// 文件按配置指纹存放在临时目录下，仅当前用户可读，同一配置只写一次。
+func tlsFiles(config *model.ConnectionConfig) (certPath, keyPath string, err error) {
+	dir := filepath.Join(os.TempDir(), "dbm-tls", tlsKey(config))
+	certPath = filepath.Join(dir, "client.crt")
+	keyPath = filepath.Join(dir, "client.key")
+
+	tlsBuildMu.Lock()
+	defer tlsBuildMu.Unlock()
+
+	if _, err := os.Stat(keyPath); err == nil {
+		return certPath, keyPath, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(certPath, []byte(config.TLS.ClientCert), 0600); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(keyPath, []byte(config.TLS.ClientKey), 0600); err != nil {
+		return "", "", err
+	}
+	return certPath, keyPath, nil
+}
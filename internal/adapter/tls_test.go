@@ -0,0 +1,319 @@
+package adapter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"dbm/internal/model"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPKI 测试用的 CA 与证书
+type testPKI struct {
+	caPEM      string
+	serverCert tls.Certificate
+	clientCert string
+	clientKey  string
+	caPool     *x509.CertPool
+}
+
+// newTestPKI 生成 CA、db.example.com 服务端证书与客户端证书
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dbm test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) (string, string) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	}
+
+	pki := &testPKI{
+		caPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+		caPool: x509.NewCertPool(),
+	}
+	pki.caPool.AddCert(ca)
+
+	certPEM, keyPEM := issue(2, "db.example.com", x509.ExtKeyUsageServerAuth)
+	pki.serverCert, err = tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pki.clientCert, pki.clientKey = issue(3, "dbm", x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+// startTLSServer 启动 TLS 服务，客户端提供证书时校验其签发 CA
+func startTLSServer(t *testing.T, pki *testPKI) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{pki.serverCert},
+		ClientCAs:    pki.caPool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// TestNewTLSConfig_Modes 测试各 TLS 模式的证书校验
+func TestNewTLSConfig_Modes(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSServer(t, pki)
+
+	tests := []struct {
+		name    string
+		tls     *model.TLSConfig
+		wantErr string
+	}{
+		{name: "require 不校验证书", tls: &model.TLSConfig{Mode: model.TLSRequire}},
+		{name: "verify-ca 未知 CA", tls: &model.TLSConfig{Mode: model.TLSVerifyCA}, wantErr: "verify server certificate"},
+		{name: "verify-ca", tls: &model.TLSConfig{Mode: model.TLSVerifyCA, CACert: pki.caPEM}},
+		{name: "verify-full 主机名不匹配", tls: &model.TLSConfig{Mode: model.TLSVerifyFull, CACert: pki.caPEM}, wantErr: "127.0.0.1"},
+		{name: "verify-full 指定 ServerName", tls: &model.TLSConfig{Mode: model.TLSVerifyFull, CACert: pki.caPEM, ServerName: "db.example.com"}},
+		{name: "客户端证书", tls: &model.TLSConfig{
+			Mode: model.TLSVerifyFull, CACert: pki.caPEM, ServerName: "db.example.com",
+			ClientCert: pki.clientCert, ClientKey: pki.clientKey,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &model.ConnectionConfig{Host: "127.0.0.1", TLS: tt.tls}
+			cfg, _, err := newTLSConfig(config)
+			if err != nil {
+				t.Fatalf("newTLSConfig() error = %v", err)
+			}
+			// 模拟驱动按拨号地址改写 ServerName
+			cfg.ServerName = "127.0.0.1"
+
+			conn, err := tls.Dial("tcp", addr, cfg)
+			if err == nil {
+				conn.Close()
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Dial() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+
+			info := NegotiatedTLS(config)
+			if info == nil || info.Version == "" || info.CipherSuite == "" {
+				t.Fatalf("NegotiatedTLS() = %+v", info)
+			}
+			if info.Verified != (tt.tls.Mode != model.TLSRequire) {
+				t.Errorf("Verified = %v, mode %s", info.Verified, tt.tls.Mode)
+			}
+		})
+	}
+}
+
+// TestNewTLSConfig_Invalid 测试无效的模式与证书
+func TestNewTLSConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		tls     *model.TLSConfig
+		wantErr string
+	}{
+		{name: "未知模式", tls: &model.TLSConfig{Mode: "prefer"}, wantErr: "unsupported tls mode"},
+		{name: "无效 CA", tls: &model.TLSConfig{Mode: model.TLSVerifyCA, CACert: "junk"}, wantErr: "CA PEM"},
+		{name: "缺少私钥", tls: &model.TLSConfig{Mode: model.TLSRequire, ClientCert: "junk"}, wantErr: "client certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newTLSConfig(&model.ConnectionConfig{Host: "db", TLS: tt.tls})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("newTLSConfig() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if cfg, _, err := newTLSConfig(&model.ConnectionConfig{TLS: &model.TLSConfig{Mode: model.TLSDisable}}); cfg != nil || err != nil {
+		t.Errorf("disable: newTLSConfig() = %v, %v", cfg, err)
+	}
+}
+
+// TestPostgreSQLTLS 测试 lib/pq 使用注册的 tls.Config 完成握手
+func TestPostgreSQLTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// 模拟服务端：应答 SSLRequest 后完成 TLS 握手
+	handshake := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			handshake <- err
+			return
+		}
+		defer conn.Close()
+		if _, err := io.ReadFull(conn, make([]byte, 8)); err != nil {
+			handshake <- err
+			return
+		}
+		conn.Write([]byte{'S'})
+		handshake <- tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{pki.serverCert}}).Handshake()
+	}()
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	config := &model.ConnectionConfig{
+		Host: "127.0.0.1", Port: port, Username: "postgres",
+		Params: map[string]string{"sslmode": "disable", "connect_timeout": "5"},
+		TLS:    &model.TLSConfig{Mode: model.TLSVerifyFull, CACert: pki.caPEM, ServerName: "db.example.com"},
+	}
+	if _, _, err := newTLSConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	dsn := NewPostgreSQLAdapter().buildDSN(config)
+	if !strings.HasSuffix(dsn, "sslmode=pqgo-"+tlsKey(config)) {
+		t.Fatalf("buildDSN() = %q", dsn)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.Ping() // 模拟服务端握手后即断开，只关心握手结果
+
+	if err := <-handshake; err != nil {
+		t.Fatalf("server handshake: %v", err)
+	}
+	if info := NegotiatedTLS(config); info == nil || !info.Verified {
+		t.Fatalf("NegotiatedTLS() = %+v", info)
+	}
+}
+
+// TestTLSDSN 测试各适配器 DSN 中的 TLS 参数
+func TestTLSDSN(t *testing.T) {
+	withTLS := func(c model.ConnectionConfig) *model.ConnectionConfig {
+		c.TLS = &model.TLSConfig{Mode: model.TLSRequire}
+		return &c
+	}
+	base := model.ConnectionConfig{Host: "db", Port: 8123, Username: "u", Password: "p", Database: "d"}
+
+	t.Run("ClickHouse HTTP 启用 TLS 改用 HTTPS", func(t *testing.T) {
+		c := base
+		c.Params = map[string]string{"protocol": "http", "secure": "false"}
+		dsn := NewClickHouseAdapter().buildDSN(withTLS(c))
+		if !strings.HasPrefix(dsn, "https://") || !strings.Contains(dsn, "secure=true") || strings.Contains(dsn, "secure=false") {
+			t.Errorf("buildDSN() = %q", dsn)
+		}
+	})
+
+	t.Run("KingBase", func(t *testing.T) {
+		config := withTLS(base)
+		dsn := NewKingBaseAdapter().buildDSN(config)
+		if !strings.HasSuffix(dsn, "sslmode=gokb-"+tlsKey(config)) {
+			t.Errorf("buildDSN() = %q", dsn)
+		}
+	})
+
+	t.Run("Oracle", func(t *testing.T) {
+		dsn := NewOracleAdapter().buildDSN(withTLS(base))
+		if !strings.Contains(dsn, "SSL=true") {
+			t.Errorf("buildDSN() = %q", dsn)
+		}
+	})
+
+	t.Run("未启用 TLS", func(t *testing.T) {
+		c := base
+		c.TLS = &model.TLSConfig{Mode: model.TLSDisable}
+		if dsn := NewPostgreSQLAdapter().buildDSN(&c); strings.Contains(dsn, "pqgo-") {
+			t.Errorf("buildDSN() = %q", dsn)
+		}
+	})
+}
+
+// TestDMTLSParams 测试达梦只支持 require 模式的客户端证书
+func TestDMTLSParams(t *testing.T) {
+	pki := newTestPKI(t)
+	a := NewDMAdapter()
+
+	config := &model.ConnectionConfig{Host: "db", TLS: &model.TLSConfig{Mode: model.TLSVerifyCA, CACert: pki.caPEM}}
+	if _, err := a.tlsParams(config); err == nil {
+		t.Error("verify-ca should be rejected")
+	}
+
+	config.TLS = &model.TLSConfig{Mode: model.TLSRequire, ClientCert: pki.clientCert, ClientKey: pki.clientKey}
+	params, err := a.tlsParams(config)
+	if err != nil || len(params) != 2 || !strings.HasPrefix(params[0], "sslCertPath=") {
+		t.Fatalf("tlsParams() = %v, %v", params, err)
+	}
+}
+
+// TestTunneledTLSServerName 测试经隧道连接时按原主机名校验证书
+func TestTunneledTLSServerName(t *testing.T) {
+	config := &model.ConnectionConfig{Type: "postgresql", Host: "db.internal", Port: 5432, TLS: &model.TLSConfig{Mode: model.TLSVerifyFull}}
+	tunneled := Tunneled(config, "127.0.0.1", 40000)
+	if got := tlsServerName(tunneled); got != "db.internal" {
+		t.Errorf("tlsServerName() = %q, want db.internal", got)
+	}
+	if config.TLS.ServerName != "" {
+		t.Error("Tunneled() modified the original config")
+	}
+}
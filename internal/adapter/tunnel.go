@@ -0,0 +1,33 @@
+package adapter
+
+import (
+	"dbm/internal/model"
+	"maps"
+)
+
+// Tunneled 返回经 SSH 隧道本地地址连接数据库的配置副本
+// 除改写地址外，固定原本依赖端口推断、主机名校验或会连接其他节点的选项。
+func Tunneled(config *model.ConnectionConfig, host string, port int) *model.ConnectionConfig {
+	tunneled := *config
+	tunneled.Host = host
+	tunneled.Port = port
+
+	// 证书仍按原主机名校验
+	if config.TLS.Enabled() && config.TLS.ServerName == "" {
+		tunneled.TLS = config.TLS.Clone()
+		tunneled.TLS.ServerName = config.Host
+	}
+
+	tunneled.Params = make(map[string]string, len(config.Params)+1)
+	maps.Copy(tunneled.Params, config.Params)
+	switch config.Type {
+	case model.DatabaseClickHouse:
+		// 隧道端口无法再推断 HTTP/HTTPS 协议
+		tunneled.Params["protocol"] = clickHouseProtocol(config)
+	case model.DatabaseMongoDB:
+		// 副本集成员地址在隧道外不可达
+		tunneled.Params["directConnection"] = "true"
+	}
+
+	return &tunneled
+}
@@ -0,0 +1,294 @@
+// Package audit 记录对数据库执行的语句与数据变更
+// 审计记录以 JSON Lines 追加写入数据目录下的文件，超过大小后轮转，并可转发到 syslog 与 webhook。
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"dbm/internal/adapter"
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// currentFile 正在写入的日志文件
+	currentFile = "audit.jsonl"
+	// rotatedPrefix 轮转后的日志文件名前缀，其后为轮转时间，按文件名排序即按时间排序
+	rotatedPrefix = "audit-"
+	// rotatedTimeFormat 轮转文件名中的时间格式
+	rotatedTimeFormat = "20060102-150405.000000000"
+
+	// DefaultQueryLimit 查询默认返回的记录数
+	DefaultQueryLimit = 100
+	// MaxQueryLimit 单次查询返回的最大记录数
+	MaxQueryLimit = 1000
+	// maxLineSize 读取日志时单行的最大字节数
+	maxLineSize = 16 << 20
+)
+
+// Log 追加写入的审计日志
+type Log struct {
+	mu       sync.Mutex
+	dir      string
+	file     *os.File
+	size     int64
+	maxSize  int64
+	maxFiles int
+	redact   bool
+	forward  *forwarder // 转发到 syslog、webhook，未配置时为 nil
+}
+
+// NewLog 打开审计日志目录，配置了 syslog 或 webhook 时启动转发
+func NewLog(dir string, cfg config.AuditConfig) (*Log, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		dir:      dir,
+		maxSize:  int64(cfg.MaxFileSize) << 20,
+		maxFiles: cfg.MaxFiles,
+		redact:   cfg.RedactLiterals,
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	var sinks []Sink
+	if cfg.Syslog.Address != "" {
+		sinks = append(sinks, NewSyslogSink(cfg.Syslog))
+	}
+	if cfg.Webhook.URL != "" {
+		sinks = append(sinks, NewWebhookSink(cfg.Webhook))
+	}
+	if len(sinks) > 0 {
+		l.forward = newForwarder(sinks)
+	}
+	return l, nil
+}
+
+// open 以追加方式打开当前日志文件
+func (l *Log) open() error {
+	file, err := os.OpenFile(filepath.Join(l.dir, currentFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Record 写入一条审计记录，未设置的 ID 与时间自动生成
+// 配置了字面量脱敏时，语句在写入前脱敏；表结构修改与重命名的描述不含数据，不做处理。
+func (l *Log) Record(entry *model.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if l.redact && entry.Statement != "" && entry.Action != model.AuditAlter && entry.Action != model.AuditRename {
+		entry.Statement = adapter.RedactLiterals(entry.DatabaseType, entry.Statement)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	err = l.write(line)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if l.forward != nil {
+		l.forward.send(entry)
+	}
+	return nil
+}
+
+// write 写入一行，超过文件大小上限时先轮转
+func (l *Log) write(line []byte) error {
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// rotate 将当前文件改名为带时间的文件并新建当前文件，删除超出数量的旧文件
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	rotated := filepath.Join(l.dir, rotatedPrefix+time.Now().Format(rotatedTimeFormat)+".jsonl")
+	if err := os.Rename(filepath.Join(l.dir, currentFile), rotated); err != nil {
+		// 改名失败时重新打开当前文件，之后的记录仍可写入
+		if openErr := l.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	// 当前文件也计入保留数量
+	for len(files) > l.maxFiles-1 {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// rotatedFiles 返回轮转后的日志文件，最旧的在前
+func (l *Log) rotatedFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(l.dir, rotatedPrefix+"*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Query 按条件查询审计记录，最近的在前
+// 从最新的文件开始逐个读取，达到 Limit 后停止。
+func (l *Log) Query(filter model.AuditFilter) ([]model.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultQueryLimit
+	}
+	if filter.Limit > MaxQueryLimit {
+		filter.Limit = MaxQueryLimit
+	}
+	filter.Search = strings.ToLower(filter.Search)
+
+	// 持有锁期间确定文件列表，避免与轮转交错
+	l.mu.Lock()
+	files, err := l.rotatedFiles()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, filepath.Join(l.dir, currentFile))
+
+	entries := make([]model.AuditEntry, 0)
+	for i := len(files) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		matched, err := readFile(files[i], &filter)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// 查询期间被轮转删除
+				continue
+			}
+			return nil, err
+		}
+		for j := len(matched) - 1; j >= 0 && len(entries) < filter.Limit; j-- {
+			entries = append(entries, matched[j])
+		}
+	}
+	return entries, nil
+}
+
+// readFile 读取日志文件中符合条件的记录，按写入顺序返回
+func readFile(path string, filter *model.AuditFilter) ([]model.AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []model.AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry model.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// 写入中断留下的不完整行
+			continue
+		}
+		if matches(&entry, filter) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return entries, nil
+}
+
+// matches 判断记录是否符合查询条件
+func matches(entry *model.AuditEntry, filter *model.AuditFilter) bool {
+	switch {
+	case filter.UserID != "" && entry.UserID != filter.UserID:
+		return false
+	case filter.Username != "" && entry.Username != filter.Username:
+		return false
+	case filter.ConnectionID != "" && entry.ConnectionID != filter.ConnectionID:
+		return false
+	case filter.Database != "" && entry.Database != filter.Database:
+		return false
+	case filter.Action != "" && entry.Action != filter.Action:
+		return false
+	case filter.Success != nil && entry.Success != *filter.Success:
+		return false
+	case !filter.From.IsZero() && entry.Time.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && !entry.Time.Before(filter.To):
+		return false
+	case filter.Search != "" && !strings.Contains(strings.ToLower(entry.Statement), filter.Search):
+		return false
+	}
+	return true
+}
+
+// Close 停止转发并关闭日志文件，队列中未转发的记录会先发送完
+func (l *Log) Close() error {
+	if l.forward != nil {
+		l.forward.close()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLog 创建测试用的审计日志
+func newTestLog(t *testing.T, cfg config.AuditConfig) *Log {
+	t.Helper()
+	if cfg.MaxFileSize == 0 {
+		cfg.MaxFileSize = config.DefaultAuditMaxFileSize
+	}
+	if cfg.MaxFiles == 0 {
+		cfg.MaxFiles = config.DefaultAuditMaxFiles
+	}
+	l, err := NewLog(t.TempDir(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// TestLog_Query 测试写入与按条件查询
+func TestLog_Query(t *testing.T) {
+	l := newTestLog(t, config.AuditConfig{})
+	start := time.Now()
+
+	records := []model.AuditEntry{
+		{Username: "alice", ConnectionID: "c1", Database: "app", Action: model.AuditQuery, Statement: "SELECT 1", Success: true},
+		{Username: "bob", ConnectionID: "c1", Database: "app", Action: model.AuditExecute, Statement: "DELETE FROM orders", RowsAffected: 3, Success: true},
+		{Username: "bob", ConnectionID: "c2", Action: model.AuditExecute, Statement: "DROP TABLE t", Error: "permission denied"},
+	}
+	for i := range records {
+		records[i].Time = start.Add(time.Duration(i) * time.Second)
+		if err := l.Record(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failed := false
+	tests := []struct {
+		name   string
+		filter model.AuditFilter
+		want   []string
+	}{
+		{"all, newest first", model.AuditFilter{}, []string{"DROP TABLE t", "DELETE FROM orders", "SELECT 1"}},
+		{"by user", model.AuditFilter{Username: "bob"}, []string{"DROP TABLE t", "DELETE FROM orders"}},
+		{"by connection and database", model.AuditFilter{ConnectionID: "c1", Database: "app", Action: model.AuditExecute}, []string{"DELETE FROM orders"}},
+		{"failed only", model.AuditFilter{Success: &failed}, []string{"DROP TABLE t"}},
+		{"time range", model.AuditFilter{From: start.Add(time.Second), To: start.Add(2 * time.Second)}, []string{"DELETE FROM orders"}},
+		{"search", model.AuditFilter{Search: "orders"}, []string{"DELETE FROM orders"}},
+		{"limit", model.AuditFilter{Limit: 1}, []string{"DROP TABLE t"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := l.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Statement)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	entries, _ := l.Query(model.AuditFilter{Limit: 1})
+	if entries[0].ID == "" {
+		t.Error("ID should be generated")
+	}
+}
+
+// TestLog_Rotate 测试超过大小后轮转并只保留 MaxFiles 个文件，查询跨越多个文件
+func TestLog_Rotate(t *testing.T) {
+	l := newTestLog(t, config.AuditConfig{MaxFiles: 3})
+	l.maxSize = 512
+
+	for i := 0; i < 40; i++ {
+		entry := &model.AuditEntry{Username: "alice", Action: model.AuditQuery, Statement: fmt.Sprintf("SELECT %d", i), Success: true}
+		if err := l.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(l.dir, "*.jsonl"))
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3: %v", len(files), files)
+	}
+
+	entries, err := l.Query(model.AuditFilter{Limit: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || len(entries) >= 40 {
+		t.Fatalf("got %d entries, want older files pruned", len(entries))
+	}
+	if entries[0].Statement != "SELECT 39" {
+		t.Errorf("newest entry = %q", entries[0].Statement)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Time.After(entries[i-1].Time) {
+			t.Fatalf("entries not in reverse order at %d", i)
+		}
+	}
+}
+
+// TestLog_Redact 测试字面量脱敏，表结构修改的描述保持原样
+func TestLog_Redact(t *testing.T) {
+	l := newTestLog(t, config.AuditConfig{RedactLiterals: true})
+
+	query := &model.AuditEntry{DatabaseType: model.DatabasePostgreSQL, Action: model.AuditQuery, Statement: "SELECT * FROM users WHERE password = 'hunter2'"}
+	alter := &model.AuditEntry{DatabaseType: model.DatabasePostgreSQL, Action: model.AuditAlter, Statement: `[{"type":"add_column","column":{"name":"age","length":10}}]`}
+	for _, e := range []*model.AuditEntry{query, alter} {
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if query.Statement != "SELECT * FROM users WHERE password = ?" {
+		t.Errorf("query statement = %q", query.Statement)
+	}
+	if !strings.Contains(alter.Statement, `"length":10`) {
+		t.Errorf("alter statement should be kept: %q", alter.Statement)
+	}
+}
+
+// TestLog_Forward 测试转发到 webhook 与 syslog
+func TestLog_Forward(t *testing.T) {
+	received := make(chan model.AuditEntry, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var entry model.AuditEntry
+		_ = json.NewDecoder(r.Body).Decode(&entry)
+		received <- entry
+	}))
+	defer server.Close()
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+
+	l := newTestLog(t, config.AuditConfig{
+		Syslog:  config.SyslogConfig{Network: "udp", Address: udp.LocalAddr().String(), Tag: "dbm"},
+		Webhook: config.WebhookConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}, Timeout: time.Second},
+	})
+	if err := l.Record(&model.AuditEntry{Username: "alice", Action: model.AuditExecute, Statement: "DELETE FROM t", Error: "boom"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case entry := <-received:
+		if entry.Statement != "DELETE FROM t" || entry.Username != "alice" {
+			t.Errorf("webhook received %+v", entry)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+
+	buf := make([]byte, 4096)
+	_ = udp.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := udp.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// local0.warning：失败的操作
+	if !strings.HasPrefix(msg, "<132>1 ") || !strings.Contains(msg, " dbm - audit - {") || !strings.Contains(msg, `"statement":"DELETE FROM t"`) {
+		t.Errorf("syslog message = %q", msg)
+	}
+}
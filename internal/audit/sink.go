@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"bytes"
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// forwardQueueSize 等待转发的记录数上限，超出时丢弃
+	forwardQueueSize = 1024
+	// syslogDialTimeout 连接 syslog 服务的超时时间
+	syslogDialTimeout = 5 * time.Second
+	// syslogFacility local0
+	syslogFacility = 16
+	// syslogSeverityInfo 成功的操作
+	syslogSeverityInfo = 6
+	// syslogSeverityWarning 失败的操作
+	syslogSeverityWarning = 4
+)
+
+// Sink 审计记录的转发目标
+type Sink interface {
+	Send(entry *model.AuditEntry) error
+	Close() error
+}
+
+// forwarder 在后台依次将审计记录发送到各转发目标
+// 转发目标不可用时不阻塞请求：队列满后丢弃记录并在恢复后输出丢弃数量，本地日志不受影响。
+type forwarder struct {
+	mu      sync.Mutex
+	closed  bool
+	queue   chan model.AuditEntry
+	sinks   []Sink
+	dropped atomic.Int64
+	done    chan struct{}
+}
+
+// newForwarder 创建转发器并启动后台发送
+func newForwarder(sinks []Sink) *forwarder {
+	f := &forwarder{
+		queue: make(chan model.AuditEntry, forwardQueueSize),
+		sinks: sinks,
+		done:  make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// send 将记录加入转发队列
+func (f *forwarder) send(entry *model.AuditEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	select {
+	case f.queue <- *entry:
+	default:
+		f.dropped.Add(1)
+	}
+}
+
+// run 发送队列中的记录，队列关闭后关闭各转发目标
+func (f *forwarder) run() {
+	defer close(f.done)
+	for entry := range f.queue {
+		if n := f.dropped.Swap(0); n > 0 {
+			log.Printf("审计日志转发队列已满，丢弃了 %d 条记录", n)
+		}
+		for _, sink := range f.sinks {
+			if err := sink.Send(&entry); err != nil {
+				log.Printf("审计日志转发失败: %v", err)
+			}
+		}
+	}
+	for _, sink := range f.sinks {
+		_ = sink.Close()
+	}
+}
+
+// close 停止接收记录，等待队列中的记录发送完
+func (f *forwarder) close() {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return
+	}
+	f.closed = true
+	close(f.queue)
+	f.mu.Unlock()
+	<-f.done
+}
+
+// syslogSink 以 RFC 5424 格式发送到 syslog 服务，消息内容为审计记录的 JSON
+// 不依赖 log/syslog，以便在 Windows 上同样可以转发到远程 syslog。
+type syslogSink struct {
+	cfg      config.SyslogConfig
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink 创建 syslog 转发目标，首次发送时建立连接
+func NewSyslogSink(cfg config.SyslogConfig) Sink {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSink{cfg: cfg, hostname: hostname}
+}
+
+// Send 发送一条记录，写入失败时重新连接并重试一次
+func (s *syslogSink) Send(entry *model.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	severity := syslogSeverityInfo
+	if !entry.Success {
+		severity = syslogSeverityWarning
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s - audit - %s",
+		syslogFacility*8+severity, entry.Time.UTC().Format(time.RFC3339Nano), s.hostname, s.cfg.Tag, data)
+	// 流式连接以换行分隔消息
+	if s.cfg.Network == "tcp" || s.cfg.Network == "unix" {
+		msg += "\n"
+	}
+
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			conn, err := net.DialTimeout(s.cfg.Network, s.cfg.Address, syslogDialTimeout)
+			if err != nil {
+				return fmt.Errorf("syslog %s: %w", s.cfg.Address, err)
+			}
+			s.conn = conn
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+		if _, err = io.WriteString(s.conn, msg); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return fmt.Errorf("syslog %s: %w", s.cfg.Address, err)
+		}
+	}
+}
+
+// Close 关闭连接
+func (s *syslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// webhookSink 将每条记录以 JSON 格式 POST 到指定地址
+type webhookSink struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookSink 创建 webhook 转发目标
+func NewWebhookSink(cfg config.WebhookConfig) Sink {
+	return &webhookSink{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Send 发送一条记录，非 2xx 响应视为失败
+func (w *webhookSink) Send(entry *model.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Close 关闭空闲连接
+func (w *webhookSink) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
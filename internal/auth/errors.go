@@ -0,0 +1,44 @@
+package auth
+
+import "errors"
+
+var (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUserExists 用户名已存在
+	ErrUserExists = errors.New("username already exists")
+
+	// ErrInvalidUsername 用户名不合法
+	ErrInvalidUsername = errors.New("username must be 1-64 characters of letters, digits, '.', '_', '-' or '@'")
+
+	// ErrWeakPassword 密码过短
+	ErrWeakPassword = errors.New("password must be at least 8 characters")
+
+	// ErrInvalidRole 未知角色
+	ErrInvalidRole = errors.New("role must be admin or user")
+
+	// ErrInvalidCredentials 用户名或密码错误，或用户已禁用
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrLastAdmin 不能删除、禁用或降级最后一个可用的管理员
+	ErrLastAdmin = errors.New("cannot remove the last active admin")
+
+	// ErrRoleNotFound 权限角色不存在
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrInvalidRoleDef 权限角色定义不合法
+	ErrInvalidRoleDef = errors.New("role name must be 1-64 characters of letters, digits, '.', '_' or '-' with known permissions")
+
+	// ErrBuiltinRole 内置角色不可修改或删除
+	ErrBuiltinRole = errors.New("builtin roles cannot be modified")
+
+	// ErrRoleInUse 角色仍被授权引用
+	ErrRoleInUse = errors.New("role is still granted to users")
+
+	// ErrGrantNotFound 授权不存在
+	ErrGrantNotFound = errors.New("grant not found")
+
+	// ErrInvalidGrant 授权不能同时指定连接与分组
+	ErrInvalidGrant = errors.New("grant must target a connection, a group or all connections")
+)
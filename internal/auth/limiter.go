@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginLimiter 登录失败限流
+// 同一个 key（客户端地址或用户名）在窗口期内失败达到上限后锁定一个窗口期，登录成功后清零。
+type LoginLimiter struct {
+	mu          sync.Mutex
+	failures    map[string]*loginFailures
+	maxAttempts int
+	window      time.Duration
+}
+
+// loginFailures 窗口期内的失败记录
+type loginFailures struct {
+	count       int
+	first       time.Time // 本窗口第一次失败的时间
+	lockedUntil time.Time
+}
+
+// NewLoginLimiter 创建登录限流器，maxAttempts <= 0 时不限流
+func NewLoginLimiter(maxAttempts int, window time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		failures:    make(map[string]*loginFailures),
+		maxAttempts: maxAttempts,
+		window:      window,
+	}
+}
+
+// Allow 检查 keys 是否都允许尝试登录，被锁定时返回剩余锁定时间
+func (l *LoginLimiter) Allow(keys ...string) (time.Duration, bool) {
+	if l.maxAttempts <= 0 {
+		return 0, true
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	for _, key := range keys {
+		if f, ok := l.failures[key]; ok && now.Before(f.lockedUntil) {
+			wait = max(wait, f.lockedUntil.Sub(now))
+		}
+	}
+	return wait, wait == 0
+}
+
+// Fail 记录一次登录失败
+func (l *LoginLimiter) Fail(keys ...string) {
+	if l.maxAttempts <= 0 {
+		return
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	for _, key := range keys {
+		f, ok := l.failures[key]
+		if !ok || now.Sub(f.first) > l.window {
+			f = &loginFailures{first: now}
+			l.failures[key] = f
+		}
+		f.count++
+		if f.count >= l.maxAttempts {
+			f.lockedUntil = now.Add(l.window)
+		}
+	}
+}
+
+// Reset 登录成功后清除失败记录
+func (l *LoginLimiter) Reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		delete(l.failures, key)
+	}
+}
+
+// sweep 清除已过期且未锁定的记录，调用方需持有锁
+func (l *LoginLimiter) sweep(now time.Time) {
+	for key, f := range l.failures {
+		if now.Sub(f.first) > l.window && now.After(f.lockedUntil) {
+			delete(l.failures, key)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package auth
+
+import (
+	"dbm/internal/model"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// roleNamePattern 允许的权限角色名
+var roleNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// PermissionSet 用户在某个连接上拥有的权限
+type PermissionSet map[model.Permission]bool
+
+// Has 是否拥有全部指定权限
+func (p PermissionSet) Has(perms ...model.Permission) bool {
+	for _, perm := range perms {
+		if !p[perm] {
+			return false
+		}
+	}
+	return true
+}
+
+// List 按 model.AllPermissions 的顺序返回拥有的权限
+func (p PermissionSet) List() []model.Permission {
+	perms := make([]model.Permission, 0, len(p))
+	for _, perm := range model.AllPermissions {
+		if p[perm] {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// Policy 权限角色与授权，保存在数据目录的 rbac.json
+// 管理员拥有所有连接的全部权限；普通用户只拥有授权给自己的角色中的权限。
+type Policy struct {
+	mu       sync.RWMutex
+	roles    map[string]*model.Role  // 自定义角色，key: 角色名
+	grants   map[string]*model.Grant // key: grantID
+	dataPath string
+}
+
+// policyFile rbac.json 的内容
+type policyFile struct {
+	Roles  []*model.Role  `json:"roles"`
+	Grants []*model.Grant `json:"grants"`
+}
+
+// NewPolicy 创建权限策略并加载已保存的角色与授权
+func NewPolicy(dataPath string) (*Policy, error) {
+	p := &Policy{
+		roles:    make(map[string]*model.Role),
+		grants:   make(map[string]*model.Grant),
+		dataPath: dataPath,
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Roles 返回内置角色与自定义角色
+func (p *Policy) Roles() []*model.Role {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	roles := model.BuiltinRoles()
+	custom := make([]*model.Role, 0, len(p.roles))
+	for _, role := range p.roles {
+		copied := *role
+		copied.Permissions = slices.Clone(role.Permissions)
+		custom = append(custom, &copied)
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Name < custom[j].Name })
+	return append(roles, custom...)
+}
+
+// SaveRole 创建或更新自定义角色
+func (p *Policy) SaveRole(role *model.Role) (*model.Role, error) {
+	if !roleNamePattern.MatchString(role.Name) || len(role.Permissions) == 0 {
+		return nil, ErrInvalidRoleDef
+	}
+	for _, perm := range role.Permissions {
+		if !perm.Valid() {
+			return nil, ErrInvalidRoleDef
+		}
+	}
+	if builtinRole(role.Name) != nil {
+		return nil, ErrBuiltinRole
+	}
+
+	saved := &model.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: slices.Compact(slices.Sorted(slices.Values(role.Permissions))),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	previous, existed := p.roles[role.Name]
+	p.roles[role.Name] = saved
+	if err := p.save(); err != nil {
+		if existed {
+			p.roles[role.Name] = previous
+		} else {
+			delete(p.roles, role.Name)
+		}
+		return nil, err
+	}
+	copied := *saved
+	return &copied, nil
+}
+
+// DeleteRole 删除自定义角色，仍被授权引用时拒绝
+func (p *Policy) DeleteRole(name string) error {
+	if builtinRole(name) != nil {
+		return ErrBuiltinRole
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	role, ok := p.roles[name]
+	if !ok {
+		return ErrRoleNotFound
+	}
+	for _, grant := range p.grants {
+		if grant.Role == name {
+			return ErrRoleInUse
+		}
+	}
+
+	delete(p.roles, name)
+	if err := p.save(); err != nil {
+		p.roles[name] = role
+		return err
+	}
+	return nil
+}
+
+// Grants 返回用户的授权，userID 为空时返回所有授权
+func (p *Policy) Grants(userID string) []*model.Grant {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	grants := make([]*model.Grant, 0)
+	for _, grant := range p.grants {
+		if userID == "" || grant.UserID == userID {
+			copied := *grant
+			grants = append(grants, &copied)
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].CreatedAt.Before(grants[j].CreatedAt) })
+	return grants
+}
+
+// AddGrant 添加授权
+func (p *Policy) AddGrant(grant *model.Grant) (*model.Grant, error) {
+	if grant.UserID == "" || (grant.ConnectionID != "" && grant.GroupID != "") {
+		return nil, ErrInvalidGrant
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.role(grant.Role) == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	saved := &model.Grant{
+		ID:           uuid.New().String(),
+		UserID:       grant.UserID,
+		ConnectionID: grant.ConnectionID,
+		GroupID:      grant.GroupID,
+		Role:         grant.Role,
+		CreatedAt:    time.Now(),
+	}
+	p.grants[saved.ID] = saved
+	if err := p.save(); err != nil {
+		delete(p.grants, saved.ID)
+		return nil, err
+	}
+	copied := *saved
+	return &copied, nil
+}
+
+// DeleteGrant 删除授权
+func (p *Policy) DeleteGrant(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	grant, ok := p.grants[id]
+	if !ok {
+		return ErrGrantNotFound
+	}
+	delete(p.grants, id)
+	if err := p.save(); err != nil {
+		p.grants[id] = grant
+		return err
+	}
+	return nil
+}
+
+// RemoveTarget 删除用户、连接或分组时清除引用它们的授权
+func (p *Policy) RemoveTarget(userID, connectionID, groupID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	removed := false
+	for id, grant := range p.grants {
+		if (userID != "" && grant.UserID == userID) ||
+			(connectionID != "" && grant.ConnectionID == connectionID) ||
+			(groupID != "" && grant.GroupID == groupID) {
+			delete(p.grants, id)
+			removed = true
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return p.save()
+}
+
+// Permissions 计算用户在连接上的权限
+// groupIDs 为连接所在分组及其所有上级分组。
+func (p *Policy) Permissions(user *model.User, connectionID string, groupIDs []string) PermissionSet {
+	perms := make(PermissionSet)
+	if user.Role == model.RoleAdmin {
+		for _, perm := range model.AllPermissions {
+			perms[perm] = true
+		}
+		return perms
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, grant := range p.grants {
+		if grant.UserID != user.ID {
+			continue
+		}
+		applies := grant.ConnectionID == connectionID ||
+			(grant.GroupID != "" && slices.Contains(groupIDs, grant.GroupID)) ||
+			(grant.ConnectionID == "" && grant.GroupID == "")
+		if !applies {
+			continue
+		}
+		if role := p.role(grant.Role); role != nil {
+			for _, perm := range role.Permissions {
+				perms[perm] = true
+			}
+		}
+	}
+	return perms
+}
+
+// role 按名称查找内置或自定义角色，调用方需持有锁
+func (p *Policy) role(name string) *model.Role {
+	if role := builtinRole(name); role != nil {
+		return role
+	}
+	return p.roles[name]
+}
+
+// builtinRole 按名称查找内置角色
+func builtinRole(name string) *model.Role {
+	for _, role := range model.BuiltinRoles() {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+// load 加载 rbac.json
+func (p *Policy) load() error {
+	if p.dataPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dataPath, "rbac.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var file policyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	for _, role := range file.Roles {
+		p.roles[role.Name] = role
+	}
+	for _, grant := range file.Grants {
+		p.grants[grant.ID] = grant
+	}
+	return nil
+}
+
+// save 写入 rbac.json，先写临时文件再替换，调用方需持有写锁
+func (p *Policy) save() error {
+	if p.dataPath == "" {
+		return nil
+	}
+
+	file := policyFile{
+		Roles:  make([]*model.Role, 0, len(p.roles)),
+		Grants: make([]*model.Grant, 0, len(p.grants)),
+	}
+	for _, role := range p.roles {
+		file.Roles = append(file.Roles, role)
+	}
+	for _, grant := range p.grants {
+		file.Grants = append(file.Grants, grant)
+	}
+	sort.Slice(file.Roles, func(i, j int) bool { return file.Roles[i].Name < file.Roles[j].Name })
+	sort.Slice(file.Grants, func(i, j int) bool { return file.Grants[i].CreatedAt.Before(file.Grants[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(p.dataPath, "rbac.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
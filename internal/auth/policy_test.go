@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"dbm/internal/model"
+	"errors"
+	"testing"
+)
+
+// TestPolicy_Permissions 测试连接、分组与全局授权的权限合并
+func TestPolicy_Permissions(t *testing.T) {
+	p, err := NewPolicy(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &model.User{ID: "u1", Role: model.RoleUser}
+	admin := &model.User{ID: "u0", Role: model.RoleAdmin}
+
+	if perms := p.Permissions(alice, "c1", nil); len(perms) != 0 {
+		t.Fatalf("user without grants should have no permissions, got %v", perms.List())
+	}
+	if !p.Permissions(admin, "c1", nil).Has(model.AllPermissions...) {
+		t.Error("admin should have all permissions")
+	}
+
+	mustGrant := func(g *model.Grant) {
+		t.Helper()
+		if _, err := p.AddGrant(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustGrant(&model.Grant{UserID: "u1", ConnectionID: "c1", Role: "readonly"})
+	mustGrant(&model.Grant{UserID: "u1", GroupID: "parent", Role: "analyst"})
+
+	perms := p.Permissions(alice, "c1", nil)
+	if !perms.Has(model.PermMetadata, model.PermSelect) || perms.Has(model.PermExport) {
+		t.Errorf("c1 permissions = %v", perms.List())
+	}
+	// 分组授权对子分组下的连接生效
+	if perms := p.Permissions(alice, "c2", []string{"child", "parent"}); !perms.Has(model.PermExport) || perms.Has(model.PermDML) {
+		t.Errorf("c2 permissions = %v", perms.List())
+	}
+	if perms := p.Permissions(alice, "c3", []string{"other"}); len(perms) != 0 {
+		t.Errorf("c3 permissions = %v", perms.List())
+	}
+
+	mustGrant(&model.Grant{UserID: "u1", Role: "developer"})
+	if perms := p.Permissions(alice, "c3", nil); !perms.Has(model.PermDML, model.PermEdit) || perms.Has(model.PermDDL) {
+		t.Errorf("global grant permissions = %v", perms.List())
+	}
+
+	if _, err := p.AddGrant(&model.Grant{UserID: "u1", ConnectionID: "c1", GroupID: "g", Role: "readonly"}); !errors.Is(err, ErrInvalidGrant) {
+		t.Errorf("grant with both targets: %v", err)
+	}
+	if _, err := p.AddGrant(&model.Grant{UserID: "u1", Role: "nope"}); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("grant with unknown role: %v", err)
+	}
+
+	if err := p.RemoveTarget("", "", "parent"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(p.Grants("u1")); n != 2 {
+		t.Errorf("grants after removing group = %d, want 2", n)
+	}
+	if err := p.RemoveTarget("u1", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(p.Grants("")); n != 0 {
+		t.Errorf("grants after removing user = %d, want 0", n)
+	}
+}
+
+// TestPolicy_Roles 测试自定义角色的校验、持久化与删除
+func TestPolicy_Roles(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewPolicy(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.SaveRole(&model.Role{Name: "readonly", Permissions: []model.Permission{model.PermSelect}}); !errors.Is(err, ErrBuiltinRole) {
+		t.Errorf("overwrite builtin: %v", err)
+	}
+	if _, err := p.SaveRole(&model.Role{Name: "bad name", Permissions: []model.Permission{model.PermSelect}}); !errors.Is(err, ErrInvalidRoleDef) {
+		t.Errorf("invalid name: %v", err)
+	}
+	if _, err := p.SaveRole(&model.Role{Name: "x", Permissions: []model.Permission{"drop"}}); !errors.Is(err, ErrInvalidRoleDef) {
+		t.Errorf("unknown permission: %v", err)
+	}
+
+	role, err := p.SaveRole(&model.Role{Name: "ops", Permissions: []model.Permission{model.PermSelect, model.PermDDL, model.PermSelect}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(role.Permissions) != 2 {
+		t.Errorf("permissions should be deduplicated: %v", role.Permissions)
+	}
+	if _, err := p.AddGrant(&model.Grant{UserID: "u1", Role: "ops"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新加载后角色与授权仍在
+	reloaded, err := NewPolicy(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(reloaded.Roles()); n != len(model.BuiltinRoles())+1 {
+		t.Errorf("roles after reload = %d", n)
+	}
+	if !reloaded.Permissions(&model.User{ID: "u1"}, "c1", nil).Has(model.PermDDL) {
+		t.Error("grant should survive reload")
+	}
+
+	if err := reloaded.DeleteRole("ops"); !errors.Is(err, ErrRoleInUse) {
+		t.Errorf("delete role in use: %v", err)
+	}
+	if err := reloaded.DeleteRole("owner"); !errors.Is(err, ErrBuiltinRole) {
+		t.Errorf("delete builtin: %v", err)
+	}
+	grants := reloaded.Grants("u1")
+	if err := reloaded.DeleteGrant(grants[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.DeleteRole("ops"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.DeleteRole("ops"); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("delete missing role: %v", err)
+	}
+}
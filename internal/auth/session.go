@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Session 登录会话
+type Session struct {
+	UserID    string
+	CSRFToken string // 通过 Cookie 认证的写请求须在 X-CSRF-Token 头中携带
+	CreatedAt time.Time
+	LastSeen  time.Time
+	ExpiresAt time.Time // 绝对过期时间
+}
+
+// SessionStore 内存中的登录会话，服务重启后需重新登录
+// 只保存令牌的 SHA-256，令牌本身仅返回给客户端。
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session // key: 令牌哈希
+	ttl      time.Duration       // 登录后的最长有效期
+	idle     time.Duration       // 闲置超时
+}
+
+// NewSessionStore 创建会话存储
+func NewSessionStore(ttl, idle time.Duration) *SessionStore {
+	return &SessionStore{
+		sessions: make(map[string]*Session),
+		ttl:      ttl,
+		idle:     idle,
+	}
+}
+
+// Create 为用户创建会话，返回会话与令牌
+func (s *SessionStore) Create(userID string) (*Session, string) {
+	token := randomToken(32)
+	now := time.Now()
+	session := &Session{
+		UserID:    userID,
+		CSRFToken: randomToken(32),
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	s.sessions[hashToken(token)] = session
+	copied := *session
+	return &copied, token
+}
+
+// Get 按令牌获取会话并刷新最近访问时间，过期或不存在时返回 false
+func (s *SessionStore) Get(token string) (*Session, bool) {
+	if token == "" {
+		return nil, false
+	}
+	key := hashToken(token)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[key]
+	if !ok {
+		return nil, false
+	}
+	if s.expired(session, now) {
+		delete(s.sessions, key)
+		return nil, false
+	}
+	session.LastSeen = now
+	copied := *session
+	return &copied, true
+}
+
+// Delete 删除令牌对应的会话
+func (s *SessionStore) Delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, hashToken(token))
+}
+
+// DeleteUser 删除用户的所有会话，except 为保留的令牌（如修改密码的当前会话）
+func (s *SessionStore) DeleteUser(userID, except string) {
+	keep := ""
+	if except != "" {
+		keep = hashToken(except)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, session := range s.sessions {
+		if session.UserID == userID && key != keep {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// expired 会话是否已过期
+func (s *SessionStore) expired(session *Session, now time.Time) bool {
+	if now.After(session.ExpiresAt) {
+		return true
+	}
+	return s.idle > 0 && now.Sub(session.LastSeen) > s.idle
+}
+
+// sweep 清除过期会话，调用方需持有锁
+func (s *SessionStore) sweep(now time.Time) {
+	for key, session := range s.sessions {
+		if s.expired(session, now) {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// CheckCSRF 校验请求携带的 CSRF 令牌
+func (session *Session) CheckCSRF(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) == 1
+}
+
+// hashToken 令牌的 SHA-256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSessionStore 测试会话的创建、过期与按用户撤销
+func TestSessionStore(t *testing.T) {
+	s := NewSessionStore(time.Hour, 50*time.Millisecond)
+
+	session, token := s.Create("u1")
+	got, ok := s.Get(token)
+	if !ok || got.UserID != "u1" || got.CSRFToken != session.CSRFToken {
+		t.Fatalf("Get() = %+v, %v", got, ok)
+	}
+	if !got.CheckCSRF(session.CSRFToken) || got.CheckCSRF("") || got.CheckCSRF("other") {
+		t.Error("CheckCSRF() mismatch")
+	}
+	if _, ok := s.Get("unknown"); ok {
+		t.Error("unknown token should not be valid")
+	}
+
+	// 闲置超时
+	time.Sleep(80 * time.Millisecond)
+	if _, ok := s.Get(token); ok {
+		t.Error("idle session should expire")
+	}
+
+	// 撤销用户的其他会话，保留当前会话
+	_, keep := s.Create("u1")
+	_, other := s.Create("u1")
+	_, another := s.Create("u2")
+	s.DeleteUser("u1", keep)
+	if _, ok := s.Get(keep); !ok {
+		t.Error("kept session was deleted")
+	}
+	if _, ok := s.Get(other); ok {
+		t.Error("other session of the user should be deleted")
+	}
+	if _, ok := s.Get(another); !ok {
+		t.Error("session of another user was deleted")
+	}
+
+	s.Delete(keep)
+	if _, ok := s.Get(keep); ok {
+		t.Error("deleted session should be invalid")
+	}
+}
+
+// TestLoginLimiter 测试登录失败锁定
+func TestLoginLimiter(t *testing.T) {
+	l := NewLoginLimiter(3, 100*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		l.Fail("ip:1", "user:a")
+	}
+	if _, ok := l.Allow("ip:1", "user:a"); !ok {
+		t.Fatal("should allow before reaching the limit")
+	}
+	l.Fail("ip:1", "user:a")
+	if wait, ok := l.Allow("ip:2", "user:a"); ok || wait <= 0 {
+		t.Fatalf("locked user allowed: %v, %v", wait, ok)
+	}
+	if _, ok := l.Allow("ip:2", "user:b"); !ok {
+		t.Error("unrelated keys should be allowed")
+	}
+
+	// 锁定期过后恢复
+	time.Sleep(120 * time.Millisecond)
+	if _, ok := l.Allow("ip:1", "user:a"); !ok {
+		t.Error("lock should expire after the window")
+	}
+
+	// 登录成功后清零
+	l.Fail("user:c")
+	l.Fail("user:c")
+	l.Reset("user:c")
+	l.Fail("user:c")
+	if _, ok := l.Allow("user:c"); !ok {
+		t.Error("Reset should clear failures")
+	}
+
+	if _, ok := NewLoginLimiter(0, time.Minute).Allow("x"); !ok {
+		t.Error("limiter with no maximum should always allow")
+	}
+}
@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"dbm/internal/model"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// usernamePattern 允许的用户名
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]{1,64}$`)
+
+// Store 用户存储，保存在数据目录的 users.json
+type Store struct {
+	mu       sync.RWMutex
+	users    map[string]*model.User // key: userID
+	dataPath string
+
+	dummyOnce sync.Once
+	dummyHash string // 用户不存在时参与校验的哈希，使响应时间与密码错误一致
+}
+
+// UserUpdate 用户更新内容，nil 表示不修改
+type UserUpdate struct {
+	Role     *model.UserRole `json:"role"`
+	Disabled *bool           `json:"disabled"`
+	Password *string         `json:"password"`
+}
+
+// NewStore 创建用户存储并加载已保存的用户
+func NewStore(dataPath string) (*Store, error) {
+	s := &Store{
+		users:    make(map[string]*model.User),
+		dataPath: dataPath,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Bootstrap 首次运行时创建管理员
+// 已有用户时不做任何操作；password 为空时生成随机密码，返回值为实际使用的密码。
+func (s *Store) Bootstrap(username, password string) (string, bool, error) {
+	s.mu.RLock()
+	empty := len(s.users) == 0
+	s.mu.RUnlock()
+	if !empty {
+		return "", false, nil
+	}
+
+	if username == "" {
+		username = "admin"
+	}
+	if password == "" {
+		password = randomToken(12)
+	}
+	if _, err := s.Create(username, password, model.RoleAdmin); err != nil {
+		return "", false, err
+	}
+	return password, true, nil
+}
+
+// List 返回所有用户（不含密码哈希），按用户名排序
+func (s *Store) List() []*model.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*model.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user.Masked())
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+// Get 获取用户（不含密码哈希）
+func (s *Store) Get(id string) (*model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return user.Masked(), nil
+}
+
+// Create 创建用户
+func (s *Store) Create(username, password string, role model.UserRole) (*model.User, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if len(password) < MinPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findByUsername(username) != nil {
+		return nil, ErrUserExists
+	}
+
+	now := time.Now()
+	user := &model.User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		Role:         role,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.users[user.ID] = user
+	if err := s.save(); err != nil {
+		delete(s.users, user.ID)
+		return nil, err
+	}
+	return user.Masked(), nil
+}
+
+// Update 更新用户角色、禁用状态或密码
+func (s *Store) Update(id string, update UserUpdate) (*model.User, error) {
+	if update.Role != nil && !update.Role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	var hash string
+	if update.Password != nil {
+		if len(*update.Password) < MinPasswordLength {
+			return nil, ErrWeakPassword
+		}
+		var err error
+		if hash, err = HashPassword(*update.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	user := *existing
+	if update.Role != nil {
+		user.Role = *update.Role
+	}
+	if update.Disabled != nil {
+		user.Disabled = *update.Disabled
+	}
+	if hash != "" {
+		user.PasswordHash = hash
+	}
+	if isActiveAdmin(existing) && !isActiveAdmin(&user) && s.activeAdmins() == 1 {
+		return nil, ErrLastAdmin
+	}
+
+	user.UpdatedAt = time.Now()
+	s.users[id] = &user
+	if err := s.save(); err != nil {
+		s.users[id] = existing
+		return nil, err
+	}
+	return user.Masked(), nil
+}
+
+// Delete 删除用户
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if isActiveAdmin(user) && s.activeAdmins() == 1 {
+		return ErrLastAdmin
+	}
+
+	delete(s.users, id)
+	if err := s.save(); err != nil {
+		s.users[id] = user
+		return err
+	}
+	return nil
+}
+
+// Authenticate 校验用户名与密码，成功时返回用户（不含密码哈希）
+// 用户不存在、已禁用或密码错误均返回 ErrInvalidCredentials，且都完成一次哈希计算。
+func (s *Store) Authenticate(username, password string) (*model.User, error) {
+	s.mu.RLock()
+	user := s.findByUsername(username)
+	s.mu.RUnlock()
+
+	if user == nil {
+		s.dummyOnce.Do(func() { s.dummyHash, _ = HashPassword(randomToken(16)) })
+		VerifyPassword(s.dummyHash, password)
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := VerifyPassword(user.PasswordHash, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || user.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+	return user.Masked(), nil
+}
+
+// findByUsername 按用户名查找，调用方需持有锁
+func (s *Store) findByUsername(username string) *model.User {
+	for _, user := range s.users {
+		if user.Username == username {
+			return user
+		}
+	}
+	return nil
+}
+
+// activeAdmins 未禁用的管理员数量，调用方需持有锁
+func (s *Store) activeAdmins() int {
+	n := 0
+	for _, user := range s.users {
+		if isActiveAdmin(user) {
+			n++
+		}
+	}
+	return n
+}
+
+// isActiveAdmin 是否为未禁用的管理员
+func isActiveAdmin(user *model.User) bool {
+	return user.Role == model.RoleAdmin && !user.Disabled
+}
+
+// load 加载 users.json
+func (s *Store) load() error {
+	if s.dataPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dataPath, "users.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var users []*model.User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return err
+	}
+	for _, user := range users {
+		s.users[user.ID] = user
+	}
+	return nil
+}
+
+// save 写入 users.json，先写临时文件再替换，调用方需持有写锁
+func (s *Store) save() error {
+	if s.dataPath == "" {
+		return nil
+	}
+
+	users := make([]*model.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(s.dataPath, "users.json")
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"dbm/internal/model"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHashPassword 测试 argon2id 哈希与校验
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Fatalf("unexpected hash format: %s", hash)
+	}
+	if again, _ := HashPassword("correct horse"); again == hash {
+		t.Error("hash should use a random salt")
+	}
+
+	if ok, err := VerifyPassword(hash, "correct horse"); !ok || err != nil {
+		t.Errorf("VerifyPassword(correct) = %v, %v", ok, err)
+	}
+	if ok, err := VerifyPassword(hash, "wrong horse"); ok || err != nil {
+		t.Errorf("VerifyPassword(wrong) = %v, %v", ok, err)
+	}
+	if _, err := VerifyPassword("$2a$10$bcrypt", "x"); err == nil {
+		t.Error("VerifyPassword should reject other hash formats")
+	}
+}
+
+// TestStore_Bootstrap 测试首次运行创建管理员并持久化
+func TestStore_Bootstrap(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	password, created, err := s.Bootstrap("", "")
+	if err != nil || !created || len(password) < MinPasswordLength {
+		t.Fatalf("Bootstrap() = %q, %v, %v", password, created, err)
+	}
+	if _, created, _ := s.Bootstrap("other", "other-password"); created {
+		t.Error("Bootstrap should not create a user when users exist")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), password) || !strings.Contains(string(data), "$argon2id$") {
+		t.Errorf("users.json should only contain the password hash: %s", data)
+	}
+
+	reloaded, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := reloaded.Authenticate("admin", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != model.RoleAdmin || user.PasswordHash != "" {
+		t.Errorf("Authenticate() = %+v", user)
+	}
+}
+
+// TestStore_Users 测试用户的创建、校验、修改与删除
+func TestStore_Users(t *testing.T) {
+	s, err := NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := s.Create("admin", "admin-password", model.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		role     model.UserRole
+		wantErr  error
+	}{
+		{name: "重复用户名", username: "admin", password: "long-enough", role: model.RoleUser, wantErr: ErrUserExists},
+		{name: "非法用户名", username: "a b", password: "long-enough", role: model.RoleUser, wantErr: ErrInvalidUsername},
+		{name: "密码过短", username: "bob", password: "short", role: model.RoleUser, wantErr: ErrWeakPassword},
+		{name: "未知角色", username: "bob", password: "long-enough", role: "root", wantErr: ErrInvalidRole},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Create(tt.username, tt.password, tt.role); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	bob, err := s.Create("bob", "bob-password", model.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate("bob", "wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong password error = %v", err)
+	}
+	if _, err := s.Authenticate("nobody", "bob-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("unknown user error = %v", err)
+	}
+
+	// 禁用后无法登录
+	disabled := true
+	if _, err := s.Update(bob.ID, UserUpdate{Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate("bob", "bob-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("disabled user error = %v", err)
+	}
+
+	// 重置密码
+	enabled, password := false, "new-bob-password"
+	if _, err := s.Update(bob.ID, UserUpdate{Disabled: &enabled, Password: &password}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate("bob", password); err != nil {
+		t.Errorf("Authenticate() after reset error = %v", err)
+	}
+
+	// 最后一个管理员不能被降级、禁用或删除
+	role := model.RoleUser
+	if _, err := s.Update(admin.ID, UserUpdate{Role: &role}); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("demote last admin error = %v", err)
+	}
+	if _, err := s.Update(admin.ID, UserUpdate{Disabled: &disabled}); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("disable last admin error = %v", err)
+	}
+	if err := s.Delete(admin.ID); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("delete last admin error = %v", err)
+	}
+
+	if err := s.Delete(bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if users := s.List(); len(users) != 1 || users[0].Username != "admin" {
+		t.Errorf("List() = %+v", users)
+	}
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultMaxRows 单次查询返回的默认最大行数
+	DefaultMaxRows = 10000
+	// DefaultCountLimit 分页统计总数时的默认上限
+	DefaultCountLimit = 100000
+	// DefaultMaxValueSize 查询结果中单元格值的默认最大字节数
+	DefaultMaxValueSize = 8 * 1024
+
+	// DefaultMaxIdleConns 默认最大空闲连接数
+	DefaultMaxIdleConns = 10
+	// DefaultMaxOpenConns 默认最大打开连接数
+	DefaultMaxOpenConns = 100
+	// DefaultConnMaxLifetime 默认连接最大存活时间
+	DefaultConnMaxLifetime = time.Hour
+	// DefaultConnMaxIdleTime 默认空闲连接最大存活时间
+	DefaultConnMaxIdleTime = 10 * time.Minute
+	// DefaultPoolIdleTimeout 默认连接池闲置回收时间
+	DefaultPoolIdleTimeout = 30 * time.Minute
+
+	// DefaultSessionIdleTimeout 默认会话闲置超时时间
+	DefaultSessionIdleTimeout = 15 * time.Minute
+
+	// DefaultExportRetention 默认导出文件保留时间
+	DefaultExportRetention = 24 * time.Hour
+
+	// DefaultAuthSessionTTL 默认登录会话最长有效期
+	DefaultAuthSessionTTL = 24 * time.Hour
+	// DefaultAuthIdleTimeout 默认登录会话闲置超时时间
+	DefaultAuthIdleTimeout = 2 * time.Hour
+	// DefaultMaxLoginAttempts 默认锁定前允许的登录失败次数
+	DefaultMaxLoginAttempts = 5
+	// DefaultLoginLockout 默认登录失败的统计窗口与锁定时间
+	DefaultLoginLockout = 15 * time.Minute
+
+	// DefaultAuditMaxFileSize 默认审计日志单个文件的最大 MB 数
+	DefaultAuditMaxFileSize = 64
+	// DefaultAuditMaxFiles 默认保留的审计日志文件数
+	DefaultAuditMaxFiles = 20
+	// DefaultAuditSyslogTag 默认 syslog 应用名称
+	DefaultAuditSyslogTag = "dbm"
+	// DefaultAuditWebhookTimeout 默认 webhook 请求超时时间
+	DefaultAuditWebhookTimeout = 5 * time.Second
+)
+
+// Config 配置文件内容
+type Config struct {
+	Query   QueryConfig   `yaml:"query"`
+	Pool    PoolConfig    `yaml:"pool"`
+	Session SessionConfig `yaml:"session"`
+	Export  ExportConfig  `yaml:"export"`
+	Server  ServerConfig  `yaml:"server"`
+	Auth    AuthConfig    `yaml:"auth"`
+	Audit   AuditConfig   `yaml:"audit"`
+}
+
+// QueryConfig 查询配置
+type QueryConfig struct {
+	// MaxRows 单次查询返回的最大行数（硬上限），超出部分被截断
+	MaxRows int `yaml:"max_rows"`
+	// CountLimit 分页统计总数的上限，超过时只返回上限值
+	CountLimit int `yaml:"count_limit"`
+	// MaxValueSize 单元格值的最大字节数，超出部分截断为预览，完整值通过单元格接口获取
+	MaxValueSize int `yaml:"max_value_size"`
+}
+
+// PoolConfig 连接池配置
+type PoolConfig struct {
+	// MaxIdleConns 每个连接池的最大空闲连接数
+	MaxIdleConns int `yaml:"max_idle_conns"`
+	// MaxOpenConns 每个连接池的最大打开连接数
+	MaxOpenConns int `yaml:"max_open_conns"`
+	// ConnMaxLifetime 连接最大存活时间
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// ConnMaxIdleTime 空闲连接最大存活时间
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// IdleTimeout 整个连接池闲置超过该时间后被关闭回收
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+}
+
+// SessionConfig SQL 会话配置
+type SessionConfig struct {
+	// IdleTimeout 会话闲置超过该时间后自动回滚未提交事务并关闭
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+}
+
+// ExportConfig 后台导出配置
+type ExportConfig struct {
+	// Dir 导出文件目录，为空时使用数据目录下的 exports
+	Dir string `yaml:"dir"`
+	// Retention 导出任务结束后文件的保留时间，过期后任务与文件一并清除
+	Retention time.Duration `yaml:"retention"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	// Debug 注册 /debug/pprof 路由，启用认证时仅管理员可访问
+	Debug bool `yaml:"debug"`
+	// AllowedOrigins 允许跨域访问 API 的来源，为空时只允许同源访问
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// AuthConfig 登录认证配置
+type AuthConfig struct {
+	// Enabled 是否要求登录，关闭后所有接口无需认证（仅限本机单人使用）
+	Enabled bool `yaml:"enabled"`
+	// SessionTTL 登录会话的最长有效期
+	SessionTTL time.Duration `yaml:"session_ttl"`
+	// IdleTimeout 登录会话闲置超过该时间后失效
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// MaxLoginAttempts 同一地址或用户名在 LoginLockout 内允许的失败次数，达到后锁定
+	MaxLoginAttempts int `yaml:"max_login_attempts"`
+	// LoginLockout 登录失败的统计窗口与锁定时间
+	LoginLockout time.Duration `yaml:"login_lockout"`
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	// Enabled 是否记录审计日志
+	Enabled bool `yaml:"enabled"`
+	// Dir 审计日志目录，为空时使用数据目录下的 audit
+	Dir string `yaml:"dir"`
+	// RedactLiterals 将语句中的字符串与数字字面量替换为 ?
+	RedactLiterals bool `yaml:"redact_literals"`
+	// MaxFileSize 单个日志文件的最大 MB 数，超过后轮转
+	MaxFileSize int `yaml:"max_file_size"`
+	// MaxFiles 保留的日志文件数（含当前文件），超出时删除最旧的文件
+	MaxFiles int `yaml:"max_files"`
+	// Syslog 转发到 syslog，地址为空时不转发
+	Syslog SyslogConfig `yaml:"syslog"`
+	// Webhook 转发到 HTTP 接口，URL 为空时不转发
+	Webhook WebhookConfig `yaml:"webhook"`
+}
+
+// SyslogConfig syslog 转发配置
+type SyslogConfig struct {
+	// Network 网络类型：udp、tcp、unix、unixgram
+	Network string `yaml:"network"`
+	// Address syslog 服务地址，如 127.0.0.1:514 或 /dev/log
+	Address string `yaml:"address"`
+	// Tag 应用名称
+	Tag string `yaml:"tag"`
+}
+
+// WebhookConfig webhook 转发配置
+type WebhookConfig struct {
+	// URL 接收审计记录的地址，每条记录以 JSON 格式 POST
+	URL string `yaml:"url"`
+	// Headers 附加的请求头，如认证令牌
+	Headers map[string]string `yaml:"headers"`
+	// Timeout 请求超时时间
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Query: QueryConfig{
+			MaxRows:      DefaultMaxRows,
+			CountLimit:   DefaultCountLimit,
+			MaxValueSize: DefaultMaxValueSize,
+		},
+		Pool: PoolConfig{
+			MaxIdleConns:    DefaultMaxIdleConns,
+			MaxOpenConns:    DefaultMaxOpenConns,
+			ConnMaxLifetime: DefaultConnMaxLifetime,
+			ConnMaxIdleTime: DefaultConnMaxIdleTime,
+			IdleTimeout:     DefaultPoolIdleTimeout,
+		},
+		Session: SessionConfig{
+			IdleTimeout: DefaultSessionIdleTimeout,
+		},
+		Export: ExportConfig{
+			Retention: DefaultExportRetention,
+		},
+		Auth: AuthConfig{
+			Enabled:          true,
+			SessionTTL:       DefaultAuthSessionTTL,
+			IdleTimeout:      DefaultAuthIdleTimeout,
+			MaxLoginAttempts: DefaultMaxLoginAttempts,
+			LoginLockout:     DefaultLoginLockout,
+		},
+		Audit: AuditConfig{
+			Enabled:     true,
+			MaxFileSize: DefaultAuditMaxFileSize,
+			MaxFiles:    DefaultAuditMaxFiles,
+			Syslog: SyslogConfig{
+				Network: "udp",
+				Tag:     DefaultAuditSyslogTag,
+			},
+			Webhook: WebhookConfig{
+				Timeout: DefaultAuditWebhookTimeout,
+			},
+		},
+	}
+}
+
+// Load 加载配置文件，文件不存在时返回默认配置
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+// normalize 修正非法配置项为默认值
+func (c *Config) normalize() {
+	if c.Query.MaxRows <= 0 {
+		c.Query.MaxRows = DefaultMaxRows
+	}
+	if c.Query.CountLimit <= 0 {
+		c.Query.CountLimit = DefaultCountLimit
+	}
+	if c.Query.MaxValueSize <= 0 {
+		c.Query.MaxValueSize = DefaultMaxValueSize
+	}
+	if c.Pool.MaxIdleConns < 0 {
+		c.Pool.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if c.Pool.MaxOpenConns < 0 {
+		c.Pool.MaxOpenConns = DefaultMaxOpenConns
+	}
+	if c.Session.IdleTimeout <= 0 {
+		c.Session.IdleTimeout = DefaultSessionIdleTimeout
+	}
+	if c.Export.Retention <= 0 {
+		c.Export.Retention = DefaultExportRetention
+	}
+	if c.Auth.SessionTTL <= 0 {
+		c.Auth.SessionTTL = DefaultAuthSessionTTL
+	}
+	if c.Auth.IdleTimeout < 0 {
+		c.Auth.IdleTimeout = DefaultAuthIdleTimeout
+	}
+	if c.Auth.MaxLoginAttempts < 0 {
+		c.Auth.MaxLoginAttempts = DefaultMaxLoginAttempts
+	}
+	if c.Auth.LoginLockout <= 0 {
+		c.Auth.LoginLockout = DefaultLoginLockout
+	}
+	if c.Audit.MaxFileSize <= 0 {
+		c.Audit.MaxFileSize = DefaultAuditMaxFileSize
+	}
+	if c.Audit.MaxFiles <= 0 {
+		c.Audit.MaxFiles = DefaultAuditMaxFiles
+	}
+	if c.Audit.Syslog.Network == "" {
+		c.Audit.Syslog.Network = "udp"
+	}
+	if c.Audit.Syslog.Tag == "" {
+		c.Audit.Syslog.Tag = DefaultAuditSyslogTag
+	}
+	if c.Audit.Webhook.Timeout <= 0 {
+		c.Audit.Webhook.Timeout = DefaultAuditWebhookTimeout
+	}
+}
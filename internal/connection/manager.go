@@ -1,9 +1,9 @@
 package connection
 
 import (
+	"dbm/internal/config"
 	"dbm/internal/model"
 	"encoding/json"
-	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -11,29 +11,31 @@ import (
 
 // Manager 连接管理器
 type Manager struct {
-	mu                 sync.RWMutex
-	connections        map[string]any // key: connectionID
-	configs            map[string]*model.ConnectionConfig
-	decryptedPasswords map[string]string       // 缓存解密后的密码，避免频繁调用昂贵的 Argon2
-	groups             map[string]*model.Group // key: groupID
-	crypto             *Encryptor
-	dataPath           string
+	mu        sync.RWMutex
+	pool      *Pool // 连接池登记表，key: (connectionID, database)
+	configs   map[string]*model.ConnectionConfig
+	decrypted map[string]*model.ConnectionConfig // 缓存解密后的配置，避免频繁调用昂贵的 Argon2
+	tunnels   *tunnelRegistry                    // 按连接共享的 SSH 隧道
+	groups    map[string]*model.Group            // key: groupID
+	crypto    *Encryptor
+	dataPath  string
 }
 
 // NewManager 创建连接管理器
-func NewManager(dataPath, encryptionKey string) (*Manager, error) {
+func NewManager(dataPath, encryptionKey string, poolConfig config.PoolConfig) (*Manager, error) {
 	crypto, err := NewEncryptor(encryptionKey)
 	if err != nil {
 		return nil, err
 	}
 
 	m := &Manager{
-		connections:        make(map[string]any),
-		configs:            make(map[string]*model.ConnectionConfig),
-		decryptedPasswords: make(map[string]string),
-		groups:             make(map[string]*model.Group),
-		crypto:             crypto,
-		dataPath:           dataPath,
+		pool:      NewPool(poolConfig),
+		configs:   make(map[string]*model.ConnectionConfig),
+		decrypted: make(map[string]*model.ConnectionConfig),
+		tunnels:   newTunnelRegistry(),
+		groups:    make(map[string]*model.Group),
+		crypto:    crypto,
+		dataPath:  dataPath,
 	}
 
 	// 加载配置
@@ -50,23 +52,24 @@ func (m *Manager) AddConnection(config *model.ConnectionConfig) error {
 	defer m.mu.Unlock()
 
 	// 创建副本以避免外部修改（如 masking）影响内部存储
-	configCopy := *config
-
-	// 加密密码
-	// If password looks exactly like base64, assume it might be already encrypted? No, avoid double encryption.
-	// Better: Only encrypt if it's not empty. (But empty password is valid... handled by Connect)
-	// Actually, simple way: always encrypt what's given. The persistence layer saves encrypted.
-	// Wait, updateConnection sends encrypted password from existing? NO.
-	// updateConnection retrieves existing (decrypted), so it's plaintext.
-	// So config.Password here is Plaintext. We must encrypt it.
-	encryptedPassword, err := m.crypto.Encrypt(configCopy.Password)
-	if err != nil {
-		return err
+	// 传入的密码、SSH 凭据与 TLS 证书均为明文，加密后存储
+	configCopy := config.Clone()
+	for _, secret := range configCopy.Secrets() {
+		encrypted, err := m.crypto.Encrypt(*secret)
+		if err != nil {
+			return err
+		}
+		*secret = encrypted
 	}
-	configCopy.Password = encryptedPassword
 
-	m.configs[configCopy.ID] = &configCopy
-	m.decryptedPasswords[configCopy.ID] = config.Password // 缓存原始明文密码
+	// 配置变更后旧的连接池与隧道不再有效
+	if _, exists := m.configs[configCopy.ID]; exists {
+		_ = m.pool.Remove(configCopy.ID)
+		m.tunnels.remove(configCopy.ID)
+	}
+
+	m.configs[configCopy.ID] = configCopy
+	m.decrypted[configCopy.ID] = config.Clone() // 缓存原始明文配置
 
 	// Save to file
 	return m.saveConfigs()
@@ -78,99 +81,105 @@ func (m *Manager) RemoveConnection(id string) error {
 	defer m.mu.Unlock()
 
 	// 关闭数据库连接
-	if db, exists := m.connections[id]; exists {
-		if closer, ok := db.(io.Closer); ok {
-			_ = closer.Close()
-		}
-		delete(m.connections, id)
-	}
+	_ = m.pool.Remove(id)
+	m.tunnels.remove(id)
 
 	delete(m.configs, id)
-	delete(m.decryptedPasswords, id)
+	delete(m.decrypted, id)
 
 	// Save to file
 	return m.saveConfigs()
 }
 
 // GetConnection 获取已缓存的数据库连接
-func (m *Manager) GetConnection(id string) (any, *model.ConnectionConfig, error) {
+func (m *Manager) GetConnection(id, database string) (any, *model.ConnectionConfig, error) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
-
 	config, exists := m.configs[id]
+	m.mu.RUnlock()
 	if !exists {
 		return nil, nil, ErrConnectionNotFound
 	}
 
 	// 如果连接池中已有连接，直接返回
-	if db, exists := m.connections[id]; exists {
+	if db, exists := m.pool.Get(id, database); exists {
 		return db, config, nil
 	}
 
 	return nil, config, nil
 }
 
-// PutConnection 将连接放入管理器
-func (m *Manager) PutConnection(id string, db any) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.connections[id] = db
+// AcquireConnection 获取已缓存的数据库连接并占用，使用结束后调用 release
+func (m *Manager) AcquireConnection(id, database string) (any, func(), bool) {
+	return m.pool.Acquire(id, database)
 }
 
-// CloseConnection 关闭特定连接
-func (m *Manager) CloseConnection(id string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// PutConnection 将连接放入连接池，返回实际使用的连接
+// closer 用于连接池回收时关闭连接。
+func (m *Manager) PutConnection(id, database string, db any, closer func() error) any {
+	return m.pool.Put(id, database, db, closer)
+}
 
-	if db, exists := m.connections[id]; exists {
-		var err error
-		if closer, ok := db.(io.Closer); ok {
-			err = closer.Close()
-		}
-		delete(m.connections, id)
-		return err
-	}
-	return nil
+// CloseConnection 关闭连接的所有连接池
+func (m *Manager) CloseConnection(id string) error {
+	return m.pool.Remove(id)
 }
 
 // IsConnectionActive 检查连接是否活跃
 func (m *Manager) IsConnectionActive(id string) bool {
+	return m.pool.Has(id)
+}
+
+// PoolStats 获取连接的连接池统计信息
+func (m *Manager) PoolStats(id string) ([]model.PoolStats, error) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
-	_, exists := m.connections[id]
-	return exists
+	_, exists := m.configs[id]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, ErrConnectionNotFound
+	}
+
+	return m.pool.Stats(id), nil
 }
 
-// GetConfig 获取连接配置（解密密码）
+// GetConfig 获取连接配置（解密密码、SSH 凭据与 TLS 证书）
 func (m *Manager) GetConfig(id string) (*model.ConnectionConfig, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	// 解密结果写入缓存，需要写锁
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	config, exists := m.configs[id]
 	if !exists {
 		return nil, ErrConnectionNotFound
 	}
-	// 先检查缓存
-	if decrypted, exists := m.decryptedPasswords[id]; exists {
-		configCopy := *config
-		configCopy.Password = decrypted
-		return &configCopy, nil
-	}
 
-	// 解密密码
-	decryptedPassword, err := m.crypto.Decrypt(config.Password)
-	if err != nil {
-		return nil, err
+	// 先检查缓存，未命中时解密并存入缓存
+	decrypted, exists := m.decrypted[id]
+	if !exists {
+		decrypted = config.Clone()
+		for _, secret := range decrypted.Secrets() {
+			value, err := m.crypto.Decrypt(*secret)
+			if err != nil {
+				return nil, err
+			}
+			*secret = value
+		}
+		m.decrypted[id] = decrypted
 	}
 
-	// 存入缓存
-	m.decryptedPasswords[id] = decryptedPassword
-
 	// 返回配置的副本
-	configCopy := *config
-	configCopy.Password = decryptedPassword
+	return decrypted.Clone(), nil
+}
 
-	return &configCopy, nil
+// AcquireTunnel 获取连接的 SSH 隧道并增加引用，未启用隧道时返回 nil
+// config 需为 GetConfig 返回的解密配置。同一连接的使用者共用一条隧道，
+// 使用完毕（关闭数据库连接后）调用 release，最后一个引用释放时关闭隧道。
+func (m *Manager) AcquireTunnel(config *model.ConnectionConfig) (*Tunnel, func(), error) {
+	if config.SSH == nil || !config.SSH.Enabled {
+		return nil, func() {}, nil
+	}
+	return m.tunnels.acquire(config.ID, func() (*Tunnel, error) {
+		return OpenTunnel(config)
+	})
 }
 
 // ListConfigs 获取所有连接配置列表
@@ -180,12 +189,11 @@ func (m *Manager) ListConfigs() ([]*model.ConnectionConfig, error) {
 
 	configs := make([]*model.ConnectionConfig, 0, len(m.configs))
 	for id, config := range m.configs {
-		// 返回时不包含密码
-		configCopy := *config
-		configCopy.Password = ""
+		// 返回时不包含密码、SSH 凭据与 TLS 证书
+		configCopy := config.Masked()
 		// 设置连接状态
-		_, configCopy.Connected = m.connections[id]
-		configs = append(configs, &configCopy)
+		configCopy.Connected = m.pool.Has(id)
+		configs = append(configs, configCopy)
 	}
 
 	return configs, nil
@@ -193,17 +201,7 @@ func (m *Manager) ListConfigs() ([]*model.ConnectionConfig, error) {
 
 // Close 关闭所有连接
 func (m *Manager) Close() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	for id, db := range m.connections {
-		if closer, ok := db.(io.Closer); ok {
-			_ = closer.Close()
-		}
-		delete(m.connections, id)
-	}
-
-	return nil
+	return m.pool.Close()
 }
 
 // ==================== 分组管理 ====================
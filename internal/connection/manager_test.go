@@ -0,0 +1,70 @@
+package connection
+
+import (
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestManager_TLSSecrets 测试 TLS 证书与私钥加密存储
+func TestManager_TLSSecrets(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir, "test-master-key-123", config.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	tlsConfig := &model.TLSConfig{
+		Mode:       model.TLSVerifyFull,
+		CACert:     "ca-pem-content",
+		ClientCert: "cert-pem-content",
+		ClientKey:  "key-pem-content",
+		ServerName: "db.example.com",
+	}
+	if err := m.AddConnection(&model.ConnectionConfig{
+		ID: "conn", Name: "tls", Type: model.DatabasePostgreSQL, Host: "db", Port: 5432, TLS: tlsConfig,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "connections.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"ca-pem-content", "cert-pem-content", "key-pem-content"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("%s stored in plaintext", secret)
+		}
+	}
+	if tlsConfig.ClientKey != "key-pem-content" {
+		t.Error("AddConnection modified the caller's config")
+	}
+
+	configs, _ := m.ListConfigs()
+	if len(configs) != 1 || configs[0].TLS.ClientKey != "" || configs[0].TLS.Mode != model.TLSVerifyFull {
+		t.Errorf("ListConfigs tls = %+v", configs[0].TLS)
+	}
+
+	reloaded, err := NewManager(dir, "test-master-key-123", config.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	cfg, err := reloaded.GetConfig("conn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.TLS != *tlsConfig {
+		t.Fatalf("decrypted tls = %+v", cfg.TLS)
+	}
+
+	// 修改返回的配置不影响缓存
+	cfg.TLS.ClientKey = ""
+	if again, _ := reloaded.GetConfig("conn"); again.TLS.ClientKey != "key-pem-content" {
+		t.Error("GetConfig returned the cached config")
+	}
+}
@@ -0,0 +1,274 @@
+package connection
+
+import (
+	"database/sql"
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// poolKey 连接池键：同一连接的不同数据库使用独立的连接池
+type poolKey struct {
+	connectionID string
+	database     string
+}
+
+// poolEntry 连接池条目
+type poolEntry struct {
+	db        any
+	closer    func() error
+	createdAt time.Time
+	lastUsed  time.Time
+	refs      int // Acquire 占用且尚未释放的次数
+}
+
+// inUse 判断连接池是否有正在使用的连接
+// 非 database/sql 的连接（如 MongoDB 客户端）无法获知内部连接状态，按占用计数判断。
+func (e *poolEntry) inUse() bool {
+	if e.refs > 0 {
+		return true
+	}
+	if dbSQL, ok := e.db.(*sql.DB); ok {
+		return dbSQL.Stats().InUse > 0
+	}
+	return false
+}
+
+// Pool 数据库连接池登记表
+// 按 (连接 ID, 数据库) 复用连接句柄，并定期关闭闲置过久的连接池。
+type Pool struct {
+	mu       sync.Mutex
+	entries  map[poolKey]*poolEntry
+	config   config.PoolConfig
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPool 创建连接池登记表
+func NewPool(cfg config.PoolConfig) *Pool {
+	p := &Pool{
+		entries: make(map[poolKey]*poolEntry),
+		config:  cfg,
+		stop:    make(chan struct{}),
+	}
+
+	if cfg.IdleTimeout > 0 {
+		go p.evictLoop()
+	}
+
+	return p
+}
+
+// Get 获取已登记的连接
+func (p *Pool) Get(connectionID, database string) (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, exists := p.entries[poolKey{connectionID, database}]
+	if !exists {
+		return nil, false
+	}
+	entry.lastUsed = time.Now()
+	return entry.db, true
+}
+
+// Acquire 获取已登记的连接并占用，占用期间不会被闲置回收
+// 返回的 release 在使用结束后调用，重复调用无效。
+func (p *Pool) Acquire(connectionID, database string) (any, func(), bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, exists := p.entries[poolKey{connectionID, database}]
+	if !exists {
+		return nil, nil, false
+	}
+	entry.refs++
+	entry.lastUsed = time.Now()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			p.mu.Lock()
+			entry.refs--
+			entry.lastUsed = time.Now()
+			p.mu.Unlock()
+		})
+	}
+	return entry.db, release, true
+}
+
+// Put 登记连接并返回实际使用的连接
+// 并发创建时若已有其他连接先登记，则关闭传入的连接并返回已登记的连接。
+func (p *Pool) Put(connectionID, database string, db any, closer func() error) any {
+	p.mu.Lock()
+	key := poolKey{connectionID, database}
+	if entry, exists := p.entries[key]; exists {
+		entry.lastUsed = time.Now()
+		p.mu.Unlock()
+		if closer != nil {
+			_ = closer()
+		}
+		return entry.db
+	}
+	defer p.mu.Unlock()
+
+	p.configure(db)
+
+	now := time.Now()
+	p.entries[key] = &poolEntry{
+		db:        db,
+		closer:    closer,
+		createdAt: now,
+		lastUsed:  now,
+	}
+	return db
+}
+
+// configure 按配置设置连接池参数（仅 database/sql 连接）
+func (p *Pool) configure(db any) {
+	dbSQL, ok := db.(*sql.DB)
+	if !ok {
+		return
+	}
+	dbSQL.SetMaxOpenConns(p.config.MaxOpenConns)
+	dbSQL.SetMaxIdleConns(p.config.MaxIdleConns)
+	dbSQL.SetConnMaxLifetime(p.config.ConnMaxLifetime)
+	dbSQL.SetConnMaxIdleTime(p.config.ConnMaxIdleTime)
+}
+
+// Has 判断连接是否有已登记的连接池
+func (p *Pool) Has(connectionID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.entries {
+		if key.connectionID == connectionID {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove 关闭并移除连接的所有连接池
+func (p *Pool) Remove(connectionID string) error {
+	p.mu.Lock()
+	var closers []func() error
+	for key, entry := range p.entries {
+		if key.connectionID == connectionID {
+			closers = append(closers, entry.closer)
+			delete(p.entries, key)
+		}
+	}
+	p.mu.Unlock()
+
+	var firstErr error
+	for _, closer := range closers {
+		if closer == nil {
+			continue
+		}
+		if err := closer(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Stats 获取连接的连接池统计信息
+func (p *Pool) Stats(connectionID string) []model.PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]model.PoolStats, 0)
+	for key, entry := range p.entries {
+		if key.connectionID != connectionID {
+			continue
+		}
+		item := model.PoolStats{
+			Database:   key.database,
+			CreatedAt:  entry.createdAt,
+			LastUsedAt: entry.lastUsed,
+		}
+		if dbSQL, ok := entry.db.(*sql.DB); ok {
+			s := dbSQL.Stats()
+			item.MaxOpenConnections = s.MaxOpenConnections
+			item.OpenConnections = s.OpenConnections
+			item.InUse = s.InUse
+			item.Idle = s.Idle
+			item.WaitCount = s.WaitCount
+			item.WaitDuration = s.WaitDuration.Milliseconds()
+			item.MaxIdleClosed = s.MaxIdleClosed
+			item.MaxIdleTimeClosed = s.MaxIdleTimeClosed
+			item.MaxLifetimeClosed = s.MaxLifetimeClosed
+		}
+		stats = append(stats, item)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Database < stats[j].Database
+	})
+	return stats
+}
+
+// Close 停止回收并关闭所有连接池
+func (p *Pool) Close() error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	p.mu.Lock()
+	entries := p.entries
+	p.entries = make(map[poolKey]*poolEntry)
+	p.mu.Unlock()
+
+	for _, entry := range entries {
+		if entry.closer != nil {
+			_ = entry.closer()
+		}
+	}
+	return nil
+}
+
+// evictLoop 定期回收闲置的连接池
+func (p *Pool) evictLoop() {
+	interval := p.config.IdleTimeout / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.evictIdle(time.Now())
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// evictIdle 关闭闲置超过 IdleTimeout 且没有正在使用连接的连接池，返回关闭数量
+func (p *Pool) evictIdle(now time.Time) int {
+	p.mu.Lock()
+	var evicted []*poolEntry
+	for key, entry := range p.entries {
+		if now.Sub(entry.lastUsed) < p.config.IdleTimeout || entry.inUse() {
+			continue
+		}
+		evicted = append(evicted, entry)
+		delete(p.entries, key)
+	}
+	p.mu.Unlock()
+
+	for _, entry := range evicted {
+		if entry.closer == nil {
+			continue
+		}
+		if err := entry.closer(); err != nil {
+			log.Printf("关闭闲置连接池失败: %v", err)
+		}
+	}
+	return len(evicted)
+}
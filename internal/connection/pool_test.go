@@ -0,0 +1,100 @@
+package connection
+
+import (
+	"dbm/internal/config"
+	"testing"
+	"time"
+)
+
+func TestPool_PutGet(t *testing.T) {
+	p := NewPool(config.PoolConfig{})
+	defer p.Close()
+
+	closed := 0
+	closer := func() error {
+		closed++
+		return nil
+	}
+
+	if got := p.Put("conn1", "db1", "first", closer); got != "first" {
+		t.Fatalf("Put() = %v, want first", got)
+	}
+
+	// 并发创建的连接应被关闭，返回已登记的连接
+	if got := p.Put("conn1", "db1", "second", closer); got != "first" {
+		t.Errorf("Put() duplicate = %v, want first", got)
+	}
+	if closed != 1 {
+		t.Errorf("duplicate connection closed %d times, want 1", closed)
+	}
+
+	if db, ok := p.Get("conn1", "db1"); !ok || db != "first" {
+		t.Errorf("Get() = %v, %v, want first, true", db, ok)
+	}
+	if _, ok := p.Get("conn1", "db2"); ok {
+		t.Error("Get() for other database should miss")
+	}
+
+	p.Put("conn1", "db2", "third", closer)
+	if len(p.Stats("conn1")) != 2 {
+		t.Errorf("Stats() len = %d, want 2", len(p.Stats("conn1")))
+	}
+
+	if err := p.Remove("conn1"); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if p.Has("conn1") {
+		t.Error("Has() after Remove() should be false")
+	}
+	if closed != 3 {
+		t.Errorf("closed = %d, want 3", closed)
+	}
+}
+
+func TestPool_EvictIdle(t *testing.T) {
+	p := NewPool(config.PoolConfig{})
+	defer p.Close()
+	p.config.IdleTimeout = time.Minute
+
+	closed := 0
+	p.Put("conn1", "", "db", func() error {
+		closed++
+		return nil
+	})
+
+	if n := p.evictIdle(time.Now()); n != 0 {
+		t.Errorf("evictIdle() = %d, want 0 for fresh pool", n)
+	}
+	if n := p.evictIdle(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Errorf("evictIdle() = %d, want 1", n)
+	}
+	if closed != 1 || p.Has("conn1") {
+		t.Errorf("idle pool not closed: closed=%d, has=%v", closed, p.Has("conn1"))
+	}
+}
+
+func TestPool_AcquireBlocksEviction(t *testing.T) {
+	p := NewPool(config.PoolConfig{})
+	defer p.Close()
+	p.config.IdleTimeout = time.Minute
+
+	p.Put("conn1", "", "client", nil)
+	if _, _, ok := p.Acquire("conn1", "other"); ok {
+		t.Fatal("Acquire() for unknown database should miss")
+	}
+	db, release, ok := p.Acquire("conn1", "")
+	if !ok || db != "client" {
+		t.Fatalf("Acquire() = %v, %v, want client, true", db, ok)
+	}
+
+	// 非 database/sql 连接在占用期间不应被回收
+	if n := p.evictIdle(time.Now().Add(2 * time.Minute)); n != 0 {
+		t.Errorf("evictIdle() = %d, want 0 while acquired", n)
+	}
+
+	release()
+	release()
+	if n := p.evictIdle(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Errorf("evictIdle() = %d, want 1 after release", n)
+	}
+}
@@ -0,0 +1,426 @@
+package connection
+
+import (
+	"context"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// defaultSSHPort SSH 默认端口
+	defaultSSHPort = 22
+	// sshDialTimeout 建立每一跳 SSH 连接的超时时间
+	sshDialTimeout = 10 * time.Second
+	// sshKeepAliveInterval 隧道保活间隔，及时发现堡垒机断开
+	sshKeepAliveInterval = 30 * time.Second
+)
+
+var (
+	// ErrSSHNoAuth SSH 主机未配置密码或私钥
+	ErrSSHNoAuth = errors.New("ssh: no password or private key configured")
+
+	// ErrSSHNoTarget 启用隧道的连接缺少数据库地址
+	ErrSSHNoTarget = errors.New("ssh: database host and port are required for tunnel")
+
+	// ErrTunnelClosed 隧道已关闭
+	ErrTunnelClosed = errors.New("ssh: tunnel closed")
+)
+
+// Tunnel SSH 隧道
+// 在本机回环地址上监听，将每个连接经 SSH 链路转发到数据库地址。
+// SSH 链路断开后，下一个连接到来时自动重新登录，本地地址保持不变，连接池无需重建。
+type Tunnel struct {
+	ssh      *model.SSHConfig
+	target   string // 数据库地址，由堡垒机解析
+	hostKey  ssh.HostKeyCallback
+	listener net.Listener
+
+	mu      sync.Mutex
+	clients []*ssh.Client // 当前 SSH 链路，依次为各跳板机与堡垒机；断开后为空
+	closed  bool
+}
+
+// OpenTunnel 按连接配置建立 SSH 隧道
+// 建立时即登录一次以便尽早暴露认证与主机密钥错误。
+func OpenTunnel(config *model.ConnectionConfig) (*Tunnel, error) {
+	if config.SSH == nil || !config.SSH.Enabled {
+		return nil, errors.New("ssh: tunnel not enabled")
+	}
+	if config.Host == "" || config.Port <= 0 {
+		return nil, ErrSSHNoTarget
+	}
+
+	hostKey, err := hostKeyCallback(config.SSH)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tunnel{
+		ssh:     config.SSH.Clone(),
+		target:  net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		hostKey: hostKey,
+	}
+	if _, err := t.client(); err != nil {
+		return nil, err
+	}
+
+	t.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+	go t.serve()
+
+	return t, nil
+}
+
+// Addr 返回隧道的本地监听地址
+func (t *Tunnel) Addr() (string, int) {
+	addr := t.listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+// Close 关闭隧道的本地监听与 SSH 链路，已转发的连接随之断开
+func (t *Tunnel) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	clients := t.clients
+	t.clients = nil
+	t.mu.Unlock()
+
+	var err error
+	if t.listener != nil {
+		err = t.listener.Close()
+	}
+	closeClients(clients)
+	return err
+}
+
+// serve 接受本地连接并转发，直到隧道关闭
+func (t *Tunnel) serve() {
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		go t.forward(local)
+	}
+}
+
+// forward 将本地连接经 SSH 链路转发到数据库地址
+func (t *Tunnel) forward(local net.Conn) {
+	defer local.Close()
+
+	client, err := t.client()
+	if err != nil {
+		return
+	}
+	remote, err := client.Dial("tcp", t.target)
+	if err != nil {
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	// 任一方向结束即关闭两端，另一方向随之退出
+	<-done
+}
+
+// client 返回当前 SSH 链路的末端（堡垒机）客户端，链路断开时重新登录
+func (t *Tunnel) client() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, ErrTunnelClosed
+	}
+	if len(t.clients) > 0 {
+		return t.clients[len(t.clients)-1], nil
+	}
+
+	clients, err := dialChain(t.ssh.Hops(), t.hostKey)
+	if err != nil {
+		return nil, err
+	}
+	t.clients = clients
+
+	last := clients[len(clients)-1]
+	go t.keepAlive(last)
+	go func() {
+		// 任一跳断开都会使末端连接结束，此时丢弃整条链路
+		_ = last.Wait()
+		t.mu.Lock()
+		if len(t.clients) > 0 && t.clients[len(t.clients)-1] == last {
+			t.clients = nil
+		}
+		t.mu.Unlock()
+		closeClients(clients)
+	}()
+
+	return last, nil
+}
+
+// keepAlive 定期发送保活请求，失败时关闭链路以便下次重新登录
+func (t *Tunnel) keepAlive(client *ssh.Client) {
+	ticker := time.NewTicker(sshKeepAliveInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+			_ = client.Close()
+			return
+		}
+	}
+}
+
+// dialChain 依次登录各跳 SSH 主机，后一跳经前一跳转发
+func dialChain(hops []model.SSHHop, hostKey ssh.HostKeyCallback) ([]*ssh.Client, error) {
+	clients := make([]*ssh.Client, 0, len(hops))
+	for _, hop := range hops {
+		client, err := dialHop(hop, hostKey, clients)
+		if err != nil {
+			closeClients(clients)
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// dialHop 登录一跳 SSH 主机，prev 非空时经上一跳转发
+func dialHop(hop model.SSHHop, hostKey ssh.HostKeyCallback, prev []*ssh.Client) (*ssh.Client, error) {
+	port := hop.Port
+	if port <= 0 {
+		port = defaultSSHPort
+	}
+	addr := net.JoinHostPort(hop.Host, strconv.Itoa(port))
+
+	auth, err := authMethods(hop)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", addr, err)
+	}
+	config := &ssh.ClientConfig{
+		User:            hop.User,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         sshDialTimeout,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sshDialTimeout)
+	defer cancel()
+
+	var conn net.Conn
+	if len(prev) == 0 {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = prev[len(prev)-1].DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", addr, err)
+	}
+
+	// 握手期间同样受超时限制
+	_ = conn.SetDeadline(time.Now().Add(sshDialTimeout))
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// authMethods 按跳板机配置构造认证方式，私钥优先于密码
+func authMethods(hop model.SSHHop) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	if hop.PrivateKey != "" {
+		var (
+			signer ssh.Signer
+			err    error
+		)
+		if hop.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(hop.PrivateKey), []byte(hop.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(hop.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if hop.Password != "" {
+		password := hop.Password
+		methods = append(methods,
+			ssh.Password(password),
+			// 部分堡垒机只开放键盘交互认证，对所有提问回答密码
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+	if len(methods) == 0 {
+		return nil, ErrSSHNoAuth
+	}
+	return methods, nil
+}
+
+// hostKeyCallback 按 known_hosts 构造主机密钥校验
+// 未配置 known_hosts 内容时使用当前用户的 ~/.ssh/known_hosts。
+func hostKeyCallback(cfg *model.SSHConfig) (ssh.HostKeyCallback, error) {
+	if cfg.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	var (
+		callback ssh.HostKeyCallback
+		err      error
+	)
+	if cfg.KnownHosts != "" {
+		callback, err = parseKnownHosts(cfg.KnownHosts)
+	} else {
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			return nil, fmt.Errorf("ssh: known_hosts not configured: %w", homeErr)
+		}
+		callback, err = knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ssh: load known_hosts: %w", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) > 0 {
+				return fmt.Errorf("ssh: host key mismatch for %s, possible man-in-the-middle attack", hostname)
+			}
+			// 附上可直接加入 known_hosts 的条目，便于核对指纹后信任
+			return fmt.Errorf("ssh: unknown host key for %s (%s), add to known_hosts: %s",
+				hostname, ssh.FingerprintSHA256(key), knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+		}
+		return err
+	}, nil
+}
+
+// parseKnownHosts 解析 known_hosts 内容
+// knownhosts 只能从文件加载，借助临时文件解析，解析完成后即删除。
+func parseKnownHosts(content string) (ssh.HostKeyCallback, error) {
+	file, err := os.CreateTemp("", "dbm-known-hosts-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return knownhosts.New(file.Name())
+}
+
+// closeClients 由末端向前关闭 SSH 链路
+func closeClients(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		_ = clients[i].Close()
+	}
+}
+
+// sharedTunnel 按连接共享的隧道
+type sharedTunnel struct {
+	ready  chan struct{} // 建立完成后关闭
+	tunnel *Tunnel
+	err    error
+	refs   int
+}
+
+// tunnelRegistry 隧道登记表
+// 同一连接的所有连接池共用一条隧道，按引用计数在最后一个使用者释放时关闭。
+type tunnelRegistry struct {
+	mu      sync.Mutex
+	tunnels map[string]*sharedTunnel // key: connectionID
+}
+
+// newTunnelRegistry 创建隧道登记表
+func newTunnelRegistry() *tunnelRegistry {
+	return &tunnelRegistry{tunnels: make(map[string]*sharedTunnel)}
+}
+
+// acquire 获取连接的隧道并增加引用，不存在时以 open 建立
+// 返回的 release 可重复调用，只释放一次引用。
+func (r *tunnelRegistry) acquire(id string, open func() (*Tunnel, error)) (*Tunnel, func(), error) {
+	r.mu.Lock()
+	s, exists := r.tunnels[id]
+	if !exists {
+		s = &sharedTunnel{ready: make(chan struct{})}
+		r.tunnels[id] = s
+	}
+	s.refs++
+	r.mu.Unlock()
+
+	// 建立隧道时不持有锁，其他连接不受慢速堡垒机影响
+	if !exists {
+		s.tunnel, s.err = open()
+		close(s.ready)
+	}
+	<-s.ready
+
+	if s.err != nil {
+		r.release(id, s)
+		return nil, nil, s.err
+	}
+
+	var once sync.Once
+	return s.tunnel, func() { once.Do(func() { r.release(id, s) }) }, nil
+}
+
+// release 释放一次引用，最后一个引用释放时关闭隧道
+func (r *tunnelRegistry) release(id string, s *sharedTunnel) {
+	r.mu.Lock()
+	s.refs--
+	last := s.refs == 0
+	if last && r.tunnels[id] == s {
+		delete(r.tunnels, id)
+	}
+	r.mu.Unlock()
+
+	if last && s.tunnel != nil {
+		_ = s.tunnel.Close()
+	}
+}
+
+// remove 使连接的隧道不再被新的使用者共享，用于配置变更后
+// 仍持有引用的使用者继续使用原隧道，最后一次释放时关闭。
+func (r *tunnelRegistry) remove(id string) {
+	r.mu.Lock()
+	delete(r.tunnels, id)
+	r.mu.Unlock()
+}
@@ -0,0 +1,456 @@
+package connection
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testSSHServer 进程内 SSH 服务器，只支持 direct-tcpip 端口转发
+type testSSHServer struct {
+	addr     string
+	hostKey  ssh.Signer
+	logins   atomic.Int32
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns []*ssh.ServerConn
+}
+
+// newTestSSHServer 启动 SSH 服务器，接受密码 password 或公钥 authorized 登录
+func newTestSSHServer(t *testing.T, password string, authorized ssh.PublicKey) *testSSHServer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if password != "" && string(pass) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected")
+		},
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorized != nil && bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("public key rejected")
+		},
+	}
+	cfg.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSSHServer{addr: listener.Addr().String(), hostKey: hostKey, listener: listener}
+	t.Cleanup(func() {
+		listener.Close()
+		s.dropAll()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn, cfg)
+		}
+	}()
+	return s
+}
+
+// handle 完成握手并转发 direct-tcpip 通道
+func (s *testSSHServer) handle(conn net.Conn, cfg *ssh.ServerConfig) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	s.logins.Add(1)
+	s.mu.Lock()
+	s.conns = append(s.conns, sconn)
+	s.mu.Unlock()
+
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		var payload struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			defer target.Close()
+			go func() {
+				_, _ = io.Copy(target, channel)
+				target.Close()
+			}()
+			_, _ = io.Copy(channel, target)
+		}()
+	}
+}
+
+// dropAll 断开所有已登录的 SSH 连接，模拟堡垒机断线
+func (s *testSSHServer) dropAll() {
+	s.mu.Lock()
+	conns := s.conns
+	s.conns = nil
+	s.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// knownHost 返回该服务器的 known_hosts 条目
+func (s *testSSHServer) knownHost() string {
+	return knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, s.hostKey.PublicKey())
+}
+
+// hostPort 拆分地址
+func hostPort(t *testing.T, addr string) (string, int) {
+	t.Helper()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+// newEchoServer 启动回显服务器，代替数据库
+func newEchoServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// roundTrip 经隧道本地地址发送数据并校验回显
+func roundTrip(tunnel *Tunnel) error {
+	host, port := tunnel.Addr()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		return err
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if string(buf) != "ping" {
+		return fmt.Errorf("echo = %q", buf)
+	}
+	return nil
+}
+
+// tunnelConfig 构造经 bastion 连接 target 的连接配置
+func tunnelConfig(t *testing.T, target string, bastion *testSSHServer, password string) *model.ConnectionConfig {
+	t.Helper()
+	host, port := hostPort(t, target)
+	sshHost, sshPort := hostPort(t, bastion.addr)
+	return &model.ConnectionConfig{
+		ID:   "conn",
+		Type: model.DatabaseMySQL,
+		Host: host,
+		Port: port,
+		SSH: &model.SSHConfig{
+			Enabled:    true,
+			Host:       sshHost,
+			Port:       sshPort,
+			User:       "dbm",
+			Password:   password,
+			KnownHosts: bastion.knownHost(),
+		},
+	}
+}
+
+func TestTunnel_Password(t *testing.T) {
+	bastion := newTestSSHServer(t, "secret", nil)
+	cfg := tunnelConfig(t, newEchoServer(t), bastion, "secret")
+
+	tunnel, err := OpenTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := roundTrip(tunnel); err != nil {
+			t.Fatalf("round trip %d: %v", i, err)
+		}
+	}
+	// 多个本地连接复用同一条 SSH 连接
+	if n := bastion.logins.Load(); n != 1 {
+		t.Errorf("logins = %d, want 1", n)
+	}
+
+	tunnel.Close()
+	if err := roundTrip(tunnel); err == nil {
+		t.Error("round trip after Close succeeded")
+	}
+}
+
+func TestTunnel_JumpChainWithEncryptedKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, _ := ssh.NewSignerFromKey(key)
+
+	jump := newTestSSHServer(t, "jump-secret", nil)
+	bastion := newTestSSHServer(t, "", signer.PublicKey())
+	cfg := tunnelConfig(t, newEchoServer(t), bastion, "")
+	cfg.SSH.PrivateKey = string(pem.EncodeToMemory(block))
+	cfg.SSH.Passphrase = "passphrase"
+	jumpHost, jumpPort := hostPort(t, jump.addr)
+	cfg.SSH.Jumps = []model.SSHHop{{Host: jumpHost, Port: jumpPort, User: "jump", Password: "jump-secret"}}
+	cfg.SSH.KnownHosts = jump.knownHost() + "\n" + bastion.knownHost() + "\n"
+
+	tunnel, err := OpenTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	if err := roundTrip(tunnel); err != nil {
+		t.Fatal(err)
+	}
+	if jump.logins.Load() != 1 || bastion.logins.Load() != 1 {
+		t.Errorf("logins = %d/%d, want 1/1", jump.logins.Load(), bastion.logins.Load())
+	}
+
+	// 口令错误时无法解析私钥
+	cfg.SSH.Passphrase = "wrong"
+	if _, err := OpenTunnel(cfg); err == nil || !strings.Contains(err.Error(), "private key") {
+		t.Errorf("OpenTunnel with wrong passphrase error = %v", err)
+	}
+}
+
+func TestTunnel_HostKeyVerification(t *testing.T) {
+	bastion := newTestSSHServer(t, "secret", nil)
+	other := newTestSSHServer(t, "secret", nil)
+	target := newEchoServer(t)
+
+	// 主机不在 known_hosts 中：错误信息附带可加入的条目
+	cfg := tunnelConfig(t, target, bastion, "secret")
+	cfg.SSH.KnownHosts = other.knownHost()
+	_, err := OpenTunnel(cfg)
+	if err == nil || !strings.Contains(err.Error(), bastion.knownHost()) {
+		t.Errorf("unknown host error = %v", err)
+	}
+
+	// 主机密钥与 known_hosts 不一致
+	cfg.SSH.KnownHosts = knownhosts.Line([]string{knownhosts.Normalize(bastion.addr)}, other.hostKey.PublicKey())
+	if _, err := OpenTunnel(cfg); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("mismatched host key error = %v", err)
+	}
+
+	// 显式跳过校验
+	cfg.SSH.InsecureIgnoreHostKey = true
+	tunnel, err := OpenTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tunnel.Close()
+
+	// 密码错误
+	cfg = tunnelConfig(t, target, bastion, "wrong")
+	if _, err := OpenTunnel(cfg); err == nil {
+		t.Error("OpenTunnel with wrong password succeeded")
+	}
+	cfg.SSH.Password = ""
+	if _, err := OpenTunnel(cfg); err == nil || !strings.Contains(err.Error(), ErrSSHNoAuth.Error()) {
+		t.Errorf("OpenTunnel without auth error = %v", err)
+	}
+}
+
+func TestTunnel_Reconnect(t *testing.T) {
+	bastion := newTestSSHServer(t, "secret", nil)
+	tunnel, err := OpenTunnel(tunnelConfig(t, newEchoServer(t), bastion, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	if err := roundTrip(tunnel); err != nil {
+		t.Fatal(err)
+	}
+
+	// 堡垒机断线后，后续连接自动重新登录，本地地址不变
+	bastion.dropAll()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if err = roundTrip(tunnel); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("tunnel did not reconnect: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := bastion.logins.Load(); n != 2 {
+		t.Errorf("logins = %d, want 2", n)
+	}
+}
+
+func TestManager_SharedTunnel(t *testing.T) {
+	bastion := newTestSSHServer(t, "ssh-secret", nil)
+	dir := t.TempDir()
+	m, err := NewManager(dir, "test-master-key-123", config.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.AddConnection(tunnelConfig(t, newEchoServer(t), bastion, "ssh-secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	// SSH 凭据加密存储，列表中隐藏，GetConfig 返回明文
+	data, err := os.ReadFile(filepath.Join(dir, "connections.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ssh-secret") {
+		t.Error("ssh password stored in plaintext")
+	}
+	configs, _ := m.ListConfigs()
+	if len(configs) != 1 || configs[0].SSH == nil || configs[0].SSH.Password != "" || configs[0].SSH.Host == "" {
+		t.Errorf("ListConfigs ssh = %+v", configs[0].SSH)
+	}
+	reloaded, err := NewManager(dir, "test-master-key-123", config.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := reloaded.GetConfig("conn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SSH.Password != "ssh-secret" {
+		t.Fatalf("decrypted ssh password = %q", cfg.SSH.Password)
+	}
+
+	// 同一连接的使用者共用隧道，最后一个引用释放时关闭
+	first, releaseFirst, err := m.AcquireTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, releaseSecond, err := m.AcquireTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || bastion.logins.Load() != 1 {
+		t.Fatalf("tunnel not shared: %p %p, logins %d", first, second, bastion.logins.Load())
+	}
+	releaseFirst()
+	releaseFirst() // 重复释放无效
+	if err := roundTrip(first); err != nil {
+		t.Fatalf("tunnel closed while still referenced: %v", err)
+	}
+	releaseSecond()
+	if err := roundTrip(first); err == nil {
+		t.Error("tunnel still open after last release")
+	}
+
+	// 配置变更后新的使用者建立新隧道，原隧道在释放前继续可用
+	old, releaseOld, err := m.AcquireTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddConnection(cfg); err != nil {
+		t.Fatal(err)
+	}
+	renewed, releaseRenewed, err := m.AcquireTunnel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releaseRenewed()
+	if renewed == old {
+		t.Error("tunnel shared across config change")
+	}
+	if err := roundTrip(old); err != nil {
+		t.Errorf("old tunnel closed before release: %v", err)
+	}
+	releaseOld()
+	if err := roundTrip(renewed); err != nil {
+		t.Errorf("renewed tunnel: %v", err)
+	}
+
+	// 未启用隧道时不建立隧道
+	cfg.SSH.Enabled = false
+	if tunnel, release, err := m.AcquireTunnel(cfg); tunnel != nil || err != nil {
+		t.Errorf("AcquireTunnel without ssh = %v, %v", tunnel, err)
+	} else {
+		release()
+	}
+}
@@ -1,14 +1,64 @@
 package export
 
 import (
+	"bufio"
+	"bytes"
 	"dbm/internal/model"
-	"encoding/csv"
 	"fmt"
 	"io"
-	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
 )
 
+// csvEncoding CSV 导出支持的编码
+type csvEncoding struct {
+	charset  string            // Content-Type 中的 charset
+	encoding encoding.Encoding // 为 nil 时直接写出 UTF-8
+	bom      []byte            // 文件开头写出的字节序标记
+}
+
+// csvEncodings 按规范化名称（大写，去除 - 与 _）索引的编码
+// UTF-8 与 UTF-16 写出 BOM 以便 Excel 识别，指定字节序的 UTF-16LE/UTF-16BE 不写出 BOM。
+var csvEncodings = map[string]csvEncoding{
+	"UTF8":        {"utf-8", nil, []byte("\xEF\xBB\xBF")},
+	"GBK":         {"gbk", simplifiedchinese.GBK, nil},
+	"GB2312":      {"gbk", simplifiedchinese.GBK, nil},
+	"CP936":       {"gbk", simplifiedchinese.GBK, nil},
+	"GB18030":     {"gb18030", simplifiedchinese.GB18030, nil},
+	"UTF16":       {"utf-16", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), []byte{0xFF, 0xFE}},
+	"UTF16LE":     {"utf-16le", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil},
+	"UTF16BE":     {"utf-16be", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil},
+	"LATIN1":      {"iso-8859-1", charmap.ISO8859_1, nil},
+	"ISO88591":    {"iso-8859-1", charmap.ISO8859_1, nil},
+	"WINDOWS1252": {"windows-1252", charmap.Windows1252, nil},
+	"CP1252":      {"windows-1252", charmap.Windows1252, nil},
+}
+
+// lookupCSVEncoding 按名称查找编码，为空时为 UTF-8
+func lookupCSVEncoding(name string) (csvEncoding, error) {
+	key := strings.ToUpper(strings.NewReplacer("-", "", "_", "").Replace(name))
+	if key == "" {
+		key = "UTF8"
+	}
+	enc, ok := csvEncodings[key]
+	if !ok {
+		return csvEncoding{}, fmt.Errorf("不支持的编码: %s", name)
+	}
+	return enc, nil
+}
+
+// CSVCharset 返回编码对应的 Content-Type charset，编码不受支持时返回错误
+func CSVCharset(name string) (string, error) {
+	enc, err := lookupCSVEncoding(name)
+	return enc.charset, err
+}
+
 // CSVExporter CSV 导出器
 type CSVExporter struct {
 	opts *model.CSVOptions
@@ -35,66 +85,238 @@ func NewCSVExporter(opts *model.CSVOptions) *CSVExporter {
 	if opts.DateFormat == "" {
 		opts.DateFormat = "2006-01-02 15:04:05"
 	}
+	if opts.DecimalPoint == "" {
+		opts.DecimalPoint = "."
+	}
+	if opts.TrueValue == "" {
+		opts.TrueValue = "1"
+	}
+	if opts.FalseValue == "" {
+		opts.FalseValue = "0"
+	}
 
 	return &CSVExporter{opts: opts}
 }
 
-// Export 导出数据到 CSV
-func (e *CSVExporter) Export(writer io.Writer, columns []string, rows []map[string]interface{}) error {
-	// 如果是 UTF-8 编码，写入 BOM 头以解决中文乱码问题
-	if e.opts.Encoding == "UTF-8" {
-		if _, err := writer.Write([]byte("\xEF\xBB\xBF")); err != nil {
+// Export 逐行读取数据并写出 CSV，超过 MaxRows 的行不再读取
+// 选项不合法时在写出任何内容之前返回错误；值无法以目标编码表示时返回出错的行与列。
+func (e *CSVExporter) Export(writer io.Writer, rows RowIterator) error {
+	columns := rows.Columns()
+	w, err := e.newWriter(writer, columns)
+	if err != nil {
+		return err
+	}
+
+	// 写入字节序标记，UTF-8 的 BOM 用于解决 Excel 中文乱码问题
+	if len(w.bom) > 0 {
+		if _, err := w.out.Write(w.bom); err != nil {
 			return fmt.Errorf("failed to write BOM: %w", err)
 		}
 	}
 
-	w := csv.NewWriter(writer)
-	defer w.Flush()
-
 	// 写入表头
 	if e.opts.IncludeHeader {
-		if err := w.Write(columns); err != nil {
+		if err := w.write(columns, nil); err != nil {
 			return fmt.Errorf("failed to write header: %w", err)
 		}
 	}
 
 	// 写入数据行
-	for _, row := range rows {
-		record := make([]string, len(columns))
-		for i, col := range columns {
-			record[i] = e.formatValue(row[col])
+	kinds := columnKinds(rows)
+	rows = Limit(rows, e.opts.MaxRows)
+	record := make([]string, len(columns))
+	nulls := make([]bool, len(columns))
+	for count := 1; rows.Next(); count++ {
+		for i, v := range rows.Values() {
+			record[i], nulls[i] = e.formatValue(v, kinds[i]), v == nil
+		}
+		if err := w.write(record, nulls); err != nil {
+			return fmt.Errorf("第 %d 行: %w", count, err)
 		}
-		if err := w.Write(record); err != nil {
-			return fmt.Errorf("failed to write record: %w", err)
+		if count%flushRows == 0 {
+			if err := w.out.Flush(); err != nil {
+				return err
+			}
+			flush(writer)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	return nil
+	return w.out.Flush()
 }
 
-// formatValue 格式化值
-func (e *CSVExporter) formatValue(v interface{}) string {
-	if v == nil {
-		return e.opts.NullValue
+// newWriter 校验选项并创建记录写出器
+func (e *CSVExporter) newWriter(writer io.Writer, columns []string) (*csvWriter, error) {
+	enc, err := lookupCSVEncoding(e.opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, escape := e.opts.Quote, e.opts.Escape
+	if utf8.RuneCountInString(quote) != 1 {
+		return nil, fmt.Errorf("引号必须是单个字符: %q", quote)
+	}
+	if escape == quote {
+		escape = ""
+	}
+	if escape != "" && utf8.RuneCountInString(escape) != 1 {
+		return nil, fmt.Errorf("转义字符必须是单个字符: %q", escape)
+	}
+
+	separator := strings.ReplaceAll(e.opts.Separator, `\t`, "\t")
+	if strings.Contains(separator, quote) || strings.ContainsAny(separator, "\r\n") {
+		return nil, fmt.Errorf("分隔符不能包含引号或换行符: %q", e.opts.Separator)
+	}
+
+	w := &csvWriter{
+		out:       bufio.NewWriter(writer),
+		columns:   columns,
+		charset:   enc.charset,
+		bom:       enc.bom,
+		separator: separator,
+		quote:     quote,
+		specials:  quote + escape + "\r\n",
+	}
+	if enc.encoding != nil {
+		w.encoder = enc.encoding.NewEncoder()
+	}
+	if escape == "" {
+		w.escaper = strings.NewReplacer(quote, quote+quote)
+	} else {
+		w.escaper = strings.NewReplacer(escape, escape+escape, quote, escape+quote)
 	}
 
+	switch strings.ToLower(e.opts.QuoteMode) {
+	case "", "minimal":
+	case "all":
+		w.quoteAll = true
+	default:
+		return nil, fmt.Errorf("不支持的引号方式: %s", e.opts.QuoteMode)
+	}
+
+	switch strings.ToLower(e.opts.LineEnding) {
+	case "", "lf", "\n":
+		w.lineEnding = "\n"
+	case "crlf", "\r\n":
+		w.lineEnding = "\r\n"
+	default:
+		return nil, fmt.Errorf("不支持的换行符: %q", e.opts.LineEnding)
+	}
+	return w, nil
+}
+
+// formatValue 格式化值，按列类型识别以文本返回的数值、日期与布尔值
+func (e *CSVExporter) formatValue(v any, kind valueKind) string {
 	switch val := v.(type) {
-	case string:
-		return val
-	case int, int8, int16, int32, int64:
-		return fmt.Sprintf("%d", val)
-	case uint, uint8, uint16, uint32, uint64:
-		return fmt.Sprintf("%d", val)
-	case float32, float64:
-		return strconv.FormatFloat(val.(float64), 'f', -1, 64)
+	case nil:
+		return e.opts.NullValue
 	case bool:
-		if val {
-			return "1"
-		}
-		return "0"
+		return e.boolText(val)
 	case time.Time:
 		return val.Format(e.opts.DateFormat)
-	default:
-		return fmt.Sprintf("%v", val)
+	case float32, float64:
+		return e.decimalText(textValue(val, ""))
+	}
+
+	switch kind {
+	case kindNumber:
+		if n, ok := numberText(v); ok {
+			return e.decimalText(n)
+		}
+	case kindDate:
+		if t, ok := dateValue(v); ok {
+			return t.Format(e.opts.DateFormat)
+		}
+	case kindBool:
+		if b, ok := boolValue(v); ok {
+			return e.boolText(b)
+		}
+	}
+	return textValue(v, e.opts.NullValue)
+}
+
+// decimalText 按选项替换数值的小数点
+func (e *CSVExporter) decimalText(n string) string {
+	if e.opts.DecimalPoint == "." {
+		return n
+	}
+	return strings.Replace(n, ".", e.opts.DecimalPoint, 1)
+}
+
+// boolText 布尔值的文本
+func (e *CSVExporter) boolText(b bool) string {
+	if b {
+		return e.opts.TrueValue
+	}
+	return e.opts.FalseValue
+}
+
+// csvWriter 按选项拼接 CSV 记录并转换为目标编码
+// encoding/csv 只支持单字符分隔符与固定的双引号，因此自行处理引号与转义。
+type csvWriter struct {
+	out        *bufio.Writer
+	encoder    *encoding.Encoder // 为 nil 时直接写出 UTF-8
+	columns    []string
+	charset    string
+	bom        []byte
+	separator  string
+	quote      string
+	escaper    *strings.Replacer
+	specials   string // 出现时需要加引号的字符
+	quoteAll   bool
+	lineEnding string
+	line       bytes.Buffer
+}
+
+// write 写出一条记录，nulls 标记的 NULL 值按原样写出，不加引号也不转义，以区分 NULL 与文本
+func (w *csvWriter) write(record []string, nulls []bool) error {
+	w.line.Reset()
+	for i, field := range record {
+		if i > 0 {
+			w.line.WriteString(w.separator)
+		}
+		null := nulls != nil && nulls[i]
+		if !null && (w.quoteAll || w.needsQuotes(field)) {
+			w.line.WriteString(w.quote)
+			w.escaper.WriteString(&w.line, field)
+			w.line.WriteString(w.quote)
+		} else {
+			w.line.WriteString(field)
+		}
+	}
+	w.line.WriteString(w.lineEnding)
+
+	out := w.line.Bytes()
+	if w.encoder != nil {
+		encoded, err := w.encoder.Bytes(out)
+		if err != nil {
+			return w.encodeError(record)
+		}
+		out = encoded
+	}
+	_, err := w.out.Write(out)
+	return err
+}
+
+// needsQuotes 字段包含分隔符、引号、转义字符、换行符或以空白开头时需要加引号
+func (w *csvWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if strings.Contains(field, w.separator) || strings.ContainsAny(field, w.specials) {
+		return true
+	}
+	return field[0] == ' ' || field[0] == '\t'
+}
+
+// encodeError 找出无法以目标编码表示的字段
+func (w *csvWriter) encodeError(record []string) error {
+	for i, field := range record {
+		if _, err := w.encoder.String(field); err != nil && i < len(w.columns) {
+			return fmt.Errorf("列 %s 的值 %q 无法以 %s 编码", w.columns[i], field, w.charset)
+		}
 	}
+	return fmt.Errorf("无法以 %s 编码", w.charset)
 }
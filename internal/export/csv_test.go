@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"dbm/internal/model"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestCSVExporter_Options(t *testing.T) {
+	rows := func() RowIterator {
+		return &typedRows{
+			SliceRows: NewSliceRows([]string{"id", "name", "price", "ok", "created"}, [][]any{
+				{int64(1), `say "hi"`, "12.50", true, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+				{float32(0.5), " lead", nil, int64(0), "2024-01-02 03:04:05"},
+			}),
+			types: []string{"int", "varchar", "decimal(10,2)", "boolean", "datetime"},
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts *model.CSVOptions
+		want string
+	}{
+		{
+			name: "defaults without BOM",
+			opts: &model.CSVOptions{Encoding: "GBK"},
+			want: "1,\"say \"\"hi\"\"\",12.50,1,2024-01-02 03:04:05\n0.5,\" lead\",NULL,0,2024-01-02 03:04:05\n",
+		},
+		{
+			name: "quote all with escape and crlf",
+			opts: &model.CSVOptions{Encoding: "GBK", Quote: "'", Escape: `\`, QuoteMode: "all", LineEnding: "crlf"},
+			want: "'1','say \"hi\"','12.50','1','2024-01-02 03:04:05'\r\n'0.5',' lead',NULL,'0','2024-01-02 03:04:05'\r\n",
+		},
+		{
+			name: "formatters and multi-character separator",
+			opts: &model.CSVOptions{
+				Encoding: "GBK", Separator: `\t|`, Escape: `\`, DecimalPoint: ",", TrueValue: "Y", FalseValue: "N",
+				DateFormat: "02/01/2006", NullValue: "\\N",
+			},
+			want: "1\t|\"say \\\"hi\\\"\"\t|12,50\t|Y\t|02/01/2024\n0,5\t|\" lead\"\t|\\N\t|N\t|02/01/2024\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := NewCSVExporter(tt.opts).Export(&out, rows()); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVExporter_Encoding(t *testing.T) {
+	rows := func() RowIterator {
+		return NewSliceRows([]string{"名称"}, [][]any{{"中文"}})
+	}
+	utf16, _ := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().String("名称\n中文\n")
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String("名称\n中文\n")
+
+	tests := []struct {
+		encoding string
+		want     string
+	}{
+		{"", "\xEF\xBB\xBF名称\n中文\n"},
+		{"gbk", gbk},
+		{"UTF-16", "\xFF\xFE" + utf16},
+		{"utf-16le", utf16},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		opts := &model.CSVOptions{IncludeHeader: true, Encoding: tt.encoding}
+		if err := NewCSVExporter(opts).Export(&out, rows()); err != nil {
+			t.Fatalf("%s: %v", tt.encoding, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %x, want %x", tt.encoding, out.String(), tt.want)
+		}
+	}
+
+	var out bytes.Buffer
+	err := NewCSVExporter(&model.CSVOptions{Encoding: "Latin-1"}).Export(&out, rows())
+	if err == nil || !strings.Contains(err.Error(), "第 1 行") || !strings.Contains(err.Error(), "名称") {
+		t.Errorf("expected unencodable value error, got %v", err)
+	}
+}
+
+func TestCSVExporter_InvalidOptions(t *testing.T) {
+	for _, opts := range []*model.CSVOptions{
+		{Encoding: "EBCDIC"},
+		{Quote: `""`},
+		{Escape: "ab"},
+		{Separator: `"`},
+		{QuoteMode: "never"},
+		{LineEnding: "cr"},
+	} {
+		var out bytes.Buffer
+		if err := NewCSVExporter(opts).Export(&out, NewSliceRows([]string{"a"}, nil)); err == nil {
+			t.Errorf("%+v: expected error", opts)
+		}
+		if out.Len() != 0 {
+			t.Errorf("%+v: wrote %q before failing", opts, out.String())
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package export
+
+import (
+	"dbm/internal/model"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlDialect 生成 SQL 语句时目标数据库的方言：标识符引用、字面量与自增列语法
+type sqlDialect model.DatabaseType
+
+// quote 引用标识符
+func (d sqlDialect) quote(ident string) string {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMySQL, model.DatabaseClickHouse:
+		return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+	case model.DatabaseMSSQL:
+		return "[" + strings.ReplaceAll(ident, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+// quoteList 引用多个标识符并以逗号分隔
+func (d sqlDialect) quoteList(idents []string) string {
+	quoted := make([]string, len(idents))
+	for i, ident := range idents {
+		quoted[i] = d.quote(ident)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// globalIndexNames 索引名在 schema 内唯一而不是在表内唯一
+func (d sqlDialect) globalIndexNames() bool {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMySQL, model.DatabaseMSSQL, model.DatabaseClickHouse:
+		return false
+	}
+	return true
+}
+
+// stringLiteral 字符串字面量
+// MySQL 与 ClickHouse 默认将反斜杠视为转义字符，SQL Server 的字符串加上 N 前缀以保留 Unicode 字符。
+func (d sqlDialect) stringLiteral(s string) string {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMySQL:
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", "''", "\x00", `\0`).Replace(s) + "'"
+	case model.DatabaseClickHouse:
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	case model.DatabaseMSSQL:
+		return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// binaryLiteral 二进制字面量，以十六进制表示
+func (d sqlDialect) binaryLiteral(b []byte) string {
+	h := hex.EncodeToString(b)
+	switch model.DatabaseType(d) {
+	case model.DatabasePostgreSQL, model.DatabaseKingBase:
+		return `'\x` + h + "'"
+	case model.DatabaseOracle:
+		return "HEXTORAW('" + h + "')"
+	case model.DatabaseMSSQL, model.DatabaseDM:
+		return "0x" + h
+	case model.DatabaseClickHouse:
+		return "unhex('" + h + "')"
+	}
+	return "X'" + h + "'"
+}
+
+// boolLiteral 布尔字面量，没有布尔类型的数据库使用 1 与 0
+func (d sqlDialect) boolLiteral(b bool) string {
+	switch model.DatabaseType(d) {
+	case model.DatabasePostgreSQL, model.DatabaseKingBase:
+		return strings.ToUpper(strconv.FormatBool(b))
+	case model.DatabaseClickHouse:
+		return strconv.FormatBool(b)
+	}
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// timeLiteral 时间字面量
+// PostgreSQL 带上时区偏移，写入 TIMESTAMP 列时忽略偏移，写入 TIMESTAMPTZ 列时换算为同一时刻；
+// SQL Server 的 DATETIME 最多 3 位小数，使用不受语言设置影响的 ISO 8601 格式。
+func (d sqlDialect) timeLiteral(t time.Time) string {
+	switch model.DatabaseType(d) {
+	case model.DatabasePostgreSQL, model.DatabaseKingBase:
+		return "'" + t.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+	case model.DatabaseOracle, model.DatabaseDM:
+		return "TIMESTAMP '" + t.Format("2006-01-02 15:04:05.999999999") + "'"
+	case model.DatabaseMSSQL:
+		return "'" + t.Format("2006-01-02T15:04:05.999") + "'"
+	}
+	return "'" + t.Format("2006-01-02 15:04:05.999999") + "'"
+}
+
+// floatLiteral 浮点数字面量，NaN 与无穷大只在支持的数据库中保留，其余为 NULL
+func (d sqlDialect) floatLiteral(f float64, bits int) string {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, bits)
+	}
+	switch model.DatabaseType(d) {
+	case model.DatabasePostgreSQL, model.DatabaseKingBase:
+		switch {
+		case math.IsNaN(f):
+			return "'NaN'"
+		case f > 0:
+			return "'Infinity'"
+		}
+		return "'-Infinity'"
+	case model.DatabaseClickHouse:
+		switch {
+		case math.IsNaN(f):
+			return "nan"
+		case f > 0:
+			return "inf"
+		}
+		return "-inf"
+	}
+	return "NULL"
+}
+
+// currentTimestamp 当前时间的默认值表达式，MySQL 中需要与列的小数位数一致
+func (d sqlDialect) currentTimestamp(columnType string) string {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMySQL:
+		if t := parseColumnType(columnType); numericParamsRegex.MatchString(t.params) {
+			return "CURRENT_TIMESTAMP(" + t.params + ")"
+		}
+	case model.DatabaseClickHouse:
+		return "now()"
+	}
+	return "CURRENT_TIMESTAMP"
+}
+
+// identityType 自增列的类型：SQLite 必须为 INTEGER，非整数类型改为 64 位整数
+func (d sqlDialect) identityType(typ string) string {
+	if model.DatabaseType(d) == model.DatabaseSQLite {
+		return "INTEGER"
+	}
+	t := parseColumnType(typ)
+	switch strings.TrimSuffix(t.base, "_UNSIGNED") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT2", "INT4", "INT8":
+		return typ
+	case "NUMBER", "DECIMAL", "NUMERIC":
+		// Oracle、DM 与 SQL Server 的自增列可以是小数位数为 0 的数值类型
+		if d.numericIdentity() && !strings.Contains(t.params, ",") {
+			return typ
+		}
+	}
+	if model.DatabaseType(d) == model.DatabaseOracle {
+		return "NUMBER(19)"
+	}
+	return "BIGINT"
+}
+
+// numericIdentity 自增列可以使用 NUMBER/DECIMAL 类型
+func (d sqlDialect) numericIdentity() bool {
+	switch model.DatabaseType(d) {
+	case model.DatabaseOracle, model.DatabaseDM, model.DatabaseMSSQL:
+		return true
+	}
+	return false
+}
+
+// identityClause 列定义中的自增语法，SQLite 的自增列在列定义中同时声明主键，ClickHouse 不支持自增列
+func (d sqlDialect) identityClause() string {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMySQL:
+		return " AUTO_INCREMENT"
+	case model.DatabasePostgreSQL, model.DatabaseKingBase, model.DatabaseOracle:
+		return " GENERATED BY DEFAULT AS IDENTITY"
+	case model.DatabaseMSSQL, model.DatabaseDM:
+		return " IDENTITY(1,1)"
+	case model.DatabaseSQLite:
+		return " PRIMARY KEY AUTOINCREMENT"
+	}
+	return ""
+}
+
+// identityInsert 写入自增列前后的语句
+// SQL Server 与 DM 需要开启 IDENTITY_INSERT 才能写入自增列；PostgreSQL 与 Oracle 写入后将序列推进到已有的最大值。
+func (d sqlDialect) identityInsert(table, column string) (before, after string) {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMSSQL, model.DatabaseDM:
+		return fmt.Sprintf("SET IDENTITY_INSERT %s ON;\n", table), fmt.Sprintf("SET IDENTITY_INSERT %s OFF;\n", table)
+	case model.DatabasePostgreSQL, model.DatabaseKingBase:
+		return "", fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), MAX(%s)) FROM %s;\n",
+			d.stringLiteral(table), d.stringLiteral(column), d.quote(column), table)
+	case model.DatabaseOracle:
+		return "", fmt.Sprintf("ALTER TABLE %s MODIFY (%s GENERATED BY DEFAULT AS IDENTITY (START WITH LIMIT VALUE));\n", table, d.quote(column))
+	}
+	return "", ""
+}
+
+// dropTable 删除表的语句，Oracle 没有 IF EXISTS，表不存在时忽略 ORA-00942
+func (d sqlDialect) dropTable(table string) string {
+	if model.DatabaseType(d) == model.DatabaseOracle {
+		return fmt.Sprintf("BEGIN\n  EXECUTE IMMEDIATE %s;\nEXCEPTION\n  WHEN OTHERS THEN\n    IF SQLCODE != -942 THEN\n      RAISE;\n    END IF;\nEND;\n/\n\n",
+			d.stringLiteral("DROP TABLE "+table))
+	}
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n\n", table)
+}
+
+// inlineComments 列注释写在列定义中，否则在建表后使用 COMMENT ON 等语句
+func (d sqlDialect) inlineComments() bool {
+	switch model.DatabaseType(d) {
+	case model.DatabaseMySQL, model.DatabaseClickHouse:
+		return true
+	}
+	return false
+}
+
+// columnComment 建表后设置列注释的语句，table 为引用后的表名，name 为原始表名；SQLite 不支持注释
+func (d sqlDialect) columnComment(table, name, column, comment string) string {
+	switch model.DatabaseType(d) {
+	case model.DatabasePostgreSQL, model.DatabaseKingBase, model.DatabaseOracle, model.DatabaseDM:
+		return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;\n", table, d.quote(column), d.stringLiteral(comment))
+	case model.DatabaseMSSQL:
+		schema, name := splitSchema(name)
+		return fmt.Sprintf("EXEC sp_addextendedproperty N'MS_Description', %s, N'SCHEMA', %s, N'TABLE', %s, N'COLUMN', %s;\n",
+			d.stringLiteral(comment), d.stringLiteral(schema), d.stringLiteral(name), d.stringLiteral(column))
+	}
+	return ""
+}
+
+// splitSchema 拆分 SQL Server 的 schema.table 形式的表名，未指定 schema 时为 dbo
+func splitSchema(table string) (string, string) {
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		return schema, name
+	}
+	return "dbo", table
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bytes"
+	"dbm/internal/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JSONExporter JSON 数组与 NDJSON 导出器
+// JSON 格式按查询或单表导出时为对象数组，多表导出时为以表名为键的对象；
+// NDJSON 格式每行一个对象，多表导出时每个对象带有表名字段。
+type JSONExporter struct {
+	writer io.Writer
+	opts   *model.JSONOptions
+	// lines 为 true 时输出 NDJSON
+	lines bool
+	// keyed 为 true 时 JSON 以表名为键，NDJSON 在每行加入 tableField 字段
+	keyed  bool
+	tables int
+}
+
+// tableField 多表 NDJSON 导出时记录表名的字段
+const tableField = "_table"
+
+// NewJSONExporter 创建 JSON 导出器，multiTable 为 true 时按表名区分各表的数据
+func NewJSONExporter(writer io.Writer, opts *model.JSONOptions, multiTable bool) *JSONExporter {
+	if opts == nil {
+		opts = &model.JSONOptions{}
+	}
+	return &JSONExporter{writer: writer, opts: opts, keyed: multiTable}
+}
+
+// NewNDJSONExporter 创建 NDJSON 导出器，multiTable 为 true 时每行记录所属的表名
+func NewNDJSONExporter(writer io.Writer, opts *model.JSONOptions, multiTable bool) *JSONExporter {
+	e := NewJSONExporter(writer, opts, multiTable)
+	e.lines = true
+	return e
+}
+
+// WriteTable 逐行写出一个表
+func (e *JSONExporter) WriteTable(name string, rows RowIterator) error {
+	if !e.lines {
+		if err := e.beginTable(name); err != nil {
+			return err
+		}
+	}
+	e.tables++
+
+	columns := rows.Columns()
+	kinds := columnKinds(rows)
+	rows = Limit(rows, e.opts.MaxRows)
+
+	var buf bytes.Buffer
+	count := 0
+	for rows.Next() {
+		count++
+		buf.Reset()
+		if !e.lines {
+			if count > 1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(e.indent(2))
+		}
+
+		buf.WriteByte('{')
+		first := true
+		if e.lines && e.keyed {
+			writeJSONField(&buf, tableField, name, e.opts.Pretty && !e.lines, "")
+			first = false
+		}
+		for i, v := range rows.Values() {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeJSONField(&buf, columns[i], jsonValue(v, kinds[i]), e.opts.Pretty && !e.lines, e.indent(3))
+		}
+		if e.opts.Pretty && !e.lines && len(columns) > 0 {
+			buf.WriteString(e.indent(2))
+		}
+		buf.WriteByte('}')
+		if e.lines {
+			buf.WriteByte('\n')
+		}
+
+		if _, err := e.writer.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if count%flushRows == 0 {
+			flush(e.writer)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !e.lines {
+		tail := "]"
+		if count > 0 {
+			tail = e.indent(1) + tail
+		}
+		_, err := io.WriteString(e.writer, tail)
+		return err
+	}
+	return nil
+}
+
+// beginTable 写出数组开头，多表导出时先写出表名键
+func (e *JSONExporter) beginTable(name string) error {
+	var prefix string
+	switch {
+	case !e.keyed && e.tables > 0:
+		return fmt.Errorf("JSON 数组格式只能导出一个结果集")
+	case e.keyed && e.tables == 0:
+		prefix = "{" + e.indent(1)
+	case e.keyed:
+		prefix = "," + e.indent(1)
+	}
+	if e.keyed {
+		key, _ := json.Marshal(name)
+		prefix += string(key) + ":"
+		if e.opts.Pretty {
+			prefix += " "
+		}
+	}
+	_, err := io.WriteString(e.writer, prefix+"[")
+	return err
+}
+
+// Close 写出 JSON 结尾，没有导出任何表时写出空数组或空对象
+func (e *JSONExporter) Close() error {
+	if e.lines {
+		return nil
+	}
+
+	var tail string
+	switch {
+	case e.keyed && e.tables == 0:
+		tail = "{}"
+	case e.keyed:
+		tail = e.indent(0) + "}"
+	case e.tables == 0:
+		tail = "[]"
+	}
+	_, err := io.WriteString(e.writer, tail+"\n")
+	return err
+}
+
+// indent 返回格式化输出时换行并缩进 level 层的前缀，多表导出时数组整体多缩进一层
+func (e *JSONExporter) indent(level int) string {
+	if !e.opts.Pretty || e.lines {
+		return ""
+	}
+	if !e.keyed && level > 0 {
+		level--
+	}
+	prefix := "\n"
+	for i := 0; i < level; i++ {
+		prefix += "  "
+	}
+	return prefix
+}
+
+// writeJSONField 写出一个键值对
+func writeJSONField(buf *bytes.Buffer, key string, value any, pretty bool, indent string) {
+	buf.WriteString(indent)
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	if pretty {
+		buf.WriteByte(' ')
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		// NaN、Inf 等无法表示为 JSON 的值
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
+
+// jsonValue 将值转换为 JSON 值：数值列写为数字，时间写为 RFC 3339 格式的字符串
+func jsonValue(v any, kind valueKind) any {
+	switch val := v.(type) {
+	case nil, bool:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return val
+	}
+
+	if kind == kindNumber {
+		if n, ok := numberText(v); ok {
+			return json.Number(n)
+		}
+	}
+	if kind == kindBool {
+		if b, ok := boolValue(v); ok {
+			return b
+		}
+	}
+	return textValue(v, "")
+}
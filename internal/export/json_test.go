@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"dbm/internal/model"
+)
+
+// typedRows 带列类型的内存行迭代器，模拟以文本返回数值的驱动
+type typedRows struct {
+	*SliceRows
+	types []string
+}
+
+func (r *typedRows) ColumnTypes() []string { return r.types }
+
+func newTypedRows() *typedRows {
+	return &typedRows{
+		SliceRows: NewSliceRows([]string{"id", "price", "name", "created", "active"}, [][]any{
+			{int64(1), "12345678901234567890.5", "a|b", "2024-01-02 03:04:05", "1"},
+			{int64(2), nil, "line1\nline2 <x>", nil, "0"},
+		}),
+		types: []string{"BIGINT", "DECIMAL", "VARCHAR", "DATETIME", "BOOLEAN"},
+	}
+}
+
+func TestJSONExporter_Array(t *testing.T) {
+	var out bytes.Buffer
+	e := NewJSONExporter(&out, &model.JSONOptions{Pretty: true}, false)
+	if err := e.WriteTable("", newTypedRows()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(&out)
+	dec.UseNumber()
+	var got []map[string]any
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("rows = %d, want 2", len(got))
+	}
+	// DECIMAL 以数字写出且不丢失精度
+	if n, ok := got[0]["price"].(json.Number); !ok || n.String() != "12345678901234567890.5" {
+		t.Errorf("price = %#v", got[0]["price"])
+	}
+	if got[0]["active"] != true || got[1]["active"] != false {
+		t.Errorf("active = %v, %v", got[0]["active"], got[1]["active"])
+	}
+	if got[1]["price"] != nil || got[1]["name"] != "line1\nline2 <x>" {
+		t.Errorf("row 2 = %v", got[1])
+	}
+}
+
+func TestJSONExporter_MultiTable(t *testing.T) {
+	var out bytes.Buffer
+	e := NewJSONExporter(&out, &model.JSONOptions{MaxRows: 1}, true)
+	for _, name := range []string{"users", "empty"} {
+		rows := RowIterator(newTypedRows())
+		if name == "empty" {
+			rows = NewSliceRows([]string{"id"}, nil)
+		}
+		if err := e.WriteTable(name, rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string][]map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got["users"]) != 1 || got["empty"] == nil || len(got["empty"]) != 0 {
+		t.Errorf("tables = %v", got)
+	}
+}
+
+func TestNDJSONExporter(t *testing.T) {
+	var out bytes.Buffer
+	e := NewNDJSONExporter(&out, nil, true)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := e.WriteTable("t", NewSliceRows([]string{"id", "at"}, [][]any{{1, created}, {2, nil}})); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	want := []string{
+		`{"_table":"t","id":1,"at":"2024-01-02T03:04:05Z"}`,
+		`{"_table":"t","id":2,"at":null}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), strings.Join(want, "\n"))
+	}
+}
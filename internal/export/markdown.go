@@ -0,0 +1,191 @@
+package export
+
+import (
+	"bytes"
+	"dbm/internal/model"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// MarkdownExporter Markdown 表格导出器
+// 多表导出时每个表前写出以表名为标题的二级标题。
+type MarkdownExporter struct {
+	writer io.Writer
+	opts   *model.MarkdownOptions
+	tables int
+}
+
+// NewMarkdownExporter 创建 Markdown 表格导出器
+func NewMarkdownExporter(writer io.Writer, opts *model.MarkdownOptions) *MarkdownExporter {
+	if opts == nil {
+		opts = &model.MarkdownOptions{}
+	}
+	if opts.NullValue == "" {
+		opts.NullValue = "NULL"
+	}
+	return &MarkdownExporter{writer: writer, opts: opts}
+}
+
+// markdownEscaper 转义单元格中会破坏表格结构的字符
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// WriteTable 逐行写出一个表
+func (e *MarkdownExporter) WriteTable(name string, rows RowIterator) error {
+	var buf bytes.Buffer
+	if e.tables > 0 {
+		buf.WriteByte('\n')
+	}
+	if name != "" {
+		buf.WriteString("## " + markdownEscaper.Replace(name) + "\n\n")
+	}
+	e.tables++
+
+	// 表头与分隔行
+	columns := rows.Columns()
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = markdownEscaper.Replace(col)
+	}
+	writeMarkdownRow(&buf, cells)
+	for i := range cells {
+		cells[i] = "---"
+	}
+	writeMarkdownRow(&buf, cells)
+	if _, err := e.writer.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	rows = Limit(rows, e.opts.MaxRows)
+	for count := 1; rows.Next(); count++ {
+		buf.Reset()
+		for i, v := range rows.Values() {
+			cells[i] = markdownEscaper.Replace(textValue(v, e.opts.NullValue))
+		}
+		writeMarkdownRow(&buf, cells)
+		if _, err := e.writer.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if count%flushRows == 0 {
+			flush(e.writer)
+		}
+	}
+	return rows.Err()
+}
+
+// Close Markdown 没有文件结尾
+func (e *MarkdownExporter) Close() error { return nil }
+
+// writeMarkdownRow 写出一行表格
+func writeMarkdownRow(buf *bytes.Buffer, cells []string) {
+	buf.WriteByte('|')
+	for _, cell := range cells {
+		buf.WriteString(" " + cell + " |")
+	}
+	buf.WriteByte('\n')
+}
+
+// HTMLExporter HTML 表格导出器
+// 输出完整的 HTML 页面，多表导出时每个表前写出以表名为标题的二级标题。
+type HTMLExporter struct {
+	writer  io.Writer
+	opts    *model.HTMLOptions
+	started bool
+}
+
+// NewHTMLExporter 创建 HTML 表格导出器
+func NewHTMLExporter(writer io.Writer, opts *model.HTMLOptions) *HTMLExporter {
+	if opts == nil {
+		opts = &model.HTMLOptions{}
+	}
+	if opts.NullValue == "" {
+		opts.NullValue = "NULL"
+	}
+	if opts.Title == "" {
+		opts.Title = "Export"
+	}
+	return &HTMLExporter{writer: writer, opts: opts}
+}
+
+// htmlHead 页面开头，%s 为标题
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; white-space: pre-wrap; }
+th { background: #f5f5f5; }
+td.null { color: #999; font-style: italic; }
+</style>
+</head>
+<body>
+`
+
+// begin 首次写出时写出页面开头
+func (e *HTMLExporter) begin() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	_, err := fmt.Fprintf(e.writer, htmlHead, html.EscapeString(e.opts.Title))
+	return err
+}
+
+// WriteTable 逐行写出一个表
+func (e *HTMLExporter) WriteTable(name string, rows RowIterator) error {
+	if err := e.begin(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if name != "" {
+		buf.WriteString("<h2>" + html.EscapeString(name) + "</h2>\n")
+	}
+	buf.WriteString("<table>\n<thead>\n<tr>")
+	for _, col := range rows.Columns() {
+		buf.WriteString("<th>" + html.EscapeString(col) + "</th>")
+	}
+	buf.WriteString("</tr>\n</thead>\n<tbody>\n")
+	if _, err := e.writer.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	rows = Limit(rows, e.opts.MaxRows)
+	for count := 1; rows.Next(); count++ {
+		buf.Reset()
+		buf.WriteString("<tr>")
+		for _, v := range rows.Values() {
+			if v == nil {
+				buf.WriteString(`<td class="null">` + html.EscapeString(e.opts.NullValue) + "</td>")
+				continue
+			}
+			buf.WriteString("<td>" + html.EscapeString(textValue(v, "")) + "</td>")
+		}
+		buf.WriteString("</tr>\n")
+		if _, err := e.writer.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if count%flushRows == 0 {
+			flush(e.writer)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(e.writer, "</tbody>\n</table>\n")
+	return err
+}
+
+// Close 写出页面结尾
+func (e *HTMLExporter) Close() error {
+	if err := e.begin(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.writer, "</body>\n</html>\n")
+	return err
+}
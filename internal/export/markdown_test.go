@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"dbm/internal/model"
+)
+
+func TestMarkdownExporter(t *testing.T) {
+	var out bytes.Buffer
+	e := NewMarkdownExporter(&out, &model.MarkdownOptions{NullValue: "-"})
+	if err := e.WriteTable("t1", newTypedRows()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WriteTable("t2", NewSliceRows([]string{"x"}, [][]any{{1.5}})); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "## t1\n\n" +
+		"| id | price | name | created | active |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| 1 | 12345678901234567890.5 | a\\|b | 2024-01-02 03:04:05 | 1 |\n" +
+		"| 2 | - | line1<br>line2 <x> | - | 0 |\n" +
+		"\n## t2\n\n" +
+		"| x |\n| --- |\n| 1.5 |\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestHTMLExporter(t *testing.T) {
+	var out bytes.Buffer
+	e := NewHTMLExporter(&out, &model.HTMLOptions{Title: "a & b"})
+	if err := e.WriteTable("", newTypedRows()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	html := out.String()
+	for _, want := range []string{
+		"<title>a &amp; b</title>",
+		"<th>price</th>",
+		"<td>line1\nline2 &lt;x&gt;</td>",
+		`<td class="null">NULL</td>`,
+		"</tbody>\n</table>\n</body>\n</html>\n",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("output missing %q:\n%s", want, html)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package export
+
+import (
+	"dbm/internal/model"
+	"dbm/internal/parquet"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParquetTarget 类型映射配置中 Parquet 的目标名称，规则键为 <源数据库>_to_parquet
+const ParquetTarget model.DatabaseType = "parquet"
+
+// ParquetExporter Parquet 导出器
+// 列类型优先取 SetSchema 提供的表结构，其次取结果集返回的数据库类型；
+// 类型映射配置中有 <源数据库>_to_parquet 规则时按规则的目标类型，否则按源数据库类型推断 Parquet 逻辑类型。
+type ParquetExporter struct {
+	writer   io.Writer
+	opts     *model.ParquetOptions
+	sourceDB model.DatabaseType
+	mapper   *TypeMapper
+	schema   []model.ColumnInfo
+	pw       *parquet.Writer
+	tables   int
+}
+
+// NewParquetExporter 创建 Parquet 导出器，mapper 为空时只按内置规则推断类型
+func NewParquetExporter(writer io.Writer, opts *model.ParquetOptions, sourceDB model.DatabaseType, mapper *TypeMapper) *ParquetExporter {
+	if opts == nil {
+		opts = &model.ParquetOptions{}
+	}
+	return &ParquetExporter{writer: writer, opts: opts, sourceDB: sourceDB, mapper: mapper}
+}
+
+// SetSchema 设置导出表的列信息，用于确定 DECIMAL 的精度等结果集不提供的类型细节
+func (e *ParquetExporter) SetSchema(columns []model.ColumnInfo) {
+	e.schema = columns
+}
+
+// WriteTable 写出一个结果集，Parquet 文件只能包含一个表
+func (e *ParquetExporter) WriteTable(name string, rows RowIterator) error {
+	if e.tables > 0 {
+		return fmt.Errorf("Parquet 文件只能导出一个表")
+	}
+	e.tables++
+
+	codec, err := parquet.ParseCodec(e.opts.Compression)
+	if err != nil {
+		return err
+	}
+	pw, err := parquet.NewWriter(e.writer, e.Columns(rows), parquet.WriterOptions{
+		Codec:        codec,
+		RowGroupSize: e.opts.RowGroupSize,
+	})
+	if err != nil {
+		return err
+	}
+	e.pw = pw
+
+	rows = Limit(rows, e.opts.MaxRows)
+	count := 0
+	for rows.Next() {
+		count++
+		if err := pw.Write(rows.Values()); err != nil {
+			return fmt.Errorf("第 %d 行: %w", count, err)
+		}
+	}
+	return rows.Err()
+}
+
+// Close 写出文件尾
+func (e *ParquetExporter) Close() error {
+	if e.pw == nil {
+		return nil
+	}
+	return e.pw.Close()
+}
+
+// Columns 确定结果集各列的 Parquet 类型
+// 所有列都可为空，避免表结构声明非空但数据中存在空值时导出中断；重名的列加上序号后缀。
+func (e *ParquetExporter) Columns(rows RowIterator) []parquet.Column {
+	names := rows.Columns()
+	infos := make([]model.ColumnInfo, len(names))
+	var types []string
+	if typer, ok := rows.(ColumnTyper); ok {
+		types = typer.ColumnTypes()
+	}
+	for i, name := range names {
+		infos[i].Name = name
+		if i < len(types) {
+			infos[i].Type = types[i]
+		}
+		for _, col := range e.schema {
+			if strings.EqualFold(col.Name, name) && col.Type != "" {
+				infos[i].Type = col.Type
+				break
+			}
+		}
+	}
+
+	var mapped map[string]string
+	if e.mapper != nil {
+		if result, err := e.mapper.MapTypes(e.sourceDB, ParquetTarget, infos); err == nil {
+			mapped = result.Mapped
+		}
+	}
+
+	columns := make([]parquet.Column, len(infos))
+	seen := make(map[string]int)
+	for i, info := range infos {
+		var col parquet.Column
+		if target, ok := mapped[info.Type]; ok && target != "" && target != info.Type {
+			col = parquetColumn(ParquetTarget, target)
+		} else {
+			col = parquetColumn(e.sourceDB, info.Type)
+		}
+
+		col.Name = info.Name
+		if col.Name == "" {
+			col.Name = "column_" + strconv.Itoa(i+1)
+		}
+		key := strings.ToLower(col.Name)
+		if n := seen[key]; n > 0 {
+			col.Name += "_" + strconv.Itoa(n+1)
+		}
+		seen[key]++
+		columns[i] = col
+	}
+	return columns
+}
+
+// parquetColumn 将数据库类型转换为 Parquet 列类型，无法识别的类型写为 STRING
+// sourceDB 为 ParquetTarget 时 typ 为类型映射配置中的 Parquet 类型名称，如 INT32、TIMESTAMP_MICROS、DECIMAL(18,2)。
+func parquetColumn(sourceDB model.DatabaseType, typ string) parquet.Column {
+	t := strings.ToUpper(strings.TrimSpace(typ))
+	for _, wrapper := range []string{"NULLABLE(", "LOWCARDINALITY("} {
+		if strings.HasPrefix(t, wrapper) {
+			t = strings.TrimSuffix(strings.TrimPrefix(t, wrapper), ")")
+		}
+	}
+	unsigned := strings.Contains(t, "UNSIGNED")
+	zoned := strings.Contains(t, "WITH TIME ZONE") || strings.Contains(t, "WITH LOCAL TIME ZONE")
+	t = strings.TrimSpace(strings.ReplaceAll(t, "UNSIGNED", ""))
+
+	base, args := t, []int(nil)
+	if i := strings.Index(t, "("); i > 0 {
+		base = strings.TrimSpace(t[:i])
+		if j := strings.Index(t[i:], ")"); j > 0 {
+			for _, a := range strings.Split(t[i+1:i+j], ",") {
+				if n, err := strconv.Atoi(strings.TrimSpace(a)); err == nil {
+					args = append(args, n)
+				}
+			}
+		}
+	}
+	arg := func(i, def int) int {
+		if i < len(args) {
+			return args[i]
+		}
+		return def
+	}
+
+	stringCol := parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalString}
+	integer := func(bits int, signed bool) parquet.Column {
+		physical := parquet.Int32
+		if bits == 64 {
+			physical = parquet.Int64
+		}
+		return parquet.Column{Type: physical, Logical: parquet.LogicalInteger, BitWidth: bits, Signed: signed}
+	}
+	timestamp := func(unit parquet.TimeUnit, utc bool) parquet.Column {
+		return parquet.Column{Type: parquet.Int64, Logical: parquet.LogicalTimestamp, Unit: unit, UTC: utc}
+	}
+
+	// ClickHouse 与 Parquet 的整数、浮点类型名称带有位数
+	if sourceDB == model.DatabaseClickHouse || sourceDB == ParquetTarget {
+		for _, prefix := range []string{"UINT", "INT"} {
+			if bits, err := strconv.Atoi(strings.TrimPrefix(base, prefix)); err == nil && strings.HasPrefix(base, prefix) {
+				switch bits {
+				case 8, 16, 32, 64:
+					return integer(bits, prefix == "INT")
+				default:
+					// Int128、Int256 超出 INT64 范围
+					return stringCol
+				}
+			}
+		}
+		switch base {
+		case "FLOAT32", "FLOAT":
+			return parquet.Column{Type: parquet.Float}
+		case "FLOAT64":
+			return parquet.Column{Type: parquet.Double}
+		case "DECIMAL32", "DECIMAL64", "DECIMAL128", "DECIMAL256":
+			precision := map[string]int{"DECIMAL32": 9, "DECIMAL64": 18, "DECIMAL128": 38, "DECIMAL256": 76}[base]
+			return decimalColumn(precision, arg(0, 0))
+		case "TIMESTAMP_MILLIS":
+			return timestamp(parquet.Millis, true)
+		case "TIMESTAMP_MICROS":
+			return timestamp(parquet.Micros, true)
+		case "TIMESTAMP_NANOS":
+			return timestamp(parquet.Nanos, true)
+		case "STRING", "UTF8":
+			return stringCol
+		case "BYTE_ARRAY":
+			return parquet.Column{Type: parquet.ByteArray}
+		case "DATETIME", "DATETIME64":
+			return timestamp(parquet.Micros, true)
+		case "ENUM8", "ENUM16":
+			return parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalEnum}
+		}
+	}
+
+	switch base {
+	case "BOOL", "BOOLEAN":
+		return parquet.Column{Type: parquet.Boolean}
+	case "BIT":
+		// MySQL 的 BIT 以二进制返回
+		if sourceDB == model.DatabaseMySQL {
+			return parquet.Column{Type: parquet.ByteArray}
+		}
+		return parquet.Column{Type: parquet.Boolean}
+	case "TINYINT":
+		// SQL Server 的 TINYINT 无符号
+		return integer(8, !unsigned && sourceDB != model.DatabaseMSSQL)
+	case "SMALLINT", "INT2", "SMALLSERIAL", "YEAR":
+		return integer(16, !unsigned)
+	case "MEDIUMINT", "INT", "INT4", "SERIAL", "INTEGER":
+		switch {
+		case sourceDB == model.DatabaseSQLite:
+			return integer(64, true)
+		case sourceDB == model.DatabaseOracle && base == "INTEGER":
+			return decimalColumn(38, 0)
+		}
+		return integer(32, !unsigned)
+	case "BIGINT", "INT8", "BIGSERIAL", "SERIAL8":
+		return integer(64, !unsigned)
+	case "REAL":
+		if sourceDB == model.DatabasePostgreSQL || sourceDB == model.DatabaseKingBase || sourceDB == model.DatabaseMSSQL {
+			return parquet.Column{Type: parquet.Float}
+		}
+		return parquet.Column{Type: parquet.Double}
+	case "FLOAT4", "BINARY_FLOAT":
+		return parquet.Column{Type: parquet.Float}
+	case "FLOAT":
+		if sourceDB == model.DatabaseMySQL && arg(0, 0) <= 24 {
+			return parquet.Column{Type: parquet.Float}
+		}
+		return parquet.Column{Type: parquet.Double}
+	case "FLOAT8", "DOUBLE", "DOUBLE PRECISION", "BINARY_DOUBLE":
+		return parquet.Column{Type: parquet.Double}
+	case "DECIMAL", "NUMERIC", "NUMBER", "DEC":
+		// 未指定精度的 NUMERIC、NUMBER 可以存放任意精度的数值，写为文本以免丢失精度
+		if len(args) == 0 {
+			return stringCol
+		}
+		return decimalColumn(arg(0, 0), arg(1, 0))
+	case "MONEY":
+		// PostgreSQL 的 MONEY 以带货币符号的文本返回
+		if sourceDB == model.DatabasePostgreSQL || sourceDB == model.DatabaseKingBase {
+			return stringCol
+		}
+		return decimalColumn(19, 4)
+	case "SMALLMONEY":
+		return decimalColumn(10, 4)
+	case "DATE", "DATE32":
+		// Oracle 的 DATE 包含时间
+		if sourceDB == model.DatabaseOracle {
+			return timestamp(parquet.Micros, false)
+		}
+		return parquet.Column{Type: parquet.Int32, Logical: parquet.LogicalDate}
+	case "DATETIME", "DATETIME2", "SMALLDATETIME", "TIMESTAMP":
+		return timestamp(parquet.Micros, zoned)
+	case "TIMESTAMPTZ", "DATETIMEOFFSET":
+		return timestamp(parquet.Micros, true)
+	case "UUID", "UNIQUEIDENTIFIER":
+		return parquet.Column{Type: parquet.FixedLenByteArray, Length: 16, Logical: parquet.LogicalUUID}
+	case "JSON", "JSONB":
+		return parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalJSON}
+	case "ENUM":
+		return parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalEnum}
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA", "BINARY", "VARBINARY", "RAW", "LONG RAW", "IMAGE":
+		return parquet.Column{Type: parquet.ByteArray}
+	}
+
+	// TIMESTAMP(6) WITH TIME ZONE 等带参数的时间戳
+	if strings.HasPrefix(base, "TIMESTAMP") {
+		return timestamp(parquet.Micros, zoned)
+	}
+	return stringCol
+}
+
+// decimalColumn 按精度选择 DECIMAL 的物理类型，超过 38 位的写为文本
+func decimalColumn(precision, scale int) parquet.Column {
+	if scale < 0 || precision <= 0 || precision > 38 {
+		return parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalString}
+	}
+	// Oracle 允许小数位数大于精度，如 NUMBER(2,5)
+	precision = max(precision, scale)
+
+	col := parquet.Column{Logical: parquet.LogicalDecimal, Precision: precision, Scale: scale}
+	switch {
+	case precision <= 9:
+		col.Type = parquet.Int32
+	case precision <= 18:
+		col.Type = parquet.Int64
+	default:
+		col.Type, col.Length = parquet.FixedLenByteArray, parquet.DecimalLength(precision)
+	}
+	return col
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"dbm/internal/model"
+	"dbm/internal/parquet"
+)
+
+func TestParquetExporter(t *testing.T) {
+	var out bytes.Buffer
+	e := NewParquetExporter(&out, &model.ParquetOptions{Compression: "zstd", RowGroupSize: 1}, model.DatabaseMySQL, nil)
+	e.SetSchema([]model.ColumnInfo{{Name: "price", Type: "decimal(22,1)"}})
+	if err := e.WriteTable("orders", newTypedRows()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WriteTable("other", newTypedRows()); err == nil {
+		t.Error("expected error for second table")
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := parquet.Open(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, col := range r.Columns() {
+		types = append(types, col.Name+" "+col.String())
+	}
+	want := []string{
+		"id INT64 INT(64)",
+		"price FIXED_LEN_BYTE_ARRAY(10) DECIMAL(22,1)",
+		"name BYTE_ARRAY STRING",
+		"created INT64 TIMESTAMP(MICROS)",
+		"active BOOLEAN",
+	}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("columns = %v, want %v", types, want)
+	}
+
+	var rows [][]any
+	it := r.Rows()
+	for it.Next() {
+		rows = append(rows, append([]any(nil), it.Values()...))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	wantRows := [][]any{
+		{int64(1), "12345678901234567890.5", "a|b", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true},
+		{int64(2), nil, "line1\nline2 <x>", nil, false},
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("rows = %v, want %v", rows, wantRows)
+	}
+}
+
+func TestParquetExporter_TypeMapping(t *testing.T) {
+	mapper := &TypeMapper{mappings: map[string]map[string]*TypeRule{
+		"mysql_to_parquet": {"YEAR": {TargetType: "INT32"}, "DATETIME": {TargetType: "TIMESTAMP_MILLIS"}},
+	}}
+	rows := &typedRows{
+		SliceRows: NewSliceRows([]string{"y", "t", "id", "ID"}, nil),
+		types:     []string{"year", "datetime", "int", "int"},
+	}
+	e := NewParquetExporter(&bytes.Buffer{}, nil, model.DatabaseMySQL, mapper)
+
+	var got []string
+	for _, col := range e.Columns(rows) {
+		got = append(got, col.Name+" "+col.String())
+	}
+	want := []string{"y INT32 INT(32)", "t INT64 TIMESTAMP(MILLIS)", "id INT32 INT(32)", "ID_2 INT32 INT(32)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+}
+
+func TestParquetColumn(t *testing.T) {
+	tests := []struct {
+		db   model.DatabaseType
+		typ  string
+		want string
+	}{
+		{model.DatabaseMySQL, "int(10) unsigned", "INT32 UINT(32)"},
+		{model.DatabaseMySQL, "tinyint(1)", "INT32 INT(8)"},
+		{model.DatabaseMySQL, "float", "FLOAT"},
+		{model.DatabaseMySQL, "decimal(10,2)", "INT64 DECIMAL(10,2)"},
+		{model.DatabaseMySQL, "decimal(65,2)", "BYTE_ARRAY STRING"},
+		{model.DatabaseMySQL, "bit(1)", "BYTE_ARRAY"},
+		{model.DatabaseMySQL, "json", "BYTE_ARRAY JSON"},
+		{model.DatabaseMySQL, "enum('a','b')", "BYTE_ARRAY ENUM"},
+		{model.DatabasePostgreSQL, "numeric", "BYTE_ARRAY STRING"},
+		{model.DatabasePostgreSQL, "numeric(5,2)", "INT32 DECIMAL(5,2)"},
+		{model.DatabasePostgreSQL, "INT8", "INT64 INT(64)"},
+		{model.DatabasePostgreSQL, "real", "FLOAT"},
+		{model.DatabasePostgreSQL, "float", "DOUBLE"},
+		{model.DatabasePostgreSQL, "timestamp without time zone", "INT64 TIMESTAMP(MICROS)"},
+		{model.DatabasePostgreSQL, "timestamp(3) with time zone", "INT64 TIMESTAMP(MICROS)"},
+		{model.DatabasePostgreSQL, "uuid", "FIXED_LEN_BYTE_ARRAY(16) UUID"},
+		{model.DatabasePostgreSQL, "bytea", "BYTE_ARRAY"},
+		{model.DatabasePostgreSQL, "money", "BYTE_ARRAY STRING"},
+		{model.DatabaseMSSQL, "tinyint", "INT32 UINT(8)"},
+		{model.DatabaseMSSQL, "money", "FIXED_LEN_BYTE_ARRAY(9) DECIMAL(19,4)"},
+		{model.DatabaseMSSQL, "bit", "BOOLEAN"},
+		{model.DatabaseMSSQL, "uniqueidentifier", "FIXED_LEN_BYTE_ARRAY(16) UUID"},
+		{model.DatabaseOracle, "NUMBER(38,0)", "FIXED_LEN_BYTE_ARRAY(16) DECIMAL(38,0)"},
+		{model.DatabaseOracle, "DATE", "INT64 TIMESTAMP(MICROS)"},
+		{model.DatabaseOracle, "BINARY_FLOAT", "FLOAT"},
+		{model.DatabaseSQLite, "INTEGER", "INT64 INT(64)"},
+		{model.DatabaseClickHouse, "Nullable(UInt16)", "INT32 UINT(16)"},
+		{model.DatabaseClickHouse, "Int128", "BYTE_ARRAY STRING"},
+		{model.DatabaseClickHouse, "Float32", "FLOAT"},
+		{model.DatabaseClickHouse, "Decimal64(4)", "INT64 DECIMAL(18,4)"},
+		{model.DatabaseClickHouse, "DateTime64(3, 'Asia/Shanghai')", "INT64 TIMESTAMP(MICROS)"},
+		{model.DatabaseClickHouse, "LowCardinality(String)", "BYTE_ARRAY STRING"},
+		{model.DatabaseMySQL, "date", "INT32 DATE"},
+		{model.DatabaseMySQL, "time", "BYTE_ARRAY STRING"},
+		{model.DatabaseMongoDB, "", "BYTE_ARRAY STRING"},
+	}
+	for _, tt := range tests {
+		if got := parquetColumn(tt.db, tt.typ).String(); got != tt.want {
+			t.Errorf("parquetColumn(%s, %q) = %s, want %s", tt.db, tt.typ, got, tt.want)
+		}
+	}
+
+	if col := parquetColumn(model.DatabasePostgreSQL, "timestamptz"); !col.UTC {
+		t.Error("timestamptz must be adjusted to UTC")
+	}
+	if col := parquetColumn(model.DatabaseMySQL, "datetime"); col.UTC {
+		t.Error("datetime must not be adjusted to UTC")
+	}
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// flushRows 每写出多少行刷新一次输出，使 HTTP 响应持续下发而不是积压在缓冲区中
+const flushRows = 1000
+
+// RowIterator 导出数据的行迭代器
+// 导出器边读取边写出，不需要在内存中保留整个结果集。
+type RowIterator interface {
+	// Columns 返回列名
+	Columns() []string
+	// Next 前进到下一行，没有更多数据或出错时返回 false
+	Next() bool
+	// Values 返回当前行按 Columns 顺序排列的值，下一次调用 Next 后失效
+	Values() []any
+	// Err 返回迭代过程中的错误
+	Err() error
+}
+
+// ColumnTyper 能够提供列数据库类型的行迭代器
+// JSON、XLSX 等带类型的格式据此将以文本返回的数值、日期写为对应类型。
+type ColumnTyper interface {
+	// ColumnTypes 返回按 Columns 顺序排列的数据库类型名称，未知时为空字符串
+	ColumnTypes() []string
+}
+
+// valueKind 导出时单元格的值类别
+type valueKind int
+
+const (
+	kindText valueKind = iota
+	kindNumber
+	kindDate
+	kindBool
+	kindBinary
+)
+
+// columnKinds 根据数据库类型确定各列的值类别，迭代器不提供类型时全部为 kindText
+func columnKinds(rows RowIterator) []valueKind {
+	kinds := make([]valueKind, len(rows.Columns()))
+	typer, ok := rows.(ColumnTyper)
+	if !ok {
+		return kinds
+	}
+	for i, typ := range typer.ColumnTypes() {
+		if i < len(kinds) {
+			kinds[i] = typeKind(typ)
+		}
+	}
+	return kinds
+}
+
+// typeKind 识别数据库类型名称的值类别，兼容 ClickHouse 的 Nullable(...) 与 LowCardinality(...)
+func typeKind(typ string) valueKind {
+	t := strings.ToUpper(typ)
+	for _, wrapper := range []string{"NULLABLE(", "LOWCARDINALITY("} {
+		if strings.HasPrefix(t, wrapper) {
+			t = strings.TrimSuffix(strings.TrimPrefix(t, wrapper), ")")
+		}
+	}
+	t = strings.TrimPrefix(t, "UNSIGNED ")
+	if i := strings.IndexAny(t, "( "); i > 0 {
+		t = t[:i]
+	}
+	// INT4、UINT64、FLOAT8 等带位数的类型
+	base := strings.TrimRight(t, "0123456789")
+
+	switch {
+	case t == "BOOL" || t == "BOOLEAN":
+		return kindBool
+	case t == "DATE" || t == "DATE32" || strings.HasPrefix(t, "DATETIME") || strings.HasPrefix(t, "TIMESTAMP") || t == "SMALLDATETIME":
+		return kindDate
+	case t == "BLOB" || t == "TINYBLOB" || t == "MEDIUMBLOB" || t == "LONGBLOB" || t == "BINARY" || t == "VARBINARY" ||
+		t == "BYTEA" || t == "RAW" || t == "IMAGE":
+		return kindBinary
+	case strings.HasSuffix(base, "INT") && !strings.HasSuffix(base, "POINT"), base == "INTEGER", base == "FLOAT", base == "DECIMAL",
+		base == "NUMERIC", base == "NUMBER", base == "REAL", base == "DOUBLE", base == "MONEY", base == "SMALLMONEY",
+		base == "BINARY_FLOAT", base == "BINARY_DOUBLE":
+		return kindNumber
+	}
+	return kindText
+}
+
+// SliceRows 基于内存数据的行迭代器
+type SliceRows struct {
+	columns []string
+	rows    [][]any
+	current int
+}
+
+// NewSliceRows 创建基于内存数据的行迭代器
+func NewSliceRows(columns []string, rows [][]any) *SliceRows {
+	return &SliceRows{columns: columns, rows: rows, current: -1}
+}
+
+func (r *SliceRows) Columns() []string { return r.columns }
+
+func (r *SliceRows) Next() bool {
+	if r.current+1 >= len(r.rows) {
+		return false
+	}
+	r.current++
+	return true
+}
+
+func (r *SliceRows) Values() []any { return r.rows[r.current] }
+
+func (r *SliceRows) Err() error { return nil }
+
+// limitRows 最多返回 max 行的迭代器
+type limitRows struct {
+	RowIterator
+	max   int
+	count int
+}
+
+// Limit 限制迭代器返回的行数，max <= 0 时不限制
+// 达到上限后不再读取底层结果集，调用方关闭结果集即可结束查询。
+func Limit(rows RowIterator, max int) RowIterator {
+	if max <= 0 {
+		return rows
+	}
+	return &limitRows{RowIterator: rows, max: max}
+}
+
+func (r *limitRows) Next() bool {
+	if r.count >= r.max {
+		return false
+	}
+	if !r.RowIterator.Next() {
+		return false
+	}
+	r.count++
+	return true
+}
+
+// rowCounterKey 行计数器在 context 中的键
+type rowCounterKey struct{}
+
+// WithRowCounter 返回携带行计数器的 context
+// 适配器以该 context 流式读取数据时，每读取一行计数器加一，后台导出任务据此报告进度。
+func WithRowCounter(ctx context.Context, counter *atomic.Int64) context.Context {
+	return context.WithValue(ctx, rowCounterKey{}, counter)
+}
+
+// RowCount 返回 context 中行计数器的当前值，没有计数器时返回 0
+func RowCount(ctx context.Context) int64 {
+	if counter, ok := ctx.Value(rowCounterKey{}).(*atomic.Int64); ok {
+		return counter.Load()
+	}
+	return 0
+}
+
+// CountRows 使用 context 中的行计数器统计迭代器读取的行数，没有计数器时原样返回
+func CountRows(ctx context.Context, rows RowIterator) RowIterator {
+	counter, ok := ctx.Value(rowCounterKey{}).(*atomic.Int64)
+	if !ok {
+		return rows
+	}
+	return &countRows{RowIterator: rows, counter: counter}
+}
+
+// countRows 统计读取行数的迭代器
+type countRows struct {
+	RowIterator
+	counter *atomic.Int64
+}
+
+func (r *countRows) Next() bool {
+	if !r.RowIterator.Next() {
+		return false
+	}
+	r.counter.Add(1)
+	return true
+}
+
+// ColumnTypes 返回底层迭代器的列类型，使带类型的格式不受包装影响
+func (r *countRows) ColumnTypes() []string {
+	if typer, ok := r.RowIterator.(ColumnTyper); ok {
+		return typer.ColumnTypes()
+	}
+	return nil
+}
+
+// flush 刷新支持 Flush 的输出（如 http.ResponseWriter）
+func flush(w io.Writer) {
+	if f, ok := w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"dbm/internal/model"
+)
+
+// countingRows 生成 n 行数据并记录被读取的行数
+type countingRows struct {
+	n    int
+	read int
+	row  []any
+}
+
+func (r *countingRows) Columns() []string { return []string{"id", "name"} }
+
+func (r *countingRows) Next() bool {
+	if r.read >= r.n {
+		return false
+	}
+	r.read++
+	r.row = []any{r.read, "it's"}
+	return true
+}
+
+func (r *countingRows) Values() []any { return r.row }
+
+func (r *countingRows) Err() error { return nil }
+
+// flushBuffer 记录 Flush 调用次数的输出
+type flushBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushBuffer) Flush() { b.flushes++ }
+
+func TestCSVExporter_StreamsWithLimit(t *testing.T) {
+	rows := &countingRows{n: 5000}
+	var out flushBuffer
+
+	exporter := NewCSVExporter(&model.CSVOptions{IncludeHeader: true, Encoding: "GBK", MaxRows: 2500})
+	if err := exporter.Export(&out, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows.read != 2500 {
+		t.Errorf("read %d rows, want 2500", rows.read)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 2501 {
+		t.Errorf("wrote %d lines, want 2501", lines)
+	}
+	if out.flushes != 2 {
+		t.Errorf("flushed %d times, want 2", out.flushes)
+	}
+}
+
+func TestSQLExporter_ExportData(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *model.SQLOptions
+		want string
+	}{
+		{
+			name: "single",
+			opts: &model.SQLOptions{MaxRows: 2},
+			want: "INSERT INTO `t` (`id`, `name`) VALUES (1, 'it''s');\nINSERT INTO `t` (`id`, `name`) VALUES (2, 'it''s');\n",
+		},
+		{
+			name: "batch with partial last batch",
+			opts: &model.SQLOptions{BatchInsert: true, BatchSize: 2},
+			want: "INSERT INTO `t` (`id`, `name`) VALUES\n(1, 'it''s'),\n(2, 'it''s');\n" +
+				"INSERT INTO `t` (`id`, `name`) VALUES\n(3, 'it''s');\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := NewSQLExporter(tt.opts, model.DatabaseMySQL).ExportData(&out, "", "t", &countingRows{n: 3}); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -4,13 +4,22 @@ import (
 	"dbm/internal/model"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // SQLExporter SQL 导出器
+// 按 SQLOptions.TargetType 生成目标数据库方言的建表与 INSERT 语句，为空时与源数据库相同。
 type SQLExporter struct {
-	opts   *model.SQLOptions
-	dbType model.DatabaseType
+	opts        *model.SQLOptions
+	dbType      model.DatabaseType // 源数据库类型
+	target      sqlDialect
+	mapper      *TypeMapper
+	identity    map[string]string   // 表名 → 自增列，写出数据时据此处理自增列的写入
+	boolColumns map[string][]string // 表名 → 目标类型为布尔的列，源数据库以整数保存时写为布尔字面量
+	foreignKeys []string            // 所有表建好之后再添加的外键
 }
 
 // NewSQLExporter 创建 SQL 导出器
@@ -22,179 +31,512 @@ func NewSQLExporter(opts *model.SQLOptions, dbType model.DatabaseType) *SQLExpor
 	if opts.BatchSize <= 0 {
 		opts.BatchSize = 100
 	}
+	target := opts.TargetType
+	if target == "" {
+		target = dbType
+	}
 
 	return &SQLExporter{
-		opts:   opts,
-		dbType: dbType,
+		opts:        opts,
+		dbType:      dbType,
+		target:      sqlDialect(target),
+		identity:    make(map[string]string),
+		boolColumns: make(map[string][]string),
 	}
 }
 
+// SameDialect 目标数据库与源数据库类型相同，此时可以使用数据库生成的原生建表语句
+func (e *SQLExporter) SameDialect() bool {
+	return model.DatabaseType(e.target) == e.dbType
+}
+
 // ExportSchema 导出表结构
+// 跨数据库导出时按类型映射转换列类型，映射的警告以注释写在建表语句之前。
 func (e *SQLExporter) ExportSchema(writer io.Writer, schema *model.TableSchema) error {
+	columns, warnings, err := e.mapColumns(schema.Columns)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, warning := range warnings {
+		sb.WriteString("-- " + warning + "\n")
+	}
+
 	// DROP TABLE
 	if e.opts.IncludeDropTable {
-		dropSQL := e.generateDropTable(schema.Database, schema.Table)
-		if _, err := writer.Write([]byte(dropSQL)); err != nil {
-			return err
-		}
+		sb.WriteString(e.generateDropTable(schema.Database, schema.Table))
 	}
 
 	// CREATE TABLE
 	if e.opts.IncludeCreateTable {
-		createSQL := e.generateCreateTable(schema)
-		if _, err := writer.Write([]byte(createSQL)); err != nil {
-			return err
-		}
+		sb.WriteString(e.generateCreateTable(schema, columns))
 	}
 
-	return nil
+	_, err = io.WriteString(writer, sb.String())
+	return err
+}
+
+// ExportForeignKeys 写出建表时收集的外键，应在所有表的结构与数据之后调用，以免引用的表尚未创建
+func (e *SQLExporter) ExportForeignKeys(writer io.Writer) error {
+	if len(e.foreignKeys) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(writer, strings.Join(e.foreignKeys, "")+"\n")
+	e.foreignKeys = nil
+	return err
 }
 
-// ExportData 导出表数据
-func (e *SQLExporter) ExportData(writer io.Writer, database, table string, columns []string, rows []map[string]interface{}) error {
+// ExportData 逐行读取表数据并写出 INSERT 语句，超过 MaxRows 的行不再读取
+func (e *SQLExporter) ExportData(writer io.Writer, database, table string, rows RowIterator) error {
 	if e.opts.StructureOnly {
 		return nil
 	}
 
+	batchSize := 1
 	if e.opts.BatchInsert && e.opts.BatchSize > 1 {
-		return e.exportBatchInsert(writer, database, table, columns, rows)
+		batchSize = e.opts.BatchSize
 	}
 
-	return e.exportSingleInsert(writer, database, table, columns, rows)
-}
+	columns := rows.Columns()
+	kinds := columnKinds(rows)
+	for i, column := range columns {
+		if containsFold(e.boolColumns[e.tableKey(table)], column) {
+			kinds[i] = kindBool
+		}
+	}
+	name := e.tableName(database, table)
+	rows = Limit(rows, e.opts.MaxRows)
+	batch := make([][]string, 0, batchSize)
+	written := 0
+
+	// 建表时声明了自增列且数据中包含该列时，写入前后按目标数据库处理自增列
+	var before, after string
+	if column, ok := e.identity[e.tableKey(table)]; ok && containsFold(columns, column) {
+		before, after = e.target.identityInsert(name, column)
+	}
 
-// exportSingleInsert 单行 INSERT
-func (e *SQLExporter) exportSingleInsert(writer io.Writer, database, table string, columns []string, rows []map[string]interface{}) error {
-	for _, row := range rows {
-		insertSQL := e.generateInsert(database, table, columns, row)
-		if _, err := writer.Write([]byte(insertSQL)); err != nil {
+	writeBatch := func() error {
+		var stmt string
+		if written == 0 {
+			stmt = before
+		}
+		if batchSize > 1 {
+			stmt += e.generateBatchInsert(name, columns, batch)
+		} else {
+			stmt += e.generateInsert(name, columns, batch[0])
+		}
+		if _, err := writer.Write([]byte(stmt)); err != nil {
 			return err
 		}
+
+		// 按行数定期刷新输出
+		if written/flushRows != (written+len(batch))/flushRows {
+			flush(writer)
+		}
+		written += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		values := make([]string, len(columns))
+		for i, v := range rows.Values() {
+			values[i] = e.formatValue(v, kinds[i])
+		}
+		batch = append(batch, values)
+
+		if len(batch) == batchSize {
+			if err := writeBatch(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		if err := writeBatch(); err != nil {
+			return err
+		}
+	}
+	if written > 0 && after != "" {
+		_, err := io.WriteString(writer, after)
+		return err
 	}
 	return nil
 }
 
-// exportBatchInsert 批量 INSERT
-func (e *SQLExporter) exportBatchInsert(writer io.Writer, database, table string, columns []string, rows []map[string]interface{}) error {
-	for i := 0; i < len(rows); i += e.opts.BatchSize {
-		end := i + e.opts.BatchSize
-		if end > len(rows) {
-			end = len(rows)
+// mapColumns 跨数据库导出时将列类型映射为目标数据库的类型
+func (e *SQLExporter) mapColumns(columns []model.ColumnInfo) ([]model.ColumnInfo, []string, error) {
+	if e.SameDialect() || len(columns) == 0 {
+		return columns, nil, nil
+	}
+
+	if e.mapper == nil {
+		mapper, err := LoadDefaultConfig()
+		if err != nil {
+			return nil, nil, err
 		}
+		e.mapper = mapper
+	}
+	result, err := e.mapper.MapTypes(e.dbType, model.DatabaseType(e.target), columns)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		batch := rows[i:end]
-		batchSQL := e.generateBatchInsert(database, table, columns, batch)
-		if _, err := writer.Write([]byte(batchSQL)); err != nil {
-			return err
+	var choices map[string]string
+	for sourceType := range result.RequiresUser {
+		if choice, ok := e.opts.TypeChoices[sourceType]; ok {
+			if choices == nil {
+				choices = make(map[string]string)
+			}
+			choices[sourceType] = choice
 		}
 	}
+	if err := e.mapper.ApplyUserChoices(result, choices); err != nil {
+		return nil, nil, err
+	}
 
-	return nil
+	mapped := make([]model.ColumnInfo, len(columns))
+	for i, col := range columns {
+		mapped[i] = col
+		if target := result.Mapped[col.Type]; target != "" {
+			mapped[i].Type = target
+		}
+	}
+	return mapped, result.Warnings, nil
+}
+
+// tableName 引用后的表名
+// 库名只在同类数据库之间有意义，跨数据库导出时只使用表名；PostgreSQL、KingBase 与 SQLite 的库名不能用于限定表名。
+func (e *SQLExporter) tableName(database, table string) string {
+	if e.dbType == model.DatabaseMSSQL && e.SameDialect() {
+		schema, name := splitSchema(table)
+		return e.target.quote(schema) + "." + e.target.quote(name)
+	}
+	table = e.tableKey(table)
+	switch model.DatabaseType(e.target) {
+	case model.DatabasePostgreSQL, model.DatabaseKingBase, model.DatabaseSQLite:
+		database = ""
+	}
+	if database == "" || !e.SameDialect() {
+		return e.target.quote(table)
+	}
+	return e.target.quote(database) + "." + e.target.quote(table)
+}
+
+// tableKey 不带 schema 的表名，SQL Server 的表名可能带有 schema，跨数据库导出时只使用表名
+func (e *SQLExporter) tableKey(table string) string {
+	if e.dbType == model.DatabaseMSSQL {
+		_, name := splitSchema(table)
+		return name
+	}
+	return table
 }
 
 // generateDropTable 生成 DROP TABLE 语句
 func (e *SQLExporter) generateDropTable(database, table string) string {
-	if database != "" {
-		return fmt.Sprintf("DROP TABLE IF EXISTS `%s`.`%s`;\n\n", database, table)
-	}
-	return fmt.Sprintf("DROP TABLE IF EXISTS `%s`;\n\n", table)
+	return e.target.dropTable(e.tableName(database, table))
 }
 
-// generateCreateTable 生成 CREATE TABLE 语句
-func (e *SQLExporter) generateCreateTable(schema *model.TableSchema) string {
+// generateCreateTable 生成 CREATE TABLE 语句，以及建表后的注释与索引语句
+// columns 为映射为目标数据库类型后的列，外键收集后由 ExportForeignKeys 写出。
+func (e *SQLExporter) generateCreateTable(schema *model.TableSchema, columns []model.ColumnInfo) string {
 	var sb strings.Builder
+	d := e.target
+	dbType := model.DatabaseType(d)
+	name := e.tableName(schema.Database, schema.Table)
+	primaryKey := e.primaryKey(schema)
+
+	// 自增列，SQLite 的 AUTOINCREMENT 只能用于单列主键
+	identity := ""
+	for _, col := range schema.Columns {
+		if e.autoIncrement(col, primaryKey) {
+			identity = col.Name
+			break
+		}
+	}
+	if identity != "" && (dbType == model.DatabaseClickHouse ||
+		dbType == model.DatabaseSQLite && (len(primaryKey) != 1 || primaryKey[0] != identity)) {
+		identity = ""
+	}
+	if identity != "" {
+		e.identity[e.tableKey(schema.Table)] = identity
+	} else {
+		delete(e.identity, e.tableKey(schema.Table))
+	}
 
-	qualifiedName := schema.Table
-	if schema.Database != "" {
-		qualifiedName = fmt.Sprintf("`%s`.`%s`", schema.Database, schema.Table)
+	var bools []string
+	for _, col := range columns {
+		if typeKind(col.Type) == kindBool {
+			bools = append(bools, col.Name)
+		}
 	}
+	e.boolColumns[e.tableKey(schema.Table)] = bools
 
-	sb.WriteString(fmt.Sprintf("CREATE TABLE `%s` (\n", qualifiedName))
+	var defs, comments []string
+	for _, col := range columns {
+		def := d.quote(col.Name) + " " + e.columnType(col, primaryKey)
+		if col.Name == identity {
+			def = d.quote(col.Name) + " " + d.identityType(col.Type) + d.identityClause()
+		}
+		// Oracle 要求 DEFAULT 写在 NOT NULL 之前
+		if col.Name != identity {
+			if value := e.defaultValue(col); value != "" {
+				def += " DEFAULT " + value
+			}
+		}
+		if !col.Nullable && dbType != model.DatabaseClickHouse {
+			def += " NOT NULL"
+		}
+		// MySQL 的 ON UPDATE CURRENT_TIMESTAMP
+		if col.Name != identity && dbType == model.DatabaseMySQL && e.SameDialect() && strings.HasPrefix(strings.ToLower(col.Extra), "on update") {
+			def += " " + col.Extra
+		}
+		if col.Comment != "" {
+			if d.inlineComments() {
+				def += " COMMENT " + d.stringLiteral(col.Comment)
+			} else if stmt := d.columnComment(name, schema.Table, col.Name, col.Comment); stmt != "" {
+				comments = append(comments, stmt)
+			}
+		}
+		defs = append(defs, "  "+def)
+	}
 
-	// 列定义
-	for i, col := range schema.Columns {
-		colDef := fmt.Sprintf("  `%s` %s", col.Name, col.Type)
+	// 主键，ClickHouse 以主键作为排序键
+	if len(primaryKey) > 0 && dbType != model.DatabaseClickHouse && !(dbType == model.DatabaseSQLite && identity != "") {
+		defs = append(defs, fmt.Sprintf("  PRIMARY KEY (%s)", d.quoteList(primaryKey)))
+	}
 
-		if !col.Nullable {
-			colDef += " NOT NULL"
+	// 外键：SQLite 不支持 ALTER TABLE ADD CONSTRAINT，写在建表语句中；ClickHouse 不支持外键
+	for _, fk := range foreignKeys(schema.Constraints) {
+		switch dbType {
+		case model.DatabaseClickHouse:
+		case model.DatabaseSQLite:
+			defs = append(defs, fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s)",
+				d.quoteList(fk.columns), d.quote(fk.refTable), d.quoteList(fk.refColumns)))
+		default:
+			e.foreignKeys = append(e.foreignKeys, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s);\n",
+				name, d.quote(fk.name), d.quoteList(fk.columns), d.quote(fk.refTable), d.quoteList(fk.refColumns)))
 		}
+	}
 
-		if col.DefaultValue != "" {
-			colDef += fmt.Sprintf(" DEFAULT %s", col.DefaultValue)
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", name))
+	sb.WriteString(strings.Join(defs, ",\n"))
+	sb.WriteString("\n)")
+	if dbType == model.DatabaseClickHouse {
+		orderBy := "tuple()"
+		if len(primaryKey) > 0 {
+			orderBy = "(" + d.quoteList(primaryKey) + ")"
 		}
+		sb.WriteString("\nENGINE = MergeTree()\nORDER BY " + orderBy)
+	}
+	sb.WriteString(";\n")
+
+	for _, stmt := range comments {
+		sb.WriteString(stmt)
+	}
 
-		if col.Extra != "" {
-			colDef += fmt.Sprintf(" %s", col.Extra)
+	// 索引，ClickHouse 没有对应的二级索引
+	if dbType != model.DatabaseClickHouse {
+		for _, idx := range schema.Indexes {
+			if idx.Primary && sameColumns(idx.Columns, primaryKey) || len(idx.Columns) == 0 {
+				continue
+			}
+			unique := ""
+			if idx.Unique || idx.Primary {
+				unique = "UNIQUE "
+			}
+			sb.WriteString(fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);\n",
+				unique, d.quote(e.indexName(schema.Table, idx)), name, d.quoteList(idx.Columns)))
 		}
+	}
+
+	sb.WriteString("\n")
+	return sb.String()
+}
 
-		if i < len(schema.Columns)-1 {
-			colDef += ","
+// primaryKey 主键列
+// SQLite 的 INTEGER PRIMARY KEY 没有对应的索引，按列信息中的 PRI 标记确定；其余数据库按主键索引确定。
+func (e *SQLExporter) primaryKey(schema *model.TableSchema) []string {
+	if e.dbType != model.DatabaseSQLite {
+		for _, idx := range schema.Indexes {
+			if idx.Primary && len(idx.Columns) > 0 {
+				return idx.Columns
+			}
 		}
+	}
+	var columns []string
+	for _, col := range schema.Columns {
+		if col.Key == "PRI" {
+			columns = append(columns, col.Name)
+		}
+	}
+	return columns
+}
 
-		sb.WriteString(colDef + "\n")
+// autoIncrement 判断列是否为自增列：MySQL 的 auto_increment、SQL Server 的 IDENTITY、
+// PostgreSQL 的 serial（nextval 默认值）与 Oracle 的序列默认值；
+// SQLite 中单列的 INTEGER PRIMARY KEY 为 rowid 的别名，导出到其他数据库时视为自增列。
+func (e *SQLExporter) autoIncrement(col model.ColumnInfo, primaryKey []string) bool {
+	extra := strings.ToLower(col.Extra)
+	def := strings.ToLower(col.DefaultValue)
+	switch {
+	case strings.Contains(extra, "auto_increment"), strings.Contains(extra, "identity"):
+		return true
+	case strings.HasPrefix(def, "nextval("), strings.Contains(def, ".nextval"):
+		return true
 	}
+	return e.dbType == model.DatabaseSQLite && !e.SameDialect() &&
+		len(primaryKey) == 1 && primaryKey[0] == col.Name && strings.EqualFold(col.Type, "INTEGER")
+}
 
-	// 主键
-	for _, idx := range schema.Indexes {
-		if idx.Primary && len(idx.Columns) > 0 {
-			sb.WriteString(fmt.Sprintf("  PRIMARY KEY (`%s`)", strings.Join(idx.Columns, "`, `")))
-			break
-		}
+// columnType 列类型，ClickHouse 的可空列使用 Nullable 包装，排序键中的列除外
+func (e *SQLExporter) columnType(col model.ColumnInfo, primaryKey []string) string {
+	if model.DatabaseType(e.target) != model.DatabaseClickHouse || e.SameDialect() || !col.Nullable ||
+		containsFold(primaryKey, col.Name) || strings.HasPrefix(col.Type, "Nullable(") {
+		return col.Type
 	}
+	return "Nullable(" + col.Type + ")"
+}
 
-	sb.WriteString("\n)")
+var (
+	// numericLiteralRegex 数值默认值
+	numericLiteralRegex = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`)
+	// currentTimestampRegex 各数据库表示当前时间的默认值
+	currentTimestampRegex = regexp.MustCompile(`(?i)^(current_timestamp|now|getdate|sysdatetime|systimestamp|sysdate|localtimestamp)(\(\d*\))?$`)
+	// typeCastRegex PostgreSQL 默认值中的类型转换，如 'abc'::character varying
+	typeCastRegex = regexp.MustCompile(`::[a-zA-Z ]+(\(\d+(,\d+)?\))?(\[\])?$`)
+)
 
-	// 表注释
-	if schema.Columns != nil && len(schema.Columns) > 0 {
-		// 获取表注释（从第一个列的注释或其他方式）
+// defaultValue 列的默认值表达式
+// 同类数据库之间原样使用（MySQL 的字符串默认值不带引号，仍需转换）；跨数据库导出时转换数值、字符串、布尔值与当前时间，
+// 其余表达式无法转换，不写出默认值。
+func (e *SQLExporter) defaultValue(col model.ColumnInfo) string {
+	def := strings.TrimSpace(col.DefaultValue)
+	if def == "" || e.SameDialect() && e.dbType != model.DatabaseMySQL {
+		return def
 	}
 
-	sb.WriteString(";\n\n")
+	// SQL Server 的默认值带有括号，如 ((0))、(N'abc')、(getdate())
+	for len(def) >= 2 && def[0] == '(' && def[len(def)-1] == ')' {
+		def = strings.TrimSpace(def[1 : len(def)-1])
+	}
+	def = typeCastRegex.ReplaceAllString(def, "")
+
+	switch upper := strings.ToUpper(def); {
+	case upper == "NULL":
+		return ""
+	case numericLiteralRegex.MatchString(def):
+		// 以 0 与 1 表示的布尔值写为目标数据库的布尔字面量
+		if typeKind(col.Type) == kindBool {
+			return e.target.boolLiteral(def != "0")
+		}
+		return def
+	case upper == "TRUE" || upper == "FALSE":
+		return e.target.boolLiteral(upper == "TRUE")
+	case currentTimestampRegex.MatchString(def):
+		return e.target.currentTimestamp(col.Type)
+	}
 
-	return sb.String()
+	if s, ok := unquoteLiteral(def); ok {
+		return e.target.stringLiteral(s)
+	}
+	// MySQL 的 information_schema 中字符串默认值不带引号，表达式默认值的 EXTRA 为 DEFAULT_GENERATED
+	if e.dbType == model.DatabaseMySQL && !strings.Contains(strings.ToUpper(col.Extra), "DEFAULT_GENERATED") {
+		return e.target.stringLiteral(def)
+	}
+	return ""
 }
 
-// generateInsert 生成 INSERT 语句
-func (e *SQLExporter) generateInsert(database, table string, columns []string, row map[string]interface{}) string {
-	var sb strings.Builder
+// unquoteLiteral 解析单引号字符串字面量，支持 SQL Server 的 N 前缀
+func unquoteLiteral(s string) (string, bool) {
+	s = strings.TrimPrefix(s, "N")
+	if len(s) < 2 || s[0] != '\'' || s[len(s)-1] != '\'' {
+		return "", false
+	}
+	inner := s[1 : len(s)-1]
+	if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+		return "", false
+	}
+	return strings.ReplaceAll(inner, "''", "'"), true
+}
 
-	qualifiedTable := table
-	if database != "" {
-		qualifiedTable = fmt.Sprintf("`%s`.`%s`", database, table)
+// indexName 目标数据库中的索引名
+// SQLite 自动创建的索引名不能用于 CREATE INDEX；从索引名在表内唯一的数据库导出到索引名在 schema 内唯一的数据库时加上表名前缀。
+func (e *SQLExporter) indexName(table string, idx model.IndexInfo) string {
+	name := strings.Trim(idx.Name, `"`)
+	if strings.HasPrefix(name, "sqlite_autoindex_") {
+		return table + "_" + strings.Join(idx.Columns, "_") + "_key"
 	}
+	if e.target.globalIndexNames() && !sqlDialect(e.dbType).globalIndexNames() {
+		return table + "_" + name
+	}
+	return name
+}
 
-	sb.WriteString(fmt.Sprintf("INSERT INTO `%s` (`%s`) VALUES (", qualifiedTable, strings.Join(columns, "`, `")))
+// foreignKey 按约束名合并后的外键
+type foreignKey struct {
+	name       string
+	columns    []string
+	refTable   string
+	refColumns []string
+}
 
-	values := make([]string, len(columns))
-	for i, col := range columns {
-		values[i] = e.formatValue(row[col])
+// foreignKeys 将按列记录的外键约束按约束名合并，保持约束与列的顺序
+func foreignKeys(constraints []model.ConstraintInfo) []*foreignKey {
+	var (
+		keys   []*foreignKey
+		byName = make(map[string]*foreignKey)
+	)
+	for _, c := range constraints {
+		if c.Type != "FOREIGN KEY" {
+			continue
+		}
+		fk, ok := byName[c.Name]
+		if !ok {
+			fk = &foreignKey{name: c.Name, refTable: c.ReferenceTable}
+			byName[c.Name] = fk
+			keys = append(keys, fk)
+		}
+		fk.columns = append(fk.columns, c.ColumnName)
+		fk.refColumns = append(fk.refColumns, c.ReferenceColumn)
 	}
+	return keys
+}
+
+// generateInsert 生成 INSERT 语句，table 为引用后的表名，values 为已格式化的值
+func (e *SQLExporter) generateInsert(table string, columns []string, values []string) string {
+	var sb strings.Builder
 
+	sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES (", table, e.target.quoteList(columns)))
 	sb.WriteString(strings.Join(values, ", "))
 	sb.WriteString(");\n")
 
 	return sb.String()
 }
 
-// generateBatchInsert 生成批量 INSERT 语句
-func (e *SQLExporter) generateBatchInsert(database, table string, columns []string, rows []map[string]interface{}) string {
+// generateBatchInsert 生成批量 INSERT 语句，table 为引用后的表名，rows 为已格式化的值
+// Oracle 不支持多行 VALUES，使用 INSERT ALL。
+func (e *SQLExporter) generateBatchInsert(table string, columns []string, rows [][]string) string {
 	var sb strings.Builder
+	columnList := e.target.quoteList(columns)
 
-	qualifiedTable := table
-	if database != "" {
-		qualifiedTable = fmt.Sprintf("`%s`.`%s`", database, table)
+	if model.DatabaseType(e.target) == model.DatabaseOracle {
+		sb.WriteString("INSERT ALL\n")
+		for _, values := range rows {
+			sb.WriteString(fmt.Sprintf("  INTO %s (%s) VALUES (%s)\n", table, columnList, strings.Join(values, ", ")))
+		}
+		sb.WriteString("SELECT 1 FROM DUAL;\n")
+		return sb.String()
 	}
 
-	sb.WriteString(fmt.Sprintf("INSERT INTO `%s` (`%s`) VALUES\n", qualifiedTable, strings.Join(columns, "`, `")))
+	sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", table, columnList))
 
-	for i, row := range rows {
+	for i, values := range rows {
 		sb.WriteString("(")
-
-		values := make([]string, len(columns))
-		for j, col := range columns {
-			values[j] = e.formatValue(row[col])
-		}
-
 		sb.WriteString(strings.Join(values, ", "))
 		sb.WriteString(")")
 
@@ -208,27 +550,65 @@ func (e *SQLExporter) generateBatchInsert(database, table string, columns []stri
 	return sb.String()
 }
 
-// formatValue 格式化值
-func (e *SQLExporter) formatValue(v interface{}) string {
-	if v == nil {
-		return "NULL"
-	}
-
+// formatValue 按目标数据库格式化值
+// 以文本返回的数值不加引号，布尔列的值（包括以整数保存的值）写为目标数据库的布尔字面量，二进制列的值写为十六进制字面量。
+func (e *SQLExporter) formatValue(v any, kind valueKind) string {
+	d := e.target
 	switch val := v.(type) {
-	case string:
-		// 转义单引号
-		escaped := strings.ReplaceAll(val, "'", "''")
-		return "'" + escaped + "'"
+	case nil:
+		return "NULL"
+	case bool:
+		return d.boolLiteral(val)
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if kind == kindBool {
+			return d.boolLiteral(fmt.Sprintf("%d", val) != "0")
+		}
 		return fmt.Sprintf("%d", val)
-	case float32, float64:
-		return fmt.Sprintf("%f", val)
-	case bool:
-		if val {
-			return "1"
+	case float32:
+		return d.floatLiteral(float64(val), 32)
+	case float64:
+		return d.floatLiteral(val, 64)
+	case time.Time:
+		return d.timeLiteral(val)
+	case []byte:
+		return d.binaryLiteral(val)
+	case string:
+		switch kind {
+		case kindNumber:
+			if n, ok := numberText(val); ok {
+				return n
+			}
+		case kindBool:
+			if b, err := strconv.ParseBool(val); err == nil {
+				return d.boolLiteral(b)
+			}
+		case kindBinary:
+			return d.binaryLiteral([]byte(val))
+		}
+		return d.stringLiteral(val)
+	}
+	return d.stringLiteral(textValue(v, ""))
+}
+
+// containsFold 判断 names 中是否有与 name 相同（不区分大小写）的名称
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameColumns 判断两组列是否相同
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
-		return "0"
-	default:
-		return "NULL"
 	}
+	return true
 }
@@ -0,0 +1,228 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"dbm/internal/model"
+)
+
+// mysqlUsersSchema MySQL 的测试表结构：自增主键、带注释与默认值的列、唯一索引与外键
+func mysqlUsersSchema() *model.TableSchema {
+	return &model.TableSchema{
+		Database: "shop",
+		Table:    "users",
+		Columns: []model.ColumnInfo{
+			{Name: "id", Type: "int unsigned", Key: "PRI", Extra: "auto_increment"},
+			{Name: "name", Type: "varchar(50)", DefaultValue: "it's", Comment: "名称"},
+			{Name: "created", Type: "datetime(3)", Nullable: true, DefaultValue: "CURRENT_TIMESTAMP(3)", Extra: "DEFAULT_GENERATED"},
+			{Name: "org_id", Type: "int", Nullable: true},
+			{Name: "active", Type: "tinyint(1)", DefaultValue: "1"},
+		},
+		Indexes: []model.IndexInfo{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "idx_name", Columns: []string{"name"}, Unique: true},
+		},
+		Constraints: []model.ConstraintInfo{
+			{Name: "fk_org", Type: "FOREIGN KEY", ColumnName: "org_id", ReferenceTable: "orgs", ReferenceColumn: "id"},
+		},
+	}
+}
+
+// exportUsers 按目标数据库导出测试表的结构、两行数据与外键
+func exportUsers(t *testing.T, opts *model.SQLOptions) string {
+	t.Helper()
+
+	e := NewSQLExporter(opts, model.DatabaseMySQL)
+	var out bytes.Buffer
+	if err := e.ExportSchema(&out, mysqlUsersSchema()); err != nil {
+		t.Fatal(err)
+	}
+	rows := NewSliceRows([]string{"id", "name", "active"}, [][]any{
+		{int64(1), `a'b\c`, int64(1)},
+		{int64(2), "c", int64(0)},
+	})
+	if err := e.ExportData(&out, "shop", "users", rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExportForeignKeys(&out); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// assertSQL 检查生成的 SQL 包含 want 中的每一段且不包含 unwanted 中的任何一段
+func assertSQL(t *testing.T, got string, want, unwanted []string) {
+	t.Helper()
+	for _, s := range want {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+	for _, s := range unwanted {
+		if strings.Contains(got, s) {
+			t.Errorf("unexpected %q in:\n%s", s, got)
+		}
+	}
+}
+
+func TestSQLExporter_MySQLToPostgreSQL(t *testing.T) {
+	got := exportUsers(t, &model.SQLOptions{
+		IncludeCreateTable: true,
+		IncludeDropTable:   true,
+		TargetType:         model.DatabasePostgreSQL,
+	})
+
+	assertSQL(t, got, []string{
+		`DROP TABLE IF EXISTS "users";`,
+		`"id" BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL`,
+		`"name" VARCHAR(50) DEFAULT 'it''s' NOT NULL`,
+		`"created" TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP`,
+		`"active" BOOLEAN DEFAULT TRUE NOT NULL`,
+		`PRIMARY KEY ("id")`,
+		`COMMENT ON COLUMN "users"."name" IS '名称';`,
+		`CREATE UNIQUE INDEX "users_idx_name" ON "users" ("name");`,
+		`INSERT INTO "users" ("id", "name", "active") VALUES (1, 'a''b\c', TRUE);`,
+		`SELECT setval(pg_get_serial_sequence('"users"', 'id'), MAX("id")) FROM "users";`,
+		`ALTER TABLE "users" ADD CONSTRAINT "fk_org" FOREIGN KEY ("org_id") REFERENCES "orgs" ("id");`,
+	}, []string{"`", "AUTO_INCREMENT", " COMMENT '", "shop"})
+
+	// 外键在所有数据之后添加
+	if strings.Index(got, "ADD CONSTRAINT") < strings.Index(got, "INSERT INTO") {
+		t.Errorf("foreign key written before data:\n%s", got)
+	}
+}
+
+func TestSQLExporter_MySQLToMSSQL(t *testing.T) {
+	got := exportUsers(t, &model.SQLOptions{IncludeCreateTable: true, TargetType: model.DatabaseMSSQL})
+
+	assertSQL(t, got, []string{
+		"[id] BIGINT IDENTITY(1,1) NOT NULL",
+		"[name] NVARCHAR(50) DEFAULT N'it''s' NOT NULL",
+		"[active] BIT DEFAULT 1 NOT NULL",
+		"EXEC sp_addextendedproperty N'MS_Description', N'名称', N'SCHEMA', N'dbo', N'TABLE', N'users', N'COLUMN', N'name';",
+		"CREATE UNIQUE INDEX [idx_name] ON [users] ([name]);",
+		"SET IDENTITY_INSERT [users] ON;\nINSERT INTO [users] ([id], [name], [active]) VALUES (1, N'a''b\\c', 1);",
+		"VALUES (2, N'c', 0);\nSET IDENTITY_INSERT [users] OFF;",
+	}, []string{"`", "AUTO_INCREMENT", "DROP TABLE"})
+}
+
+func TestSQLExporter_MySQLToOracle(t *testing.T) {
+	got := exportUsers(t, &model.SQLOptions{
+		IncludeCreateTable: true,
+		IncludeDropTable:   true,
+		BatchInsert:        true,
+		BatchSize:          10,
+		TargetType:         model.DatabaseOracle,
+	})
+
+	assertSQL(t, got, []string{
+		`EXECUTE IMMEDIATE 'DROP TABLE "users"';`,
+		"IF SQLCODE != -942 THEN",
+		`"id" NUMBER(10) GENERATED BY DEFAULT AS IDENTITY NOT NULL`,
+		`"name" VARCHAR2(50) DEFAULT 'it''s' NOT NULL`,
+		`COMMENT ON COLUMN "users"."name" IS '名称';`,
+		"INSERT ALL\n  INTO \"users\" (\"id\", \"name\", \"active\") VALUES (1, 'a''b\\c', 1)\n" +
+			"  INTO \"users\" (\"id\", \"name\", \"active\") VALUES (2, 'c', 0)\nSELECT 1 FROM DUAL;",
+		`ALTER TABLE "users" MODIFY ("id" GENERATED BY DEFAULT AS IDENTITY (START WITH LIMIT VALUE));`,
+	}, []string{"DROP TABLE IF EXISTS", "`"})
+}
+
+func TestSQLExporter_MySQLToClickHouse(t *testing.T) {
+	got := exportUsers(t, &model.SQLOptions{IncludeCreateTable: true, TargetType: model.DatabaseClickHouse})
+
+	assertSQL(t, got, []string{
+		"`id` UInt32,",
+		"`name` String DEFAULT 'it\\'s' COMMENT '名称'",
+		"`created` Nullable(DateTime64(3)) DEFAULT now()",
+		"`active` Bool DEFAULT true",
+		"ENGINE = MergeTree()\nORDER BY (`id`);",
+		"VALUES (1, 'a\\'b\\\\c', true);",
+	}, []string{"AUTO_INCREMENT", "NOT NULL", "FOREIGN KEY", "CREATE UNIQUE INDEX"})
+}
+
+func TestSQLExporter_MySQLToSQLite(t *testing.T) {
+	got := exportUsers(t, &model.SQLOptions{IncludeCreateTable: true, TargetType: model.DatabaseSQLite})
+
+	assertSQL(t, got, []string{
+		`"id" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL`,
+		`FOREIGN KEY ("org_id") REFERENCES "orgs" ("id")`,
+		`CREATE UNIQUE INDEX "users_idx_name" ON "users" ("name");`,
+	}, []string{"ALTER TABLE", "COMMENT", `PRIMARY KEY ("id")`})
+}
+
+func TestSQLExporter_SameDialect(t *testing.T) {
+	got := exportUsers(t, &model.SQLOptions{IncludeCreateTable: true})
+
+	assertSQL(t, got, []string{
+		"CREATE TABLE `shop`.`users` (",
+		"`id` int unsigned AUTO_INCREMENT NOT NULL",
+		"`name` varchar(50) DEFAULT 'it''s' NOT NULL COMMENT '名称'",
+		"`created` datetime(3) DEFAULT CURRENT_TIMESTAMP(3)",
+		"CREATE UNIQUE INDEX `idx_name` ON `shop`.`users` (`name`);",
+		"VALUES (1, 'a''b\\\\c', 1);",
+	}, nil)
+}
+
+func TestSQLDialect_Literals(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.FixedZone("", 8*3600))
+	tests := []struct {
+		dialect               model.DatabaseType
+		str, bin, boolean, tm string
+	}{
+		{model.DatabaseMySQL, `'it''s \\ x'`, "X'00ff'", "1", "'2024-01-02 03:04:05.123456'"},
+		{model.DatabasePostgreSQL, `'it''s \ x'`, `'\x00ff'`, "TRUE", "'2024-01-02 03:04:05.123456+08:00'"},
+		{model.DatabaseSQLite, `'it''s \ x'`, "X'00ff'", "1", "'2024-01-02 03:04:05.123456'"},
+		{model.DatabaseMSSQL, `N'it''s \ x'`, "0x00ff", "1", "'2024-01-02T03:04:05.123'"},
+		{model.DatabaseOracle, `'it''s \ x'`, "HEXTORAW('00ff')", "1", "TIMESTAMP '2024-01-02 03:04:05.123456'"},
+		{model.DatabaseClickHouse, `'it\'s \\ x'`, "unhex('00ff')", "true", "'2024-01-02 03:04:05.123456'"},
+		{model.DatabaseDM, `'it''s \ x'`, "0x00ff", "1", "TIMESTAMP '2024-01-02 03:04:05.123456'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			e := NewSQLExporter(&model.SQLOptions{TargetType: tt.dialect}, model.DatabaseMySQL)
+			if got := e.formatValue(`it's \ x`, kindText); got != tt.str {
+				t.Errorf("string = %s, want %s", got, tt.str)
+			}
+			if got := e.formatValue("\x00\xff", kindBinary); got != tt.bin {
+				t.Errorf("binary = %s, want %s", got, tt.bin)
+			}
+			if got := e.formatValue(true, kindText); got != tt.boolean {
+				t.Errorf("bool = %s, want %s", got, tt.boolean)
+			}
+			if got := e.formatValue(ts, kindDate); got != tt.tm {
+				t.Errorf("time = %s, want %s", got, tt.tm)
+			}
+		})
+	}
+}
+
+func TestSQLExporter_MSSQLSchemaQualifiedTable(t *testing.T) {
+	schema := &model.TableSchema{
+		Table: "orders",
+		Columns: []model.ColumnInfo{
+			{Name: "id", Type: "int", Key: "PRI", Extra: "IDENTITY"},
+			{Name: "total", Type: "money", Nullable: true, DefaultValue: "((0))"},
+		},
+		Indexes: []model.IndexInfo{{Name: "PK_orders", Columns: []string{"id"}, Unique: true, Primary: true}},
+	}
+	e := NewSQLExporter(&model.SQLOptions{IncludeCreateTable: true, TargetType: model.DatabasePostgreSQL}, model.DatabaseMSSQL)
+	var out bytes.Buffer
+	if err := e.ExportSchema(&out, schema); err != nil {
+		t.Fatal(err)
+	}
+	rows := NewSliceRows([]string{"id", "total"}, [][]any{{int64(1), "12.5000"}})
+	if err := e.ExportData(&out, "", "sales.orders", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	assertSQL(t, out.String(), []string{
+		`"id" INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL`,
+		`"total" NUMERIC(19,4) DEFAULT 0`,
+		`INSERT INTO "orders" ("id", "total") VALUES (1, '12.5000');`,
+		`SELECT setval(pg_get_serial_sequence('"orders"', 'id'), MAX("id")) FROM "orders";`,
+	}, []string{"sales", "[", "CREATE UNIQUE INDEX"})
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"dbm/internal/model"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableWriter 可以在一个文件中依次写出多个表的导出器
+// 按查询导出时表名为空；全部表写出后调用 Close 写出文件结尾，Close 不关闭底层输出。
+type TableWriter interface {
+	// WriteTable 逐行读取并写出一个表，超过导出器 MaxRows 的行不再读取
+	WriteTable(name string, rows RowIterator) error
+	// Close 写出文件结尾
+	Close() error
+}
+
+// SchemaSetter 需要表结构确定列类型的导出器，如 Parquet 的 DECIMAL 精度
+// 按表导出时在 WriteTable 之前传入该表的列信息。
+type SchemaSetter interface {
+	SetSchema(columns []model.ColumnInfo)
+}
+
+// defaultDateLayout 文本格式中时间值的默认格式
+const defaultDateLayout = "2006-01-02 15:04:05"
+
+// textValue 将值格式化为文本，nil 格式化为 null
+func textValue(v any, null string) string {
+	switch val := v.(type) {
+	case nil:
+		return null
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		return val.Format(defaultDateLayout)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// numberText 返回数值的文本形式，值不是合法数字时返回 false
+// 数据库驱动常以文本返回 DECIMAL 等类型，按文本写出可以避免转换为浮点数丢失精度。
+func numberText(v any) (string, bool) {
+	switch val := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(val), true
+	case float32, float64:
+		s := textValue(val, "")
+		return s, s != "NaN" && !strings.Contains(s, "Inf")
+	case string:
+		s := strings.TrimSpace(val)
+		if _, err := strconv.ParseFloat(s, 64); err != nil || !json.Valid([]byte(s)) {
+			return "", false
+		}
+		return s, true
+	}
+	return "", false
+}
+
+// dateLayouts 以文本返回的日期时间值可能的格式
+var dateLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02",
+}
+
+// dateValue 解析日期时间值，无法解析时返回 false
+func dateValue(v any) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// boolValue 解析布尔值，无法解析时返回 false
+func boolValue(v any) (value, ok bool) {
+	switch val := v.(type) {
+	case bool:
+		return val, true
+	case int64:
+		return val != 0, val == 0 || val == 1
+	case string:
+		b, err := strconv.ParseBool(val)
+		return b, err == nil
+	}
+	return false, false
+}
@@ -1,58 +1,71 @@
 package export
 
 import (
+	_ "embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"dbm/internal/model"
 
 	"gopkg.in/yaml.v3"
 )
 
+// defaultTypeMapping 内置的类型映射配置
+//
+//go:embed config/type_mapping.yaml
+var defaultTypeMapping []byte
+
 // TypeMapperConfig 配置文件结构
 type TypeMapperConfig struct {
-	TypeMappings map[string]map[string]TypeRule
+	TypeMappings map[string]map[string]TypeRule `yaml:"type_mapping"`
 }
 
 // TypeRule 类型转换规则
+// 目标类型不带参数时沿用源类型的长度或精度，如 VARCHAR(255) → VARCHAR2(255)；
+// 设置 MaxLength 时，源类型没有长度或长度超出上限时使用 SafeFallback。
 type TypeRule struct {
-	TargetType   string
-	SafeFallback string
-	PrecisionLoss bool
-	RequiresUser  bool
-	UserOptions  []TypeOption
-	Note         string
+	TargetType    string       `yaml:"target" json:"targetType"`
+	SafeFallback  string       `yaml:"safe_fallback" json:"safeFallback"`
+	PrecisionLoss bool         `yaml:"precision_loss" json:"precisionLoss"`
+	RequiresUser  bool         `yaml:"requires_user" json:"requiresUser"`
+	UserOptions   []TypeOption `yaml:"user_options" json:"userOptions"`
+	MaxLength     int          `yaml:"max_length" json:"maxLength,omitempty"`
+	Note          string       `yaml:"note" json:"note"`
 }
 
 // TypeOption 用户可选择的类型选项
 type TypeOption struct {
-	Label string
-	Value string
+	Label string `yaml:"label" json:"label"`
+	Value string `yaml:"value" json:"value"`
 }
 
 // TypeMappingResult 类型映射结果
 type TypeMappingResult struct {
-	Success      bool                `json:"success"`
-	Mapped       map[string]string   `json:"mapped"`         // 源类型 → 目标类型
-	Warnings     []string            `json:"warnings"`
+	Success      bool                 `json:"success"`
+	Mapped       map[string]string    `json:"mapped"` // 源类型 → 目标类型
+	Warnings     []string             `json:"warnings"`
 	RequiresUser map[string]*TypeRule `json:"requiresUser"` // 需要用户选择的类型
-	Summary      TypeSummary        `json:"summary"`
+	Summary      TypeSummary          `json:"summary"`
 }
 
-// TypeSummary 映射摘要
+// TypeSummary 映射摘要，按不同的源类型计数
 type TypeSummary struct {
-	Total       int `json:"total"`
-	Direct      int `json:"direct"`       // 直接映射
-	Fallback    int `json:"fallback"`     // 安全降级
-	UserChoice  int `json:"userChoice"`   // 需要用户选择
-	LossyCount  int `json:"lossyCount"`  // 有精度损失
+	Total      int `json:"total"`
+	Direct     int `json:"direct"`     // 直接映射
+	Fallback   int `json:"fallback"`   // 安全降级
+	UserChoice int `json:"userChoice"` // 需要用户选择
+	LossyCount int `json:"lossyCount"` // 有精度损失
 }
 
 // TypeMapper 类型映射器
 type TypeMapper struct {
 	configPath string
-	mappings   map[string]map[string]*TypeRule // key: "mysql_to_postgresql"
+	mappings   map[string]map[string]*TypeRule // key: "mysql_to_postgresql"，规则的键为 typeKey 规范化后的类型名
 }
 
 // NewTypeMapper 创建类型映射器
@@ -62,6 +75,19 @@ func NewTypeMapper(configPath string) (*TypeMapper, error) {
 		return nil, fmt.Errorf("failed to read type mapping config: %w", err)
 	}
 
+	mappings, err := parseTypeMappings(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypeMapper{
+		configPath: configPath,
+		mappings:   mappings,
+	}, nil
+}
+
+// parseTypeMappings 解析类型映射配置，规则的键按 typeKey 规范化
+func parseTypeMappings(data []byte) (map[string]map[string]*TypeRule, error) {
 	var config TypeMapperConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse type mapping config: %w", err)
@@ -73,70 +99,83 @@ func NewTypeMapper(configPath string) (*TypeMapper, error) {
 		ptrMapping := make(map[string]*TypeRule)
 		for typeKey, rule := range mapping {
 			ruleCopy := rule
-			ptrMapping[typeKey] = &ruleCopy
+			ptrMapping[normalizeTypeKey(typeKey)] = &ruleCopy
 		}
 		mappings[key] = ptrMapping
 	}
-
-	return &TypeMapper{
-		configPath: configPath,
-		mappings:   mappings,
-	}, nil
+	return mappings, nil
 }
 
 // MapTypes 映射类型
 // sourceDB: 源数据库类型
 // targetDB: 目标数据库类型
 // columns: 源表列信息
+// 结果以源列的原始类型为键；没有对应规则的类型保持不变并给出警告。
 func (m *TypeMapper) MapTypes(sourceDB, targetDB model.DatabaseType, columns []model.ColumnInfo) (*TypeMappingResult, error) {
 	result := &TypeMappingResult{
 		Success:      true,
 		Mapped:       make(map[string]string),
 		RequiresUser: make(map[string]*TypeRule),
 		Warnings:     []string{},
-		Summary: TypeSummary{
-			Total: len(columns),
-		},
 	}
 
+	// 多个列的类型相同时只映射一次
+	var types []string
+	for _, col := range columns {
+		if _, seen := result.Mapped[col.Type]; !seen {
+			result.Mapped[col.Type] = col.Type
+			types = append(types, col.Type)
+		}
+	}
+	result.Summary.Total = len(types)
+
 	mappingKey := getMappingKey(sourceDB, targetDB)
 	mapping, exists := m.mappings[mappingKey]
 
 	if !exists {
 		// 无映射配置，返回原始类型
-		for _, col := range columns {
-			result.Mapped[col.Type] = col.Type
+		result.Summary.Direct = len(types)
+		if sourceDB != targetDB && len(types) > 0 {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("未配置 %s 到 %s 的类型映射，保持原类型", sourceDB, targetDB))
 		}
-		result.Summary.Direct = len(columns)
 		return result, nil
 	}
 
-	for _, col := range columns {
-		rule, hasRule := mapping[col.Type]
+	for _, typ := range types {
+		rule, source := parseColumnType(typ).lookup(mapping)
 
-		if !hasRule {
+		if rule == nil {
 			// 无规则，保持原类型
-			result.Mapped[col.Type] = col.Type
 			result.Summary.Direct++
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s 未配置映射规则，保持原类型", typ))
 			continue
 		}
 
 		if rule.RequiresUser {
 			// 需要用户选择
-			result.RequiresUser[col.Type] = rule
+			result.RequiresUser[typ] = rule
 			result.Summary.UserChoice++
 			// 暂时使用默认值
-			result.Mapped[col.Type] = rule.TargetType
+			result.Mapped[typ], _ = rule.resolve(targetDB, source)
 		} else if rule.PrecisionLoss {
 			// 有精度损失，使用安全降级
-			result.Mapped[col.Type] = rule.SafeFallback
+			target := withParams(targetDB, rule.fallback(), source)
+			result.Mapped[typ] = target
 			result.Summary.Fallback++
 			result.Summary.LossyCount++
 			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("%s → %s (有精度损失)", col.Type, rule.SafeFallback))
+				fmt.Sprintf("%s → %s (有精度损失)", typ, target))
+		} else if target, exceeded := rule.resolve(targetDB, source); exceeded {
+			// 长度或精度超出目标类型的上限
+			result.Mapped[typ] = target
+			result.Summary.Fallback++
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s → %s (超出 %s 的上限 %d)", typ, target, rule.TargetType, rule.MaxLength))
 		} else {
 			// 直接映射
-			result.Mapped[col.Type] = rule.TargetType
+			result.Mapped[typ] = target
 			result.Summary.Direct++
 		}
 	}
@@ -167,22 +206,213 @@ func (m *TypeMapper) ApplyUserChoices(result *TypeMappingResult, choices map[str
 	return nil
 }
 
+// Pairs 返回已配置的映射键，如 mysql_to_postgresql
+func (m *TypeMapper) Pairs() []string {
+	pairs := make([]string, 0, len(m.mappings))
+	for key := range m.mappings {
+		pairs = append(pairs, key)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
 // getMappingKey 获取映射键
 func getMappingKey(sourceDB, targetDB model.DatabaseType) string {
 	return fmt.Sprintf("%s_to_%s", sourceDB, targetDB)
 }
 
 // LoadDefaultConfig 加载默认配置
+// 使用内置配置，~/.dbm/type_mapping.yaml 存在时其中的规则按映射键与类型覆盖内置规则。
 func LoadDefaultConfig() (*TypeMapper, error) {
+	mappings, err := parseTypeMappings(defaultTypeMapping)
+	if err != nil {
+		return nil, err
+	}
+
 	// 尝试从用户目录加载
 	homeDir, _ := os.UserHomeDir()
 	configPath := filepath.Join(homeDir, ".dbm", "type_mapping.yaml")
 
 	if _, err := os.Stat(configPath); err == nil {
-		return NewTypeMapper(configPath)
+		custom, err := NewTypeMapper(configPath)
+		if err != nil {
+			return nil, err
+		}
+		for key, rules := range custom.mappings {
+			if mappings[key] == nil {
+				mappings[key] = make(map[string]*TypeRule)
+			}
+			for typeKey, rule := range rules {
+				mappings[key][typeKey] = rule
+			}
+		}
+	}
+
+	return &TypeMapper{configPath: configPath, mappings: mappings}, nil
+}
+
+var (
+	// typeWrapperRegex ClickHouse 的 Nullable(...)、LowCardinality(...) 包装
+	typeWrapperRegex = regexp.MustCompile(`(?i)^(nullable|lowcardinality)\((.*)\)$`)
+	// numericParamsRegex 可以沿用到目标类型的参数：长度，或精度与小数位数
+	numericParamsRegex = regexp.MustCompile(`^\d+(,\d+)?$`)
+)
+
+// columnType 解析后的源列类型
+type columnType struct {
+	full   string // 规范化后的完整类型，如 TINYINT(1)、DECIMAL(10,2)_UNSIGNED
+	base   string // 去掉参数的类型，如 VARCHAR、INT_UNSIGNED、TIMESTAMP_WITH_TIME_ZONE
+	params string // 第一组参数，如 255、10,2
+}
+
+// parseColumnType 解析数据库返回的列类型
+// 去掉 ClickHouse 的 Nullable/LowCardinality 包装与 MySQL 的 ZEROFILL，参数与类型名分开保存。
+func parseColumnType(typ string) columnType {
+	typ = strings.TrimSpace(typ)
+	for {
+		m := typeWrapperRegex.FindStringSubmatch(typ)
+		if m == nil {
+			break
+		}
+		typ = strings.TrimSpace(m[2])
+	}
+
+	base, params := splitTypeParams(typ)
+	var t columnType
+	if len(params) > 0 {
+		t.params = strings.ReplaceAll(params[0], " ", "")
+	}
+	t.full = normalizeTypeKey(typ)
+	t.base = normalizeTypeKey(base)
+	return t
+}
+
+// splitTypeParams 拆分类型名与括号中的参数，如 TIMESTAMP(6) WITH TIME ZONE → TIMESTAMP WITH TIME ZONE 与 [6]
+// 参数中可以嵌套括号，如 Array(Nullable(String))。
+func splitTypeParams(typ string) (string, []string) {
+	var (
+		base   strings.Builder
+		params []string
+		depth  int
+		start  int
+	)
+	for i, r := range typ {
+		switch {
+		case r == '(':
+			if depth == 0 {
+				start = i + 1
+				base.WriteByte(' ')
+			}
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+			if depth == 0 {
+				params = append(params, typ[start:i])
+			}
+		case depth == 0:
+			base.WriteRune(r)
+		}
+	}
+	return base.String(), params
+}
+
+// normalizeTypeKey 规范化类型名：大写，空白替换为下划线，参数中的空白去掉
+func normalizeTypeKey(typ string) string {
+	var (
+		key   strings.Builder
+		depth int
+	)
+	for _, r := range typ {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth > 0 && r == ' ' {
+			continue
+		}
+		key.WriteRune(r)
+	}
+
+	var words []string
+	for _, word := range strings.Fields(strings.ToUpper(key.String())) {
+		if word != "ZEROFILL" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, "_")
+}
+
+// lookup 查找规则：先按完整类型（如 TINYINT(1)），再按去掉参数的类型，无符号类型最后按有符号类型
+// 按带参数的完整类型匹配时，参数已由规则确定，返回的类型不再带参数。
+func (t columnType) lookup(mapping map[string]*TypeRule) (*TypeRule, columnType) {
+	if rule, ok := mapping[t.full]; ok && t.full != t.base {
+		return rule, columnType{full: t.base, base: t.base}
 	}
+	for _, key := range []string{t.base, strings.TrimSuffix(t.base, "_UNSIGNED")} {
+		if rule, ok := mapping[key]; ok {
+			return rule, t
+		}
+	}
+	return nil, t
+}
+
+// length 源类型的第一个参数，即长度或精度
+func (t columnType) length() (int, bool) {
+	if !numericParamsRegex.MatchString(t.params) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.SplitN(t.params, ",", 2)[0])
+	return n, err == nil
+}
+
+// resolve 按规则确定目标类型，源类型没有长度时不视为超出上限
+func (r *TypeRule) resolve(targetDB model.DatabaseType, t columnType) (target string, exceeded bool) {
+	if r.MaxLength > 0 {
+		n, ok := t.length()
+		if !ok {
+			return r.fallback(), false
+		}
+		if n > r.MaxLength {
+			return r.fallback(), true
+		}
+	}
+	return withParams(targetDB, r.TargetType, t), false
+}
 
-	// 使用内置配置
-	configPath = filepath.Join("configs", "type_mapping.yaml")
-	return NewTypeMapper(configPath)
-}
\ No newline at end of file
+// fallback 安全降级的目标类型，未配置时为目标类型
+func (r *TypeRule) fallback() string {
+	if r.SafeFallback != "" {
+		return r.SafeFallback
+	}
+	return r.TargetType
+}
+
+// paramTypes 可以带长度或精度参数的目标类型
+var paramTypes = map[string]bool{
+	"CHAR": true, "VARCHAR": true, "VARCHAR2": true, "NCHAR": true, "NVARCHAR": true, "NVARCHAR2": true,
+	"CHARACTER": true, "CHARACTER VARYING": true, "BINARY": true, "VARBINARY": true, "RAW": true,
+	"BIT": true, "BIT VARYING": true, "DECIMAL": true, "NUMERIC": true, "NUMBER": true,
+	"TIME": true, "TIMESTAMP": true, "DATETIME": true, "DATETIME2": true, "DATETIMEOFFSET": true,
+}
+
+// clickHouseParamTypes ClickHouse 中可以带参数的目标类型，类型名区分大小写
+var clickHouseParamTypes = map[string]bool{
+	"Decimal": true, "FixedString": true, "DateTime64": true,
+}
+
+// withParams 目标类型没有参数时沿用源类型的长度或精度
+func withParams(targetDB model.DatabaseType, target string, t columnType) string {
+	if strings.Contains(target, "(") || !numericParamsRegex.MatchString(t.params) {
+		return target
+	}
+	if targetDB == model.DatabaseClickHouse {
+		if !clickHouseParamTypes[target] {
+			return target
+		}
+	} else if !paramTypes[strings.ToUpper(target)] {
+		return target
+	}
+	return target + "(" + t.params + ")"
+}
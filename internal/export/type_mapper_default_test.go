@@ -0,0 +1,121 @@
+package export
+
+import (
+	"testing"
+
+	"dbm/internal/model"
+)
+
+func TestLoadDefaultConfig_CoversAllPairs(t *testing.T) {
+	// 内置配置不依赖工作目录
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	mapper, err := LoadDefaultConfig()
+	if err != nil {
+		t.Fatalf("LoadDefaultConfig failed: %v", err)
+	}
+
+	sources := []model.DatabaseType{
+		model.DatabaseMySQL, model.DatabasePostgreSQL, model.DatabaseSQLite, model.DatabaseOracle,
+		model.DatabaseClickHouse, model.DatabaseKingBase, model.DatabaseDM, model.DatabaseMSSQL, model.DatabaseMongoDB,
+	}
+	pairs := make(map[string]bool)
+	for _, key := range mapper.Pairs() {
+		pairs[key] = true
+	}
+	for _, source := range sources {
+		for _, target := range sources[:len(sources)-1] {
+			if source == target {
+				continue
+			}
+			if key := getMappingKey(source, target); !pairs[key] {
+				t.Errorf("missing mapping %s", key)
+			}
+		}
+	}
+}
+
+func TestTypeMapper_MapTypes_Parameterised(t *testing.T) {
+	mapper, err := LoadDefaultConfig()
+	if err != nil {
+		t.Fatalf("LoadDefaultConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		source, target model.DatabaseType
+		typ, want      string
+	}{
+		{model.DatabaseMySQL, model.DatabaseOracle, "varchar(255)", "VARCHAR2(255)"},
+		{model.DatabaseMySQL, model.DatabaseOracle, "varchar(20000)", "CLOB"},
+		{model.DatabaseMySQL, model.DatabasePostgreSQL, "decimal(10,2)", "NUMERIC(10,2)"},
+		{model.DatabaseMySQL, model.DatabasePostgreSQL, "int(10) unsigned", "BIGINT"},
+		{model.DatabaseMySQL, model.DatabasePostgreSQL, "decimal(10,2) unsigned zerofill", "NUMERIC(10,2)"},
+		{model.DatabaseMySQL, model.DatabasePostgreSQL, "tinyint(1)", "BOOLEAN"},
+		{model.DatabaseMySQL, model.DatabaseClickHouse, "datetime(3)", "DateTime64(3)"},
+		{model.DatabaseClickHouse, model.DatabasePostgreSQL, "Nullable(Int32)", "INTEGER"},
+		{model.DatabaseClickHouse, model.DatabaseMySQL, "LowCardinality(Nullable(String))", "LONGTEXT"},
+		{model.DatabasePostgreSQL, model.DatabaseMySQL, "character varying(64)", "VARCHAR(64)"},
+		{model.DatabasePostgreSQL, model.DatabaseMySQL, "text", "LONGTEXT"},
+		{model.DatabasePostgreSQL, model.DatabaseMySQL, "double precision", "DOUBLE"},
+		{model.DatabaseOracle, model.DatabaseMySQL, "NUMBER", "DECIMAL(65,30)"},
+		{model.DatabaseOracle, model.DatabaseClickHouse, "NUMBER(12,4)", "Decimal(12,4)"},
+		{model.DatabaseMongoDB, model.DatabasePostgreSQL, "bson.ObjectID", "CHAR(24)"},
+		{model.DatabaseMySQL, model.DatabaseMSSQL, "tinyint(1)", "BIT"},
+		{model.DatabaseMySQL, model.DatabaseMSSQL, "varchar(100)", "NVARCHAR(100)"},
+		{model.DatabaseMySQL, model.DatabaseMSSQL, "datetime(3)", "DATETIME2(3)"},
+		{model.DatabasePostgreSQL, model.DatabaseMSSQL, "character varying(8000)", "NVARCHAR(MAX)"},
+		{model.DatabaseMSSQL, model.DatabaseOracle, "nvarchar(MAX)", "CLOB"},
+	}
+	for _, tt := range tests {
+		result, err := mapper.MapTypes(tt.source, tt.target, []model.ColumnInfo{{Name: "c", Type: tt.typ}})
+		if err != nil {
+			t.Fatalf("MapTypes failed: %v", err)
+		}
+		if got := result.Mapped[tt.typ]; got != tt.want {
+			t.Errorf("%s → %s: %s = %q, want %q", tt.source, tt.target, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestTypeMapper_MapTypes_MaxLength(t *testing.T) {
+	mapper := &TypeMapper{
+		mappings: map[string]map[string]*TypeRule{
+			"mysql_to_oracle": {
+				"VARCHAR": &TypeRule{TargetType: "VARCHAR2", SafeFallback: "CLOB", MaxLength: 4000},
+			},
+		},
+	}
+
+	columns := []model.ColumnInfo{
+		{Name: "a", Type: "VARCHAR(100)"},
+		{Name: "b", Type: "VARCHAR(5000)"},
+	}
+	result, err := mapper.MapTypes(model.DatabaseMySQL, model.DatabaseOracle, columns)
+	if err != nil {
+		t.Fatalf("MapTypes failed: %v", err)
+	}
+
+	if result.Mapped["VARCHAR(100)"] != "VARCHAR2(100)" || result.Mapped["VARCHAR(5000)"] != "CLOB" {
+		t.Errorf("Mapped = %v", result.Mapped)
+	}
+	if result.Summary.Direct != 1 || result.Summary.Fallback != 1 || len(result.Warnings) != 1 {
+		t.Errorf("Summary = %+v, warnings = %v", result.Summary, result.Warnings)
+	}
+}
+
+func TestTypeMapper_MapTypes_MissingPair(t *testing.T) {
+	mapper := &TypeMapper{mappings: map[string]map[string]*TypeRule{}}
+
+	result, err := mapper.MapTypes(model.DatabaseMySQL, model.DatabaseOracle, []model.ColumnInfo{{Name: "a", Type: "INT"}})
+	if err != nil {
+		t.Fatalf("MapTypes failed: %v", err)
+	}
+
+	if result.Mapped["INT"] != "INT" {
+		t.Errorf("Expected INT kept, got %s", result.Mapped["INT"])
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %v", result.Warnings)
+	}
+}
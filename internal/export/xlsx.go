@@ -0,0 +1,357 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"dbm/internal/model"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// xlsxMaxRows Excel 工作表的最大行数，超出的行写入同名的续表
+	xlsxMaxRows = 1048576
+	// xlsxMaxCellText Excel 单元格文本的最大字符数，超出部分截断
+	xlsxMaxCellText = 32767
+	// xlsxMaxSheetName 工作表名称的最大字符数
+	xlsxMaxSheetName = 31
+	// xlsxMaxDigits Excel 数值的有效位数，位数更多的数值按文本写出以免丢失精度
+	xlsxMaxDigits = 15
+)
+
+// 样式索引，与 xlsxStyles 中 cellXfs 的顺序一致
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleHeader = 2
+)
+
+// xlsxEpoch Excel 日期序列号的起点（1900 日期系统）
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// XLSXExporter Excel 导出器
+// 每个表写出为一个工作表，数值、布尔与日期写为对应类型的单元格；
+// 工作表逐行写入压缩包，不在内存中保留整个结果集。
+type XLSXExporter struct {
+	zip    *zip.Writer
+	writer io.Writer
+	opts   *model.XLSXOptions
+	sheets []string
+}
+
+// NewXLSXExporter 创建 Excel 导出器
+func NewXLSXExporter(writer io.Writer, opts *model.XLSXOptions) *XLSXExporter {
+	if opts == nil {
+		opts = &model.XLSXOptions{}
+	}
+	if opts.DateFormat == "" {
+		opts.DateFormat = "yyyy-mm-dd hh:mm:ss"
+	}
+	return &XLSXExporter{zip: zip.NewWriter(writer), writer: writer, opts: opts}
+}
+
+// WriteTable 将一个表写出为工作表，超过 Excel 行数上限时续写到新的工作表
+func (e *XLSXExporter) WriteTable(name string, rows RowIterator) error {
+	columns := rows.Columns()
+	kinds := columnKinds(rows)
+	refs := make([]string, len(columns))
+	for i := range refs {
+		refs[i] = columnLetters(i)
+	}
+
+	sheetRows := xlsxMaxRows
+	if e.opts.IncludeHeader {
+		sheetRows--
+	}
+
+	rows = Limit(rows, e.opts.MaxRows)
+	var buf bytes.Buffer
+	var w io.Writer
+	rowNum := 0
+	for count := 0; ; count++ {
+		more := rows.Next()
+		// 首个工作表即使没有数据也要写出，之后每 sheetRows 行换一个工作表
+		if w == nil || (more && rowNum-boolInt(e.opts.IncludeHeader) == sheetRows) {
+			if w != nil {
+				if _, err := io.WriteString(w, xlsxSheetTail); err != nil {
+					return err
+				}
+			}
+			var err error
+			if w, err = e.beginSheet(name, columns); err != nil {
+				return err
+			}
+			rowNum = boolInt(e.opts.IncludeHeader)
+		}
+		if !more {
+			break
+		}
+
+		rowNum++
+		buf.Reset()
+		fmt.Fprintf(&buf, `<row r="%d">`, rowNum)
+		for i, v := range rows.Values() {
+			e.writeCell(&buf, refs[i]+strconv.Itoa(rowNum), v, kinds[i])
+		}
+		buf.WriteString("</row>")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if (count+1)%flushRows == 0 {
+			if err := e.zip.Flush(); err != nil {
+				return err
+			}
+			flush(e.writer)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, xlsxSheetTail)
+	return err
+}
+
+// beginSheet 在压缩包中创建新的工作表并写出表头
+func (e *XLSXExporter) beginSheet(name string, columns []string) (io.Writer, error) {
+	e.sheets = append(e.sheets, e.sheetName(name))
+	w, err := e.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(e.sheets)))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if e.opts.IncludeHeader {
+		buf.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	buf.WriteString("<sheetData>")
+	if e.opts.IncludeHeader {
+		buf.WriteString(`<row r="1">`)
+		for i, col := range columns {
+			writeInlineString(&buf, columnLetters(i)+"1", col, xlsxStyleHeader)
+		}
+		buf.WriteString("</row>")
+	}
+	_, err = w.Write(buf.Bytes())
+	return w, err
+}
+
+// xlsxSheetTail 工作表结尾
+const xlsxSheetTail = "</sheetData></worksheet>"
+
+// sheetName 生成合法且不重复的工作表名称
+// 名称不能包含 []:*?/\ 且最长 31 个字符，重名时追加序号。
+func (e *XLSXExporter) sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncateRunes(name, xlsxMaxSheetName)
+	for n := 2; e.sheetExists(candidate); n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = truncateRunes(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+// sheetExists 判断工作表名称是否已使用，Excel 不区分大小写
+func (e *XLSXExporter) sheetExists(name string) bool {
+	for _, sheet := range e.sheets {
+		if strings.EqualFold(sheet, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCell 按值的类型写出单元格
+func (e *XLSXExporter) writeCell(buf *bytes.Buffer, ref string, v any, kind valueKind) {
+	if v == nil {
+		return
+	}
+
+	switch val := v.(type) {
+	case bool:
+		writeBoolCell(buf, ref, val)
+		return
+	case time.Time:
+		if writeDateCell(buf, ref, val) {
+			return
+		}
+	}
+
+	switch kind {
+	case kindNumber:
+		if n, ok := numberText(v); ok && significantDigits(n) <= xlsxMaxDigits {
+			fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, n)
+			return
+		}
+	case kindDate:
+		if t, ok := dateValue(v); ok && writeDateCell(buf, ref, t) {
+			return
+		}
+	case kindBool:
+		if b, ok := boolValue(v); ok {
+			writeBoolCell(buf, ref, b)
+			return
+		}
+	}
+
+	// 驱动直接返回的数值
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		if n, ok := numberText(v); ok && significantDigits(n) <= xlsxMaxDigits {
+			fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, n)
+			return
+		}
+	}
+
+	writeInlineString(buf, ref, textValue(v, ""), 0)
+}
+
+// writeBoolCell 写出布尔单元格
+func writeBoolCell(buf *bytes.Buffer, ref string, b bool) {
+	fmt.Fprintf(buf, `<c r="%s" t="b"><v>%d</v></c>`, ref, boolInt(b))
+}
+
+// writeDateCell 将时间写为日期序列号，Excel 无法表示 1900 年之前的日期，此时返回 false
+func writeDateCell(buf *bytes.Buffer, ref string, t time.Time) bool {
+	// 按时间值自身的时区取日期与时刻
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	if wall.Year() < 1900 || wall.Year() > 9999 {
+		return false
+	}
+	serial := float64(wall.Sub(xlsxEpoch)) / float64(24*time.Hour)
+	fmt.Fprintf(buf, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, strconv.FormatFloat(serial, 'f', -1, 64))
+	return true
+}
+
+// writeInlineString 写出内联字符串单元格，超长文本截断
+func writeInlineString(buf *bytes.Buffer, ref, text string, style int) {
+	text = truncateRunes(text, xlsxMaxCellText)
+	fmt.Fprintf(buf, `<c r="%s" t="inlineStr"`, ref)
+	if style > 0 {
+		fmt.Fprintf(buf, ` s="%d"`, style)
+	}
+	buf.WriteString(`><is><t xml:space="preserve">`)
+	xml.EscapeText(buf, []byte(text))
+	buf.WriteString("</t></is></c>")
+}
+
+// Close 写出工作簿结构、样式并结束压缩包，没有导出任何表时写出一个空工作表
+func (e *XLSXExporter) Close() error {
+	if len(e.sheets) == 0 {
+		w, err := e.beginSheet("", nil)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, xlsxSheetTail); err != nil {
+			return err
+		}
+	}
+
+	var workbook, rels, types bytes.Buffer
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+
+	for i, name := range e.sheets {
+		n := i + 1
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name))
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+	}
+	workbook.WriteString("</sheets></workbook>")
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`, len(e.sheets)+1)
+	types.WriteString("</Types>")
+
+	var styles bytes.Buffer
+	styles.WriteString(xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="`)
+	xml.EscapeText(&styles, []byte(e.opts.DateFormat))
+	styles.WriteString(`"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`)
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", types.Bytes()},
+		{"_rels/.rels", []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`)},
+		{"xl/workbook.xml", workbook.Bytes()},
+		{"xl/_rels/workbook.xml.rels", rels.Bytes()},
+		{"xl/styles.xml", styles.Bytes()},
+	}
+	for _, f := range files {
+		w, err := e.zip.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return e.zip.Close()
+}
+
+// columnLetters 将从 0 开始的列序号转换为 A、B、…、AA 形式的列名
+func columnLetters(i int) string {
+	var letters []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		letters = append([]byte{byte('A' + (i-1)%26)}, letters...)
+	}
+	return string(letters)
+}
+
+// significantDigits 统计数值文本的有效数字位数
+func significantDigits(n string) int {
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		n = n[:i]
+	}
+	n = strings.TrimLeft(strings.Trim(n, "+-"), "0.")
+	if strings.Contains(n, ".") {
+		n = strings.TrimRight(n, "0")
+	}
+	return strings.Count(n, "") - 1 - strings.Count(n, ".")
+}
+
+// truncateRunes 截断到最多 max 个字符
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// boolInt 将布尔值转换为 0 或 1
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"dbm/internal/model"
+)
+
+// readZipFile 读取压缩包中的文件内容
+func readZipFile(t *testing.T, r *zip.Reader, name string) string {
+	t.Helper()
+	f, err := r.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每个部件都必须是格式正确的 XML
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("%s is not well-formed: %v", name, err)
+		}
+	}
+	return string(data)
+}
+
+func TestXLSXExporter(t *testing.T) {
+	var out bytes.Buffer
+	e := NewXLSXExporter(&out, &model.XLSXOptions{IncludeHeader: true})
+	if err := e.WriteTable("orders/2024", newTypedRows()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WriteTable("ORDERS_2024", NewSliceRows([]string{"n"}, [][]any{{3.25}, {true}})); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		readZipFile(t, r, name)
+	}
+
+	workbook := readZipFile(t, r, "xl/workbook.xml")
+	if !strings.Contains(workbook, `name="orders_2024"`) || !strings.Contains(workbook, `name="ORDERS_2024 (2)"`) {
+		t.Errorf("sheet names not sanitized:\n%s", workbook)
+	}
+
+	sheet := readZipFile(t, r, "xl/worksheets/sheet1.xml")
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr" s="2"><is><t xml:space="preserve">id</t></is></c>`,
+		`<c r="A2"><v>1</v></c>`,
+		// 超过 15 位有效数字的数值按文本写出
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">12345678901234567890.5</t></is></c>`,
+		`<c r="C3" t="inlineStr"><is><t xml:space="preserve">line1&#xA;line2 &lt;x&gt;</t></is></c>`,
+		`<c r="D2" s="1"><v>45293.12783564815</v></c>`,
+		`<c r="E2" t="b"><v>1</v></c>`,
+		`<row r="3"><c r="A3"><v>2</v></c><c r="C3"`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet1 missing %s\n%s", want, sheet)
+		}
+	}
+
+	sheet2 := readZipFile(t, r, "xl/worksheets/sheet2.xml")
+	if !strings.Contains(sheet2, `<c r="A2"><v>3.25</v></c>`) || !strings.Contains(sheet2, `<c r="A3" t="b"><v>1</v></c>`) {
+		t.Errorf("sheet2 = %s", sheet2)
+	}
+}
+
+func TestColumnLetters(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := columnLetters(i); got != want {
+			t.Errorf("columnLetters(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestTypeKind(t *testing.T) {
+	tests := map[string]valueKind{
+		"INT4":                   kindNumber,
+		"UNSIGNED BIGINT":        kindNumber,
+		"Nullable(UInt64)":       kindNumber,
+		"Decimal(18, 2)":         kindNumber,
+		"NUMBER":                 kindNumber,
+		"FLOAT8":                 kindNumber,
+		"POINT":                  kindText,
+		"INTERVAL":               kindText,
+		"TIMESTAMPTZ":            kindDate,
+		"DateTime64(3)":          kindDate,
+		"BOOL":                   kindBool,
+		"LowCardinality(String)": kindText,
+		"":                       kindText,
+	}
+	for typ, want := range tests {
+		if got := typeKind(typ); got != want {
+			t.Errorf("typeKind(%q) = %d, want %d", typ, got, want)
+		}
+	}
+}
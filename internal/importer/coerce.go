@@ -0,0 +1,356 @@
+package importer
+
+import (
+	"dbm/internal/model"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valueKind 列值的转换类别
+type valueKind int
+
+const (
+	kindText valueKind = iota
+	kindInteger
+	kindFloat
+	kindDecimal
+	kindBool
+	kindDateTime
+	kindBinary
+)
+
+var (
+	// wrapperRegex ClickHouse 的 Nullable(...)、LowCardinality(...) 包装
+	wrapperRegex  = regexp.MustCompile(`(?i)^(Nullable|LowCardinality)\((.*)\)$`)
+	integerRegex  = regexp.MustCompile(`(?i)^((TINY|SMALL|MEDIUM|BIG)?INT(EGER)?|U?INT(8|16|32|64|128|256)|INT[248]|(BIG|SMALL)?SERIAL)\b`)
+	floatRegex    = regexp.MustCompile(`(?i)^(FLOAT(4|8|32|64)?|DOUBLE|REAL|BINARY_FLOAT|BINARY_DOUBLE)\b`)
+	decimalRegex  = regexp.MustCompile(`(?i)^(DECIMAL(32|64|128|256)?|NUMERIC|NUMBER|DEC|MONEY|SMALLMONEY)\b`)
+	boolRegex     = regexp.MustCompile(`(?i)^(BOOL|BOOLEAN|BIT)\b`)
+	dateTimeRegex = regexp.MustCompile(`(?i)^(DATE|DATE32|DATETIME(2|64)?|SMALLDATETIME|DATETIMEOFFSET|TIMESTAMP\w*)\b|DateTime$`)
+	binaryRegex   = regexp.MustCompile(`(?i)^((TINY|MEDIUM|LONG)?BLOB|(VAR)?BINARY|BYTEA|(LONG )?RAW|IMAGE)\b`)
+)
+
+// dateTimeLayouts 可识别的日期时间格式，不含时区的值按 UTC 解析
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// columnKind 按数据库列类型确定转换类别
+func columnKind(typ string) valueKind {
+	typ = strings.TrimSpace(typ)
+	for {
+		m := wrapperRegex.FindStringSubmatch(typ)
+		if m == nil {
+			break
+		}
+		typ = m[2]
+	}
+
+	switch {
+	case boolRegex.MatchString(typ):
+		return kindBool
+	case integerRegex.MatchString(typ):
+		return kindInteger
+	case floatRegex.MatchString(typ):
+		return kindFloat
+	case decimalRegex.MatchString(typ):
+		return kindDecimal
+	case dateTimeRegex.MatchString(typ):
+		return kindDateTime
+	case binaryRegex.MatchString(typ):
+		return kindBinary
+	default:
+		return kindText
+	}
+}
+
+// coerce 将文件中的字符串转换为列类型对应的值
+func coerce(kind valueKind, s string) (any, error) {
+	switch kind {
+	case kindInteger:
+		v := strings.TrimSpace(s)
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return n, nil
+		}
+		if b, ok := parseBool(v); ok {
+			if b {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		return nil, fmt.Errorf("不是有效的整数")
+	case kindFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("不是有效的数字")
+		}
+		return f, nil
+	case kindDecimal:
+		// 校验后保留原文，避免精度损失
+		v := strings.TrimSpace(s)
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("不是有效的数字")
+		}
+		return v, nil
+	case kindBool:
+		b, ok := parseBool(strings.TrimSpace(s))
+		if !ok {
+			return nil, fmt.Errorf("不是有效的布尔值")
+		}
+		return b, nil
+	case kindDateTime:
+		t, ok := parseDateTime(strings.TrimSpace(s))
+		if !ok {
+			return nil, fmt.Errorf("不是可识别的日期时间格式")
+		}
+		return t, nil
+	case kindBinary:
+		// 0x 或 \x 前缀的十六进制按字节解码，其余按原文字节写入
+		v := strings.TrimSpace(s)
+		if len(v) > 2 && (v[:2] == "0x" || v[:2] == "0X" || v[:2] == `\x`) {
+			b, err := hex.DecodeString(v[2:])
+			if err != nil {
+				return nil, fmt.Errorf("不是有效的十六进制数据")
+			}
+			return b, nil
+		}
+		return []byte(s), nil
+	default:
+		return s, nil
+	}
+}
+
+// NewValueConverter 返回按目标列类型 typ 转换值的函数，用于跨数据库迁移
+// 值的 Go 类型与列类别一致时直接使用，否则按文本转换；nil 保持为 nil。
+func NewValueConverter(typ string) func(v any) (any, error) {
+	kind := columnKind(typ)
+	return func(v any) (any, error) {
+		if v == nil {
+			return nil, nil
+		}
+		if val, ok := convertTyped(kind, v); ok {
+			return val, nil
+		}
+		return coerce(kind, valueText(v))
+	}
+}
+
+// convertTyped 值的 Go 类型可以直接用于列类别时返回转换后的值
+func convertTyped(kind valueKind, v any) (any, bool) {
+	switch val := v.(type) {
+	case int64:
+		switch kind {
+		case kindInteger, kindDecimal:
+			return val, true
+		case kindFloat:
+			return float64(val), true
+		case kindBool:
+			return val != 0, true
+		}
+	case uint64:
+		if kind == kindInteger || kind == kindDecimal {
+			return val, true
+		}
+	case float64:
+		switch kind {
+		case kindFloat, kindDecimal:
+			return val, true
+		case kindInteger:
+			if val == float64(int64(val)) {
+				return int64(val), true
+			}
+		}
+	case bool:
+		switch kind {
+		case kindBool:
+			return val, true
+		case kindInteger:
+			if val {
+				return int64(1), true
+			}
+			return int64(0), true
+		}
+	case time.Time:
+		if kind == kindDateTime {
+			return val, true
+		}
+	case []byte:
+		if kind == kindBinary {
+			return val, true
+		}
+	case string:
+		if kind == kindBinary {
+			return []byte(val), true
+		}
+	}
+	return nil, false
+}
+
+// valueText 值的文本形式，用于按文本转换
+func valueText(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05.999999999")
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// parseBool 解析常见的布尔值写法
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "1", "true", "t", "yes", "y", "是":
+		return true, true
+	case "0", "false", "f", "no", "n", "否":
+		return false, true
+	}
+	return false, false
+}
+
+// parseDateTime 按 dateTimeLayouts 依次尝试解析
+func parseDateTime(s string) (time.Time, bool) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// columnStats 建表时用于推断列类型的样本统计
+type columnStats struct {
+	samples  int
+	integer  bool
+	float    bool
+	bool     bool
+	dateTime bool
+	maxLen   int
+}
+
+func newColumnStats() *columnStats {
+	return &columnStats{integer: true, float: true, bool: true, dateTime: true}
+}
+
+// add 记录一个非空样本值
+func (s *columnStats) add(v string) {
+	s.samples++
+	s.maxLen = max(s.maxLen, len([]rune(v)))
+	if s.integer {
+		_, err := strconv.ParseInt(v, 10, 64)
+		s.integer = err == nil
+	}
+	if s.float {
+		_, err := strconv.ParseFloat(v, 64)
+		s.float = err == nil
+	}
+	if s.bool {
+		// 0/1 推断为整数，这里只接受单词形式
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no":
+		default:
+			s.bool = false
+		}
+	}
+	if s.dateTime {
+		_, s.dateTime = parseDateTime(v)
+	}
+}
+
+// kind 推断的转换类别，没有样本时为文本
+func (s *columnStats) kind() valueKind {
+	switch {
+	case s.samples == 0:
+		return kindText
+	case s.integer:
+		return kindInteger
+	case s.float:
+		return kindFloat
+	case s.bool:
+		return kindBool
+	case s.dateTime:
+		return kindDateTime
+	default:
+		return kindText
+	}
+}
+
+// inferredColumn 按推断的类别生成目标数据库的列定义，列均可空
+func inferredColumn(dbType model.DatabaseType, name string, kind valueKind, maxLen int) model.ColumnDef {
+	col := model.ColumnDef{Name: name, Nullable: true}
+	switch dbType {
+	case model.DatabaseMySQL:
+		col.Type = map[valueKind]string{kindInteger: "BIGINT", kindFloat: "DOUBLE", kindBool: "TINYINT", kindDateTime: "DATETIME"}[kind]
+		if kind == kindBool {
+			col.Length = 1
+		}
+		if kind == kindText {
+			col.Type, col.Length = textType(maxLen, 255, "VARCHAR", "LONGTEXT")
+		}
+	case model.DatabasePostgreSQL, model.DatabaseKingBase:
+		col.Type = map[valueKind]string{kindInteger: "BIGINT", kindFloat: "DOUBLE PRECISION", kindBool: "BOOLEAN", kindDateTime: "TIMESTAMP", kindText: "TEXT"}[kind]
+	case model.DatabaseSQLite:
+		col.Type = map[valueKind]string{kindInteger: "INTEGER", kindFloat: "REAL", kindBool: "INTEGER", kindDateTime: "DATETIME", kindText: "TEXT"}[kind]
+	case model.DatabaseOracle:
+		col.Type = map[valueKind]string{kindInteger: "NUMBER", kindFloat: "BINARY_DOUBLE", kindBool: "NUMBER", kindDateTime: "TIMESTAMP"}[kind]
+		switch kind {
+		case kindInteger:
+			col.Precision = 19
+		case kindBool:
+			col.Precision = 1
+		case kindText:
+			col.Type, col.Length = textType(maxLen, 1000, "VARCHAR2", "CLOB")
+		}
+	case model.DatabaseDM:
+		col.Type = map[valueKind]string{kindInteger: "BIGINT", kindFloat: "DOUBLE", kindBool: "BIT", kindDateTime: "TIMESTAMP"}[kind]
+		if kind == kindText {
+			col.Type, col.Length = textType(maxLen, 2000, "VARCHAR", "CLOB")
+		}
+	case model.DatabaseMSSQL:
+		col.Type = map[valueKind]string{kindInteger: "BIGINT", kindFloat: "FLOAT", kindBool: "BIT", kindDateTime: "DATETIME2"}[kind]
+		if kind == kindText {
+			col.Type, col.Length = textType(maxLen, 4000, "NVARCHAR", "NVARCHAR(MAX)")
+		}
+	case model.DatabaseClickHouse:
+		col.Type = map[valueKind]string{kindInteger: "Int64", kindFloat: "Float64", kindBool: "Bool", kindDateTime: "DateTime64", kindText: "String"}[kind]
+		if kind == kindDateTime {
+			col.Precision = 6
+		}
+	default:
+		col.Type = map[valueKind]string{kindInteger: "int64", kindFloat: "float64", kindBool: "bool", kindDateTime: "date", kindText: "string"}[kind]
+	}
+	return col
+}
+
+// textType 样本最大长度不超过 limit 时使用定长的变长字符串类型，否则使用大文本类型
+// 长度取不小于样本最大长度两倍的 2 的幂（不超过 limit），为后续数据留出余量。
+func textType(maxLen, limit int, varchar, large string) (string, int) {
+	if maxLen > limit {
+		return large, 0
+	}
+	length := 16
+	for length < maxLen*2 && length < limit {
+		length *= 2
+	}
+	return varchar, min(length, limit)
+}
@@ -0,0 +1,501 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultBatchSize 默认每批写入行数
+	defaultBatchSize = 1000
+	// inferSampleRows 识别表头与推断列类型时预读的行数
+	inferSampleRows = 1000
+	// maxReportedErrors 结果中最多返回的失败行数
+	maxReportedErrors = 100
+)
+
+// Target 导入目标表
+type Target struct {
+	Adapter  adapter.DatabaseAdapter
+	DB       any
+	DBType   model.DatabaseType
+	Database string
+	Table    string
+}
+
+// CSVImporter CSV/TSV 导入器
+type CSVImporter struct {
+	opts *model.CSVImportOptions
+}
+
+// NewCSVImporter 创建 CSV 导入器
+func NewCSVImporter(opts *model.CSVImportOptions) *CSVImporter {
+	// 设置默认选项
+	if opts == nil {
+		opts = &model.CSVImportOptions{}
+	}
+	if opts.Quote == "" {
+		opts.Quote = `"`
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	return &CSVImporter{opts: opts}
+}
+
+// binding 文件列到表列的映射
+type binding struct {
+	source int // 文件列下标
+	column model.ImportColumn
+	kind   valueKind
+	def    model.ColumnDef // 建表时推断的列定义
+}
+
+// record 读取的一条记录
+type record struct {
+	fields []string
+	line   int
+}
+
+// Import 读取 CSV 数据并写入目标表
+// 类型转换失败的行跳过并记录；批量写入失败时逐行重试，定位并记录失败的行。
+func (i *CSVImporter) Import(ctx context.Context, src io.Reader, target *Target) (*model.ImportResult, error) {
+	start := time.Now()
+	result := &model.ImportResult{DryRun: i.opts.DryRun}
+
+	inserter, ok := target.Adapter.(adapter.BulkInserter)
+	if !ok {
+		return nil, fmt.Errorf("%s 不支持数据导入", target.DBType)
+	}
+
+	reader, err := i.newReader(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预读样本，用于识别表头与推断列类型
+	var samples []record
+	for len(samples) < inferSampleRows {
+		fields, line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBlank(fields) {
+			continue
+		}
+		samples = append(samples, record{fields, line})
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("文件中没有数据")
+	}
+
+	tableColumns, exists, err := lookupColumns(target, i.opts.CreateTable)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && !i.opts.CreateTable {
+		return nil, fmt.Errorf("表 %s 不存在，可启用建表选项按推断的列类型建表", target.Table)
+	}
+
+	// 表头
+	hasHeader := detectHeader(samples[0].fields, tableColumns)
+	if i.opts.Header != nil {
+		hasHeader = *i.opts.Header
+	}
+	var sources []string
+	if hasHeader {
+		for _, name := range samples[0].fields {
+			sources = append(sources, strings.TrimSpace(name))
+		}
+		samples = samples[1:]
+	} else {
+		for n := range samples[0].fields {
+			sources = append(sources, strconv.Itoa(n+1))
+		}
+	}
+
+	var bindings []binding
+	if exists {
+		bindings, result.Ignored, err = bind(sources, i.opts.Mapping, hasHeader, tableColumns)
+	} else {
+		bindings, result.Ignored, err = i.infer(sources, hasHeader, samples, target.DBType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bindings {
+		result.Columns = append(result.Columns, b.column)
+	}
+
+	if !exists && !i.opts.DryRun {
+		defs := make([]model.ColumnDef, len(bindings))
+		for n, b := range bindings {
+			defs[n] = b.def
+		}
+		if err := inserter.CreateTable(ctx, target.DB, target.Database, target.Table, defs); err != nil {
+			return nil, fmt.Errorf("建表失败: %w", err)
+		}
+		result.TableCreated = true
+	}
+
+	columns := make([]string, len(bindings))
+	for n, b := range bindings {
+		columns[n] = b.column.Target
+	}
+
+	w := &batchWriter{
+		ctx:      ctx,
+		inserter: inserter,
+		target:   target,
+		columns:  columns,
+		dryRun:   i.opts.DryRun,
+		result:   result,
+	}
+
+	process := func(rec record) error {
+		result.TotalRows++
+		row, rowErr := i.convert(rec, bindings)
+		if rowErr != nil {
+			w.fail(*rowErr)
+			return nil
+		}
+		w.rows = append(w.rows, row)
+		w.lines = append(w.lines, rec.line)
+		if len(w.rows) >= i.opts.BatchSize {
+			return w.flush()
+		}
+		return nil
+	}
+
+	for _, rec := range samples {
+		if err := process(rec); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fields, line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBlank(fields) {
+			continue
+		}
+		if err := process(record{fields, line}); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+
+	result.TimeCost = time.Since(start)
+	return result, nil
+}
+
+// newReader 按编码、分隔符与引号选项创建记录读取器
+func (i *CSVImporter) newReader(src io.Reader) (*recordReader, error) {
+	decoded, err := decodeReader(src, i.opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	buffered := bufio.NewReaderSize(decoded, sniffSize)
+
+	var sep rune
+	switch {
+	case i.opts.Separator == "":
+		sep = sniffSeparator(buffered)
+	case i.opts.Separator == `\t` || strings.EqualFold(i.opts.Separator, "tab"):
+		sep = '\t'
+	case utf8.RuneCountInString(i.opts.Separator) == 1:
+		sep, _ = utf8.DecodeRuneInString(i.opts.Separator)
+	default:
+		return nil, fmt.Errorf("分隔符必须是单个字符: %q", i.opts.Separator)
+	}
+
+	var quote rune
+	if !strings.EqualFold(i.opts.Quote, "none") {
+		if utf8.RuneCountInString(i.opts.Quote) != 1 {
+			return nil, fmt.Errorf("引号必须是单个字符: %q", i.opts.Quote)
+		}
+		quote, _ = utf8.DecodeRuneInString(i.opts.Quote)
+	}
+	if quote == sep {
+		return nil, fmt.Errorf("引号与分隔符不能相同")
+	}
+
+	return newRecordReader(buffered, sep, quote), nil
+}
+
+// TableExists 判断目标表是否存在（有列）
+func TableExists(target *Target) (bool, error) {
+	_, exists, err := lookupColumns(target, true)
+	return exists, err
+}
+
+// lookupColumns 获取目标表的列，表不存在或没有列时 exists 为 false
+func lookupColumns(target *Target, createTable bool) ([]model.ColumnInfo, bool, error) {
+	schema, err := target.Adapter.GetTableSchema(target.DB, target.Database, target.Table)
+	if err != nil {
+		// 部分数据库查询不存在的表会返回错误，建表模式下视为不存在
+		if createTable {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if schema == nil || len(schema.Columns) == 0 {
+		return nil, false, nil
+	}
+	return schema.Columns, true, nil
+}
+
+// bind 将文件列映射到已有表的列，mapping 为空时按列名（byName）或位置匹配
+func bind(sources []string, mapping map[string]string, byName bool, tableColumns []model.ColumnInfo) ([]binding, []string, error) {
+	find := func(name string) *model.ColumnInfo {
+		for n := range tableColumns {
+			if strings.EqualFold(tableColumns[n].Name, name) {
+				return &tableColumns[n]
+			}
+		}
+		return nil
+	}
+
+	var (
+		bindings []binding
+		ignored  []string
+	)
+	for n, source := range sources {
+		var col *model.ColumnInfo
+		switch {
+		case len(mapping) > 0:
+			name, ok := mapping[source]
+			if !ok || name == "" {
+				ignored = append(ignored, source)
+				continue
+			}
+			if col = find(name); col == nil {
+				return nil, nil, fmt.Errorf("映射的表列 %s 不存在", name)
+			}
+		case byName:
+			col = find(source)
+		case n < len(tableColumns):
+			col = &tableColumns[n]
+		}
+		if col == nil {
+			ignored = append(ignored, source)
+			continue
+		}
+		bindings = append(bindings, binding{
+			source: n,
+			column: model.ImportColumn{Source: source, Target: col.Name, Type: col.Type},
+			kind:   columnKind(col.Type),
+		})
+	}
+	if len(bindings) == 0 {
+		return nil, nil, fmt.Errorf("文件列与表列没有可匹配的列，请指定列映射")
+	}
+	return bindings, ignored, nil
+}
+
+// infer 建表时由样本推断列类型，列名取表头（无表头时为 column1、column2…）或映射
+func (i *CSVImporter) infer(sources []string, hasHeader bool, samples []record, dbType model.DatabaseType) ([]binding, []string, error) {
+	var (
+		bindings []binding
+		ignored  []string
+	)
+	for n, source := range sources {
+		name := source
+		if !hasHeader {
+			name = "column" + source
+		}
+		if len(i.opts.Mapping) > 0 {
+			mapped, ok := i.opts.Mapping[source]
+			if !ok || mapped == "" {
+				ignored = append(ignored, source)
+				continue
+			}
+			name = mapped
+		}
+		if name == "" {
+			return nil, nil, fmt.Errorf("第 %d 列的表头为空，请指定列映射", n+1)
+		}
+
+		// 空值与 NULL 不参与推断
+		stats := newColumnStats()
+		for _, rec := range samples {
+			if n >= len(rec.fields) || (i.opts.NullValue != "" && rec.fields[n] == i.opts.NullValue) {
+				continue
+			}
+			if field := strings.TrimSpace(rec.fields[n]); field != "" {
+				stats.add(field)
+			}
+		}
+		kind := stats.kind()
+		def := inferredColumn(dbType, name, kind, stats.maxLen)
+		bindings = append(bindings, binding{
+			source: n,
+			column: model.ImportColumn{Source: source, Target: name, Type: columnTypeName(def)},
+			kind:   kind,
+			def:    def,
+		})
+	}
+	if len(bindings) == 0 {
+		return nil, nil, fmt.Errorf("没有需要导入的列")
+	}
+	return bindings, ignored, nil
+}
+
+// convert 按映射转换一条记录，失败时返回行错误
+func (i *CSVImporter) convert(rec record, bindings []binding) ([]any, *model.ImportRowError) {
+	row := make([]any, len(bindings))
+	for n, b := range bindings {
+		if b.source >= len(rec.fields) {
+			continue
+		}
+		field := rec.fields[b.source]
+		if i.isNull(field, b.kind) {
+			continue
+		}
+		val, err := coerce(b.kind, field)
+		if err != nil {
+			return nil, &model.ImportRowError{Line: rec.line, Column: b.column.Target, Value: field, Error: err.Error()}
+		}
+		row[n] = val
+	}
+	return row, nil
+}
+
+// isNull 字段等于 NULL 表示或非文本列的值为空时视为 NULL
+func (i *CSVImporter) isNull(field string, kind valueKind) bool {
+	if i.opts.NullValue != "" && field == i.opts.NullValue {
+		return true
+	}
+	return kind != kindText && strings.TrimSpace(field) == ""
+}
+
+// detectHeader 识别首行是否为表头
+// 表已存在时首行包含任一表列名即为表头；否则要求首行各值非空、互不相同且都不是数字或日期。
+func detectHeader(first []string, tableColumns []model.ColumnInfo) bool {
+	if len(tableColumns) > 0 {
+		for _, field := range first {
+			for _, col := range tableColumns {
+				if strings.EqualFold(strings.TrimSpace(field), col.Name) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	seen := make(map[string]bool, len(first))
+	for _, field := range first {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			return false
+		}
+		seen[field] = true
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			return false
+		}
+		if _, ok := parseDateTime(field); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// columnTypeName 列定义的完整类型名，如 VARCHAR(64)
+func columnTypeName(def model.ColumnDef) string {
+	switch {
+	case def.Length > 0:
+		return fmt.Sprintf("%s(%d)", def.Type, def.Length)
+	case def.Precision > 0 && def.Scale > 0:
+		return fmt.Sprintf("%s(%d,%d)", def.Type, def.Precision, def.Scale)
+	case def.Precision > 0:
+		return fmt.Sprintf("%s(%d)", def.Type, def.Precision)
+	default:
+		return def.Type
+	}
+}
+
+// isBlank 空行
+func isBlank(fields []string) bool {
+	return len(fields) == 1 && strings.TrimSpace(fields[0]) == ""
+}
+
+// batchWriter 按批写入转换后的行
+type batchWriter struct {
+	ctx      context.Context
+	inserter adapter.BulkInserter
+	target   *Target
+	columns  []string
+	dryRun   bool
+	result   *model.ImportResult
+
+	rows  [][]any
+	lines []int
+}
+
+// flush 写入当前批次，失败时逐行重试并记录失败的行
+func (w *batchWriter) flush() error {
+	rows, lines := w.rows, w.lines
+	w.rows, w.lines = nil, nil
+	if len(rows) == 0 {
+		return nil
+	}
+	if w.dryRun {
+		w.result.Imported += int64(len(rows))
+		return nil
+	}
+
+	n, err := w.insert(rows)
+	w.result.Imported += n
+	if err == nil {
+		return nil
+	}
+	if w.ctx.Err() != nil {
+		return w.ctx.Err()
+	}
+
+	// 已写入的行之后逐行重试
+	for k := int(n); k < len(rows); k++ {
+		n, err := w.insert(rows[k : k+1])
+		w.result.Imported += n
+		if err != nil {
+			if w.ctx.Err() != nil {
+				return w.ctx.Err()
+			}
+			w.fail(model.ImportRowError{Line: lines[k], Error: err.Error()})
+		}
+	}
+	return nil
+}
+
+func (w *batchWriter) insert(rows [][]any) (int64, error) {
+	return w.inserter.InsertRows(w.ctx, w.target.DB, w.target.Database, w.target.Table, w.columns, rows)
+}
+
+// fail 记录失败的行，只保留前 maxReportedErrors 条明细
+func (w *batchWriter) fail(rowErr model.ImportRowError) {
+	w.result.Failed++
+	if len(w.result.Errors) < maxReportedErrors {
+		w.result.Errors = append(w.result.Errors, rowErr)
+	}
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// TestRecordReader 测试引号、转义、跨行字段与自定义引号字符
+func TestRecordReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		sep   rune
+		quote rune
+		want  [][]string
+		lines []int
+	}{
+		{
+			name:  "rfc4180",
+			input: "a,\"b,1\",\"say \"\"hi\"\"\"\r\n\"multi\nline\",,x\n",
+			sep:   ',',
+			quote: '"',
+			want:  [][]string{{"a", "b,1", `say "hi"`}, {"multi\nline", "", "x"}},
+			lines: []int{1, 2},
+		},
+		{
+			name:  "single quote tsv",
+			input: "'a\tb'\tc\nd\t'it''s'",
+			sep:   '\t',
+			quote: '\'',
+			want:  [][]string{{"a\tb", "c"}, {"d", "it's"}},
+			lines: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRecordReader(bufio.NewReader(strings.NewReader(tt.input)), tt.sep, tt.quote)
+			var got [][]string
+			var lines []int
+			for {
+				fields, line, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, fields)
+				lines = append(lines, line)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("lines = %v, want %v", lines, tt.lines)
+			}
+		})
+	}
+
+	r := newRecordReader(bufio.NewReader(strings.NewReader(`"open`)), ',', '"')
+	if _, _, err := r.Read(); err == nil {
+		t.Error("unterminated quote should fail")
+	}
+}
+
+// TestColumnKind 测试列类型到转换类别的识别
+func TestColumnKind(t *testing.T) {
+	tests := map[string]valueKind{
+		"bigint(20) unsigned":      kindInteger,
+		"Nullable(Int64)":          kindInteger,
+		"int32":                    kindInteger,
+		"DECIMAL(10,2)":            kindDecimal,
+		"double precision":         kindFloat,
+		"boolean":                  kindBool,
+		"timestamp with time zone": kindDateTime,
+		"bson.DateTime":            kindDateTime,
+		"bytea":                    kindBinary,
+		"interval":                 kindText,
+		"point":                    kindText,
+		"varchar(255)":             kindText,
+	}
+	for typ, want := range tests {
+		if got := columnKind(typ); got != want {
+			t.Errorf("columnKind(%q) = %d, want %d", typ, got, want)
+		}
+	}
+}
+
+// TestCSVImporter_SQLite 测试导入已有表：表头识别、GBK 编码、类型转换错误与写入失败
+func TestCSVImporter_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "import.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := adapter.NewSQLiteAdapter()
+	if _, err := a.Execute(db, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER, active BOOLEAN)"); err != nil {
+		t.Fatal(err)
+	}
+	target := &Target{Adapter: a, DB: db, DBType: model.DatabaseSQLite, Table: "users"}
+
+	// 第 3 行年龄无效，第 5 行主键重复，第 6 行 name 为 NULL 违反约束
+	csv := "ID;Name;Age;Active;Extra\n1;张三;30;是;x\n2;李四;abc;否;x\n3;王五;;true;x\n1;重复;1;0;x\n4;NULL;1;0;x\n"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 试运行不写入
+	opts := &model.CSVImportOptions{Encoding: "GBK", NullValue: "NULL", DryRun: true, BatchSize: 2}
+	result, err := NewCSVImporter(opts).Import(context.Background(), bytes.NewReader(gbk), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalRows != 5 || result.Imported != 4 || result.Failed != 1 {
+		t.Errorf("dry run = total %d, imported %d, failed %d, want 5, 4, 1", result.TotalRows, result.Imported, result.Failed)
+	}
+	if !reflect.DeepEqual(result.Ignored, []string{"Extra"}) {
+		t.Errorf("Ignored = %v, want [Extra]", result.Ignored)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 0 {
+		t.Fatalf("dry run wrote %d rows, %v", count, err)
+	}
+
+	opts.DryRun = false
+	result, err = NewCSVImporter(opts).Import(context.Background(), bytes.NewReader(gbk), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 2 || result.Failed != 3 {
+		t.Errorf("import = imported %d, failed %d, want 2, 3", result.Imported, result.Failed)
+	}
+	wantLines := []int{3, 5, 6}
+	for n, rowErr := range result.Errors {
+		if rowErr.Line != wantLines[n] {
+			t.Errorf("error %d line = %d, want %d (%s)", n, rowErr.Line, wantLines[n], rowErr.Error)
+		}
+	}
+	if result.Errors[0].Column != "age" || result.Errors[0].Value != "abc" {
+		t.Errorf("coercion error = %+v", result.Errors[0])
+	}
+
+	var name string
+	var age sql.NullInt64
+	if err := db.QueryRow("SELECT name, age FROM users WHERE id = 3").Scan(&name, &age); err != nil || name != "王五" || age.Valid {
+		t.Errorf("row 3 = %q, %v, %v", name, age, err)
+	}
+}
+
+// TestCSVImporter_CreateTable 测试无表头文件按推断类型建表
+func TestCSVImporter_CreateTable(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "create.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := adapter.NewSQLiteAdapter()
+	target := &Target{Adapter: a, DB: db, DBType: model.DatabaseSQLite, Table: "metrics"}
+	data := "1\t1.5\t2024-01-02 03:04:05\thello\n2\t2\t2024-01-03\t\n"
+
+	result, err := NewCSVImporter(&model.CSVImportOptions{CreateTable: true}).Import(context.Background(), strings.NewReader(data), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TableCreated || result.Imported != 2 {
+		t.Fatalf("created = %v, imported = %d", result.TableCreated, result.Imported)
+	}
+
+	wantTypes := []string{"INTEGER", "REAL", "DATETIME", "TEXT"}
+	for n, col := range result.Columns {
+		if col.Target != "column"+col.Source || col.Type != wantTypes[n] {
+			t.Errorf("column %d = %+v, want type %s", n, col, wantTypes[n])
+		}
+	}
+
+	var sum float64
+	if err := db.QueryRow("SELECT SUM(column2) FROM metrics").Scan(&sum); err != nil || sum != 3.5 {
+		t.Errorf("SUM(column2) = %v, %v, want 3.5", sum, err)
+	}
+}
@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"dbm/internal/parquet"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ParquetImporter Parquet 导入器
+type ParquetImporter struct {
+	opts *model.ParquetImportOptions
+}
+
+// NewParquetImporter 创建 Parquet 导入器
+func NewParquetImporter(opts *model.ParquetImportOptions) *ParquetImporter {
+	if opts == nil {
+		opts = &model.ParquetImportOptions{}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	return &ParquetImporter{opts: opts}
+}
+
+// Import 读取 Parquet 文件并写入目标表
+// 文件列按列名（不区分大小写）或映射匹配表列，表不存在时按文件中的列类型建表；
+// 失败行的行号为数据行的序号，从 1 开始。
+func (i *ParquetImporter) Import(ctx context.Context, src io.ReaderAt, size int64, target *Target) (*model.ImportResult, error) {
+	start := time.Now()
+	result := &model.ImportResult{DryRun: i.opts.DryRun}
+
+	inserter, ok := target.Adapter.(adapter.BulkInserter)
+	if !ok {
+		return nil, fmt.Errorf("%s 不支持数据导入", target.DBType)
+	}
+
+	file, err := parquet.Open(src, size)
+	if err != nil {
+		return nil, err
+	}
+	fileColumns := file.Columns()
+	sources := make([]string, len(fileColumns))
+	for n, col := range fileColumns {
+		sources[n] = col.Name
+	}
+
+	tableColumns, exists, err := lookupColumns(target, i.opts.CreateTable)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && !i.opts.CreateTable {
+		return nil, fmt.Errorf("表 %s 不存在，可启用建表选项按文件的列类型建表", target.Table)
+	}
+
+	var bindings []binding
+	if exists {
+		bindings, result.Ignored, err = bind(sources, i.opts.Mapping, true, tableColumns)
+	} else {
+		bindings, result.Ignored, err = i.infer(fileColumns, target.DBType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bindings {
+		result.Columns = append(result.Columns, b.column)
+	}
+
+	if !exists && !i.opts.DryRun {
+		defs := make([]model.ColumnDef, len(bindings))
+		for n, b := range bindings {
+			defs[n] = b.def
+		}
+		if err := inserter.CreateTable(ctx, target.DB, target.Database, target.Table, defs); err != nil {
+			return nil, fmt.Errorf("建表失败: %w", err)
+		}
+		result.TableCreated = true
+	}
+
+	columns := make([]string, len(bindings))
+	for n, b := range bindings {
+		columns[n] = b.column.Target
+	}
+	w := &batchWriter{
+		ctx:      ctx,
+		inserter: inserter,
+		target:   target,
+		columns:  columns,
+		dryRun:   i.opts.DryRun,
+		result:   result,
+	}
+
+	rows := file.Rows()
+	for line := 1; rows.Next(); line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.TotalRows++
+		row, rowErr := convertParquet(rows.Values(), line, bindings, fileColumns)
+		if rowErr != nil {
+			w.fail(*rowErr)
+			continue
+		}
+		w.rows = append(w.rows, row)
+		w.lines = append(w.lines, line)
+		if len(w.rows) >= i.opts.BatchSize {
+			if err := w.flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+
+	result.TimeCost = time.Since(start)
+	return result, nil
+}
+
+// infer 建表时按文件的列类型生成列定义
+func (i *ParquetImporter) infer(fileColumns []parquet.Column, dbType model.DatabaseType) ([]binding, []string, error) {
+	var (
+		bindings []binding
+		ignored  []string
+	)
+	for n, col := range fileColumns {
+		name := col.Name
+		if len(i.opts.Mapping) > 0 {
+			mapped, ok := i.opts.Mapping[col.Name]
+			if !ok || mapped == "" {
+				ignored = append(ignored, col.Name)
+				continue
+			}
+			name = mapped
+		}
+
+		kind := parquetKind(col)
+		def := parquetColumnDef(dbType, name, col, kind)
+		bindings = append(bindings, binding{
+			source: n,
+			column: model.ImportColumn{Source: col.Name, Target: name, Type: columnTypeName(def)},
+			kind:   kind,
+			def:    def,
+		})
+	}
+	if len(bindings) == 0 {
+		return nil, nil, fmt.Errorf("没有需要导入的列")
+	}
+	return bindings, ignored, nil
+}
+
+// parquetKind Parquet 列对应的转换类别
+func parquetKind(col parquet.Column) valueKind {
+	switch {
+	case col.Type == parquet.Boolean:
+		return kindBool
+	case col.Type == parquet.Int96, col.Logical == parquet.LogicalDate, col.Logical == parquet.LogicalTimestamp:
+		return kindDateTime
+	case col.Logical == parquet.LogicalDecimal:
+		return kindDecimal
+	case col.Type == parquet.Float, col.Type == parquet.Double:
+		return kindFloat
+	case col.Logical == parquet.LogicalTime:
+		return kindText
+	case col.Type == parquet.Int32, col.Type == parquet.Int64:
+		return kindInteger
+	case col.Logical == parquet.LogicalNone, col.Logical == parquet.LogicalBSON:
+		return kindBinary
+	}
+	return kindText
+}
+
+// parquetColumnDef 建表时 Parquet 列对应的列定义，DECIMAL 保留精度，二进制列使用各数据库的二进制类型
+func parquetColumnDef(dbType model.DatabaseType, name string, col parquet.Column, kind valueKind) model.ColumnDef {
+	switch kind {
+	case kindDecimal:
+		def := model.ColumnDef{Name: name, Nullable: true, Precision: col.Precision, Scale: col.Scale}
+		def.Type = map[model.DatabaseType]string{
+			model.DatabaseOracle:     "NUMBER",
+			model.DatabaseDM:         "NUMBER",
+			model.DatabasePostgreSQL: "NUMERIC",
+			model.DatabaseKingBase:   "NUMERIC",
+			model.DatabaseSQLite:     "NUMERIC",
+			model.DatabaseClickHouse: "Decimal",
+			model.DatabaseMongoDB:    "decimal",
+		}[dbType]
+		if def.Type == "" {
+			def.Type = "DECIMAL"
+		}
+		return def
+	case kindBinary:
+		def := model.ColumnDef{Name: name, Nullable: true}
+		def.Type = map[model.DatabaseType]string{
+			model.DatabaseMySQL:      "LONGBLOB",
+			model.DatabasePostgreSQL: "BYTEA",
+			model.DatabaseKingBase:   "BYTEA",
+			model.DatabaseMSSQL:      "VARBINARY(MAX)",
+			model.DatabaseClickHouse: "String",
+			model.DatabaseMongoDB:    "binData",
+		}[dbType]
+		if def.Type == "" {
+			def.Type = "BLOB"
+		}
+		return def
+	}
+	// 文本列的长度未知，按大文本建表
+	return inferredColumn(dbType, name, kind, 1<<20)
+}
+
+// convertParquet 按映射转换一行，失败时返回行错误
+func convertParquet(values []any, line int, bindings []binding, fileColumns []parquet.Column) ([]any, *model.ImportRowError) {
+	row := make([]any, len(bindings))
+	for n, b := range bindings {
+		v := values[b.source]
+		if v == nil {
+			continue
+		}
+		val, err := parquetValue(b.kind, v, fileColumns[b.source])
+		if err != nil {
+			return nil, &model.ImportRowError{Line: line, Column: b.column.Target, Value: parquetText(v, fileColumns[b.source]), Error: err.Error()}
+		}
+		row[n] = val
+	}
+	return row, nil
+}
+
+// parquetValue 将读取的值转换为表列的类别，类型一致时直接使用，否则按文本转换
+func parquetValue(kind valueKind, v any, col parquet.Column) (any, error) {
+	if val, ok := convertTyped(kind, v); ok {
+		return val, nil
+	}
+	return coerce(kind, parquetText(v, col))
+}
+
+// parquetText 值的文本形式，DATE 列只保留日期
+func parquetText(v any, col parquet.Column) string {
+	if t, ok := v.(time.Time); ok && col.Logical == parquet.LogicalDate {
+		return t.Format("2006-01-02")
+	}
+	return valueText(v)
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"dbm/internal/parquet"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeParquet 生成测试用的 Parquet 文件
+func writeParquet(t *testing.T, columns []parquet.Column, rows [][]any) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, columns, parquet.WriterOptions{Codec: parquet.Snappy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// TestParquetImporter_CreateTable 测试按文件列类型建表
+func TestParquetImporter_CreateTable(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "parquet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	src := writeParquet(t, []parquet.Column{
+		{Name: "id", Type: parquet.Int64},
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+		{Name: "price", Type: parquet.Int64, Logical: parquet.LogicalDecimal, Precision: 10, Scale: 2},
+		{Name: "created", Type: parquet.Int64, Logical: parquet.LogicalTimestamp, Unit: parquet.Micros, UTC: true},
+		{Name: "payload", Type: parquet.ByteArray},
+		{Name: "active", Type: parquet.Boolean},
+	}, [][]any{
+		{int64(1), "苹果", "12.50", created, []byte{0xff, 0x00}, true},
+		{int64(2), nil, nil, nil, nil, false},
+	})
+
+	a := adapter.NewSQLiteAdapter()
+	target := &Target{Adapter: a, DB: db, DBType: model.DatabaseSQLite, Table: "goods"}
+	result, err := NewParquetImporter(&model.ParquetImportOptions{CreateTable: true}).Import(context.Background(), src, src.Size(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TableCreated || result.Imported != 2 || result.Failed != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	wantTypes := []string{"INTEGER", "TEXT", "NUMERIC(10,2)", "DATETIME", "BLOB", "INTEGER"}
+	for n, col := range result.Columns {
+		if col.Type != wantTypes[n] {
+			t.Errorf("column %s type = %s, want %s", col.Target, col.Type, wantTypes[n])
+		}
+	}
+
+	var (
+		name    string
+		price   float64
+		payload []byte
+		active  bool
+	)
+	if err := db.QueryRow("SELECT name, price, payload, active FROM goods WHERE id = 1").Scan(&name, &price, &payload, &active); err != nil {
+		t.Fatal(err)
+	}
+	if name != "苹果" || price != 12.5 || !bytes.Equal(payload, []byte{0xff, 0x00}) || !active {
+		t.Errorf("row 1 = %q, %v, %x, %v", name, price, payload, active)
+	}
+}
+
+// TestParquetImporter_Mapping 测试导入已有表：列映射、类型转换错误与忽略的列
+func TestParquetImporter_Mapping(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "mapping.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := adapter.NewSQLiteAdapter()
+	if _, err := a.Execute(db, "CREATE TABLE users (id INTEGER PRIMARY KEY, full_name TEXT, age INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	src := writeParquet(t, []parquet.Column{
+		{Name: "ID", Type: parquet.Int32},
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+		{Name: "age", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+		{Name: "extra", Type: parquet.Double},
+	}, [][]any{
+		{int64(1), "张三", "30", 1.5},
+		{int64(2), "李四", "abc", 2.5},
+		{int64(3), "王五", nil, nil},
+	})
+
+	target := &Target{Adapter: a, DB: db, DBType: model.DatabaseSQLite, Table: "users"}
+	opts := &model.ParquetImportOptions{Mapping: map[string]string{"ID": "id", "name": "full_name", "age": "age"}}
+	result, err := NewParquetImporter(opts).Import(context.Background(), src, src.Size(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalRows != 3 || result.Imported != 2 || result.Failed != 1 {
+		t.Fatalf("result = total %d, imported %d, failed %d", result.TotalRows, result.Imported, result.Failed)
+	}
+	if len(result.Ignored) != 1 || result.Ignored[0] != "extra" {
+		t.Errorf("Ignored = %v", result.Ignored)
+	}
+	if e := result.Errors[0]; e.Line != 2 || e.Column != "age" || e.Value != "abc" {
+		t.Errorf("error = %+v", e)
+	}
+
+	var name string
+	if err := db.QueryRow("SELECT full_name FROM users WHERE id = 3").Scan(&name); err != nil || name != "王五" {
+		t.Errorf("row 3 = %q, %v", name, err)
+	}
+}
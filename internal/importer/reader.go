@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// sniffSize 自动识别分隔符时读取的字节数
+const sniffSize = 64 * 1024
+
+// separatorCandidates 自动识别的分隔符
+var separatorCandidates = []rune{',', '\t', ';', '|'}
+
+// decodeReader 按编码将输入转换为 UTF-8，UTF-8 输入去除 BOM
+func decodeReader(r io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToUpper(strings.ReplaceAll(encoding, "-", "")) {
+	case "", "UTF8":
+		return transform.NewReader(r, unicode.UTF8BOM.NewDecoder()), nil
+	case "GBK", "GB2312", "CP936":
+		return transform.NewReader(r, simplifiedchinese.GBK.NewDecoder()), nil
+	case "GB18030":
+		return transform.NewReader(r, simplifiedchinese.GB18030.NewDecoder()), nil
+	default:
+		return nil, fmt.Errorf("不支持的编码: %s", encoding)
+	}
+}
+
+// sniffSeparator 按首行中出现次数最多的候选字符识别分隔符，默认为逗号
+func sniffSeparator(r *bufio.Reader) rune {
+	head, _ := r.Peek(sniffSize)
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+
+	sep, best := ',', 0
+	for _, c := range separatorCandidates {
+		if n := strings.Count(string(head), string(c)); n > best {
+			sep, best = c, n
+		}
+	}
+	return sep
+}
+
+// recordReader 读取分隔符与引号字符可配置的 CSV 记录
+// encoding/csv 只支持双引号，这里按 RFC 4180 的规则处理任意引号字符：
+// 字段以引号开头时为引用字段，可包含分隔符与换行，两个连续引号表示一个引号字符。
+type recordReader struct {
+	r     *bufio.Reader
+	sep   rune
+	quote rune // 0 表示不处理引号
+	line  int  // 下一条记录的起始行号
+}
+
+// newRecordReader 创建记录读取器
+func newRecordReader(r *bufio.Reader, sep, quote rune) *recordReader {
+	return &recordReader{r: r, sep: sep, quote: quote, line: 1}
+}
+
+// Read 读取下一条记录，返回字段与记录的起始行号，没有更多记录时返回 io.EOF
+func (r *recordReader) Read() ([]string, int, error) {
+	line := r.line
+	var (
+		fields  []string
+		field   strings.Builder
+		quoted  bool
+		started bool
+	)
+
+	for {
+		c, _, err := r.r.ReadRune()
+		if err == io.EOF {
+			if quoted {
+				return nil, line, fmt.Errorf("第 %d 行：引号未闭合", line)
+			}
+			if !started {
+				return nil, line, io.EOF
+			}
+			return append(fields, field.String()), line, nil
+		}
+		if err != nil {
+			return nil, line, err
+		}
+		started = true
+
+		if quoted {
+			if c == r.quote {
+				next, _, err := r.r.ReadRune()
+				if err == nil && next == r.quote {
+					field.WriteRune(c)
+					continue
+				}
+				if err == nil {
+					_ = r.r.UnreadRune()
+				}
+				quoted = false
+				continue
+			}
+			if c == '\n' {
+				r.line++
+			}
+			field.WriteRune(c)
+			continue
+		}
+
+		switch {
+		case c == r.quote && r.quote != 0 && field.Len() == 0:
+			quoted = true
+		case c == r.sep:
+			fields = append(fields, field.String())
+			field.Reset()
+		case c == '\r' || c == '\n':
+			if c == '\r' {
+				if next, _, err := r.r.ReadRune(); err == nil && next != '\n' {
+					_ = r.r.UnreadRune()
+				}
+			}
+			r.line++
+			return append(fields, field.String()), line, nil
+		default:
+			field.WriteRune(c)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// AuditAction 审计记录的操作类型
+type AuditAction string
+
+const (
+	// AuditQuery 执行查询
+	AuditQuery AuditAction = "query"
+	// AuditExecute 执行非查询语句
+	AuditExecute AuditAction = "execute"
+	// AuditScript 执行多语句脚本
+	AuditScript AuditAction = "script"
+	// AuditDataEdit 在数据浏览中新增、修改、删除行或写入单元格
+	AuditDataEdit AuditAction = "data"
+	// AuditAlter 修改表结构
+	AuditAlter AuditAction = "alter"
+	// AuditRename 重命名表
+	AuditRename AuditAction = "rename"
+	// AuditExport 导出数据（同步导出与后台导出任务）
+	AuditExport AuditAction = "export"
+	// AuditImport 导入 CSV、Parquet 文件
+	AuditImport AuditAction = "import"
+	// AuditSession 提交、回滚或关闭 SQL 会话
+	AuditSession AuditAction = "session"
+)
+
+// AuditEntry 一条审计记录
+type AuditEntry struct {
+	ID           string        `json:"id"`
+	Time         time.Time     `json:"time"` // 开始执行的时间
+	UserID       string        `json:"userId,omitempty"`
+	Username     string        `json:"username"`
+	ClientIP     string        `json:"clientIp,omitempty"`
+	ConnectionID string        `json:"connectionId"`
+	DatabaseType DatabaseType  `json:"databaseType,omitempty"`
+	Database     string        `json:"database,omitempty"`
+	Action       AuditAction   `json:"action"`
+	Target       string        `json:"target,omitempty"`    // 操作的表或 SQL 会话 ID
+	Statement    string        `json:"statement,omitempty"` // 执行的语句或操作描述，可按配置脱敏字面量
+	Duration     time.Duration `json:"duration"`
+	RowsAffected int64         `json:"rowsAffected"` // 影响、导入或导出的行数
+	Success      bool          `json:"success"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// AuditFilter 审计记录查询条件，零值字段不参与过滤
+type AuditFilter struct {
+	UserID       string
+	Username     string
+	ConnectionID string
+	Database     string
+	Action       AuditAction
+	Success      *bool
+	From         time.Time
+	To           time.Time
+	Search       string // 语句中包含的文本（不区分大小写）
+	Limit        int
+}
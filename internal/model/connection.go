@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"maps"
 	"time"
 )
 
@@ -36,6 +37,100 @@ type ConnectionConfig struct {
 	GroupID           string            `json:"groupId"`           // 所属分组 ID
 	Connected         bool              `json:"connected"`         // 运行时状态：是否已连接
 	MonitoringEnabled bool              `json:"monitoringEnabled"` // 是否启用监控
+	SSH               *SSHConfig        `json:"ssh,omitempty"`     // SSH 隧道配置（敏感字段加密存储）
+	TLS               *TLSConfig        `json:"tls,omitempty"`     // TLS 配置（证书与私钥加密存储）
+}
+
+// Clone 深拷贝连接配置
+func (c *ConnectionConfig) Clone() *ConnectionConfig {
+	clone := *c
+	clone.Params = maps.Clone(c.Params)
+	clone.SSH = c.SSH.Clone()
+	clone.TLS = c.TLS.Clone()
+	return &clone
+}
+
+// Secrets 返回所有敏感字段的指针：数据库密码、SSH 凭据与 TLS 证书
+// 用于统一加密、解密与隐藏。
+func (c *ConnectionConfig) Secrets() []*string {
+	secrets := []*string{&c.Password}
+	if c.SSH != nil {
+		secrets = append(secrets, c.SSH.Secrets()...)
+	}
+	if c.TLS != nil {
+		secrets = append(secrets, c.TLS.Secrets()...)
+	}
+	return secrets
+}
+
+// Masked 返回清空了敏感字段的副本，用于返回给客户端
+func (c *ConnectionConfig) Masked() *ConnectionConfig {
+	masked := c.Clone()
+	for _, secret := range masked.Secrets() {
+		*secret = ""
+	}
+	return masked
+}
+
+// SSHConfig SSH 隧道配置
+// 依次经 Jumps 中的跳板机登录堡垒机，再由堡垒机转发到 Host:Port 所指的数据库，
+// 数据库地址在堡垒机一侧解析。
+type SSHConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Host       string   `json:"host"`       // 堡垒机地址
+	Port       int      `json:"port"`       // 堡垒机端口，默认 22
+	User       string   `json:"user"`       // 登录用户
+	Password   string   `json:"password"`   // 登录密码（加密存储）
+	PrivateKey string   `json:"privateKey"` // PEM 格式私钥（加密存储）
+	Passphrase string   `json:"passphrase"` // 私钥口令（加密存储）
+	Jumps      []SSHHop `json:"jumps"`      // 到达堡垒机前依次经过的跳板机
+
+	KnownHosts            string `json:"knownHosts"`            // known_hosts 内容，为空时使用 ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool   `json:"insecureIgnoreHostKey"` // 跳过主机密钥校验（仅限测试环境）
+}
+
+// SSHHop SSH 跳板机
+type SSHHop struct {
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	User       string `json:"user"`
+	Password   string `json:"password"`   // 加密存储
+	PrivateKey string `json:"privateKey"` // 加密存储
+	Passphrase string `json:"passphrase"` // 加密存储
+}
+
+// Hops 返回按登录顺序排列的所有 SSH 主机，堡垒机在最后
+func (c *SSHConfig) Hops() []SSHHop {
+	hops := make([]SSHHop, 0, len(c.Jumps)+1)
+	hops = append(hops, c.Jumps...)
+	return append(hops, SSHHop{
+		Host:       c.Host,
+		Port:       c.Port,
+		User:       c.User,
+		Password:   c.Password,
+		PrivateKey: c.PrivateKey,
+		Passphrase: c.Passphrase,
+	})
+}
+
+// Clone 深拷贝 SSH 配置
+func (c *SSHConfig) Clone() *SSHConfig {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	clone.Jumps = append([]SSHHop(nil), c.Jumps...)
+	return &clone
+}
+
+// Secrets 返回所有敏感字段的指针
+func (c *SSHConfig) Secrets() []*string {
+	secrets := []*string{&c.Password, &c.PrivateKey, &c.Passphrase}
+	for i := range c.Jumps {
+		hop := &c.Jumps[i]
+		secrets = append(secrets, &hop.Password, &hop.PrivateKey, &hop.Passphrase)
+	}
+	return secrets
 }
 
 // ConnectionParams 序列化参数
@@ -55,3 +150,66 @@ func (c *ConnectionConfig) ParseConnectionParams(params string) error {
 	}
 	return json.Unmarshal([]byte(params), &c.Params)
 }
+
+// TLSMode TLS 模式，取值与 PostgreSQL sslmode 一致
+type TLSMode string
+
+const (
+	TLSDisable    TLSMode = "disable"     // 不使用 TLS
+	TLSRequire    TLSMode = "require"     // 加密传输，不校验服务端证书
+	TLSVerifyCA   TLSMode = "verify-ca"   // 校验服务端证书由受信任的 CA 签发
+	TLSVerifyFull TLSMode = "verify-full" // 校验证书链及证书中的主机名
+)
+
+// TLSConfig 连接的 TLS 配置
+// 证书与私钥为 PEM 格式内容，与密码一样加密存储。
+type TLSConfig struct {
+	Mode       TLSMode `json:"mode"`
+	CACert     string  `json:"caCert"`     // CA 证书，为空时使用系统根证书
+	ClientCert string  `json:"clientCert"` // 客户端证书，服务端要求双向认证时使用
+	ClientKey  string  `json:"clientKey"`  // 客户端私钥
+	ServerName string  `json:"serverName"` // 校验主机名与 SNI 使用的名称，为空时使用连接的主机地址
+}
+
+// Enabled 是否启用 TLS
+func (c *TLSConfig) Enabled() bool {
+	return c != nil && c.Mode != "" && c.Mode != TLSDisable
+}
+
+// Clone 拷贝 TLS 配置
+func (c *TLSConfig) Clone() *TLSConfig {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	return &clone
+}
+
+// Secrets 返回所有 PEM 内容字段的指针
+func (c *TLSConfig) Secrets() []*string {
+	return []*string{&c.CACert, &c.ClientCert, &c.ClientKey}
+}
+
+// TLSInfo 握手协商的 TLS 参数
+type TLSInfo struct {
+	Version     string `json:"version"`     // 协议版本，如 TLS 1.3
+	CipherSuite string `json:"cipherSuite"` // 加密套件
+	ServerName  string `json:"serverName"`  // 校验证书使用的主机名
+	Verified    bool   `json:"verified"`    // 是否校验了服务端证书
+}
+
+// PoolStats 连接池统计信息（对应 sql.DBStats）
+type PoolStats struct {
+	Database           string    `json:"database"`           // 数据库名（空表示连接默认数据库）
+	CreatedAt          time.Time `json:"createdAt"`          // 连接池创建时间
+	LastUsedAt         time.Time `json:"lastUsedAt"`         // 最近一次使用时间
+	MaxOpenConnections int       `json:"maxOpenConnections"` // 最大打开连接数
+	OpenConnections    int       `json:"openConnections"`    // 当前打开连接数
+	InUse              int       `json:"inUse"`              // 使用中的连接数
+	Idle               int       `json:"idle"`               // 空闲连接数
+	WaitCount          int64     `json:"waitCount"`          // 等待连接的总次数
+	WaitDuration       int64     `json:"waitDuration"`       // 等待连接的总时长（毫秒）
+	MaxIdleClosed      int64     `json:"maxIdleClosed"`      // 因超过最大空闲数关闭的连接数
+	MaxIdleTimeClosed  int64     `json:"maxIdleTimeClosed"`  // 因空闲超时关闭的连接数
+	MaxLifetimeClosed  int64     `json:"maxLifetimeClosed"`  // 因超过最大存活时间关闭的连接数
+}
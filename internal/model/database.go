@@ -69,12 +69,53 @@ type ExecuteResult struct {
 
 // QueryResult 查询结果
 type QueryResult struct {
-	Columns      []string                 `json:"columns"`
-	Rows         []map[string]interface{} `json:"rows"`
-	Total        int64                    `json:"total"`
-	RowsAffected int64                    `json:"rowsAffected"`
-	Message      string                   `json:"message"`
-	TimeCost     time.Duration            `json:"timeCost"`
+	Columns     []string        `json:"columns"`
+	ColumnTypes []ColumnMeta    `json:"columnTypes,omitempty"` // 列信息，与 Columns 一一对应
+	Rows        [][]interface{} `json:"rows"`                  // 按列顺序排列的行，重名列互不覆盖
+	// TruncatedCells 超过 MaxValueSize 被截断为预览的单元格，完整值通过单元格接口获取
+	TruncatedCells []TruncatedCell `json:"truncatedCells,omitempty"`
+	Total          int64           `json:"total"`              // 总行数（分页时为有界 COUNT 结果）
+	Page           int             `json:"page,omitempty"`     // 当前页码
+	PageSize       int             `json:"pageSize,omitempty"` // 实际页大小
+	Truncated      bool            `json:"truncated"`          // 结果超过最大行数被截断
+	TotalCapped    bool            `json:"totalCapped"`        // 总行数达到统计上限，实际可能更多
+	RowsAffected   int64           `json:"rowsAffected"`
+	Message        string          `json:"message"`
+	TimeCost       time.Duration   `json:"timeCost"`
+}
+
+// RowMaps 将行转换为列名 → 值的映射，重名列以后出现的为准
+func (r *QueryResult) RowMaps() []map[string]interface{} {
+	maps := make([]map[string]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		m := make(map[string]interface{}, len(r.Columns))
+		for j, col := range r.Columns {
+			if j < len(row) {
+				m[col] = row[j]
+			}
+		}
+		maps[i] = m
+	}
+	return maps
+}
+
+// TruncatedCell 被截断为预览的单元格
+type TruncatedCell struct {
+	Row    int `json:"row"`    // 行下标
+	Column int `json:"column"` // 列下标
+	Size   int `json:"size"`   // 完整值的字节数
+}
+
+// ColumnMeta 结果集列信息，驱动未提供的属性为空
+type ColumnMeta struct {
+	Name      string `json:"name"`
+	DBType    string `json:"dbType"`              // 数据库类型名，如 DECIMAL、VARCHAR
+	ScanType  string `json:"scanType,omitempty"`  // 驱动扫描使用的 Go 类型
+	Nullable  *bool  `json:"nullable,omitempty"`  // 是否可空
+	Length    *int64 `json:"length,omitempty"`    // 变长类型的长度
+	Precision *int64 `json:"precision,omitempty"` // 数值精度
+	Scale     *int64 `json:"scale,omitempty"`     // 小数位数
+	Binary    bool   `json:"binary,omitempty"`    // 二进制列，值为 base64 编码
 }
 
 // QueryOptions 分页查询选项
@@ -84,19 +125,305 @@ type QueryOptions struct {
 	PageSize int    `json:"pageSize"`
 	SortBy   string `json:"sortBy"`
 	SortDesc bool   `json:"sortDesc"`
+	// 以下限制由服务端配置设置，0 表示使用默认值
+	MaxRows      int `json:"-"` // 单次最多返回行数
+	CountLimit   int `json:"-"` // 分页统计总数的上限
+	MaxValueSize int `json:"-"` // 单元格值的最大字节数，超出部分截断为预览
+}
+
+// RowKey 行标识：主键或唯一键列名 → 值
+// 表没有主键时可使用伪列定位：ROWID（Oracle、达梦、SQLite）、ctid（PostgreSQL、KingBase）、_id（MongoDB）。
+type RowKey map[string]interface{}
+
+// ChangeOp 变更集中的操作类型
+type ChangeOp string
+
+const (
+	ChangeInsert ChangeOp = "insert"
+	ChangeUpdate ChangeOp = "update"
+	ChangeDelete ChangeOp = "delete"
+)
+
+// RowChange 变更集中的单行更新
+type RowChange struct {
+	Key  RowKey                 `json:"key"`  // 修改前的行标识
+	Data map[string]interface{} `json:"data"` // 修改的列值
+}
+
+// ChangeSet 表数据变更集
+// 按删除、更新、插入的顺序应用，避免替换行时触发唯一约束冲突。
+type ChangeSet struct {
+	Inserts []map[string]interface{} `json:"inserts"`
+	Updates []RowChange              `json:"updates"`
+	Deletes []RowKey                 `json:"deletes"`
+}
+
+// RowChangeResult 变更集中单行的执行结果
+type RowChangeResult struct {
+	Op           ChangeOp        `json:"op"`
+	Index        int             `json:"index"`     // 在对应操作列表中的下标
+	Statement    string          `json:"statement"` // 生成的语句（参数已内联，仅用于展示）
+	Status       StatementStatus `json:"status"`
+	RowsAffected int64           `json:"rowsAffected"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// ChangeSetResult 变更集执行结果
+type ChangeSetResult struct {
+	Rows         []RowChangeResult `json:"rows"`
+	Committed    bool              `json:"committed"` // 预览或出错回滚时为 false
+	RowsAffected int64             `json:"rowsAffected"`
+	TimeCost     time.Duration     `json:"timeCost"`
+}
+
+// ScriptOptions 脚本执行选项
+type ScriptOptions struct {
+	Database        string `json:"database"`        // 目标数据库
+	ContinueOnError bool   `json:"continueOnError"` // 出错后继续执行后续语句，默认遇错停止
+}
+
+// StatementStatus 脚本中语句的执行状态
+type StatementStatus string
+
+const (
+	StatementSuccess StatementStatus = "success"
+	StatementError   StatementStatus = "error"
+	StatementSkipped StatementStatus = "skipped" // 前面的语句出错或脚本被取消，未执行
+	StatementPending StatementStatus = "pending" // 仅预览，未执行
+)
+
+// StatementResult 脚本中单条语句的执行结果
+type StatementResult struct {
+	Index     int             `json:"index"`
+	Line      int             `json:"line"` // 语句在脚本中的起始行号
+	Statement string          `json:"statement"`
+	Status    StatementStatus `json:"status"`
+	Result    *QueryResult    `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// ScriptResult 脚本执行结果
+type ScriptResult struct {
+	Statements []StatementResult `json:"statements"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Skipped    int               `json:"skipped"`
+	TimeCost   time.Duration     `json:"timeCost"`
 }
 
 // CSVOptions CSV 导出选项
 type CSVOptions struct {
 	IncludeHeader bool   `json:"includeHeader"` // 包含表头
-	Separator     string `json:"separator"`     // 分隔符
+	Separator     string `json:"separator"`     // 分隔符，可为多个字符，\t 表示制表符
 	Quote         string `json:"quote"`         // 引号字符
-	Encoding      string `json:"encoding"`      // 编码
+	Escape        string `json:"escape"`        // 字段中引号的转义字符，为空时写为两个引号
+	QuoteMode     string `json:"quoteMode"`     // 加引号方式：minimal 仅在需要时（默认），all 所有非 NULL 字段
+	LineEnding    string `json:"lineEnding"`    // 换行符：lf（默认）或 crlf
+	Encoding      string `json:"encoding"`      // 编码：UTF-8（带 BOM）、GBK、GB18030、UTF-16、UTF-16LE、UTF-16BE、Latin-1
 	NullValue     string `json:"nullValue"`     // NULL 值表示
-	DateFormat    string `json:"dateFormat"`    // 日期格式
+	DateFormat    string `json:"dateFormat"`    // 时间值的格式（Go 时间格式）
+	DecimalPoint  string `json:"decimalPoint"`  // 数值的小数点，默认为 .
+	TrueValue     string `json:"trueValue"`     // 布尔真值的文本，默认为 1
+	FalseValue    string `json:"falseValue"`    // 布尔假值的文本，默认为 0
 	MaxRows       int    `json:"maxRows"`       // 最大行数 (0表示无限制)
 }
 
+// CSVImportOptions CSV/TSV 导入选项
+type CSVImportOptions struct {
+	// Header 首行是否为表头，为空时自动识别
+	Header *bool `json:"header,omitempty"`
+	// Separator 分隔符，为空时从 , \t ; | 中自动识别
+	Separator string `json:"separator"`
+	// Quote 引号字符，默认 "
+	Quote string `json:"quote"`
+	// Encoding 文件编码：UTF-8（默认，自动去除 BOM）、GBK、GB18030
+	Encoding string `json:"encoding"`
+	// NullValue 表示 NULL 的字符串；非文本列的空值也视为 NULL
+	NullValue string `json:"nullValue"`
+	// Mapping 文件列 → 表列，无表头时文件列为从 1 开始的序号；为空时按列名匹配（无表头时按位置），映射为空字符串的列不导入
+	Mapping map[string]string `json:"mapping,omitempty"`
+	// CreateTable 表不存在时按推断的列类型建表
+	CreateTable bool `json:"createTable"`
+	// DryRun 只解析与转换数据，不写入
+	DryRun bool `json:"dryRun"`
+	// BatchSize 每批写入行数，默认 1000
+	BatchSize int `json:"batchSize"`
+}
+
+// ParquetImportOptions Parquet 导入选项
+type ParquetImportOptions struct {
+	// Mapping 文件列 → 表列；为空时按列名匹配（不区分大小写），映射为空字符串的列不导入
+	Mapping map[string]string `json:"mapping,omitempty"`
+	// CreateTable 表不存在时按文件中的列类型建表
+	CreateTable bool `json:"createTable"`
+	// DryRun 只读取与转换数据，不写入
+	DryRun bool `json:"dryRun"`
+	// BatchSize 每批写入行数，默认 1000
+	BatchSize int `json:"batchSize"`
+}
+
+// ImportColumn 导入的列映射
+type ImportColumn struct {
+	Source string `json:"source"` // 文件列
+	Target string `json:"target"` // 表列
+	Type   string `json:"type"`   // 表列类型，建表时为推断的类型
+}
+
+// ImportRowError 导入失败的行
+type ImportRowError struct {
+	Line   int    `json:"line"`             // 行在文件中的起始行号
+	Column string `json:"column,omitempty"` // 转换失败的表列，写入失败时为空
+	Value  string `json:"value,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Columns      []ImportColumn   `json:"columns"`
+	Ignored      []string         `json:"ignored,omitempty"` // 未映射到表列的文件列
+	TableCreated bool             `json:"tableCreated"`
+	TotalRows    int              `json:"totalRows"` // 读取的数据行数
+	Imported     int64            `json:"imported"`  // 写入的行数，试运行时为可写入的行数
+	Failed       int              `json:"failed"`
+	Errors       []ImportRowError `json:"errors,omitempty"` // 失败行明细，最多返回前若干条
+	DryRun       bool             `json:"dryRun"`
+	TimeCost     time.Duration    `json:"timeCost"`
+}
+
+// RestoreOptions SQL 文件导入选项
+type RestoreOptions struct {
+	Database        string `json:"database"`        // 目标数据库
+	ContinueOnError bool   `json:"continueOnError"` // 出错后跳过该语句继续执行，默认遇错停止
+	Transaction     bool   `json:"transaction"`     // 在单个事务中执行，失败时整体回滚（仅支持事务性 DDL 的数据库）
+}
+
+// RestoreStatus SQL 文件导入任务状态
+type RestoreStatus string
+
+const (
+	RestoreRunning   RestoreStatus = "running"
+	RestoreCompleted RestoreStatus = "completed"
+	RestoreFailed    RestoreStatus = "failed"
+	RestoreCancelled RestoreStatus = "cancelled"
+)
+
+// RestoreError 导入时执行失败的语句
+type RestoreError struct {
+	Line      int    `json:"line"`      // 语句在文件中的起始行号
+	Statement string `json:"statement"` // 语句文本，过长时截断
+	Error     string `json:"error"`
+}
+
+// RestoreJob SQL 文件导入任务进度
+type RestoreJob struct {
+	ID           string         `json:"id"`
+	ConnectionID string         `json:"connectionId"`
+	Database     string         `json:"database"`
+	FileName     string         `json:"fileName"`
+	Status       RestoreStatus  `json:"status"`
+	Transaction  bool           `json:"transaction"`
+	TotalBytes   int64          `json:"totalBytes"` // 上传文件大小（gzip 文件为压缩后大小）
+	ReadBytes    int64          `json:"readBytes"`  // 已读取的文件字节数
+	Line         int            `json:"line"`       // 当前执行语句的起始行号
+	Statements   int            `json:"statements"` // 已执行的语句数
+	Failed       int            `json:"failed"`
+	Errors       []RestoreError `json:"errors,omitempty"` // 失败语句明细，最多保留前若干条
+	Error        string         `json:"error,omitempty"`  // 任务终止原因
+	StartedAt    time.Time      `json:"startedAt"`
+	FinishedAt   *time.Time     `json:"finishedAt,omitempty"`
+}
+
+// MigrationOptions 跨连接迁移选项
+type MigrationOptions struct {
+	SourceDatabase string            `json:"sourceDatabase"`        // 源数据库
+	Tables         []string          `json:"tables"`                // 要迁移的表
+	TargetID       string            `json:"targetId"`              // 目标连接 ID
+	TargetDatabase string            `json:"targetDatabase"`        // 目标数据库
+	TypeChoices    map[string]string `json:"typeChoices,omitempty"` // 需要用户选择的源类型 → 选择的目标类型
+	CreateTables   bool              `json:"createTables"`          // 目标表不存在时按映射后的类型建表
+	BatchSize      int               `json:"batchSize"`             // 每批写入的行数，默认 1000
+}
+
+// MigrationColumn 迁移时源列与目标列类型的对应
+type MigrationColumn struct {
+	Name       string `json:"name"`
+	SourceType string `json:"sourceType"`
+	TargetType string `json:"targetType"`
+	Nullable   bool   `json:"nullable"`
+}
+
+// MigrationTablePlan 单个表的迁移计划
+type MigrationTablePlan struct {
+	Table   string            `json:"table"`
+	Exists  bool              `json:"exists"` // 目标表已存在，数据追加到已有表
+	Columns []MigrationColumn `json:"columns"`
+}
+
+// MigrationStatus 迁移任务与表的状态
+type MigrationStatus string
+
+const (
+	MigrationPending   MigrationStatus = "pending"
+	MigrationRunning   MigrationStatus = "running"
+	MigrationCompleted MigrationStatus = "completed"
+	MigrationFailed    MigrationStatus = "failed"
+	MigrationCancelled MigrationStatus = "cancelled"
+)
+
+// MigrationTable 单个表的迁移进度，Copied 即断点：恢复任务时跳过源表中已写入的行
+type MigrationTable struct {
+	Table      string          `json:"table"`
+	Status     MigrationStatus `json:"status"`
+	Created    bool            `json:"created"`           // 目标表由迁移任务创建
+	SourceRows int64           `json:"sourceRows"`        // 开始复制时源表的行数
+	Copied     int64           `json:"copied"`            // 已提交到目标表的行数
+	BaseRows   int64           `json:"baseRows"`          // 迁移前目标表已有的行数
+	TargetRows int64           `json:"targetRows"`        // 复制完成后目标表的行数
+	Verified   bool            `json:"verified"`          // 目标表新增行数与源表行数一致
+	Ignored    []string        `json:"ignored,omitempty"` // 目标表中不存在而忽略的列
+	Error      string          `json:"error,omitempty"`
+}
+
+// MigrationJob 跨连接迁移任务进度
+type MigrationJob struct {
+	ID             string           `json:"id"`
+	ConnectionID   string           `json:"connectionId"` // 源连接 ID
+	SourceDatabase string           `json:"sourceDatabase"`
+	TargetID       string           `json:"targetId"`
+	TargetDatabase string           `json:"targetDatabase"`
+	Status         MigrationStatus  `json:"status"`
+	Tables         []MigrationTable `json:"tables"`
+	Error          string           `json:"error,omitempty"` // 任务终止原因
+	StartedAt      time.Time        `json:"startedAt"`
+	FinishedAt     *time.Time       `json:"finishedAt,omitempty"`
+}
+
+// ExportStatus 后台导出任务状态
+type ExportStatus string
+
+const (
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed"
+	ExportFailed    ExportStatus = "failed"
+	ExportCancelled ExportStatus = "cancelled"
+)
+
+// ExportJob 后台导出任务进度
+type ExportJob struct {
+	ID           string       `json:"id"`
+	ConnectionID string       `json:"connectionId"`
+	Database     string       `json:"database"`
+	Format       string       `json:"format"`   // csv、sql、json 等导出格式
+	FileName     string       `json:"fileName"` // 下载时的文件名
+	Status       ExportStatus `json:"status"`
+	Rows         int64        `json:"rows"`            // 已写出的数据行数
+	Bytes        int64        `json:"bytes"`           // 已写出的文件字节数
+	Error        string       `json:"error,omitempty"` // 任务终止原因
+	StartedAt    time.Time    `json:"startedAt"`
+	FinishedAt   *time.Time   `json:"finishedAt,omitempty"`
+	ExpiresAt    *time.Time   `json:"expiresAt,omitempty"` // 任务与文件的清除时间
+}
+
 // SQLOptions SQL 导出选项
 type SQLOptions struct {
 	IncludeCreateTable bool   `json:"includeCreateTable"` // 包含建表语句
@@ -107,6 +434,42 @@ type SQLOptions struct {
 	MaxRows            int    `json:"maxRows"`            // 最大行数 (0表示无限制)
 	Query              string `json:"query"`              // 自定义查询 SQL
 	TableName          string `json:"tableName"`          // 自定义查询时的表名（用于 INSERT 语句）
+
+	TargetType  DatabaseType      `json:"targetType"`  // 目标数据库类型，为空时与源数据库相同
+	TypeChoices map[string]string `json:"typeChoices"` // 需要用户选择的源类型 → 目标类型
+}
+
+// JSONOptions JSON 与 NDJSON 导出选项
+type JSONOptions struct {
+	Pretty  bool `json:"pretty"`  // 缩进格式化（仅 JSON 数组）
+	MaxRows int  `json:"maxRows"` // 每个表的最大行数 (0表示无限制)
+}
+
+// XLSXOptions Excel 导出选项
+type XLSXOptions struct {
+	IncludeHeader bool   `json:"includeHeader"` // 包含表头
+	DateFormat    string `json:"dateFormat"`    // 日期单元格的 Excel 数字格式，默认 yyyy-mm-dd hh:mm:ss
+	MaxRows       int    `json:"maxRows"`       // 每个工作表的最大行数 (0表示无限制)
+}
+
+// MarkdownOptions Markdown 表格导出选项
+type MarkdownOptions struct {
+	NullValue string `json:"nullValue"` // NULL 值表示
+	MaxRows   int    `json:"maxRows"`   // 每个表的最大行数 (0表示无限制)
+}
+
+// HTMLOptions HTML 表格导出选项
+type HTMLOptions struct {
+	Title     string `json:"title"`     // 页面标题
+	NullValue string `json:"nullValue"` // NULL 值表示
+	MaxRows   int    `json:"maxRows"`   // 每个表的最大行数 (0表示无限制)
+}
+
+// ParquetOptions Parquet 导出选项
+type ParquetOptions struct {
+	RowGroupSize int    `json:"rowGroupSize"` // 每个行组的行数，默认 100000
+	Compression  string `json:"compression"`  // 压缩算法：snappy（默认）、zstd、gzip、none
+	MaxRows      int    `json:"maxRows"`      // 最大行数 (0表示无限制)
 }
 
 // AlterTableRequest 修改表结构请求
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Permission 连接上的操作权限
+type Permission string
+
+const (
+	// PermMetadata 查看库、表、视图等元数据，连接与测试连接
+	PermMetadata Permission = "metadata"
+	// PermSelect 执行查询语句
+	PermSelect Permission = "select"
+	// PermDML 执行 INSERT、UPDATE、DELETE 等修改数据的语句
+	PermDML Permission = "dml"
+	// PermDDL 执行 CREATE、ALTER、DROP 等修改结构的语句，修改与重命名表
+	PermDDL Permission = "ddl"
+	// PermEdit 在数据浏览中编辑数据、导入数据
+	PermEdit Permission = "edit"
+	// PermExport 导出数据，作为迁移的源连接
+	PermExport Permission = "export"
+	// PermCredentials 查看解密后的连接详情（密码、SSH 凭据、TLS 私钥）
+	PermCredentials Permission = "credentials"
+)
+
+// AllPermissions 所有权限
+var AllPermissions = []Permission{PermMetadata, PermSelect, PermDML, PermDDL, PermEdit, PermExport, PermCredentials}
+
+// Valid 是否为已知权限
+func (p Permission) Valid() bool {
+	return slices.Contains(AllPermissions, p)
+}
+
+// Role 权限角色：授权时引用的一组权限
+type Role struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+	Builtin     bool         `json:"builtin"` // 内置角色不可修改或删除
+}
+
+// BuiltinRoles 内置角色
+func BuiltinRoles() []*Role {
+	return []*Role{
+		{Name: "readonly", Description: "只读：查看元数据与执行查询", Builtin: true,
+			Permissions: []Permission{PermMetadata, PermSelect}},
+		{Name: "analyst", Description: "分析：只读并可导出数据", Builtin: true,
+			Permissions: []Permission{PermMetadata, PermSelect, PermExport}},
+		{Name: "developer", Description: "开发：读写数据，不可修改表结构", Builtin: true,
+			Permissions: []Permission{PermMetadata, PermSelect, PermDML, PermEdit, PermExport}},
+		{Name: "owner", Description: "所有者：全部权限，包括修改表结构与查看连接凭据", Builtin: true,
+			Permissions: slices.Clone(AllPermissions)},
+	}
+}
+
+// Grant 授予用户在连接或分组上的角色
+// ConnectionID 与 GroupID 均为空时对所有连接生效；分组授权包含子分组下的连接。
+type Grant struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"userId"`
+	ConnectionID string    `json:"connectionId,omitempty"`
+	GroupID      string    `json:"groupId,omitempty"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// UserRole 用户角色
+type UserRole string
+
+const (
+	// RoleAdmin 管理员：管理用户，启用调试路由时可访问 /debug/pprof
+	RoleAdmin UserRole = "admin"
+	// RoleUser 普通用户
+	RoleUser UserRole = "user"
+)
+
+// Valid 是否为已知角色
+func (r UserRole) Valid() bool {
+	return r == RoleAdmin || r == RoleUser
+}
+
+// User Web 界面用户
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Role         UserRole  `json:"role"`
+	PasswordHash string    `json:"passwordHash,omitempty"` // argon2id 哈希，接口响应中不返回
+	Disabled     bool      `json:"disabled"`               // 禁用后无法登录，已登录的会话立即失效
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Masked 返回不含密码哈希的副本
+func (u *User) Masked() *User {
+	masked := *u
+	masked.PasswordHash = ""
+	return &masked
+}
@@ -151,8 +151,26 @@ func (c *Collector) scrapeConnection(ctx context.Context, ch chan<- prometheus.M
 		[]string{"connection_id", "connection_name", "db_type", "host", "port"}, nil,
 	)
 
-	// 创建新的数据库连接（不使用连接池）
-	db, err := c.createConnection(config)
+	// 创建新的数据库连接（不使用连接池），启用 SSH 隧道时与连接池共用隧道
+	tunnel, release, err := c.connMgr.AcquireTunnel(config)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(
+			upDesc,
+			prometheus.GaugeValue,
+			0,
+			config.ID, config.Name, dbType, config.Host, fmt.Sprint(config.Port),
+		)
+		c.sendScrapeFailure(ch, config, fmt.Sprintf("SSH tunnel failed: %v", err))
+		return
+	}
+	defer release()
+
+	target := config
+	if tunnel != nil {
+		host, port := tunnel.Addr()
+		target = adapter.Tunneled(config, host, port)
+	}
+	db, err := c.createConnection(target)
 	if err != nil {
 		// 连接失败，记录 up=0
 		ch <- prometheus.MustNewConstMetric(
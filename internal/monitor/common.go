@@ -91,7 +91,7 @@ func QueryAndParse(ctx context.Context, adp adapter.DatabaseAdapter, db any, par
 		return fmt.Errorf("query failed: %w", err)
 	}
 
-	for _, row := range result.Rows {
+	for _, row := range result.RowMaps() {
 		// 将 map[string]interface{} 转换为 map[string]string
 		rowMap := make(map[string]string)
 		for k, v := range row {
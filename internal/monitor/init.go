@@ -17,6 +17,7 @@ var dbTypeConfigFile = map[string]string{
 	"dm":         "dm.yaml",
 	"kingbase":   "kingbase.yaml",
 	"mongodb":    "mongodb.yaml",
+	"mssql":      "mssql.yaml",
 }
 
 // metricsConfigsCache 缓存已加载的指标配置
@@ -0,0 +1,238 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+var errCorrupt = errors.New("parquet: 数据页已损坏")
+
+// appendLevels 以 RLE 编码写出位宽为 1 的定义级别
+// 每段连续相同的值写为一个 RLE 段：头部为 长度<<1 的变长整数，随后一个字节的值。
+func appendLevels(dst []byte, levels []byte) []byte {
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		dst = binary.AppendUvarint(dst, uint64(j-i)<<1)
+		dst = append(dst, levels[i])
+		i = j
+	}
+	return dst
+}
+
+// decodeHybrid 解码 RLE 与位打包混合编码的 n 个值
+func decodeHybrid(data []byte, bitWidth, n int) ([]uint32, error) {
+	if bitWidth > 32 {
+		return nil, errCorrupt
+	}
+	values := make([]uint32, 0, n)
+	byteWidth := (bitWidth + 7) / 8
+	for len(values) < n {
+		header, k := binary.Uvarint(data)
+		if k <= 0 {
+			return nil, errCorrupt
+		}
+		data = data[k:]
+
+		if header&1 == 0 {
+			// RLE 段
+			count := int(header >> 1)
+			if len(data) < byteWidth {
+				return nil, errCorrupt
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(data[i]) << (8 * i)
+			}
+			data = data[byteWidth:]
+			for i := 0; i < count && len(values) < n; i++ {
+				values = append(values, v)
+			}
+			continue
+		}
+
+		// 位打包段：每组 8 个值，低位在前
+		groups := int(header >> 1)
+		size := groups * bitWidth
+		if len(data) < size {
+			return nil, errCorrupt
+		}
+		var acc uint64
+		bits := 0
+		mask := uint64(1)<<bitWidth - 1
+		for _, b := range data[:size] {
+			acc |= uint64(b) << bits
+			bits += 8
+			for bits >= bitWidth && bitWidth > 0 {
+				if len(values) < n {
+					values = append(values, uint32(acc&mask))
+				}
+				acc >>= bitWidth
+				bits -= bitWidth
+			}
+		}
+		if bitWidth == 0 {
+			for i := 0; i < groups*8 && len(values) < n; i++ {
+				values = append(values, 0)
+			}
+		}
+		data = data[size:]
+	}
+	return values, nil
+}
+
+// appendPlain 以 PLAIN 编码追加一个非空的值，布尔值由调用方单独按位打包
+func appendPlain(dst []byte, col *Column, v any) []byte {
+	switch val := v.(type) {
+	case int32:
+		return binary.LittleEndian.AppendUint32(dst, uint32(val))
+	case int64:
+		return binary.LittleEndian.AppendUint64(dst, uint64(val))
+	case float32:
+		return binary.LittleEndian.AppendUint32(dst, math.Float32bits(val))
+	case float64:
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(val))
+	case []byte:
+		if col.Type == ByteArray {
+			dst = binary.LittleEndian.AppendUint32(dst, uint32(len(val)))
+		}
+		return append(dst, val...)
+	}
+	return dst
+}
+
+// packBools 按位打包布尔值，低位在前
+func packBools(values []bool) []byte {
+	out := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+// decodePlain 解码 n 个 PLAIN 编码的值
+func decodePlain(data []byte, col *Column, n int) ([]any, error) {
+	values := make([]any, n)
+	switch col.Type {
+	case Boolean:
+		if len(data)*8 < n {
+			return nil, errCorrupt
+		}
+		for i := range values {
+			values[i] = data[i/8]&(1<<(i%8)) != 0
+		}
+	case Int32, Float:
+		if len(data) < 4*n {
+			return nil, errCorrupt
+		}
+		for i := range values {
+			u := binary.LittleEndian.Uint32(data[4*i:])
+			if col.Type == Int32 {
+				values[i] = int32(u)
+			} else {
+				values[i] = math.Float32frombits(u)
+			}
+		}
+	case Int64, Double:
+		if len(data) < 8*n {
+			return nil, errCorrupt
+		}
+		for i := range values {
+			u := binary.LittleEndian.Uint64(data[8*i:])
+			if col.Type == Int64 {
+				values[i] = int64(u)
+			} else {
+				values[i] = math.Float64frombits(u)
+			}
+		}
+	case Int96, FixedLenByteArray:
+		size := col.Length
+		if col.Type == Int96 {
+			size = 12
+		}
+		if size <= 0 || len(data) < size*n {
+			return nil, errCorrupt
+		}
+		for i := range values {
+			values[i] = data[size*i : size*(i+1)]
+		}
+	case ByteArray:
+		for i := range values {
+			if len(data) < 4 {
+				return nil, errCorrupt
+			}
+			size := int(binary.LittleEndian.Uint32(data))
+			if size < 0 || len(data)-4 < size {
+				return nil, errCorrupt
+			}
+			values[i] = data[4 : 4+size]
+			data = data[4+size:]
+		}
+	default:
+		return nil, fmt.Errorf("parquet: 不支持的物理类型 %s", col.Type)
+	}
+	return values, nil
+}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+	gzipPool       = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+)
+
+// compress 按压缩算法压缩页数据
+func compress(codec Codec, data []byte) ([]byte, error) {
+	switch codec {
+	case Uncompressed:
+		return data, nil
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	case Zstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzipPool.Get().(*gzip.Writer)
+		defer gzipPool.Put(w)
+		w.Reset(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("parquet: 不支持的压缩算法 %d", codec)
+}
+
+// decompress 解压页数据
+func decompress(codec Codec, data []byte) ([]byte, error) {
+	switch codec {
+	case Uncompressed:
+		return data, nil
+	case Snappy:
+		return snappy.Decode(nil, data)
+	case Zstd:
+		return zstdDecoder.DecodeAll(data, nil)
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	}
+	return nil, fmt.Errorf("parquet: 不支持的压缩算法 %d（仅支持 snappy、gzip、zstd）", codec)
+}
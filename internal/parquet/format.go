@@ -0,0 +1,374 @@
+// Package parquet 实现 Apache Parquet 文件的读写
+// 只支持扁平（非嵌套）的列，满足表数据导出与导入的需要。
+package parquet
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// magic Parquet 文件头尾的标识
+const magic = "PAR1"
+
+// Type 物理类型
+type Type int32
+
+const (
+	Boolean           Type = 0
+	Int32             Type = 1
+	Int64             Type = 2
+	Int96             Type = 3
+	Float             Type = 4
+	Double            Type = 5
+	ByteArray         Type = 6
+	FixedLenByteArray Type = 7
+)
+
+var typeNames = map[Type]string{
+	Boolean:           "BOOLEAN",
+	Int32:             "INT32",
+	Int64:             "INT64",
+	Int96:             "INT96",
+	Float:             "FLOAT",
+	Double:            "DOUBLE",
+	ByteArray:         "BYTE_ARRAY",
+	FixedLenByteArray: "FIXED_LEN_BYTE_ARRAY",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Type(%d)", int32(t))
+}
+
+// Logical 逻辑类型
+type Logical int
+
+const (
+	LogicalNone Logical = iota
+	LogicalString
+	LogicalEnum
+	LogicalJSON
+	LogicalBSON
+	LogicalUUID
+	LogicalDate
+	LogicalTime
+	LogicalTimestamp
+	LogicalDecimal
+	LogicalInteger
+)
+
+// TimeUnit TIME 与 TIMESTAMP 的时间单位
+type TimeUnit int
+
+const (
+	Millis TimeUnit = iota + 1
+	Micros
+	Nanos
+)
+
+// Codec 压缩算法
+type Codec int32
+
+const (
+	Uncompressed Codec = 0
+	Snappy       Codec = 1
+	Gzip         Codec = 2
+	Zstd         Codec = 6
+)
+
+// ParseCodec 解析压缩算法名称，空字符串为 snappy
+func ParseCodec(name string) (Codec, error) {
+	switch strings.ToLower(name) {
+	case "", "snappy":
+		return Snappy, nil
+	case "none", "uncompressed":
+		return Uncompressed, nil
+	case "gzip":
+		return Gzip, nil
+	case "zstd":
+		return Zstd, nil
+	}
+	return 0, fmt.Errorf("不支持的压缩算法: %s", name)
+}
+
+// 编码方式
+const (
+	encPlain         = 0
+	encPlainDict     = 2
+	encRLE           = 3
+	encBitPacked     = 4
+	encRLEDictionary = 8
+)
+
+// 字段的重复类型
+const (
+	repRequired int32 = 0
+	repOptional int32 = 1
+	repRepeated int32 = 2
+)
+
+// 页类型
+const (
+	pageData       = 0
+	pageIndex      = 1
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+// 旧版本的 ConvertedType，写出时同时设置以兼容只识别它的读取器
+const (
+	convertedUTF8            = 0
+	convertedEnum            = 4
+	convertedDecimal         = 5
+	convertedDate            = 6
+	convertedTimeMillis      = 7
+	convertedTimeMicros      = 8
+	convertedTimestampMillis = 9
+	convertedTimestampMicros = 10
+	convertedUint8           = 11
+	convertedInt8            = 15
+	convertedJSON            = 19
+	convertedBSON            = 20
+)
+
+// Column 扁平列定义
+type Column struct {
+	Name string
+	Type Type
+	// Length FIXED_LEN_BYTE_ARRAY 的字节数
+	Length  int
+	Logical Logical
+	// Precision 与 Scale 为 DECIMAL 的精度与小数位数
+	Precision int
+	Scale     int
+	// Unit 与 UTC 为 TIME、TIMESTAMP 的时间单位与是否为 UTC 时间
+	Unit TimeUnit
+	UTC  bool
+	// BitWidth 与 Signed 为 INTEGER 的位数与是否有符号
+	BitWidth int
+	Signed   bool
+	// Required 列不可为空
+	Required bool
+}
+
+// String 返回列类型的描述，如 INT64 TIMESTAMP(MICROS)
+func (c Column) String() string {
+	s := c.Type.String()
+	if c.Type == FixedLenByteArray {
+		s += fmt.Sprintf("(%d)", c.Length)
+	}
+	switch c.Logical {
+	case LogicalString:
+		s += " STRING"
+	case LogicalEnum:
+		s += " ENUM"
+	case LogicalJSON:
+		s += " JSON"
+	case LogicalBSON:
+		s += " BSON"
+	case LogicalUUID:
+		s += " UUID"
+	case LogicalDate:
+		s += " DATE"
+	case LogicalTime:
+		s += " TIME(" + c.Unit.String() + ")"
+	case LogicalTimestamp:
+		s += " TIMESTAMP(" + c.Unit.String() + ")"
+	case LogicalDecimal:
+		s += fmt.Sprintf(" DECIMAL(%d,%d)", c.Precision, c.Scale)
+	case LogicalInteger:
+		if c.Signed {
+			s += fmt.Sprintf(" INT(%d)", c.BitWidth)
+		} else {
+			s += fmt.Sprintf(" UINT(%d)", c.BitWidth)
+		}
+	}
+	return s
+}
+
+func (u TimeUnit) String() string {
+	switch u {
+	case Millis:
+		return "MILLIS"
+	case Nanos:
+		return "NANOS"
+	default:
+		return "MICROS"
+	}
+}
+
+// DecimalLength 以 FIXED_LEN_BYTE_ARRAY 存储指定精度的 DECIMAL 所需的最少字节数
+func DecimalLength(precision int) int {
+	one := big.NewInt(1)
+	for n := 1; ; n++ {
+		// n 字节有符号整数的最大值为 2^(8n-1)-1，能够完整表示的十进制位数比它的位数少一位
+		limit := new(big.Int).Lsh(one, uint(8*n-1))
+		if len(limit.Sub(limit, one).String())-1 >= precision {
+			return n
+		}
+	}
+}
+
+// schemaElement 编码列定义
+func (c Column) schemaElement() tstruct {
+	rep := repOptional
+	if c.Required {
+		rep = repRequired
+	}
+
+	s := tstruct{{1, int32(c.Type)}}
+	if c.Type == FixedLenByteArray {
+		s = append(s, field{2, int32(c.Length)})
+	}
+	s = append(s, field{3, rep}, field{4, c.Name})
+
+	var converted any
+	var logical tstruct
+	timeUnit := func() tstruct {
+		return tstruct{{int16(c.Unit), tstruct{}}}
+	}
+	switch c.Logical {
+	case LogicalString:
+		converted, logical = int32(convertedUTF8), tstruct{{1, tstruct{}}}
+	case LogicalEnum:
+		converted, logical = int32(convertedEnum), tstruct{{4, tstruct{}}}
+	case LogicalDecimal:
+		converted = int32(convertedDecimal)
+		logical = tstruct{{5, tstruct{{1, int32(c.Scale)}, {2, int32(c.Precision)}}}}
+	case LogicalDate:
+		converted, logical = int32(convertedDate), tstruct{{6, tstruct{}}}
+	case LogicalTime:
+		if c.UTC && c.Unit != Nanos {
+			converted = int32(convertedTimeMillis + int(c.Unit) - int(Millis))
+		}
+		logical = tstruct{{7, tstruct{{1, c.UTC}, {2, timeUnit()}}}}
+	case LogicalTimestamp:
+		if c.UTC && c.Unit != Nanos {
+			converted = int32(convertedTimestampMillis + int(c.Unit) - int(Millis))
+		}
+		logical = tstruct{{8, tstruct{{1, c.UTC}, {2, timeUnit()}}}}
+	case LogicalInteger:
+		base := convertedUint8
+		if c.Signed {
+			base = convertedInt8
+		}
+		converted = int32(base + bitIndex(c.BitWidth))
+		logical = tstruct{{10, tstruct{{1, int8(c.BitWidth)}, {2, c.Signed}}}}
+	case LogicalJSON:
+		converted, logical = int32(convertedJSON), tstruct{{12, tstruct{}}}
+	case LogicalBSON:
+		converted, logical = int32(convertedBSON), tstruct{{13, tstruct{}}}
+	case LogicalUUID:
+		logical = tstruct{{14, tstruct{}}}
+	}
+
+	if converted != nil {
+		s = append(s, field{6, converted})
+	}
+	if c.Logical == LogicalDecimal {
+		s = append(s, field{7, int32(c.Scale)}, field{8, int32(c.Precision)})
+	}
+	if logical != nil {
+		s = append(s, field{10, logical})
+	}
+	return s
+}
+
+// bitIndex 8、16、32、64 位对应的 ConvertedType 偏移
+func bitIndex(bits int) int {
+	switch bits {
+	case 16:
+		return 1
+	case 32:
+		return 2
+	case 64:
+		return 3
+	}
+	return 0
+}
+
+// parseColumn 解析列定义
+func parseColumn(e tvalue) (Column, error) {
+	c := Column{
+		Name:     e.string(4),
+		Type:     Type(e.int(1)),
+		Length:   int(e.int(2)),
+		Required: e.int(3) == int64(repRequired),
+	}
+	if e.int(5) > 0 || e.int(3) == int64(repRepeated) {
+		return c, fmt.Errorf("parquet: 不支持嵌套或重复的列 %s", c.Name)
+	}
+
+	if logical := e.strct(10); len(logical) > 0 {
+		parseLogical(&c, logical)
+		return c, nil
+	}
+	if !e.has(6) {
+		return c, nil
+	}
+
+	// 旧版本文件只有 ConvertedType
+	switch converted := int(e.int(6)); {
+	case converted == convertedUTF8:
+		c.Logical = LogicalString
+	case converted == convertedEnum:
+		c.Logical = LogicalEnum
+	case converted == convertedDecimal:
+		c.Logical, c.Scale, c.Precision = LogicalDecimal, int(e.int(7)), int(e.int(8))
+	case converted == convertedDate:
+		c.Logical = LogicalDate
+	case converted == convertedTimeMillis || converted == convertedTimeMicros:
+		c.Logical, c.UTC, c.Unit = LogicalTime, true, TimeUnit(converted-convertedTimeMillis+int(Millis))
+	case converted == convertedTimestampMillis || converted == convertedTimestampMicros:
+		c.Logical, c.UTC, c.Unit = LogicalTimestamp, true, TimeUnit(converted-convertedTimestampMillis+int(Millis))
+	case converted >= convertedUint8 && converted <= convertedInt8+3:
+		c.Logical, c.Signed = LogicalInteger, converted >= convertedInt8
+		c.BitWidth = 8 << ((converted - convertedUint8) % 4)
+	case converted == convertedJSON:
+		c.Logical = LogicalJSON
+	case converted == convertedBSON:
+		c.Logical = LogicalBSON
+	}
+	return c, nil
+}
+
+// parseLogical 解析 LogicalType 联合体
+func parseLogical(c *Column, logical tvalue) {
+	unit := func(t tvalue) TimeUnit {
+		u := t.strct(2)
+		for id := range u {
+			return TimeUnit(id)
+		}
+		return Micros
+	}
+
+	switch {
+	case logical.has(1):
+		c.Logical = LogicalString
+	case logical.has(4):
+		c.Logical = LogicalEnum
+	case logical.has(5):
+		d := logical.strct(5)
+		c.Logical, c.Scale, c.Precision = LogicalDecimal, int(d.int(1)), int(d.int(2))
+	case logical.has(6):
+		c.Logical = LogicalDate
+	case logical.has(7):
+		t := logical.strct(7)
+		c.Logical, c.UTC, c.Unit = LogicalTime, t.bool(1, false), unit(t)
+	case logical.has(8):
+		t := logical.strct(8)
+		c.Logical, c.UTC, c.Unit = LogicalTimestamp, t.bool(1, false), unit(t)
+	case logical.has(10):
+		i := logical.strct(10)
+		c.Logical, c.BitWidth, c.Signed = LogicalInteger, int(i.int(1)), i.bool(2, true)
+	case logical.has(12):
+		c.Logical = LogicalJSON
+	case logical.has(13):
+		c.Logical = LogicalBSON
+	case logical.has(14):
+		c.Logical = LogicalUUID
+	}
+}
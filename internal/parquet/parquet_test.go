@@ -0,0 +1,197 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testColumns() []Column {
+	return []Column{
+		{Name: "id", Type: Int64, Required: true},
+		{Name: "name", Type: ByteArray, Logical: LogicalString},
+		{Name: "price", Type: Int64, Logical: LogicalDecimal, Precision: 12, Scale: 2},
+		{Name: "big", Type: FixedLenByteArray, Length: DecimalLength(30), Logical: LogicalDecimal, Precision: 30, Scale: 4},
+		{Name: "ok", Type: Boolean},
+		{Name: "created", Type: Int64, Logical: LogicalTimestamp, Unit: Micros},
+		{Name: "day", Type: Int32, Logical: LogicalDate},
+		{Name: "uid", Type: FixedLenByteArray, Length: 16, Logical: LogicalUUID},
+		{Name: "small", Type: Int32, Logical: LogicalInteger, BitWidth: 16, Signed: true},
+		{Name: "ratio", Type: Float},
+		{Name: "data", Type: ByteArray},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC)
+	rows := [][]any{
+		{int64(1), "苹果", "12.50", "-12345678901234567890.1234", true, created, "2024-03-01", "0f8fad5b-d9cb-469f-a165-70867728950e", int64(-300), float32(0.1), []byte{0, 1, 2}},
+		{"2", nil, 3.14159, nil, false, "2024-03-01 12:30:45.123456", created, nil, "7", "2.5", nil},
+		{int64(3), "", nil, int64(42), nil, nil, nil, []byte("0123456789abcdef"), nil, nil, ""},
+	}
+	want := [][]any{
+		{int64(1), "苹果", "12.50", "-12345678901234567890.1234", true, created, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "0f8fad5b-d9cb-469f-a165-70867728950e", int64(-300), 0.1, []byte{0, 1, 2}},
+		{int64(2), nil, "3.14", nil, false, created, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), nil, int64(7), 2.5, nil},
+		{int64(3), "", nil, "42.0000", nil, nil, nil, "30313233-3435-3637-3839-616263646566", nil, nil, []byte{}},
+	}
+
+	for _, codec := range []Codec{Uncompressed, Snappy, Gzip, Zstd} {
+		var buf bytes.Buffer
+		// 每个行组两行，验证跨行组读取
+		w, err := NewWriter(&buf, testColumns(), WriterOptions{Codec: codec, RowGroupSize: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				t.Fatalf("codec %d: %v", codec, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("codec %d: %v", codec, err)
+		}
+		if r.NumRows() != 3 || len(r.groups) != 2 {
+			t.Fatalf("rows=%d groups=%d", r.NumRows(), len(r.groups))
+		}
+		if got := r.Columns(); !reflect.DeepEqual(got, testColumns()) {
+			t.Errorf("columns = %v, want %v", got, testColumns())
+		}
+
+		it := r.Rows()
+		i := 0
+		for it.Next() {
+			if !reflect.DeepEqual(it.Values(), want[i]) {
+				t.Errorf("codec %d row %d = %#v, want %#v", codec, i, it.Values(), want[i])
+			}
+			i++
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if i != len(want) {
+			t.Errorf("read %d rows, want %d", i, len(want))
+		}
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns(), WriterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := [][]any{
+		{nil, "a", nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		{int64(1), "a", "1234567890123", nil, nil, nil, nil, nil, nil, nil, nil},
+		{int64(1), "a", nil, nil, "maybe", nil, nil, nil, nil, nil, nil},
+		{int64(1), "a", nil, nil, nil, nil, nil, "not-a-uuid", nil, nil, nil},
+		{int64(1), "a", nil, nil, nil, nil, nil, nil, int64(1) << 40, nil, nil},
+		{int64(1)},
+	}
+	for i, row := range bad {
+		if err := w.Write(row); err == nil {
+			t.Errorf("row %d: expected error", i)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NumRows() != 0 || r.Rows().Next() {
+		t.Error("rejected rows must not be written")
+	}
+
+	if _, err := NewWriter(&buf, []Column{{Name: "a", Type: Int32}, {Name: "A", Type: Int32}}, WriterOptions{}); err == nil {
+		t.Error("expected duplicate column error")
+	}
+}
+
+// TestReadDictionaryV2 读取其他工具常用的字典编码与 V2 数据页
+func TestReadDictionaryV2(t *testing.T) {
+	col := Column{Name: "city", Type: ByteArray, Logical: LogicalString}
+
+	var dict []byte
+	for _, s := range []string{"北京", "上海"} {
+		dict = binary.LittleEndian.AppendUint32(dict, uint32(len(s)))
+		dict = append(dict, s...)
+	}
+	// 5 行：上海、空、北京、上海、空
+	levels := appendLevels(nil, []byte{1, 0, 1, 1, 0})
+	indexes := []byte{1, 1<<1 | 1, 0b00000101}
+	values, err := compress(Zstd, indexes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dictData, err := compress(Zstd, dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+	dictOffset := int64(file.Len())
+	file.Write(encodeStruct(tstruct{
+		{1, int32(pageDictionary)}, {2, int32(len(dict))}, {3, int32(len(dictData))},
+		{7, tstruct{{1, int32(2)}, {2, int32(encPlain)}}},
+	}))
+	file.Write(dictData)
+	dataOffset := int64(file.Len())
+	file.Write(encodeStruct(tstruct{
+		{1, int32(pageDataV2)}, {2, int32(len(levels) + len(indexes))}, {3, int32(len(levels) + len(values))},
+		{8, tstruct{{1, int32(5)}, {2, int32(2)}, {3, int32(5)}, {4, int32(encRLEDictionary)}, {5, int32(len(levels))}, {6, int32(0)}}},
+	}))
+	file.Write(levels)
+	file.Write(values)
+	size := int64(file.Len()) - dictOffset
+
+	footer := encodeStruct(tstruct{
+		{1, int32(2)},
+		{2, []tstruct{{{4, "schema"}, {5, int32(1)}}, col.schemaElement()}},
+		{3, int64(5)},
+		{4, []tstruct{{
+			{1, []tstruct{{{2, dictOffset}, {3, tstruct{
+				{1, int32(ByteArray)}, {2, []int32{encPlain, encRLEDictionary}}, {3, []string{"city"}}, {4, int32(Zstd)},
+				{5, int64(5)}, {6, size}, {7, size}, {9, dataOffset}, {11, dictOffset},
+			}}}}},
+			{2, size}, {3, int64(5)},
+		}}},
+	})
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.WriteString(magic)
+
+	r, err := Open(bytes.NewReader(file.Bytes()), int64(file.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []any
+	it := r.Rows()
+	for it.Next() {
+		got = append(got, it.Values()[0])
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []any{"上海", nil, "北京", "上海", nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDecimalLength(t *testing.T) {
+	for precision, want := range map[int]int{1: 1, 2: 1, 3: 2, 9: 4, 18: 8, 19: 9, 38: 16} {
+		if got := DecimalLength(precision); got != want {
+			t.Errorf("DecimalLength(%d) = %d, want %d", precision, got, want)
+		}
+	}
+}
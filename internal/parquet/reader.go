@@ -0,0 +1,322 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Reader Parquet 文件读取器
+type Reader struct {
+	r       io.ReaderAt
+	size    int64
+	columns []Column
+	groups  []tvalue
+	rows    int64
+}
+
+// Open 读取文件尾的元数据
+func Open(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(2*len(magic)+4) {
+		return nil, fmt.Errorf("parquet: 文件过小")
+	}
+	tail := make([]byte, 8)
+	if _, err := r.ReadAt(tail, size-8); err != nil {
+		return nil, err
+	}
+	switch string(tail[4:]) {
+	case magic:
+	case "PARE":
+		return nil, fmt.Errorf("parquet: 不支持加密的文件")
+	default:
+		return nil, fmt.Errorf("parquet: 不是 Parquet 文件")
+	}
+
+	length := int64(binary.LittleEndian.Uint32(tail))
+	if length <= 0 || length > size-8-int64(len(magic)) {
+		return nil, errCorrupt
+	}
+	footer := make([]byte, length)
+	if _, err := r.ReadAt(footer, size-8-length); err != nil {
+		return nil, err
+	}
+	meta, err := decodeStruct(bytes.NewReader(footer))
+	if err != nil {
+		return nil, fmt.Errorf("parquet: 解析元数据失败: %w", err)
+	}
+
+	schema := meta.list(2)
+	if len(schema) < 2 {
+		return nil, fmt.Errorf("parquet: 文件没有列")
+	}
+	root, _ := schema[0].(tvalue)
+	if int(root.int(5)) != len(schema)-1 {
+		return nil, fmt.Errorf("parquet: 不支持嵌套的列")
+	}
+
+	pr := &Reader{r: r, size: size, rows: meta.int(3)}
+	for _, e := range schema[1:] {
+		elem, _ := e.(tvalue)
+		col, err := parseColumn(elem)
+		if err != nil {
+			return nil, err
+		}
+		pr.columns = append(pr.columns, col)
+	}
+	for _, g := range meta.list(4) {
+		group, _ := g.(tvalue)
+		if len(group.list(1)) != len(pr.columns) {
+			return nil, errCorrupt
+		}
+		pr.groups = append(pr.groups, group)
+	}
+	return pr, nil
+}
+
+// Columns 返回列定义
+func (r *Reader) Columns() []Column { return r.columns }
+
+// NumRows 返回总行数
+func (r *Reader) NumRows() int64 { return r.rows }
+
+// Rows 返回按行读取的迭代器，每次在内存中解码一个行组
+func (r *Reader) Rows() *Rows {
+	return &Rows{r: r, data: make([][]any, len(r.columns)), row: make([]any, len(r.columns))}
+}
+
+// Rows 行迭代器
+type Rows struct {
+	r     *Reader
+	group int
+	data  [][]any
+	pos   int
+	count int
+	row   []any
+	err   error
+}
+
+// Next 前进到下一行，没有更多数据或出错时返回 false
+func (it *Rows) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.pos+1 >= it.count {
+		if it.group >= len(it.r.groups) {
+			return false
+		}
+		if it.err = it.readGroup(it.r.groups[it.group]); it.err != nil {
+			return false
+		}
+		it.group++
+		it.pos = -1
+	}
+	it.pos++
+	for i, col := range it.r.columns {
+		it.row[i] = col.Value(it.data[i][it.pos])
+	}
+	return true
+}
+
+// Values 返回当前行按 Columns 顺序排列的值，值由 Column.Value 转换
+func (it *Rows) Values() []any { return it.row }
+
+// Err 返回读取过程中的错误
+func (it *Rows) Err() error { return it.err }
+
+// readGroup 解码一个行组的全部列
+func (it *Rows) readGroup(group tvalue) error {
+	rows := int(group.int(3))
+	if rows < 0 {
+		return errCorrupt
+	}
+	for i, c := range group.list(1) {
+		chunk, _ := c.(tvalue)
+		values, err := it.r.readChunk(chunk, &it.r.columns[i], rows)
+		if err != nil {
+			return fmt.Errorf("parquet: 读取列 %s 失败: %w", it.r.columns[i].Name, err)
+		}
+		it.data[i] = values
+	}
+	it.count = rows
+	return nil
+}
+
+// readChunk 读取并解码一个列块
+func (r *Reader) readChunk(chunk tvalue, col *Column, rows int) ([]any, error) {
+	meta := chunk.strct(3)
+	if meta == nil {
+		return nil, fmt.Errorf("不支持外部文件中的列块")
+	}
+	codec := Codec(meta.int(4))
+	start := meta.int(9)
+	if dict := meta.int(11); dict > 0 && dict < start {
+		start = dict
+	}
+	size := meta.int(7)
+	if start < 0 || size < 0 || start+size > r.size {
+		return nil, errCorrupt
+	}
+
+	buf := make([]byte, size)
+	if _, err := r.r.ReadAt(buf, start); err != nil {
+		return nil, err
+	}
+
+	br := bytes.NewReader(buf)
+	values := make([]any, 0, rows)
+	var dict []any
+	for len(values) < rows {
+		if br.Len() == 0 {
+			return nil, errCorrupt
+		}
+		header, err := decodeStruct(br)
+		if err != nil {
+			return nil, err
+		}
+		pos := len(buf) - br.Len()
+		length := int(header.int(3))
+		if length < 0 || length > br.Len() {
+			return nil, errCorrupt
+		}
+		page := buf[pos : pos+length]
+		if _, err := br.Seek(int64(length), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+
+		switch header.int(1) {
+		case pageDictionary:
+			data, err := decompress(codec, page)
+			if err != nil {
+				return nil, err
+			}
+			if dict, err = decodePlain(data, col, int(header.strct(7).int(1))); err != nil {
+				return nil, err
+			}
+		case pageData:
+			h := header.strct(5)
+			data, err := decompress(codec, page)
+			if err != nil {
+				return nil, err
+			}
+			n := int(h.int(1))
+			var levels []uint32
+			if !col.Required {
+				if len(data) < 4 {
+					return nil, errCorrupt
+				}
+				length := int(binary.LittleEndian.Uint32(data))
+				if length > len(data)-4 {
+					return nil, errCorrupt
+				}
+				if levels, err = decodeHybrid(data[4:4+length], 1, n); err != nil {
+					return nil, err
+				}
+				data = data[4+length:]
+			}
+			if values, err = appendPage(values, data, col, int(h.int(2)), n, levels, dict); err != nil {
+				return nil, err
+			}
+		case pageDataV2:
+			h := header.strct(8)
+			n := int(h.int(1))
+			repLen, defLen := int(h.int(6)), int(h.int(5))
+			if repLen < 0 || defLen < 0 || repLen+defLen > len(page) {
+				return nil, errCorrupt
+			}
+			var levels []uint32
+			if !col.Required {
+				if levels, err = decodeHybrid(page[repLen:repLen+defLen], 1, n); err != nil {
+					return nil, err
+				}
+			}
+			data := page[repLen+defLen:]
+			if h.bool(7, true) {
+				if data, err = decompress(codec, data); err != nil {
+					return nil, err
+				}
+			}
+			if values, err = appendPage(values, data, col, int(h.int(4)), n, levels, dict); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(values) != rows {
+		return nil, errCorrupt
+	}
+	return values, nil
+}
+
+// appendPage 解码数据页中的 n 个值并追加到 values，levels 为 0 的位置为空值
+func appendPage(values []any, data []byte, col *Column, encoding, n int, levels []uint32, dict []any) ([]any, error) {
+	if n < 0 || n > cap(values)-len(values) {
+		return nil, errCorrupt
+	}
+	present := n
+	if levels != nil {
+		present = 0
+		for _, l := range levels {
+			if l == 1 {
+				present++
+			}
+		}
+	}
+
+	var decoded []any
+	var err error
+	switch encoding {
+	case encPlain:
+		decoded, err = decodePlain(data, col, present)
+	case encPlainDict, encRLEDictionary:
+		if present == 0 {
+			break
+		}
+		if dict == nil || len(data) == 0 {
+			return nil, errCorrupt
+		}
+		var indexes []uint32
+		if indexes, err = decodeHybrid(data[1:], int(data[0]), present); err != nil {
+			return nil, err
+		}
+		decoded = make([]any, present)
+		for i, idx := range indexes {
+			if int(idx) >= len(dict) {
+				return nil, errCorrupt
+			}
+			decoded[i] = dict[idx]
+		}
+	case encRLE:
+		if col.Type != Boolean || len(data) < 4 {
+			return nil, fmt.Errorf("不支持的编码 %d", encoding)
+		}
+		var bits []uint32
+		if bits, err = decodeHybrid(data[4:], 1, present); err != nil {
+			return nil, err
+		}
+		decoded = make([]any, present)
+		for i, b := range bits {
+			decoded[i] = b == 1
+		}
+	default:
+		return nil, fmt.Errorf("不支持的编码 %d", encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != present {
+		return nil, errCorrupt
+	}
+
+	if levels == nil {
+		return append(values, decoded...), nil
+	}
+	for _, l := range levels {
+		if l == 1 {
+			values = append(values, decoded[0])
+			decoded = decoded[1:]
+		} else {
+			values = append(values, nil)
+		}
+	}
+	return values, nil
+}
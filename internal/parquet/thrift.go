@@ -0,0 +1,356 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Thrift Compact 协议的字段类型
+const (
+	tStop   = 0
+	tTrue   = 1
+	tFalse  = 2
+	tByte   = 3
+	tI16    = 4
+	tI32    = 5
+	tI64    = 6
+	tDouble = 7
+	tBinary = 8
+	tList   = 9
+	tSet    = 10
+	tMap    = 11
+	tStruct = 12
+)
+
+// maxThriftSize 解析元数据时单个字符串或列表的最大长度，防止损坏的文件导致超大内存分配
+const maxThriftSize = 64 << 20
+
+var errThriftSize = errors.New("parquet: 元数据长度超出限制，文件可能已损坏")
+
+// field 待编码的结构体字段
+// value 为 bool、int8、int16、int32、int64、string、[]byte、[]int32、[]string、[]tstruct 或 tstruct。
+type field struct {
+	id    int16
+	value any
+}
+
+// tstruct 待编码的 Thrift 结构体，字段按 id 升序排列
+type tstruct []field
+
+// thriftWriter Thrift Compact 协议编码器
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // 各层结构体上一个字段的 id
+}
+
+// encodeStruct 编码结构体
+func encodeStruct(s tstruct) []byte {
+	w := &thriftWriter{}
+	w.writeStruct(s)
+	return w.buf
+}
+
+func (w *thriftWriter) writeStruct(s tstruct) {
+	w.last = append(w.last, 0)
+	for _, f := range s {
+		if f.value == nil {
+			continue
+		}
+		w.writeField(f)
+	}
+	w.buf = append(w.buf, tStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) writeField(f field) {
+	typ := valueType(f.value)
+	if b, ok := f.value.(bool); ok {
+		typ = tFalse
+		if b {
+			typ = tTrue
+		}
+	}
+
+	last := &w.last[len(w.last)-1]
+	if delta := f.id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(int64(f.id))
+	}
+	*last = f.id
+
+	if _, ok := f.value.(bool); !ok {
+		w.writeValue(f.value)
+	}
+}
+
+func (w *thriftWriter) writeValue(v any) {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			w.buf = append(w.buf, tTrue)
+		} else {
+			w.buf = append(w.buf, tFalse)
+		}
+	case int8:
+		w.buf = append(w.buf, byte(val))
+	case int16:
+		w.varint(int64(val))
+	case int32:
+		w.varint(int64(val))
+	case int64:
+		w.varint(val)
+	case string:
+		w.uvarint(uint64(len(val)))
+		w.buf = append(w.buf, val...)
+	case []byte:
+		w.uvarint(uint64(len(val)))
+		w.buf = append(w.buf, val...)
+	case []int32:
+		w.listHeader(len(val), tI32)
+		for _, e := range val {
+			w.varint(int64(e))
+		}
+	case []string:
+		w.listHeader(len(val), tBinary)
+		for _, e := range val {
+			w.writeValue(e)
+		}
+	case []tstruct:
+		w.listHeader(len(val), tStruct)
+		for _, e := range val {
+			w.writeStruct(e)
+		}
+	case tstruct:
+		w.writeStruct(val)
+	default:
+		panic(fmt.Sprintf("parquet: unsupported thrift value %T", v))
+	}
+}
+
+// valueType 返回值对应的 Compact 协议类型
+func valueType(v any) byte {
+	switch v.(type) {
+	case bool:
+		return tTrue
+	case int8:
+		return tByte
+	case int16:
+		return tI16
+	case int32:
+		return tI32
+	case int64:
+		return tI64
+	case string, []byte:
+		return tBinary
+	case []int32, []string, []tstruct:
+		return tList
+	default:
+		return tStruct
+	}
+}
+
+func (w *thriftWriter) listHeader(size int, elem byte) {
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elem)
+		return
+	}
+	w.buf = append(w.buf, 0xF0|elem)
+	w.uvarint(uint64(size))
+}
+
+// varint 写出 zigzag 编码的整数
+func (w *thriftWriter) varint(n int64) {
+	w.uvarint(uint64(n<<1) ^ uint64(n>>63))
+}
+
+func (w *thriftWriter) uvarint(n uint64) {
+	w.buf = binary.AppendUvarint(w.buf, n)
+}
+
+// tvalue 解码后的结构体，整数统一为 int64，列表为 []any
+type tvalue map[int16]any
+
+// int 返回整数字段，不存在时为 0
+func (v tvalue) int(id int16) int64 {
+	n, _ := v[id].(int64)
+	return n
+}
+
+// has 判断字段是否存在
+func (v tvalue) has(id int16) bool {
+	_, ok := v[id]
+	return ok
+}
+
+// bool 返回布尔字段，不存在时为 def
+func (v tvalue) bool(id int16, def bool) bool {
+	b, ok := v[id].(bool)
+	if !ok {
+		return def
+	}
+	return b
+}
+
+// string 返回字符串字段
+func (v tvalue) string(id int16) string {
+	b, _ := v[id].([]byte)
+	return string(b)
+}
+
+// strct 返回结构体字段，不存在时为 nil
+func (v tvalue) strct(id int16) tvalue {
+	s, _ := v[id].(tvalue)
+	return s
+}
+
+// list 返回列表字段
+func (v tvalue) list(id int16) []any {
+	l, _ := v[id].([]any)
+	return l
+}
+
+// thriftReader Thrift Compact 协议解码器
+type thriftReader struct {
+	r io.ByteReader
+}
+
+// decodeStruct 从 r 中解码一个结构体，r 停在结构体结束的位置
+func decodeStruct(r io.ByteReader) (tvalue, error) {
+	tr := &thriftReader{r: r}
+	return tr.readStruct()
+}
+
+func (r *thriftReader) readStruct() (tvalue, error) {
+	s := make(tvalue)
+	var last int16
+	for {
+		header, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		typ := header & 0x0F
+		if typ == tStop {
+			return s, nil
+		}
+
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			n, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(n)
+		}
+		last = id
+
+		var v any
+		switch typ {
+		case tTrue:
+			v = true
+		case tFalse:
+			v = false
+		default:
+			if v, err = r.readValue(typ); err != nil {
+				return nil, err
+			}
+		}
+		s[id] = v
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) (any, error) {
+	switch typ {
+	case tTrue, tFalse:
+		// 列表中的布尔元素每个占一个字节
+		b, err := r.r.ReadByte()
+		return b == tTrue, err
+	case tByte:
+		b, err := r.r.ReadByte()
+		return int64(int8(b)), err
+	case tI16, tI32, tI64:
+		return r.varint()
+	case tDouble:
+		var b [8]byte
+		for i := range b {
+			c, err := r.r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			b[i] = c
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+	case tBinary:
+		n, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return nil, err
+		}
+		if n > maxThriftSize {
+			return nil, errThriftSize
+		}
+		b := make([]byte, n)
+		for i := range b {
+			if b[i], err = r.r.ReadByte(); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case tList, tSet:
+		header, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = binary.ReadUvarint(r.r); err != nil {
+				return nil, err
+			}
+		}
+		if size > maxThriftSize {
+			return nil, errThriftSize
+		}
+		list := make([]any, 0, min(size, 1024))
+		for i := uint64(0); i < size; i++ {
+			v, err := r.readValue(header & 0x0F)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case tMap:
+		size, err := binary.ReadUvarint(r.r)
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		types, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < size; i++ {
+			if _, err := r.readValue(types >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(types & 0x0F); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case tStruct:
+		return r.readStruct()
+	}
+	return nil, fmt.Errorf("parquet: 未知的 thrift 类型 %d", typ)
+}
+
+// varint 读取 zigzag 编码的整数
+func (r *thriftReader) varint() (int64, error) {
+	u, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
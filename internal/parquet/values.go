@@ -0,0 +1,506 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// julianEpoch 1970-01-01 对应的儒略日，INT96 时间戳以儒略日加当日纳秒存储
+const julianEpoch = 2440588
+
+// timeLayouts 解析以文本返回的日期时间时尝试的格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Physical 将 Go 值转换为列的物理类型值
+// 返回 bool、int32、int64、float32、float64 或 []byte，nil 表示空值。
+// 数据库驱动常以文本返回数值与日期，这里按列的逻辑类型解析。
+func (c Column) Physical(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch c.Type {
+	case Boolean:
+		return toBool(v)
+	case Int32, Int64:
+		n, err := c.integer(v)
+		if err != nil {
+			return nil, err
+		}
+		if c.Type == Int64 {
+			return n, nil
+		}
+		if c.Logical == LogicalInteger && !c.Signed && n >= 0 && n <= math.MaxUint32 {
+			return int32(uint32(n)), nil
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("值 %d 超出 INT32 范围", n)
+		}
+		return int32(n), nil
+	case Float:
+		f, err := toFloat(v)
+		return float32(f), err
+	case Double:
+		return toFloat(v)
+	case Int96:
+		t, err := toTime(v)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, 12)
+		days := t.Unix() / 86400
+		if t.Unix()%86400 < 0 {
+			days--
+		}
+		days += julianEpoch
+		nanos := t.Sub(time.Unix((days-julianEpoch)*86400, 0))
+		binary.LittleEndian.PutUint64(b, uint64(nanos))
+		binary.LittleEndian.PutUint32(b[8:], uint32(days))
+		return b, nil
+	case ByteArray, FixedLenByteArray:
+		return c.bytes(v)
+	}
+	return nil, fmt.Errorf("不支持的物理类型 %s", c.Type)
+}
+
+// integer 将值转换为 INT32、INT64 列存储的整数
+func (c Column) integer(v any) (int64, error) {
+	switch c.Logical {
+	case LogicalDate:
+		t, err := toTime(v)
+		if err != nil {
+			return 0, err
+		}
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400, nil
+	case LogicalTimestamp:
+		t, err := toTime(v)
+		if err != nil {
+			return 0, err
+		}
+		if !c.UTC {
+			// 不带时区的时间戳按本地时钟读数存储
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+		switch c.Unit {
+		case Millis:
+			return t.UnixMilli(), nil
+		case Nanos:
+			return t.UnixNano(), nil
+		}
+		return t.UnixMicro(), nil
+	case LogicalTime:
+		d, err := toTimeOfDay(v)
+		if err != nil {
+			return 0, err
+		}
+		switch c.Unit {
+		case Millis:
+			return d.Milliseconds(), nil
+		case Nanos:
+			return d.Nanoseconds(), nil
+		}
+		return d.Microseconds(), nil
+	case LogicalDecimal:
+		n, err := c.unscaled(v)
+		if err != nil {
+			return 0, err
+		}
+		return n.Int64(), nil
+	}
+
+	switch n := v.(type) {
+	case uint64:
+		// UINT64 以相同的位存储在 INT64 中
+		return int64(n), nil
+	case uint:
+		return int64(n), nil
+	case string:
+		if c.Logical == LogicalInteger && !c.Signed {
+			u, err := strconv.ParseUint(strings.TrimSpace(n), 10, 64)
+			return int64(u), err
+		}
+	}
+	return toInt(v)
+}
+
+// bytes 将值转换为 BYTE_ARRAY 或 FIXED_LEN_BYTE_ARRAY
+func (c Column) bytes(v any) ([]byte, error) {
+	var b []byte
+	switch c.Logical {
+	case LogicalDecimal:
+		n, err := c.unscaled(v)
+		if err != nil {
+			return nil, err
+		}
+		b = twosComplement(n, c.Length)
+	case LogicalUUID:
+		u, err := toUUID(v)
+		if err != nil {
+			return nil, err
+		}
+		b = u
+	case LogicalJSON:
+		switch val := v.(type) {
+		case string:
+			b = []byte(val)
+		case []byte:
+			b = val
+		default:
+			data, err := json.Marshal(val)
+			if err != nil {
+				return nil, err
+			}
+			b = data
+		}
+	default:
+		b = toBytes(v)
+	}
+
+	if c.Type == FixedLenByteArray && len(b) != c.Length {
+		return nil, fmt.Errorf("值长度 %d 与定长列的长度 %d 不一致", len(b), c.Length)
+	}
+	return b, nil
+}
+
+// unscaled 将值按 DECIMAL 的小数位数四舍五入并返回去掉小数点后的整数
+func (c Column) unscaled(v any) (*big.Int, error) {
+	r := new(big.Rat)
+	switch n := v.(type) {
+	case float32:
+		r.SetFloat64(float64(n))
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%v 不能转换为 DECIMAL", n)
+		}
+		// 以最短十进制表示解析，避免二进制误差在小数位数较多时显现
+		r.SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	case string, []byte:
+		s := strings.TrimSpace(string(toBytes(n)))
+		if _, ok := r.SetString(s); !ok {
+			return nil, fmt.Errorf("无法将 %q 解析为 DECIMAL", s)
+		}
+	default:
+		i, err := toInt(v)
+		if err != nil {
+			return nil, fmt.Errorf("无法将 %v 转换为 DECIMAL", v)
+		}
+		r.SetInt64(i)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.Scale)), nil)
+	r.Mul(r, new(big.Rat).SetInt(scale))
+
+	// 四舍五入，远离零
+	num, den := r.Num(), r.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Abs(m).Lsh(m, 1).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	if digits := len(new(big.Int).Abs(q).String()); q.Sign() != 0 && digits > c.Precision {
+		return nil, fmt.Errorf("值 %v 超出 DECIMAL(%d,%d) 的精度", v, c.Precision, c.Scale)
+	}
+	return q, nil
+}
+
+// twosComplement 以大端补码编码整数，size 为 0 时使用最少的字节数
+func twosComplement(n *big.Int, size int) []byte {
+	if size <= 0 {
+		size = (n.BitLen() + 8) / 8
+	}
+	b := make([]byte, size)
+	if n.Sign() >= 0 {
+		n.FillBytes(b)
+		return b
+	}
+	// 负数：2^(8*size) + n
+	m := new(big.Int).Lsh(big.NewInt(1), uint(8*size))
+	m.Add(m, n).FillBytes(b)
+	return b
+}
+
+// fromTwosComplement 解码大端补码整数
+func fromTwosComplement(b []byte) *big.Int {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return n
+}
+
+// decimalString 将去掉小数点的整数按小数位数格式化
+func decimalString(n *big.Int, scale int) string {
+	if scale <= 0 {
+		return n.String()
+	}
+	s := new(big.Int).Abs(n).String()
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	s = s[:len(s)-scale] + "." + s[len(s)-scale:]
+	if n.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Value 将读取到的物理类型值按逻辑类型转换为 Go 值
+// 整数为 int64（UINT64 为 uint64），浮点数为 float64，文本、DECIMAL 与 UUID 为 string，
+// 日期时间为 time.Time，其余二进制数据为 []byte。返回值不引用读取缓冲区。
+func (c Column) Value(v any) any {
+	switch val := v.(type) {
+	case nil, bool, float64:
+		return val
+	case float32:
+		// 以最短十进制表示转换，避免 0.1 变为 0.10000000149011612
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(val), 'g', -1, 32), 64)
+		return f
+	case int32:
+		if c.Logical == LogicalInteger && !c.Signed {
+			return int64(uint32(val))
+		}
+		return c.integerValue(int64(val))
+	case int64:
+		if c.Logical == LogicalInteger && !c.Signed {
+			return uint64(val)
+		}
+		return c.integerValue(val)
+	case []byte:
+		switch {
+		case c.Type == Int96 && len(val) == 12:
+			nanos := int64(binary.LittleEndian.Uint64(val))
+			days := int64(binary.LittleEndian.Uint32(val[8:]))
+			return time.Unix((days-julianEpoch)*86400, nanos).UTC()
+		case c.Logical == LogicalDecimal:
+			return decimalString(fromTwosComplement(val), c.Scale)
+		case c.Logical == LogicalUUID && len(val) == 16:
+			return formatUUID(val)
+		case c.Logical == LogicalString, c.Logical == LogicalEnum, c.Logical == LogicalJSON:
+			return string(val)
+		}
+		return append([]byte{}, val...)
+	}
+	return v
+}
+
+// integerValue 转换 INT32、INT64 的逻辑类型
+func (c Column) integerValue(n int64) any {
+	switch c.Logical {
+	case LogicalDate:
+		return time.Unix(n*86400, 0).UTC()
+	case LogicalTimestamp:
+		var t time.Time
+		switch c.Unit {
+		case Millis:
+			t = time.UnixMilli(n)
+		case Nanos:
+			t = time.Unix(0, n)
+		default:
+			t = time.UnixMicro(n)
+		}
+		return t.UTC()
+	case LogicalTime:
+		d := time.Duration(n) * time.Microsecond
+		switch c.Unit {
+		case Millis:
+			d = time.Duration(n) * time.Millisecond
+		case Nanos:
+			d = time.Duration(n)
+		}
+		return time.Time{}.Add(d).Format("15:04:05.999999999")
+	case LogicalDecimal:
+		return decimalString(big.NewInt(n), c.Scale)
+	}
+	return n
+}
+
+// toBool 转换布尔值，数值非零为 true
+func toBool(v any) (bool, error) {
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case string, []byte:
+		s := strings.TrimSpace(string(toBytes(val)))
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("无法将 %q 解析为布尔值", s)
+		}
+		return b, nil
+	}
+	n, err := toInt(v)
+	return n != 0, err
+}
+
+// toInt 转换整数，文本按十进制解析
+func toInt(v any) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint8:
+		return int64(n), nil
+	case uint16:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case uint:
+		if uint64(n) > math.MaxInt64 {
+			return 0, fmt.Errorf("值 %d 超出 INT64 范围", n)
+		}
+		return int64(n), nil
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, fmt.Errorf("值 %d 超出 INT64 范围", n)
+		}
+		return int64(n), nil
+	case bool:
+		if n {
+			return 1, nil
+		}
+		return 0, nil
+	case float32, float64:
+		f, _ := toFloat(n)
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("值 %v 不是整数", f)
+		}
+		return int64(f), nil
+	case string, []byte:
+		s := strings.TrimSpace(string(toBytes(n)))
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 解析为整数", s)
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("无法将 %T 转换为整数", v)
+}
+
+// toFloat 转换浮点数
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string, []byte:
+		s := strings.TrimSpace(string(toBytes(n)))
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 解析为浮点数", s)
+		}
+		return f, nil
+	}
+	i, err := toInt(v)
+	if err != nil {
+		return 0, fmt.Errorf("无法将 %T 转换为浮点数", v)
+	}
+	return float64(i), nil
+}
+
+// toTime 转换日期时间，文本按常见格式解析
+func toTime(v any) (time.Time, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, nil
+	case string, []byte:
+		s := strings.TrimSpace(string(toBytes(val)))
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("无法将 %q 解析为日期时间", s)
+	}
+	return time.Time{}, fmt.Errorf("无法将 %T 转换为日期时间", v)
+}
+
+// toTimeOfDay 转换一天中的时间
+func toTimeOfDay(v any) (time.Duration, error) {
+	switch val := v.(type) {
+	case time.Time:
+		y, m, d := val.Date()
+		return val.Sub(time.Date(y, m, d, 0, 0, 0, 0, val.Location())), nil
+	case time.Duration:
+		return val, nil
+	case string, []byte:
+		s := strings.TrimSpace(string(toBytes(val)))
+		t, err := time.Parse("15:04:05.999999999", s)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 解析为时间", s)
+		}
+		return t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+	return 0, fmt.Errorf("无法将 %T 转换为时间", v)
+}
+
+// toUUID 解析 UUID 文本或 16 字节的二进制值
+func toUUID(v any) ([]byte, error) {
+	switch val := v.(type) {
+	case []byte:
+		if len(val) == 16 {
+			return val, nil
+		}
+		return toUUID(string(val))
+	case [16]byte:
+		return val[:], nil
+	case string:
+		s := strings.Trim(strings.TrimSpace(val), "{}")
+		b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+		if err != nil || len(b) != 16 {
+			return nil, fmt.Errorf("无法将 %q 解析为 UUID", val)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("无法将 %T 转换为 UUID", v)
+}
+
+// formatUUID 格式化 UUID 为 8-4-4-4-12 形式
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// toBytes 转换文本或二进制值，其余类型格式化为文本
+func toBytes(v any) []byte {
+	switch val := v.(type) {
+	case []byte:
+		return val
+	case string:
+		return []byte(val)
+	case time.Time:
+		return []byte(val.Format(time.RFC3339Nano))
+	case float32:
+		return strconv.AppendFloat(nil, float64(val), 'g', -1, 32)
+	case float64:
+		return strconv.AppendFloat(nil, val, 'g', -1, 64)
+	case fmt.Stringer:
+		return []byte(val.String())
+	}
+	return []byte(fmt.Sprint(v))
+}
@@ -0,0 +1,260 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultRowGroupSize 默认每个行组的行数
+	DefaultRowGroupSize = 100000
+	// maxRowGroupBytes 行组缓冲的数据超过该大小时提前写出，避免宽表占用过多内存
+	maxRowGroupBytes = 64 << 20
+)
+
+// WriterOptions 写出选项
+type WriterOptions struct {
+	// Codec 数据页的压缩算法
+	Codec Codec
+	// RowGroupSize 每个行组的行数，<= 0 时为 DefaultRowGroupSize
+	RowGroupSize int
+	// CreatedBy 写入文件元数据的生成程序名称
+	CreatedBy string
+}
+
+// Writer Parquet 文件写出器
+// 数据按行写入，每满一个行组后按列写出，每个列块只有一个 PLAIN 编码的 V1 数据页。
+type Writer struct {
+	w       *countingWriter
+	columns []Column
+	opts    WriterOptions
+	chunks  []columnBuffer
+	row     []any
+	rows    int
+	size    int
+	total   int64
+	groups  []tstruct
+	closed  bool
+}
+
+// columnBuffer 当前行组中一列的数据
+type columnBuffer struct {
+	levels []byte
+	values []byte
+	bools  []bool
+}
+
+// countingWriter 记录已写出的字节数，用于计算各页在文件中的偏移
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// NewWriter 创建写出器并写出文件头
+func NewWriter(w io.Writer, columns []Column, opts WriterOptions) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("parquet: 至少需要一列")
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if col.Name == "" {
+			return nil, fmt.Errorf("parquet: 列名不能为空")
+		}
+		key := strings.ToLower(col.Name)
+		if seen[key] {
+			return nil, fmt.Errorf("parquet: 列名 %s 重复", col.Name)
+		}
+		seen[key] = true
+		if col.Type == FixedLenByteArray && col.Length <= 0 {
+			return nil, fmt.Errorf("parquet: 定长列 %s 的长度必须大于 0", col.Name)
+		}
+	}
+	if opts.RowGroupSize <= 0 {
+		opts.RowGroupSize = DefaultRowGroupSize
+	}
+	if opts.CreatedBy == "" {
+		opts.CreatedBy = "dbm"
+	}
+
+	pw := &Writer{
+		w:       &countingWriter{w: w},
+		columns: columns,
+		opts:    opts,
+		chunks:  make([]columnBuffer, len(columns)),
+		row:     make([]any, len(columns)),
+	}
+	if _, err := io.WriteString(pw.w, magic); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// Write 写入一行，值按 Column.Physical 转换
+func (w *Writer) Write(row []any) error {
+	if w.closed {
+		return fmt.Errorf("parquet: 写出器已关闭")
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: 行有 %d 个值，应为 %d 个", len(row), len(w.columns))
+	}
+
+	// 先转换整行，出错时不留下半行数据
+	for i, col := range w.columns {
+		v, err := col.Physical(row[i])
+		if err != nil {
+			return fmt.Errorf("列 %s: %w", col.Name, err)
+		}
+		if v == nil && col.Required {
+			return fmt.Errorf("列 %s 不能为空", col.Name)
+		}
+		w.row[i] = v
+	}
+
+	for i, v := range w.row {
+		chunk := &w.chunks[i]
+		if v == nil {
+			chunk.levels = append(chunk.levels, 0)
+			continue
+		}
+		chunk.levels = append(chunk.levels, 1)
+		if b, ok := v.(bool); ok {
+			chunk.bools = append(chunk.bools, b)
+			continue
+		}
+		n := len(chunk.values)
+		chunk.values = appendPlain(chunk.values, &w.columns[i], v)
+		w.size += len(chunk.values) - n
+	}
+
+	w.rows++
+	if w.rows >= w.opts.RowGroupSize || w.size >= maxRowGroupBytes {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush 写出当前行组
+func (w *Writer) flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+
+	start := w.w.n
+	var groupSize, compressedSize int64
+	chunks := make([]tstruct, len(w.columns))
+	for i := range w.columns {
+		col := &w.columns[i]
+		chunk := &w.chunks[i]
+
+		var page []byte
+		if !col.Required {
+			levels := appendLevels(nil, chunk.levels)
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+			page = append(page, levels...)
+		}
+		if col.Type == Boolean {
+			page = append(page, packBools(chunk.bools)...)
+		} else {
+			page = append(page, chunk.values...)
+		}
+
+		data, err := compress(w.opts.Codec, page)
+		if err != nil {
+			return err
+		}
+		header := encodeStruct(tstruct{
+			{1, int32(pageData)},
+			{2, int32(len(page))},
+			{3, int32(len(data))},
+			{5, tstruct{
+				{1, int32(w.rows)},
+				{2, int32(encPlain)},
+				{3, int32(encRLE)},
+				{4, int32(encRLE)},
+			}},
+		})
+
+		offset := w.w.n
+		if _, err := w.w.Write(header); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(data); err != nil {
+			return err
+		}
+
+		uncompressed := int64(len(header) + len(page))
+		compressed := int64(len(header) + len(data))
+		groupSize += uncompressed
+		compressedSize += compressed
+		chunks[i] = tstruct{
+			{2, offset},
+			{3, tstruct{
+				{1, int32(col.Type)},
+				{2, []int32{encPlain, encRLE}},
+				{3, []string{col.Name}},
+				{4, int32(w.opts.Codec)},
+				{5, int64(w.rows)},
+				{6, uncompressed},
+				{7, compressed},
+				{9, offset},
+			}},
+		}
+
+		chunk.levels = chunk.levels[:0]
+		chunk.values = chunk.values[:0]
+		chunk.bools = chunk.bools[:0]
+	}
+
+	w.groups = append(w.groups, tstruct{
+		{1, chunks},
+		{2, groupSize},
+		{3, int64(w.rows)},
+		{5, start},
+		{6, compressedSize},
+	})
+	w.total += int64(w.rows)
+	w.rows = 0
+	w.size = 0
+	return nil
+}
+
+// Close 写出剩余的数据与文件尾，不关闭底层的 io.Writer
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	schema := make([]tstruct, 0, len(w.columns)+1)
+	schema = append(schema, tstruct{{4, "schema"}, {5, int32(len(w.columns))}})
+	for _, col := range w.columns {
+		schema = append(schema, col.schemaElement())
+	}
+	groups := w.groups
+	if groups == nil {
+		groups = []tstruct{}
+	}
+	footer := encodeStruct(tstruct{
+		{1, int32(1)},
+		{2, schema},
+		{3, w.total},
+		{4, groups},
+		{6, w.opts.CreatedBy},
+	})
+
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, magic...)
+	_, err := w.w.Write(footer)
+	return err
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"bytes"
+	"dbm/internal/model"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ctxAudit         = "audit"         // 当前请求的审计记录
+	ctxAuditDetached = "auditDetached" // 审计记录由处理器在操作结束后自行写入
+
+	// maxAuditErrorBody 从错误响应中读取错误信息的最大字节数
+	maxAuditErrorBody = 4096
+)
+
+// auditWriter 保留错误响应的内容，用于在审计记录中写入错误信息
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 只保留错误响应的开头部分
+func (w *auditWriter) capture(data []byte) {
+	if w.Status() < http.StatusBadRequest || w.body.Len() >= maxAuditErrorBody {
+		return
+	}
+	if n := maxAuditErrorBody - w.body.Len(); len(data) > n {
+		data = data[:n]
+	}
+	w.body.Write(data)
+}
+
+// errorMessage 返回错误响应中的错误信息
+func (w *auditWriter) errorMessage() string {
+	var resp APIResponse
+	if err := json.Unmarshal(w.body.Bytes(), &resp); err == nil && resp.Message != "" {
+		return resp.Message
+	}
+	return http.StatusText(w.Status())
+}
+
+// audited 在请求结束后写入审计记录，需在 authorize 之前使用，被拒绝的请求同样记录
+// 处理器通过 setAuditStatement、setAuditResult 补充语句、影响行数与语句级错误；响应状态码不小于 400 时视为失败。
+func (s *Server) audited(action model.AuditAction) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.audit == nil {
+			c.Next()
+			return
+		}
+
+		user := currentUser(c)
+		entry := &model.AuditEntry{
+			Time:         time.Now(),
+			UserID:       user.ID,
+			Username:     user.Username,
+			ClientIP:     c.ClientIP(),
+			ConnectionID: c.Param("id"),
+			Database:     c.Query("database"),
+			Action:       action,
+			Target:       c.Param("table"),
+		}
+		if entry.Target == "" {
+			entry.Target = c.Param("sessionId")
+		}
+
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Set(ctxAudit, entry)
+
+		c.Next()
+
+		if c.GetBool(ctxAuditDetached) {
+			return
+		}
+		// 数据库类型由 authorize 记录，连接不存在或权限校验失败时为空
+		entry.DatabaseType = connectionType(c)
+		var err error
+		if writer.Status() >= http.StatusBadRequest {
+			err = errors.New(writer.errorMessage())
+		}
+		s.completeAudit(entry, err)
+	}
+}
+
+// completeAudit 计算耗时并写入审计记录，写入失败只输出日志，不影响请求
+func (s *Server) completeAudit(entry *model.AuditEntry, err error) {
+	entry.Duration = time.Since(entry.Time)
+	if err != nil && entry.Error == "" {
+		entry.Error = err.Error()
+	}
+	entry.Success = entry.Error == ""
+	if err := s.audit.Record(entry); err != nil {
+		log.Printf("写入审计日志失败: %v", err)
+	}
+}
+
+// auditEntry 返回当前请求的审计记录，未启用审计时返回 nil
+func auditEntry(c *gin.Context) *model.AuditEntry {
+	if entry, ok := c.Get(ctxAudit); ok {
+		return entry.(*model.AuditEntry)
+	}
+	return nil
+}
+
+// setAuditStatement 记录执行的语句或操作描述，database 为空时保留请求参数中的数据库
+func setAuditStatement(c *gin.Context, database, statement string) {
+	if entry := auditEntry(c); entry != nil {
+		if database != "" {
+			entry.Database = database
+		}
+		entry.Statement = statement
+	}
+}
+
+// setAuditResult 记录影响的行数；响应成功但操作失败时（如脚本中有语句出错）记录错误信息
+func setAuditResult(c *gin.Context, rows int64, errMsg string) {
+	if entry := auditEntry(c); entry != nil {
+		entry.RowsAffected = rows
+		entry.Error = errMsg
+	}
+}
+
+// detachAudit 请求结束时不写入审计记录，由调用方在后台操作结束后调用 completeAudit
+// 未启用审计时返回 nil。
+func detachAudit(c *gin.Context) *model.AuditEntry {
+	entry := auditEntry(c)
+	if entry != nil {
+		entry.DatabaseType = connectionType(c)
+		c.Set(ctxAuditDetached, true)
+	}
+	return entry
+}
+
+// ==================== 审计日志 ====================
+
+// requireAudit 未启用审计日志时返回错误
+func (s *Server) requireAudit(c *gin.Context) {
+	if s.audit == nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(400, "Audit log is disabled"))
+		return
+	}
+	c.Next()
+}
+
+// listAudit 查询审计记录，最近的在前
+// 过滤参数：userId、username、connectionId、database、action、success、from、to（RFC 3339）、q（语句包含的文本）、limit。
+func (s *Server) listAudit(c *gin.Context) {
+	filter := model.AuditFilter{
+		UserID:       c.Query("userId"),
+		Username:     c.Query("username"),
+		ConnectionID: c.Query("connectionId"),
+		Database:     c.Query("database"),
+		Action:       model.AuditAction(c.Query("action")),
+		Search:       c.Query("q"),
+	}
+
+	if raw := c.Query("success"); raw != "" {
+		success, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid success: "+raw))
+			return
+		}
+		filter.Success = &success
+	}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid "+name+": "+raw))
+			return
+		}
+		*target = t
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid limit: "+raw))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := s.audit.Query(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(entries))
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"dbm/internal/model"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// auditEntries 返回指定操作的审计记录，最近的在前
+func (ts *testServer) auditEntries(action model.AuditAction) []model.AuditEntry {
+	ts.t.Helper()
+
+	entries, err := ts.audit.Query(model.AuditFilter{Action: action})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return entries
+}
+
+func TestAudited_RecordsDenied(t *testing.T) {
+	ts := newTestServer(t)
+	user, token := ts.login("reader", model.RoleUser, model.PermMetadata, model.PermSelect)
+
+	w := ts.doJSON(http.MethodPost, "/api/v1/connections/"+ts.connID+"/tables/t/rename", token, map[string]string{"newName": "t2"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("rename without ddl: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	entries := ts.auditEntries(model.AuditRename)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 rename entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.UserID != user.ID || entry.ConnectionID != ts.connID || entry.Target != "t" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.Success || !strings.Contains(entry.Error, "Permission denied") {
+		t.Errorf("expected denied entry, got success=%v error=%q", entry.Success, entry.Error)
+	}
+}
+
+func TestAudited_SessionRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.login("reader", model.RoleUser, model.PermMetadata, model.PermSelect)
+	base := "/api/v1/connections/" + ts.connID + "/sessions"
+
+	w := ts.do(http.MethodPost, base, token, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create session: status = %d, body = %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := resp.Data.ID
+
+	// 没有事务时提交失败，同样记录
+	if w := ts.do(http.MethodPost, base+"/"+sessionID+"/commit", token, nil, nil); w.Code != http.StatusConflict {
+		t.Fatalf("commit without transaction: status = %d, body = %s", w.Code, w.Body)
+	}
+	if w := ts.do(http.MethodDelete, base+"/"+sessionID, token, nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("close session: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	entries := ts.auditEntries(model.AuditSession)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 session entries, got %d", len(entries))
+	}
+	closed, commit := entries[0], entries[1]
+	if commit.Statement != "COMMIT" || commit.Target != sessionID || commit.Success {
+		t.Errorf("unexpected commit entry: %+v", commit)
+	}
+	if closed.Statement != "CLOSE SESSION" || closed.Target != sessionID || !closed.Success {
+		t.Errorf("unexpected close entry: %+v", closed)
+	}
+	if closed.DatabaseType != model.DatabaseSQLite {
+		t.Errorf("close entry database type = %q, want sqlite", closed.DatabaseType)
+	}
+}
+
+func TestAudited_QueryRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	user, token := ts.login("reader", model.RoleUser, model.PermMetadata, model.PermSelect)
+	query := "/api/v1/connections/" + ts.connID + "/query"
+
+	// 未登录的请求在认证时拒绝，不产生审计记录
+	if w := ts.doJSON(http.MethodPost, query, "", map[string]string{"query": "SELECT 1"}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("query without credentials: status = %d", w.Code)
+	}
+	if entries := ts.auditEntries(model.AuditQuery); len(entries) != 0 {
+		t.Fatalf("unauthenticated request should not be audited, got %d entries", len(entries))
+	}
+
+	if w := ts.doJSON(http.MethodPost, query, token, map[string]string{"query": "SELECT 1 AS n"}); w.Code != http.StatusOK {
+		t.Fatalf("select: status = %d, body = %s", w.Code, w.Body)
+	}
+	// 语句类别超出权限时记录语句与拒绝原因
+	if w := ts.doJSON(http.MethodPost, query, token, map[string]string{"query": "DROP TABLE t"}); w.Code != http.StatusForbidden {
+		t.Fatalf("drop without ddl: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	entries := ts.auditEntries(model.AuditQuery)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 query entries, got %d", len(entries))
+	}
+	denied, allowed := entries[0], entries[1]
+	if !allowed.Success || allowed.Statement != "SELECT 1 AS n" || allowed.UserID != user.ID ||
+		allowed.DatabaseType != model.DatabaseSQLite {
+		t.Errorf("unexpected entry for allowed query: %+v", allowed)
+	}
+	if denied.Success || denied.Statement != "DROP TABLE t" || !strings.Contains(denied.Error, "Permission denied") {
+		t.Errorf("unexpected entry for denied query: %+v", denied)
+	}
+}
@@ -0,0 +1,369 @@
+package server
+
+import (
+	"dbm/internal/auth"
+	"dbm/internal/model"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sessionCookie 登录会话 Cookie
+	sessionCookie = "dbm_session"
+	// csrfHeader 通过 Cookie 认证的写请求携带 CSRF 令牌的请求头
+	csrfHeader = "X-CSRF-Token"
+
+	ctxUser    = "user"    // 当前用户
+	ctxSession = "session" // 当前登录会话，未启用认证时不存在
+	ctxToken   = "token"   // 当前会话令牌
+)
+
+// anonymousUser 未启用认证时的请求用户
+var anonymousUser = &model.User{Username: "anonymous", Role: model.RoleAdmin}
+
+// authEnabled 是否要求登录
+func (s *Server) authEnabled() bool {
+	return s.users != nil && s.config.Auth.Enabled
+}
+
+// currentUser 返回当前请求的用户
+func currentUser(c *gin.Context) *model.User {
+	if user, ok := c.Get(ctxUser); ok {
+		return user.(*model.User)
+	}
+	return anonymousUser
+}
+
+// authenticate 校验登录会话
+// 支持 Cookie 与 Authorization: Bearer 两种方式；Cookie 由浏览器自动携带，写请求须同时携带 CSRF 令牌。
+func (s *Server) authenticate(c *gin.Context) {
+	if !s.authEnabled() {
+		c.Set(ctxUser, anonymousUser)
+		c.Next()
+		return
+	}
+
+	token, fromCookie := requestToken(c.Request)
+	session, ok := s.authSessions.Get(token)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(401, "Authentication required"))
+		return
+	}
+	// 每次请求重新读取用户，角色变更与禁用立即生效
+	user, err := s.users.Get(session.UserID)
+	if err != nil || user.Disabled {
+		s.authSessions.Delete(token)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(401, "Authentication required"))
+		return
+	}
+	if fromCookie && !safeMethod(c.Request.Method) && !session.CheckCSRF(c.GetHeader(csrfHeader)) {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(403, "Invalid CSRF token"))
+		return
+	}
+
+	c.Set(ctxUser, user)
+	c.Set(ctxSession, session)
+	c.Set(ctxToken, token)
+	c.Next()
+}
+
+// requireAdmin 仅允许管理员访问，需在 authenticate 之后使用
+func (s *Server) requireAdmin(c *gin.Context) {
+	if currentUser(c).Role != model.RoleAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(403, "Admin role required"))
+		return
+	}
+	c.Next()
+}
+
+// requestToken 读取请求中的会话令牌，Authorization 头优先
+func requestToken(r *http.Request) (token string, fromCookie bool) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if scheme, value, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(value), false
+		}
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		return cookie.Value, true
+	}
+	return "", false
+}
+
+// safeMethod 是否为不修改数据的请求方法
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// corsMiddleware 跨域访问控制
+// 只有同源与 server.allowed_origins 中的来源可以携带凭据访问；其他来源的写请求直接拒绝，
+// 浏览器表单等无需预检的跨站请求也无法借用登录会话。
+func (s *Server) corsMiddleware() gin.HandlerFunc {
+	allowed := make(map[string]bool, len(s.config.Server.AllowedOrigins))
+	for _, origin := range s.config.Server.AllowedOrigins {
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin != "" && !sameOrigin(c.Request, origin) {
+			if !allowed[origin] {
+				if !safeMethod(c.Request.Method) {
+					c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(403, "Origin not allowed"))
+					return
+				}
+			} else {
+				header := c.Writer.Header()
+				header.Set("Access-Control-Allow-Origin", origin)
+				header.Set("Access-Control-Allow-Credentials", "true")
+				header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+csrfHeader)
+				header.Set("Access-Control-Expose-Headers", queryIDHeader)
+				header.Add("Vary", "Origin")
+			}
+		}
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// sameOrigin Origin 是否与请求的主机一致
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// ==================== 登录认证 ====================
+
+// loginRequest 登录请求
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// login 登录，成功后设置会话 Cookie 并返回令牌
+// 令牌也可以作为 Authorization: Bearer 在脚本中使用，此时无需 CSRF 令牌。
+func (s *Server) login(c *gin.Context) {
+	if !s.authEnabled() {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Authentication is disabled"))
+		return
+	}
+
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	// 按连接地址与用户名分别限流；不使用 X-Forwarded-For，避免伪造地址绕过
+	ipKey, userKey := "ip:"+c.RemoteIP(), "user:"+req.Username
+	if wait, ok := s.loginLimiter.Allow(ipKey, userKey); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		c.JSON(http.StatusTooManyRequests, errorResponse(429, "Too many failed login attempts, try again later"))
+		return
+	}
+
+	user, err := s.users.Authenticate(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			s.loginLimiter.Fail(ipKey, userKey)
+			c.JSON(http.StatusUnauthorized, errorResponse(401, "Invalid username or password"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	s.loginLimiter.Reset(userKey)
+
+	session, token := s.authSessions.Create(user.ID)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   isHTTPS(c.Request),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"user":        user,
+		"token":       token,
+		"csrfToken":   session.CSRFToken,
+		"expiresAt":   session.ExpiresAt,
+		"authEnabled": true,
+	}))
+}
+
+// logout 退出登录
+func (s *Server) logout(c *gin.Context) {
+	if token := c.GetString(ctxToken); token != "" {
+		s.authSessions.Delete(token)
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(c.Request),
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// getCurrentUser 返回当前用户与 CSRF 令牌，页面刷新后据此恢复登录状态
+func (s *Server) getCurrentUser(c *gin.Context) {
+	data := gin.H{
+		"user":        currentUser(c),
+		"authEnabled": s.authEnabled(),
+	}
+	if session, ok := c.Get(ctxSession); ok {
+		data["csrfToken"] = session.(*auth.Session).CSRFToken
+		data["expiresAt"] = session.(*auth.Session).ExpiresAt
+	}
+	c.JSON(http.StatusOK, successResponse(data))
+}
+
+// changePasswordRequest 修改密码请求
+type changePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+// changePassword 修改当前用户的密码，其他会话随之失效
+func (s *Server) changePassword(c *gin.Context) {
+	if !s.authEnabled() {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Authentication is disabled"))
+		return
+	}
+
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	user := currentUser(c)
+	userKey := "user:" + user.Username
+	if wait, ok := s.loginLimiter.Allow(userKey); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		c.JSON(http.StatusTooManyRequests, errorResponse(429, "Too many failed login attempts, try again later"))
+		return
+	}
+	if _, err := s.users.Authenticate(user.Username, req.CurrentPassword); err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			s.loginLimiter.Fail(userKey)
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Current password is incorrect"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	if _, err := s.users.Update(user.ID, auth.UserUpdate{Password: &req.NewPassword}); err != nil {
+		writeUserError(c, err)
+		return
+	}
+	s.authSessions.DeleteUser(user.ID, c.GetString(ctxToken))
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// ==================== 用户管理 ====================
+
+// createUserRequest 创建用户请求
+type createUserRequest struct {
+	Username string         `json:"username" binding:"required"`
+	Password string         `json:"password" binding:"required"`
+	Role     model.UserRole `json:"role"`
+}
+
+// listUsers 用户列表
+func (s *Server) listUsers(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(s.users.List()))
+}
+
+// createUser 创建用户
+func (s *Server) createUser(c *gin.Context) {
+	var req createUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+	if req.Role == "" {
+		req.Role = model.RoleUser
+	}
+
+	user, err := s.users.Create(req.Username, req.Password, req.Role)
+	if err != nil {
+		writeUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(user))
+}
+
+// updateUser 修改用户角色、禁用状态或重置密码
+// 禁用、降级或重置密码后，该用户已登录的会话全部失效。
+func (s *Server) updateUser(c *gin.Context) {
+	var req auth.UserUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	id := c.Param("id")
+	user, err := s.users.Update(id, req)
+	if err != nil {
+		writeUserError(c, err)
+		return
+	}
+	if req.Disabled != nil || req.Password != nil || req.Role != nil {
+		s.authSessions.DeleteUser(id, c.GetString(ctxToken))
+	}
+	c.JSON(http.StatusOK, successResponse(user))
+}
+
+// deleteUser 删除用户
+func (s *Server) deleteUser(c *gin.Context) {
+	id := c.Param("id")
+	if err := s.users.Delete(id); err != nil {
+		writeUserError(c, err)
+		return
+	}
+	s.authSessions.DeleteUser(id, "")
+	if s.policy != nil {
+		if err := s.policy.RemoveTarget(id, "", ""); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// writeUserError 按用户存储的错误类型返回状态码
+func writeUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+	case errors.Is(err, auth.ErrUserExists), errors.Is(err, auth.ErrLastAdmin):
+		c.JSON(http.StatusConflict, errorResponse(409, err.Error()))
+	case errors.Is(err, auth.ErrInvalidUsername), errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	}
+}
+
+// isHTTPS 请求是否经 HTTPS 到达（含反向代理终止 TLS 的情况）
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"dbm/internal/auth"
+	"dbm/internal/config"
+	"dbm/internal/model"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthenticate_CookieAndBearer(t *testing.T) {
+	ts := newTestServer(t)
+	if _, err := ts.users.Create("alice", "password-alice", model.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := ts.doJSON(http.MethodPost, "/api/v1/auth/login", "", map[string]string{"username": "alice", "password": "wrong"}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login with wrong password: status = %d", w.Code)
+	}
+
+	w := ts.doJSON(http.MethodPost, "/api/v1/auth/login", "", map[string]string{"username": "alice", "password": "password-alice"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status = %d, body = %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Data struct {
+			Token     string `json:"token"`
+			CSRFToken string `json:"csrfToken"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value != resp.Data.Token || !cookie.HttpOnly {
+		t.Fatalf("login should set an HttpOnly session cookie, got %+v", cookie)
+	}
+
+	withCookie := func(csrf string) http.Header {
+		header := http.Header{"Cookie": {sessionCookie + "=" + cookie.Value}}
+		if csrf != "" {
+			header.Set(csrfHeader, csrf)
+		}
+		return header
+	}
+	sessions := "/api/v1/connections/" + ts.connID + "/sessions"
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		header http.Header
+		want   int
+	}{
+		{"no credentials", http.MethodGet, "/api/v1/auth/me", "", nil, http.StatusUnauthorized},
+		{"invalid bearer", http.MethodGet, "/api/v1/auth/me", "invalid", nil, http.StatusUnauthorized},
+		{"bearer", http.MethodGet, "/api/v1/auth/me", resp.Data.Token, nil, http.StatusOK},
+		{"cookie read", http.MethodGet, "/api/v1/auth/me", "", withCookie(""), http.StatusOK},
+		// Cookie 认证的写请求必须携带 CSRF 令牌，Bearer 令牌不需要
+		{"cookie write without csrf", http.MethodPost, sessions, "", withCookie(""), http.StatusForbidden},
+		{"cookie write with wrong csrf", http.MethodPost, sessions, "", withCookie("wrong"), http.StatusForbidden},
+		{"cookie write with csrf", http.MethodPost, sessions, "", withCookie(resp.Data.CSRFToken), http.StatusOK},
+		{"bearer write", http.MethodPost, sessions, resp.Data.Token, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := ts.do(tt.method, tt.target, tt.token, nil, tt.header); w.Code != tt.want {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_DisabledUser(t *testing.T) {
+	ts := newTestServer(t)
+	user, token := ts.login("bob", model.RoleUser)
+
+	if w := ts.do(http.MethodGet, "/api/v1/auth/me", token, nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	// 禁用后已有会话立即失效
+	disabled := true
+	if _, err := ts.users.Update(user.ID, auth.UserUpdate{Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if w := ts.do(http.MethodGet, "/api/v1/auth/me", token, nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("disabled user: status = %d, want 401", w.Code)
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.AllowedOrigins = []string{"https://app.example.org/"}
+
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use((&Server{config: cfg}).corsMiddleware())
+	engine.Any("/api", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		want        int
+		allowOrigin string
+	}{
+		{"no origin", http.MethodPost, "", http.StatusOK, ""},
+		{"same origin", http.MethodPost, "http://example.com", http.StatusOK, ""},
+		{"allowed origin", http.MethodPost, "https://app.example.org", http.StatusOK, "https://app.example.org"},
+		{"allowed preflight", http.MethodOptions, "https://app.example.org", http.StatusNoContent, "https://app.example.org"},
+		// 其他来源只能发起不修改数据的请求，且响应不允许跨域读取
+		{"foreign read", http.MethodGet, "https://evil.example.net", http.StatusOK, ""},
+		{"foreign write", http.MethodPost, "https://evil.example.net", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://example.com/api", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if tt.allowOrigin != "" && w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Error("allowed origin should allow credentials")
+			}
+		})
+	}
+}
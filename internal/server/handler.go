@@ -1,19 +1,33 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"dbm/internal/adapter"
+	"dbm/internal/audit"
+	"dbm/internal/auth"
+	"dbm/internal/config"
 	"dbm/internal/connection"
 	"dbm/internal/export"
+	"dbm/internal/importer"
 	"dbm/internal/model"
 	"dbm/internal/monitor"
+	"dbm/internal/parquet"
 	"dbm/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/pprof"
+	"os"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,18 +42,34 @@ type Server struct {
 	connManager   *connection.Manager
 	connectionSvc *service.ConnectionService
 	databaseSvc   *service.DatabaseService
+	queries       *service.QueryRegistry
+	sessions      *service.SessionManager
+	restores      *service.RestoreManager
+	migrations    *service.MigrationManager
+	exports       *service.ExportManager
+	config        *config.Config
 	staticFS      http.FileSystem
 	collector     *monitor.Collector
 	registry      *prometheus.Registry
+	users         *auth.Store        // 用户存储，为 nil 时不要求登录
+	authSessions  *auth.SessionStore // 登录会话
+	loginLimiter  *auth.LoginLimiter // 登录失败限流
+	policy        *auth.Policy       // 连接权限的角色与授权，为 nil 时只有管理员可以访问连接
+	audit         *audit.Log         // 审计日志，为 nil 时不记录
 }
 
 // NewServer 创建服务器
-func NewServer(connManager *connection.Manager, staticFS http.FileSystem) *Server {
+// users 为 nil 或配置关闭认证时，所有接口无需登录；policy 决定普通用户可以访问哪些连接；
+// auditLog 为 nil 时不记录审计日志。
+func NewServer(connManager *connection.Manager, users *auth.Store, policy *auth.Policy, auditLog *audit.Log, staticFS http.FileSystem, cfg *config.Config) *Server {
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 
 	engine.Use(gin.Recovery())
-	engine.Use(corsMiddleware())
 
 	factory := adapter.DefaultFactory
 	databaseSvc := service.NewDatabaseService(connManager, factory)
@@ -57,10 +87,22 @@ func NewServer(connManager *connection.Manager, staticFS http.FileSystem) *Serve
 		connManager:   connManager,
 		connectionSvc: connectionSvc,
 		databaseSvc:   databaseSvc,
+		queries:       service.NewQueryRegistry(),
+		sessions:      service.NewSessionManager(connectionSvc, cfg.Session.IdleTimeout),
+		restores:      service.NewRestoreManager(connectionSvc),
+		migrations:    service.NewMigrationManager(connectionSvc),
+		exports:       service.NewExportManager(exportDir(cfg), cfg.Export.Retention),
+		config:        cfg,
 		staticFS:      staticFS,
 		collector:     collector,
 		registry:      registry,
+		users:         users,
+		authSessions:  auth.NewSessionStore(cfg.Auth.SessionTTL, cfg.Auth.IdleTimeout),
+		loginLimiter:  auth.NewLoginLimiter(cfg.Auth.MaxLoginAttempts, cfg.Auth.LoginLockout),
+		policy:        policy,
+		audit:         auditLog,
 	}
+	engine.Use(s.corsMiddleware())
 
 	// 初始化监控配置
 	if err := s.initMonitorMetrics(); err != nil {
@@ -72,6 +114,14 @@ func NewServer(connManager *connection.Manager, staticFS http.FileSystem) *Serve
 	return s
 }
 
+// exportDir 导出文件目录，未配置时使用临时目录
+func exportDir(cfg *config.Config) string {
+	if cfg.Export.Dir != "" {
+		return cfg.Export.Dir
+	}
+	return filepath.Join(os.TempDir(), "dbm-exports")
+}
+
 // initMonitorMetrics 初始化监控指标配置
 // 使用 InitAllScrapers 自动初始化所有支持数据库的监控
 // 添加新数据库支持时，只需在 monitor/init.go 的 dbTypeConfigFile 中添加配置文件名即可
@@ -81,61 +131,140 @@ func (s *Server) initMonitorMetrics() error {
 
 // setupRoutes 设置路由
 func (s *Server) setupRoutes() {
-	// API 路由
-	api := s.engine.Group("/api/v1")
+	// 登录无需认证
+	s.engine.POST("/api/v1/auth/login", s.login)
+
+	// API 路由，均需登录
+	api := s.engine.Group("/api/v1", s.authenticate)
 	{
-		// 连接管理
+		// 当前用户
+		api.GET("/auth/me", s.getCurrentUser)
+		api.POST("/auth/logout", s.logout)
+		api.PUT("/auth/password", s.changePassword)
+
+		// 用户管理（管理员）
+		api.GET("/users", s.requireAdmin, s.listUsers)
+		api.POST("/users", s.requireAdmin, s.createUser)
+		api.PUT("/users/:id", s.requireAdmin, s.updateUser)
+		api.DELETE("/users/:id", s.requireAdmin, s.deleteUser)
+
+		// 角色与授权（管理员）
+		api.GET("/auth/permissions", s.getPermissions)
+		api.GET("/roles", s.requireAdmin, s.requirePolicy, s.listRoles)
+		api.POST("/roles", s.requireAdmin, s.requirePolicy, s.saveRole)
+		api.PUT("/roles/:name", s.requireAdmin, s.requirePolicy, s.saveRole)
+		api.DELETE("/roles/:name", s.requireAdmin, s.requirePolicy, s.deleteRole)
+		api.GET("/grants", s.requireAdmin, s.requirePolicy, s.listGrants)
+		api.POST("/grants", s.requireAdmin, s.requirePolicy, s.createGrant)
+		api.DELETE("/grants/:id", s.requireAdmin, s.requirePolicy, s.deleteGrant)
+
+		// 审计日志（管理员）
+		api.GET("/audit", s.requireAdmin, s.requireAudit, s.listAudit)
+
+		// 各操作需要的连接权限
+		var (
+			canView    = s.authorize(model.PermMetadata)
+			canQuery   = s.authorize(model.PermSelect)
+			canAlter   = s.authorize(model.PermDDL)
+			canEdit    = s.authorize(model.PermEdit)
+			canRestore = s.authorize(model.PermDML, model.PermDDL)
+			canExport  = s.authorize(model.PermExport)
+		)
+
+		// 连接管理（创建、修改、删除连接需要管理员）
 		api.GET("/connections", s.listConnections)
-		api.POST("/connections", s.createConnection)
-		api.PUT("/connections/:id", s.updateConnection)
-		api.DELETE("/connections/:id", s.deleteConnection)
-		api.POST("/connections/:id/connect", s.connectConnection)
-		api.POST("/connections/:id/close", s.closeConnection)
-		api.POST("/connections/test", s.testConnectionConfig)
-		api.POST("/connections/:id/test", s.testConnection)
+		api.POST("/connections", s.requireAdmin, s.createConnection)
+		api.PUT("/connections/:id", s.requireAdmin, s.updateConnection)
+		api.DELETE("/connections/:id", s.requireAdmin, s.deleteConnection)
+		api.GET("/connections/:id/details", s.authorize(model.PermCredentials), s.getConnectionDetails)
+		api.POST("/connections/:id/connect", canView, s.connectConnection)
+		api.POST("/connections/:id/close", canView, s.closeConnection)
+		api.GET("/connections/:id/pool", canView, s.getPoolStats)
+		api.POST("/connections/test", s.requireAdmin, s.testConnectionConfig)
+		api.POST("/connections/:id/test", canView, s.testConnection)
 
 		// 数据库元数据
-		api.GET("/connections/:id/databases", s.getDatabases)
-		api.GET("/connections/:id/schemas", s.getSchemas)
-		api.GET("/connections/:id/tables", s.getTables)
-		api.GET("/connections/:id/tables/:table/schema", s.getTableSchema)
-		api.GET("/connections/:id/views", s.getViews)
-		api.GET("/connections/:id/views/:view/definition", s.getViewDefinition)
-		api.GET("/connections/:id/procedures", s.getProcedures)
-		api.GET("/connections/:id/functions", s.getFunctions)
-		api.GET("/connections/:id/routines/:routine/definition", s.getRoutineDefinition)
+		api.GET("/connections/:id/databases", canView, s.getDatabases)
+		api.GET("/connections/:id/schemas", canView, s.getSchemas)
+		api.GET("/connections/:id/tables", canView, s.getTables)
+		api.GET("/connections/:id/tables/:table/schema", canView, s.getTableSchema)
+		api.GET("/connections/:id/views", canView, s.getViews)
+		api.GET("/connections/:id/views/:view/definition", canView, s.getViewDefinition)
+		api.GET("/connections/:id/procedures", canView, s.getProcedures)
+		api.GET("/connections/:id/functions", canView, s.getFunctions)
+		api.GET("/connections/:id/routines/:routine/definition", canView, s.getRoutineDefinition)
 
 		// 表结构修改
-		api.POST("/connections/:id/tables/:table/alter", s.alterTable)
-		api.POST("/connections/:id/tables/:table/rename", s.renameTable)
+		api.POST("/connections/:id/tables/:table/alter", s.audited(model.AuditAlter), canAlter, s.alterTable)
+		api.POST("/connections/:id/tables/:table/rename", s.audited(model.AuditRename), canAlter, s.renameTable)
 
 		// 数据编辑
-		api.POST("/connections/:id/tables/:table/data", s.createData)
-		api.PUT("/connections/:id/tables/:table/data", s.updateData)
-		api.DELETE("/connections/:id/tables/:table/data", s.deleteData)
-
-		// SQL 执行
-		api.POST("/connections/:id/query", s.executeQuery)
-		api.POST("/connections/:id/execute", s.executeNonQuery)
+		api.POST("/connections/:id/tables/:table/data", s.audited(model.AuditDataEdit), canEdit, s.createData)
+		api.PUT("/connections/:id/tables/:table/data", s.audited(model.AuditDataEdit), canEdit, s.updateData)
+		api.DELETE("/connections/:id/tables/:table/data", s.audited(model.AuditDataEdit), canEdit, s.deleteData)
+		api.POST("/connections/:id/tables/:table/changes", s.audited(model.AuditDataEdit), canEdit, s.applyChanges)
+		api.GET("/connections/:id/tables/:table/cell", canQuery, s.readCell)
+		api.PUT("/connections/:id/tables/:table/cell", s.audited(model.AuditDataEdit), canEdit, s.writeCell)
+
+		// 数据导入
+		api.POST("/connections/:id/tables/:table/import/csv", s.audited(model.AuditImport), canEdit, s.importCSV)
+		api.POST("/connections/:id/tables/:table/import/parquet", s.audited(model.AuditImport), canEdit, s.importParquet)
+		api.POST("/connections/:id/restore", s.audited(model.AuditImport), canRestore, s.startRestore)
+		api.GET("/connections/:id/restore", canRestore, s.listRestores)
+		api.GET("/connections/:id/restore/:jobId", canRestore, s.getRestore)
+		api.GET("/connections/:id/restore/:jobId/events", canRestore, s.restoreEvents)
+		api.POST("/connections/:id/restore/:jobId/cancel", canRestore, s.cancelRestore)
+
+		// 跨连接迁移（源连接需要导出权限，目标连接在处理函数中校验）
+		api.POST("/connections/:id/migrations/preview", canExport, s.previewMigration)
+		api.POST("/connections/:id/migrations", canExport, s.startMigration)
+		api.GET("/connections/:id/migrations", canExport, s.listMigrations)
+		api.GET("/connections/:id/migrations/:jobId", canExport, s.getMigration)
+		api.GET("/connections/:id/migrations/:jobId/events", canExport, s.migrationEvents)
+		api.POST("/connections/:id/migrations/:jobId/cancel", canExport, s.cancelMigration)
+		api.POST("/connections/:id/migrations/:jobId/resume", canExport, s.resumeMigration)
+
+		// SQL 执行（按语句类别在处理函数中校验 DML、DDL 权限）
+		api.POST("/connections/:id/query", s.audited(model.AuditQuery), canQuery, s.executeQuery)
+		api.POST("/connections/:id/execute", s.audited(model.AuditExecute), canQuery, s.executeNonQuery)
+		api.POST("/connections/:id/script", s.audited(model.AuditScript), canQuery, s.executeScript)
+		api.GET("/connections/:id/queries", canQuery, s.listRunningQueries)
+		api.POST("/connections/:id/queries/:queryId/cancel", canQuery, s.cancelQuery)
+
+		// SQL 会话（事务）
+		api.GET("/connections/:id/sessions", canQuery, s.listSessions)
+		api.POST("/connections/:id/sessions", canQuery, s.createSession)
+		api.GET("/connections/:id/sessions/:sessionId", canQuery, s.getSession)
+		api.POST("/connections/:id/sessions/:sessionId/commit", s.audited(model.AuditSession), canQuery, s.commitSession)
+		api.POST("/connections/:id/sessions/:sessionId/rollback", s.audited(model.AuditSession), canQuery, s.rollbackSession)
+		api.DELETE("/connections/:id/sessions/:sessionId", s.audited(model.AuditSession), canQuery, s.closeSession)
 
 		// 导出
-		api.POST("/connections/:id/export/csv", s.exportCSV)
-		api.POST("/connections/:id/export/sql", s.exportSQL)
-		api.POST("/connections/:id/export/sql/preview", s.previewExportSQL)
+		api.POST("/connections/:id/export/csv", s.audited(model.AuditExport), canExport, s.exportCSV)
+		api.POST("/connections/:id/export/sql", s.audited(model.AuditExport), canExport, s.exportSQL)
+		api.POST("/connections/:id/export/sql/preview", canExport, s.previewExportSQL)
+		api.POST("/connections/:id/export/:format", s.audited(model.AuditExport), canExport, s.exportFormat)
+
+		// 后台导出任务
+		api.POST("/connections/:id/export/jobs", s.audited(model.AuditExport), canExport, s.startExport)
+		api.GET("/jobs", s.listExports)
+		api.GET("/jobs/:id", s.authorizeJob(model.PermExport), s.getExport)
+		api.DELETE("/jobs/:id", s.authorizeJob(model.PermExport), s.deleteExport)
+		api.GET("/jobs/:id/download", s.authorizeJob(model.PermExport), s.downloadExport)
 
 		// 分组管理
 		api.GET("/groups", s.listGroups)
-		api.POST("/groups", s.createGroup)
-		api.PUT("/groups/:id", s.updateGroup)
-		api.DELETE("/groups/:id", s.deleteGroup)
+		api.POST("/groups", s.requireAdmin, s.createGroup)
+		api.PUT("/groups/:id", s.requireAdmin, s.updateGroup)
+		api.DELETE("/groups/:id", s.requireAdmin, s.deleteGroup)
 
 		// 监控
 		api.GET("/monitor/stats", s.getMonitorStats)
 	}
 
-	// pprof 路由
-	debug := s.engine.Group("/debug/pprof")
-	{
+	// pprof 路由，仅在 server.debug 开启时注册，且只允许管理员访问
+	if s.config.Server.Debug {
+		debug := s.engine.Group("/debug/pprof", s.authenticate, s.requireAdmin)
 		debug.GET("/", gin.WrapF(pprof.Index))
 		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
 		debug.GET("/profile", gin.WrapF(pprof.Profile))
@@ -182,17 +311,38 @@ func (s *Server) Run(addr string) error {
 	return s.engine.Run(addr)
 }
 
+// Close 关闭服务器持有的会话并取消进行中的导入、迁移与导出任务，未提交的事务会被回滚
+func (s *Server) Close() {
+	s.restores.Shutdown()
+	s.migrations.Shutdown()
+	s.exports.Shutdown()
+	s.sessions.Shutdown()
+}
+
 // ==================== 连接管理 ====================
 
-// listConnections 获取连接列表
+// listConnections 获取连接列表，普通用户只能看到有元数据权限的连接
 func (s *Server) listConnections(c *gin.Context) {
 	configs, err := s.connManager.ListConfigs()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	groups, err := s.groupMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	user := currentUser(c)
+	visible := make([]*model.ConnectionConfig, 0, len(configs))
+	for _, config := range configs {
+		if s.permissions(user, config, groups).Has(model.PermMetadata) {
+			visible = append(visible, config)
+		}
+	}
 
-	c.JSON(http.StatusOK, successResponse(configs))
+	c.JSON(http.StatusOK, successResponse(visible))
 }
 
 // createConnection 创建连接
@@ -216,9 +366,8 @@ func (s *Server) createConnection(c *gin.Context) {
 		return
 	}
 
-	// 返回时不包含密码
-	config.Password = ""
-	c.JSON(http.StatusOK, successResponse(config))
+	// 返回时不包含密码、SSH 凭据与 TLS 证书
+	c.JSON(http.StatusOK, successResponse(config.Masked()))
 }
 
 // updateConnection 更新连接
@@ -236,11 +385,10 @@ func (s *Server) updateConnection(c *gin.Context) {
 	config.UpdatedAt = time.Now()
 
 	// 如果密码为空，保留原有密码
-	if config.Password == "" {
-		if existing, err := s.connManager.GetConfig(id); err == nil {
-			config.Password = existing.Password
-		}
-	}
+	s.keepSecrets(&config)
+
+	// 配置变更后原有会话不再有效
+	s.sessions.CloseConnection(id)
 
 	// 添加新配置
 	if err := s.connManager.AddConnection(&config); err != nil {
@@ -248,19 +396,77 @@ func (s *Server) updateConnection(c *gin.Context) {
 		return
 	}
 
-	// 返回时不包含密码
-	config.Password = ""
-	c.JSON(http.StatusOK, successResponse(config))
+	// 返回时不包含密码、SSH 凭据与 TLS 证书
+	c.JSON(http.StatusOK, successResponse(config.Masked()))
+}
+
+// keepSecrets 未填写的数据库密码、SSH 凭据与 TLS 证书沿用已保存的配置
+// 连接列表不返回这些字段，编辑表单留空表示不修改。
+func (s *Server) keepSecrets(config *model.ConnectionConfig) {
+	existing, err := s.connManager.GetConfig(config.ID)
+	if err != nil {
+		return
+	}
+
+	if config.Password == "" {
+		config.Password = existing.Password
+	}
+
+	if config.TLS != nil && existing.TLS != nil {
+		saved := existing.TLS.Secrets()
+		for i, secret := range config.TLS.Secrets() {
+			if *secret == "" {
+				*secret = *saved[i]
+			}
+		}
+	}
+
+	if config.SSH == nil || existing.SSH == nil {
+		return
+	}
+	saved := existing.SSH.Hops()
+	hops := config.SSH.Hops()
+	bastion := hops[len(hops)-1]
+	keepHopSecrets(&bastion, saved[len(saved)-1])
+	config.SSH.Password, config.SSH.PrivateKey, config.SSH.Passphrase = bastion.Password, bastion.PrivateKey, bastion.Passphrase
+	for i := range config.SSH.Jumps {
+		if i < len(existing.SSH.Jumps) {
+			keepHopSecrets(&config.SSH.Jumps[i], existing.SSH.Jumps[i])
+		}
+	}
+}
+
+// keepHopSecrets SSH 主机与用户未变时沿用已保存的凭据，避免把凭据发给其他主机
+func keepHopSecrets(hop *model.SSHHop, saved model.SSHHop) {
+	if hop.Host != saved.Host || hop.User != saved.User {
+		return
+	}
+	if hop.Password == "" {
+		hop.Password = saved.Password
+	}
+	if hop.PrivateKey == "" {
+		hop.PrivateKey = saved.PrivateKey
+		if hop.Passphrase == "" {
+			hop.Passphrase = saved.Passphrase
+		}
+	}
 }
 
 // deleteConnection 删除连接
 func (s *Server) deleteConnection(c *gin.Context) {
 	id := c.Param("id")
 
+	s.sessions.CloseConnection(id)
 	if err := s.connManager.RemoveConnection(id); err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	if s.policy != nil {
+		if err := s.policy.RemoveTarget("", id, ""); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, successResponse(nil))
 }
@@ -290,6 +496,7 @@ func (s *Server) testConnection(c *gin.Context) {
 		c.JSON(http.StatusOK, successResponse(map[string]interface{}{
 			"connected": true,
 			"latency":   "< 10ms",
+			"tls":       result.TLS,
 		}))
 	} else {
 		c.JSON(http.StatusOK, successResponse(map[string]interface{}{
@@ -303,6 +510,7 @@ func (s *Server) testConnection(c *gin.Context) {
 func (s *Server) closeConnection(c *gin.Context) {
 	id := c.Param("id")
 
+	s.sessions.CloseConnection(id)
 	if err := s.connectionSvc.CloseConnection(id); err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
@@ -311,16 +519,34 @@ func (s *Server) closeConnection(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(nil))
 }
 
+// getPoolStats 获取连接池统计信息
+func (s *Server) getPoolStats(c *gin.Context) {
+	id := c.Param("id")
+
+	stats, err := s.connectionSvc.PoolStats(id)
+	if err != nil {
+		if err == connection.ErrConnectionNotFound {
+			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(stats))
+}
+
 // connectConnection 建立连接并缓存
 func (s *Server) connectConnection(c *gin.Context) {
 	id := c.Param("id")
 
-	// GetDBCached 会自动将主连接放入连接池
-	_, _, err := s.connectionSvc.GetDBCached(id, "")
+	// GetDB 会自动将主连接放入连接池
+	_, _, release, err := s.connectionSvc.GetDB(id, "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	release()
 
 	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
 		"connected": true,
@@ -336,6 +562,11 @@ func (s *Server) testConnectionConfig(c *gin.Context) {
 		return
 	}
 
+	// 编辑已保存的连接时，未填写的密码沿用已保存的配置
+	if config.ID != "" {
+		s.keepSecrets(&config)
+	}
+
 	// 测试连接
 	result, err := s.connectionSvc.TestConnection(&config)
 	if err != nil {
@@ -347,6 +578,7 @@ func (s *Server) testConnectionConfig(c *gin.Context) {
 		c.JSON(http.StatusOK, successResponse(map[string]interface{}{
 			"connected": true,
 			"latency":   "< 10ms",
+			"tls":       result.TLS,
 		}))
 	} else {
 		c.JSON(http.StatusOK, successResponse(map[string]interface{}{
@@ -358,14 +590,44 @@ func (s *Server) testConnectionConfig(c *gin.Context) {
 
 // ==================== 分组管理 ====================
 
-// listGroups 获取所有分组
+// listGroups 获取所有分组，普通用户只能看到包含可见连接的分组
 func (s *Server) listGroups(c *gin.Context) {
 	groups, err := s.connManager.ListGroups()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, successResponse(groups))
+	user := currentUser(c)
+	if user.Role == model.RoleAdmin {
+		c.JSON(http.StatusOK, successResponse(groups))
+		return
+	}
+
+	configs, err := s.connManager.ListConfigs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	byID, err := s.groupMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	used := make(map[string]bool)
+	for _, config := range configs {
+		if s.permissions(user, config, byID).Has(model.PermMetadata) {
+			for _, id := range groupChain(byID, config.GroupID) {
+				used[id] = true
+			}
+		}
+	}
+	visible := make([]*model.Group, 0, len(used))
+	for _, group := range groups {
+		if used[group.ID] {
+			visible = append(visible, group)
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(visible))
 }
 
 // createGroup 创建分组
@@ -413,6 +675,12 @@ func (s *Server) deleteGroup(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	if s.policy != nil {
+		if err := s.policy.RemoveTarget("", "", id); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+			return
+		}
+	}
 	c.JSON(http.StatusOK, successResponse(nil))
 }
 
@@ -423,11 +691,12 @@ func (s *Server) getSchemas(c *gin.Context) {
 	id := c.Param("id")
 	database := c.DefaultQuery("database", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -464,11 +733,12 @@ func (s *Server) getSchemas(c *gin.Context) {
 func (s *Server) getDatabases(c *gin.Context) {
 	id := c.Param("id")
 
-	db, config, err := s.connectionSvc.GetDB(id, "")
+	db, config, release, err := s.connectionSvc.GetDB(id, "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -492,11 +762,12 @@ func (s *Server) getTables(c *gin.Context) {
 	database := c.DefaultQuery("database", "")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -540,11 +811,12 @@ func (s *Server) getTableSchema(c *gin.Context) {
 	database := c.Query("database")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -579,11 +851,12 @@ func (s *Server) getViews(c *gin.Context) {
 	database := c.DefaultQuery("database", "")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -618,11 +891,12 @@ func (s *Server) getProcedures(c *gin.Context) {
 	database := c.DefaultQuery("database", "")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -657,11 +931,12 @@ func (s *Server) getFunctions(c *gin.Context) {
 	database := c.DefaultQuery("database", "")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -697,11 +972,12 @@ func (s *Server) getViewDefinition(c *gin.Context) {
 	database := c.DefaultQuery("database", "")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -737,11 +1013,12 @@ func (s *Server) getRoutineDefinition(c *gin.Context) {
 	database := c.DefaultQuery("database", "")
 	schema := c.DefaultQuery("schema", "")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 	defer func() {}() // 保持连接打开
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -776,8 +1053,10 @@ func (s *Server) executeQuery(c *gin.Context) {
 	id := c.Param("id")
 
 	var req struct {
-		Query string              `json:"query"`
-		Opts  *model.QueryOptions `json:"opts"`
+		Query     string              `json:"query"`
+		QueryID   string              `json:"queryId"`   // 客户端指定的查询 ID，用于取消
+		SessionID string              `json:"sessionId"` // 会话 ID，指定时在会话连接上执行
+		Opts      *model.QueryOptions `json:"opts"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -789,17 +1068,42 @@ func (s *Server) executeQuery(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse(400, "Query cannot be empty"))
 		return
 	}
+	if req.Opts == nil {
+		req.Opts = &model.QueryOptions{}
+	}
+	setAuditStatement(c, req.Opts.Database, req.Query)
+	if !authorizeSQL(c, req.Query) {
+		return
+	}
+
+	// 行数上限由服务端配置决定，不接受客户端指定
+	req.Opts.MaxRows = s.config.Query.MaxRows
+	req.Opts.CountLimit = s.config.Query.CountLimit
+	req.Opts.MaxValueSize = s.config.Query.MaxValueSize
+
+	if req.SessionID != "" {
+		ctx, done, ok := s.registerQuery(c, id, req.QueryID, req.Query)
+		if !ok {
+			return
+		}
+		defer done()
 
-	database := ""
-	if req.Opts != nil {
-		database = req.Opts.Database
+		result, err := s.sessions.Query(ctx, id, req.SessionID, ownerFilter(c), req.Query, req.Opts)
+		if err != nil {
+			s.sessionError(c, ctx, err)
+			return
+		}
+		setAuditResult(c, queryRows(result), "")
+		c.JSON(http.StatusOK, successResponse(result))
+		return
 	}
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, req.Opts.Database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
 	if err != nil {
@@ -807,21 +1111,43 @@ func (s *Server) executeQuery(c *gin.Context) {
 		return
 	}
 
-	result, err := dbAdapter.Query(db, req.Query, req.Opts)
+	// 客户端断开或调用取消接口时终止查询
+	ctx, done, ok := s.registerQuery(c, id, req.QueryID, req.Query)
+	if !ok {
+		return
+	}
+	defer done()
+
+	result, err := dbAdapter.QueryContext(ctx, db, req.Query, req.Opts)
 	if err != nil {
+		if ctx.Err() != nil {
+			c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Query cancelled"))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
 
+	setAuditResult(c, queryRows(result), "")
 	c.JSON(http.StatusOK, successResponse(result))
 }
 
+// queryRows 审计记录中查询的行数：修改数据的语句为影响行数，否则为返回的行数
+func queryRows(result *model.QueryResult) int64 {
+	if result.RowsAffected > 0 {
+		return result.RowsAffected
+	}
+	return int64(len(result.Rows))
+}
+
 // executeNonQuery 执行非查询 SQL
 func (s *Server) executeNonQuery(c *gin.Context) {
 	id := c.Param("id")
 
 	var req struct {
-		Query string `json:"query"`
+		Query     string `json:"query"`
+		QueryID   string `json:"queryId"`   // 客户端指定的查询 ID，用于取消
+		SessionID string `json:"sessionId"` // 会话 ID，指定时在会话连接上执行
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -833,14 +1159,36 @@ func (s *Server) executeNonQuery(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse(400, "Query cannot be empty"))
 		return
 	}
+	setAuditStatement(c, "", req.Query)
+	if !authorizeSQL(c, req.Query) {
+		return
+	}
+
+	if req.SessionID != "" {
+		ctx, done, ok := s.registerQuery(c, id, req.QueryID, req.Query)
+		if !ok {
+			return
+		}
+		defer done()
+
+		result, err := s.sessions.Execute(ctx, id, req.SessionID, ownerFilter(c), req.Query)
+		if err != nil {
+			s.sessionError(c, ctx, err)
+			return
+		}
+		setAuditResult(c, result.RowsAffected, "")
+		c.JSON(http.StatusOK, successResponse(result))
+		return
+	}
 
 	database := c.Query("database")
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
 	if err != nil {
@@ -848,24 +1196,35 @@ func (s *Server) executeNonQuery(c *gin.Context) {
 		return
 	}
 
-	result, err := dbAdapter.Execute(db, req.Query)
+	ctx, done, ok := s.registerQuery(c, id, req.QueryID, req.Query)
+	if !ok {
+		return
+	}
+	defer done()
+
+	result, err := dbAdapter.ExecuteContext(ctx, db, req.Query)
 	if err != nil {
+		if ctx.Err() != nil {
+			c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Query cancelled"))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
 
+	setAuditResult(c, result.RowsAffected, "")
 	c.JSON(http.StatusOK, successResponse(result))
 }
 
-// ==================== 导出 ====================
-
-// exportCSV CSV 导出
-func (s *Server) exportCSV(c *gin.Context) {
+// executeScript 执行多语句脚本，返回每条语句的结果
+func (s *Server) executeScript(c *gin.Context) {
 	id := c.Param("id")
 
 	var req struct {
-		Query string            `json:"query"`
-		Opts  *model.CSVOptions `json:"opts"`
+		Script    string `json:"script"`
+		QueryID   string `json:"queryId"`   // 客户端指定的查询 ID，用于取消
+		SessionID string `json:"sessionId"` // 会话 ID，为空时使用临时会话
+		model.ScriptOptions
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -873,399 +1232,1736 @@ func (s *Server) exportCSV(c *gin.Context) {
 		return
 	}
 
-	database := c.Query("database")
-
-	db, config, err := s.connectionSvc.GetDB(id, database)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
-	}
-
-	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	if strings.TrimSpace(req.Script) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Script cannot be empty"))
 		return
 	}
-
-	// 设置响应头
-	c.Header("Content-Type", "text/csv; charset=utf-8")
-	c.Header("Content-Disposition", "attachment; filename=export.csv")
-
-	// 执行导出
-	err = dbAdapter.ExportToCSV(db, c.Writer, database, req.Query, req.Opts)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	setAuditStatement(c, req.Database, req.Script)
+	if !authorizeSQL(c, req.Script) {
 		return
 	}
-}
-
-// exportSQL SQL 导出
-func (s *Server) exportSQL(c *gin.Context) {
-	id := c.Param("id")
 
-	var req struct {
-		Tables []string          `json:"tables"`
-		Opts   *model.SQLOptions `json:"opts"`
+	queryOpts := &model.QueryOptions{
+		Database:     req.Database,
+		MaxRows:      s.config.Query.MaxRows,
+		CountLimit:   s.config.Query.CountLimit,
+		MaxValueSize: s.config.Query.MaxValueSize,
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+	ctx, done, ok := s.registerQuery(c, id, req.QueryID, req.Script)
+	if !ok {
 		return
 	}
+	defer done()
 
-	if len(req.Tables) == 0 && (req.Opts == nil || req.Opts.Query == "") {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "No tables or query specified"))
+	result, err := s.sessions.RunScript(ctx, id, req.SessionID, ownerFilter(c), req.Script, &req.ScriptOptions, queryOpts)
+	if err != nil {
+		if err == connection.ErrConnectionNotFound {
+			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+			return
+		}
+		s.sessionError(c, ctx, err)
 		return
 	}
 
-	database := c.Query("database")
+	setAuditResult(c, scriptRows(result), scriptError(result))
+	c.JSON(http.StatusOK, successResponse(result))
+}
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
+// scriptRows 脚本中各语句影响的行数之和
+func scriptRows(result *model.ScriptResult) int64 {
+	var rows int64
+	for _, stmt := range result.Statements {
+		if stmt.Result != nil {
+			rows += stmt.Result.RowsAffected
+		}
 	}
+	return rows
+}
 
-	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
+// scriptError 脚本中有语句失败时返回第一条错误
+func scriptError(result *model.ScriptResult) string {
+	for _, stmt := range result.Statements {
+		if stmt.Status == model.StatementError {
+			return fmt.Sprintf("statement %d (line %d): %s", stmt.Index+1, stmt.Line, stmt.Error)
+		}
 	}
+	return ""
+}
 
-	// 设置响应头
-	c.Header("Content-Type", "text/sql; charset=utf-8")
-	c.Header("Content-Disposition", "attachment; filename=export.sql")
-
-	// 执行导出
-	err = dbAdapter.ExportToSQL(db, c.Writer, database, req.Tables, req.Opts)
+// registerQuery 登记查询以便取消，查询 ID 通过 X-Query-ID 响应头返回
+// 客户端未指定 queryId 时可在查询运行期间通过运行中查询列表获取 ID；ID 重复时返回 409。
+func (s *Server) registerQuery(c *gin.Context, connectionID, queryID, query string) (context.Context, func(), bool) {
+	ctx, queryID, done, err := s.queries.Register(c.Request.Context(), connectionID, currentUser(c).ID, queryID, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
+		c.JSON(http.StatusConflict, errorResponse(409, err.Error()))
+		return nil, nil, false
 	}
+	c.Header(queryIDHeader, queryID)
+	return ctx, done, true
 }
 
-// previewExportSQL 预览 SQL 导出的类型映射
-func (s *Server) previewExportSQL(c *gin.Context) {
+// listRunningQueries 获取连接下当前用户正在执行的查询，管理员可以看到所有用户的查询
+func (s *Server) listRunningQueries(c *gin.Context) {
 	id := c.Param("id")
+	c.JSON(http.StatusOK, successResponse(s.queries.List(id, ownerFilter(c))))
+}
 
-	var req struct {
-		Tables       []string           `json:"tables"`
-		TargetDBType model.DatabaseType `json:"targetDbType"` // 目标数据库类型
-	}
+// cancelQuery 按 ID 取消正在执行的查询，普通用户只能取消自己的查询
+func (s *Server) cancelQuery(c *gin.Context) {
+	id := c.Param("id")
+	queryID := c.Param("queryId")
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+	if !s.queries.Cancel(id, queryID, ownerFilter(c)) {
+		c.JSON(http.StatusNotFound, errorResponse(404, "Query not found"))
 		return
 	}
 
-	if len(req.Tables) == 0 {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "No tables specified"))
-		return
-	}
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"queryId":   queryID,
+		"cancelled": true,
+	}))
+}
 
-	// 验证目标数据库类型
-	if req.TargetDBType == "" {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Target database type required"))
-		return
+// ==================== SQL 会话 ====================
+
+// listSessions 获取连接下当前用户的会话，管理员可以看到所有用户的会话
+func (s *Server) listSessions(c *gin.Context) {
+	id := c.Param("id")
+	c.JSON(http.StatusOK, successResponse(s.sessions.List(id, ownerFilter(c))))
+}
+
+// createSession 创建会话
+func (s *Server) createSession(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		Database string `json:"database"`
+	}
+	// 请求体可为空
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+			return
+		}
 	}
 
-	// 获取连接配置
-	config, err := s.connManager.GetConfig(id)
+	session, err := s.sessions.Create(c.Request.Context(), id, req.Database, currentUser(c).ID)
 	if err != nil {
 		if err == connection.ErrConnectionNotFound {
 			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
 			return
 		}
+		if err == adapter.ErrSessionUnsupported {
+			c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
 
-	// 获取数据库连接
-	db, _, err := s.connectionSvc.GetDB(id, "")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
-	}
+	c.JSON(http.StatusOK, successResponse(session))
+}
 
-	// 获取适配器
-	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+// getSession 获取会话信息
+func (s *Server) getSession(c *gin.Context) {
+	session, err := s.sessions.Get(c.Param("id"), c.Param("sessionId"), ownerFilter(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
 		return
 	}
 
-	// 获取所有表的列信息
-	var allColumns []model.ColumnInfo
-	for _, table := range req.Tables {
-		schema, err := dbAdapter.GetTableSchema(db, "", table)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to get table schema: "+table))
-			return
-		}
-		allColumns = append(allColumns, schema.Columns...)
-	}
+	c.JSON(http.StatusOK, successResponse(session))
+}
 
-	// 执行类型映射
-	mapper, err := export.LoadDefaultConfig()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to load type mapper"))
+// commitSession 提交会话中的事务
+func (s *Server) commitSession(c *gin.Context) {
+	setAuditStatement(c, "", "COMMIT")
+	if err := s.sessions.Commit(c.Param("id"), c.Param("sessionId"), ownerFilter(c)); err != nil {
+		s.sessionError(c, c.Request.Context(), err)
 		return
 	}
 
-	// 如果是同类型数据库迁移，不需要类型映射
-	if config.Type == req.TargetDBType {
-		// 返回直接映射结果
-		result := &export.TypeMappingResult{
-			Success: true,
-			Mapped:  make(map[string]string),
-			Summary: export.TypeSummary{
-				Total:  len(allColumns),
-				Direct: len(allColumns),
-			},
-		}
-		for _, col := range allColumns {
-			result.Mapped[col.Type] = col.Type
-		}
-		c.JSON(http.StatusOK, successResponse(result))
-		return
-	}
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"committed": true,
+	}))
+}
 
-	result, err := mapper.MapTypes(config.Type, req.TargetDBType, allColumns)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+// rollbackSession 回滚会话中的事务
+func (s *Server) rollbackSession(c *gin.Context) {
+	setAuditStatement(c, "", "ROLLBACK")
+	if err := s.sessions.Rollback(c.Param("id"), c.Param("sessionId"), ownerFilter(c)); err != nil {
+		s.sessionError(c, c.Request.Context(), err)
 		return
 	}
 
-	c.JSON(http.StatusOK, successResponse(result))
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"rolledBack": true,
+	}))
+}
+
+// closeSession 关闭会话，未提交的事务会被回滚
+func (s *Server) closeSession(c *gin.Context) {
+	setAuditStatement(c, "", "CLOSE SESSION")
+	if err := s.sessions.Close(c.Param("id"), c.Param("sessionId"), ownerFilter(c)); err != nil {
+		s.sessionError(c, c.Request.Context(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// sessionError 输出会话相关错误
+func (s *Server) sessionError(c *gin.Context, ctx context.Context, err error) {
+	switch {
+	case err == service.ErrSessionNotFound:
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+	case err == service.ErrSessionBusy || err == adapter.ErrNoTransaction || err == adapter.ErrTransactionActive:
+		c.JSON(http.StatusConflict, errorResponse(409, err.Error()))
+	case ctx.Err() != nil:
+		c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Query cancelled"))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	}
+}
+
+// ==================== 导出 ====================
+
+// exportRequest 导出请求：指定 query 时导出查询结果，否则依次导出 tables 中的表
+// opts 按格式解析为 CSVOptions、SQLOptions 等；SQL 导出的查询也可以放在 opts.query 中。
+type exportRequest struct {
+	Format string          `json:"format"`
+	Query  string          `json:"query"`
+	Tables []string        `json:"tables"`
+	Opts   json.RawMessage `json:"opts"`
+}
+
+// exportPlan 校验通过的导出：文件名、内容类型与写出函数
+// 导出结束后调用 release 释放连接占用。
+type exportPlan struct {
+	fileName    string
+	contentType string
+	run         service.ExportTask
+	release     func()
+}
+
+// decodeOptions 解析导出选项，未提供时保留 opts 的默认值
+func decodeOptions(raw json.RawMessage, opts any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.Unmarshal(raw, opts)
+}
+
+// prepareExport 校验导出请求并创建写出函数，失败时返回响应状态码
+// 写出函数既可以直接写入 HTTP 响应，也可以交给后台导出任务写入文件；
+// 导出自定义查询时由 checkQuery 校验当前用户能否执行该语句。
+func (s *Server) prepareExport(id, database string, req *exportRequest, checkQuery func(query string) error) (*exportPlan, int, error) {
+	var (
+		plan  *exportPlan
+		query = req.Query
+		build func(db any, dbAdapter adapter.DatabaseAdapter, dbType model.DatabaseType) (service.ExportTask, error)
+	)
+
+	switch req.Format {
+	case "csv":
+		opts := &model.CSVOptions{}
+		if err := decodeOptions(req.Opts, opts); err != nil {
+			return nil, http.StatusBadRequest, errors.New("Invalid export options: " + err.Error())
+		}
+		charset, err := export.CSVCharset(opts.Encoding)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if req.Query == "" {
+			return nil, http.StatusBadRequest, errors.New("No query specified")
+		}
+		plan = &exportPlan{fileName: "export.csv", contentType: "text/csv; charset=" + charset}
+		build = func(db any, dbAdapter adapter.DatabaseAdapter, _ model.DatabaseType) (service.ExportTask, error) {
+			return func(ctx context.Context, w io.Writer) error {
+				return dbAdapter.ExportToCSV(ctx, db, w, database, req.Query, opts)
+			}, nil
+		}
+
+	case "sql":
+		opts := &model.SQLOptions{}
+		if err := decodeOptions(req.Opts, opts); err != nil {
+			return nil, http.StatusBadRequest, errors.New("Invalid export options: " + err.Error())
+		}
+		if opts.Query == "" {
+			opts.Query = req.Query
+		}
+		query = opts.Query
+		if len(req.Tables) == 0 && opts.Query == "" {
+			return nil, http.StatusBadRequest, errors.New("No tables or query specified")
+		}
+		// 目标数据库只能是关系型数据库
+		switch opts.TargetType {
+		case "", model.DatabaseMySQL, model.DatabasePostgreSQL, model.DatabaseSQLite, model.DatabaseMSSQL,
+			model.DatabaseOracle, model.DatabaseClickHouse, model.DatabaseKingBase, model.DatabaseDM:
+		default:
+			return nil, http.StatusBadRequest, errors.New("Unsupported target database type: " + string(opts.TargetType))
+		}
+		plan = &exportPlan{fileName: "export.sql", contentType: "text/sql; charset=utf-8"}
+		build = func(db any, dbAdapter adapter.DatabaseAdapter, _ model.DatabaseType) (service.ExportTask, error) {
+			return func(ctx context.Context, w io.Writer) error {
+				return dbAdapter.ExportToSQL(ctx, db, w, database, req.Tables, opts)
+			}, nil
+		}
+
+	default:
+		format, ok := exportFormats[req.Format]
+		if !ok {
+			return nil, http.StatusBadRequest, errors.New("Unsupported export format: " + req.Format)
+		}
+		if len(req.Tables) == 0 && req.Query == "" {
+			return nil, http.StatusBadRequest, errors.New("No tables or query specified")
+		}
+		plan = &exportPlan{fileName: "export." + format.ext, contentType: format.contentType}
+		build = func(db any, dbAdapter adapter.DatabaseAdapter, dbType model.DatabaseType) (service.ExportTask, error) {
+			newWriter, maxRows, err := newTableWriter(req.Format, req.Opts, req.Query == "" && len(req.Tables) > 1, dbType)
+			if err != nil {
+				return nil, errors.New("Invalid export options: " + err.Error())
+			}
+			streamer, ok := dbAdapter.(adapter.RowStreamer)
+			if !ok {
+				return nil, errors.New("Export is not supported for this database type")
+			}
+			return func(ctx context.Context, w io.Writer) error {
+				return exportTables(ctx, db, dbAdapter, streamer, newWriter(w), database, req, maxRows)
+			}, nil
+		}
+	}
+
+	if query != "" {
+		if err := checkQuery(query); err != nil {
+			return nil, http.StatusForbidden, err
+		}
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		if errors.Is(err, connection.ErrConnectionNotFound) {
+			return nil, http.StatusNotFound, errors.New("Connection not found")
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		release()
+		return nil, http.StatusInternalServerError, err
+	}
+
+	plan.run, err = build(db, dbAdapter, config.Type)
+	if err != nil {
+		release()
+		return nil, http.StatusBadRequest, err
+	}
+	plan.release = release
+	return plan, http.StatusOK, nil
+}
+
+// exportTables 将查询结果或依次将各表写入同一个导出器
+func exportTables(ctx context.Context, db any, dbAdapter adapter.DatabaseAdapter, streamer adapter.RowStreamer, writer export.TableWriter, database string, req *exportRequest, maxRows int) error {
+	var err error
+	if req.Query != "" {
+		err = streamer.StreamQuery(ctx, db, database, req.Query, func(rows export.RowIterator) error {
+			return writer.WriteTable("", rows)
+		})
+	} else {
+		for _, table := range req.Tables {
+			if w, ok := writer.(export.SchemaSetter); ok {
+				if schema, err := dbAdapter.GetTableSchema(db, database, table); err == nil {
+					w.SetSchema(schema.Columns)
+				}
+			}
+			err = streamer.StreamTable(ctx, db, database, table, maxRows, func(rows export.RowIterator) error {
+				return writer.WriteTable(table, rows)
+			})
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// checkExportQuery 返回校验自定义查询的函数，同时在审计记录中写入导出的查询或表
+func checkExportQuery(c *gin.Context, req *exportRequest) func(query string) error {
+	if entry := auditEntry(c); entry != nil && len(req.Tables) > 0 {
+		entry.Target = strings.Join(req.Tables, ", ")
+	}
+	return func(query string) error {
+		setAuditStatement(c, "", query)
+		return checkSQL(c, query)
+	}
+}
+
+// streamExport 直接在响应中写出导出文件
+// 大结果集建议使用后台导出任务：客户端断开后导出不会中断，出错时也不会得到不完整的文件。
+func (s *Server) streamExport(c *gin.Context, req *exportRequest) {
+	plan, status, err := s.prepareExport(c.Param("id"), c.Query("database"), req, checkExportQuery(c, req))
+	if err != nil {
+		c.JSON(status, errorResponse(status, err.Error()))
+		return
+	}
+	defer plan.release()
+
+	// 设置响应头
+	c.Header("Content-Type", plan.contentType)
+	c.Header("Content-Disposition", "attachment; filename="+plan.fileName)
+
+	// 执行导出，逐行写出并定期刷新响应
+	var rows atomic.Int64
+	err = plan.run(export.WithRowCounter(c.Request.Context(), &rows), c.Writer)
+	if err == nil {
+		setAuditResult(c, rows.Load(), "")
+		return
+	}
+
+	// 已写出部分内容时响应状态码仍为 200，错误需单独记入审计
+	setAuditResult(c, rows.Load(), err.Error())
+	// 已开始写出文件时无法再返回错误响应，中断后客户端得到不完整的文件
+	if !c.Writer.Written() {
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	}
+}
+
+// exportCSV CSV 导出
+func (s *Server) exportCSV(c *gin.Context) {
+	var req exportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	req.Format = "csv"
+	s.streamExport(c, &req)
+}
+
+// exportSQL SQL 导出
+func (s *Server) exportSQL(c *gin.Context) {
+	var req exportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	req.Format = "sql"
+	s.streamExport(c, &req)
+}
+
+// exportFormats 通用导出支持的格式：内容类型与文件扩展名
+var exportFormats = map[string]struct{ contentType, ext string }{
+	"json":     {"application/json; charset=utf-8", "json"},
+	"ndjson":   {"application/x-ndjson; charset=utf-8", "ndjson"},
+	"xlsx":     {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	"markdown": {"text/markdown; charset=utf-8", "md"},
+	"html":     {"text/html; charset=utf-8", "html"},
+	"parquet":  {"application/vnd.apache.parquet", "parquet"},
+}
+
+// newTableWriter 按格式解析导出选项，返回创建导出器的函数与每个表的最大行数
+// sourceDB 为源数据库类型，Parquet 导出据此映射列类型。
+func newTableWriter(format string, raw json.RawMessage, multiTable bool, sourceDB model.DatabaseType) (func(w io.Writer) export.TableWriter, int, error) {
+	switch format {
+	case "json", "ndjson":
+		opts := &model.JSONOptions{}
+		if err := decodeOptions(raw, opts); err != nil {
+			return nil, 0, err
+		}
+		if format == "ndjson" {
+			return func(w io.Writer) export.TableWriter { return export.NewNDJSONExporter(w, opts, multiTable) }, opts.MaxRows, nil
+		}
+		return func(w io.Writer) export.TableWriter { return export.NewJSONExporter(w, opts, multiTable) }, opts.MaxRows, nil
+	case "xlsx":
+		opts := &model.XLSXOptions{IncludeHeader: true}
+		if err := decodeOptions(raw, opts); err != nil {
+			return nil, 0, err
+		}
+		return func(w io.Writer) export.TableWriter { return export.NewXLSXExporter(w, opts) }, opts.MaxRows, nil
+	case "markdown":
+		opts := &model.MarkdownOptions{}
+		if err := decodeOptions(raw, opts); err != nil {
+			return nil, 0, err
+		}
+		return func(w io.Writer) export.TableWriter { return export.NewMarkdownExporter(w, opts) }, opts.MaxRows, nil
+	case "parquet":
+		opts := &model.ParquetOptions{}
+		if err := decodeOptions(raw, opts); err != nil {
+			return nil, 0, err
+		}
+		if multiTable {
+			return nil, 0, errors.New("Parquet 文件只能导出一个表")
+		}
+		if _, err := parquet.ParseCodec(opts.Compression); err != nil {
+			return nil, 0, err
+		}
+		// 没有类型映射配置时按内置规则推断
+		mapper, _ := export.LoadDefaultConfig()
+		return func(w io.Writer) export.TableWriter { return export.NewParquetExporter(w, opts, sourceDB, mapper) }, opts.MaxRows, nil
+	default:
+		opts := &model.HTMLOptions{}
+		if err := decodeOptions(raw, opts); err != nil {
+			return nil, 0, err
+		}
+		return func(w io.Writer) export.TableWriter { return export.NewHTMLExporter(w, opts) }, opts.MaxRows, nil
+	}
+}
+
+// exportFormat 按格式导出查询结果或选中的表：json、ndjson、xlsx、markdown、html、parquet
+// 指定 query 时导出查询结果，否则依次导出 tables 中的表，多个表写入同一个文件。
+func (s *Server) exportFormat(c *gin.Context) {
+	var req exportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	req.Format = c.Param("format")
+	s.streamExport(c, &req)
+}
+
+// ==================== 后台导出 ====================
+
+// startExport 创建后台导出任务，导出写入服务端文件，完成后通过下载接口获取
+// 请求体与同步导出相同，format 指定格式：csv、sql、json、ndjson、xlsx、markdown、html、parquet。
+func (s *Server) startExport(c *gin.Context) {
+	id := c.Param("id")
+	database := c.Query("database")
+
+	var req exportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	plan, status, err := s.prepareExport(id, database, &req, checkExportQuery(c, &req))
+	if err != nil {
+		c.JSON(status, errorResponse(status, err.Error()))
+		return
+	}
+
+	// 导出在后台进行，任务结束后再释放连接并写入审计记录
+	entry := detachAudit(c)
+	task := func(ctx context.Context, w io.Writer) error {
+		defer plan.release()
+		err := plan.run(ctx, w)
+		if entry != nil {
+			entry.RowsAffected = export.RowCount(ctx)
+			s.completeAudit(entry, err)
+		}
+		return err
+	}
+
+	job, err := s.exports.Start(id, database, req.Format, plan.fileName, plan.contentType, task)
+	if err != nil {
+		plan.release()
+		if entry != nil {
+			s.completeAudit(entry, err)
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
+
+// listExports 获取导出任务，普通用户只能看到有导出权限的连接上的任务
+func (s *Server) listExports(c *gin.Context) {
+	jobs := s.exports.List()
+	if currentUser(c).Role == model.RoleAdmin {
+		c.JSON(http.StatusOK, successResponse(jobs))
+		return
+	}
+
+	visible := make([]model.ExportJob, 0, len(jobs))
+	allowed := make(map[string]bool)
+	for _, job := range jobs {
+		ok, checked := allowed[job.ConnectionID]
+		if !checked {
+			_, perms, err := s.connectionPermissions(c, job.ConnectionID)
+			ok = err == nil && perms.Has(model.PermExport)
+			allowed[job.ConnectionID] = ok
+		}
+		if ok {
+			visible = append(visible, job)
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(visible))
+}
+
+// getExport 获取导出任务进度：状态、已写出的行数与字节数、错误
+func (s *Server) getExport(c *gin.Context) {
+	job, err := s.exports.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
+
+// deleteExport 取消进行中的导出任务，已结束的任务连同文件一起删除
+func (s *Server) deleteExport(c *gin.Context) {
+	jobID := c.Param("id")
+
+	if !s.exports.Cancel(jobID) {
+		c.JSON(http.StatusNotFound, errorResponse(404, "Export job not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"jobId":     jobID,
+		"cancelled": true,
+	}))
+}
+
+// downloadExport 下载导出文件，支持 Range 断点续传
+// compress=gzip 或 zip 时下载压缩后的文件，压缩文件首次下载时生成，续传时复用。
+func (s *Server) downloadExport(c *gin.Context) {
+	jobID := c.Param("id")
+	compress := c.Query("compress")
+
+	file, err := s.exports.Open(jobID, compress)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrExportNotFound):
+			c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+		case errors.Is(err, service.ErrExportNotReady):
+			c.JSON(http.StatusConflict, errorResponse(409, err.Error()))
+		case errors.Is(err, service.ErrExportCompression):
+			c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		}
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	// ETag 使客户端可以用 If-Range 确认续传的仍是同一个文件
+	c.Header("ETag", strconv.Quote(jobID+compress))
+	if file.ContentType != "" {
+		c.Header("Content-Type", file.ContentType)
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	http.ServeContent(c.Writer, c.Request, file.Name, info.ModTime(), file)
+}
+
+// previewExportSQL 预览 SQL 导出的类型映射
+func (s *Server) previewExportSQL(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		Tables       []string           `json:"tables"`
+		TargetDBType model.DatabaseType `json:"targetDbType"` // 目标数据库类型
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	if len(req.Tables) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "No tables specified"))
+		return
+	}
+
+	// 验证目标数据库类型
+	if req.TargetDBType == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Target database type required"))
+		return
+	}
+
+	// 获取连接配置
+	config, err := s.connManager.GetConfig(id)
+	if err != nil {
+		if err == connection.ErrConnectionNotFound {
+			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	// 获取数据库连接
+	db, _, release, err := s.connectionSvc.GetDB(id, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer release()
+
+	// 获取适配器
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	// 获取所有表的列信息
+	var allColumns []model.ColumnInfo
+	for _, table := range req.Tables {
+		schema, err := dbAdapter.GetTableSchema(db, "", table)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to get table schema: "+table))
+			return
+		}
+		allColumns = append(allColumns, schema.Columns...)
+	}
+
+	// 执行类型映射
+	mapper, err := export.LoadDefaultConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to load type mapper"))
+		return
+	}
+
+	// 如果是同类型数据库迁移，不需要类型映射
+	if config.Type == req.TargetDBType {
+		// 返回直接映射结果
+		result := &export.TypeMappingResult{
+			Success: true,
+			Mapped:  make(map[string]string),
+			Summary: export.TypeSummary{
+				Total:  len(allColumns),
+				Direct: len(allColumns),
+			},
+		}
+		for _, col := range allColumns {
+			result.Mapped[col.Type] = col.Type
+		}
+		c.JSON(http.StatusOK, successResponse(result))
+		return
+	}
+
+	result, err := mapper.MapTypes(config.Type, req.TargetDBType, allColumns)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
 }
 
 // ==================== 监控 ====================
 
-// getMonitorStats 获取监控统计
-func (s *Server) getMonitorStats(c *gin.Context) {
-	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
-		"connections": 0,
-		"queries":     0,
-	}))
-}
+// getMonitorStats 获取监控统计
+func (s *Server) getMonitorStats(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"connections": 0,
+		"queries":     0,
+	}))
+}
+
+// metricsHandler Prometheus 指标处理器
+func (s *Server) metricsHandler(c *gin.Context) {
+	handler := promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{})
+	handler.ServeHTTP(c.Writer, c.Request)
+}
+
+// ==================== 静态文件服务 ====================
+
+// serveIndex 服务 index.html
+func (s *Server) serveIndex(c *gin.Context) {
+	// 设置正确的 Content-Type
+	c.Header("Content-Type", "text/html; charset=utf-8")
+
+	// 打开 index.html 文件
+	file, err := s.staticFS.Open("index.html")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to load index.html"))
+		return
+	}
+	defer file.Close()
+
+	// 读取文件内容
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to read index.html"))
+		return
+	}
+
+	// 返回文件内容
+	c.Data(http.StatusOK, "text/html; charset=utf-8", content)
+}
+
+// serveAsset 服务静态资源文件
+func (s *Server) serveAsset(c *gin.Context) {
+	// 获取文件路径
+	filePath := c.Param("filepath")
+	// Gin 的 *filepath 参数可能包含或不包含前导斜杠，需要处理
+	filePath = strings.TrimPrefix(filePath, "/")
+	if filePath == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid file path"))
+		return
+	}
+
+	// 打开文件（文件系统中的路径是 assets/xxx）
+	file, err := s.staticFS.Open("assets/" + filePath)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse(404, "File not found: "+filePath))
+		return
+	}
+	defer file.Close()
+
+	// 获取文件信息
+	stat, err := file.Stat()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to get file info"))
+		return
+	}
+
+	// 根据文件扩展名设置 Content-Type
+	ext := path.Ext(filePath)
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+
+	// 设置 Content-Length 头
+	c.Header("Content-Length", strconv.Itoa(int(stat.Size())))
+
+	// 使用 io.Copy 直接传输文件内容，更适合大文件和 ES6 模块
+	_, err = io.Copy(c.Writer, file)
+	if err != nil {
+		// 错误发生时连接可能已经关闭，不需要额外处理
+		return
+	}
+}
+
+// ==================== 辅助函数 ====================
+
+// statusClientClosedRequest 查询被客户端取消时返回的状态码（沿用 nginx 的 499 约定）
+const statusClientClosedRequest = 499
+
+// queryIDHeader 返回查询 ID 的响应头，客户端未指定 queryId 时据此获知生成的 ID
+const queryIDHeader = "X-Query-ID"
+
+// APIResponse 统一响应格式
+type APIResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+// successResponse 成功响应
+func successResponse(data interface{}) APIResponse {
+	return APIResponse{
+		Code:    0,
+		Message: "success",
+		Data:    data,
+	}
+}
+
+// errorResponse 错误响应
+func errorResponse(code int, message string) APIResponse {
+	return APIResponse{
+		Code:    code,
+		Message: message,
+		Data:    nil,
+	}
+}
+
+// ==================== 数据编辑 ====================
+
+// createData 创建数据
+func (s *Server) createData(c *gin.Context) {
+	id := c.Param("id")
+	table := c.Param("table")
+	database := c.Query("database")
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer release()
+
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	setAuditStatement(c, "", adapter.DescribeRowEdit(config.Type, model.ChangeInsert, table, data, nil))
+	if err := dbAdapter.Insert(db, database, table, data); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	setAuditResult(c, 1, "")
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// updateData 按行标识更新数据
+func (s *Server) updateData(c *gin.Context) {
+	id := c.Param("id")
+	table := c.Param("table")
+	database := c.Query("database")
+
+	var req struct {
+		Data map[string]interface{} `json:"data"`
+		Key  model.RowKey           `json:"key"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	if len(req.Key) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Row key required"))
+		return
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer release()
+
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	setAuditStatement(c, "", adapter.DescribeRowEdit(config.Type, model.ChangeUpdate, table, req.Data, req.Key))
+	affected, err := dbAdapter.Update(db, database, table, req.Data, req.Key)
+	if err != nil {
+		rowEditError(c, err)
+		return
+	}
+	setAuditResult(c, affected, "")
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"rowsAffected": affected}))
+}
+
+// deleteData 按行标识删除数据
+func (s *Server) deleteData(c *gin.Context) {
+	id := c.Param("id")
+	table := c.Param("table")
+	database := c.Query("database")
+
+	var req struct {
+		Key model.RowKey `json:"key"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	if len(req.Key) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Row key required"))
+		return
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer release()
+
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	setAuditStatement(c, "", adapter.DescribeRowEdit(config.Type, model.ChangeDelete, table, nil, req.Key))
+	affected, err := dbAdapter.Delete(db, database, table, req.Key)
+	if err != nil {
+		rowEditError(c, err)
+		return
+	}
+	setAuditResult(c, affected, "")
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"rowsAffected": affected}))
+}
+
+// applyChanges 在单个事务中应用数据变更集，preview 为 true 时只返回生成的语句
+func (s *Server) applyChanges(c *gin.Context) {
+	id := c.Param("id")
+	table := c.Param("table")
+	database := c.Query("database")
+
+	var req struct {
+		model.ChangeSet
+		Preview bool `json:"preview"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+
+	if len(req.Inserts)+len(req.Updates)+len(req.Deletes) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Change set is empty"))
+		return
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer release()
+
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	applier, ok := dbAdapter.(adapter.ChangeApplier)
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Batch editing is not supported for "+string(config.Type)))
+		return
+	}
+
+	// 预览不修改数据，不写入审计记录
+	if req.Preview {
+		detachAudit(c)
+	} else {
+		setAuditStatement(c, "", describeChanges(config.Type, table, &req.ChangeSet))
+	}
+
+	ctx := c.Request.Context()
+	result, err := applier.ApplyChanges(ctx, db, database, table, &req.ChangeSet, req.Preview)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Request cancelled"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	setAuditResult(c, result.RowsAffected, changesError(result))
+	c.JSON(http.StatusOK, successResponse(result))
+}
+
+// describeChanges 按执行顺序（删除、更新、插入）列出变更集的语句，用于审计记录
+func describeChanges(dbType model.DatabaseType, table string, cs *model.ChangeSet) string {
+	var stmts []string
+	for _, key := range cs.Deletes {
+		stmts = append(stmts, adapter.DescribeRowEdit(dbType, model.ChangeDelete, table, nil, key))
+	}
+	for _, change := range cs.Updates {
+		stmts = append(stmts, adapter.DescribeRowEdit(dbType, model.ChangeUpdate, table, change.Data, change.Key))
+	}
+	for _, data := range cs.Inserts {
+		stmts = append(stmts, adapter.DescribeRowEdit(dbType, model.ChangeInsert, table, data, nil))
+	}
+	return strings.Join(stmts, ";\n")
+}
+
+// changesError 变更集回滚时返回出错行的错误
+func changesError(result *model.ChangeSetResult) string {
+	if result.Committed {
+		return ""
+	}
+	for _, row := range result.Rows {
+		if row.Status == model.StatementError {
+			return fmt.Sprintf("%s #%d: %s", row.Op, row.Index+1, row.Error)
+		}
+	}
+	return "change set rolled back"
+}
+
+// maxCellUploadSize 单元格上传文件的最大字节数
+const maxCellUploadSize = 64 << 20
+
+// cellAccessor 解析单元格请求的公共参数并获取连接与适配器
+// key 为 JSON 编码的行标识，出错时已写入响应；成功时使用结束后调用 release 释放连接占用。
+func (s *Server) cellAccessor(c *gin.Context) (adapter.CellAccessor, any, string, model.RowKey, func(), bool) {
+	id := c.Param("id")
+	database := c.Query("database")
+
+	if c.Query("column") == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Column required"))
+		return nil, nil, "", nil, nil, false
+	}
+
+	var key model.RowKey
+	if err := json.Unmarshal([]byte(c.Query("key")), &key); err != nil || len(key) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Row key required"))
+		return nil, nil, "", nil, nil, false
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return nil, nil, "", nil, nil, false
+	}
+
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	if err != nil {
+		release()
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return nil, nil, "", nil, nil, false
+	}
+
+	accessor, ok := dbAdapter.(adapter.CellAccessor)
+	if !ok {
+		release()
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Cell access is not supported for "+string(config.Type)))
+		return nil, nil, "", nil, nil, false
+	}
+	return accessor, db, database, key, release, true
+}
+
+// readCell 返回单元格的完整值，按内容识别 Content-Type；download=1 时作为附件下载
+// 值为 NULL 时返回 204。值来自数据库，不能作为本站点的页面执行脚本：图片原样显示，
+// 文本与 JSON 以纯文本显示，其他类型（PDF、压缩包等）只能作为附件下载。
+func (s *Server) readCell(c *gin.Context) {
+	accessor, db, database, key, release, ok := s.cellAccessor(c)
+	if !ok {
+		return
+	}
+	defer release()
+	table := c.Param("table")
+	column := c.Query("column")
+
+	// 值直接从驱动的缓冲区写入响应，不再复制一份
+	ctx := c.Request.Context()
+	err := accessor.ReadCell(ctx, db, database, table, column, key, func(data []byte) error {
+		if data == nil {
+			c.Status(http.StatusNoContent)
+			return nil
+		}
+
+		contentType := cellContentType(data)
+		inline := inlineCellType(contentType)
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Content-Security-Policy", "sandbox")
+		if c.Query("download") == "1" || inline == "" {
+			ext, ok := cellExtensions[contentType]
+			if !ok {
+				ext = ".bin"
+			}
+			filename := column + ext
+			c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		} else {
+			contentType = inline
+		}
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Length", strconv.Itoa(len(data)))
+		c.Status(http.StatusOK)
+		_, err := c.Writer.Write(data)
+		return err
+	})
+	if err != nil && !c.Writer.Written() {
+		if ctx.Err() != nil {
+			c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Request cancelled"))
+			return
+		}
+		rowEditError(c, err)
+	}
+}
+
+// cellExtensions 下载单元格时按内容类型使用的文件扩展名
+var cellExtensions = map[string]string{
+	"application/json":          ".json",
+	"application/pdf":           ".pdf",
+	"application/x-gzip":        ".gz",
+	"application/zip":           ".zip",
+	"image/png":                 ".png",
+	"image/jpeg":                ".jpg",
+	"image/gif":                 ".gif",
+	"image/webp":                ".webp",
+	"image/bmp":                 ".bmp",
+	"text/plain; charset=utf-8": ".txt",
+	"text/html; charset=utf-8":  ".html",
+	"text/xml; charset=utf-8":   ".xml",
+}
+
+// rasterImageTypes 可在浏览器中直接显示的光栅图片（不含可执行脚本的 SVG）
+var rasterImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+	"image/bmp":  true,
+}
+
+// inlineCellType 返回在浏览器中直接显示单元格时使用的类型：图片原样返回，文本（含 HTML、XML）
+// 与 JSON 改为 text/plain，其他类型返回空字符串
+func inlineCellType(contentType string) string {
+	if rasterImageTypes[contentType] {
+		return contentType
+	}
+	if contentType == "application/json" {
+		return "text/plain; charset=utf-8"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "text/") {
+		return ""
+	}
+	return mime.FormatMediaType("text/plain", params)
+}
+
+// cellContentType 根据内容识别单元格值的类型（图片、gzip、PDF、JSON、文本等）
+func cellContentType(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return "application/json"
+	}
+	return http.DetectContentType(data)
+}
+
+// writeCell 使用上传的文件替换单元格的值（multipart 字段 file）
+func (s *Server) writeCell(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCellUploadSize+1<<20)
+
+	accessor, db, database, key, release, ok := s.cellAccessor(c)
+	if !ok {
+		return
+	}
+	defer release()
+	table := c.Param("table")
+	column := c.Query("column")
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "File required: "+err.Error()))
+		return
+	}
+	if header.Size > maxCellUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, errorResponse(413, "File too large"))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	// 写入数据库需要完整的值，读取时同样不超过 maxCellUploadSize
+	data, err := io.ReadAll(io.LimitReader(file, maxCellUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	if len(data) > maxCellUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, errorResponse(413, "File too large"))
+		return
+	}
+
+	setAuditStatement(c, "", adapter.DescribeRowEdit(connectionType(c), model.ChangeUpdate, table,
+		map[string]interface{}{column: fmt.Sprintf("<%d bytes>", len(data))}, key))
+	affected, err := accessor.WriteCell(c.Request.Context(), db, database, table, column, key, data)
+	if err != nil {
+		rowEditError(c, err)
+		return
+	}
+	setAuditResult(c, affected, "")
 
-// metricsHandler Prometheus 指标处理器
-func (s *Server) metricsHandler(c *gin.Context) {
-	handler := promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{})
-	handler.ServeHTTP(c.Writer, c.Request)
+	c.JSON(http.StatusOK, successResponse(gin.H{"rowsAffected": affected}))
 }
 
-// ==================== 静态文件服务 ====================
+// importCSV 将上传的 CSV/TSV 文件导入到表（multipart 字段 file，导入选项为 JSON 字段 options）
+func (s *Server) importCSV(c *gin.Context) {
+	id := c.Param("id")
+	table := c.Param("table")
+	database := c.Query("database")
 
-// serveIndex 服务 index.html
-func (s *Server) serveIndex(c *gin.Context) {
-	// 设置正确的 Content-Type
-	c.Header("Content-Type", "text/html; charset=utf-8")
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "File required: "+err.Error()))
+		return
+	}
 
-	// 打开 index.html 文件
-	file, err := s.staticFS.Open("index.html")
+	var opts model.CSVImportOptions
+	if raw := c.PostForm("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid options: "+err.Error()))
+			return
+		}
+	}
+	// .tsv 文件默认使用制表符分隔
+	if opts.Separator == "" && strings.EqualFold(path.Ext(header.Filename), ".tsv") {
+		opts.Separator = "\t"
+	}
+
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to load index.html"))
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
-	defer file.Close()
+	defer release()
 
-	// 读取文件内容
-	content, err := io.ReadAll(file)
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to read index.html"))
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
 
-	// 返回文件内容
-	c.Data(http.StatusOK, "text/html; charset=utf-8", content)
-}
+	if _, ok := dbAdapter.(adapter.BulkInserter); !ok {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Import is not supported for "+string(config.Type)))
+		return
+	}
 
-// serveAsset 服务静态资源文件
-func (s *Server) serveAsset(c *gin.Context) {
-	// 获取文件路径
-	filePath := c.Param("filepath")
-	// Gin 的 *filepath 参数可能包含或不包含前导斜杠，需要处理
-	filePath = strings.TrimPrefix(filePath, "/")
-	if filePath == "" {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid file path"))
+	target := &importer.Target{
+		Adapter:  dbAdapter,
+		DB:       db,
+		DBType:   config.Type,
+		Database: database,
+		Table:    table,
+	}
+	if !authorizeImportTarget(c, target, &opts.CreateTable, opts.DryRun) {
 		return
 	}
 
-	// 打开文件（文件系统中的路径是 assets/xxx）
-	file, err := s.staticFS.Open("assets/" + filePath)
+	file, err := header.Open()
 	if err != nil {
-		c.JSON(http.StatusNotFound, errorResponse(404, "File not found: "+filePath))
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
 	defer file.Close()
 
-	// 获取文件信息
-	stat, err := file.Stat()
+	setAuditStatement(c, "", importStatement(header.Filename, opts.DryRun))
+	ctx := c.Request.Context()
+	result, err := importer.NewCSVImporter(&opts).Import(ctx, file, target)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, "Failed to get file info"))
+		if ctx.Err() != nil {
+			c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Request cancelled"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+		return
+	}
+	if !result.DryRun {
+		setAuditResult(c, result.Imported, "")
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
+}
+
+// importParquet 将上传的 Parquet 文件导入到表（multipart 字段 file，导入选项为 JSON 字段 options）
+func (s *Server) importParquet(c *gin.Context) {
+	id := c.Param("id")
+	table := c.Param("table")
+	database := c.Query("database")
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "File required: "+err.Error()))
 		return
 	}
 
-	// 根据文件扩展名设置 Content-Type
-	ext := path.Ext(filePath)
-	contentType := mime.TypeByExtension(ext)
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	var opts model.ParquetImportOptions
+	if raw := c.PostForm("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid options: "+err.Error()))
+			return
+		}
 	}
-	c.Header("Content-Type", contentType)
 
-	// 设置 Content-Length 头
-	c.Header("Content-Length", strconv.Itoa(int(stat.Size())))
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer release()
 
-	// 使用 io.Copy 直接传输文件内容，更适合大文件和 ES6 模块
-	_, err = io.Copy(c.Writer, file)
+	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
 	if err != nil {
-		// 错误发生时连接可能已经关闭，不需要额外处理
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
-}
 
-// ==================== 辅助函数 ====================
+	if _, ok := dbAdapter.(adapter.BulkInserter); !ok {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Import is not supported for "+string(config.Type)))
+		return
+	}
 
-// APIResponse 统一响应格式
-type APIResponse struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
-}
+	target := &importer.Target{
+		Adapter:  dbAdapter,
+		DB:       db,
+		DBType:   config.Type,
+		Database: database,
+		Table:    table,
+	}
+	if !authorizeImportTarget(c, target, &opts.CreateTable, opts.DryRun) {
+		return
+	}
 
-// successResponse 成功响应
-func successResponse(data interface{}) APIResponse {
-	return APIResponse{
-		Code:    0,
-		Message: "success",
-		Data:    data,
+	// Parquet 的元数据在文件末尾，需要随机读取
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	setAuditStatement(c, "", importStatement(header.Filename, opts.DryRun))
+	ctx := c.Request.Context()
+	result, err := importer.NewParquetImporter(&opts).Import(ctx, file, header.Size, target)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.JSON(statusClientClosedRequest, errorResponse(statusClientClosedRequest, "Request cancelled"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+		return
+	}
+	if !result.DryRun {
+		setAuditResult(c, result.Imported, "")
 	}
+
+	c.JSON(http.StatusOK, successResponse(result))
 }
 
-// errorResponse 错误响应
-func errorResponse(code int, message string) APIResponse {
-	return APIResponse{
-		Code:    code,
-		Message: message,
-		Data:    nil,
+// importStatement 导入文件的审计描述，试运行不写入数据
+func importStatement(fileName string, dryRun bool) string {
+	if dryRun {
+		return fileName + " (dry run)"
 	}
+	return fileName
 }
 
-// ==================== 数据编辑 ====================
+// restoreEventInterval SSE 推送导入进度的间隔
+const restoreEventInterval = 500 * time.Millisecond
 
-// createData 创建数据
-func (s *Server) createData(c *gin.Context) {
+// startRestore 上传 SQL 文件（可为 gzip 压缩）并在后台导入（multipart 字段 file，导入选项为 JSON 字段 options）
+func (s *Server) startRestore(c *gin.Context) {
 	id := c.Param("id")
-	table := c.Param("table")
-	database := c.Query("database")
 
-	var data map[string]interface{}
-	if err := c.ShouldBindJSON(&data); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "File required: "+err.Error()))
 		return
 	}
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	var opts model.RestoreOptions
+	if raw := c.PostForm("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid options: "+err.Error()))
+			return
+		}
+	}
+
+	// 上传的临时文件在请求结束后删除，任务需要自己的副本
+	file, err := saveUpload(header)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
 
-	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	// 导入在后台进行，任务结束后再写入审计记录
+	var done func(job model.RestoreJob)
+	entry := detachAudit(c)
+	if entry != nil {
+		entry.Database = opts.Database
+		entry.Statement = header.Filename
+		done = func(job model.RestoreJob) {
+			entry.RowsAffected = int64(job.Statements)
+			s.completeAudit(entry, restoreError(&job))
+		}
+	}
+
+	job, err := s.restores.Start(id, header.Filename, file, &opts, done)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		file.Close()
+		os.Remove(file.Name())
+		if entry != nil {
+			s.completeAudit(entry, err)
+		}
+
+		switch err {
+		case connection.ErrConnectionNotFound:
+			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+		case service.ErrRestoreUnsupported, service.ErrRestoreTransactionUnsupported:
+			c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		}
 		return
 	}
 
-	if err := dbAdapter.Insert(db, database, table, data); err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
+	c.JSON(http.StatusOK, successResponse(job))
+}
+
+// restoreError 返回导入任务的审计错误：终止原因、取消或失败的语句数
+func restoreError(job *model.RestoreJob) error {
+	switch {
+	case job.Error != "":
+		return errors.New(job.Error)
+	case job.Status == model.RestoreCancelled:
+		return errors.New("cancelled")
+	case job.Failed > 0:
+		return fmt.Errorf("%d statements failed", job.Failed)
+	}
+	return nil
+}
+
+// saveUpload 将上传文件复制到临时文件，返回的文件读取位置在开头
+func saveUpload(header *multipart.FileHeader) (*os.File, error) {
+	src, err := header.Open()
+	if err != nil {
+		return nil, err
 	}
+	defer src.Close()
 
-	c.JSON(http.StatusOK, successResponse(nil))
+	file, err := os.CreateTemp("", "dbm-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, src); err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
 }
 
-// updateData 更新数据
-func (s *Server) updateData(c *gin.Context) {
+// listRestores 获取连接下的导入任务
+func (s *Server) listRestores(c *gin.Context) {
 	id := c.Param("id")
-	table := c.Param("table")
-	database := c.Query("database")
+	c.JSON(http.StatusOK, successResponse(s.restores.List(id)))
+}
 
-	var req struct {
-		Data  map[string]interface{} `json:"data"`
-		Where string                 `json:"where"`
+// getRestore 获取导入任务进度
+func (s *Server) getRestore(c *gin.Context) {
+	job, err := s.restores.Get(c.Param("id"), c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+		return
 	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+// restoreEvents 以 SSE 推送导入任务进度，任务结束后发送最终状态并关闭
+func (s *Server) restoreEvents(c *gin.Context) {
+	id := c.Param("id")
+	jobID := c.Param("jobId")
+
+	job, err := s.restores.Get(id, jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
 		return
 	}
 
-	if req.Where == "" {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Where condition required"))
+	ticker := time.NewTicker(restoreEventInterval)
+	defer ticker.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		c.SSEvent("progress", job)
+		if job.Status != model.RestoreRunning {
+			return false
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-ticker.C:
+		}
+
+		job, err = s.restores.Get(id, jobID)
+		return err == nil
+	})
+}
+
+// cancelRestore 取消导入任务
+func (s *Server) cancelRestore(c *gin.Context) {
+	id := c.Param("id")
+	jobID := c.Param("jobId")
+
+	if !s.restores.Cancel(id, jobID) {
+		c.JSON(http.StatusNotFound, errorResponse(404, "Restore job not found"))
 		return
 	}
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
-	if err != nil {
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"jobId":     jobID,
+		"cancelled": true,
+	}))
+}
+
+// bindMigration 解析迁移选项，源表与目标连接必填
+func bindMigration(c *gin.Context) (*model.MigrationOptions, bool) {
+	var opts model.MigrationOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request: "+err.Error()))
+		return nil, false
+	}
+	if len(opts.Tables) == 0 || opts.TargetID == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "tables and targetId are required"))
+		return nil, false
+	}
+	return &opts, true
+}
+
+// migrationError 将迁移错误转换为响应
+func migrationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, connection.ErrConnectionNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+	case errors.Is(err, service.ErrMigrationNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+	case errors.Is(err, service.ErrMigrationUnsupported), errors.Is(err, service.ErrMigrationSameTarget),
+		errors.Is(err, service.ErrMigrationChoiceRequired), errors.Is(err, service.ErrMigrationNotResumable):
+		c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+	default:
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	}
+}
+
+// previewMigration 预览迁移的类型映射与目标表，需要用户选择的类型在 mapping.requiresUser 中
+func (s *Server) previewMigration(c *gin.Context) {
+	opts, ok := bindMigration(c)
+	if !ok || !s.authorizeMigrationTarget(c, opts) {
 		return
 	}
 
-	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	plan, err := s.migrations.Plan(c.Param("id"), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		migrationError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, successResponse(plan))
+}
 
-	if err := dbAdapter.Update(db, database, table, req.Data, req.Where); err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+// startMigration 创建迁移任务并在后台执行
+func (s *Server) startMigration(c *gin.Context) {
+	opts, ok := bindMigration(c)
+	if !ok || !s.authorizeMigrationTarget(c, opts) {
 		return
 	}
 
-	c.JSON(http.StatusOK, successResponse(nil))
+	job, err := s.migrations.Start(c.Param("id"), opts)
+	if err != nil {
+		migrationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(job))
 }
 
-// deleteData 删除数据
-func (s *Server) deleteData(c *gin.Context) {
+// listMigrations 获取连接下的迁移任务
+func (s *Server) listMigrations(c *gin.Context) {
 	id := c.Param("id")
-	table := c.Param("table")
-	database := c.Query("database")
+	c.JSON(http.StatusOK, successResponse(s.migrations.List(id)))
+}
 
-	var req struct {
-		Where string `json:"where"`
+// getMigration 获取迁移任务进度
+func (s *Server) getMigration(c *gin.Context) {
+	job, err := s.migrations.Get(c.Param("id"), c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+		return
 	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+// migrationEvents 以 SSE 推送迁移任务进度，任务结束后发送最终状态并关闭
+func (s *Server) migrationEvents(c *gin.Context) {
+	id := c.Param("id")
+	jobID := c.Param("jobId")
+
+	job, err := s.migrations.Get(id, jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
 		return
 	}
 
-	if req.Where == "" {
-		c.JSON(http.StatusBadRequest, errorResponse(400, "Where condition required"))
+	ticker := time.NewTicker(restoreEventInterval)
+	defer ticker.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		c.SSEvent("progress", job)
+		if job.Status != model.MigrationRunning {
+			return false
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-ticker.C:
+		}
+
+		job, err = s.migrations.Get(id, jobID)
+		return err == nil
+	})
+}
+
+// cancelMigration 取消迁移任务，已写入的批次保留，可通过恢复接口继续
+func (s *Server) cancelMigration(c *gin.Context) {
+	id := c.Param("id")
+	jobID := c.Param("jobId")
+
+	if !s.migrations.Cancel(id, jobID) {
+		c.JSON(http.StatusNotFound, errorResponse(404, "Migration job not found"))
 		return
 	}
 
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	c.JSON(http.StatusOK, successResponse(map[string]interface{}{
+		"jobId":     jobID,
+		"cancelled": true,
+	}))
+}
+
+// resumeMigration 从断点恢复失败或已取消的迁移任务
+func (s *Server) resumeMigration(c *gin.Context) {
+	opts, err := s.migrations.Options(c.Param("id"), c.Param("jobId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		migrationError(c, err)
+		return
+	}
+	if !s.authorizeMigrationTarget(c, &opts) {
 		return
 	}
 
-	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
+	job, err := s.migrations.Resume(c.Param("id"), c.Param("jobId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		migrationError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
 
-	if err := dbAdapter.Delete(db, database, table, req.Where); err != nil {
+// rowEditError 将数据编辑错误转换为响应
+func rowEditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, adapter.ErrRowKeyRequired):
+		c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+	case errors.Is(err, adapter.ErrRowKeyNotUnique):
+		c.JSON(http.StatusConflict, errorResponse(409, err.Error()))
+	case errors.Is(err, adapter.ErrRowNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+	default:
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
-		return
 	}
-
-	c.JSON(http.StatusOK, successResponse(nil))
 }
 
 // alterTable 修改表结构
@@ -1303,11 +2999,12 @@ func (s *Server) alterTable(c *gin.Context) {
 	}
 
 	// 获取数据库连接
-	db, config, err := s.connectionSvc.GetDB(id, req.Database)
+	db, config, release, err := s.connectionSvc.GetDB(id, req.Database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 
 	// 获取适配器
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -1316,6 +3013,11 @@ func (s *Server) alterTable(c *gin.Context) {
 		return
 	}
 
+	// 审计记录修改操作的 JSON 描述
+	if actions, err := json.Marshal(req.Actions); err == nil {
+		setAuditStatement(c, req.Database, string(actions))
+	}
+
 	// 执行表结构修改
 	if err := dbAdapter.AlterTable(db, &req); err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
@@ -1348,11 +3050,12 @@ func (s *Server) renameTable(c *gin.Context) {
 	}
 
 	// 获取数据库连接
-	db, config, err := s.connectionSvc.GetDB(id, database)
+	db, config, release, err := s.connectionSvc.GetDB(id, database)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
 	}
+	defer release()
 
 	// 获取适配器
 	dbAdapter, err := s.databaseSvc.GetAdapter(config.Type)
@@ -1362,6 +3065,7 @@ func (s *Server) renameTable(c *gin.Context) {
 	}
 
 	// 执行重命名
+	setAuditStatement(c, "", "RENAME "+oldName+" TO "+req.NewName)
 	if err := dbAdapter.RenameTable(db, database, oldName, req.NewName); err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
 		return
@@ -1373,19 +3077,3 @@ func (s *Server) renameTable(c *gin.Context) {
 		"newName": req.NewName,
 	}))
 }
-
-// corsMiddleware CORS 中间件
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	}
-}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"database/sql"
+	"dbm/internal/model"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestReadCell_ContentType(t *testing.T) {
+	ts := newTestServer(t)
+	_, reader := ts.login("reader", model.RoleUser, model.PermMetadata, model.PermSelect)
+
+	db, err := sql.Open("sqlite3", ts.dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	if _, err := db.Exec("CREATE TABLE files (id INTEGER PRIMARY KEY, body BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO files VALUES (1, ?), (2, ?), (3, ?)",
+		[]byte("<html><script>alert(document.cookie)</script></html>"), []byte(png), []byte("%PDF-1.4\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	cellURL := func(id, download string) string {
+		query := url.Values{"column": {"body"}, "key": {`{"id":` + id + `}`}, "download": {download}}
+		return "/api/v1/connections/" + ts.connID + "/tables/files/cell?" + query.Encode()
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		attachment  bool
+	}{
+		// HTML 以纯文本显示，不能在本站点下执行脚本
+		{"html", cellURL("1", ""), "text/plain; charset=utf-8", false},
+		{"html download", cellURL("1", "1"), "text/html; charset=utf-8", true},
+		{"image", cellURL("2", ""), "image/png", false},
+		{"pdf", cellURL("3", ""), "application/pdf", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := ts.do(http.MethodGet, tt.target, reader, nil, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment"); got != tt.attachment {
+				t.Errorf("Content-Disposition = %q, want attachment = %v", w.Header().Get("Content-Disposition"), tt.attachment)
+			}
+			if w.Header().Get("X-Content-Type-Options") != "nosniff" || w.Header().Get("Content-Security-Policy") != "sandbox" {
+				t.Errorf("missing nosniff or sandbox headers: %v", w.Header())
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package server
+
+import (
+	"dbm/internal/adapter"
+	"dbm/internal/auth"
+	"dbm/internal/connection"
+	"dbm/internal/importer"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ctxPermissions    = "permissions"    // 当前用户在 :id 连接上的权限
+	ctxConnectionType = "connectionType" // :id 连接的数据库类型
+)
+
+// permissions 计算用户在连接上的权限，管理员拥有全部权限
+func (s *Server) permissions(user *model.User, config *model.ConnectionConfig, groups map[string]*model.Group) auth.PermissionSet {
+	if s.policy == nil {
+		if user.Role == model.RoleAdmin {
+			return allPermissions()
+		}
+		return auth.PermissionSet{}
+	}
+	return s.policy.Permissions(user, config.ID, groupChain(groups, config.GroupID))
+}
+
+// allPermissions 全部权限，未启用认证时使用
+func allPermissions() auth.PermissionSet {
+	perms := make(auth.PermissionSet, len(model.AllPermissions))
+	for _, perm := range model.AllPermissions {
+		perms[perm] = true
+	}
+	return perms
+}
+
+// groupMap 按 ID 索引所有分组
+func (s *Server) groupMap() (map[string]*model.Group, error) {
+	groups, err := s.connManager.ListGroups()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*model.Group, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+	return byID, nil
+}
+
+// groupChain 返回分组及其所有上级分组的 ID，分组间出现循环时停止
+func groupChain(groups map[string]*model.Group, groupID string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	for groupID != "" && !seen[groupID] {
+		seen[groupID] = true
+		chain = append(chain, groupID)
+		group, ok := groups[groupID]
+		if !ok {
+			break
+		}
+		groupID = group.ParentID
+	}
+	return chain
+}
+
+// connectionPermissions 计算当前用户在连接上的权限
+func (s *Server) connectionPermissions(c *gin.Context, id string) (*model.ConnectionConfig, auth.PermissionSet, error) {
+	config, err := s.connManager.GetConfig(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := s.groupMap()
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, s.permissions(currentUser(c), config, groups), nil
+}
+
+// authorize 要求当前用户在 :id 连接上拥有全部指定权限，需在 authenticate 之后使用
+func (s *Server) authorize(perms ...model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config, granted, err := s.connectionPermissions(c, c.Param("id"))
+		if err != nil {
+			if errors.Is(err, connection.ErrConnectionNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+			return
+		}
+		if !granted.Has(perms...) {
+			abortForbidden(c, perms...)
+			return
+		}
+
+		c.Set(ctxPermissions, granted)
+		c.Set(ctxConnectionType, config.Type)
+		c.Next()
+	}
+}
+
+// authorizeJob 要求当前用户在导出任务 :id 所属的连接上拥有指定权限
+func (s *Server) authorizeJob(perm model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := s.exports.Get(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, errorResponse(404, err.Error()))
+			return
+		}
+		_, granted, err := s.connectionPermissions(c, job.ConnectionID)
+		// 连接已删除时只有管理员可以访问其导出任务
+		if err != nil && !errors.Is(err, connection.ErrConnectionNotFound) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+			return
+		}
+		if currentUser(c).Role != model.RoleAdmin && !granted.Has(perm) {
+			abortForbidden(c, perm)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortForbidden 返回缺少权限的 403 响应
+func abortForbidden(c *gin.Context, perms ...model.Permission) {
+	c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(403, "Permission denied: "+joinPermissions(perms)+" permission required on this connection"))
+}
+
+// joinPermissions 拼接权限名称用于错误信息
+func joinPermissions(perms []model.Permission) string {
+	names := make([]string, len(perms))
+	for i, perm := range perms {
+		names[i] = string(perm)
+	}
+	return strings.Join(names, ", ")
+}
+
+// statementPermission 执行某类语句需要的权限
+func statementPermission(class adapter.StatementClass) model.Permission {
+	switch class {
+	case adapter.StatementQuery:
+		return model.PermSelect
+	case adapter.StatementDML:
+		return model.PermDML
+	default:
+		return model.PermDDL
+	}
+}
+
+// connectionType 返回 authorize 记录的 :id 连接的数据库类型
+func connectionType(c *gin.Context) model.DatabaseType {
+	dbType, _ := c.Get(ctxConnectionType)
+	t, _ := dbType.(model.DatabaseType)
+	return t
+}
+
+// grantedPermissions 返回 authorize 记录的当前用户在 :id 连接上的权限
+func grantedPermissions(c *gin.Context) auth.PermissionSet {
+	granted, _ := c.Get(ctxPermissions)
+	perms, _ := granted.(auth.PermissionSet)
+	return perms
+}
+
+// ownerFilter 返回限定会话与运行中查询所属用户的用户 ID
+// 普通用户只能访问自己创建的会话与查询，管理员不受限制返回空。
+func ownerFilter(c *gin.Context) string {
+	if user := currentUser(c); user.Role != model.RoleAdmin {
+		return user.ID
+	}
+	return ""
+}
+
+// checkSQL 按语句类别校验当前用户能否在 :id 连接上执行 SQL，需在 authorize 之后使用
+func checkSQL(c *gin.Context, sql string) error {
+	perms := grantedPermissions(c)
+
+	class := adapter.ClassifySQL(connectionType(c), sql)
+	if perm := statementPermission(class); !perms.Has(perm) {
+		return fmt.Errorf("Permission denied: %s statements require %s permission on this connection", class, perm)
+	}
+	return nil
+}
+
+// authorizeSQL 同 checkSQL，不允许执行时返回 403 响应并返回 false
+func authorizeSQL(c *gin.Context, sql string) bool {
+	if err := checkSQL(c, sql); err != nil {
+		c.JSON(http.StatusForbidden, errorResponse(403, err.Error()))
+		return false
+	}
+	return true
+}
+
+// authorizeMigrationTarget 校验当前用户能否写入迁移的目标连接：写入数据需要 DML 权限，建表还需要 DDL 权限
+func (s *Server) authorizeMigrationTarget(c *gin.Context, opts *model.MigrationOptions) bool {
+	perms := []model.Permission{model.PermDML}
+	if opts.CreateTables {
+		perms = append(perms, model.PermDDL)
+	}
+
+	_, granted, err := s.connectionPermissions(c, opts.TargetID)
+	if err != nil {
+		migrationError(c, err)
+		return false
+	}
+	if !granted.Has(perms...) {
+		c.JSON(http.StatusForbidden, errorResponse(403, "Permission denied: "+joinPermissions(perms)+" permission required on the target connection"))
+		return false
+	}
+	return true
+}
+
+// authorizeImportTarget 校验导入时的建表：启用建表且目标表不存在时需要 DDL 权限
+// 没有 DDL 权限但表已存在时关闭 *createTable，避免导入过程中表被删除后仍然建表。
+// 不允许导入时已写入响应并返回 false。
+func authorizeImportTarget(c *gin.Context, target *importer.Target, createTable *bool, dryRun bool) bool {
+	if !*createTable || dryRun || grantedPermissions(c).Has(model.PermDDL) {
+		return true
+	}
+
+	exists, err := importer.TableExists(target)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusForbidden, errorResponse(403, "Permission denied: "+string(model.PermDDL)+" permission required to create the table on this connection"))
+		return false
+	}
+	*createTable = false
+	return true
+}
+
+// ==================== 权限 ====================
+
+// getPermissions 返回当前用户在各连接上的权限，前端据此隐藏无权使用的操作
+func (s *Server) getPermissions(c *gin.Context) {
+	configs, err := s.connManager.ListConfigs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	groups, err := s.groupMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+
+	user := currentUser(c)
+	connections := make(map[string][]model.Permission, len(configs))
+	for _, config := range configs {
+		if perms := s.permissions(user, config, groups); len(perms) > 0 {
+			connections[config.ID] = perms.List()
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"admin":       user.Role == model.RoleAdmin,
+		"connections": connections,
+	}))
+}
+
+// getConnectionDetails 返回解密后的连接配置（密码、SSH 凭据与 TLS 证书），需要 credentials 权限
+func (s *Server) getConnectionDetails(c *gin.Context) {
+	config, err := s.connManager.GetConfig(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, connection.ErrConnectionNotFound) {
+			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(config))
+}
+
+// ==================== 角色与授权 ====================
+
+// listRoles 获取内置与自定义角色
+func (s *Server) listRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(s.policy.Roles()))
+}
+
+// saveRole 创建或更新自定义角色，PUT 时角色名取自路径
+func (s *Server) saveRole(c *gin.Context) {
+	var role model.Role
+	if err := c.ShouldBindJSON(&role); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+	if name := c.Param("name"); name != "" {
+		role.Name = name
+	}
+
+	saved, err := s.policy.SaveRole(&role)
+	if err != nil {
+		writePolicyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(saved))
+}
+
+// deleteRole 删除自定义角色
+func (s *Server) deleteRole(c *gin.Context) {
+	if err := s.policy.DeleteRole(c.Param("name")); err != nil {
+		writePolicyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// listGrants 获取授权，可按 userId 过滤
+func (s *Server) listGrants(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(s.policy.Grants(c.Query("userId"))))
+}
+
+// createGrant 授予用户在连接、分组或所有连接上的角色
+func (s *Server) createGrant(c *gin.Context) {
+	var grant model.Grant
+	if err := c.ShouldBindJSON(&grant); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(400, "Invalid request body"))
+		return
+	}
+	if _, err := s.users.Get(grant.UserID); err != nil {
+		writeUserError(c, err)
+		return
+	}
+	if grant.ConnectionID != "" {
+		if _, err := s.connManager.GetConfig(grant.ConnectionID); err != nil {
+			c.JSON(http.StatusNotFound, errorResponse(404, "Connection not found"))
+			return
+		}
+	}
+	if grant.GroupID != "" {
+		groups, err := s.groupMap()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+			return
+		}
+		if _, ok := groups[grant.GroupID]; !ok {
+			c.JSON(http.StatusNotFound, errorResponse(404, "Group not found"))
+			return
+		}
+	}
+
+	saved, err := s.policy.AddGrant(&grant)
+	if err != nil {
+		writePolicyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(saved))
+}
+
+// deleteGrant 撤销授权
+func (s *Server) deleteGrant(c *gin.Context) {
+	if err := s.policy.DeleteGrant(c.Param("id")); err != nil {
+		writePolicyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// requirePolicy 未启用认证时没有角色与授权
+func (s *Server) requirePolicy(c *gin.Context) {
+	if s.policy == nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(400, "Authentication is disabled"))
+		return
+	}
+	c.Next()
+}
+
+// writePolicyError 按权限策略的错误类型返回状态码
+func writePolicyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrRoleNotFound), errors.Is(err, auth.ErrGrantNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(404, err.Error()))
+	case errors.Is(err, auth.ErrRoleInUse), errors.Is(err, auth.ErrBuiltinRole):
+		c.JSON(http.StatusConflict, errorResponse(409, err.Error()))
+	case errors.Is(err, auth.ErrInvalidRoleDef), errors.Is(err, auth.ErrInvalidGrant):
+		c.JSON(http.StatusBadRequest, errorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(500, err.Error()))
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"database/sql"
+	"dbm/internal/model"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImportCSV_CreateTableRequiresDDL(t *testing.T) {
+	ts := newTestServer(t)
+	_, editor := ts.login("editor", model.RoleUser, model.PermMetadata, model.PermEdit, model.PermDML)
+	_, owner := ts.login("owner", model.RoleUser, model.PermMetadata, model.PermEdit, model.PermDML, model.PermDDL)
+
+	csv := "id,name\n1,alice\n2,bob\n"
+	options := map[string]string{"options": `{"createTable":true}`}
+	importURL := func(table string) string {
+		return "/api/v1/connections/" + ts.connID + "/tables/" + table + "/import/csv"
+	}
+
+	// 没有 DDL 权限时不能通过导入建表
+	if w := ts.upload(importURL("people"), editor, "people.csv", csv, options); w.Code != http.StatusForbidden {
+		t.Fatalf("import into missing table without ddl: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	// 仅预览不建表，不需要 DDL 权限
+	dryRun := map[string]string{"options": `{"createTable":true,"dryRun":true}`}
+	if w := ts.upload(importURL("people"), editor, "people.csv", csv, dryRun); w.Code != http.StatusOK {
+		t.Fatalf("dry run without ddl: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	// 表已存在时只写入数据
+	db, err := sql.Open("sqlite3", ts.dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE existing (id INTEGER, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if w := ts.upload(importURL("existing"), editor, "existing.csv", csv, options); w.Code != http.StatusOK {
+		t.Fatalf("import into existing table without ddl: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	// 有 DDL 权限时可以建表
+	if w := ts.upload(importURL("people"), owner, "people.csv", csv, options); w.Code != http.StatusOK {
+		t.Fatalf("import into missing table with ddl: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM people").Scan(&n); err != nil {
+		t.Fatalf("count people: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("people rows = %d, want 2", n)
+	}
+}
+
+func TestAuthorize_Routes(t *testing.T) {
+	ts := newTestServer(t)
+	_, admin := ts.login("admin", model.RoleAdmin)
+	_, reader := ts.login("reader", model.RoleUser, model.PermMetadata, model.PermSelect)
+	_, stranger := ts.login("stranger", model.RoleUser)
+
+	conn := "/api/v1/connections/" + ts.connID
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		body   any
+		want   int
+	}{
+		{"metadata", http.MethodGet, conn + "/tables", reader, nil, http.StatusOK},
+		{"no grant", http.MethodGet, conn + "/tables", stranger, nil, http.StatusForbidden},
+		{"unknown connection", http.MethodGet, "/api/v1/connections/missing/tables", reader, nil, http.StatusNotFound},
+		{"select", http.MethodPost, conn + "/query", reader, map[string]string{"query": "SELECT 1"}, http.StatusOK},
+		// 按语句类别校验：只读用户不能执行 DML、DDL 与有副作用的函数调用
+		{"dml statement", http.MethodPost, conn + "/execute", reader, map[string]string{"query": "DELETE FROM t"}, http.StatusForbidden},
+		{"ddl statement", http.MethodPost, conn + "/query", reader, map[string]string{"query": "SELECT 1; DROP TABLE t"}, http.StatusForbidden},
+		{"side-effecting function", http.MethodPost, conn + "/query", reader, map[string]string{"query": "SELECT load_extension('x')"}, http.StatusForbidden},
+		{"alter", http.MethodPost, conn + "/tables/t/rename", reader, map[string]string{"newName": "t2"}, http.StatusForbidden},
+		{"edit", http.MethodDelete, conn + "/tables/t/data", reader, map[string]any{"key": map[string]any{"id": 1}}, http.StatusForbidden},
+		{"export", http.MethodPost, conn + "/export/csv", reader, map[string]string{"query": "SELECT 1"}, http.StatusForbidden},
+		{"credentials", http.MethodGet, conn + "/details", reader, nil, http.StatusForbidden},
+		{"admin credentials", http.MethodGet, conn + "/details", admin, nil, http.StatusOK},
+		{"admin only", http.MethodDelete, conn, reader, nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w *httptest.ResponseRecorder
+			if tt.body != nil {
+				w = ts.doJSON(tt.method, tt.target, tt.token, tt.body)
+			} else {
+				w = ts.do(tt.method, tt.target, tt.token, nil, nil)
+			}
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestSessionAndQueryOwnership(t *testing.T) {
+	ts := newTestServer(t)
+	_, admin := ts.login("admin", model.RoleAdmin)
+	aliceUser, alice := ts.login("alice", model.RoleUser, model.PermMetadata, model.PermSelect)
+	_, bob := ts.login("bob", model.RoleUser, model.PermMetadata, model.PermSelect)
+
+	sessions := "/api/v1/connections/" + ts.connID + "/sessions"
+	w := ts.do(http.MethodPost, sessions, alice, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create session: status = %d, body = %s", w.Code, w.Body)
+	}
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	session := sessions + "/" + created.Data.ID
+
+	listed := func(token string) int {
+		w := ts.do(http.MethodGet, sessions, token, nil, nil)
+		var resp struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return len(resp.Data)
+	}
+	if n := listed(bob); n != 0 {
+		t.Errorf("bob sees %d sessions, want 0", n)
+	}
+	if n := listed(admin); n != 1 {
+		t.Errorf("admin sees %d sessions, want 1", n)
+	}
+
+	// 其他用户不能使用或关闭会话
+	if w := ts.do(http.MethodGet, session, bob, nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("get other user's session: status = %d, want 404", w.Code)
+	}
+	if w := ts.doJSON(http.MethodPost, "/api/v1/connections/"+ts.connID+"/query", bob,
+		map[string]string{"query": "SELECT 1", "sessionId": created.Data.ID}); w.Code != http.StatusNotFound {
+		t.Errorf("query in other user's session: status = %d, want 404", w.Code)
+	}
+	if w := ts.do(http.MethodDelete, session, bob, nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("close other user's session: status = %d, want 404", w.Code)
+	}
+	if w := ts.do(http.MethodGet, session, alice, nil, nil); w.Code != http.StatusOK {
+		t.Errorf("get own session: status = %d, want 200", w.Code)
+	}
+	if w := ts.do(http.MethodDelete, session, admin, nil, nil); w.Code != http.StatusOK {
+		t.Errorf("admin close session: status = %d, want 200", w.Code)
+	}
+
+	// 运行中的查询只能由发起的用户或管理员取消
+	cancel := "/api/v1/connections/" + ts.connID + "/queries/q-1/cancel"
+	_, _, done, err := ts.queries.Register(t.Context(), ts.connID, aliceUser.ID, "q-1", "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+	if w := ts.do(http.MethodPost, cancel, bob, nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("cancel other user's query: status = %d, want 404", w.Code)
+	}
+	if w := ts.do(http.MethodPost, cancel, admin, nil, nil); w.Code != http.StatusOK {
+		t.Errorf("admin cancel query: status = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"dbm/internal/audit"
+	"dbm/internal/auth"
+	"dbm/internal/config"
+	"dbm/internal/connection"
+	"dbm/internal/model"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// testServer 启用认证与审计的测试服务，带一个 SQLite 连接
+type testServer struct {
+	*Server
+	t      *testing.T
+	policy *auth.Policy
+	audit  *audit.Log
+	connID string
+	dbPath string
+}
+
+// newTestServer 在临时目录中创建测试服务
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+
+	cfg := config.Default()
+	manager, err := connection.NewManager(dir, "test-master-key-123", cfg.Pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := auth.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := auth.NewPolicy(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog, err := audit.NewLog(filepath.Join(dir, "audit"), cfg.Audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+
+	ts := &testServer{
+		t:      t,
+		policy: policy,
+		audit:  auditLog,
+		connID: "conn1",
+		dbPath: filepath.Join(dir, "test.db"),
+	}
+	if err := manager.AddConnection(&model.ConnectionConfig{
+		ID:   ts.connID,
+		Name: "test",
+		Type: model.DatabaseSQLite,
+		Host: ts.dbPath,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.Server = NewServer(manager, users, policy, auditLog, nil, cfg)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// login 创建用户并返回 Bearer 令牌
+// 指定权限时为用户创建角色 test-<用户名> 并授予测试连接。
+func (ts *testServer) login(username string, role model.UserRole, perms ...model.Permission) (*model.User, string) {
+	ts.t.Helper()
+
+	user, err := ts.users.Create(username, "password-"+username, role)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	if len(perms) > 0 {
+		if _, err := ts.policy.SaveRole(&model.Role{Name: "test-" + username, Permissions: perms}); err != nil {
+			ts.t.Fatal(err)
+		}
+		if _, err := ts.policy.AddGrant(&model.Grant{UserID: user.ID, ConnectionID: ts.connID, Role: "test-" + username}); err != nil {
+			ts.t.Fatal(err)
+		}
+	}
+	_, token := ts.authSessions.Create(user.ID)
+	return user, token
+}
+
+// do 以 Bearer 令牌发送请求，token 为空时不带认证
+func (ts *testServer) do(method, target, token string, body io.Reader, header http.Header) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	req := httptest.NewRequest(method, target, body)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	ts.engine.ServeHTTP(w, req)
+	return w
+}
+
+// doJSON 发送 JSON 请求体
+func (ts *testServer) doJSON(method, target, token string, body any) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return ts.do(method, target, token, bytes.NewReader(data), http.Header{"Content-Type": {"application/json"}})
+}
+
+// upload 以 multipart 表单上传文件
+func (ts *testServer) upload(target, token, filename, content string, fields map[string]string) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			ts.t.Fatal(err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	if _, err := io.WriteString(fw, content); err != nil {
+		ts.t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		ts.t.Fatal(err)
+	}
+	return ts.do(http.MethodPost, target, token, &body, http.Header{"Content-Type": {mw.FormDataContentType()}})
+}
@@ -20,12 +20,14 @@ func NewConnectionService(connManager *connection.Manager, factory *adapter.Fact
 	}
 }
 
-// GetDB 获取数据库连接（每次创建新连接）
-func (s *ConnectionService) GetDB(connectionID string, database string) (any, *model.ConnectionConfig, error) {
+// GetDB 获取数据库连接并占用
+// 连接按 (连接 ID, 数据库) 放入连接池复用，调用方不应关闭返回的连接；
+// 使用结束后必须调用返回的 release，占用期间连接池不会被闲置回收。出错时 release 为空操作。
+func (s *ConnectionService) GetDB(connectionID string, database string) (any, *model.ConnectionConfig, func(), error) {
 	// 获取配置
 	config, err := s.connManager.GetConfig(connectionID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, noRelease, err
 	}
 
 	// 创建副本以避免修改原始配置（ contamination ）
@@ -36,38 +38,65 @@ func (s *ConnectionService) GetDB(connectionID string, database string) (any, *m
 		configCopy.Database = database
 	}
 
+	// 优先复用连接池中的连接
+	for {
+		if db, release, ok := s.connManager.AcquireConnection(connectionID, database); ok {
+			return db, &configCopy, release, nil
+		}
+		// 新建的连接池在占用前被回收时重新创建
+		if err := s.connect(connectionID, database, &configCopy); err != nil {
+			return nil, &configCopy, noRelease, err
+		}
+	}
+}
+
+// noRelease 获取连接失败时返回的空 release
+func noRelease() {}
+
+// connect 创建连接并放入连接池，并发创建时以先登记的连接为准
+func (s *ConnectionService) connect(connectionID, database string, configCopy *model.ConnectionConfig) error {
 	// 创建适配器
 	dbAdapter, err := s.factory.CreateAdapter(configCopy.Type)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	// 创建新连接
-	db, err := dbAdapter.Connect(&configCopy)
+	// 启用 SSH 隧道时经同一连接共享的隧道连接，连接池关闭连接时释放隧道引用
+	tunnel, release, err := s.connManager.AcquireTunnel(configCopy)
 	if err != nil {
-		return nil, &configCopy, err
+		return err
+	}
+	target := configCopy
+	if tunnel != nil {
+		host, port := tunnel.Addr()
+		target = adapter.Tunneled(configCopy, host, port)
 	}
 
-	// 放入连接池（如果未指定特定数据库，则认为是主连接）
-	if database == "" {
-		s.connManager.PutConnection(connectionID, db)
+	// 创建新连接
+	conn, err := dbAdapter.Connect(target)
+	if err != nil {
+		release()
+		return err
 	}
 
-	return db, &configCopy, nil
+	s.connManager.PutConnection(connectionID, database, conn, func() error {
+		defer release()
+		return dbAdapter.Close(conn)
+	})
+	return nil
 }
 
 // GetDBCached 获取缓存的数据库连接
-func (s *ConnectionService) GetDBCached(connectionID string, database string) (any, *model.ConnectionConfig, error) {
-	// 如果是主数据库连接，尝试从缓存获取
-	if database == "" {
-		if db, config, err := s.connManager.GetConnection(connectionID); err == nil && db != nil {
-			return db, config, nil
-		}
-	}
-
+// Deprecated: GetDB 已复用连接池，直接使用 GetDB。
+func (s *ConnectionService) GetDBCached(connectionID string, database string) (any, *model.ConnectionConfig, func(), error) {
 	return s.GetDB(connectionID, database)
 }
 
+// PoolStats 获取连接的连接池统计信息
+func (s *ConnectionService) PoolStats(connectionID string) ([]model.PoolStats, error) {
+	return s.connManager.PoolStats(connectionID)
+}
+
 // CloseConnection 关闭连接
 func (s *ConnectionService) CloseConnection(connectionID string) error {
 	return s.connManager.CloseConnection(connectionID)
@@ -81,6 +110,20 @@ func (s *ConnectionService) TestConnection(config *model.ConnectionConfig) (*Con
 		return nil, err
 	}
 
+	// 启用 SSH 隧道时建立独立的隧道，按待测试的配置登录，不影响已共享的隧道
+	if config.SSH != nil && config.SSH.Enabled {
+		tunnel, err := connection.OpenTunnel(config)
+		if err != nil {
+			return &ConnectionTestResult{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+		defer tunnel.Close()
+		host, port := tunnel.Addr()
+		config = adapter.Tunneled(config, host, port)
+	}
+
 	// 尝试连接
 	db, err := dbAdapter.Connect(config)
 	if err != nil {
@@ -102,12 +145,14 @@ func (s *ConnectionService) TestConnection(config *model.ConnectionConfig) (*Con
 	return &ConnectionTestResult{
 		Success: true,
 		Latency: "<10ms",
+		TLS:     adapter.NegotiatedTLS(config),
 	}, nil
 }
 
 // ConnectionTestResult 连接测试结果
 type ConnectionTestResult struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
-	Latency string `json:"latency,omitempty"`
+	Success bool           `json:"success"`
+	Error   string         `json:"error,omitempty"`
+	Latency string         `json:"latency,omitempty"`
+	TLS     *model.TLSInfo `json:"tls,omitempty"` // 启用 TLS 时握手协商的协议版本与加密套件
 }
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"dbm/internal/export"
+	"dbm/internal/model"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// exportFilePrefix 导出文件名前缀，启动时据此清除上次运行遗留的文件
+	exportFilePrefix = "dbm-export-"
+	// exportBufferSize 写出导出文件的缓冲区大小
+	exportBufferSize = 256 * 1024
+	// exportCleanupInterval 检查过期导出任务的最长间隔
+	exportCleanupInterval = 10 * time.Minute
+)
+
+var (
+	// ErrExportNotFound 导出任务不存在或已过期
+	ErrExportNotFound = errors.New("导出任务不存在或已过期")
+	// ErrExportNotReady 导出任务尚未完成或未成功，没有可下载的文件
+	ErrExportNotReady = errors.New("导出任务尚未完成，没有可下载的文件")
+	// ErrExportCompression 不支持的下载压缩格式
+	ErrExportCompression = errors.New("不支持的压缩格式，可选 gzip 或 zip")
+)
+
+// ExportTask 导出任务的执行函数，将导出内容写入 w
+// ctx 携带行计数器，适配器流式读取数据时据此统计已写出的行数。
+type ExportTask func(ctx context.Context, w io.Writer) error
+
+// ExportFile 打开用于下载的导出文件
+type ExportFile struct {
+	*os.File
+	Name        string // 下载时的文件名
+	ContentType string // 内容类型，为空时按文件名推断
+}
+
+// exportEntry 导出任务登记项
+type exportEntry struct {
+	mu          sync.Mutex
+	job         model.ExportJob
+	path        string // 导出文件路径，压缩后的文件在其后追加扩展名
+	contentType string
+	rows        atomic.Int64
+	bytes       atomic.Int64
+	cancel      context.CancelFunc
+
+	compressMu sync.Mutex // 生成压缩文件时持有，避免并发下载重复压缩
+}
+
+// snapshot 返回任务进度的副本
+func (e *exportEntry) snapshot() model.ExportJob {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	job := e.job
+	job.Rows = e.rows.Load()
+	job.Bytes = e.bytes.Load()
+	return job
+}
+
+// update 在锁内修改任务进度
+func (e *exportEntry) update(fn func(job *model.ExportJob)) {
+	e.mu.Lock()
+	fn(&e.job)
+	e.mu.Unlock()
+}
+
+// removeFiles 删除导出文件及其压缩文件
+func (e *exportEntry) removeFiles() {
+	files, _ := filepath.Glob(e.path + "*")
+	for _, file := range files {
+		_ = os.Remove(file)
+	}
+}
+
+// ExportManager 后台导出任务管理器
+// 导出写入 dir 下的文件，客户端断开不影响任务；完成后通过下载接口获取（支持断点续传），
+// 已结束的任务与文件保留 retention 后清除。
+type ExportManager struct {
+	mu        sync.Mutex
+	jobs      map[string]*exportEntry // key: jobID
+	dir       string
+	retention time.Duration
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewExportManager 创建导出任务管理器并启动过期清理
+// 任务只保存在内存中，dir 下上次运行遗留的导出文件无法再下载，创建时一并删除。
+func NewExportManager(dir string, retention time.Duration) *ExportManager {
+	m := &ExportManager{
+		jobs:      make(map[string]*exportEntry),
+		dir:       dir,
+		retention: retention,
+		stop:      make(chan struct{}),
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, exportFilePrefix+"*"))
+	for _, file := range files {
+		_ = os.Remove(file)
+	}
+
+	go m.cleanup()
+	return m
+}
+
+// Start 创建导出任务并在后台执行
+// fileName 与 contentType 用于下载，fileName 的扩展名同时用于导出文件。
+func (m *ExportManager) Start(connectionID, database, format, fileName, contentType string, task ExportTask) (model.ExportJob, error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return model.ExportJob{}, err
+	}
+
+	id := uuid.New().String()
+	path := filepath.Join(m.dir, exportFilePrefix+id+filepath.Ext(fileName))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return model.ExportJob{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &exportEntry{
+		job: model.ExportJob{
+			ID:           id,
+			ConnectionID: connectionID,
+			Database:     database,
+			Format:       format,
+			FileName:     fileName,
+			Status:       model.ExportRunning,
+			StartedAt:    time.Now(),
+		},
+		path:        path,
+		contentType: contentType,
+		cancel:      cancel,
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[id] = e
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		e.run(export.WithRowCounter(ctx, &e.rows), file, task, m.retention)
+	}()
+
+	return e.snapshot(), nil
+}
+
+// Get 获取导出任务进度
+func (m *ExportManager) Get(jobID string) (model.ExportJob, error) {
+	e, err := m.entry(jobID)
+	if err != nil {
+		return model.ExportJob{}, err
+	}
+	return e.snapshot(), nil
+}
+
+// List 获取所有导出任务，最近开始的在前
+func (m *ExportManager) List() []model.ExportJob {
+	m.mu.Lock()
+	m.prune()
+	jobs := make([]model.ExportJob, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		jobs = append(jobs, e.snapshot())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.After(jobs[j].StartedAt) })
+	return jobs
+}
+
+// Cancel 取消进行中的导出任务，已结束的任务直接删除任务及其文件；任务不存在时返回 false
+// 取消的任务删除未写完的文件，任务状态保留到过期以便客户端查询。
+func (m *ExportManager) Cancel(jobID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, exists := m.jobs[jobID]
+	if !exists {
+		return false
+	}
+
+	if e.snapshot().Status == model.ExportRunning {
+		e.cancel()
+		return true
+	}
+
+	delete(m.jobs, jobID)
+	e.removeFiles()
+	return true
+}
+
+// Open 打开已完成任务的导出文件用于下载
+// compress 为 gzip 或 zip 时返回压缩后的文件，首次下载时生成并保留，续传时复用。
+func (m *ExportManager) Open(jobID, compress string) (*ExportFile, error) {
+	e, err := m.entry(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	job := e.snapshot()
+	if job.Status != model.ExportCompleted {
+		return nil, ErrExportNotReady
+	}
+
+	var (
+		file        *os.File
+		name        string
+		contentType string // 压缩文件按扩展名推断
+	)
+	switch compress {
+	case "":
+		name, contentType = job.FileName, e.contentType
+		file, err = os.Open(e.path)
+	case "gzip":
+		name = job.FileName + ".gz"
+		file, err = e.openCompressed(".gz", func(w io.Writer, src io.Reader) error {
+			zw := gzip.NewWriter(w)
+			zw.Name = job.FileName
+			if _, err := io.Copy(zw, src); err != nil {
+				return err
+			}
+			return zw.Close()
+		})
+	case "zip":
+		name = strings.TrimSuffix(job.FileName, filepath.Ext(job.FileName)) + ".zip"
+		file, err = e.openCompressed(".zip", func(w io.Writer, src io.Reader) error {
+			zw := zip.NewWriter(w)
+			entry, err := zw.CreateHeader(&zip.FileHeader{Name: job.FileName, Method: zip.Deflate, Modified: *job.FinishedAt})
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(entry, src); err != nil {
+				return err
+			}
+			return zw.Close()
+		})
+	default:
+		return nil, ErrExportCompression
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ExportFile{File: file, Name: name, ContentType: contentType}, nil
+}
+
+// Shutdown 停止过期清理并取消所有进行中的导出任务
+func (m *ExportManager) Shutdown() {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.jobs {
+		e.cancel()
+	}
+}
+
+// entry 查找任务
+func (m *ExportManager) entry(jobID string) (*exportEntry, error) {
+	m.mu.Lock()
+	e, exists := m.jobs[jobID]
+	m.mu.Unlock()
+
+	if !exists {
+		return nil, ErrExportNotFound
+	}
+	return e, nil
+}
+
+// cleanup 定期清除过期的任务与文件，直到 Shutdown
+func (m *ExportManager) cleanup() {
+	interval := min(m.retention, exportCleanupInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			m.prune()
+			m.mu.Unlock()
+		}
+	}
+}
+
+// prune 清除超过保留时间的已结束任务并删除其文件，调用方需持有 m.mu
+func (m *ExportManager) prune() {
+	now := time.Now()
+	for id, e := range m.jobs {
+		job := e.snapshot()
+		if job.ExpiresAt != nil && now.After(*job.ExpiresAt) {
+			delete(m.jobs, id)
+			e.removeFiles()
+		}
+	}
+}
+
+// run 执行导出并写入文件，失败或取消时删除不完整的文件
+func (e *exportEntry) run(ctx context.Context, file *os.File, task ExportTask, retention time.Duration) {
+	buf := bufio.NewWriterSize(&countingWriter{w: file, n: &e.bytes}, exportBufferSize)
+	err := task(ctx, buf)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	status := model.ExportCompleted
+	switch {
+	case ctx.Err() != nil:
+		status, err = model.ExportCancelled, nil
+	case err != nil:
+		status = model.ExportFailed
+	}
+	if status != model.ExportCompleted {
+		_ = os.Remove(e.path)
+	}
+
+	e.update(func(job *model.ExportJob) {
+		now := time.Now()
+		expires := now.Add(retention)
+		job.Status = status
+		job.FinishedAt = &now
+		job.ExpiresAt = &expires
+		if err != nil {
+			job.Error = err.Error()
+		}
+	})
+}
+
+// openCompressed 打开导出文件的压缩版本，不存在时先以 compress 生成
+// 先写入临时文件再重命名，生成中断时不会留下不完整的压缩文件。
+func (e *exportEntry) openCompressed(ext string, compress func(w io.Writer, src io.Reader) error) (*os.File, error) {
+	e.compressMu.Lock()
+	defer e.compressMu.Unlock()
+
+	path := e.path + ext
+	if file, err := os.Open(path); err == nil {
+		return file, nil
+	}
+
+	src, err := os.Open(e.path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriterSize(tmp, exportBufferSize)
+	err = compress(w, src)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// countingWriter 统计已写出字节数的写入器
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"dbm/internal/export"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitExport 等待导出任务结束并返回最终进度
+func waitExport(t *testing.T, m *ExportManager, jobID string) model.ExportJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != model.ExportRunning {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("export job did not finish")
+	return model.ExportJob{}
+}
+
+// csvTask 以行迭代器写出 n 行 CSV，与适配器一样经过 context 中的行计数器
+func csvTask(n int) ExportTask {
+	return func(ctx context.Context, w io.Writer) error {
+		data := make([][]any, n)
+		for i := range data {
+			data[i] = []any{int64(i), fmt.Sprintf("name-%d", i)}
+		}
+		rows := export.CountRows(ctx, export.NewSliceRows([]string{"id", "name"}, data))
+		return export.NewCSVExporter(&model.CSVOptions{IncludeHeader: true}).Export(w, rows)
+	}
+}
+
+func TestExportManager_CompleteAndDownload(t *testing.T) {
+	m := NewExportManager(t.TempDir(), time.Hour)
+	defer m.Shutdown()
+
+	started, err := m.Start("conn", "db", "csv", "export.csv", "text/csv; charset=utf-8", csvTask(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := waitExport(t, m, started.ID)
+	// CSV 默认以带 BOM 的 UTF-8 编码
+	want := "\ufeffid,name\n0,name-0\n1,name-1\n2,name-2\n"
+	if job.Status != model.ExportCompleted || job.Rows != 3 || job.Bytes != int64(len(want)) || job.ExpiresAt == nil {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	read := func(compress string) (string, string) {
+		f, err := m.Open(job.ID, compress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		file := f.File
+
+		var r io.Reader = file
+		switch compress {
+		case "gzip":
+			if r, err = gzip.NewReader(file); err != nil {
+				t.Fatal(err)
+			}
+		case "zip":
+			info, _ := file.Stat()
+			zr, err := zip.NewReader(file, info.Size())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(zr.File) != 1 || zr.File[0].Name != "export.csv" {
+				t.Fatalf("unexpected zip entries: %v", zr.File)
+			}
+			if r, err = zr.File[0].Open(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data), f.Name
+	}
+
+	for compress, wantName := range map[string]string{"": "export.csv", "gzip": "export.csv.gz", "zip": "export.zip"} {
+		// 第二次读取复用已生成的压缩文件
+		for i := 0; i < 2; i++ {
+			if got, name := read(compress); got != want || name != wantName {
+				t.Errorf("compress %q: got %q as %s", compress, got, name)
+			}
+		}
+	}
+
+	if _, err := m.Open(job.ID, "bzip2"); !errors.Is(err, ErrExportCompression) {
+		t.Errorf("Open(bzip2) error = %v", err)
+	}
+}
+
+func TestExportManager_FailedRemovesFile(t *testing.T) {
+	dir := t.TempDir()
+	m := NewExportManager(dir, time.Hour)
+	defer m.Shutdown()
+
+	started, err := m.Start("conn", "", "sql", "export.sql", "text/sql; charset=utf-8", func(ctx context.Context, w io.Writer) error {
+		_, _ = w.Write([]byte("INSERT INTO t VALUES (1);\n"))
+		return errors.New("connection lost")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := waitExport(t, m, started.ID)
+	if job.Status != model.ExportFailed || job.Error != "connection lost" {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	if _, err := m.Open(job.ID, ""); !errors.Is(err, ErrExportNotReady) {
+		t.Errorf("Open error = %v, want ErrExportNotReady", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("partial file left behind: %v", files)
+	}
+}
+
+func TestExportManager_CancelAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	m := NewExportManager(dir, time.Hour)
+	defer m.Shutdown()
+
+	started, err := m.Start("conn", "", "csv", "export.csv", "text/csv; charset=utf-8", func(ctx context.Context, w io.Writer) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Cancel(started.ID) {
+		t.Fatal("Cancel returned false")
+	}
+	if job := waitExport(t, m, started.ID); job.Status != model.ExportCancelled || job.Error != "" {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	// 已结束的任务再次删除时连同记录一起清除
+	if !m.Cancel(started.ID) {
+		t.Fatal("Cancel of finished job returned false")
+	}
+	if _, err := m.Get(started.ID); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Get error = %v, want ErrExportNotFound", err)
+	}
+	if m.Cancel(started.ID) {
+		t.Error("Cancel of deleted job returned true")
+	}
+}
+
+func TestExportManager_Retention(t *testing.T) {
+	dir := t.TempDir()
+	// 上次运行遗留的导出文件在创建管理器时删除，其他文件保留
+	stale := filepath.Join(dir, exportFilePrefix+"old.csv")
+	other := filepath.Join(dir, "keep.txt")
+	for _, path := range []string{stale, other} {
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewExportManager(dir, 20*time.Millisecond)
+	defer m.Shutdown()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale export file not removed: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("unrelated file removed: %v", err)
+	}
+
+	started, err := m.Start("conn", "", "csv", "export.csv", "text/csv; charset=utf-8", csvTask(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitExport(t, m, started.ID)
+	file, err := m.Open(started.ID, "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := m.Get(started.ID); errors.Is(err, ErrExportNotFound) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := m.Get(started.ID); !errors.Is(err, ErrExportNotFound) {
+		t.Fatal("expired job not removed")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, exportFilePrefix+"*"))
+	if len(files) != 0 {
+		t.Errorf("expired files not removed: %v", files)
+	}
+}
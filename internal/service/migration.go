@@ -0,0 +1,595 @@
+package service
+
+import (
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/export"
+	"dbm/internal/importer"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultMigrationBatchSize 每批写入目标表的默认行数
+	defaultMigrationBatchSize = 1000
+	// migrationRetention 已结束任务的保留时间，失败或取消的任务在此期间可以恢复
+	migrationRetention = 24 * time.Hour
+)
+
+var (
+	// ErrMigrationNotFound 迁移任务不存在或已过期
+	ErrMigrationNotFound = errors.New("迁移任务不存在或已过期")
+	// ErrMigrationUnsupported 数据库类型不支持作为迁移的源或目标
+	ErrMigrationUnsupported = errors.New("该数据库类型不支持迁移")
+	// ErrMigrationSameTarget 源与目标是同一个数据库
+	ErrMigrationSameTarget = errors.New("源与目标不能是同一个数据库")
+	// ErrMigrationChoiceRequired 存在需要用户选择目标类型的源类型
+	ErrMigrationChoiceRequired = errors.New("以下类型需要选择目标类型")
+	// ErrMigrationNotResumable 只有失败或已取消的任务可以恢复
+	ErrMigrationNotResumable = errors.New("只能恢复失败或已取消的迁移任务")
+)
+
+// MigrationPlan 迁移前的类型映射预览
+type MigrationPlan struct {
+	SourceType model.DatabaseType         `json:"sourceType"`
+	TargetType model.DatabaseType         `json:"targetType"`
+	Mapping    *export.TypeMappingResult  `json:"mapping"`
+	Tables     []model.MigrationTablePlan `json:"tables"`
+}
+
+// migrationEndpoint 迁移的源或目标
+type migrationEndpoint struct {
+	adapter  adapter.DatabaseAdapter
+	db       any
+	dbType   model.DatabaseType
+	database string
+}
+
+// migrationEntry 迁移任务登记项
+type migrationEntry struct {
+	mu     sync.Mutex
+	job    model.MigrationJob
+	opts   model.MigrationOptions
+	mapped map[string]string // 源类型 → 目标类型，已应用用户选择
+	cancel context.CancelFunc
+}
+
+// snapshot 返回任务进度的副本
+func (e *migrationEntry) snapshot() model.MigrationJob {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	job := e.job
+	job.Tables = append([]model.MigrationTable(nil), e.job.Tables...)
+	return job
+}
+
+// update 在锁内修改任务进度
+func (e *migrationEntry) update(fn func(job *model.MigrationJob)) {
+	e.mu.Lock()
+	fn(&e.job)
+	e.mu.Unlock()
+}
+
+// updateTable 在锁内修改第 i 个表的进度
+func (e *migrationEntry) updateTable(i int, fn func(table *model.MigrationTable)) {
+	e.update(func(job *model.MigrationJob) { fn(&job.Tables[i]) })
+}
+
+// MigrationManager 跨连接迁移任务管理器
+// 任务逐表复制数据，失败或取消后可以从每个表的断点恢复；已结束的任务保留 migrationRetention 后清除。
+type MigrationManager struct {
+	mu            sync.Mutex
+	jobs          map[string]*migrationEntry // key: jobID
+	connectionSvc *ConnectionService
+}
+
+// NewMigrationManager 创建迁移任务管理器
+func NewMigrationManager(connectionSvc *ConnectionService) *MigrationManager {
+	return &MigrationManager{
+		jobs:          make(map[string]*migrationEntry),
+		connectionSvc: connectionSvc,
+	}
+}
+
+// Plan 预览迁移的类型映射与目标表
+func (m *MigrationManager) Plan(connectionID string, opts *model.MigrationOptions) (*MigrationPlan, error) {
+	src, dst, release, err := m.endpoints(connectionID, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return buildMigrationPlan(src, dst, opts)
+}
+
+// Start 创建迁移任务并在后台执行
+// 需要用户选择的类型必须在 TypeChoices 中给出，否则返回 ErrMigrationChoiceRequired。
+func (m *MigrationManager) Start(connectionID string, opts *model.MigrationOptions) (model.MigrationJob, error) {
+	src, dst, release, err := m.endpoints(connectionID, opts)
+	if err != nil {
+		return model.MigrationJob{}, err
+	}
+	plan, err := buildMigrationPlan(src, dst, opts)
+	if err != nil {
+		release()
+		return model.MigrationJob{}, err
+	}
+
+	var missing []string
+	for typ := range plan.Mapping.RequiresUser {
+		if _, ok := opts.TypeChoices[typ]; !ok {
+			missing = append(missing, typ)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		release()
+		return model.MigrationJob{}, fmt.Errorf("%w: %s", ErrMigrationChoiceRequired, strings.Join(missing, ", "))
+	}
+
+	e := &migrationEntry{
+		job: model.MigrationJob{
+			ID:             uuid.New().String(),
+			ConnectionID:   connectionID,
+			SourceDatabase: opts.SourceDatabase,
+			TargetID:       opts.TargetID,
+			TargetDatabase: opts.TargetDatabase,
+			Status:         model.MigrationRunning,
+			StartedAt:      time.Now(),
+		},
+		opts:   *opts,
+		mapped: plan.Mapping.Mapped,
+	}
+	if e.opts.BatchSize <= 0 {
+		e.opts.BatchSize = defaultMigrationBatchSize
+	}
+	for _, table := range opts.Tables {
+		e.job.Tables = append(e.job.Tables, model.MigrationTable{Table: table, Status: model.MigrationPending})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[e.job.ID] = e
+	m.mu.Unlock()
+
+	go func() {
+		defer release()
+		defer cancel()
+		e.run(ctx, src, dst)
+	}()
+
+	return e.snapshot(), nil
+}
+
+// Resume 从断点恢复失败或已取消的任务：跳过已完成的表，未完成的表跳过已写入的行
+func (m *MigrationManager) Resume(connectionID, jobID string) (model.MigrationJob, error) {
+	e, err := m.entry(connectionID, jobID)
+	if err != nil {
+		return model.MigrationJob{}, err
+	}
+	src, dst, release, err := m.endpoints(connectionID, &e.opts)
+	if err != nil {
+		return model.MigrationJob{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.mu.Lock()
+	if e.job.Status != model.MigrationFailed && e.job.Status != model.MigrationCancelled {
+		e.mu.Unlock()
+		cancel()
+		release()
+		return model.MigrationJob{}, ErrMigrationNotResumable
+	}
+	e.job.Status = model.MigrationRunning
+	e.job.Error = ""
+	e.job.FinishedAt = nil
+	e.cancel = cancel
+	e.mu.Unlock()
+
+	go func() {
+		defer release()
+		defer cancel()
+		e.run(ctx, src, dst)
+	}()
+
+	return e.snapshot(), nil
+}
+
+// Get 获取迁移任务进度
+func (m *MigrationManager) Get(connectionID, jobID string) (model.MigrationJob, error) {
+	e, err := m.entry(connectionID, jobID)
+	if err != nil {
+		return model.MigrationJob{}, err
+	}
+	return e.snapshot(), nil
+}
+
+// Options 获取迁移任务创建时的选项，恢复任务前据此校验目标连接的权限
+func (m *MigrationManager) Options(connectionID, jobID string) (model.MigrationOptions, error) {
+	e, err := m.entry(connectionID, jobID)
+	if err != nil {
+		return model.MigrationOptions{}, err
+	}
+	return e.opts, nil
+}
+
+// List 获取源连接下的迁移任务
+func (m *MigrationManager) List(connectionID string) []model.MigrationJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	jobs := make([]model.MigrationJob, 0)
+	for _, e := range m.jobs {
+		if e.job.ConnectionID == connectionID {
+			jobs = append(jobs, e.snapshot())
+		}
+	}
+	return jobs
+}
+
+// Cancel 取消迁移任务，任务不存在时返回 false
+// 已提交的批次保留在目标表中，恢复任务时从断点继续。
+func (m *MigrationManager) Cancel(connectionID, jobID string) bool {
+	e, err := m.entry(connectionID, jobID)
+	if err != nil {
+		return false
+	}
+
+	e.mu.Lock()
+	cancel := e.cancel
+	e.mu.Unlock()
+	cancel()
+	return true
+}
+
+// Shutdown 取消所有进行中的迁移任务
+func (m *MigrationManager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.jobs {
+		e.mu.Lock()
+		e.cancel()
+		e.mu.Unlock()
+	}
+}
+
+// entry 查找连接下的任务
+func (m *MigrationManager) entry(connectionID, jobID string) (*migrationEntry, error) {
+	m.mu.Lock()
+	e, exists := m.jobs[jobID]
+	m.mu.Unlock()
+
+	if !exists || e.job.ConnectionID != connectionID {
+		return nil, ErrMigrationNotFound
+	}
+	return e, nil
+}
+
+// prune 清除超过保留时间的已结束任务，调用方需持有 m.mu
+func (m *MigrationManager) prune() {
+	for id, e := range m.jobs {
+		job := e.snapshot()
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > migrationRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// endpoints 获取迁移的源与目标连接，使用结束后调用 release 释放两端的连接占用
+func (m *MigrationManager) endpoints(connectionID string, opts *model.MigrationOptions) (src, dst *migrationEndpoint, release func(), err error) {
+	if connectionID == opts.TargetID && opts.SourceDatabase == opts.TargetDatabase {
+		return nil, nil, nil, ErrMigrationSameTarget
+	}
+
+	src, releaseSrc, err := m.endpoint(connectionID, opts.SourceDatabase)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dst, releaseDst, err := m.endpoint(opts.TargetID, opts.TargetDatabase)
+	if err != nil {
+		releaseSrc()
+		return nil, nil, nil, err
+	}
+	release = func() {
+		releaseSrc()
+		releaseDst()
+	}
+
+	if _, ok := src.adapter.(adapter.RowStreamer); !ok {
+		release()
+		return nil, nil, nil, fmt.Errorf("%w: %s 不支持读取表数据", ErrMigrationUnsupported, src.dbType)
+	}
+	if _, ok := dst.adapter.(adapter.BulkInserter); !ok {
+		release()
+		return nil, nil, nil, fmt.Errorf("%w: %s 不支持批量写入", ErrMigrationUnsupported, dst.dbType)
+	}
+	return src, dst, release, nil
+}
+
+// endpoint 获取连接与适配器
+func (m *MigrationManager) endpoint(connectionID, database string) (*migrationEndpoint, func(), error) {
+	db, config, release, err := m.connectionSvc.GetDB(connectionID, database)
+	if err != nil {
+		return nil, nil, err
+	}
+	dbAdapter, err := m.connectionSvc.factory.CreateAdapter(config.Type)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return &migrationEndpoint{adapter: dbAdapter, db: db, dbType: config.Type, database: database}, release, nil
+}
+
+// buildMigrationPlan 读取源表结构并映射到目标数据库的类型
+// 目标表已存在时使用已有列的类型，源表中多出的列在计划中的目标类型为空。
+func buildMigrationPlan(src, dst *migrationEndpoint, opts *model.MigrationOptions) (*MigrationPlan, error) {
+	schemas := make([]*model.TableSchema, len(opts.Tables))
+	var columns []model.ColumnInfo
+	for i, table := range opts.Tables {
+		schema, err := src.adapter.GetTableSchema(src.db, src.database, table)
+		if err != nil {
+			return nil, fmt.Errorf("获取表 %s 的结构失败: %w", table, err)
+		}
+		schemas[i] = schema
+		columns = append(columns, schema.Columns...)
+	}
+
+	mapping, err := mapMigrationTypes(src.dbType, dst.dbType, columns, opts.TypeChoices)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &MigrationPlan{SourceType: src.dbType, TargetType: dst.dbType, Mapping: mapping}
+	for i, table := range opts.Tables {
+		existing := targetColumns(dst, table)
+		tablePlan := model.MigrationTablePlan{Table: table, Exists: len(existing) > 0}
+		for _, col := range schemas[i].Columns {
+			targetType := mapping.Mapped[col.Type]
+			if tablePlan.Exists {
+				targetType = ""
+				if target := findColumn(existing, col.Name); target != nil {
+					targetType = target.Type
+				}
+			}
+			tablePlan.Columns = append(tablePlan.Columns, model.MigrationColumn{
+				Name:       col.Name,
+				SourceType: col.Type,
+				TargetType: targetType,
+				Nullable:   col.Nullable,
+			})
+		}
+		plan.Tables = append(plan.Tables, tablePlan)
+	}
+	return plan, nil
+}
+
+// mapMigrationTypes 映射源类型并应用用户选择，同类型数据库之间保持原类型
+func mapMigrationTypes(sourceType, targetType model.DatabaseType, columns []model.ColumnInfo, choices map[string]string) (*export.TypeMappingResult, error) {
+	// 没有映射规则的类型保持不变，同类型数据库之间不需要加载配置
+	mapper := &export.TypeMapper{}
+	if sourceType != targetType {
+		var err error
+		if mapper, err = export.LoadDefaultConfig(); err != nil {
+			return nil, fmt.Errorf("加载类型映射配置失败: %w", err)
+		}
+	}
+
+	result, err := mapper.MapTypes(sourceType, targetType, columns)
+	if err != nil {
+		return nil, err
+	}
+	if err := mapper.ApplyUserChoices(result, choices); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// targetColumns 获取目标表的列，表不存在时返回 nil
+// 部分数据库查询不存在的表会返回错误，同样视为不存在。
+func targetColumns(dst *migrationEndpoint, table string) []model.ColumnInfo {
+	schema, err := dst.adapter.GetTableSchema(dst.db, dst.database, table)
+	if err != nil || schema == nil {
+		return nil
+	}
+	return schema.Columns
+}
+
+// findColumn 按列名（不区分大小写）查找列
+func findColumn(columns []model.ColumnInfo, name string) *model.ColumnInfo {
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, name) {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// run 依次迁移未完成的表
+func (e *migrationEntry) run(ctx context.Context, src, dst *migrationEndpoint) {
+	status, err := e.execute(ctx, src, dst)
+
+	e.update(func(job *model.MigrationJob) {
+		now := time.Now()
+		job.Status = status
+		job.FinishedAt = &now
+		if err != nil {
+			job.Error = err.Error()
+		}
+	})
+}
+
+// execute 执行迁移并返回任务的最终状态，出错的表保留断点
+func (e *migrationEntry) execute(ctx context.Context, src, dst *migrationEndpoint) (model.MigrationStatus, error) {
+	for i, table := range e.snapshot().Tables {
+		if table.Status == model.MigrationCompleted {
+			continue
+		}
+
+		e.updateTable(i, func(t *model.MigrationTable) {
+			t.Status = model.MigrationRunning
+			t.Error = ""
+		})
+		err := e.copyTable(ctx, i, src, dst)
+
+		if ctx.Err() != nil {
+			e.updateTable(i, func(t *model.MigrationTable) { t.Status = model.MigrationCancelled })
+			return model.MigrationCancelled, nil
+		}
+		if err != nil {
+			e.updateTable(i, func(t *model.MigrationTable) {
+				t.Status = model.MigrationFailed
+				t.Error = err.Error()
+			})
+			return model.MigrationFailed, fmt.Errorf("表 %s: %w", table.Table, err)
+		}
+		e.updateTable(i, func(t *model.MigrationTable) { t.Status = model.MigrationCompleted })
+	}
+	return model.MigrationCompleted, nil
+}
+
+// migrationColumn 源列到目标列的绑定
+type migrationColumn struct {
+	source  int // 源行中的下标
+	target  string
+	convert func(v any) (any, error)
+}
+
+// copyTable 复制一个表：目标表不存在时按映射后的类型建表，分批写入并在完成后校验行数
+func (e *migrationEntry) copyTable(ctx context.Context, i int, src, dst *migrationEndpoint) error {
+	progress := e.snapshot().Tables[i]
+	name := progress.Table
+	inserter := dst.adapter.(adapter.BulkInserter)
+
+	existing := targetColumns(dst, name)
+	if len(existing) == 0 {
+		if !e.opts.CreateTables {
+			return fmt.Errorf("目标表不存在，可启用建表选项按映射后的类型建表")
+		}
+		schema, err := src.adapter.GetTableSchema(src.db, src.database, name)
+		if err != nil {
+			return err
+		}
+		defs := make([]model.ColumnDef, len(schema.Columns))
+		for n, col := range schema.Columns {
+			typ := e.mapped[col.Type]
+			if typ == "" {
+				typ = col.Type
+			}
+			defs[n] = model.ColumnDef{Name: col.Name, Type: typ, Nullable: col.Nullable}
+			existing = append(existing, model.ColumnInfo{Name: col.Name, Type: typ})
+		}
+		if err := inserter.CreateTable(ctx, dst.db, dst.database, name, defs); err != nil {
+			return fmt.Errorf("建表失败: %w", err)
+		}
+		e.updateTable(i, func(t *model.MigrationTable) { t.Created = true })
+	}
+
+	// 尚未写入任何行时记录目标表原有的行数与源表行数，用于进度与校验
+	srcCounter, srcOK := src.adapter.(adapter.RowCounter)
+	dstCounter, dstOK := dst.adapter.(adapter.RowCounter)
+	if srcOK {
+		n, err := srcCounter.CountRows(ctx, src.db, src.database, name)
+		if err != nil {
+			return err
+		}
+		e.updateTable(i, func(t *model.MigrationTable) { t.SourceRows = n })
+	}
+	if dstOK && progress.Copied == 0 {
+		n, err := dstCounter.CountRows(ctx, dst.db, dst.database, name)
+		if err != nil {
+			return err
+		}
+		e.updateTable(i, func(t *model.MigrationTable) { t.BaseRows = n })
+	}
+
+	streamer := src.adapter.(adapter.RowStreamer)
+	err := streamer.StreamTable(ctx, src.db, src.database, name, 0, func(rows export.RowIterator) error {
+		var (
+			bindings []migrationColumn
+			columns  []string
+			ignored  []string
+		)
+		for n, col := range rows.Columns() {
+			target := findColumn(existing, col)
+			if target == nil {
+				ignored = append(ignored, col)
+				continue
+			}
+			bindings = append(bindings, migrationColumn{source: n, target: target.Name, convert: importer.NewValueConverter(target.Type)})
+			columns = append(columns, target.Name)
+		}
+		if len(bindings) == 0 {
+			return fmt.Errorf("目标表中没有与源表同名的列")
+		}
+		e.updateTable(i, func(t *model.MigrationTable) { t.Ignored = ignored })
+
+		batch := make([][]any, 0, e.opts.BatchSize)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			n, err := inserter.InsertRows(ctx, dst.db, dst.database, name, columns, batch)
+			e.updateTable(i, func(t *model.MigrationTable) { t.Copied += n })
+			batch = batch[:0]
+			return err
+		}
+
+		// 跳过断点之前已写入的行
+		var line int64
+		for rows.Next() {
+			line++
+			if line <= progress.Copied {
+				continue
+			}
+			values := rows.Values()
+			row := make([]any, len(bindings))
+			for n, b := range bindings {
+				v, err := b.convert(values[b.source])
+				if err != nil {
+					return fmt.Errorf("第 %d 行 %s 列: %v", line, b.target, err)
+				}
+				row[n] = v
+			}
+			batch = append(batch, row)
+			if len(batch) >= e.opts.BatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	if srcOK && dstOK {
+		n, err := dstCounter.CountRows(ctx, dst.db, dst.database, name)
+		if err != nil {
+			return err
+		}
+		e.updateTable(i, func(t *model.MigrationTable) {
+			t.TargetRows = n
+			t.Verified = n-t.BaseRows == t.SourceRows && t.Copied == t.SourceRows
+			if !t.Verified {
+				t.Error = fmt.Sprintf("行数校验不一致：源表 %d 行，目标表新增 %d 行", t.SourceRows, n-t.BaseRows)
+			}
+		})
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"path/filepath"
+	"testing"
+)
+
+// openMigrationDB 打开测试用的 SQLite 迁移端点
+func openMigrationDB(t *testing.T, name string, statements ...string) (*migrationEndpoint, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &migrationEndpoint{adapter: adapter.NewSQLiteAdapter(), db: db, dbType: model.DatabaseSQLite}, db
+}
+
+// newMigrationEntry 创建未登记到管理器的迁移任务
+func newMigrationEntry(t *testing.T, src, dst *migrationEndpoint, opts model.MigrationOptions) *migrationEntry {
+	t.Helper()
+
+	plan, err := buildMigrationPlan(src, dst, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &migrationEntry{job: model.MigrationJob{Status: model.MigrationRunning}, opts: opts, mapped: plan.Mapping.Mapped}
+	for _, table := range opts.Tables {
+		e.job.Tables = append(e.job.Tables, model.MigrationTable{Table: table, Status: model.MigrationPending})
+	}
+	return e
+}
+
+func TestMigration_CreateAndVerify(t *testing.T) {
+	src, _ := openMigrationDB(t, "src.db",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, score REAL)",
+		"INSERT INTO users VALUES (1, 'a', 1.5), (2, 'b', NULL), (3, 'c', 3)",
+	)
+	dst, dstDB := openMigrationDB(t, "dst.db")
+
+	e := newMigrationEntry(t, src, dst, model.MigrationOptions{Tables: []string{"users"}, CreateTables: true, BatchSize: 2})
+	e.run(context.Background(), src, dst)
+
+	job := e.snapshot()
+	table := job.Tables[0]
+	if job.Status != model.MigrationCompleted || job.FinishedAt == nil {
+		t.Fatalf("status %s, error %q", job.Status, job.Error)
+	}
+	if !table.Created || !table.Verified || table.SourceRows != 3 || table.Copied != 3 || table.TargetRows != 3 {
+		t.Fatalf("unexpected table progress: %+v", table)
+	}
+
+	var name string
+	if err := dstDB.QueryRow("SELECT name FROM users WHERE id = 3").Scan(&name); err != nil || name != "c" {
+		t.Errorf("row 3 = %q, %v", name, err)
+	}
+}
+
+func TestMigration_ResumeFromCheckpoint(t *testing.T) {
+	src, _ := openMigrationDB(t, "src.db",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, label TEXT, extra TEXT)",
+		"INSERT INTO items VALUES (1, 'a', 'x'), (2, 'b', 'y'), (3, 'c', 'z')",
+	)
+	// 目标表缺少 extra 列，且已写入断点之前的行
+	dst, dstDB := openMigrationDB(t, "dst.db",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, LABEL TEXT)",
+		"INSERT INTO items VALUES (1, 'a')",
+	)
+
+	e := newMigrationEntry(t, src, dst, model.MigrationOptions{Tables: []string{"items"}})
+	e.job.Status = model.MigrationFailed
+	e.job.Tables[0].Status = model.MigrationFailed
+	e.job.Tables[0].Copied = 1
+	e.run(context.Background(), src, dst)
+
+	job := e.snapshot()
+	table := job.Tables[0]
+	if job.Status != model.MigrationCompleted || table.Created || table.Copied != 3 || !table.Verified {
+		t.Fatalf("status %s, table %+v", job.Status, table)
+	}
+	if len(table.Ignored) != 1 || table.Ignored[0] != "extra" {
+		t.Errorf("Ignored = %v", table.Ignored)
+	}
+
+	var count int
+	if err := dstDB.QueryRow("SELECT COUNT(*) FROM items").Scan(&count); err != nil || count != 3 {
+		t.Errorf("count = %d, %v", count, err)
+	}
+}
+
+func TestMigration_MissingTable(t *testing.T) {
+	src, _ := openMigrationDB(t, "src.db", "CREATE TABLE logs (id INTEGER)")
+	dst, _ := openMigrationDB(t, "dst.db")
+
+	e := newMigrationEntry(t, src, dst, model.MigrationOptions{Tables: []string{"logs"}})
+	e.run(context.Background(), src, dst)
+
+	job := e.snapshot()
+	if job.Status != model.MigrationFailed || job.Tables[0].Status != model.MigrationFailed || job.Error == "" {
+		t.Fatalf("status %s, error %q", job.Status, job.Error)
+	}
+}
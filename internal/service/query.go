@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrQueryIDInUse 客户端指定的查询 ID 与运行中的查询重复
+var ErrQueryIDInUse = errors.New("查询 ID 已被运行中的查询使用")
+
+// RunningQuery 运行中的查询
+type RunningQuery struct {
+	ID           string    `json:"id"`
+	ConnectionID string    `json:"connectionId"`
+	UserID       string    `json:"userId"` // 发起查询的用户
+	Query        string    `json:"query"`
+	StartedAt    time.Time `json:"startedAt"`
+
+	cancel context.CancelFunc
+}
+
+// QueryRegistry 运行中查询登记表，用于按 ID 取消查询
+type QueryRegistry struct {
+	mu      sync.Mutex
+	queries map[string]*RunningQuery // key: queryID
+}
+
+// NewQueryRegistry 创建查询登记表
+func NewQueryRegistry() *QueryRegistry {
+	return &QueryRegistry{
+		queries: make(map[string]*RunningQuery),
+	}
+}
+
+// Register 登记 userID 发起的查询并返回可取消的上下文
+// queryID 为空时自动生成，与运行中的查询重复时返回 ErrQueryIDInUse；
+// 返回的 done 必须在查询结束后调用以释放登记项。
+func (r *QueryRegistry) Register(parent context.Context, connectionID, userID, queryID, query string) (context.Context, string, func(), error) {
+	if queryID == "" {
+		queryID = uuid.New().String()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	rq := &RunningQuery{
+		ID:           queryID,
+		ConnectionID: connectionID,
+		UserID:       userID,
+		Query:        query,
+		StartedAt:    time.Now(),
+		cancel:       cancel,
+	}
+
+	r.mu.Lock()
+	if _, exists := r.queries[queryID]; exists {
+		r.mu.Unlock()
+		cancel()
+		return nil, "", nil, ErrQueryIDInUse
+	}
+	r.queries[queryID] = rq
+	r.mu.Unlock()
+
+	done := func() {
+		r.mu.Lock()
+		if r.queries[queryID] == rq {
+			delete(r.queries, queryID)
+		}
+		r.mu.Unlock()
+		cancel()
+	}
+
+	return ctx, queryID, done, nil
+}
+
+// visible 查询是否属于 connectionID 连接与 userID 用户，userID 为空时不限制用户（管理员）
+func (rq *RunningQuery) visible(connectionID, userID string) bool {
+	return rq.ConnectionID == connectionID && (userID == "" || rq.UserID == userID)
+}
+
+// Cancel 取消指定连接下 userID 的查询，查询不存在或不属于 userID 时返回 false
+// userID 为空时不限制用户。
+func (r *QueryRegistry) Cancel(connectionID, queryID, userID string) bool {
+	r.mu.Lock()
+	rq, exists := r.queries[queryID]
+	r.mu.Unlock()
+
+	if !exists || !rq.visible(connectionID, userID) {
+		return false
+	}
+
+	rq.cancel()
+	return true
+}
+
+// List 获取指定连接下 userID 运行中的查询，userID 为空时返回所有用户的查询
+func (r *QueryRegistry) List(connectionID, userID string) []RunningQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queries := make([]RunningQuery, 0)
+	for _, rq := range r.queries {
+		if rq.visible(connectionID, userID) {
+			queries = append(queries, *rq)
+		}
+	}
+	return queries
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryRegistry_Cancel(t *testing.T) {
+	r := NewQueryRegistry()
+
+	ctx, queryID, done, err := r.Register(context.Background(), "conn-1", "u1", "q-1", "SELECT SLEEP(100)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	if queryID != "q-1" {
+		t.Fatalf("expected query id q-1, got %s", queryID)
+	}
+	if got := len(r.List("conn-1", "u1")); got != 1 {
+		t.Fatalf("expected 1 running query, got %d", got)
+	}
+
+	// 其他连接不能取消该查询
+	if r.Cancel("conn-2", "q-1", "u1") {
+		t.Fatal("cancel from another connection should fail")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("context should not be cancelled yet")
+	}
+
+	if !r.Cancel("conn-1", "q-1", "u1") {
+		t.Fatal("cancel should succeed")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", ctx.Err())
+	}
+}
+
+func TestQueryRegistry_DoneRemovesQuery(t *testing.T) {
+	r := NewQueryRegistry()
+
+	_, queryID, done, err := r.Register(context.Background(), "conn-1", "u1", "", "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queryID == "" {
+		t.Fatal("query id should be generated")
+	}
+
+	done()
+
+	if got := len(r.List("conn-1", "u1")); got != 0 {
+		t.Fatalf("expected no running queries, got %d", got)
+	}
+	if r.Cancel("conn-1", queryID, "u1") {
+		t.Fatal("finished query should not be cancellable")
+	}
+}
+
+func TestQueryRegistry_DuplicateID(t *testing.T) {
+	r := NewQueryRegistry()
+
+	ctx, _, done, err := r.Register(context.Background(), "conn-1", "u1", "q-1", "SELECT SLEEP(100)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	if _, _, _, err := r.Register(context.Background(), "conn-1", "u1", "q-1", "SELECT 2"); err != ErrQueryIDInUse {
+		t.Fatalf("expected ErrQueryIDInUse, got %v", err)
+	}
+
+	// 先登记的查询仍可取消
+	if !r.Cancel("conn-1", "q-1", "u1") {
+		t.Fatal("cancel should succeed")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", ctx.Err())
+	}
+}
+
+func TestQueryRegistry_Owner(t *testing.T) {
+	r := NewQueryRegistry()
+
+	ctx, _, done, err := r.Register(context.Background(), "conn-1", "u1", "q-1", "SELECT SLEEP(100)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+	_, _, done2, err := r.Register(context.Background(), "conn-1", "u2", "q-2", "SELECT 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done2()
+
+	if got := r.List("conn-1", "u2"); len(got) != 1 || got[0].ID != "q-2" {
+		t.Fatalf("expected only q-2 for u2, got %v", got)
+	}
+	// userID 为空（管理员）时不限制用户
+	if got := len(r.List("conn-1", "")); got != 2 {
+		t.Fatalf("expected 2 running queries for admin, got %d", got)
+	}
+
+	// 其他用户不能取消该查询
+	if r.Cancel("conn-1", "q-1", "u2") {
+		t.Fatal("cancel by another user should fail")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("context should not be cancelled yet")
+	}
+
+	if !r.Cancel("conn-1", "q-1", "") {
+		t.Fatal("cancel by admin should succeed")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", ctx.Err())
+	}
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRestoreErrors 每个任务保留的失败语句明细条数
+	maxRestoreErrors = 100
+	// maxRestoreStatementLen 失败明细中语句文本的最大长度
+	maxRestoreStatementLen = 1000
+	// restoreRetention 已结束任务的保留时间
+	restoreRetention = time.Hour
+	// restoreSavepoint 事务中跳过出错语句时使用的保存点
+	restoreSavepoint = "dbm_restore"
+)
+
+var (
+	// ErrRestoreNotFound 导入任务不存在或已过期
+	ErrRestoreNotFound = errors.New("导入任务不存在或已过期")
+	// ErrRestoreUnsupported 数据库类型不支持 SQL 文件导入
+	ErrRestoreUnsupported = errors.New("该数据库类型不支持 SQL 文件导入")
+	// ErrRestoreTransactionUnsupported 数据库不支持事务性 DDL，无法整体在事务中导入
+	ErrRestoreTransactionUnsupported = errors.New("该数据库的 DDL 会隐式提交事务，不支持在单个事务中导入")
+)
+
+// transactionalDDL 支持事务性 DDL 的数据库
+// savepoint 表示语句出错后事务进入中止状态，跳过出错语句时需要回滚到保存点。
+var transactionalDDL = map[model.DatabaseType]struct{ savepoint bool }{
+	model.DatabasePostgreSQL: {savepoint: true},
+	model.DatabaseKingBase:   {savepoint: true},
+	model.DatabaseMSSQL:      {},
+	model.DatabaseSQLite:     {},
+}
+
+// restoreEntry 导入任务登记项
+type restoreEntry struct {
+	mu     sync.Mutex
+	job    model.RestoreJob
+	cancel context.CancelFunc
+}
+
+// snapshot 返回任务进度的副本
+func (e *restoreEntry) snapshot() model.RestoreJob {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	job := e.job
+	job.Errors = append([]model.RestoreError(nil), e.job.Errors...)
+	return job
+}
+
+// update 在锁内修改任务进度
+func (e *restoreEntry) update(fn func(job *model.RestoreJob)) {
+	e.mu.Lock()
+	fn(&e.job)
+	e.mu.Unlock()
+}
+
+// RestoreManager SQL 文件导入任务管理器
+// 任务在后台执行，通过 ID 查询进度或取消；已结束的任务保留 restoreRetention 后清除。
+type RestoreManager struct {
+	mu            sync.Mutex
+	jobs          map[string]*restoreEntry // key: jobID
+	connectionSvc *ConnectionService
+}
+
+// NewRestoreManager 创建导入任务管理器
+func NewRestoreManager(connectionSvc *ConnectionService) *RestoreManager {
+	return &RestoreManager{
+		jobs:          make(map[string]*restoreEntry),
+		connectionSvc: connectionSvc,
+	}
+}
+
+// Start 创建导入任务并在后台执行
+// file 的所有权转移给任务，任务结束后关闭并删除；返回错误时由调用方负责清理。
+// done 不为 nil 时在任务结束后以最终状态调用。
+func (m *RestoreManager) Start(connectionID, fileName string, file *os.File, opts *model.RestoreOptions, done func(job model.RestoreJob)) (model.RestoreJob, error) {
+	db, config, release, err := m.connectionSvc.GetDB(connectionID, opts.Database)
+	if err != nil {
+		return model.RestoreJob{}, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			release()
+		}
+	}()
+
+	if opts.Transaction {
+		if _, ok := transactionalDDL[config.Type]; !ok {
+			return model.RestoreJob{}, ErrRestoreTransactionUnsupported
+		}
+	}
+
+	dbAdapter, err := m.connectionSvc.factory.CreateAdapter(config.Type)
+	if err != nil {
+		return model.RestoreJob{}, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return model.RestoreJob{}, err
+	}
+
+	// 导入过程中的 SET、USE 等语句需要在同一连接上生效
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := adapter.NewSession(ctx, db)
+	if err != nil {
+		cancel()
+		if err == adapter.ErrSessionUnsupported {
+			return model.RestoreJob{}, ErrRestoreUnsupported
+		}
+		return model.RestoreJob{}, err
+	}
+
+	e := &restoreEntry{
+		job: model.RestoreJob{
+			ID:           uuid.New().String(),
+			ConnectionID: connectionID,
+			Database:     opts.Database,
+			FileName:     fileName,
+			Status:       model.RestoreRunning,
+			Transaction:  opts.Transaction,
+			TotalBytes:   info.Size(),
+			StartedAt:    time.Now(),
+		},
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[e.job.ID] = e
+	m.mu.Unlock()
+
+	started = true
+	go func() {
+		defer release()
+		defer cancel()
+		defer os.Remove(file.Name())
+		defer file.Close()
+		defer session.Close()
+
+		e.run(ctx, dbAdapter, session, config.Type, file, opts)
+		if done != nil {
+			done(e.snapshot())
+		}
+	}()
+
+	return e.snapshot(), nil
+}
+
+// Get 获取导入任务进度
+func (m *RestoreManager) Get(connectionID, jobID string) (model.RestoreJob, error) {
+	m.mu.Lock()
+	e, exists := m.jobs[jobID]
+	m.mu.Unlock()
+
+	if !exists || e.job.ConnectionID != connectionID {
+		return model.RestoreJob{}, ErrRestoreNotFound
+	}
+	return e.snapshot(), nil
+}
+
+// List 获取连接下的导入任务
+func (m *RestoreManager) List(connectionID string) []model.RestoreJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	jobs := make([]model.RestoreJob, 0)
+	for _, e := range m.jobs {
+		if e.job.ConnectionID == connectionID {
+			jobs = append(jobs, e.snapshot())
+		}
+	}
+	return jobs
+}
+
+// Cancel 取消导入任务，任务不存在时返回 false
+// 在事务中导入时已执行的语句全部回滚，否则保留已执行的部分。
+func (m *RestoreManager) Cancel(connectionID, jobID string) bool {
+	m.mu.Lock()
+	e, exists := m.jobs[jobID]
+	m.mu.Unlock()
+
+	if !exists || e.job.ConnectionID != connectionID {
+		return false
+	}
+
+	e.cancel()
+	return true
+}
+
+// Shutdown 取消所有进行中的导入任务
+func (m *RestoreManager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.jobs {
+		e.cancel()
+	}
+}
+
+// prune 清除超过保留时间的已结束任务，调用方需持有 m.mu
+func (m *RestoreManager) prune() {
+	for id, e := range m.jobs {
+		job := e.snapshot()
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > restoreRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// run 读取 SQL 文件并逐条执行，gzip 压缩的文件自动解压
+func (e *restoreEntry) run(ctx context.Context, dbAdapter adapter.DatabaseAdapter, session *adapter.Session, dbType model.DatabaseType, src io.Reader, opts *model.RestoreOptions) {
+	counter := &countingReader{r: src}
+	status, err := e.execute(ctx, dbAdapter, session, dbType, counter, opts)
+
+	e.update(func(job *model.RestoreJob) {
+		now := time.Now()
+		job.Status = status
+		job.ReadBytes = counter.n
+		job.FinishedAt = &now
+		if err != nil {
+			job.Error = err.Error()
+		}
+	})
+}
+
+// execute 执行导入并返回任务的最终状态
+func (e *restoreEntry) execute(ctx context.Context, dbAdapter adapter.DatabaseAdapter, session *adapter.Session, dbType model.DatabaseType, counter *countingReader, opts *model.RestoreOptions) (model.RestoreStatus, error) {
+	reader, err := decompressReader(counter)
+	if err != nil {
+		return model.RestoreFailed, err
+	}
+
+	savepoint := false
+	if opts.Transaction {
+		if err := session.Begin(); err != nil {
+			return model.RestoreFailed, err
+		}
+		savepoint = opts.ContinueOnError && transactionalDDL[dbType].savepoint
+	}
+
+	exec := func(query string) error {
+		_, err := dbAdapter.ExecuteContext(ctx, session, query)
+		return err
+	}
+
+	scanner := adapter.NewStatementScanner(reader, dbType)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		stmt := scanner.Statement()
+		e.update(func(job *model.RestoreJob) {
+			job.Line = stmt.Line
+			job.ReadBytes = counter.n
+		})
+
+		handled, err := restoreControl(session, stmt.SQL, opts.Transaction)
+		if !handled {
+			if savepoint {
+				err = execSavepoint(stmt.SQL, exec)
+			} else {
+				err = exec(stmt.SQL)
+			}
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			e.update(func(job *model.RestoreJob) {
+				job.Statements++
+				job.Failed++
+				if len(job.Errors) < maxRestoreErrors {
+					job.Errors = append(job.Errors, model.RestoreError{
+						Line:      stmt.Line,
+						Statement: truncateStatement(stmt.SQL),
+						Error:     err.Error(),
+					})
+				}
+			})
+			if !opts.ContinueOnError {
+				if opts.Transaction {
+					_ = session.Rollback()
+				}
+				return model.RestoreFailed, fmt.Errorf("第 %d 行：%v", stmt.Line, err)
+			}
+			continue
+		}
+
+		e.update(func(job *model.RestoreJob) { job.Statements++ })
+	}
+
+	if ctx.Err() != nil {
+		if opts.Transaction {
+			_ = session.Rollback()
+		}
+		return model.RestoreCancelled, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		if opts.Transaction {
+			_ = session.Rollback()
+		}
+		return model.RestoreFailed, err
+	}
+
+	if opts.Transaction {
+		if err := session.Commit(); err != nil {
+			return model.RestoreFailed, err
+		}
+	}
+	return model.RestoreCompleted, nil
+}
+
+// restoreControl 处理文件中的 BEGIN、COMMIT、ROLLBACK
+// 整体在事务中导入时忽略文件自身的事务控制，避免提前提交；否则按会话事务执行。
+func restoreControl(session *adapter.Session, query string, inTransaction bool) (handled bool, err error) {
+	control := adapter.TransactionControl(query)
+	if control == "" {
+		return false, nil
+	}
+	if inTransaction {
+		return true, nil
+	}
+
+	switch control {
+	case "BEGIN":
+		return true, session.Begin()
+	case "COMMIT":
+		return true, session.Commit()
+	default:
+		return true, session.Rollback()
+	}
+}
+
+// execSavepoint 在保存点内执行语句，出错时回滚到保存点使事务可以继续
+func execSavepoint(query string, exec func(query string) error) error {
+	if err := exec("SAVEPOINT " + restoreSavepoint); err != nil {
+		return err
+	}
+	if err := exec(query); err != nil {
+		if rbErr := exec("ROLLBACK TO SAVEPOINT " + restoreSavepoint); rbErr != nil {
+			return fmt.Errorf("%v（回滚到保存点失败: %v）", err, rbErr)
+		}
+		return err
+	}
+	return exec("RELEASE SAVEPOINT " + restoreSavepoint)
+}
+
+// decompressReader 按文件头识别 gzip 压缩并返回解压后的读取器
+func decompressReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// truncateStatement 截断过长的语句文本
+func truncateStatement(query string) string {
+	runes := []rune(query)
+	if len(runes) <= maxRestoreStatementLen {
+		return query
+	}
+	return string(runes[:maxRestoreStatementLen]) + "..."
+}
+
+// countingReader 统计已读取字节数的读取器
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const restoreDump = `-- 示例导出文件
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+INSERT INTO users VALUES (1, 'a;b');
+INSERT INTO users VALUES (1, 'duplicate');
+INSERT INTO users VALUES (2, 'c');
+`
+
+// runRestore 在新的 SQLite 数据库上执行导入，返回任务进度与用户数
+func runRestore(t *testing.T, data []byte, opts *model.RestoreOptions) (model.RestoreJob, int) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "restore.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	session, err := adapter.NewSession(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &restoreEntry{job: model.RestoreJob{TotalBytes: int64(len(data))}}
+	e.run(context.Background(), adapter.NewSQLiteAdapter(), session, model.DatabaseSQLite, bytes.NewReader(data), opts)
+	_ = session.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil && !strings.Contains(err.Error(), "no such table") {
+		t.Fatal(err)
+	}
+	return e.snapshot(), count
+}
+
+func TestRestore_StopOnError(t *testing.T) {
+	job, count := runRestore(t, []byte(restoreDump), &model.RestoreOptions{})
+	if job.Status != model.RestoreFailed || job.Statements != 3 || job.Failed != 1 || count != 1 {
+		t.Fatalf("status %s, statements %d, failed %d, rows %d", job.Status, job.Statements, job.Failed, count)
+	}
+	if job.Errors[0].Line != 4 || job.FinishedAt == nil {
+		t.Errorf("unexpected job: %+v", job)
+	}
+}
+
+func TestRestore_ContinueOnErrorGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(restoreDump))
+	_ = zw.Close()
+
+	job, count := runRestore(t, buf.Bytes(), &model.RestoreOptions{ContinueOnError: true})
+	if job.Status != model.RestoreCompleted || job.Statements != 4 || job.Failed != 1 || count != 2 {
+		t.Fatalf("status %s, statements %d, failed %d, rows %d", job.Status, job.Statements, job.Failed, count)
+	}
+	if job.ReadBytes != int64(buf.Len()) {
+		t.Errorf("ReadBytes = %d, want %d", job.ReadBytes, buf.Len())
+	}
+}
+
+func TestRestore_TransactionRollback(t *testing.T) {
+	// 文件自身的 BEGIN/COMMIT 被忽略，失败时整体回滚
+	dump := "BEGIN TRANSACTION;\n" + restoreDump + "COMMIT;\n"
+	job, count := runRestore(t, []byte(dump), &model.RestoreOptions{Transaction: true})
+	if job.Status != model.RestoreFailed || count != 0 {
+		t.Fatalf("status %s, rows %d, want failed with table rolled back", job.Status, count)
+	}
+
+	job, count = runRestore(t, []byte(dump), &model.RestoreOptions{Transaction: true, ContinueOnError: true})
+	if job.Status != model.RestoreCompleted || count != 2 {
+		t.Fatalf("status %s, rows %d, want completed with 2 rows", job.Status, count)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"time"
+)
+
+// RunScript 按方言拆分脚本并依次执行每条语句
+// 指定 sessionID 时在 userID 的该会话中执行（userID 为空时不限制用户）；否则为本次脚本创建临时会话，
+// 脚本内的会话变量与事务只在脚本执行期间有效，未提交的事务在结束时回滚。
+func (m *SessionManager) RunScript(ctx context.Context, connectionID, sessionID, userID, script string, opts *model.ScriptOptions, queryOpts *model.QueryOptions) (*model.ScriptResult, error) {
+	if sessionID == "" {
+		session, err := m.Create(ctx, connectionID, opts.Database, userID)
+		if err == adapter.ErrSessionUnsupported {
+			return m.runScriptOnPool(ctx, connectionID, script, opts, queryOpts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer m.Close(connectionID, session.ID, "")
+		sessionID = session.ID
+	}
+
+	s, release, err := m.acquire(connectionID, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	statements := adapter.SplitStatements(s.dbType, script)
+	return runStatements(ctx, statements, opts.ContinueOnError, func(query string) (*model.QueryResult, error) {
+		start := time.Now()
+		if message, handled, err := s.control(query); handled {
+			if err != nil {
+				return nil, err
+			}
+			return &model.QueryResult{Message: message, TimeCost: time.Since(start)}, nil
+		}
+		return s.adapter.QueryContext(ctx, s.session, query, queryOpts)
+	}), nil
+}
+
+// runScriptOnPool 在连接池上执行脚本（用于不支持会话的数据库）
+func (m *SessionManager) runScriptOnPool(ctx context.Context, connectionID, script string, opts *model.ScriptOptions, queryOpts *model.QueryOptions) (*model.ScriptResult, error) {
+	db, config, release, err := m.connectionSvc.GetDB(connectionID, opts.Database)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	dbAdapter, err := m.connectionSvc.factory.CreateAdapter(config.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := adapter.SplitStatements(config.Type, script)
+	return runStatements(ctx, statements, opts.ContinueOnError, func(query string) (*model.QueryResult, error) {
+		return dbAdapter.QueryContext(ctx, db, query, queryOpts)
+	}), nil
+}
+
+// runStatements 依次执行语句并汇总结果
+// 出错且未设置 continueOnError 或 ctx 被取消后，剩余语句标记为跳过。
+func runStatements(ctx context.Context, statements []adapter.Statement, continueOnError bool, exec func(query string) (*model.QueryResult, error)) *model.ScriptResult {
+	start := time.Now()
+	result := &model.ScriptResult{
+		Statements: make([]model.StatementResult, 0, len(statements)),
+	}
+
+	stopped := false
+	for i, stmt := range statements {
+		item := model.StatementResult{
+			Index:     i,
+			Line:      stmt.Line,
+			Statement: stmt.SQL,
+		}
+
+		if stopped || ctx.Err() != nil {
+			item.Status = model.StatementSkipped
+			result.Skipped++
+			result.Statements = append(result.Statements, item)
+			continue
+		}
+
+		res, err := exec(stmt.SQL)
+		if err != nil {
+			item.Status = model.StatementError
+			item.Error = err.Error()
+			result.Failed++
+			stopped = !continueOnError
+		} else {
+			item.Status = model.StatementSuccess
+			item.Result = res
+			result.Succeeded++
+		}
+		result.Statements = append(result.Statements, item)
+	}
+
+	result.TimeCost = time.Since(start)
+	return result
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"errors"
+	"testing"
+)
+
+func TestRunStatements_StopOnError(t *testing.T) {
+	statements := []adapter.Statement{{SQL: "ok 1"}, {SQL: "fail"}, {SQL: "ok 2"}}
+	exec := func(query string) (*model.QueryResult, error) {
+		if query == "fail" {
+			return nil, errors.New("syntax error")
+		}
+		return &model.QueryResult{Message: query}, nil
+	}
+
+	result := runStatements(context.Background(), statements, false, exec)
+	if result.Succeeded != 1 || result.Failed != 1 || result.Skipped != 1 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+	if result.Statements[1].Error != "syntax error" || result.Statements[2].Status != model.StatementSkipped {
+		t.Errorf("unexpected statements: %+v", result.Statements)
+	}
+
+	result = runStatements(context.Background(), statements, true, exec)
+	if result.Succeeded != 2 || result.Failed != 1 || result.Skipped != 0 {
+		t.Errorf("continueOnError counts: %+v", result)
+	}
+}
+
+func TestRunStatements_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	statements := []adapter.Statement{{SQL: "first"}, {SQL: "second"}}
+
+	result := runStatements(ctx, statements, true, func(query string) (*model.QueryResult, error) {
+		cancel()
+		return &model.QueryResult{}, nil
+	})
+	if result.Succeeded != 1 || result.Skipped != 1 {
+		t.Errorf("unexpected counts after cancel: %+v", result)
+	}
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSessionNotFound 会话不存在或已过期
+	ErrSessionNotFound = errors.New("会话不存在或已过期")
+	// ErrSessionBusy 会话上有正在执行的语句
+	ErrSessionBusy = errors.New("会话正在执行其他语句，请等待完成或取消后重试")
+)
+
+// Session SQL 会话
+// 会话固定使用一条数据库连接，跨请求保持事务与会话变量。
+type Session struct {
+	ID            string    `json:"id"`
+	ConnectionID  string    `json:"connectionId"`
+	UserID        string    `json:"userId"` // 创建会话的用户
+	Database      string    `json:"database"`
+	CreatedAt     time.Time `json:"createdAt"`
+	LastUsedAt    time.Time `json:"lastUsedAt"`
+	InTransaction bool      `json:"inTransaction"`
+}
+
+// sessionEntry 会话登记项
+type sessionEntry struct {
+	Session
+
+	// busy 会话上同一时间只执行一条语句
+	busy    sync.Mutex
+	dbType  model.DatabaseType
+	adapter adapter.DatabaseAdapter
+	session *adapter.Session
+	// releaseDB 释放对连接池的占用
+	releaseDB func()
+}
+
+// close 关闭会话连接并释放连接池占用
+func (s *sessionEntry) close() error {
+	defer s.releaseDB()
+	return s.session.Close()
+}
+
+// SessionManager SQL 会话管理器
+// 闲置超过 idleTimeout 的会话会被回滚并关闭，防止遗留事务长期持有锁。
+type SessionManager struct {
+	mu            sync.Mutex
+	sessions      map[string]*sessionEntry // key: sessionID
+	connectionSvc *ConnectionService
+	idleTimeout   time.Duration
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+// NewSessionManager 创建会话管理器
+func NewSessionManager(connectionSvc *ConnectionService, idleTimeout time.Duration) *SessionManager {
+	m := &SessionManager{
+		sessions:      make(map[string]*sessionEntry),
+		connectionSvc: connectionSvc,
+		idleTimeout:   idleTimeout,
+		stop:          make(chan struct{}),
+	}
+
+	if idleTimeout > 0 {
+		go m.evictLoop()
+	}
+
+	return m
+}
+
+// Create 为 userID 创建会话
+func (m *SessionManager) Create(ctx context.Context, connectionID, database, userID string) (Session, error) {
+	db, config, releaseDB, err := m.connectionSvc.GetDB(connectionID, database)
+	if err != nil {
+		return Session{}, err
+	}
+
+	dbAdapter, err := m.connectionSvc.factory.CreateAdapter(config.Type)
+	if err != nil {
+		releaseDB()
+		return Session{}, err
+	}
+
+	session, err := adapter.NewSession(ctx, db)
+	if err != nil {
+		releaseDB()
+		return Session{}, err
+	}
+
+	now := time.Now()
+	s := &sessionEntry{
+		Session: Session{
+			ID:           uuid.New().String(),
+			ConnectionID: connectionID,
+			UserID:       userID,
+			Database:     database,
+			CreatedAt:    now,
+			LastUsedAt:   now,
+		},
+		dbType:    config.Type,
+		adapter:   dbAdapter,
+		session:   session,
+		releaseDB: releaseDB,
+	}
+
+	m.mu.Lock()
+	m.sessions[s.ID] = s
+	m.mu.Unlock()
+
+	return s.Session, nil
+}
+
+// visible 会话是否属于 connectionID 连接与 userID 用户，userID 为空时不限制用户（管理员）
+func (s *sessionEntry) visible(connectionID, userID string) bool {
+	return s.ConnectionID == connectionID && (userID == "" || s.UserID == userID)
+}
+
+// acquire 获取会话并占用，返回的 release 必须在语句结束后调用
+// 会话不属于 userID 时视为不存在；会话上已有语句在执行时不等待，直接返回 ErrSessionBusy。
+func (m *SessionManager) acquire(connectionID, sessionID, userID string) (*sessionEntry, func(), error) {
+	m.mu.Lock()
+	s, exists := m.sessions[sessionID]
+	m.mu.Unlock()
+
+	if !exists || !s.visible(connectionID, userID) {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	if !s.busy.TryLock() {
+		return nil, nil, ErrSessionBusy
+	}
+
+	// 取得会话前会话可能已被关闭
+	m.mu.Lock()
+	_, exists = m.sessions[sessionID]
+	if exists {
+		s.LastUsedAt = time.Now()
+	}
+	m.mu.Unlock()
+	if !exists {
+		s.busy.Unlock()
+		return nil, nil, ErrSessionNotFound
+	}
+
+	release := func() {
+		m.mu.Lock()
+		s.LastUsedAt = time.Now()
+		s.InTransaction = s.session.InTransaction()
+		m.mu.Unlock()
+		s.busy.Unlock()
+	}
+	return s, release, nil
+}
+
+// Query 在会话中执行查询
+// 事务控制语句（BEGIN、COMMIT、ROLLBACK）通过会话的事务接口执行。
+func (m *SessionManager) Query(ctx context.Context, connectionID, sessionID, userID, query string, opts *model.QueryOptions) (*model.QueryResult, error) {
+	s, release, err := m.acquire(connectionID, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	if message, handled, err := s.control(query); handled {
+		if err != nil {
+			return nil, err
+		}
+		return &model.QueryResult{Message: message, TimeCost: time.Since(start)}, nil
+	}
+
+	return s.adapter.QueryContext(ctx, s.session, query, opts)
+}
+
+// Execute 在会话中执行非查询 SQL
+func (m *SessionManager) Execute(ctx context.Context, connectionID, sessionID, userID, query string) (*model.ExecuteResult, error) {
+	s, release, err := m.acquire(connectionID, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	if message, handled, err := s.control(query); handled {
+		if err != nil {
+			return nil, err
+		}
+		return &model.ExecuteResult{Message: message, TimeCost: time.Since(start)}, nil
+	}
+
+	return s.adapter.ExecuteContext(ctx, s.session, query)
+}
+
+// Commit 提交会话中的事务
+func (m *SessionManager) Commit(connectionID, sessionID, userID string) error {
+	s, release, err := m.acquire(connectionID, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.session.Commit()
+}
+
+// Rollback 回滚会话中的事务
+func (m *SessionManager) Rollback(connectionID, sessionID, userID string) error {
+	s, release, err := m.acquire(connectionID, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.session.Rollback()
+}
+
+// control 处理事务控制语句，非事务控制语句返回 handled = false
+func (s *sessionEntry) control(query string) (message string, handled bool, err error) {
+	switch adapter.TransactionControl(query) {
+	case "BEGIN":
+		return "事务已开启", true, s.session.Begin()
+	case "COMMIT":
+		return "事务已提交", true, s.session.Commit()
+	case "ROLLBACK":
+		return "事务已回滚", true, s.session.Rollback()
+	}
+	return "", false, nil
+}
+
+// Get 获取会话信息，userID 为空时不限制用户
+func (m *SessionManager) Get(connectionID, sessionID, userID string) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, exists := m.sessions[sessionID]
+	if !exists || !s.visible(connectionID, userID) {
+		return Session{}, ErrSessionNotFound
+	}
+	return s.Session, nil
+}
+
+// List 获取连接下 userID 的会话，userID 为空时返回所有用户的会话
+func (m *SessionManager) List(connectionID, userID string) []Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sessions := make([]Session, 0)
+	for _, s := range m.sessions {
+		if s.visible(connectionID, userID) {
+			sessions = append(sessions, s.Session)
+		}
+	}
+	return sessions
+}
+
+// Close 关闭会话，未提交的事务会被回滚；userID 为空时不限制用户
+func (m *SessionManager) Close(connectionID, sessionID, userID string) error {
+	m.mu.Lock()
+	s, exists := m.sessions[sessionID]
+	if !exists || !s.visible(connectionID, userID) {
+		m.mu.Unlock()
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, sessionID)
+	m.mu.Unlock()
+
+	return s.close()
+}
+
+// CloseConnection 关闭连接下的所有会话
+func (m *SessionManager) CloseConnection(connectionID string) {
+	m.mu.Lock()
+	var closing []*sessionEntry
+	for id, s := range m.sessions {
+		if s.ConnectionID == connectionID {
+			closing = append(closing, s)
+			delete(m.sessions, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, s := range closing {
+		if err := s.close(); err != nil {
+			log.Printf("关闭会话 %s 失败: %v", s.ID, err)
+		}
+	}
+}
+
+// Shutdown 停止回收并关闭所有会话
+func (m *SessionManager) Shutdown() {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[string]*sessionEntry)
+	m.mu.Unlock()
+
+	for _, s := range sessions {
+		_ = s.close()
+	}
+}
+
+// evictLoop 定期回收闲置会话
+func (m *SessionManager) evictLoop() {
+	interval := m.idleTimeout / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.evictIdle(time.Now())
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// evictIdle 回滚并关闭闲置超过 idleTimeout 且没有正在执行语句的会话，返回关闭数量
+func (m *SessionManager) evictIdle(now time.Time) int {
+	m.mu.Lock()
+	var evicted []*sessionEntry
+	for id, s := range m.sessions {
+		if now.Sub(s.LastUsedAt) < m.idleTimeout || !s.busy.TryLock() {
+			continue
+		}
+		s.busy.Unlock()
+		evicted = append(evicted, s)
+		delete(m.sessions, id)
+	}
+	m.mu.Unlock()
+
+	for _, s := range evicted {
+		if err := s.close(); err != nil {
+			log.Printf("关闭闲置会话 %s 失败: %v", s.ID, err)
+		}
+	}
+	return len(evicted)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"dbm/internal/adapter"
+	"dbm/internal/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// addTestSession 在管理器中为 userID 登记一个 SQLite 会话
+func addTestSession(t *testing.T, m *SessionManager, connectionID, userID string) *sessionEntry {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "session.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	session, err := adapter.NewSession(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	s := &sessionEntry{
+		Session: Session{
+			ID:           "s-" + connectionID + "-" + userID,
+			ConnectionID: connectionID,
+			UserID:       userID,
+			CreatedAt:    now,
+			LastUsedAt:   now,
+		},
+		dbType:    model.DatabaseSQLite,
+		adapter:   adapter.NewSQLiteAdapter(),
+		session:   session,
+		releaseDB: func() {},
+	}
+	m.sessions[s.ID] = s
+	t.Cleanup(func() { _ = m.Close(connectionID, s.ID, "") })
+	return s
+}
+
+func TestSessionManager_AcquireBusy(t *testing.T) {
+	m := NewSessionManager(nil, 0)
+	s := addTestSession(t, m, "conn1", "u1")
+
+	_, release, err := m.acquire("conn1", s.ID, "u1")
+	if err != nil {
+		t.Fatalf("acquire() failed: %v", err)
+	}
+
+	// 会话上已有语句在执行时立即返回，不排队等待
+	if _, _, err := m.acquire("conn1", s.ID, "u1"); err != ErrSessionBusy {
+		t.Errorf("acquire() while busy = %v, want ErrSessionBusy", err)
+	}
+
+	release()
+	_, release, err = m.acquire("conn1", s.ID, "u1")
+	if err != nil {
+		t.Fatalf("acquire() after release failed: %v", err)
+	}
+	release()
+
+	if _, _, err := m.acquire("conn2", s.ID, "u1"); err != ErrSessionNotFound {
+		t.Errorf("acquire() on other connection = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSessionManager_Owner(t *testing.T) {
+	m := NewSessionManager(nil, 0)
+	s := addTestSession(t, m, "conn1", "u1")
+	addTestSession(t, m, "conn1", "u2")
+
+	// 其他用户的会话视为不存在
+	if _, _, err := m.acquire("conn1", s.ID, "u2"); err != ErrSessionNotFound {
+		t.Errorf("acquire() by other user = %v, want ErrSessionNotFound", err)
+	}
+	if _, err := m.Get("conn1", s.ID, "u2"); err != ErrSessionNotFound {
+		t.Errorf("Get() by other user = %v, want ErrSessionNotFound", err)
+	}
+	if err := m.Close("conn1", s.ID, "u2"); err != ErrSessionNotFound {
+		t.Errorf("Close() by other user = %v, want ErrSessionNotFound", err)
+	}
+
+	if sessions := m.List("conn1", "u1"); len(sessions) != 1 || sessions[0].ID != s.ID {
+		t.Errorf("List() for owner = %v, want only %s", sessions, s.ID)
+	}
+	// userID 为空（管理员）时不限制用户
+	if sessions := m.List("conn1", ""); len(sessions) != 2 {
+		t.Errorf("List() for admin returned %d sessions, want 2", len(sessions))
+	}
+	if _, err := m.Get("conn1", s.ID, ""); err != nil {
+		t.Errorf("Get() by admin failed: %v", err)
+	}
+
+	_, release, err := m.acquire("conn1", s.ID, "u1")
+	if err != nil {
+		t.Fatalf("acquire() by owner failed: %v", err)
+	}
+	release()
+}
@@ -6,10 +6,30 @@ const request = axios.create({
   timeout: 30000
 })
 
+// CSRF 令牌：登录或获取当前用户时由服务端返回，写请求须在请求头中携带
+let csrfToken = ''
+
+export function setCSRFToken(token: string) {
+  csrfToken = token
+}
+
+request.interceptors.request.use((config) => {
+  if (csrfToken && config.method && !['get', 'head', 'options'].includes(config.method.toLowerCase())) {
+    config.headers.set('X-CSRF-Token', csrfToken)
+  }
+  return config
+})
+
 request.interceptors.response.use(
   (response) => response.data,
   (error) => {
     console.error('API Error:', error)
+    // 会话过期或未登录时回到登录页
+    const url: string = error.config?.url || ''
+    if (error.response?.status === 401 && !url.startsWith('/auth/') && window.location.pathname !== '/login') {
+      const redirect = encodeURIComponent(window.location.pathname + window.location.search)
+      window.location.href = `/login?redirect=${redirect}`
+    }
     return Promise.reject(error)
   }
 )
@@ -17,6 +37,38 @@ request.interceptors.response.use(
 export default request
 
 export const api = {
+  // 登录认证
+  login: (username: string, password: string) =>
+    request.post<any, ApiResponse<AuthState>>('/auth/login', { username, password }),
+  logout: () => request.post<any, ApiResponse<null>>('/auth/logout'),
+  getCurrentUser: () => request.get<any, ApiResponse<AuthState>>('/auth/me'),
+  changePassword: (currentPassword: string, newPassword: string) =>
+    request.put<any, ApiResponse<null>>('/auth/password', { currentPassword, newPassword }),
+
+  // 用户管理（管理员）
+  getUsers: () => request.get<any, ApiResponse<User[]>>('/users'),
+  createUser: (data: { username: string; password: string; role: UserRole }) =>
+    request.post<any, ApiResponse<User>>('/users', data),
+  updateUser: (id: string, data: { role?: UserRole; disabled?: boolean; password?: string }) =>
+    request.put<any, ApiResponse<User>>(`/users/${id}`, data),
+  deleteUser: (id: string) => request.delete<any, ApiResponse<null>>(`/users/${id}`),
+
+  // 角色与授权
+  getPermissions: () => request.get<any, ApiResponse<PermissionState>>('/auth/permissions'),
+  getRoles: () => request.get<any, ApiResponse<Role[]>>('/roles'),
+  createRole: (data: { name: string; description: string; permissions: Permission[] }) =>
+    request.post<any, ApiResponse<Role>>('/roles', data),
+  updateRole: (name: string, data: { description: string; permissions: Permission[] }) =>
+    request.put<any, ApiResponse<Role>>(`/roles/${encodeURIComponent(name)}`, data),
+  deleteRole: (name: string) => request.delete<any, ApiResponse<null>>(`/roles/${encodeURIComponent(name)}`),
+  getGrants: (userId?: string) => request.get<any, ApiResponse<Grant[]>>('/grants', { params: { userId } }),
+  createGrant: (data: { userId: string; connectionId?: string; groupId?: string; role: string }) =>
+    request.post<any, ApiResponse<Grant>>('/grants', data),
+  deleteGrant: (id: string) => request.delete<any, ApiResponse<null>>(`/grants/${id}`),
+
+  // 审计日志（管理员）
+  getAudit: (params: AuditQuery) => request.get<any, ApiResponse<AuditEntry[]>>('/audit', { params }),
+
   // 连接管理
   getConnections: () => request.get<any, ApiResponse<ConnectionConfig[]>>('/connections'),
   createConnection: (data: any) => request.post<any, ApiResponse<ConnectionConfig>>('/connections', data),
@@ -25,7 +77,9 @@ export const api = {
   testConnection: (id: string) => request.post<any, ApiResponse<any>>(`/connections/${id}/test`),
   connectConnection: (id: string) => request.post<any, ApiResponse<any>>(`/connections/${id}/connect`),
   closeConnection: (id: string) => request.post<any, ApiResponse<null>>(`/connections/${id}/close`),
+  getPoolStats: (id: string) => request.get<any, ApiResponse<PoolStats[]>>(`/connections/${id}/pool`),
   testConnectionConfig: (data: any) => request.post<any, ApiResponse<any>>('/connections/test', data),
+  getConnectionDetails: (id: string) => request.get<any, ApiResponse<ConnectionConfig>>(`/connections/${id}/details`),
 
   // 分组管理
   getGroups: () => request.get<any, ApiResponse<Group[]>>('/groups'),
@@ -53,10 +107,25 @@ export const api = {
     request.get<any, ApiResponse<string>>(`/connections/${id}/routines/${routine}/definition`, { params: { type, database, schema } }),
 
   // SQL 执行
-  executeQuery: (id: string, query: string, opts?: QueryOptions) =>
-    request.post<any, ApiResponse<QueryResult>>(`/connections/${id}/query`, { query, opts }),
-  executeNonQuery: (id: string, query: string) =>
-    request.post<any, ApiResponse<ExecuteResult>>(`/connections/${id}/execute`, { query }),
+  executeQuery: (id: string, query: string, opts?: QueryOptions, queryId?: string, sessionId?: string) =>
+    request.post<any, ApiResponse<QueryResult>>(`/connections/${id}/query`, { query, opts, queryId, sessionId }, { timeout: 0 }),
+  executeNonQuery: (id: string, query: string, queryId?: string, sessionId?: string) =>
+    request.post<any, ApiResponse<ExecuteResult>>(`/connections/${id}/execute`, { query, queryId, sessionId }, { timeout: 0 }),
+  executeScript: (id: string, script: string, opts?: ScriptOptions, queryId?: string, sessionId?: string) =>
+    request.post<any, ApiResponse<ScriptResult>>(`/connections/${id}/script`, { script, ...opts, queryId, sessionId }, { timeout: 0 }),
+  cancelQuery: (id: string, queryId: string) =>
+    request.post<any, ApiResponse<any>>(`/connections/${id}/queries/${queryId}/cancel`),
+
+  // SQL 会话（事务）
+  listSessions: (id: string) => request.get<any, ApiResponse<SqlSession[]>>(`/connections/${id}/sessions`),
+  createSession: (id: string, database?: string) =>
+    request.post<any, ApiResponse<SqlSession>>(`/connections/${id}/sessions`, { database }),
+  commitSession: (id: string, sessionId: string) =>
+    request.post<any, ApiResponse<any>>(`/connections/${id}/sessions/${sessionId}/commit`),
+  rollbackSession: (id: string, sessionId: string) =>
+    request.post<any, ApiResponse<any>>(`/connections/${id}/sessions/${sessionId}/rollback`),
+  closeSession: (id: string, sessionId: string) =>
+    request.delete<any, ApiResponse<any>>(`/connections/${id}/sessions/${sessionId}`),
 
   // 导出
   exportCSV: (id: string, params: { query: string; opts: CSVOptions; database?: string }) =>
@@ -69,16 +138,109 @@ export const api = {
       params: { database: params.database },
       responseType: 'blob'
     }),
+  exportData: (
+    id: string,
+    format: ExportFormat,
+    params: { query?: string; tables?: string[]; opts: JSONOptions | XLSXOptions | MarkdownOptions | HTMLOptions | ParquetOptions; database?: string }
+  ) =>
+    request.post(`/connections/${id}/export/${format}`, params, {
+      params: { database: params.database },
+      responseType: 'blob'
+    }),
   previewExportSQL: (id: string, params: { tables: string[]; targetDbType: DatabaseType }) =>
     request.post<any, ApiResponse<TypeMappingResult>>(`/connections/${id}/export/sql/preview`, params),
 
+  // 后台导出任务
+  startExport: (
+    id: string,
+    params: { format: 'csv' | 'sql' | ExportFormat; query?: string; tables?: string[]; opts: any; database?: string }
+  ) =>
+    request.post<any, ApiResponse<ExportJob>>(`/connections/${id}/export/jobs`, params, {
+      params: { database: params.database }
+    }),
+  listExports: () => request.get<any, ApiResponse<ExportJob[]>>('/jobs'),
+  getExport: (jobId: string) => request.get<any, ApiResponse<ExportJob>>(`/jobs/${jobId}`),
+  deleteExport: (jobId: string) => request.delete<any, ApiResponse<any>>(`/jobs/${jobId}`),
+  // 导出文件下载地址，由浏览器直接下载（支持断点续传）
+  exportDownloadUrl: (jobId: string, compress?: 'gzip' | 'zip') =>
+    `/api/v1/jobs/${jobId}/download${compress ? `?compress=${compress}` : ''}`,
+
+  // 导入
+  startRestore: (id: string, file: File, opts: RestoreOptions) => {
+    const form = new FormData()
+    form.append('file', file)
+    form.append('options', JSON.stringify(opts))
+    return request.post<any, ApiResponse<RestoreJob>>(`/connections/${id}/restore`, form, { timeout: 0 })
+  },
+
+  getRestore: (id: string, jobId: string) =>
+    request.get<any, ApiResponse<RestoreJob>>(`/connections/${id}/restore/${jobId}`),
+
+  cancelRestore: (id: string, jobId: string) =>
+    request.post<any, ApiResponse<any>>(`/connections/${id}/restore/${jobId}/cancel`),
+
+  restoreEventsUrl: (id: string, jobId: string) => `/api/v1/connections/${id}/restore/${jobId}/events`,
+
+  previewMigration: (id: string, opts: MigrationOptions) =>
+    request.post<any, ApiResponse<MigrationPlan>>(`/connections/${id}/migrations/preview`, opts),
+
+  startMigration: (id: string, opts: MigrationOptions) =>
+    request.post<any, ApiResponse<MigrationJob>>(`/connections/${id}/migrations`, opts),
+
+  listMigrations: (id: string) =>
+    request.get<any, ApiResponse<MigrationJob[]>>(`/connections/${id}/migrations`),
+
+  cancelMigration: (id: string, jobId: string) =>
+    request.post<any, ApiResponse<any>>(`/connections/${id}/migrations/${jobId}/cancel`),
+
+  resumeMigration: (id: string, jobId: string) =>
+    request.post<any, ApiResponse<MigrationJob>>(`/connections/${id}/migrations/${jobId}/resume`),
+
+  migrationEventsUrl: (id: string, jobId: string) => `/api/v1/connections/${id}/migrations/${jobId}/events`,
+
+  importCSV: (id: string, table: string, database: string, file: File, opts: CSVImportOptions) => {
+    const form = new FormData()
+    form.append('file', file)
+    form.append('options', JSON.stringify(opts))
+    return request.post<any, ApiResponse<ImportResult>>(`/connections/${id}/tables/${table}/import/csv`, form, {
+      params: { database },
+      timeout: 0
+    })
+  },
+
+  importParquet: (id: string, table: string, database: string, file: File, opts: ParquetImportOptions) => {
+    const form = new FormData()
+    form.append('file', file)
+    form.append('options', JSON.stringify(opts))
+    return request.post<any, ApiResponse<ImportResult>>(`/connections/${id}/tables/${table}/import/parquet`, form, {
+      params: { database },
+      timeout: 0
+    })
+  },
+
   // 数据编辑
   createRow: (id: string, table: string, database: string, data: any, schema?: string) =>
     request.post(`/connections/${id}/tables/${table}/data`, data, { params: { database, schema } }),
-  updateRow: (id: string, table: string, database: string, data: any, where: string, schema?: string) =>
-    request.put(`/connections/${id}/tables/${table}/data`, { data, where }, { params: { database, schema } }),
-  deleteRow: (id: string, table: string, database: string, where: string, schema?: string) =>
-    request.delete(`/connections/${id}/tables/${table}/data`, { params: { database, schema }, data: { where } }),
+  updateRow: (id: string, table: string, database: string, data: any, key: RowKey, schema?: string) =>
+    request.put<any, ApiResponse<RowEditResult>>(`/connections/${id}/tables/${table}/data`, { data, key }, { params: { database, schema } }),
+  deleteRow: (id: string, table: string, database: string, key: RowKey, schema?: string) =>
+    request.delete<any, ApiResponse<RowEditResult>>(`/connections/${id}/tables/${table}/data`, { params: { database, schema }, data: { key } }),
+  applyChanges: (id: string, table: string, database: string, changes: ChangeSet, preview: boolean, schema?: string) =>
+    request.post<any, ApiResponse<ChangeSetResult>>(`/connections/${id}/tables/${table}/changes`, { ...changes, preview }, { params: { database, schema }, timeout: 0 }),
+  // 单元格完整值：返回可直接用于查看或下载的地址
+  cellUrl: (id: string, table: string, database: string, column: string, key: RowKey, download = false) => {
+    const params = new URLSearchParams({ database, column, key: JSON.stringify(key) })
+    if (download) params.set('download', '1')
+    return `/api/v1/connections/${id}/tables/${encodeURIComponent(table)}/cell?${params}`
+  },
+  uploadCell: (id: string, table: string, database: string, column: string, key: RowKey, file: File) => {
+    const form = new FormData()
+    form.append('file', file)
+    return request.put<any, ApiResponse<RowEditResult>>(`/connections/${id}/tables/${table}/cell`, form, {
+      params: { database, column, key: JSON.stringify(key) },
+      timeout: 0
+    })
+  },
 
   // 表结构修改
   alterTable: (id: string, table: string, database: string, req: AlterTableRequest) =>
@@ -93,15 +255,47 @@ export const api = {
 import type {
   ConnectionConfig,
   Group,
+  PoolStats,
+  SqlSession,
   TableInfo,
   TableSchema,
   QueryResult,
   ExecuteResult,
   QueryOptions,
+  ScriptOptions,
+  ScriptResult,
+  RowKey,
+  RowEditResult,
+  ChangeSet,
+  ChangeSetResult,
   CSVOptions,
+  CSVImportOptions,
+  ParquetImportOptions,
+  ImportResult,
+  RestoreOptions,
+  RestoreJob,
+  MigrationOptions,
+  MigrationPlan,
+  MigrationJob,
   SQLOptions,
+  ExportFormat,
+  ExportJob,
+  JSONOptions,
+  XLSXOptions,
+  MarkdownOptions,
+  HTMLOptions,
+  ParquetOptions,
   AlterTableRequest,
   RenameTableRequest,
   TypeMappingResult,
-  DatabaseType
+  DatabaseType,
+  AuthState,
+  User,
+  UserRole,
+  Permission,
+  PermissionState,
+  Role,
+  Grant,
+  AuditEntry,
+  AuditQuery
 } from '@/types'
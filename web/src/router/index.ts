@@ -1,10 +1,17 @@
 import { createRouter, createWebHistory, RouteRecordRaw } from 'vue-router'
+import { useAuthStore } from '@/stores/auth'
 
 const routes: RouteRecordRaw[] = [
   {
     path: '/',
     redirect: '/connections'
   },
+  {
+    path: '/login',
+    name: 'Login',
+    component: () => import('@/views/login.vue'),
+    meta: { title: '登录', public: true }
+  },
   {
     path: '/connections',
     name: 'Connections',
@@ -40,6 +47,18 @@ const routes: RouteRecordRaw[] = [
     name: 'Monitor',
     component: () => import('@/views/monitor.vue'),
     meta: { title: '监控' }
+  },
+  {
+    path: '/users',
+    name: 'Users',
+    component: () => import('@/views/users.vue'),
+    meta: { title: '用户管理', admin: true }
+  },
+  {
+    path: '/audit',
+    name: 'Audit',
+    component: () => import('@/views/audit.vue'),
+    meta: { title: '审计日志', admin: true }
   }
 ]
 
@@ -48,9 +67,24 @@ const router = createRouter({
   routes
 })
 
-router.beforeEach((to, _from, next) => {
+router.beforeEach(async (to) => {
   document.title = `${to.meta.title || 'DBM'} - Database Manager`
-  next()
+
+  // 首次进入时恢复登录状态，未登录则跳转登录页
+  const authStore = useAuthStore()
+  if (!authStore.loaded) {
+    await authStore.fetchCurrentUser()
+  }
+  if (to.meta.public) {
+    return authStore.user && to.name === 'Login' ? '/connections' : true
+  }
+  if (!authStore.user) {
+    return { path: '/login', query: { redirect: to.fullPath } }
+  }
+  if (to.meta.admin && !authStore.isAdmin) {
+    return '/connections'
+  }
+  return true
 })
 
 export default router
@@ -0,0 +1,83 @@
+import { defineStore } from 'pinia'
+import { computed, ref } from 'vue'
+import { api, setCSRFToken } from '@/api'
+import type { AuthState, Permission, PermissionState, User } from '@/types'
+
+export const useAuthStore = defineStore('auth', () => {
+  const user = ref<User | null>(null)
+  const authEnabled = ref(true)
+  const loaded = ref(false)
+  const permissions = ref<PermissionState>({ admin: false, connections: {} })
+
+  const isAdmin = computed(() => user.value?.role === 'admin')
+
+  // 当前用户在连接上是否拥有权限，管理员拥有全部权限
+  function can(connectionId: string, permission: Permission) {
+    return isAdmin.value || (permissions.value.connections[connectionId] || []).includes(permission)
+  }
+
+  // 连接列表变化或授权变更后刷新
+  async function fetchPermissions() {
+    try {
+      const res = await api.getPermissions()
+      if (res.code === 0) {
+        permissions.value = res.data
+      }
+    } catch (e) {
+      permissions.value = { admin: false, connections: {} }
+    }
+  }
+
+  function apply(state: AuthState) {
+    user.value = state.user
+    authEnabled.value = state.authEnabled
+    setCSRFToken(state.csrfToken || '')
+    loaded.value = true
+  }
+
+  // 恢复登录状态，未登录时返回 false
+  async function fetchCurrentUser() {
+    try {
+      const res = await api.getCurrentUser()
+      if (res.code === 0) {
+        apply(res.data)
+        await fetchPermissions()
+        return true
+      }
+    } catch (e) {
+      user.value = null
+      setCSRFToken('')
+    }
+    loaded.value = true
+    return false
+  }
+
+  async function login(username: string, password: string) {
+    const res = await api.login(username, password)
+    apply(res.data)
+    await fetchPermissions()
+  }
+
+  async function logout() {
+    try {
+      await api.logout()
+    } finally {
+      user.value = null
+      permissions.value = { admin: false, connections: {} }
+      setCSRFToken('')
+    }
+  }
+
+  return {
+    user,
+    authEnabled,
+    loaded,
+    isAdmin,
+    permissions,
+    can,
+    fetchPermissions,
+    fetchCurrentUser,
+    login,
+    logout
+  }
+})
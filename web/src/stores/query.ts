@@ -1,7 +1,7 @@
 import { defineStore } from 'pinia'
 import { ref } from 'vue'
 import { api } from '@/api'
-import type { QueryResult, TableInfo, TableSchema } from '@/types'
+import type { ChangeSet, QueryResult, RowKey, TableInfo, TableSchema } from '@/types'
 
 export const useQueryStore = defineStore('query', () => {
   const result = ref<QueryResult | null>(null)
@@ -12,18 +12,29 @@ export const useQueryStore = defineStore('query', () => {
   const currentSchema = ref<TableSchema | null>(null)
   const currentSchemaName = ref('')
 
+  const runningQuery = ref<{ connectionId: string; queryId: string } | null>(null)
+
   async function executeQuery(connectionId: string, query: string, opts?: any) {
     loading.value = true
+    const queryId = crypto.randomUUID()
+    runningQuery.value = { connectionId, queryId }
     try {
-      const res = await api.executeQuery(connectionId, query, opts)
+      const res = await api.executeQuery(connectionId, query, opts, queryId)
       if (res.code === 0) {
         result.value = res.data
       }
     } finally {
       loading.value = false
+      runningQuery.value = null
     }
   }
 
+  async function cancelQuery() {
+    if (!runningQuery.value) return
+    const { connectionId, queryId } = runningQuery.value
+    await api.cancelQuery(connectionId, queryId)
+  }
+
   async function fetchDatabases(connectionId: string) {
     const res = await api.getDatabases(connectionId)
     if (res.code === 0) {
@@ -66,22 +77,30 @@ export const useQueryStore = defineStore('query', () => {
     return res
   }
 
-  async function updateRow(connectionId: string, table: string, database: string, data: any, where: string) {
-    const res = await api.updateRow(connectionId, table, database, data, where, currentSchemaName.value)
+  async function updateRow(connectionId: string, table: string, database: string, data: any, key: RowKey) {
+    const res = await api.updateRow(connectionId, table, database, data, key, currentSchemaName.value)
     if (res.code !== 0) {
       throw new Error(res.message || '更新数据失败')
     }
     return res
   }
 
-  async function deleteRow(connectionId: string, table: string, database: string, where: string) {
-    const res = await api.deleteRow(connectionId, table, database, where, currentSchemaName.value)
+  async function deleteRow(connectionId: string, table: string, database: string, key: RowKey) {
+    const res = await api.deleteRow(connectionId, table, database, key, currentSchemaName.value)
     if (res.code !== 0) {
       throw new Error(res.message || '删除数据失败')
     }
     return res
   }
 
+  async function applyChanges(connectionId: string, table: string, database: string, changes: ChangeSet, preview: boolean) {
+    const res = await api.applyChanges(connectionId, table, database, changes, preview, currentSchemaName.value)
+    if (res.code !== 0) {
+      throw new Error(res.message || '提交修改失败')
+    }
+    return res.data
+  }
+
   function clearResult() {
     result.value = null
   }
@@ -89,12 +108,14 @@ export const useQueryStore = defineStore('query', () => {
   return {
     result,
     loading,
+    runningQuery,
     tables,
     databases,
     schemas,
     currentSchema,
     currentSchemaName,
     executeQuery,
+    cancelQuery,
     fetchDatabases,
     fetchSchemas,
     fetchTables,
@@ -102,6 +123,7 @@ export const useQueryStore = defineStore('query', () => {
     createRow,
     updateRow,
     deleteRow,
+    applyChanges,
     clearResult
   }
 })
@@ -27,6 +27,131 @@ export interface ConnectionConfig {
   groupId: string // 所属分组 ID
   connected: boolean
   monitoringEnabled: boolean // 是否启用监控
+  ssh?: SSHConfig // SSH 隧道配置，列表中不返回凭据
+  tls?: TLSConfig // TLS 配置，列表中不返回证书与私钥
+}
+
+// SSH 跳板机
+export interface SSHHop {
+  host: string
+  port: number
+  user: string
+  password: string
+  privateKey: string
+  passphrase: string
+}
+
+// SSH 隧道配置：依次经跳板机登录堡垒机，由堡垒机连接数据库
+export interface SSHConfig extends SSHHop {
+  enabled: boolean
+  jumps: SSHHop[]
+  knownHosts: string // known_hosts 内容，为空时使用服务端 ~/.ssh/known_hosts
+  insecureIgnoreHostKey: boolean
+}
+
+// TLS 模式：disable 不加密，require 只加密，verify-ca 校验证书链，verify-full 同时校验主机名
+export type TLSMode = 'disable' | 'require' | 'verify-ca' | 'verify-full'
+
+// TLS 配置，证书与私钥为 PEM 内容
+export interface TLSConfig {
+  mode: TLSMode
+  caCert: string // 为空时使用系统根证书
+  clientCert: string
+  clientKey: string
+  serverName: string // 校验证书使用的主机名，为空时使用连接主机
+}
+
+// 测试连接时协商的 TLS 参数
+export interface TLSInfo {
+  version: string
+  cipherSuite: string
+  serverName: string
+  verified: boolean
+}
+
+// 连接池统计信息
+export interface PoolStats {
+  database: string
+  createdAt: string
+  lastUsedAt: string
+  maxOpenConnections: number
+  openConnections: number
+  inUse: number
+  idle: number
+  waitCount: number
+  waitDuration: number // 毫秒
+  maxIdleClosed: number
+  maxIdleTimeClosed: number
+  maxLifetimeClosed: number
+}
+
+// 脚本执行选项
+export interface ScriptOptions {
+  database?: string
+  continueOnError?: boolean // 出错后继续执行，默认遇错停止
+}
+
+// 脚本中单条语句的执行结果
+export interface StatementResult {
+  index: number
+  line: number
+  statement: string
+  status: 'success' | 'error' | 'skipped'
+  result?: QueryResult
+  error?: string
+}
+
+// 脚本执行结果
+export interface ScriptResult {
+  statements: StatementResult[]
+  succeeded: number
+  failed: number
+  skipped: number
+  timeCost: number
+}
+
+// 行标识：主键/唯一键列 → 值，无主键时可使用 ROWID、ctid 或 _id
+export type RowKey = Record<string, any>
+
+// 数据编辑结果
+export interface RowEditResult {
+  rowsAffected: number
+}
+
+// 表数据变更集（按删除、更新、插入的顺序在单个事务中应用）
+export interface ChangeSet {
+  inserts: Record<string, any>[]
+  updates: { key: RowKey; data: Record<string, any> }[]
+  deletes: RowKey[]
+}
+
+// 变更集中单行的执行结果
+export interface RowChangeResult {
+  op: 'insert' | 'update' | 'delete'
+  index: number
+  statement: string
+  status: 'success' | 'error' | 'skipped' | 'pending'
+  rowsAffected: number
+  error?: string
+}
+
+// 变更集执行结果
+export interface ChangeSetResult {
+  rows: RowChangeResult[]
+  committed: boolean
+  rowsAffected: number
+  timeCost: number
+}
+
+// SQL 会话
+export interface SqlSession {
+  id: string
+  connectionId: string
+  userId: string
+  database: string
+  createdAt: string
+  lastUsedAt: string
+  inTransaction: boolean
 }
 
 // 分组信息
@@ -76,11 +201,36 @@ export interface TableSchema {
   constraints: any[]
 }
 
+// 结果集列信息
+export interface ColumnMeta {
+  name: string
+  dbType: string
+  scanType?: string
+  nullable?: boolean
+  length?: number
+  precision?: number
+  scale?: number
+  binary?: boolean // 值为 base64 编码的二进制
+}
+
+// 被截断为预览的单元格，完整值通过单元格接口获取
+export interface TruncatedCell {
+  row: number
+  column: number
+  size: number // 完整值的字节数
+}
+
 // 查询结果
 export interface QueryResult {
   columns: string[]
-  rows: Record<string, any>[]
+  columnTypes?: ColumnMeta[]
+  rows: any[][] // 按列顺序排列的行
+  truncatedCells?: TruncatedCell[] // 被截断为预览的单元格
   total: number
+  page?: number
+  pageSize?: number
+  truncated: boolean
+  totalCapped: boolean
   rowsAffected: number
   message: string
   timeCost: number
@@ -106,14 +256,185 @@ export interface QueryOptions {
 // CSV 导出选项
 export interface CSVOptions {
   includeHeader: boolean
-  separator: string
+  separator: string // 可为多个字符，\t 表示制表符
   quote: string
-  encoding: string
+  escape?: string // 为空时引号写为两个引号
+  quoteMode?: 'minimal' | 'all'
+  lineEnding?: 'lf' | 'crlf'
+  encoding: string // UTF-8、GBK、GB18030、UTF-16、UTF-16LE、UTF-16BE、Latin-1
   nullValue: string
   dateFormat: string
+  decimalPoint?: string
+  trueValue?: string
+  falseValue?: string
   maxRows?: number
 }
 
+// CSV/TSV 导入选项
+export interface CSVImportOptions {
+  header?: boolean // 为空时自动识别
+  separator: string // 为空时自动识别
+  quote: string
+  encoding: string // UTF-8、GBK、GB18030
+  nullValue: string
+  mapping?: Record<string, string> // 文件列 → 表列
+  createTable: boolean
+  dryRun: boolean
+  batchSize?: number
+}
+
+// Parquet 导入选项
+export interface ParquetImportOptions {
+  mapping?: Record<string, string> // 文件列 → 表列，为空时按列名匹配
+  createTable: boolean
+  dryRun: boolean
+  batchSize?: number
+}
+
+// 导入的列映射
+export interface ImportColumn {
+  source: string
+  target: string
+  type: string
+}
+
+// 导入失败的行
+export interface ImportRowError {
+  line: number
+  column?: string
+  value?: string
+  error: string
+}
+
+// 导入结果
+export interface ImportResult {
+  columns: ImportColumn[]
+  ignored?: string[]
+  tableCreated: boolean
+  totalRows: number
+  imported: number
+  failed: number
+  errors?: ImportRowError[]
+  dryRun: boolean
+  timeCost: number
+}
+
+// SQL 文件导入选项
+export interface RestoreOptions {
+  database: string
+  continueOnError: boolean
+  transaction: boolean // 仅 PostgreSQL、KingBase、SQL Server、SQLite 支持
+}
+
+// SQL 文件导入失败的语句
+export interface RestoreError {
+  line: number
+  statement: string
+  error: string
+}
+
+// SQL 文件导入任务
+export interface RestoreJob {
+  id: string
+  connectionId: string
+  database: string
+  fileName: string
+  status: 'running' | 'completed' | 'failed' | 'cancelled'
+  transaction: boolean
+  totalBytes: number
+  readBytes: number
+  line: number
+  statements: number
+  failed: number
+  errors?: RestoreError[]
+  error?: string
+  startedAt: string
+  finishedAt?: string
+}
+
+// 跨连接迁移选项
+export interface MigrationOptions {
+  sourceDatabase: string
+  tables: string[]
+  targetId: string
+  targetDatabase: string
+  typeChoices?: Record<string, string> // 需要用户选择的源类型 → 目标类型
+  createTables: boolean
+  batchSize: number
+}
+
+// 迁移时源列与目标列类型的对应
+export interface MigrationColumn {
+  name: string
+  sourceType: string
+  targetType: string
+  nullable: boolean
+}
+
+// 单个表的迁移计划
+export interface MigrationTablePlan {
+  table: string
+  exists: boolean
+  columns: MigrationColumn[]
+}
+
+// 迁移类型映射预览
+export interface MigrationPlan {
+  sourceType: DatabaseType
+  targetType: DatabaseType
+  mapping: TypeMappingResult
+  tables: MigrationTablePlan[]
+}
+
+export type MigrationStatus = 'pending' | 'running' | 'completed' | 'failed' | 'cancelled'
+
+// 单个表的迁移进度
+export interface MigrationTable {
+  table: string
+  status: MigrationStatus
+  created: boolean
+  sourceRows: number
+  copied: number
+  baseRows: number
+  targetRows: number
+  verified: boolean
+  ignored?: string[]
+  error?: string
+}
+
+// 跨连接迁移任务
+export interface MigrationJob {
+  id: string
+  connectionId: string
+  sourceDatabase: string
+  targetId: string
+  targetDatabase: string
+  status: MigrationStatus
+  tables: MigrationTable[]
+  error?: string
+  startedAt: string
+  finishedAt?: string
+}
+
+// 后台导出任务状态
+export type ExportStatus = 'running' | 'completed' | 'failed' | 'cancelled'
+
+// 后台导出任务
+export interface ExportJob {
+  id: string
+  connectionId: string
+  database: string
+  format: string
+  fileName: string
+  status: ExportStatus
+  rows: number  // 已写出的数据行数
+  bytes: number // 已写出的文件字节数
+  error?: string
+  startedAt: string
+  finishedAt?: string
+  expiresAt?: string // 任务与文件的清除时间
+}
+
 // SQL 导出选项
 export interface SQLOptions {
   includeCreateTable: boolean
@@ -124,6 +445,44 @@ export interface SQLOptions {
   maxRows?: number
   query?: string
   tableName?: string  // 自定义查询时的表名（用于 INSERT 语句）
+  targetType?: DatabaseType // 目标数据库类型，为空时与源数据库相同
+  typeChoices?: Record<string, string> // 需要用户选择的源类型 → 目标类型
+}
+
+// 通用导出格式
+export type ExportFormat = 'json' | 'ndjson' | 'xlsx' | 'markdown' | 'html' | 'parquet'
+
+// JSON 与 NDJSON 导出选项
+export interface JSONOptions {
+  pretty: boolean // 缩进格式化（仅 JSON 数组）
+  maxRows?: number
+}
+
+// Excel 导出选项
+export interface XLSXOptions {
+  includeHeader: boolean
+  dateFormat: string // 日期单元格的 Excel 数字格式
+  maxRows?: number
+}
+
+// Markdown 表格导出选项
+export interface MarkdownOptions {
+  nullValue: string
+  maxRows?: number
+}
+
+// HTML 表格导出选项
+export interface HTMLOptions {
+  title: string
+  nullValue: string
+  maxRows?: number
+}
+
+// Parquet 导出选项
+export interface ParquetOptions {
+  rowGroupSize: number // 每个行组的行数
+  compression: 'snappy' | 'zstd' | 'gzip' | 'none'
+  maxRows?: number
 }
 
 // API 响应
@@ -133,6 +492,89 @@ export interface ApiResponse<T = any> {
   data: T
 }
 
+// 用户角色
+export type UserRole = 'admin' | 'user'
+
+// Web 界面用户
+export interface User {
+  id: string
+  username: string
+  role: UserRole
+  disabled: boolean
+  createdAt: string
+  updatedAt: string
+}
+
+// 登录状态：未启用认证时 authEnabled 为 false，user 为匿名管理员
+export interface AuthState {
+  user: User
+  authEnabled: boolean
+  csrfToken?: string
+  expiresAt?: string
+}
+
+// 连接上的操作权限
+export type Permission = 'metadata' | 'select' | 'dml' | 'ddl' | 'edit' | 'export' | 'credentials'
+
+// 权限角色：授权时引用的一组权限，内置角色不可修改
+export interface Role {
+  name: string
+  description: string
+  permissions: Permission[]
+  builtin: boolean
+}
+
+// 授予用户在连接或分组上的角色，connectionId 与 groupId 均为空时对所有连接生效
+export interface Grant {
+  id: string
+  userId: string
+  connectionId?: string
+  groupId?: string
+  role: string
+  createdAt: string
+}
+
+// 当前用户在各连接上的权限
+export interface PermissionState {
+  admin: boolean
+  connections: Record<string, Permission[]>
+}
+
+// 审计记录的操作类型
+export type AuditAction = 'query' | 'execute' | 'script' | 'data' | 'alter' | 'rename' | 'export' | 'import' | 'session'
+
+// 审计记录：每次执行的语句或数据变更
+export interface AuditEntry {
+  id: string
+  time: string
+  userId?: string
+  username: string
+  clientIp?: string
+  connectionId: string
+  databaseType?: DatabaseType
+  database?: string
+  action: AuditAction
+  target?: string
+  statement?: string
+  duration: number // 纳秒
+  rowsAffected: number
+  success: boolean
+  error?: string
+}
+
+// 审计日志查询条件，from、to 为 RFC 3339 时间
+export interface AuditQuery {
+  username?: string
+  connectionId?: string
+  database?: string
+  action?: AuditAction
+  success?: boolean
+  from?: string
+  to?: string
+  q?: string
+  limit?: number
+}
+
 // 表结构修改相关类型
 export enum AlterActionType {
   ADD_COLUMN = 'ADD_COLUMN',
@@ -194,6 +636,7 @@ export interface TypeRule {
   safeFallback: string
   precisionLoss: boolean
   requiresUser: boolean
+  maxLength?: number // 目标类型的长度或精度上限
   userOptions: TypeOption[]
   note: string
 }
@@ -14,7 +14,11 @@ export default defineConfig({
     proxy: {
       '/api': {
         target: 'http://localhost:8080',
-        changeOrigin: true
+        changeOrigin: true,
+        // 经代理后对浏览器而言是同源请求，去掉 Origin 以免被服务端当作跨域写请求拒绝
+        configure: (proxy) => {
+          proxy.on('proxyReq', (proxyReq) => proxyReq.removeHeader('origin'))
+        }
       },
       '/metrics': {
         target: 'http://localhost:8080',